@@ -1,7 +1,9 @@
 package main
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"log"
 	"os"
 	"sync"
@@ -12,6 +14,7 @@ import (
 	"github.com/dotside-studios/davi-nfc-agent/server"
 	"github.com/dotside-studios/davi-nfc-agent/server/clientserver"
 	"github.com/dotside-studios/davi-nfc-agent/server/deviceserver"
+	"github.com/dotside-studios/davi-nfc-agent/server/linesink"
 	"github.com/dotside-studios/davi-nfc-agent/tls"
 )
 
@@ -31,11 +34,64 @@ func GetCardTypeFilterTooltip(cardType string) string {
 }
 
 type Agent struct {
-	Logger           *log.Logger
-	Manager          nfc.Manager // NFC device manager (supports hardware and smartphone)
-	Reader           *nfc.NFCReader
-	AllowedCardTypes map[string]bool // Card type filter using map
-	APISecret        string
+	Logger             *log.Logger
+	Manager            nfc.Manager // NFC device manager (supports hardware and smartphone)
+	Reader             *nfc.NFCReader
+	AllowedCardTypes   map[string]bool // Card type filter using map
+	APISecret          string
+	SigningKey         string                 // HMAC key for signing broadcasts to clients (empty to not sign)
+	DataDropPolicy     nfc.DataDropPolicy     // Which tag event to drop when consumers fall behind
+	BlankCardPolicy    nfc.BlankCardPolicy    // How cards without NDEF data are reported
+	ReaderOptions      nfc.ReaderOptions      // Reader channel buffer sizes and device selection (zero for defaults)
+	TimestampFormat    server.TimestampFormat // Timestamp encoding in client payloads
+	DebugCommands      bool                   // Enable raw tag access commands for clients
+	RedactAPDUs        bool                   // Mask card data in APDU traces returned to clients
+	WearTracker        *nfc.WearTracker       // Persisted per-UID write counts (optional)
+	SignatureKey       *nfc.SignatureKey      // Checks NTAG originality signatures in getCardInfo (optional)
+	MagicProbe         bool                   // Probe MIFARE Classic cards for the gen1a backdoor in getCardInfo
+	DeviceWriteTimeout time.Duration          // How long writes routed to a phone wait for its answer
+	WriteRateLimit     float64                // Writes per second allowed per client (0 = unlimited)
+	WriteBurst         int                    // Writes a client can send at once before WriteRateLimit applies
+	Events             *server.EventLog       // Recent log events served over HTTP (optional)
+	IdleWithoutClients bool                   // Pause tag polling while no clients are connected
+	QueueBusyWrites    bool                   // Let writes wait out reconnects and cooldowns instead of failing fast
+	WipeTrailing       bool                   // Zero-fill the NDEF area after every written message
+	RemovalGrace       time.Duration          // How long a removed card may take to come back before its removal is reported
+	RetapCooldown      time.Duration          // How long after a UID is announced that re-taps of it are not announced
+	HardwareReset      bool                   // Allow USB-level resets of a wedged reader (needs permissions)
+	MDNSName           string                 // mDNS instance name (default: derived from the hostname)
+	AgentID            string                 // Persisted ID advertised over mDNS (optional)
+
+	// LineSink writes each scan as a plain text line next to the WebSocket
+	// servers (optional)
+	LineSink *linesink.Sink
+
+	// ExclusiveWriter lets only the first connected client write; later
+	// clients join as readers until it disconnects
+	ExclusiveWriter bool
+
+	// WriterDisconnect decides whether a dropped writer's in-flight write
+	// keeps holding the writer session (default: hold)
+	WriterDisconnect clientserver.WriterDisconnectPolicy
+
+	// WriterControlsMode keeps the reader in ModeReadOnly while no client
+	// holds the writer session, and in ModeReadWrite while one does. It
+	// implies ExclusiveWriter
+	WriterControlsMode bool
+
+	// RefreshOnWriteRemoval reconnects and polls right after a write fails
+	// because the card was removed
+	RefreshOnWriteRemoval bool
+
+	// CompressThreshold is the tagData size in bytes above which messages are
+	// gzipped for clients that subscribe with compress (0 disables)
+	CompressThreshold int
+
+	// LaneDevices are further readers opened next to the main one, e.g. one
+	// per checkout lane. Their events carry the device name as readerId and
+	// writes reach them by it (optional)
+	LaneDevices []string
+	Lanes       *nfc.MultiReader
 
 	// Two-server architecture
 	Bridge       *server.ServerBridge
@@ -52,9 +108,16 @@ type Agent struct {
 	// Internal state
 	devicePath        string        // Current device path
 	serversMu         sync.Mutex    // Protects server restart operations
+	startMu           sync.Mutex    // Protects startCancel
+	startCancel       func()        // Aborts a Start still opening the device
+	cardTypesMu       sync.Mutex    // Protects AllowedCardTypes once servers run
 	serverRestartChan chan struct{} // Signals when servers are restarted
 }
 
+// ShutdownTimeout bounds how long Stop, and the shutdown on SIGINT/SIGTERM,
+// wait for the agent to stop.
+const ShutdownTimeout = 10 * time.Second
+
 func NewAgent(nfcManager nfc.Manager) *Agent {
 	return &Agent{
 		Logger:            log.New(os.Stderr, "[agent] ", log.LstdFlags),
@@ -62,6 +125,7 @@ func NewAgent(nfcManager nfc.Manager) *Agent {
 		AllowedCardTypes:  make(map[string]bool),
 		DevicePort:        9470,
 		ClientPort:        9471,
+		CompressThreshold: clientserver.DefaultCompressThreshold,
 		serverRestartChan: make(chan struct{}, 1),
 	}
 }
@@ -74,7 +138,7 @@ func (a *Agent) ServerRestarts() <-chan struct{} {
 
 func (a *Agent) Start(devicePath string) error {
 	if a.Reader != nil {
-		if devicePath == a.Reader.DevicePath() {
+		if nfc.NormalizeDeviceName(devicePath) == nfc.NormalizeDeviceName(a.Reader.DevicePath()) {
 			a.Logger.Printf("NFC reader already running on device: %s", devicePath)
 			return nil
 		}
@@ -84,14 +148,41 @@ func (a *Agent) Start(devicePath string) error {
 	// Store device path for potential restarts
 	a.devicePath = devicePath
 
+	// Opening the device can hang on a wedged reader, so let Shutdown abort it
+	ctx, cancel := context.WithCancel(context.Background())
+	a.startMu.Lock()
+	a.startCancel = cancel
+	a.startMu.Unlock()
+	defer func() {
+		a.startMu.Lock()
+		a.startCancel = nil
+		a.startMu.Unlock()
+		cancel()
+	}()
+
 	// Create NFC reader with manager (supports both hardware and smartphone devices)
-	nfcReader, err := nfc.NewNFCReader(devicePath, a.Manager, 5*time.Second)
+	readerOptions := a.ReaderOptions
+	readerOptions.Context = ctx
+	nfcReader, err := nfc.NewNFCReader(devicePath, a.Manager, 5*time.Second, readerOptions)
 	if err != nil {
 		a.Logger.Printf("Error initializing NFC reader: %v", err)
 		return err
 	}
+	if ctx.Err() != nil {
+		nfcReader.Close()
+		return errors.New("agent start cancelled by shutdown")
+	}
 
+	a.configureReader(nfcReader)
+
+	lanes, err := a.openLanes(readerOptions)
+	if err != nil {
+		nfcReader.Close()
+		a.Logger.Printf("Error initializing lane readers: %v", err)
+		return err
+	}
 	a.Reader = nfcReader
+	a.Lanes = lanes
 
 	// Start network watcher if TLS manager is configured
 	if a.TLSManager != nil {
@@ -102,40 +193,165 @@ func (a *Agent) Start(devicePath string) error {
 	return a.startServers()
 }
 
-func (a *Agent) Stop() {
-	if a.Reader == nil && a.DeviceServer == nil {
-		a.Logger.Println("Agent is not running")
-		return
+// configureReader applies the agent's reader settings to r.
+func (a *Agent) configureReader(r *nfc.NFCReader) {
+	r.SetDataDropPolicy(a.DataDropPolicy)
+	r.SetBlankCardPolicy(a.BlankCardPolicy)
+	r.SetWearTracker(a.WearTracker)
+	r.SetSignatureKey(a.SignatureKey)
+	r.SetMagicProbe(a.MagicProbe)
+	r.SetQueueWritesWhileBusy(a.QueueBusyWrites)
+	r.SetWipeTrailing(a.WipeTrailing)
+	r.SetRefreshOnWriteRemoval(a.RefreshOnWriteRemoval)
+	r.SetRemovalGrace(a.RemovalGrace)
+	r.SetRetapCooldown(a.RetapCooldown)
+	r.SetHardwareReset(a.HardwareReset)
+}
+
+// openLanes opens a reader per LaneDevices entry and merges them into a
+// MultiReader keyed by device name. It returns nil without lane devices.
+func (a *Agent) openLanes(opts nfc.ReaderOptions) (*nfc.MultiReader, error) {
+	if len(a.LaneDevices) == 0 {
+		return nil, nil
 	}
 
-	a.Logger.Println("Stopping agent...")
+	entries := make([]nfc.ReaderEntry, 0, len(a.LaneDevices))
+	closeAll := func() {
+		for _, entry := range entries {
+			entry.Reader.Close()
+		}
+	}
+	for _, device := range a.LaneDevices {
+		r, err := nfc.NewNFCReader(device, a.Manager, 5*time.Second, opts)
+		if err != nil {
+			closeAll()
+			return nil, fmt.Errorf("lane %s: %w", device, err)
+		}
+		a.configureReader(r)
+		entries = append(entries, nfc.ReaderEntry{ID: device, Reader: r})
+	}
 
-	if a.ClientServer != nil {
-		a.ClientServer.Stop()
-		a.ClientServer = nil
+	lanes, err := nfc.NewMultiReader(entries...)
+	if err != nil {
+		closeAll()
+		return nil, err
 	}
+	return lanes, nil
+}
 
-	if a.DeviceServer != nil {
-		a.DeviceServer.Stop()
-		a.DeviceServer = nil
+// readers returns the main reader followed by the lane readers.
+func (a *Agent) readers() []*nfc.NFCReader {
+	var readers []*nfc.NFCReader
+	if a.Reader != nil {
+		readers = append(readers, a.Reader)
 	}
+	if a.Lanes != nil {
+		for _, id := range a.Lanes.IDs() {
+			r, _ := a.Lanes.Reader(id)
+			readers = append(readers, r)
+		}
+	}
+	return readers
+}
 
-	if a.Bridge != nil {
-		a.Bridge.Close()
-		a.Bridge = nil
+// Stop shuts the agent down like Shutdown, giving up after ShutdownTimeout.
+func (a *Agent) Stop() {
+	ctx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
+	defer cancel()
+	if err := a.Shutdown(ctx); err != nil {
+		a.Logger.Printf("Agent did not stop cleanly: %v", err)
 	}
+}
 
-	if a.Reader != nil {
-		a.Reader.Stop()
-		a.Reader = nil
+// Shutdown stops the agent in order: new connections are refused, running
+// tag operations are allowed to finish, the reader is stopped and its device
+// closed, and only then are the servers stopped and the mDNS record withdrawn.
+// If ctx ends first, Shutdown returns without running the remaining steps and
+// the agent is left half stopped; callers are expected to exit then.
+func (a *Agent) Shutdown(ctx context.Context) error {
+	// Abort a Start still connecting to the device before waiting on it
+	a.startMu.Lock()
+	if a.startCancel != nil {
+		a.startCancel()
 	}
+	a.startMu.Unlock()
 
-	// Cleanup Manager if it's a remotenfc.Manager
-	if pm, ok := a.Manager.(*remotenfc.Manager); ok {
-		pm.Close()
+	a.serversMu.Lock()
+	defer a.serversMu.Unlock()
+
+	if a.Reader == nil && a.DeviceServer == nil {
+		a.Logger.Println("Agent is not running")
+		return nil
+	}
+
+	a.Logger.Println("Stopping agent...")
+
+	steps := []struct {
+		name string
+		run  func()
+	}{
+		{"stopping listeners", func() {
+			if a.ClientServer != nil {
+				if err := a.ClientServer.StopAccepting(ctx); err != nil {
+					a.Logger.Printf("Client server: %v", err)
+				}
+			}
+			if a.DeviceServer != nil {
+				if err := a.DeviceServer.StopAccepting(ctx); err != nil {
+					a.Logger.Printf("Device server: %v", err)
+				}
+			}
+		}},
+		{"waiting for tag operations", func() {
+			for _, r := range a.readers() {
+				r.Pause()
+				if err := r.WaitIdle(ctx); err != nil {
+					a.Logger.Printf("Tag operation still running: %v", err)
+				}
+			}
+		}},
+		{"stopping reader", func() {
+			for _, r := range a.readers() {
+				r.Stop()
+			}
+		}},
+		{"closing device", func() {
+			if a.Lanes != nil {
+				a.Lanes.Close()
+				a.Lanes = nil
+			}
+			if a.Reader != nil {
+				a.Reader.Close()
+				a.Reader = nil
+			}
+			// Cleanup Manager if it's a remotenfc.Manager
+			if pm, ok := a.Manager.(*remotenfc.Manager); ok {
+				pm.Close()
+			}
+		}},
+		{"stopping servers", a.stopServers}, // Withdraws the mDNS record last
+	}
+
+	for _, step := range steps {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("shutdown aborted before %s: %w", step.name, err)
+		}
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			step.run()
+		}()
+
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return fmt.Errorf("shutdown timed out while %s: %w", step.name, ctx.Err())
+		}
 	}
 
 	a.Logger.Println("Agent stopped successfully")
+	return nil
 }
 
 // watchNetworkChanges listens for network changes from TLS manager and restarts servers.
@@ -226,22 +442,81 @@ func (a *Agent) startServers() error {
 	}
 
 	// Create device server
+	var onTagData func(nfc.NFCData)
+	if a.LineSink != nil {
+		onTagData = a.LineSink.Write
+	}
+
 	a.DeviceServer = deviceserver.New(deviceserver.Config{
-		Reader:           a.Reader,
-		DeviceManager:    deviceManager,
-		Port:             a.DevicePort,
-		APISecret:        a.APISecret,
-		AllowedCardTypes: a.AllowedCardTypes,
-		CertFile:         a.CertFile,
-		KeyFile:          a.KeyFile,
+		Reader:                   a.Reader,
+		Readers:                  a.Lanes,
+		DeviceManager:            deviceManager,
+		Port:                     a.DevicePort,
+		APISecret:                a.APISecret,
+		AllowedCardTypes:         a.allowedCardTypes(),
+		DeviceWriteTimeout:       a.DeviceWriteTimeout,
+		RedactAPDUs:              a.RedactAPDUs,
+		TimestampFormat:          a.TimestampFormat,
+		WriteRateLimit:           a.WriteRateLimit,
+		WriteBurst:               a.WriteBurst,
+		MDNSName:                 a.MDNSName,
+		AgentID:                  a.AgentID,
+		OnTagData:                onTagData,
+		OnAllowedCardTypesChange: a.setAllowedCardTypes,
+		CertFile:                 a.CertFile,
+		KeyFile:                  a.KeyFile,
 	}, a.Bridge)
 
+	// A fresh client server has no clients yet
+	var onClientCount func(int)
+	if a.IdleWithoutClients {
+		readers := a.readers()
+		for _, r := range readers {
+			r.SetIdle(true)
+		}
+		onClientCount = func(clients int) {
+			for _, r := range readers {
+				r.SetIdle(clients == 0)
+			}
+		}
+	}
+
+	// Likewise no client holds the writer session yet
+	var onWriterChange func(bool)
+	if a.WriterControlsMode {
+		readers := a.readers()
+		for _, r := range readers {
+			r.SetMode(nfc.ModeReadOnly)
+		}
+		onWriterChange = func(held bool) {
+			mode := nfc.ModeReadOnly
+			if held {
+				mode = nfc.ModeReadWrite
+			}
+			for _, r := range readers {
+				r.SetMode(mode)
+			}
+		}
+	}
+
 	// Create client server
 	a.ClientServer = clientserver.New(clientserver.Config{
 		Port:      a.ClientPort,
 		APISecret: a.APISecret,
 		CertFile:  a.CertFile,
 		KeyFile:   a.KeyFile,
+
+		TimestampFormat: a.TimestampFormat,
+		DebugCommands:   a.DebugCommands,
+		Events:          a.Events,
+
+		ExclusiveWriter:   a.ExclusiveWriter || a.WriterControlsMode,
+		WriterDisconnect:  a.WriterDisconnect,
+		CompressThreshold: a.CompressThreshold,
+		SigningKey:        []byte(a.SigningKey),
+
+		OnClientCountChange: onClientCount,
+		OnWriterChange:      onWriterChange,
 	}, a.Bridge)
 
 	// Start both servers
@@ -261,23 +536,63 @@ func (a *Agent) SetAllowCardType(cardType string, allow bool) {
 }
 
 func (a *Agent) AllowAllCardTypes() {
+	a.cardTypesMu.Lock()
+	defer a.cardTypesMu.Unlock()
 	for _, cardType := range nfc.GetAllCardTypes() {
 		a.AllowedCardTypes[cardType] = true
 	}
+	a.syncAllowedCardTypes()
 }
 
 func (a *Agent) AllowedCardTypesLength() int {
+	a.cardTypesMu.Lock()
+	defer a.cardTypesMu.Unlock()
 	return len(a.AllowedCardTypes)
 }
 
 func (a *Agent) AllowCardType(cardType string) {
+	a.cardTypesMu.Lock()
+	defer a.cardTypesMu.Unlock()
 	a.AllowedCardTypes[cardType] = true
+	a.syncAllowedCardTypes()
 }
 
 func (a *Agent) DisallowCardType(cardType string) {
+	a.cardTypesMu.Lock()
+	defer a.cardTypesMu.Unlock()
 	delete(a.AllowedCardTypes, cardType)
+	a.syncAllowedCardTypes()
+}
+
+// syncAllowedCardTypes pushes the card type filter to the running reader.
+// The caller holds cardTypesMu.
+func (a *Agent) syncAllowedCardTypes() {
+	for _, r := range a.readers() {
+		r.SetAllowedCardTypes(a.AllowedCardTypes)
+	}
+}
+
+// allowedCardTypes returns a copy of the card type filter.
+func (a *Agent) allowedCardTypes() map[string]bool {
+	a.cardTypesMu.Lock()
+	defer a.cardTypesMu.Unlock()
+	types := make(map[string]bool, len(a.AllowedCardTypes))
+	for cardType, ok := range a.AllowedCardTypes {
+		types[cardType] = ok
+	}
+	return types
+}
+
+// setAllowedCardTypes records a filter changed by a client with
+// setAllowedTypes, so it survives server restarts. The reader already has it.
+func (a *Agent) setAllowedCardTypes(types map[string]bool) {
+	a.cardTypesMu.Lock()
+	defer a.cardTypesMu.Unlock()
+	a.AllowedCardTypes = types
 }
 
 func (a *Agent) IsCardTypeAllowed(cardType string) bool {
+	a.cardTypesMu.Lock()
+	defer a.cardTypesMu.Unlock()
 	return a.AllowedCardTypes[cardType]
 }
@@ -156,6 +156,16 @@ export interface DeviceStatus {
    * Whether a card is currently present
    */
   cardPresent?: boolean;
+
+  /**
+   * Lifecycle event behind this update ('reconnected' after the reader recovered from an error)
+   */
+  event?: 'reconnected';
+
+  /**
+   * Error category that triggered the event (e.g. 'timeout', 'io', 'config', 'cooldown')
+   */
+  reason?: string;
 }
 
 /**
@@ -216,9 +226,25 @@ export interface WriteResponse {
   message: string;
 }
 
+/**
+ * Handshake payload sent once after connecting
+ */
+export interface ReadyPayload {
+  /**
+   * Agent version
+   */
+  serverVersion: string;
+
+  /**
+   * Whether this connection may write ('writer') or only receive broadcasts ('reader')
+   */
+  sessionRole: 'writer' | 'reader';
+}
+
 /**
  * Event handler function types
  */
+export type ReadyHandler = (payload: ReadyPayload) => void;
 export type TagDataHandler = (data: TagData) => void;
 export type DeviceStatusHandler = (status: DeviceStatus) => void;
 export type ConnectedHandler = () => void;
@@ -228,12 +254,13 @@ export type ErrorHandler = (error: ErrorEvent) => void;
 /**
  * Event name types
  */
-export type EventName = 'tagData' | 'deviceStatus' | 'connected' | 'disconnected' | 'error';
+export type EventName = 'ready' | 'tagData' | 'deviceStatus' | 'connected' | 'disconnected' | 'error';
 
 /**
  * Event handler type map
  */
 export interface EventHandlerMap {
+  ready: ReadyHandler;
   tagData: TagDataHandler;
   deviceStatus: DeviceStatusHandler;
   connected: ConnectedHandler;
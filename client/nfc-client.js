@@ -36,6 +36,7 @@ class NFCClient {
 
     this.ws = null;
     this.sessionToken = null;
+    this.sessionRole = null;
     this.connected = false;
     this.reconnectAttempts = 0;
     this.intentionalDisconnect = false;
@@ -46,6 +47,7 @@ class NFCClient {
 
     // Event handlers
     this.eventHandlers = {
+      ready: [],
       tagData: [],
       deviceStatus: [],
       connected: [],
@@ -56,7 +58,7 @@ class NFCClient {
 
   /**
    * Registers an event handler
-   * @param {string} event - Event name ('tagData', 'deviceStatus', 'connected', 'disconnected', 'error')
+   * @param {string} event - Event name ('ready', 'tagData', 'deviceStatus', 'connected', 'disconnected', 'error')
    * @param {Function} handler - Callback function
    */
   on(event, handler) {
@@ -220,6 +222,10 @@ class NFCClient {
 
     // Handle broadcast messages (without ID)
     switch (type) {
+      case 'ready':
+        this.sessionRole = payload?.sessionRole || null;
+        this._emit('ready', payload);
+        break;
       case 'tagData':
         this._emit('tagData', this._parseTagData(payload));
         break;
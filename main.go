@@ -4,12 +4,15 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strings"
 	"syscall"
 	"time"
 
@@ -19,6 +22,11 @@ import (
 	"github.com/dotside-studios/davi-nfc-agent/nfc"
 	"github.com/dotside-studios/davi-nfc-agent/nfc/multimanager"
 	"github.com/dotside-studios/davi-nfc-agent/nfc/remotenfc"
+	"github.com/dotside-studios/davi-nfc-agent/server"
+	"github.com/dotside-studios/davi-nfc-agent/server/clientserver"
+	"github.com/dotside-studios/davi-nfc-agent/server/deviceserver"
+	"github.com/dotside-studios/davi-nfc-agent/server/linesink"
+	"github.com/dotside-studios/davi-nfc-agent/store"
 	"github.com/dotside-studios/davi-nfc-agent/tls"
 )
 
@@ -28,6 +36,10 @@ const (
 	DEFAULT_BOOTSTRAP_PORT = 9472
 )
 
+// forceExitGrace is how long past ShutdownTimeout the process may take to
+// exit after a signal before it is forced to.
+const forceExitGrace = 5 * time.Second
+
 var (
 	// CLI flags
 	versionFlag       bool
@@ -36,24 +48,104 @@ var (
 	clientPortFlag    int
 	bootstrapPortFlag int
 	apiSecretFlag     string
+	signingKeyFlag    string
 	certFileFlag      string
 	keyFileFlag       string
 	autoTLSFlag       bool
 	configDirFlag     string
+	trayCardFlag      string
+	trayTextMaxFlag   int
+	maxRemoteFlag     int
+	dataDropFlag      string
+	blankCardsFlag    string
+	timestampFlag     string
+	writerDiscFlag    string
+	compressFlag      int
+	enumRetriesFlag   int
+	enumDelayFlag     time.Duration
+	monitorStopFlag   time.Duration
+	debugCmdsFlag     bool
+	redactAPDUsFlag   bool
+	wearStatsFlag     bool
+	deviceWriteFlag   time.Duration
+	writeRateFlag     float64
+	writeBurstFlag    int
+	eventLogFlag      int
+	unsupportedFlag   string
+	idleFlag          bool
+	writerModeFlag    bool
+	exclusiveFlag     bool
+	queueBusyFlag     bool
+	wipeTrailingFlag  bool
+	removalGraceFlag  time.Duration
+	retapCooldownFlag time.Duration
+	hwResetFlag       bool
+	lineSinkFlag      string
+	lineFormatFlag    string
+	mdnsNameFlag      string
+	type4PreFlag      string
+	sigKeyFlag        string
+	magicProbeFlag    bool
+	dataBufferFlag    int
+	statusBufferFlag  int
+	deviceSelectFlag  string
+	maxRecordsFlag    int
+	writeRefreshFlag  bool
+	classicDelayFlag  time.Duration
 )
 
 func main() {
 	// Command line flags
 	flag.BoolVar(&versionFlag, "version", false, "Print version information and exit")
-	flag.StringVar(&devicePathFlag, "device", "", "Path to NFC device (optional)")
+	flag.StringVar(&devicePathFlag, "device", "", "NFC reader name or case-insensitive part of it, e.g. acr122; a comma-separated list also opens the other readers as lanes, whose events carry the name as readerId (optional)")
+	flag.StringVar(&deviceSelectFlag, "device-select", "first", "Device opened when -device is empty, among readers in sorted order: first, last, index:N or name:PATTERN (case-insensitive regexp)")
 	flag.IntVar(&devicePortFlag, "device-port", DEFAULT_DEVICE_PORT, "Port for device server (NFC devices, readers)")
 	flag.IntVar(&clientPortFlag, "client-port", DEFAULT_CLIENT_PORT, "Port for client server (web clients)")
 	flag.IntVar(&bootstrapPortFlag, "bootstrap-port", DEFAULT_BOOTSTRAP_PORT, "Port for CA bootstrap server (0 to disable)")
 	flag.StringVar(&apiSecretFlag, "api-secret", "", "API secret for session handshake (optional)")
+	flag.StringVar(&signingKeyFlag, "signing-key", "", "Shared secret to HMAC-sign broadcast messages to clients with, in a sig field; may equal -api-secret (optional)")
 	flag.StringVar(&certFileFlag, "cert", "", "Path to TLS certificate file (enables HTTPS/WSS)")
 	flag.StringVar(&keyFileFlag, "key", "", "Path to TLS private key file (enables HTTPS/WSS)")
 	flag.BoolVar(&autoTLSFlag, "auto-tls", true, "Automatically generate and manage TLS certificates")
 	flag.StringVar(&configDirFlag, "config-dir", "", "Config directory (default: platform-specific)")
+	flag.StringVar(&trayCardFlag, "tray-card-display", string(CardDisplayUID), "Systray card label format: uid, text or both")
+	flag.IntVar(&trayTextMaxFlag, "tray-text-max", DefaultCardTextMaxLen, "Maximum card text length shown in the systray (0 for no limit)")
+	flag.IntVar(&maxRemoteFlag, "max-remote-devices", remotenfc.DefaultMaxDevices, "Maximum number of registered remote (smartphone) devices (0 for no limit)")
+	flag.StringVar(&dataDropFlag, "data-drop-policy", nfc.DropOldest.String(), "Tag event to drop when clients fall behind: oldest or newest")
+	flag.StringVar(&blankCardsFlag, "blank-cards", nfc.BlankCardsAnnounce.String(), "Cards without NDEF data: announce, suppress, or flag (announce with blank: true)")
+	flag.IntVar(&dataBufferFlag, "data-buffer", nfc.DefaultDataBufferSize, "Number of tag events queued for clients before -data-drop-policy applies")
+	flag.IntVar(&maxRecordsFlag, "max-ndef-records", nfc.DefaultMaxNDEFRecords, "Maximum number of records in an NDEF message read from or written to a card; longer messages fail")
+	flag.IntVar(&statusBufferFlag, "status-buffer", nfc.DefaultStatusBufferSize, "Number of device status updates queued before new ones are dropped")
+	flag.StringVar(&timestampFlag, "timestamp-format", string(server.TimestampRFC3339), "Timestamp encoding for clients: rfc3339, epochms or both")
+	flag.StringVar(&writerDiscFlag, "writer-disconnect", string(clientserver.WriterHold), "When the writer disconnects mid-write: hold (keep the session until the write finishes) or release")
+	flag.IntVar(&compressFlag, "compress-threshold", clientserver.DefaultCompressThreshold, "Size in bytes above which tagData is gzipped for clients that subscribe with compress (0 to disable)")
+	flag.IntVar(&enumRetriesFlag, "enum-retries", nfc.DeviceEnumRetries, "Number of attempts when enumerating hardware readers")
+	flag.DurationVar(&enumDelayFlag, "enum-retry-delay", nfc.DeviceEnumDelay, "Delay between hardware reader enumeration attempts")
+	flag.DurationVar(&monitorStopFlag, "monitor-stop-timeout", nfc.DefaultMonitorStopTimeout, "How long closing a hardware reader waits for its card removal monitor to stop")
+	flag.BoolVar(&debugCmdsFlag, "debug-commands", false, "Enable raw tag access commands (readPages, writePage, readMAD, readRecord, readManufacturerBlock) for clients")
+	flag.BoolVar(&redactAPDUsFlag, "redact-apdus", false, "Mask card data in APDU traces, keeping command headers, lengths and status words")
+	flag.BoolVar(&wearStatsFlag, "wear-stats", true, "Track per-card write counts in the config directory")
+	flag.DurationVar(&deviceWriteFlag, "device-write-timeout", deviceserver.DefaultDeviceWriteTimeout, "How long a write routed to a smartphone waits for its response")
+	flag.Float64Var(&writeRateFlag, "write-rate-limit", 0, "Maximum writes per second per client; excess writes fail with RATE_LIMITED (0 for no limit)")
+	flag.IntVar(&writeBurstFlag, "write-burst", 0, "Writes a client can send at once before -write-rate-limit applies (default: the rate rounded up)")
+	flag.StringVar(&unsupportedFlag, "unsupported-tags", nfc.UnsupportedTagError.String(), "How to report cards the reader cannot read: error, ignore, raw (UID and ATR only) or event (unsupportedCard message)")
+	flag.BoolVar(&idleFlag, "idle-without-clients", false, "Stop polling for cards while no clients are connected (devices are still detected)")
+	flag.BoolVar(&exclusiveFlag, "exclusive-writer", false, "Let only the first connected client write; later clients join as readers and their writes fail with READ_ONLY_SESSION")
+	flag.BoolVar(&writerModeFlag, "writer-controls-mode", false, "Keep the reader read-only unless a client holds the writer session, and read/write while one does (implies -exclusive-writer)")
+	flag.BoolVar(&queueBusyFlag, "queue-busy-writes", false, "Let writes wait while the reader reconnects or cools down instead of failing with DEVICE_BUSY or DEVICE_COOLDOWN")
+	flag.BoolVar(&wipeTrailingFlag, "wipe-trailing", false, "Zero-fill the rest of the card's NDEF area on every write, so no bytes of an earlier, longer message remain (slower)")
+	flag.BoolVar(&writeRefreshFlag, "refresh-on-write-removal", false, "When a write fails because the card was removed, reconnect the reader and poll at once instead of on the next poll tick")
+	flag.DurationVar(&removalGraceFlag, "removal-grace", 0, "How long a card that left the field may take to come back with the same UID before its removal is reported, so brief contact losses do not produce remove/add pairs (0 to report removals at once)")
+	flag.DurationVar(&retapCooldownFlag, "retap-cooldown", 0, "How long after a card is announced that the same UID is not announced again, even after removal, so double taps are processed once (0 to announce every tap)")
+	flag.BoolVar(&hwResetFlag, "hardware-reset", false, "Reset a wedged reader over USB after repeated cooldowns and allow the resetDevice command (Linux; needs write access to /dev/bus/usb)")
+	flag.StringVar(&lineSinkFlag, "line-sink", "", "Also write each scan as a text line to stdout or tcp:<address>, e.g. tcp::9473 (optional)")
+	flag.StringVar(&lineFormatFlag, "line-format", linesink.DefaultFormat, "Go template for -line-sink lines; fields: UID, Type, Technology, Text, ReaderID, ScannedAt; csv quotes a field")
+	flag.StringVar(&mdnsNameFlag, "mdns-name", "", "mDNS instance name advertised by the device server (default: derived from the hostname)")
+	flag.DurationVar(&classicDelayFlag, "classic-write-delay", 0, "Pause between block writes to MIFARE Classic cards, for clones that drop back-to-back writes, e.g. 10ms (0 for none)")
+	flag.StringVar(&type4PreFlag, "type4-preselect", "", "Comma-separated hex APDUs sent to Type 4 cards before selecting the NDEF application, e.g. a proprietary SELECT and PIN VERIFY")
+	flag.StringVar(&sigKeyFlag, "ntag-signature-key", "", "Hex secp128r1 public key (04 || X || Y) to check NTAG originality signatures against in getCardInfo")
+	flag.BoolVar(&magicProbeFlag, "magic-probe", false, "Check MIFARE Classic cards for the gen1a magic backdoor in getCardInfo (non-standard commands; PN53x readers such as the ACR122U)")
+	flag.IntVar(&eventLogFlag, "event-log-size", server.DefaultEventLogSize, "Number of recent log events served at /api/v1/events (0 to disable)")
 	flag.Parse()
 
 	// Handle --version flag
@@ -62,16 +154,97 @@ func main() {
 		os.Exit(0)
 	}
 
+	// Mirror log output into the event log before anything else is logged
+	var eventLog *server.EventLog
+	if eventLogFlag > 0 {
+		eventLog = server.NewEventLog(eventLogFlag)
+		log.SetOutput(io.MultiWriter(os.Stderr, eventLog))
+	}
+
 	log.Printf("Starting %s %s", buildinfo.Name, buildinfo.FullVersion())
 
+	cardDisplay, err := ParseCardDisplayFormat(trayCardFlag)
+	if err != nil {
+		log.Fatalf("Invalid -tray-card-display: %v", err)
+	}
+
+	dataDropPolicy, err := nfc.ParseDataDropPolicy(dataDropFlag)
+	if err != nil {
+		log.Fatalf("Invalid -data-drop-policy: %v", err)
+	}
+
+	deviceSelection, err := nfc.ParseDeviceSelection(deviceSelectFlag)
+	if err != nil {
+		log.Fatalf("Invalid -device-select: %v", err)
+	}
+
+	mainDevice, laneDevices, err := parseDeviceList(devicePathFlag)
+	if err != nil {
+		log.Fatalf("Invalid -device: %v", err)
+	}
+
+	blankCardPolicy, err := nfc.ParseBlankCardPolicy(blankCardsFlag)
+	if err != nil {
+		log.Fatalf("Invalid -blank-cards: %v", err)
+	}
+
+	if writeRateFlag < 0 || writeBurstFlag < 0 {
+		log.Fatalf("Invalid -write-rate-limit or -write-burst: must not be negative")
+	}
+
+	unsupportedPolicy, err := nfc.ParseUnsupportedTagPolicy(unsupportedFlag)
+	if err != nil {
+		log.Fatalf("Invalid -unsupported-tags: %v", err)
+	}
+
+	type4PreSelect, err := nfc.ParseAPDUList(type4PreFlag)
+	if err != nil {
+		log.Fatalf("Invalid -type4-preselect: %v", err)
+	}
+
+	var signatureKey *nfc.SignatureKey
+	if sigKeyFlag != "" {
+		signatureKey, err = nfc.ParseSignatureKey(sigKeyFlag)
+		if err != nil {
+			log.Fatalf("Invalid -ntag-signature-key: %v", err)
+		}
+	}
+
+	timestampFormat, err := server.ParseTimestampFormat(timestampFlag)
+	if err != nil {
+		log.Fatalf("Invalid -timestamp-format: %v", err)
+	}
+
+	writerDisconnect, err := clientserver.ParseWriterDisconnectPolicy(writerDiscFlag)
+	if err != nil {
+		log.Fatalf("Invalid -writer-disconnect: %v", err)
+	}
+
+	if compressFlag < 0 {
+		log.Fatalf("Invalid -compress-threshold: must not be negative")
+	}
+
+	var lineSink *linesink.Sink
+	if lineSinkFlag != "" {
+		lineFormat, err := linesink.ParseFormat(lineFormatFlag)
+		if err != nil {
+			log.Fatalf("Invalid -line-format: %v", err)
+		}
+		lineSink, err = linesink.New(lineSinkFlag, lineFormat)
+		if err != nil {
+			log.Fatalf("Invalid -line-sink: %v", err)
+		}
+		defer lineSink.Close()
+	}
+
+	configDir := configDirFlag
+	if configDir == "" {
+		configDir = getDefaultConfigDir()
+	}
+
 	// Initialize auto-TLS if enabled (and no manual cert/key provided)
 	var tlsMgr *tls.Manager
 	if autoTLSFlag && certFileFlag == "" && keyFileFlag == "" {
-		configDir := configDirFlag
-		if configDir == "" {
-			configDir = getDefaultConfigDir()
-		}
-
 		tlsMgr = tls.NewManager(configDir)
 		certFile, keyFile, err := tlsMgr.EnsureCertificates()
 		if err != nil {
@@ -94,10 +267,30 @@ func main() {
 
 	// Initialize smartphone manager
 	smartphoneManager := remotenfc.NewManager(30 * time.Second)
+	smartphoneManager.SetMaxDevices(maxRemoteFlag)
+
+	nfc.SetMaxNDEFRecords(maxRecordsFlag)
+
+	hardwareManager := nfc.NewManager()
+	if ec, ok := hardwareManager.(nfc.EnumerationConfigurer); ok {
+		ec.SetEnumerationRetry(enumRetriesFlag, enumDelayFlag)
+	}
+	if uc, ok := hardwareManager.(nfc.UnsupportedTagConfigurer); ok {
+		uc.SetUnsupportedTagPolicy(unsupportedPolicy)
+	}
+	if pc, ok := hardwareManager.(nfc.Type4PreSelectConfigurer); ok {
+		pc.SetType4PreSelect(type4PreSelect)
+	}
+	if mc, ok := hardwareManager.(nfc.MonitorStopConfigurer); ok {
+		mc.SetMonitorStopTimeout(monitorStopFlag)
+	}
+	if cc, ok := hardwareManager.(nfc.ClassicWriteDelayConfigurer); ok {
+		cc.SetClassicWriteDelay(classicDelayFlag)
+	}
 
 	// Create multi-manager combining hardware and smartphone
 	manager := multimanager.NewMultiManager(
-		multimanager.ManagerEntry{Name: nfc.ManagerTypeHardware, Manager: nfc.NewManager()},
+		multimanager.ManagerEntry{Name: nfc.ManagerTypeHardware, Manager: hardwareManager},
 		multimanager.ManagerEntry{Name: nfc.ManagerTypeSmartphone, Manager: smartphoneManager},
 	)
 
@@ -106,6 +299,50 @@ func main() {
 	agent.DevicePort = devicePortFlag
 	agent.ClientPort = clientPortFlag
 	agent.APISecret = apiSecretFlag
+	agent.SigningKey = signingKeyFlag
+	agent.DataDropPolicy = dataDropPolicy
+	agent.BlankCardPolicy = blankCardPolicy
+	agent.SignatureKey = signatureKey
+	agent.MagicProbe = magicProbeFlag
+	agent.ReaderOptions = nfc.ReaderOptions{DataBufferSize: dataBufferFlag, StatusBufferSize: statusBufferFlag, DeviceSelection: deviceSelection}
+	agent.TimestampFormat = timestampFormat
+	agent.WriterDisconnect = writerDisconnect
+	agent.CompressThreshold = compressFlag
+	agent.DebugCommands = debugCmdsFlag
+	agent.RedactAPDUs = redactAPDUsFlag
+	agent.DeviceWriteTimeout = deviceWriteFlag
+	agent.WriteRateLimit = writeRateFlag
+	agent.WriteBurst = writeBurstFlag
+	agent.IdleWithoutClients = idleFlag
+	agent.ExclusiveWriter = exclusiveFlag
+	agent.WriterControlsMode = writerModeFlag
+	agent.QueueBusyWrites = queueBusyFlag
+	agent.WipeTrailing = wipeTrailingFlag
+	agent.RefreshOnWriteRemoval = writeRefreshFlag
+	agent.RemovalGrace = removalGraceFlag
+	agent.RetapCooldown = retapCooldownFlag
+	agent.HardwareReset = hwResetFlag
+	agent.LineSink = lineSink
+	agent.MDNSName = mdnsNameFlag
+	agent.LaneDevices = laneDevices
+	configStore := store.NewFileStore(configDir)
+	if agentID, err := server.LoadOrCreateAgentID(configStore); err != nil {
+		log.Printf("Warning: mDNS id record disabled: %v", err)
+	} else {
+		agent.AgentID = agentID
+	}
+	if eventLog != nil {
+		agent.Events = eventLog
+		agent.Logger.SetOutput(log.Writer())
+	}
+	if wearStatsFlag {
+		wearTracker, err := nfc.NewWearTracker(configStore)
+		if err != nil {
+			log.Printf("Warning: Wear stats disabled: %v", err)
+		} else {
+			agent.WearTracker = wearTracker
+		}
+	}
 	agent.CertFile = certFileFlag
 	agent.KeyFile = keyFileFlag
 	agent.TLSManager = tlsMgr // For network change watching and cert regeneration
@@ -116,14 +353,37 @@ func main() {
 
 	go func() {
 		<-sigChan
+		log.Println("Shutting down...")
+
+		// Exit anyway if the shutdown hangs (e.g. on a wedged USB device) or a
+		// second signal arrives
+		go func() {
+			select {
+			case <-sigChan:
+				log.Println("Second signal received, exiting immediately")
+			case <-time.After(ShutdownTimeout + forceExitGrace):
+				log.Println("Shutdown did not finish in time, exiting")
+			}
+			os.Exit(1)
+		}()
+
 		if bootstrapServer != nil {
 			bootstrapServer.Stop()
 		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
+		defer cancel()
+		if err := agent.Shutdown(ctx); err != nil {
+			log.Printf("Shutdown incomplete: %v", err)
+			os.Exit(1)
+		}
 		systray.Quit()
 	}()
 
 	// Create and run systray app
-	app := NewSystrayApp(agent, devicePathFlag, bootstrapPortFlag)
+	app := NewSystrayApp(agent, mainDevice, bootstrapPortFlag)
+	app.CardDisplay = cardDisplay
+	app.CardTextMaxLen = trayTextMaxFlag
 	app.Run()
 }
 
@@ -137,3 +397,21 @@ func getDefaultConfigDir() string {
 	}
 	return filepath.Join(configDir, buildinfo.DirName)
 }
+
+// parseDeviceList splits a comma-separated -device value into the main device
+// and the lane devices opened next to it. A single name has no lanes.
+func parseDeviceList(value string) (string, []string, error) {
+	if !strings.Contains(value, ",") {
+		return strings.TrimSpace(value), nil, nil
+	}
+
+	var devices []string
+	for _, device := range strings.Split(value, ",") {
+		device = strings.TrimSpace(device)
+		if device == "" {
+			return "", nil, fmt.Errorf("empty device name in %q", value)
+		}
+		devices = append(devices, device)
+	}
+	return devices[0], devices[1:], nil
+}
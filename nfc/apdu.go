@@ -4,6 +4,7 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"strings"
 )
 
 // APDU status words
@@ -12,6 +13,7 @@ const (
 	SW2Success     = 0x00
 	SW1MoreData    = 0x61 // More data available
 	SW1WrongLength = 0x6C // Wrong Le field
+	SW1DESFire     = 0x91 // Wrapped DESFire native status, status code in SW2
 )
 
 // Common APDU command classes
@@ -33,6 +35,12 @@ const (
 	INSSelectFile  = 0xA4 // Select file
 )
 
+// ISO7816-4 instructions beyond SELECT and READ/UPDATE BINARY
+const (
+	INSReadRecord  = 0xB2 // Read record
+	INSGetResponse = 0xC0 // Get the rest of a response announced with SW1=61
+)
+
 // MIFARE key types
 const (
 	MIFAREKeyA = 0x60
@@ -103,6 +111,12 @@ func GetUIDAPDU() []byte {
 	return BuildAPDU(CLAPCSC, INSGetUID, 0x00, 0x00, nil, &le)
 }
 
+// GetATSAPDU returns the PC/SC GET DATA APDU for the card's ATS (ISO14443-4 cards only)
+func GetATSAPDU() []byte {
+	le := byte(0x00)
+	return BuildAPDU(CLAPCSC, INSGetUID, 0x01, 0x00, nil, &le)
+}
+
 // LoadKeyAPDU returns the APDU for loading a key into reader memory
 // keySlot: 0x00-0x1F for volatile, 0x20+ for non-volatile
 func LoadKeyAPDU(keySlot byte, key []byte) []byte {
@@ -155,6 +169,25 @@ func SelectFileByAIDAPDU(aid []byte) []byte {
 	return BuildAPDU(CLAStandard, INSSelectFile, 0x04, 0x00, aid, &le)
 }
 
+// SelectFileByFIDAPDU returns the APDU for selecting an EF or DF by its 2-byte
+// file identifier, returning the FCI
+func SelectFileByFIDAPDU(fid []byte) []byte {
+	le := byte(0x00)
+	return BuildAPDU(CLAStandard, INSSelectFile, 0x00, 0x00, fid, &le)
+}
+
+// ReadRecordAPDU returns the APDU for reading record recordNo of the EF with
+// short file identifier sfi (1-30), or of the current EF if sfi is 0
+func ReadRecordAPDU(recordNo, sfi byte) []byte {
+	le := byte(0x00)
+	return BuildAPDU(CLAStandard, INSReadRecord, recordNo, sfi<<3|0x04, nil, &le)
+}
+
+// GetResponseAPDU returns the APDU for fetching length bytes of a pending response
+func GetResponseAPDU(length byte) []byte {
+	return BuildAPDU(CLAStandard, INSGetResponse, 0x00, 0x00, nil, &length)
+}
+
 // ReadBinaryExtAPDU returns an extended APDU for reading with 2-byte offset
 func ReadBinaryExtAPDU(offset uint16, length byte) []byte {
 	p1 := byte((offset >> 8) & 0x7F) // High byte (bit 7 must be 0)
@@ -213,9 +246,16 @@ const (
 	DFCmdAuthenticateISO   = 0x1A // 3DES auth
 	DFCmdAuthenticateAES   = 0xAA // AES auth
 	DFCmdGetVersion        = 0x60
+	DFCmdFreeMem           = 0x6E
 	DFCmdAdditionalFrame   = 0xAF
 )
 
+// DESFire native status codes (SW2 when SW1 is SW1DESFire)
+const (
+	DFStatusOK              = 0x00
+	DFStatusAdditionalFrame = 0xAF
+)
+
 // DESFireSelectAppAPDU returns APDU for selecting a DESFire application
 func DESFireSelectAppAPDU(aid []byte) []byte {
 	if len(aid) != 3 {
@@ -273,6 +313,16 @@ func DESFireAdditionalFrameAPDU(data []byte) []byte {
 	return DESFireWrapAPDU(DFCmdAdditionalFrame, data)
 }
 
+// DESFireGetVersionAPDU returns APDU for the first frame of GetVersion
+func DESFireGetVersionAPDU() []byte {
+	return DESFireWrapAPDU(DFCmdGetVersion, nil)
+}
+
+// DESFireFreeMemAPDU returns APDU for reading the free EEPROM size
+func DESFireFreeMemAPDU() []byte {
+	return DESFireWrapAPDU(DFCmdFreeMem, nil)
+}
+
 // Utility functions
 
 // BytesToHex converts bytes to uppercase hex string
@@ -313,6 +363,32 @@ func HexToBytes(hex string) ([]byte, error) {
 	return result, nil
 }
 
+// ParseAPDUList parses a comma-separated list of hex APDUs, e.g.
+// "00A4040007A0000000031010,0020000004 31323334". Spaces inside an APDU are
+// ignored. An empty string yields an empty list.
+func ParseAPDUList(s string) ([][]byte, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+
+	var apdus [][]byte
+	for i, part := range strings.Split(s, ",") {
+		part = strings.ReplaceAll(strings.TrimSpace(part), " ", "")
+		if part == "" {
+			return nil, fmt.Errorf("APDU %d is empty", i+1)
+		}
+		apdu, err := HexToBytes(part)
+		if err != nil {
+			return nil, fmt.Errorf("APDU %d: %w", i+1, err)
+		}
+		if len(apdu) < 4 {
+			return nil, fmt.Errorf("APDU %d is shorter than a 4-byte header", i+1)
+		}
+		apdus = append(apdus, apdu)
+	}
+	return apdus, nil
+}
+
 // Uint16ToBytes converts uint16 to big-endian bytes
 func Uint16ToBytes(v uint16) []byte {
 	b := make([]byte, 2)
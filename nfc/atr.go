@@ -0,0 +1,137 @@
+package nfc
+
+import (
+	"fmt"
+)
+
+// CardATRProvider is an optional interface for devices that know the Answer
+// To Reset of the card they are connected to, such as PC/SC readers.
+type CardATRProvider interface {
+	// CardATR returns the ATR of the connected card, or nil without one.
+	CardATR() []byte
+}
+
+// ATRInterfaceByte is one interface byte of an ATR, e.g. TD1.
+type ATRInterfaceByte struct {
+	Name  string // "TA1", "TB1", "TC1", "TD1", "TA2", ...
+	Value byte
+}
+
+// ATRInfo is a decoded Answer To Reset (ISO/IEC 7816-3). For contactless
+// cards, PC/SC readers build the ATR themselves and put the card's standard
+// and name in the historical bytes (PC/SC Part 3); Standard and CardName
+// decode them and are empty for ATRs in another format.
+type ATRInfo struct {
+	TS             byte // Initial character: 3B (direct) or 3F (inverse convention)
+	T0             byte // Format byte: interface bytes present and historical byte count
+	InterfaceBytes []ATRInterfaceByte
+	Protocols      []int // Protocols announced by the TDi bytes, e.g. 1 for T=1
+	Historical     []byte
+	TCK            byte // Check byte, only present when HasTCK is set
+	HasTCK         bool
+	TCKValid       bool
+
+	Standard     string // e.g. "ISO 14443 A, part 3"
+	CardNameCode uint16 // PC/SC Part 3 card name, e.g. 0x0001
+	CardName     string // e.g. "MIFARE Classic 1K", empty for unknown codes
+}
+
+// atrStandards names the PC/SC Part 3 standard byte (SS) values.
+var atrStandards = map[byte]string{
+	0x01: "ISO 14443 A, part 1",
+	0x02: "ISO 14443 A, part 2",
+	0x03: "ISO 14443 A, part 3",
+	0x05: "ISO 14443 B, part 1",
+	0x06: "ISO 14443 B, part 2",
+	0x07: "ISO 14443 B, part 3",
+	0x09: "ISO 15693, part 1",
+	0x0A: "ISO 15693, part 2",
+	0x0B: "ISO 15693, part 3",
+	0x0C: "ISO 15693, part 4",
+	0x0D: "ISO 7816-10, I2C",
+	0x0E: "ISO 7816-10, extended I2C",
+	0x0F: "ISO 7816-10, 2WBP",
+	0x10: "ISO 7816-10, 3WBP",
+	0x11: "FeliCa",
+}
+
+// ParseATR decodes an ATR. A malformed ATR returns what could be decoded
+// before the problem together with an error.
+func ParseATR(atr []byte) (ATRInfo, error) {
+	var info ATRInfo
+	if len(atr) < 2 {
+		return info, fmt.Errorf("ATR must be at least 2 bytes, got %d", len(atr))
+	}
+	info.TS, info.T0 = atr[0], atr[1]
+	if info.TS != 0x3B && info.TS != 0x3F {
+		return info, fmt.Errorf("invalid initial character TS 0x%02X (want 3B or 3F)", info.TS)
+	}
+
+	pos := 2
+	td := info.T0
+	for i := 1; ; i++ {
+		for bit, name := range []string{"TA", "TB", "TC", "TD"} {
+			if td&(0x10<<bit) == 0 {
+				continue
+			}
+			if pos >= len(atr) {
+				return info, fmt.Errorf("ATR truncated in interface bytes (%s%d missing)", name, i)
+			}
+			info.InterfaceBytes = append(info.InterfaceBytes, ATRInterfaceByte{Name: fmt.Sprintf("%s%d", name, i), Value: atr[pos]})
+			pos++
+		}
+		if td&0x80 == 0 {
+			break
+		}
+		td = atr[pos-1]
+		info.Protocols = append(info.Protocols, int(td&0x0F))
+	}
+
+	count := int(info.T0 & 0x0F)
+	if pos+count > len(atr) {
+		return info, fmt.Errorf("ATR truncated: %d historical bytes announced, %d present", count, len(atr)-pos)
+	}
+	info.Historical = atr[pos : pos+count]
+	pos += count
+
+	// TCK is present unless only T=0 is indicated
+	for _, protocol := range info.Protocols {
+		if protocol != 0 {
+			info.HasTCK = true
+		}
+	}
+	if info.HasTCK {
+		if pos >= len(atr) {
+			return info, fmt.Errorf("ATR truncated: check byte TCK missing")
+		}
+		info.TCK = atr[pos]
+		var check byte
+		for _, b := range atr[1 : pos+1] {
+			check ^= b
+		}
+		info.TCKValid = check == 0
+	}
+
+	info.Standard, info.CardNameCode, info.CardName = decodePCSCCardName(info.Historical)
+	return info, nil
+}
+
+// decodePCSCCardName decodes the PC/SC Part 3 historical bytes of a
+// contactless card: 80 4F 0C A0 00 00 03 06 SS NN NN 00 00 00 00.
+func decodePCSCCardName(hist []byte) (standard string, code uint16, name string) {
+	if len(hist) < 11 || hist[0] != 0x80 || hist[1] != 0x4F ||
+		hist[3] != 0xA0 || hist[4] != 0x00 || hist[5] != 0x00 || hist[6] != 0x03 || hist[7] != 0x06 {
+		return "", 0, ""
+	}
+	standard = atrStandards[hist[8]]
+	if standard == "" {
+		standard = fmt.Sprintf("unknown (0x%02X)", hist[8])
+	}
+	code = uint16(hist[9])<<8 | uint16(hist[10])
+	if hist[9] == 0x00 {
+		if tagType, ok := atrPatterns[hist[10]]; ok {
+			name = detectedTypeName(tagType)
+		}
+	}
+	return standard, code, name
+}
@@ -0,0 +1,70 @@
+package nfc
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseATR(t *testing.T) {
+	t.Run("PC/SC contactless card", func(t *testing.T) {
+		atr := []byte{0x3B, 0x8F, 0x80, 0x01, 0x80, 0x4F, 0x0C, 0xA0, 0x00, 0x00, 0x03, 0x06, 0x03, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00, 0x6A}
+		info, err := ParseATR(atr)
+		if err != nil {
+			t.Fatalf("ParseATR() error = %v", err)
+		}
+		wantIB := []ATRInterfaceByte{{"TD1", 0x80}, {"TD2", 0x01}}
+		if !reflect.DeepEqual(info.InterfaceBytes, wantIB) {
+			t.Errorf("InterfaceBytes = %+v, want %+v", info.InterfaceBytes, wantIB)
+		}
+		if !reflect.DeepEqual(info.Protocols, []int{0, 1}) {
+			t.Errorf("Protocols = %v, want [0 1]", info.Protocols)
+		}
+		if !bytes.Equal(info.Historical, atr[4:19]) {
+			t.Errorf("Historical = % X, want % X", info.Historical, atr[4:19])
+		}
+		if !info.HasTCK || info.TCK != 0x6A || !info.TCKValid {
+			t.Errorf("TCK = %02X (present %v, valid %v), want valid 6A", info.TCK, info.HasTCK, info.TCKValid)
+		}
+		if info.Standard != "ISO 14443 A, part 3" || info.CardNameCode != 0x0001 || info.CardName != "MIFARE Classic 1K" {
+			t.Errorf("Card = %q / %04X / %q, want ISO 14443 A part 3 MIFARE Classic 1K", info.Standard, info.CardNameCode, info.CardName)
+		}
+	})
+
+	t.Run("ISO 14443-4 card", func(t *testing.T) {
+		info, err := ParseATR([]byte{0x3B, 0x81, 0x80, 0x01, 0x80, 0x80})
+		if err != nil {
+			t.Fatalf("ParseATR() error = %v", err)
+		}
+		if !bytes.Equal(info.Historical, []byte{0x80}) || !info.TCKValid {
+			t.Errorf("Historical = % X, TCK valid %v; want 80 and a valid TCK", info.Historical, info.TCKValid)
+		}
+		if info.Standard != "" || info.CardName != "" {
+			t.Errorf("Card = %q / %q, want no PC/SC card name", info.Standard, info.CardName)
+		}
+	})
+
+	t.Run("bad check byte", func(t *testing.T) {
+		info, err := ParseATR([]byte{0x3B, 0x81, 0x80, 0x01, 0x80, 0x81})
+		if err != nil || info.TCKValid {
+			t.Errorf("ParseATR() = TCK valid %v, error %v; want an invalid TCK", info.TCKValid, err)
+		}
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		info, err := ParseATR([]byte{0x3B, 0x8F, 0x80, 0x01, 0x80, 0x4F})
+		if err == nil || !strings.Contains(err.Error(), "historical bytes") {
+			t.Errorf("ParseATR() error = %v, want truncated historical bytes", err)
+		}
+		if len(info.InterfaceBytes) != 2 {
+			t.Errorf("InterfaceBytes = %+v, want the two decoded before the error", info.InterfaceBytes)
+		}
+	})
+
+	t.Run("invalid TS", func(t *testing.T) {
+		if _, err := ParseATR([]byte{0x00, 0x00}); err == nil {
+			t.Error("ParseATR() succeeded, want an error for TS 00")
+		}
+	})
+}
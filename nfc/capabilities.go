@@ -78,8 +78,13 @@ func GetDeviceCapabilities(device Device) DeviceCapabilities {
 	return BuildDeviceCapabilities(device)
 }
 
+// defaultSupportedTagTypes is reported for devices that do not implement
+// DeviceInfoProvider: the tag families the agent reads on any reader.
+var defaultSupportedTagTypes = []string{"MIFARE Classic", "DESFire", "Ultralight", "NTAG", "ISO14443-4"}
+
 // BuildDeviceCapabilities constructs a DeviceCapabilities struct by
-// checking which interfaces the device implements.
+// checking which interfaces the device implements. Devices that do not
+// implement DeviceInfoProvider report a best-effort list of tag types.
 func BuildDeviceCapabilities(device Device) DeviceCapabilities {
 	caps := DeviceCapabilities{
 		CanTransceive: true,  // Default true, will check for actual support
@@ -91,6 +96,8 @@ func BuildDeviceCapabilities(device Device) DeviceCapabilities {
 	if info, ok := device.(DeviceInfoProvider); ok {
 		caps.DeviceType = info.DeviceType()
 		caps.SupportedTagTypes = info.SupportedTagTypes()
+	} else {
+		caps.SupportedTagTypes = append([]string(nil), defaultSupportedTagTypes...)
 	}
 
 	// Check for event-based device (smartphone-style)
@@ -180,6 +187,20 @@ func InferTagCapabilities(tagType string) TagCapabilities {
 		caps.TagFamily = "Type 4"
 		caps.Technology = "ISO14443A"
 
+	case strings.Contains(tagTypeLower, "type5") || strings.Contains(tagTypeLower, "iso15693"):
+		caps.CanWrite = true
+		caps.CanTransceive = true
+		caps.CanLock = false // Not implemented
+		caps.TagFamily = "Type 5"
+		caps.Technology = "ISO15693"
+
+	case strings.Contains(tagTypeLower, "type1") || strings.Contains(tagTypeLower, "topaz"):
+		caps.CanWrite = true
+		caps.CanTransceive = true
+		caps.CanLock = false // Not implemented
+		caps.TagFamily = "Type 1"
+		caps.Technology = "ISO14443A"
+
 	default:
 		// Conservative defaults for unknown types
 		caps.CanWrite = false
@@ -1,6 +1,9 @@
 package nfc
 
-import "testing"
+import (
+	"slices"
+	"testing"
+)
 
 func TestInferTagCapabilities_MifareClassic1K(t *testing.T) {
 	caps := InferTagCapabilities("MIFARE Classic 1K")
@@ -127,6 +130,20 @@ func TestInferTagCapabilities_Type4(t *testing.T) {
 	}
 }
 
+func TestInferTagCapabilities_Type5(t *testing.T) {
+	caps := InferTagCapabilities(CardTypeType5)
+
+	if !caps.CanWrite {
+		t.Error("Expected CanWrite to be true")
+	}
+	if caps.TagFamily != "Type 5" {
+		t.Errorf("TagFamily = %q, want %q", caps.TagFamily, "Type 5")
+	}
+	if caps.Technology != "ISO15693" {
+		t.Errorf("Technology = %q, want %q", caps.Technology, "ISO15693")
+	}
+}
+
 func TestInferTagCapabilities_Unknown(t *testing.T) {
 	caps := InferTagCapabilities("SomeUnknownTag")
 
@@ -274,3 +291,19 @@ func TestGetDeviceCapabilities_Fallback(t *testing.T) {
 		t.Errorf("DeviceType = %q, want %q", caps.DeviceType, "mock")
 	}
 }
+
+func TestBuildDeviceCapabilities_DefaultTagTypes(t *testing.T) {
+	caps := BuildDeviceCapabilities(nil)
+	if caps.DeviceType != "unknown" {
+		t.Errorf("DeviceType = %q, want unknown", caps.DeviceType)
+	}
+	if !slices.Contains(caps.SupportedTagTypes, "DESFire") {
+		t.Errorf("SupportedTagTypes = %v, want the default list", caps.SupportedTagTypes)
+	}
+
+	// The default list is not shared between callers
+	caps.SupportedTagTypes[0] = "changed"
+	if BuildDeviceCapabilities(nil).SupportedTagTypes[0] == "changed" {
+		t.Error("Expected BuildDeviceCapabilities to return a copy of the defaults")
+	}
+}
@@ -1,6 +1,7 @@
 package nfc
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"strings"
@@ -35,10 +36,11 @@ type Card struct {
 	MessageData  Message   `json:"message_data,omitempty"` // Cached message data, if any
 
 	// Internal state for io.Reader
-	tag        Tag    // The underlying tag implementation
-	readBuffer []byte // Cached data from the tag
-	readOffset int    // Current read position
-	hasRead    bool   // Whether data has been loaded from tag
+	tag        Tag        // The underlying tag implementation
+	readBuffer []byte     // Cached data from the tag
+	readOffset int        // Current read position
+	hasRead    bool       // Whether data has been loaded from tag
+	layout     *TLVLayout // Layout the data was read from, if the tag reports one
 
 	// Internal state for io.Writer
 	writeBuffer []byte // Buffer for data to be written
@@ -70,6 +72,10 @@ func inferTechnology(tagType string) string {
 		return "ISO14443A/B"
 	case strings.Contains(tagType, "DESFire"):
 		return "ISO14443A"
+	case strings.Contains(tagType, "Type5"):
+		return "ISO15693"
+	case strings.Contains(tagType, "Type1"):
+		return "ISO14443A"
 	default:
 		return "Unknown"
 	}
@@ -89,7 +95,11 @@ func (c *Card) Read(p []byte) (n int, err error) {
 	// Lazy load: fetch data on first read
 	if !c.hasRead {
 		c.LastAccessed = time.Now()
-		c.readBuffer, err = c.tag.ReadData()
+		if lr, ok := c.tag.(LayoutReader); ok {
+			c.readBuffer, c.layout, err = lr.ReadDataWithLayout()
+		} else {
+			c.readBuffer, err = c.tag.ReadData()
+		}
 		if err != nil {
 			return 0, fmt.Errorf("failed to read from card %s: %w", c.UID, err)
 		}
@@ -142,12 +152,15 @@ func (c *Card) Close() error {
 	return c.Flush()
 }
 
-// Reset clears the read cache, allowing fresh data to be read from the card.
+// Reset clears the read cache and the cached message, allowing fresh data to
+// be read from the card.
 // Useful if you want to re-read after writing or if the card's data may have changed.
 func (c *Card) Reset() {
+	c.MessageData = nil
 	c.hasRead = false
 	c.readBuffer = nil
 	c.readOffset = 0
+	c.layout = nil
 }
 
 // preloadData sets the read buffer with pre-fetched data.
@@ -202,6 +215,29 @@ func (c *Card) ReadMessage() (Message, error) {
 	return textMsg, nil
 }
 
+// IsBlank reports whether the card holds no data: an NDEF message with only
+// empty records (TNF 0x00), as written by formatting, or no readable bytes at all. It reads the message if needed and
+// reports false when the read fails.
+func (c *Card) IsBlank() bool {
+	msg, err := c.ReadMessage()
+	if err != nil {
+		return false
+	}
+	switch m := msg.(type) {
+	case *NDEFMessage:
+		for _, record := range m.Records() {
+			if record.TNF != 0x00 {
+				return false
+			}
+		}
+		return true
+	case *TextMessage:
+		return len(bytes.Trim(m.Data, "\x00")) == 0
+	default:
+		return false
+	}
+}
+
 // WriteMessage encodes and writes a message to the card.
 //
 // Example:
@@ -2,14 +2,25 @@ package nfc
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 	"time"
 )
 
 // NFCData represents the data read from an NFC tag including any potential errors.
 type NFCData struct {
-	Card *Card // The detected card, nil if no card is present
-	Err  error // Error that occurred during detection/reading
+	Card     *Card  // The detected card, nil if no card is present
+	Err      error  // Error that occurred during detection/reading
+	ReaderID string // Reader that produced the event when merged by MultiReader, empty otherwise
+	Blank    bool   // Card holds no NDEF data; only set with the BlankCardsFlag policy
+
+	Unsupported *UnsupportedCard // Card the device cannot read; only set with the UnsupportedTagEvent policy
+}
+
+// UnsupportedCard identifies a card the device cannot read.
+type UnsupportedCard struct {
+	UID string // Card UID in hex, empty if the reader could not get it
+	ATR string // Card ATR in hex
 }
 
 // DeviceStatus represents the status of the NFC device.
@@ -18,16 +29,26 @@ type DeviceStatus struct {
 	Connected   bool
 	Message     string
 	CardPresent bool
+	Event       string `json:"event,omitempty"`    // Lifecycle event behind this update (e.g. DeviceStatusEventReconnected), empty for plain updates
+	Reason      string `json:"reason,omitempty"`   // Error category that triggered the event (see ErrorCategory)
+	ReaderID    string `json:"readerId,omitempty"` // Reader this status belongs to when merged by MultiReader
+
+	ReaderModel    string `json:",omitempty"` // Model of the connected reader, if known (see ReaderInfo)
+	ReaderFirmware string `json:",omitempty"` // Firmware version of the connected reader, if known
 }
 
+// DeviceStatusEventReconnected marks a status update sent after the device recovered from an error.
+const DeviceStatusEventReconnected = "reconnected"
+
 // Constants for NFC operations
 const (
 	MaxRetries          = 5
 	BaseDelay           = 500 * time.Millisecond
 	MaxReconnectTries   = 10
 	ReconnectDelay      = time.Second * 2
-	DeviceCheckInterval = time.Second * 2 // Interval to check for new devices
-	DeviceEnumRetries   = 3               // Number of retries for device enumeration
+	DeviceCheckInterval = time.Second * 2        // Interval to check for new devices
+	DeviceEnumRetries   = 3                      // Number of retries for device enumeration
+	DeviceEnumDelay     = 100 * time.Millisecond // Delay between device enumeration retries
 )
 
 // TagType represents the type of NFC tag as a string.
@@ -59,8 +80,75 @@ var (
 
 	// ErrACR122Specific indicates an ACR122-specific error requiring cooldown
 	ErrACR122Specific = errors.New("ACR122 device error")
+
+	// ErrCardRemovedDuringRead is returned to WaitForCard callers when a card
+	// was tapped but removed before it could be read completely
+	ErrCardRemovedDuringRead = errors.New("card removed during read")
+
+	// ErrWaitTimeout is returned by WaitForCard when no card is read in time
+	ErrWaitTimeout = errors.New("timed out waiting for card")
+
+	// ErrPageOutOfRange indicates a raw page access outside the tag's memory
+	ErrPageOutOfRange = errors.New("page out of range")
+
+	// ErrOperationInProgress is returned by ClearCache while a tag operation is running
+	ErrOperationInProgress = errors.New("tag operation in progress")
+
+	// ErrVerifyFailed indicates the data read back after a write did not match
+	ErrVerifyFailed = errors.New("write verification failed")
+
+	// ErrReadBackFailed indicates a write that succeeded but whose result
+	// could not be read back from the card
+	ErrReadBackFailed = errors.New("read back after write failed")
+
+	// ErrUIDMismatch indicates the card in the field is not the card a write
+	// was prepared for, e.g. because it was swapped mid-operation
+	ErrUIDMismatch = errors.New("tag UID mismatch")
+
+	// ErrCardTypeNotAllowed is reported once per card whose type is outside
+	// the reader's allowlist; such cards are not read
+	ErrCardTypeNotAllowed = errors.New("not allowed by filter")
+
+	// ErrDeviceBusy indicates no device is connected, e.g. while the reader
+	// reconnects, so an operation was refused instead of waiting for it
+	ErrDeviceBusy = errors.New("device busy")
+
+	// ErrHardwareResetDisabled is returned by ResetDevice unless hardware
+	// resets were enabled, since they need elevated permissions
+	ErrHardwareResetDisabled = errors.New("hardware reset disabled")
+
+	// ErrDeviceCooldown indicates the device is recovering from errors and
+	// refuses operations until its cooldown ends. Use errors.As with
+	// *CooldownError to get the remaining time.
+	ErrDeviceCooldown = errors.New("device in cooldown")
+
+	// ErrConnectAborted is returned when a connection attempt is cancelled
+	// by its stop channel before the device was opened
+	ErrConnectAborted = errors.New("device connection aborted")
+
+	// ErrCardFull indicates an NDEF message larger than the space available
+	// for it on the card
+	ErrCardFull = errors.New("card full")
+
+	// ErrTooManyRecords indicates an NDEF message with more records than
+	// MaxNDEFRecords allows, whether read from a card or being encoded
+	ErrTooManyRecords = errors.New("too many NDEF records")
 )
 
+// CooldownError is returned for operations attempted while the device is in
+// cooldown. It matches ErrDeviceCooldown with errors.Is.
+type CooldownError struct {
+	Remaining time.Duration // Time left until the cooldown ends
+}
+
+func (e *CooldownError) Error() string {
+	return fmt.Sprintf("%v, retry in %v", ErrDeviceCooldown, e.Remaining.Round(time.Second))
+}
+
+func (e *CooldownError) Is(target error) bool {
+	return target == ErrDeviceCooldown
+}
+
 // noCardError is returned when attempting to connect to a reader with no card present.
 // This is a normal condition for NFC readers and should not be treated as a device error.
 type noCardError struct {
@@ -92,7 +180,9 @@ func IsNoCardError(err error) bool {
 // unsupportedTagError is returned when a tag is present but its type is not supported.
 // This allows the system to wait for the card to be removed rather than retrying.
 type unsupportedTagError struct {
-	ATR string
+	ATR   string
+	UID   string
+	Event bool // Reader should also emit an UnsupportedCard event (UnsupportedTagEvent)
 }
 
 func (e *unsupportedTagError) Error() string {
@@ -104,6 +194,12 @@ func NewUnsupportedTagError(atr string) error {
 	return &unsupportedTagError{ATR: atr}
 }
 
+// NewUnsupportedTagEvent creates an unsupported tag error that also asks the
+// reader to emit an UnsupportedCard event for uid and atr.
+func NewUnsupportedTagEvent(uid, atr string) error {
+	return &unsupportedTagError{ATR: atr, UID: uid, Event: true}
+}
+
 // IsUnsupportedTagError checks if an error indicates the tag type is not supported.
 func IsUnsupportedTagError(err error) bool {
 	if err == nil {
@@ -211,6 +307,27 @@ func IsDeviceConfigError(err error) bool {
 		strings.Contains(errStr, "RDR_to_PC_DataBlock")
 }
 
+// ErrorCategory returns a short category name for a device error,
+// suitable for reporting why a reconnect happened.
+func ErrorCategory(err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case IsACR122Error(err):
+		return "acr122"
+	case IsIOError(err):
+		return "io"
+	case IsDeviceConfigError(err):
+		return "config"
+	case IsTimeoutError(err):
+		return "timeout"
+	case IsDeviceClosedError(err):
+		return "closed"
+	default:
+		return "unknown"
+	}
+}
+
 func IsACR122Error(err error) bool {
 	if err == nil {
 		return false
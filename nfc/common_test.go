@@ -280,3 +280,27 @@ func TestIsDeviceClosedError(t *testing.T) {
 		})
 	}
 }
+
+func TestErrorCategory(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{"nil error", nil, ""},
+		{"timeout", ErrTimeout, "timeout"},
+		{"wrapped IO", fmt.Errorf("read failed: %w", ErrIO), "io"},
+		{"ACR122 IO", fmt.Errorf("%w: %w", ErrIO, ErrACR122Specific), "acr122"},
+		{"device config", ErrDeviceConfig, "config"},
+		{"device closed", ErrDeviceClosed, "closed"},
+		{"unrelated", errors.New("connection lost"), "unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ErrorCategory(tt.err); got != tt.expected {
+				t.Errorf("ErrorCategory() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
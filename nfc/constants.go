@@ -16,6 +16,11 @@ const (
 	CardTypeNtag216          = "NTAG216"
 	CardTypeDesfire          = "DESFire"
 	CardTypeType4            = "Type4"
+	CardTypeType5            = "Type5"
+	CardTypeType1            = "Type1"
+
+	// CardTypeUnknown is reported for cards surfaced under UnsupportedTagRaw
+	CardTypeUnknown = "Unknown"
 )
 
 // MIFARE Classic key type constants for authentication
@@ -47,5 +52,7 @@ func GetAllCardTypes() []string {
 		CardTypeNtag216,
 		CardTypeDesfire,
 		CardTypeType4,
+		CardTypeType5,
+		CardTypeType1,
 	}
 }
@@ -0,0 +1,117 @@
+package nfc
+
+import "fmt"
+
+// desfireVersionLen is the length of a complete GetVersion response:
+// hardware(7) software(7) UID(7) batch number(5) production week(1) year(1).
+const desfireVersionLen = 28
+
+// DESFireVersionPart is the hardware or software half of a DESFire GetVersion response.
+type DESFireVersionPart struct {
+	VendorID byte // 0x04 for NXP
+	Type     byte
+	Subtype  byte
+	Major    byte
+	Minor    byte
+	Storage  byte // Encoded storage size, see StorageBytes
+	Protocol byte
+}
+
+// StorageBytes decodes the storage size byte: the upper seven bits are n in
+// 2^n bytes. When the lowest bit is set the size lies between 2^n and
+// 2^(n+1); the lower bound is returned.
+func (p DESFireVersionPart) StorageBytes() int {
+	return 1 << (p.Storage >> 1)
+}
+
+// DESFireVersion holds the decoded response to the DESFire GetVersion command.
+type DESFireVersion struct {
+	Hardware       DESFireVersionPart
+	Software       DESFireVersionPart
+	UID            []byte // 7-byte UID as reported by the card
+	BatchNo        []byte // 5-byte production batch number
+	ProductionWeek int    // Decoded from BCD
+	ProductionYear int    // Two-digit year, decoded from BCD
+}
+
+// ParseDESFireVersion decodes the concatenated frames of a GetVersion response.
+func ParseDESFireVersion(data []byte) (DESFireVersion, error) {
+	if len(data) < desfireVersionLen {
+		return DESFireVersion{}, fmt.Errorf("GetVersion response must be %d bytes, got %d", desfireVersionLen, len(data))
+	}
+
+	part := func(b []byte) DESFireVersionPart {
+		return DESFireVersionPart{
+			VendorID: b[0], Type: b[1], Subtype: b[2],
+			Major: b[3], Minor: b[4], Storage: b[5], Protocol: b[6],
+		}
+	}
+
+	return DESFireVersion{
+		Hardware:       part(data[0:7]),
+		Software:       part(data[7:14]),
+		UID:            data[14:21],
+		BatchNo:        data[21:26],
+		ProductionWeek: bcdToInt(data[26]),
+		ProductionYear: bcdToInt(data[27]),
+	}, nil
+}
+
+// bcdToInt decodes a two-digit packed BCD byte.
+func bcdToInt(b byte) int {
+	return int(b>>4)*10 + int(b&0x0F)
+}
+
+// Variant names the DESFire generation from the hardware type and major version.
+func (v DESFireVersion) Variant() string {
+	if v.Hardware.Type == 0x08 {
+		return "MIFARE DESFire Light"
+	}
+	switch v.Hardware.Major {
+	case 0x00:
+		return "MIFARE DESFire EV0"
+	case 0x01:
+		return "MIFARE DESFire EV1"
+	case 0x12:
+		return "MIFARE DESFire EV2"
+	case 0x22:
+		return "MIFARE DESFire EV2 XL"
+	case 0x33:
+		return "MIFARE DESFire EV3"
+	default:
+		return fmt.Sprintf("MIFARE DESFire (hardware %d.%d)", v.Hardware.Major, v.Hardware.Minor)
+	}
+}
+
+// DESFireInfo is what a DESFire card reports without authentication.
+type DESFireInfo struct {
+	Version    DESFireVersion
+	FreeMemory int // Free EEPROM bytes, or -1 if the card refused FreeMem
+}
+
+// CardInfo identifies the detected card.
+type CardInfo struct {
+	UID     string
+	Type    string
+	DESFire *DESFireInfo // Only set for DESFire cards
+
+	// Signature is the NTAG21x originality signature, nil for other cards
+	// or when the card refused READ_SIG.
+	Signature []byte
+
+	// Originality is OriginalityGenuine or OriginalityUnknown for NTAG21x
+	// cards when a SignatureKey is configured, empty otherwise.
+	Originality string
+
+	// Magic is the gen1a probe result for MIFARE Classic cards, nil unless
+	// the probe is enabled.
+	Magic *MagicInfo
+
+	// GPB is the general purpose byte of each MIFARE Classic sector, keyed by
+	// sector, for the trailers readable with the MAD, NFC Forum or factory
+	// key. Nil for other cards.
+	GPB map[int]byte
+
+	// Reader is the model and firmware of the reader the card was read on.
+	Reader ReaderInfo
+}
@@ -33,3 +33,24 @@ type Device interface {
 type DeviceHealthChecker interface {
 	IsHealthy() error
 }
+
+// APDUTracer is an optional interface for devices that can record the APDUs
+// they exchange with the card. SetTrace(nil) stops recording.
+type APDUTracer interface {
+	SetTrace(trace *APDUTrace)
+}
+
+// CardPresenceChecker is an optional interface for devices that can tell
+// whether a card is still in the field. The reader uses it to recognise a tag
+// that was reported by GetTags but left the field before it could be read.
+type CardPresenceChecker interface {
+	IsCardPresent() bool
+}
+
+// UIDReader is an optional interface for devices that can read the UID of
+// the card in the field from the card itself rather than from the UID cached
+// when it was detected. Writers use it to confirm the card was not swapped
+// before committing data.
+type UIDReader interface {
+	ReadUID() (string, error)
+}
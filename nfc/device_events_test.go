@@ -1,7 +1,9 @@
 package nfc
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 )
@@ -16,7 +18,7 @@ func TestDeviceManager_EmitsConnectedEvent(t *testing.T) {
 	eventChan := dm.Events()
 
 	// Connect to device
-	err := dm.TryConnect()
+	err := dm.TryConnect(nil)
 	if err != nil {
 		t.Fatalf("Expected successful connection, got error: %v", err)
 	}
@@ -48,7 +50,7 @@ func TestDeviceManager_EmitsCooldownStartedEvent(t *testing.T) {
 	dm := NewDeviceManager(mockManager, "mock:usb:001", fakeClock)
 
 	// Connect first
-	_ = dm.TryConnect()
+	_ = dm.TryConnect(nil)
 
 	// Drain initial connected event
 	<-dm.Events()
@@ -96,7 +98,7 @@ func TestDeviceManager_EmitsReconnectingEvent(t *testing.T) {
 	dm := NewDeviceManager(mockManager, "mock:usb:001", fakeClock)
 
 	// Connect first
-	_ = dm.TryConnect()
+	_ = dm.TryConnect(nil)
 
 	// Drain initial connected event
 	<-dm.Events()
@@ -132,6 +134,65 @@ func TestDeviceManager_EmitsReconnectingEvent(t *testing.T) {
 	}
 }
 
+// TestDeviceManager_EmitsReconnectedEvent tests that a successful error-triggered
+// reconnect emits DeviceReconnected with the error category as reason
+func TestDeviceManager_EmitsReconnectedEvent(t *testing.T) {
+	mockManager := NewMockManager()
+	fakeClock := NewFakeClock(time.Now())
+	dm := NewDeviceManager(mockManager, "mock:usb:001", fakeClock)
+
+	_ = dm.TryConnect(nil)
+	<-dm.Events()
+
+	stopChan := make(chan struct{})
+	defer close(stopChan)
+
+	go dm.HandleError(ErrTimeout, stopChan)
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		fakeClock.Advance(500 * time.Millisecond)
+	}()
+
+	timeout := time.After(500 * time.Millisecond)
+	for {
+		select {
+		case event := <-dm.Events():
+			if event.Type != DeviceReconnected {
+				continue
+			}
+			if event.Reason != "timeout" {
+				t.Errorf("Expected reason 'timeout', got %q", event.Reason)
+			}
+			return
+		case <-timeout:
+			t.Fatal("Timeout waiting for DeviceReconnected event")
+		}
+	}
+}
+
+// TestDeviceManager_ManualReconnectNoReconnectedEvent tests that Reconnect without
+// a preceding error does not report a recovery
+func TestDeviceManager_ManualReconnectNoReconnectedEvent(t *testing.T) {
+	mockManager := NewMockManager()
+	dm := NewDeviceManager(mockManager, "mock:usb:001", NewFakeClock(time.Now()))
+
+	if err := dm.Reconnect(nil); err != nil {
+		t.Fatalf("Reconnect() error = %v", err)
+	}
+
+	for {
+		select {
+		case event := <-dm.Events():
+			if event.Type == DeviceReconnected {
+				t.Fatal("Unexpected DeviceReconnected event for manual reconnect")
+			}
+		default:
+			return
+		}
+	}
+}
+
 // TestDeviceManager_EmitsCooldownEndedEvent tests that DeviceManager emits CooldownEnded
 func TestDeviceManager_EmitsCooldownEndedEvent(t *testing.T) {
 	mockManager := NewMockManager()
@@ -178,7 +239,7 @@ func TestDeviceManager_EmitsDisconnectedOnClose(t *testing.T) {
 	dm := NewDeviceManager(mockManager, "mock:usb:001", fakeClock)
 
 	// Connect first
-	_ = dm.TryConnect()
+	_ = dm.TryConnect(nil)
 
 	// Drain initial connected event
 	<-dm.Events()
@@ -213,6 +274,7 @@ func TestDeviceEventType_String(t *testing.T) {
 		{CooldownStarted, "CooldownStarted"},
 		{CooldownEnded, "CooldownEnded"},
 		{DeviceError, "DeviceError"},
+		{DeviceReconnected, "DeviceReconnected"},
 		{DeviceEventType(999), "Unknown(999)"},
 	}
 
@@ -304,3 +366,108 @@ func TestDeviceManager_UsesClockForTiming(t *testing.T) {
 		t.Error("Expected cooldown timer to be created")
 	}
 }
+
+// countCalls counts the occurrences of call in the manager's call log.
+func countCalls(m *MockManager, call string) int {
+	n := 0
+	for _, c := range m.GetCallLog() {
+		if c == call {
+			n++
+		}
+	}
+	return n
+}
+
+// TestDeviceManager_HardwareReset tests that the reader is reset only when
+// enabled, and automatically only after repeated cooldowns without a connect
+func TestDeviceManager_HardwareReset(t *testing.T) {
+	mockManager := NewMockManager()
+	dm := NewDeviceManager(mockManager, "mock:usb:001", NewFakeClock(time.Now()))
+	stopChan := make(chan struct{})
+	defer close(stopChan)
+	acr122Error := fmt.Errorf("%w: %w", ErrIO, ErrACR122Specific)
+	const reset = "ResetDevice(mock:usb:001)"
+
+	if err := dm.ResetDevice(); !errors.Is(err, ErrHardwareResetDisabled) {
+		t.Fatalf("Expected ErrHardwareResetDisabled, got %v", err)
+	}
+	dm.HandleError(acr122Error, stopChan)
+	dm.HandleError(acr122Error, stopChan)
+	if n := countCalls(mockManager, reset); n != 0 {
+		t.Fatalf("Reset %d times while disabled", n)
+	}
+
+	dm.SetHardwareReset(true)
+
+	// A connect in between means the reader is not wedged
+	_ = dm.TryConnect(nil)
+	dm.HandleError(acr122Error, stopChan)
+	_ = dm.TryConnect(nil)
+	dm.HandleError(acr122Error, stopChan)
+	if n := countCalls(mockManager, reset); n != 0 {
+		t.Fatalf("Reset %d times after recovered cooldowns", n)
+	}
+
+	dm.HandleError(acr122Error, stopChan)
+	if n := countCalls(mockManager, reset); n != 1 {
+		t.Fatalf("Expected a reset after %d cooldowns in a row, got %d", HardwareResetAfterCooldowns, n)
+	}
+
+	_ = dm.TryConnect(nil)
+	if err := dm.ResetDevice(); err != nil {
+		t.Fatalf("ResetDevice() error = %v", err)
+	}
+	if dm.HasDevice() {
+		t.Error("Expected ResetDevice() to close the device")
+	}
+	if n := countCalls(mockManager, reset); n != 2 {
+		t.Errorf("Expected ResetDevice() to reset the reader, got %d resets", n)
+	}
+
+	mockManager.ResetDeviceError = fmt.Errorf("permission denied")
+	if err := dm.ResetDevice(); err == nil || !strings.Contains(err.Error(), "permission denied") {
+		t.Errorf("Expected the reset error, got %v", err)
+	}
+}
+
+// TestDeviceManager_TryConnectAborted tests that closing the stop channel abandons a hung open
+func TestDeviceManager_TryConnectAborted(t *testing.T) {
+	mockManager := NewMockManager()
+	mockManager.OpenDeviceBlock = make(chan struct{})
+	dm := NewDeviceManager(mockManager, "mock:usb:001", NewFakeClock(time.Now()))
+
+	stopChan := make(chan struct{})
+	result := make(chan error, 1)
+	go func() {
+		result <- dm.TryConnect(stopChan)
+	}()
+	close(stopChan)
+
+	select {
+	case err := <-result:
+		if !errors.Is(err, ErrConnectAborted) {
+			t.Fatalf("Expected ErrConnectAborted, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("TryConnect did not return after stop")
+	}
+	if dm.HasDevice() {
+		t.Error("Expected no device after an aborted connect")
+	}
+
+	// The device opening late is closed rather than leaked
+	close(mockManager.OpenDeviceBlock)
+	deadline := time.Now().Add(time.Second)
+	for {
+		mockManager.MockDevice.mu.Lock()
+		open := mockManager.MockDevice.IsOpen
+		mockManager.MockDevice.mu.Unlock()
+		if !open {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the late device to be closed")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
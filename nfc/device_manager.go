@@ -1,6 +1,7 @@
 package nfc
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"math"
@@ -8,6 +9,11 @@ import (
 	"time"
 )
 
+// HardwareResetAfterCooldowns is how many cooldowns in a row, without a
+// successful connect in between, make the DeviceManager reset the reader
+// hardware when hardware resets are enabled.
+const HardwareResetAfterCooldowns = 2
+
 // DeviceEventType categorizes device lifecycle events
 type DeviceEventType int
 
@@ -32,6 +38,9 @@ const (
 
 	// DeviceError indicates a recoverable device error occurred
 	DeviceError
+
+	// DeviceReconnected indicates the device came back after an error-triggered reconnect
+	DeviceReconnected
 )
 
 // String returns the event type as a string
@@ -51,6 +60,8 @@ func (et DeviceEventType) String() string {
 		return "CooldownEnded"
 	case DeviceError:
 		return "DeviceError"
+	case DeviceReconnected:
+		return "DeviceReconnected"
 	default:
 		return fmt.Sprintf("Unknown(%d)", et)
 	}
@@ -63,6 +74,7 @@ type DeviceEvent struct {
 	Device    Device // nil if disconnected
 	Message   string // Human-readable description
 	Err       error  // Associated error, if any
+	Reason    string // Error category that triggered a reconnect (DeviceReconnected only)
 }
 
 // DeviceManager handles device lifecycle, connection management, and reconnection logic.
@@ -70,15 +82,22 @@ type DeviceEvent struct {
 type DeviceManager struct {
 	manager    Manager
 	device     Device
-	devicePath string
+	devicePath string // Path of the device last connected, or the configured path
+	pathHint   string // Configured path; "" selects a device by selection on every connect
+	selection  DeviceSelection
 	hasDevice  bool
 
 	// Reconnection state
 	retryCount    int           // Tracks retry attempts for timeout/closed errors
 	inCooldown    bool
+	cooldownUntil time.Time     // When the current cooldown ends
 	cooldownTimer Timer         // Timer interface for testability
 	clock         Clock         // Clock abstraction for time operations
 
+	// Hardware reset state
+	hardwareReset   bool // Reset the reader through the Manager's DeviceResetter
+	cooldownsInARow int  // Cooldowns since the last successful connect
+
 	// Event broadcasting
 	events   chan DeviceEvent // Buffered channel for device events
 	eventMux sync.RWMutex     // Protects event channel
@@ -106,6 +125,7 @@ func NewDeviceManager(manager Manager, devicePath string, clock Clock) *DeviceMa
 	return &DeviceManager{
 		manager:       manager,
 		devicePath:    devicePath,
+		pathHint:      devicePath,
 		hasDevice:     false,
 		clock:         clock,
 		cooldownTimer: timer,
@@ -123,6 +143,11 @@ func (dm *DeviceManager) Events() <-chan DeviceEvent {
 // emitEvent sends an event to the event channel without blocking.
 // If the channel is full, the event is dropped with a warning log.
 func (dm *DeviceManager) emitEvent(eventType DeviceEventType, message string, err error) {
+	dm.emitEventWithReason(eventType, message, err, "")
+}
+
+// emitEventWithReason is emitEvent with the error category that caused the event.
+func (dm *DeviceManager) emitEventWithReason(eventType DeviceEventType, message string, err error, reason string) {
 	dm.eventMux.RLock()
 	eventChan := dm.events
 	dm.eventMux.RUnlock()
@@ -141,6 +166,7 @@ func (dm *DeviceManager) emitEvent(eventType DeviceEventType, message string, er
 		Device:    device,
 		Message:   message,
 		Err:       err,
+		Reason:    reason,
 	}
 
 	select {
@@ -172,6 +198,17 @@ func (dm *DeviceManager) InCooldown() bool {
 	return dm.inCooldown
 }
 
+// CooldownRemaining returns the time left in the current cooldown period,
+// or 0 when the device manager is not in cooldown.
+func (dm *DeviceManager) CooldownRemaining() time.Duration {
+	dm.mu.RLock()
+	defer dm.mu.RUnlock()
+	if !dm.inCooldown {
+		return 0
+	}
+	return max(dm.cooldownUntil.Sub(dm.clock.Now()), 0)
+}
+
 // DevicePath returns the path of the device being managed.
 func (dm *DeviceManager) DevicePath() string {
 	dm.mu.RLock()
@@ -179,9 +216,19 @@ func (dm *DeviceManager) DevicePath() string {
 	return dm.devicePath
 }
 
+// SetDeviceSelection sets how a device is chosen when the DeviceManager was
+// created without a device path. It applies to the next connect or reconnect.
+func (dm *DeviceManager) SetDeviceSelection(selection DeviceSelection) {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	dm.selection = selection
+}
+
 // TryConnect attempts to connect to the device. If the device is already connected
 // and responsive, it returns nil. Otherwise, it attempts to open and initialize the device.
-func (dm *DeviceManager) TryConnect() error {
+// Closing stopChan abandons a pending enumeration or open with ErrConnectAborted;
+// a device that finishes opening afterwards is closed. A nil stopChan never aborts.
+func (dm *DeviceManager) TryConnect(stopChan <-chan struct{}) error {
 	dm.mu.Lock()
 	hasDev := dm.hasDevice
 	currentDevice := dm.device
@@ -208,28 +255,37 @@ func (dm *DeviceManager) TryConnect() error {
 		dm.mu.Unlock()
 	}
 
-	devicePathToConnect := dm.devicePath
-	if devicePathToConnect == "" {
-		devices, errList := dm.manager.ListDevices()
-		if errList != nil {
-			return fmt.Errorf("error listing NFC devices: %w", errList)
-		}
-		if len(devices) == 0 {
-			return fmt.Errorf("no NFC devices found by manager")
-		}
-		devicePathToConnect = devices[0]
-	}
+	// Enumeration and open can block inside the driver, so run them aside and
+	// give up on them if stopChan closes first
+	dm.mu.RLock()
+	pathHint, selection := dm.pathHint, dm.selection
+	dm.mu.RUnlock()
+	resultChan := make(chan openResult, 1)
+	go func() {
+		resultChan <- dm.openDevice(pathHint, selection)
+	}()
 
-	newDevice, errOpen := dm.manager.OpenDevice(devicePathToConnect)
-	if errOpen != nil {
-		return fmt.Errorf("failed to open device %s: %w", devicePathToConnect, errOpen)
+	var result openResult
+	select {
+	case result = <-resultChan:
+	case <-stopChan:
+		go func() {
+			if late := <-resultChan; late.err == nil {
+				late.device.Close()
+			}
+		}()
+		return ErrConnectAborted
 	}
-	// Note: Device initialization is handled inside OpenDevice()
+	if result.err != nil {
+		return result.err
+	}
+	newDevice, devicePathToConnect := result.device, result.path
 
 	dm.mu.Lock()
 	dm.device = newDevice
 	dm.hasDevice = true
 	dm.devicePath = devicePathToConnect
+	dm.cooldownsInARow = 0
 	dm.mu.Unlock()
 
 	log.Printf("Successfully connected to device: %s", newDevice.String())
@@ -237,6 +293,36 @@ func (dm *DeviceManager) TryConnect() error {
 	return nil
 }
 
+// openResult is the outcome of openDevice.
+type openResult struct {
+	device Device
+	path   string
+	err    error
+}
+
+// openDevice opens devicePath, or the device selection picks from those the
+// manager lists when devicePath is empty.
+func (dm *DeviceManager) openDevice(devicePath string, selection DeviceSelection) openResult {
+	if devicePath == "" {
+		devices, errList := dm.manager.ListDevices()
+		if errList != nil {
+			return openResult{err: fmt.Errorf("error listing NFC devices: %w", errList)}
+		}
+		selected, errSelect := selection.Select(devices)
+		if errSelect != nil {
+			return openResult{err: errSelect}
+		}
+		devicePath = selected
+	}
+
+	newDevice, errOpen := dm.manager.OpenDevice(devicePath)
+	if errOpen != nil {
+		return openResult{err: fmt.Errorf("failed to open device %s: %w", devicePath, errOpen)}
+	}
+	// Note: Device initialization is handled inside OpenDevice()
+	return openResult{device: newDevice, path: devicePath}
+}
+
 // EnsureConnected ensures the device is connected and responsive.
 // If not connected, attempts to connect. If in cooldown, returns an error.
 // This method manages internal retry state for the device manager.
@@ -250,7 +336,7 @@ func (dm *DeviceManager) EnsureConnected(stopChan <-chan struct{}) error {
 	}
 
 	// Try to connect if not already connected
-	err := dm.TryConnect()
+	err := dm.TryConnect(stopChan)
 	if err == nil {
 		// Success - reset retry count
 		dm.mu.Lock()
@@ -258,6 +344,9 @@ func (dm *DeviceManager) EnsureConnected(stopChan <-chan struct{}) error {
 		dm.mu.Unlock()
 		return nil
 	}
+	if errors.Is(err, ErrConnectAborted) {
+		return err
+	}
 
 	// Connection failed - handle the error using the existing error handling logic
 	needsCooldown := dm.HandleError(err, stopChan)
@@ -279,16 +368,18 @@ func (dm *DeviceManager) EnsureConnected(stopChan <-chan struct{}) error {
 
 // Reconnect attempts to reconnect to the device with exponential backoff.
 func (dm *DeviceManager) Reconnect(stopChan <-chan struct{}) error {
-	return dm.reconnectDevice(false, stopChan)
+	return dm.reconnectDevice(false, "", stopChan)
 }
 
 // ForceReconnect attempts to force reconnect with device reset wait time.
 func (dm *DeviceManager) ForceReconnect(stopChan <-chan struct{}) error {
-	return dm.reconnectDevice(true, stopChan)
+	return dm.reconnectDevice(true, "", stopChan)
 }
 
 // reconnectDevice attempts to reconnect to the NFC device with configurable retry logic.
-func (dm *DeviceManager) reconnectDevice(forceMode bool, stopChan <-chan struct{}) error {
+// reason is the error category that triggered the reconnect; when set, a successful
+// reconnect emits DeviceReconnected so clients can tell a recovery from a first connect.
+func (dm *DeviceManager) reconnectDevice(forceMode bool, reason string, stopChan <-chan struct{}) error {
 	logPrefix := "Reconnect"
 	maxAttempts := MaxReconnectTries
 	if forceMode {
@@ -321,11 +412,18 @@ func (dm *DeviceManager) reconnectDevice(forceMode bool, stopChan <-chan struct{
 
 	var lastErr error
 	for attempt := 1; attempt <= maxAttempts; attempt++ {
-		connectErr := dm.TryConnect()
+		connectErr := dm.TryConnect(stopChan)
 		if connectErr == nil {
 			log.Printf("%s: Attempt %d successful.", logPrefix, attempt)
+			if reason != "" {
+				dm.emitEventWithReason(DeviceReconnected, fmt.Sprintf("Reader recovered after %s error", reason), nil, reason)
+			}
 			return nil
 		}
+		if errors.Is(connectErr, ErrConnectAborted) {
+			log.Printf("%s: Stop signal received during attempt %d, aborting.", logPrefix, attempt)
+			return connectErr
+		}
 
 		lastErr = connectErr
 		log.Printf("%s: Attempt %d failed: %v", logPrefix, attempt, connectErr)
@@ -399,17 +497,19 @@ func (dm *DeviceManager) HandleError(err error, stopChan <-chan struct{}) (needs
 			dm.mu.Lock()
 			if !dm.inCooldown {
 				dm.inCooldown = true
+				dm.cooldownUntil = dm.clock.Now().Add(DeviceErrorCooldownPeriod)
 				log.Printf("ACR122-like error. Entering cooldown for %v", DeviceErrorCooldownPeriod)
 				dm.cooldownTimer.Reset(DeviceErrorCooldownPeriod)
 			}
 			dm.mu.Unlock()
 			dm.emitEvent(CooldownStarted, fmt.Sprintf("Entering cooldown for %v", DeviceErrorCooldownPeriod), err)
+			dm.resetIfWedged()
 			return true
 		}
 
 		log.Println("Attempting force reconnect after IO/Config error...")
 		dm.clock.Sleep(PostErrorPauseTime)
-		if errReconnect := dm.ForceReconnect(stopChan); errReconnect != nil {
+		if errReconnect := dm.reconnectDevice(true, ErrorCategory(err), stopChan); errReconnect != nil {
 			log.Printf("Force reconnection failed after IO/Config error: %v", errReconnect)
 		}
 		return false
@@ -438,7 +538,7 @@ func (dm *DeviceManager) HandleError(err error, stopChan <-chan struct{}) (needs
 			case <-stopChan:
 				return false
 			}
-			if errReconnect := dm.Reconnect(stopChan); errReconnect != nil {
+			if errReconnect := dm.reconnectDevice(false, ErrorCategory(err), stopChan); errReconnect != nil {
 				log.Printf("Device reconnection failed: %v", errReconnect)
 				dm.emitEvent(DeviceReconnectFailed, fmt.Sprintf("Reconnection attempt %d failed", newRetry), errReconnect)
 			} else {
@@ -458,11 +558,13 @@ func (dm *DeviceManager) HandleError(err error, stopChan <-chan struct{}) (needs
 			dm.retryCount = 0 // Reset retry count when entering cooldown
 			if !dm.inCooldown {
 				dm.inCooldown = true
+				dm.cooldownUntil = dm.clock.Now().Add(MaxRetriesCooldownPeriod)
 				dm.cooldownTimer.Reset(MaxRetriesCooldownPeriod)
 				log.Println("Entering long cooldown after max retries for Timeout/Closed error.")
 			}
 			dm.mu.Unlock()
 			dm.emitEvent(CooldownStarted, "Max retries reached, entering cooldown", err)
+			dm.resetIfWedged()
 			return true
 		}
 		return false
@@ -472,6 +574,71 @@ func (dm *DeviceManager) HandleError(err error, stopChan <-chan struct{}) (needs
 	return false
 }
 
+// SetHardwareReset enables resetting the reader hardware, e.g. with a USB port
+// reset on Linux, through the Manager's DeviceResetter. When enabled, the
+// reader is reset after HardwareResetAfterCooldowns cooldowns in a row, and
+// ResetDevice may be called. It is off by default because the reset needs
+// elevated permissions and briefly removes the reader from the system.
+func (dm *DeviceManager) SetHardwareReset(enabled bool) {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	dm.hardwareReset = enabled
+}
+
+// ResetDevice closes the device and resets the reader hardware. The device is
+// not reopened here; the next EnsureConnected finds it once the reader is
+// back. Returns ErrHardwareResetDisabled unless SetHardwareReset(true) was
+// called, or a not-supported error if the Manager cannot reset devices.
+func (dm *DeviceManager) ResetDevice() error {
+	dm.mu.RLock()
+	enabled := dm.hardwareReset
+	dm.mu.RUnlock()
+	if !enabled {
+		return ErrHardwareResetDisabled
+	}
+
+	dm.Close()
+	return dm.resetHardware()
+}
+
+// resetIfWedged counts a cooldown and resets the reader hardware once the
+// reader has gone into cooldown HardwareResetAfterCooldowns times without
+// connecting in between, the usual sign of a wedged reader.
+func (dm *DeviceManager) resetIfWedged() {
+	dm.mu.Lock()
+	dm.cooldownsInARow++
+	wedged := dm.hardwareReset && dm.cooldownsInARow >= HardwareResetAfterCooldowns
+	dm.mu.Unlock()
+
+	if !wedged {
+		return
+	}
+	log.Printf("Reader entered cooldown %d times in a row, resetting hardware", HardwareResetAfterCooldowns)
+	if err := dm.resetHardware(); err != nil {
+		log.Printf("Hardware reset failed: %v", err)
+	}
+}
+
+// resetHardware resets the reader through the Manager's DeviceResetter.
+func (dm *DeviceManager) resetHardware() error {
+	resetter, ok := dm.manager.(DeviceResetter)
+	if !ok {
+		return NewNotSupportedError("ResetDevice")
+	}
+
+	dm.mu.Lock()
+	devicePath := dm.devicePath
+	dm.cooldownsInARow = 0
+	dm.mu.Unlock()
+
+	if err := resetter.ResetDevice(devicePath); err != nil {
+		return fmt.Errorf("failed to reset device %s: %w", devicePath, err)
+	}
+	log.Printf("Device %s reset", devicePath)
+	dm.emitEvent(DeviceDisconnected, "Reader hardware reset", nil)
+	return nil
+}
+
 // EndCooldown ends the current cooldown period and attempts to reconnect.
 func (dm *DeviceManager) EndCooldown(stopChan <-chan struct{}) {
 	log.Println("Device cooldown period ended.")
@@ -479,7 +646,7 @@ func (dm *DeviceManager) EndCooldown(stopChan <-chan struct{}) {
 	dm.inCooldown = false
 	dm.mu.Unlock()
 	dm.emitEvent(CooldownEnded, "Cooldown period ended, attempting reconnect", nil)
-	if err := dm.ForceReconnect(stopChan); err != nil {
+	if err := dm.reconnectDevice(true, "cooldown", stopChan); err != nil {
 		log.Printf("Reconnection after cooldown failed: %v.", err)
 	}
 }
@@ -66,6 +66,9 @@ type MockDevice struct {
 	// MockSupportsEvents makes the device report as event-based (like smartphone)
 	MockSupportsEvents bool
 
+	// CardAbsent makes IsCardPresent() report that the card left the field
+	CardAbsent bool
+
 	mu sync.Mutex
 }
 
@@ -108,6 +111,15 @@ func (m *MockDevice) IsHealthy() error {
 	return m.InitError
 }
 
+// IsCardPresent reports whether a card is in the field (implements CardPresenceChecker).
+func (m *MockDevice) IsCardPresent() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.CallLog = append(m.CallLog, "IsCardPresent")
+	return !m.CardAbsent
+}
+
 // String returns the simulated device name.
 func (m *MockDevice) String() string {
 	m.mu.Lock()
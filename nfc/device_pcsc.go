@@ -6,6 +6,7 @@ import (
 	"log"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/ebfe/scard"
 )
@@ -13,7 +14,7 @@ import (
 // pcscDevice implements Device using PC/SC via ebfe/scard
 type pcscDevice struct {
 	ctx        *scard.Context
-	card       *scard.Card
+	card       scardTransmitter
 	readerName string
 	uid        string
 	atr        []byte
@@ -24,25 +25,47 @@ type pcscDevice struct {
 	lastEventCount uint16          // Event counter from upper 16 bits of EventState
 
 	// Background monitoring for card removal
-	stopMonitor chan struct{} // Signals the monitor goroutine to stop
-	cardRemoved chan struct{} // Signals that card was removed (detected by monitor)
+	stopMonitor        chan struct{} // Signals the monitor goroutine to stop
+	monitorDone        chan struct{} // Closed when the monitor goroutine has returned
+	monitorCtx         statusWaiter  // Context the monitor blocks on; cancelled to stop it
+	monitorStopTimeout time.Duration // How long Close waits for the monitor to return
+	cardRemoved        chan struct{} // Signals that card was removed (detected by monitor)
 
 	// Tracks if unsupported tag error was already reported for current card
 	unsupportedReported bool
+	unsupportedPolicy   UnsupportedTagPolicy
+
+	// APDUs sent to Type 4 tags before selecting the NDEF application
+	type4PreSelect [][]byte
+
+	// Pause between block writes to Classic cards, for clones that drop
+	// back-to-back writes
+	classicWriteDelay time.Duration
+
+	// Records Transceive exchanges while set (protected by mu)
+	trace *APDUTrace
+
+	// Reader model and firmware, queried once on connect
+	readerInfo ReaderInfo
+
+	// Removal signals held back while a transaction is open (protected by txMu)
+	txMu            sync.Mutex
+	inTransaction   bool
+	removalDeferred bool
 }
 
 // newPCSCDevice creates a new PC/SC device from a connected card
-func newPCSCDevice(ctx *scard.Context, card *scard.Card, readerName string) (*pcscDevice, error) {
+func newPCSCDevice(ctx *scard.Context, card *scard.Card, readerName string, unsupportedPolicy UnsupportedTagPolicy) (*pcscDevice, error) {
 	// Validate protocol before any operations - the scard library panics on invalid protocol
-	proto := card.ActiveProtocol()
-	if proto != scard.ProtocolT0 && proto != scard.ProtocolT1 {
+	if proto := card.ActiveProtocol(); !isValidProtocol(proto) {
 		return nil, fmt.Errorf("unsupported card protocol: %d", proto)
 	}
 
 	dev := &pcscDevice{
-		ctx:        ctx,
-		card:       card,
-		readerName: readerName,
+		ctx:               ctx,
+		card:              card,
+		readerName:        readerName,
+		unsupportedPolicy: unsupportedPolicy,
 	}
 
 	// Get card status to retrieve ATR
@@ -62,6 +85,11 @@ func newPCSCDevice(ctx *scard.Context, card *scard.Card, readerName string) (*pc
 		dev.lastEventCount = uint16(readerStates[0].EventState >> 16)
 	}
 
+	dev.readerInfo = queryReaderInfo(card, readerName)
+	if dev.readerInfo.Firmware != "" {
+		log.Printf("Reader %s firmware: %s", readerName, dev.readerInfo.Firmware)
+	}
+
 	// Get UID
 	uid, err := dev.getUID()
 	if err != nil {
@@ -70,8 +98,21 @@ func newPCSCDevice(ctx *scard.Context, card *scard.Card, readerName string) (*pc
 		dev.uid = uid
 	}
 
+	// The monitor blocks on a context of its own, so cancelling it on Close
+	// does not interrupt other calls on the manager's context
+	monitorCtx, err := scard.EstablishContext()
+	if err != nil {
+		log.Printf("Warning: could not create card monitor context: %v", err)
+	} else {
+		dev.monitorCtx = monitorCtx
+	}
+
 	// Start background card removal monitor
-	dev.startCardMonitor()
+	dev.startCardMonitor(func() {
+		if monitorCtx != nil {
+			monitorCtx.Release()
+		}
+	})
 
 	return dev, nil
 }
@@ -91,19 +132,45 @@ func (d *pcscDevice) Close() error {
 	return nil
 }
 
+// DefaultMonitorStopTimeout is how long closing a PC/SC device waits for
+// its card monitor to return after cancelling it.
+const DefaultMonitorStopTimeout = time.Second
+
+// cardMonitorPoll is how long the card monitor blocks in one GetStatusChange
+// call. Stopping cancels the call, so this only bounds the wait on readers
+// whose context cannot be cancelled.
+const cardMonitorPoll = 500 * time.Millisecond
+
+// statusWaiter is the subset of *scard.Context the card monitor blocks on,
+// so tests can stand in for a reader.
+type statusWaiter interface {
+	GetStatusChange(readerStates []scard.ReaderState, timeout time.Duration) error
+	Cancel() error
+}
+
 // startCardMonitor starts a background goroutine that monitors for card removal.
 // This provides the most reliable detection by continuously checking card state.
-func (d *pcscDevice) startCardMonitor() {
+// The monitor blocks on monitorCtx, falling back to the shared context (which
+// is not cancelled) when there is none; release runs when it returns.
+func (d *pcscDevice) startCardMonitor(release func()) {
 	d.stopMonitor = make(chan struct{})
+	d.monitorDone = make(chan struct{})
 	d.cardRemoved = make(chan struct{}, 1)
 
 	go func() {
+		defer close(d.monitorDone)
+		defer release()
+
 		// Start with current state
 		d.mu.Lock()
 		readerStates := []scard.ReaderState{
 			{Reader: d.readerName, CurrentState: d.lastEventState},
 		}
-		ctx := d.ctx
+		var ctx statusWaiter = d.monitorCtx
+		if ctx == nil && d.ctx != nil {
+			ctx = d.ctx
+		}
+		stop := d.stopMonitor
 		d.mu.Unlock()
 
 		if ctx == nil {
@@ -112,14 +179,12 @@ func (d *pcscDevice) startCardMonitor() {
 
 		for {
 			select {
-			case <-d.stopMonitor:
+			case <-stop:
 				return
 			default:
 			}
 
-			// Block for up to 500ms waiting for state change
-			// Timeout allows checking stopMonitor channel periodically
-			err := ctx.GetStatusChange(readerStates, 500)
+			err := ctx.GetStatusChange(readerStates, cardMonitorPoll)
 			if err != nil {
 				// Check if context was cancelled
 				if errors.Is(err, scard.ErrCancelled) {
@@ -132,10 +197,7 @@ func (d *pcscDevice) startCardMonitor() {
 				}
 				// Other errors may indicate reader disconnection
 				log.Printf("cardMonitor: error %v, treating as removal", err)
-				select {
-				case d.cardRemoved <- struct{}{}:
-				default:
-				}
+				d.signalRemoval()
 				return
 			}
 
@@ -144,10 +206,7 @@ func (d *pcscDevice) startCardMonitor() {
 			// Check if card was removed - only use StateEmpty as the definitive indicator
 			// StatePresent being absent during transitions can cause false positives
 			if (eventState & scard.StateEmpty) != 0 {
-				select {
-				case d.cardRemoved <- struct{}{}:
-				default:
-				}
+				d.signalRemoval()
 				return
 			}
 
@@ -157,11 +216,73 @@ func (d *pcscDevice) startCardMonitor() {
 	}()
 }
 
-// stopCardMonitor stops the background card removal monitor
+// signalRemoval reports a removal seen by the monitor to the next Transceive
+// or GetTags. During a transaction the signal is held back until
+// EndTransaction, so a chained command sequence is not cut short by a
+// transient empty state; a card that is really gone fails the next transmit.
+func (d *pcscDevice) signalRemoval() {
+	d.txMu.Lock()
+	defer d.txMu.Unlock()
+	if d.inTransaction {
+		d.removalDeferred = true
+		return
+	}
+	select {
+	case d.cardRemoved <- struct{}{}:
+	default:
+	}
+}
+
+// BeginTransaction holds back monitor removal signals until EndTransaction
+// (implements TransactionGuard).
+func (d *pcscDevice) BeginTransaction() {
+	d.txMu.Lock()
+	d.inTransaction = true
+	d.txMu.Unlock()
+}
+
+// EndTransaction delivers a removal signal held back since BeginTransaction
+// (implements TransactionGuard).
+func (d *pcscDevice) EndTransaction() {
+	d.txMu.Lock()
+	deferred := d.removalDeferred
+	d.inTransaction = false
+	d.removalDeferred = false
+	d.txMu.Unlock()
+	if deferred {
+		d.signalRemoval()
+	}
+}
+
+// stopCardMonitor stops the background card removal monitor. It cancels the
+// monitor's blocking GetStatusChange and waits, up to monitorStopTimeout, for
+// the monitor to return.
 func (d *pcscDevice) stopCardMonitor() {
-	if d.stopMonitor != nil {
-		close(d.stopMonitor)
-		d.stopMonitor = nil
+	if d.stopMonitor == nil {
+		return
+	}
+	close(d.stopMonitor)
+	d.stopMonitor = nil
+
+	select {
+	case <-d.monitorDone:
+		return // Already returned after a removal; its context is released
+	default:
+	}
+	if d.monitorCtx != nil {
+		if err := d.monitorCtx.Cancel(); err != nil {
+			log.Printf("cardMonitor: cancel failed: %v", err)
+		}
+	}
+
+	timeout := d.monitorStopTimeout
+	if timeout <= 0 {
+		timeout = DefaultMonitorStopTimeout
+	}
+	select {
+	case <-d.monitorDone:
+	case <-time.After(timeout):
+		log.Printf("cardMonitor: did not stop within %v", timeout)
 	}
 }
 
@@ -173,6 +294,16 @@ func (d *pcscDevice) Connection() string {
 	return d.readerName
 }
 
+// ReaderInfo returns the reader model and firmware (implements ReaderInfoProvider)
+func (d *pcscDevice) ReaderInfo() ReaderInfo {
+	return d.readerInfo
+}
+
+// CardATR returns the ATR of the connected card (implements CardATRProvider)
+func (d *pcscDevice) CardATR() []byte {
+	return append([]byte(nil), d.atr...)
+}
+
 // DeviceType returns the device type identifier (implements DeviceInfoProvider)
 func (d *pcscDevice) DeviceType() string {
 	return "pcsc"
@@ -180,7 +311,7 @@ func (d *pcscDevice) DeviceType() string {
 
 // SupportedTagTypes returns the list of supported tag types (implements DeviceInfoProvider)
 func (d *pcscDevice) SupportedTagTypes() []string {
-	return []string{"MIFARE Classic", "DESFire", "Ultralight", "NTAG", "ISO14443-4"}
+	return []string{"MIFARE Classic", "DESFire", "Ultralight", "NTAG", "ISO14443-4", "ISO15693", "Topaz"}
 }
 
 // IsHealthy checks if the device is still connected (implements DeviceHealthChecker)
@@ -219,8 +350,11 @@ func (d *pcscDevice) checkCardPresence() error {
 	// Send GET_UID command - this is a simple command that should always succeed
 	// if a card is present. If it fails, the card was likely removed.
 	cmd := GetUIDAPDU()
-	resp, err := d.card.Transmit(cmd)
+	resp, err := safeTransmit(d.card, cmd)
 	if err != nil {
+		if IsCardRemovedError(err) {
+			return err
+		}
 		if isCardRemovedPCSCError(err) {
 			return NewCardRemovedError(err)
 		}
@@ -260,16 +394,17 @@ func (d *pcscDevice) Transceive(txData []byte) ([]byte, error) {
 		return nil, NewCardRemovedError(fmt.Errorf("device not connected"))
 	}
 
-	// Validate protocol before transmit - the scard library panics on invalid protocol
-	proto := d.card.ActiveProtocol()
-	if proto != scard.ProtocolT0 && proto != scard.ProtocolT1 {
-		return nil, NewCardRemovedError(fmt.Errorf("invalid card protocol"))
-	}
-
 	// Transmit APDU - let transmit errors indicate card removal
-	rxData, err := d.card.Transmit(txData)
+	start := time.Now()
+	rxData, err := safeTransmit(d.card, txData)
+	if d.trace != nil {
+		d.trace.Record(redactAPDU(txData), rxData, err, time.Since(start))
+	}
 	if err != nil {
 		// Check if this is a card removal error
+		if IsCardRemovedError(err) {
+			return nil, err
+		}
 		if isCardRemovedPCSCError(err) {
 			return nil, NewCardRemovedError(err)
 		}
@@ -279,6 +414,45 @@ func (d *pcscDevice) Transceive(txData []byte) ([]byte, error) {
 	return rxData, nil
 }
 
+// scardCard is the subset of *scard.Card used for transmitting, so the
+// panic guard can be exercised without a reader.
+type scardCard interface {
+	ActiveProtocol() scard.Protocol
+	Transmit(cmd []byte) ([]byte, error)
+}
+
+// scardTransmitter is the subset of *scard.Card that pcscDevice depends on,
+// so tests can drive a device with scripted responses instead of a reader.
+type scardTransmitter interface {
+	scardCard
+	Status() (*scard.CardStatus, error)
+	Disconnect(d scard.Disposition) error
+}
+
+// isValidProtocol reports whether proto is one the scard library can transmit on.
+func isValidProtocol(proto scard.Protocol) bool {
+	return proto == scard.ProtocolT0 || proto == scard.ProtocolT1
+}
+
+// safeTransmit sends cmd to the card, guarding against the scard library
+// panicking when the card has reset to an invalid protocol. Both an invalid
+// protocol and a recovered panic are reported as card removal, since the card
+// must be reconnected either way.
+func safeTransmit(card scardCard, cmd []byte) (resp []byte, err error) {
+	if proto := card.ActiveProtocol(); !isValidProtocol(proto) {
+		return nil, NewCardRemovedError(fmt.Errorf("invalid card protocol: %d", proto))
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			resp = nil
+			err = NewCardRemovedError(fmt.Errorf("transmit panicked: %v", r))
+		}
+	}()
+
+	return card.Transmit(cmd)
+}
+
 // isCardRemovedPCSCError checks if a PC/SC error indicates the card was removed.
 // Uses typed error checking first (most reliable), with string matching fallback.
 func isCardRemovedPCSCError(err error) bool {
@@ -315,7 +489,7 @@ func isCardRemovedPCSCError(err error) bool {
 func (d *pcscDevice) getUID() (string, error) {
 	// GET UID: FF CA 00 00 00
 	cmd := GetUIDAPDU()
-	resp, err := d.card.Transmit(cmd)
+	resp, err := safeTransmit(d.card, cmd)
 	if err != nil {
 		return "", fmt.Errorf("GET UID failed: %w", err)
 	}
@@ -329,7 +503,57 @@ func (d *pcscDevice) getUID() (string, error) {
 		return "", parsed.Error()
 	}
 
-	return BytesToHex(parsed.Data), nil
+	uid, err := parseUIDResponse(parsed.Data)
+	if err != nil {
+		return "", err
+	}
+	return BytesToHex(uid), nil
+}
+
+// SetTrace starts recording Transceive exchanges into trace, or stops when
+// trace is nil (implements APDUTracer).
+func (d *pcscDevice) SetTrace(trace *APDUTrace) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.trace = trace
+}
+
+// ReadUID reads the UID of the card currently in the field (implements UIDReader).
+func (d *pcscDevice) ReadUID() (string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.card == nil {
+		return "", NewCardRemovedError(fmt.Errorf("device not connected"))
+	}
+	return d.getUID()
+}
+
+// cascadeTag is the ISO14443-3 cascade tag byte (CT) that prefixes a partial
+// UID at cascade levels 1 and 2 of a 7- or 10-byte UID.
+const cascadeTag = 0x88
+
+// parseUIDResponse normalizes a GET UID response so 4-, 7- and 10-byte UIDs are
+// reported in full. Some readers include the cascade tag bytes in the response,
+// which are stripped here. A lone 4-byte cascade-level-1 UID (CT + 3 bytes)
+// means the reader stopped anticollision early and is reported as an error
+// rather than as a truncated UID.
+func parseUIDResponse(data []byte) ([]byte, error) {
+	switch {
+	case len(data) == 0:
+		return nil, fmt.Errorf("empty UID response")
+	case len(data) == 4 && data[0] == cascadeTag:
+		return nil, fmt.Errorf("incomplete UID: reader returned cascade level 1 only (%s)", BytesToHex(data))
+	case len(data) == 8 && data[0] == cascadeTag:
+		// CT + 7-byte UID
+		return data[1:], nil
+	case len(data) == 12 && data[0] == cascadeTag && data[4] == cascadeTag:
+		// CT + 3 bytes + CT + 7 bytes for a 10-byte UID
+		uid := make([]byte, 0, 10)
+		uid = append(uid, data[1:4]...)
+		return append(uid, data[5:]...), nil
+	}
+	return data, nil
 }
 
 // GetTags returns the tags detected on this reader
@@ -367,7 +591,8 @@ func (d *pcscDevice) GetTags() ([]Tag, error) {
 	// Create appropriate tag wrapper based on detected type
 	var tag Tag
 	switch tagType {
-	case DetectedClassic1K, DetectedClassic4K:
+	case DetectedClassic1K, DetectedClassic4K, DetectedPlus2K, DetectedPlus4K:
+		// MIFARE Plus in SL1 is Classic compatible, so it shares the Classic path
 		tag = newPCSCClassicTag(d, d.uid, tagType)
 	case DetectedUltralight, DetectedUltralightC:
 		tag = newPCSCUltralightTag(d, d.uid, tagType)
@@ -377,6 +602,10 @@ func (d *pcscDevice) GetTags() ([]Tag, error) {
 		tag = newPCSCDESFireTag(d, d.uid)
 	case DetectedISO14443_4:
 		tag = newPCSCISO14443Tag(d, d.uid)
+	case DetectedISO15693:
+		tag = newPCSCType5Tag(d, d.uid)
+	case DetectedTopaz:
+		tag = newPCSCType1Tag(d, d.uid)
 	default:
 		// Try to detect more precisely using commands
 		tag = d.detectTagWithCommands()
@@ -385,13 +614,23 @@ func (d *pcscDevice) GetTags() ([]Tag, error) {
 			if isISO14443_4Compatible(d.atr) {
 				tag = newPCSCISO14443Tag(d, d.uid)
 			} else {
-				// Return error only once per card session to avoid log spam
-				if !d.unsupportedReported {
-					d.unsupportedReported = true
-					return nil, NewUnsupportedTagError(BytesToHex(d.atr))
+				switch d.unsupportedPolicy {
+				case UnsupportedTagRaw:
+					tag = newPCSCRawTag(d, d.uid, d.atr)
+				case UnsupportedTagIgnore:
+					return nil, nil
+				default:
+					// Return error only once per card session to avoid log spam
+					if !d.unsupportedReported {
+						d.unsupportedReported = true
+						if d.unsupportedPolicy == UnsupportedTagEvent {
+							return nil, NewUnsupportedTagEvent(d.uid, BytesToHex(d.atr))
+						}
+						return nil, NewUnsupportedTagError(BytesToHex(d.atr))
+					}
+					// Already reported, return nil to indicate no tags without error
+					return nil, nil
 				}
-				// Already reported, return nil to indicate no tags without error
-				return nil, nil
 			}
 		}
 	}
@@ -431,7 +670,7 @@ func (d *pcscDevice) detectTagWithCommands() Tag {
 func (d *pcscDevice) tryGetVersion() ([]byte, error) {
 	// Direct transmit of GET_VERSION (0x60)
 	cmd := GetVersionAPDU()
-	resp, err := d.card.Transmit(cmd)
+	resp, err := safeTransmit(d.card, cmd)
 	if err != nil {
 		return nil, err
 	}
@@ -460,7 +699,7 @@ func (d *pcscDevice) tryClassicAuth() bool {
 	for _, key := range keys {
 		// Load key
 		loadCmd := LoadKeyAPDU(0x00, key)
-		resp, err := d.card.Transmit(loadCmd)
+		resp, err := safeTransmit(d.card, loadCmd)
 		if err != nil {
 			continue
 		}
@@ -471,7 +710,7 @@ func (d *pcscDevice) tryClassicAuth() bool {
 
 		// Try auth to block 3 (sector 0 trailer)
 		authCmd := MIFAREAuthAPDU(0x03, MIFAREKeyA, 0x00)
-		resp, err = d.card.Transmit(authCmd)
+		resp, err = safeTransmit(d.card, authCmd)
 		if err != nil {
 			continue
 		}
@@ -509,9 +748,9 @@ func readerContainsPattern(name string) bool {
 		"ACR", "ACS", "NFC", "PICC", "Contactless",
 		"SCL", "HID", "Identiv", "CCID", "Dual",
 	}
-	upperName := strings.ToUpper(name)
+	normalized := NormalizeDeviceName(name)
 	for _, p := range patterns {
-		if strings.Contains(upperName, strings.ToUpper(p)) {
+		if strings.Contains(normalized, NormalizeDeviceName(p)) {
 			return true
 		}
 	}
@@ -523,7 +762,7 @@ func filterContactlessReaders(readers []string) []string {
 	var filtered []string
 	for _, r := range readers {
 		// Skip SAM slots (usually contain "SAM" or end with numbers indicating slots)
-		if strings.Contains(strings.ToUpper(r), "SAM") {
+		if strings.Contains(NormalizeDeviceName(r), "sam") {
 			continue
 		}
 		// Include readers that match NFC patterns
@@ -0,0 +1,443 @@
+package nfc
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ebfe/scard"
+)
+
+// stubSCardCard is a scardCard whose protocol and transmit behavior are set by the test.
+type stubSCardCard struct {
+	protocol scard.Protocol
+	resp     []byte
+	panicMsg string
+	calls    int
+}
+
+func (c *stubSCardCard) ActiveProtocol() scard.Protocol {
+	return c.protocol
+}
+
+func (c *stubSCardCard) Transmit(cmd []byte) ([]byte, error) {
+	c.calls++
+	if c.panicMsg != "" {
+		panic(c.panicMsg)
+	}
+	return c.resp, nil
+}
+
+func TestSafeTransmit(t *testing.T) {
+	tests := []struct {
+		name        string
+		card        *stubSCardCard
+		wantRemoved bool
+		wantCalls   int
+	}{
+		{
+			name:      "T1 transmits",
+			card:      &stubSCardCard{protocol: scard.ProtocolT1, resp: []byte{0x90, 0x00}},
+			wantCalls: 1,
+		},
+		{
+			name:        "invalid protocol is not transmitted",
+			card:        &stubSCardCard{protocol: scard.ProtocolUndefined},
+			wantRemoved: true,
+			wantCalls:   0,
+		},
+		{
+			name:        "panic is recovered",
+			card:        &stubSCardCard{protocol: scard.ProtocolT0, panicMsg: "invalid protocol"},
+			wantRemoved: true,
+			wantCalls:   1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := safeTransmit(tt.card, GetUIDAPDU())
+
+			if IsCardRemovedError(err) != tt.wantRemoved {
+				t.Fatalf("safeTransmit() error = %v, want card removed: %v", err, tt.wantRemoved)
+			}
+			if tt.wantRemoved && resp != nil {
+				t.Errorf("Expected nil response on failure, got %X", resp)
+			}
+			if !tt.wantRemoved && len(resp) != 2 {
+				t.Errorf("Expected 2-byte response, got %X", resp)
+			}
+			if tt.card.calls != tt.wantCalls {
+				t.Errorf("Transmit called %d times, want %d", tt.card.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+// blockingStatusWaiter blocks in GetStatusChange, whatever the timeout, until
+// it is cancelled. With ignoreCancel it never returns, like a wedged driver.
+type blockingStatusWaiter struct {
+	blocked      chan struct{}
+	cancelled    chan struct{}
+	ignoreCancel bool
+}
+
+func newBlockingStatusWaiter(ignoreCancel bool) *blockingStatusWaiter {
+	return &blockingStatusWaiter{
+		blocked:      make(chan struct{}, 1),
+		cancelled:    make(chan struct{}),
+		ignoreCancel: ignoreCancel,
+	}
+}
+
+func (w *blockingStatusWaiter) GetStatusChange(readerStates []scard.ReaderState, timeout time.Duration) error {
+	select {
+	case w.blocked <- struct{}{}:
+	default:
+	}
+	if w.ignoreCancel {
+		select {}
+	}
+	<-w.cancelled
+	return scard.ErrCancelled
+}
+
+func (w *blockingStatusWaiter) Cancel() error {
+	close(w.cancelled)
+	return nil
+}
+
+func TestPCSCDevice_CloseCancelsCardMonitor(t *testing.T) {
+	tests := []struct {
+		name         string
+		ignoreCancel bool
+		stopTimeout  time.Duration
+		wantReleased bool
+		maxClose     time.Duration
+	}{
+		{"cancel interrupts GetStatusChange", false, 5 * time.Second, true, time.Second},
+		{"stop timeout bounds a wedged monitor", true, 50 * time.Millisecond, false, time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			waiter := newBlockingStatusWaiter(tt.ignoreCancel)
+			dev := newMockPCSCDevice(newMockScardCard(), pcscATR(0x01))
+			dev.monitorCtx = waiter
+			dev.monitorStopTimeout = tt.stopTimeout
+			released := make(chan struct{})
+			dev.startCardMonitor(func() { close(released) })
+
+			select {
+			case <-waiter.blocked:
+			case <-time.After(time.Second):
+				t.Fatal("Monitor never called GetStatusChange")
+			}
+
+			start := time.Now()
+			if err := dev.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+			if elapsed := time.Since(start); elapsed > tt.maxClose {
+				t.Errorf("Close() took %v, want under %v", elapsed, tt.maxClose)
+			}
+
+			select {
+			case <-released:
+				if !tt.wantReleased {
+					t.Error("Monitor context released while the monitor was still blocked")
+				}
+			default:
+				if tt.wantReleased {
+					t.Error("Monitor context not released after Close()")
+				}
+			}
+		})
+	}
+}
+
+// pcscATR builds a PC/SC Part 3 contactless ATR carrying the given card name byte.
+func pcscATR(cardName byte) []byte {
+	return []byte{0x3B, 0x8F, 0x80, 0x01, 0x80, 0x4F, 0x0C, 0xA0, 0x00, 0x00, 0x03, 0x06,
+		0x03, 0x00, cardName, 0x00, 0x00, 0x00, 0x00, 0x00}
+}
+
+// unknownATR is a contact card ATR that carries no contactless card name.
+var unknownATR = []byte{0x3B, 0x02, 0x14, 0x50}
+
+// newMockPCSCDevice returns a pcscDevice connected to card with the given ATR,
+// without a PC/SC context or removal monitor.
+func newMockPCSCDevice(card *mockScardCard, atr []byte) *pcscDevice {
+	card.atr = atr
+	return &pcscDevice{
+		card:        card,
+		readerName:  "Mock Reader 00 00",
+		uid:         "04A1B2C3",
+		atr:         atr,
+		cardRemoved: make(chan struct{}, 1),
+	}
+}
+
+// addClassicAuth scripts a successful key load and sector 0 authentication for key.
+func (m *mockScardCard) addClassicAuth(key []byte) {
+	m.addResponse(hex.EncodeToString(LoadKeyAPDU(0x00, key)), "9000")
+	m.addResponse(hex.EncodeToString(MIFAREAuthAPDU(0x03, MIFAREKeyA, 0x00)), "9000")
+}
+
+func TestDetectTagTypeFromATR(t *testing.T) {
+	tests := []struct {
+		name string
+		atr  []byte
+		want DetectedTagType
+	}{
+		{"Classic 1K", pcscATR(0x01), DetectedClassic1K},
+		{"Classic 4K", pcscATR(0x02), DetectedClassic4K},
+		{"Ultralight", pcscATR(0x03), DetectedUltralight},
+		{"Ultralight C", pcscATR(0x05), DetectedUltralightC},
+		{"DESFire", pcscATR(0x26), DetectedDESFire},
+		{"unknown card name", pcscATR(0x7F), DetectedUnknown},
+		{"contact card", unknownATR, DetectedUnknown},
+		{"too short", []byte{0x3B}, DetectedUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectTagTypeFromATR(tt.atr); got != tt.want {
+				t.Errorf("detectTagTypeFromATR(%X) = %s, want %s", tt.atr, detectedTypeName(got), detectedTypeName(tt.want))
+			}
+		})
+	}
+}
+
+func TestPCSCDevice_TryClassicAuth(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(card *mockScardCard)
+		want  bool
+	}{
+		{
+			name:  "transport key",
+			setup: func(card *mockScardCard) { card.addClassicAuth([]byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}) },
+			want:  true,
+		},
+		{
+			name:  "NFC Forum public key",
+			setup: func(card *mockScardCard) { card.addClassicAuth([]byte{0xD3, 0xF7, 0xD3, 0xF7, 0xD3, 0xF7}) },
+			want:  true,
+		},
+		{
+			name: "key loads but auth fails",
+			setup: func(card *mockScardCard) {
+				card.addResponse(hex.EncodeToString(LoadKeyAPDU(0x00, []byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF})), "9000")
+			},
+			want: false,
+		},
+		{
+			name:  "transmit errors",
+			setup: func(card *mockScardCard) { card.transmitErr = errors.New("transmit failed") },
+			want:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			card := newMockScardCard()
+			tt.setup(card)
+			dev := newMockPCSCDevice(card, unknownATR)
+
+			if got := dev.tryClassicAuth(); got != tt.want {
+				t.Errorf("tryClassicAuth() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPCSCDevice_GetTags(t *testing.T) {
+	tests := []struct {
+		name     string
+		atr      []byte
+		setup    func(card *mockScardCard)
+		wantType string
+	}{
+		{name: "Classic from ATR", atr: pcscATR(0x01), wantType: CardTypeMifareClassic1K},
+		{name: "Ultralight from ATR", atr: pcscATR(0x03), wantType: CardTypeMifareUltralight},
+		{
+			name: "NTAG215 from GET_VERSION",
+			atr:  unknownATR,
+			setup: func(card *mockScardCard) {
+				card.addResponse(hex.EncodeToString(GetVersionAPDU()), "0004040201001103"+"9000")
+			},
+			wantType: CardTypeNtag215,
+		},
+		{
+			name:     "Classic from auth probe",
+			atr:      unknownATR,
+			setup:    func(card *mockScardCard) { card.addClassicAuth([]byte{0xA0, 0xA1, 0xA2, 0xA3, 0xA4, 0xA5}) },
+			wantType: CardTypeMifareClassic1K,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			card := newMockScardCard()
+			if tt.setup != nil {
+				tt.setup(card)
+			}
+			dev := newMockPCSCDevice(card, tt.atr)
+
+			tags, err := dev.GetTags()
+			if err != nil {
+				t.Fatalf("GetTags() error = %v", err)
+			}
+			if len(tags) != 1 {
+				t.Fatalf("Expected 1 tag, got %d", len(tags))
+			}
+			if tags[0].Type() != tt.wantType {
+				t.Errorf("Type() = %s, want %s", tags[0].Type(), tt.wantType)
+			}
+			if tags[0].UID() != "04A1B2C3" {
+				t.Errorf("UID() = %s, want 04A1B2C3", tags[0].UID())
+			}
+		})
+	}
+
+	t.Run("unsupported card reported once", func(t *testing.T) {
+		dev := newMockPCSCDevice(newMockScardCard(), unknownATR)
+
+		if _, err := dev.GetTags(); !IsUnsupportedTagError(err) {
+			t.Fatalf("First GetTags() error = %v, want unsupported tag error", err)
+		}
+		tags, err := dev.GetTags()
+		if err != nil || tags != nil {
+			t.Errorf("Second GetTags() = %v, %v, want no tags and no error", tags, err)
+		}
+	})
+
+	t.Run("unsupported card ignored", func(t *testing.T) {
+		dev := newMockPCSCDevice(newMockScardCard(), unknownATR)
+		dev.unsupportedPolicy = UnsupportedTagIgnore
+
+		for i := 0; i < 2; i++ {
+			if tags, err := dev.GetTags(); err != nil || tags != nil {
+				t.Errorf("GetTags() = %v, %v, want no tags and no error", tags, err)
+			}
+		}
+	})
+
+	t.Run("unsupported card reported as event", func(t *testing.T) {
+		dev := newMockPCSCDevice(newMockScardCard(), unknownATR)
+		dev.unsupportedPolicy = UnsupportedTagEvent
+
+		_, err := dev.GetTags()
+		var unsupported *unsupportedTagError
+		if !errors.As(err, &unsupported) || !unsupported.Event {
+			t.Fatalf("First GetTags() error = %v, want unsupported tag event", err)
+		}
+		if unsupported.UID != "04A1B2C3" || unsupported.ATR != BytesToHex(unknownATR) {
+			t.Errorf("Event UID, ATR = %s, %s, want 04A1B2C3, %s", unsupported.UID, unsupported.ATR, BytesToHex(unknownATR))
+		}
+		if tags, err := dev.GetTags(); err != nil || tags != nil {
+			t.Errorf("Second GetTags() = %v, %v, want no tags and no error", tags, err)
+		}
+	})
+
+	t.Run("unsupported card surfaced raw", func(t *testing.T) {
+		dev := newMockPCSCDevice(newMockScardCard(), unknownATR)
+		dev.unsupportedPolicy = UnsupportedTagRaw
+
+		tags, err := dev.GetTags()
+		if err != nil || len(tags) != 1 {
+			t.Fatalf("GetTags() = %v, %v, want one raw tag", tags, err)
+		}
+		if tags[0].Type() != CardTypeUnknown || tags[0].UID() != "04A1B2C3" {
+			t.Errorf("Expected Unknown tag with UID 04A1B2C3, got %s %s", tags[0].Type(), tags[0].UID())
+		}
+		if atr := tags[0].(ATRProvider).ATR(); atr != BytesToHex(unknownATR) {
+			t.Errorf("ATR() = %s, want %s", atr, BytesToHex(unknownATR))
+		}
+		if data, err := tags[0].ReadData(); err != nil || len(data) != 0 {
+			t.Errorf("ReadData() = %X, %v, want no data", data, err)
+		}
+		if err := tags[0].WriteData([]byte{0x03, 0x00}); err == nil {
+			t.Error("Expected WriteData() to fail on a raw tag")
+		}
+	})
+}
+
+// TestPCSCDevice_TransceiveCardRemoved tests which transmit failures are
+// classified as card removal.
+func TestPCSCDevice_ReadUID(t *testing.T) {
+	card := newMockScardCard()
+	card.addResponse(hex.EncodeToString(GetUIDAPDU()), "04b2c3d4"+"9000")
+	dev := newMockPCSCDevice(card, pcscATR(0x01))
+
+	// The UID comes from the card, not the one cached at detection
+	uid, err := dev.ReadUID()
+	if err != nil {
+		t.Fatalf("ReadUID() error = %v", err)
+	}
+	if uid != "04B2C3D4" {
+		t.Errorf("ReadUID() = %s, want 04B2C3D4", uid)
+	}
+
+	dev.card = nil
+	if _, err := dev.ReadUID(); !IsCardRemovedError(err) {
+		t.Errorf("Expected card removed error without a card, got %v", err)
+	}
+}
+
+func TestPCSCDevice_TransceiveCardRemoved(t *testing.T) {
+	tests := []struct {
+		name        string
+		setup       func(card *mockScardCard, dev *pcscDevice)
+		wantRemoved bool
+	}{
+		{
+			name:        "removed card",
+			setup:       func(card *mockScardCard, dev *pcscDevice) { card.transmitErr = scard.ErrRemovedCard },
+			wantRemoved: true,
+		},
+		{
+			name:        "reset card",
+			setup:       func(card *mockScardCard, dev *pcscDevice) { card.transmitErr = scard.ErrResetCard },
+			wantRemoved: true,
+		},
+		{
+			name:        "no smart card message",
+			setup:       func(card *mockScardCard, dev *pcscDevice) { card.transmitErr = errors.New("No smart card inserted") },
+			wantRemoved: true,
+		},
+		{
+			name:        "invalid protocol",
+			setup:       func(card *mockScardCard, dev *pcscDevice) { card.protocol = scard.ProtocolUndefined },
+			wantRemoved: true,
+		},
+		{
+			name:        "monitor signalled removal",
+			setup:       func(card *mockScardCard, dev *pcscDevice) { dev.cardRemoved <- struct{}{} },
+			wantRemoved: true,
+		},
+		{
+			name:        "other transmit error",
+			setup:       func(card *mockScardCard, dev *pcscDevice) { card.transmitErr = errors.New("insufficient buffer") },
+			wantRemoved: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			card := newMockScardCard()
+			dev := newMockPCSCDevice(card, pcscATR(0x01))
+			tt.setup(card, dev)
+
+			_, err := dev.Transceive(GetUIDAPDU())
+			if err == nil {
+				t.Fatal("Expected Transceive() to fail")
+			}
+			if IsCardRemovedError(err) != tt.wantRemoved {
+				t.Errorf("Transceive() error = %v, want card removed: %v", err, tt.wantRemoved)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package nfc
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// DeviceSelectPolicy selects the device a DeviceManager opens when no device
+// is named. Devices are considered in sorted order, so the choice does not
+// depend on the order in which the manager enumerates them.
+type DeviceSelectPolicy int
+
+const (
+	// DeviceSelectFirst opens the first device (default).
+	DeviceSelectFirst DeviceSelectPolicy = iota
+	// DeviceSelectLast opens the last device.
+	DeviceSelectLast
+	// DeviceSelectIndex opens the device at DeviceSelection.Index.
+	DeviceSelectIndex
+	// DeviceSelectName opens the first device whose name matches
+	// DeviceSelection.Pattern.
+	DeviceSelectName
+)
+
+// DeviceSelection is a DeviceSelectPolicy with its argument.
+type DeviceSelection struct {
+	Policy  DeviceSelectPolicy
+	Index   int            // Zero-based position for DeviceSelectIndex
+	Pattern *regexp.Regexp // Name pattern for DeviceSelectName
+}
+
+// String returns the selection as accepted by ParseDeviceSelection.
+func (s DeviceSelection) String() string {
+	switch s.Policy {
+	case DeviceSelectFirst:
+		return "first"
+	case DeviceSelectLast:
+		return "last"
+	case DeviceSelectIndex:
+		return fmt.Sprintf("index:%d", s.Index)
+	case DeviceSelectName:
+		if s.Pattern == nil {
+			return "name:"
+		}
+		return "name:" + strings.TrimPrefix(s.Pattern.String(), "(?i)")
+	default:
+		return fmt.Sprintf("DeviceSelectPolicy(%d)", int(s.Policy))
+	}
+}
+
+// ParseDeviceSelection parses "first", "last", "index:N" or "name:PATTERN"
+// into a DeviceSelection. PATTERN is a regular expression matched against
+// device names without regard to case, e.g. "name:acr122".
+func ParseDeviceSelection(s string) (DeviceSelection, error) {
+	policy, arg, hasArg := strings.Cut(s, ":")
+	switch {
+	case (policy == "first" || policy == "") && !hasArg:
+		return DeviceSelection{Policy: DeviceSelectFirst}, nil
+	case policy == "last" && !hasArg:
+		return DeviceSelection{Policy: DeviceSelectLast}, nil
+	case policy == "index" && hasArg:
+		index, err := strconv.Atoi(arg)
+		if err != nil || index < 0 {
+			return DeviceSelection{}, fmt.Errorf("invalid device index %q (expected a number from 0)", arg)
+		}
+		return DeviceSelection{Policy: DeviceSelectIndex, Index: index}, nil
+	case policy == "name" && hasArg:
+		if arg == "" {
+			return DeviceSelection{}, fmt.Errorf("device name pattern must not be empty")
+		}
+		pattern, err := regexp.Compile("(?i)" + arg)
+		if err != nil {
+			return DeviceSelection{}, fmt.Errorf("invalid device name pattern: %w", err)
+		}
+		return DeviceSelection{Policy: DeviceSelectName, Pattern: pattern}, nil
+	default:
+		return DeviceSelection{}, fmt.Errorf("unknown device selection %q (expected first, last, index:N or name:PATTERN)", s)
+	}
+}
+
+// Select picks a device from devices according to the selection.
+func (s DeviceSelection) Select(devices []string) (string, error) {
+	if len(devices) == 0 {
+		return "", fmt.Errorf("no NFC devices found by manager")
+	}
+	sorted := slices.Sorted(slices.Values(devices))
+
+	switch s.Policy {
+	case DeviceSelectFirst:
+		return sorted[0], nil
+	case DeviceSelectLast:
+		return sorted[len(sorted)-1], nil
+	case DeviceSelectIndex:
+		if s.Index < 0 || s.Index >= len(sorted) {
+			return "", fmt.Errorf("no NFC device at index %d (%d found)", s.Index, len(sorted))
+		}
+		return sorted[s.Index], nil
+	case DeviceSelectName:
+		for _, device := range sorted {
+			if s.Pattern != nil && s.Pattern.MatchString(device) {
+				return device, nil
+			}
+		}
+		return "", fmt.Errorf("no NFC device matches %q (found %s)", s.String(), strings.Join(sorted, ", "))
+	default:
+		return "", fmt.Errorf("unknown device selection policy %d", int(s.Policy))
+	}
+}
@@ -0,0 +1,114 @@
+package nfc
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDeviceSelection(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string // String() of the parsed selection
+		wantErr bool
+	}{
+		{"", "first", false},
+		{"first", "first", false},
+		{"last", "last", false},
+		{"index:2", "index:2", false},
+		{"name:acr12[0-9]", "name:acr12[0-9]", false},
+		{"index:-1", "", true},
+		{"index:x", "", true},
+		{"index", "", true},
+		{"name:", "", true},
+		{"name:(", "", true},
+		{"last:1", "", true},
+		{"random", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseDeviceSelection(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseDeviceSelection(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if err == nil && got.String() != tt.want {
+			t.Errorf("ParseDeviceSelection(%q) = %s, want %s", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestDeviceSelection_Select(t *testing.T) {
+	// Listed out of order, as a manager may enumerate them
+	devices := []string{
+		"Identiv uTrust 3700 F CL Reader 01 00",
+		"ACS ACR122U PICC Interface 00 00",
+		"ACS ACR1252 1S CL Reader PICC 0",
+	}
+
+	tests := []struct {
+		selection string
+		want      string
+		wantErr   bool
+	}{
+		{"first", devices[1], false},
+		{"last", devices[0], false},
+		{"index:1", devices[2], false},
+		{"index:3", "", true},
+		{"name:UTRUST", devices[0], false},
+		{"name:acr12", devices[1], false},
+		{"name:pn532", "", true},
+	}
+
+	for _, tt := range tests {
+		selection, err := ParseDeviceSelection(tt.selection)
+		if err != nil {
+			t.Fatalf("ParseDeviceSelection(%q): %v", tt.selection, err)
+		}
+		got, err := selection.Select(devices)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: error = %v, wantErr %v", tt.selection, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: selected %q, want %q", tt.selection, got, tt.want)
+		}
+	}
+
+	if _, err := (DeviceSelection{}).Select(nil); err == nil {
+		t.Error("Expected an error without devices")
+	}
+}
+
+// TestDeviceManager_DeviceSelection tests that the selection applies on
+// reconnect too, and that a configured path bypasses it
+func TestDeviceManager_DeviceSelection(t *testing.T) {
+	mockManager := NewMockManager()
+	mockManager.DevicesList = []string{"mock:usb:002", "mock:usb:001"}
+	dm := NewDeviceManager(mockManager, "", NewFakeClock(time.Now()))
+	dm.SetDeviceSelection(DeviceSelection{Policy: DeviceSelectLast})
+
+	if err := dm.TryConnect(nil); err != nil {
+		t.Fatalf("TryConnect failed: %v", err)
+	}
+	if got := dm.DevicePath(); got != "mock:usb:002" {
+		t.Fatalf("Connected to %q, want mock:usb:002", got)
+	}
+
+	// The reader that sorted last is gone; reconnecting selects again
+	mockManager.DevicesList = []string{"mock:usb:003", "mock:usb:001"}
+	if err := dm.Reconnect(nil); err != nil {
+		t.Fatalf("Reconnect failed: %v", err)
+	}
+	if got := dm.DevicePath(); got != "mock:usb:003" {
+		t.Errorf("Reconnected to %q, want mock:usb:003", got)
+	}
+
+	named := NewDeviceManager(mockManager, "mock:usb:001", NewFakeClock(time.Now()))
+	named.SetDeviceSelection(DeviceSelection{Policy: DeviceSelectLast})
+	if err := named.TryConnect(nil); err != nil {
+		t.Fatalf("TryConnect failed: %v", err)
+	}
+	if got := named.DevicePath(); got != "mock:usb:001" {
+		t.Errorf("Named manager connected to %q, want mock:usb:001", got)
+	}
+}
@@ -125,3 +125,43 @@ func TestMockDevice_CallLog(t *testing.T) {
 		}
 	}
 }
+
+func TestParseUIDResponse(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   []byte
+		want    string
+		wantErr bool
+	}{
+		{"4-byte UID", []byte{0x8A, 0x1B, 0x2C, 0x3D}, "8A1B2C3D", false},
+		{"7-byte UID", []byte{0x04, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66}, "04112233445566", false},
+		{"10-byte UID", []byte{0x04, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99}, "04112233445566778899", false},
+		{"7-byte UID with cascade tag", []byte{0x88, 0x04, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66}, "04112233445566", false},
+		{"10-byte UID with cascade tags", []byte{0x88, 0x04, 0x11, 0x22, 0x88, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99}, "04112233445566778899", false},
+		{"cascade level 1 only", []byte{0x88, 0x04, 0x11, 0x22}, "", true},
+		{"empty", []byte{}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseUIDResponse(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseUIDResponse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if BytesToHex(got) != tt.want {
+				t.Errorf("parseUIDResponse() = %s, want %s", BytesToHex(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestMockTag_UIDLengths(t *testing.T) {
+	for _, uid := range []string{"8A1B2C3D", "04112233445566", "04112233445566778899"} {
+		tag := NewMockTag(uid)
+		tag.IsConnected = true
+		card := NewCard(tag)
+		if card.UID != uid {
+			t.Errorf("Card UID = %s, want %s", card.UID, uid)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package nfc
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Latency operation kinds
+const (
+	LatencyRead      = "read"      // Card reads while polling
+	LatencyWrite     = "write"     // Writes and formats
+	LatencyOperation = "operation" // Other protected tag operations (card info, page access, ...)
+)
+
+// LatencyWindow is the rolling window latency percentiles are computed over.
+// It is kept as latencySlots slots, the oldest of which is replaced as time
+// moves on, so stats cover between LatencyWindow minus one slot and
+// LatencyWindow of history.
+const LatencyWindow = 5 * time.Minute
+
+const latencySlots = 5
+
+// latencyBounds are the upper bounds of the histogram buckets; a final
+// bucket holds everything slower.
+var latencyBounds = []time.Duration{
+	1 * time.Millisecond,
+	2 * time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	20 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	200 * time.Millisecond,
+	500 * time.Millisecond,
+	1 * time.Second,
+	2 * time.Second,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// LatencyStats summarizes the durations recorded for one kind of operation.
+// Percentiles are interpolated within fixed histogram buckets, so they are
+// estimates; Max is exact.
+type LatencyStats struct {
+	Count int
+	P50   time.Duration
+	P95   time.Duration
+	P99   time.Duration
+	Max   time.Duration
+}
+
+// latencySlot is the histogram of one slot of the window.
+type latencySlot struct {
+	start  time.Time
+	counts []int // One per bucket in latencyBounds, plus the overflow bucket
+	max    time.Duration
+}
+
+// LatencyRecorder keeps rolling latency histograms per operation kind. Its
+// memory is fixed by the number of kinds, slots and buckets.
+type LatencyRecorder struct {
+	clock Clock
+
+	mu  sync.Mutex
+	ops map[string]*[latencySlots]latencySlot
+}
+
+// NewLatencyRecorder creates a recorder. If clock is nil, a RealClock is used.
+func NewLatencyRecorder(clock Clock) *LatencyRecorder {
+	if clock == nil {
+		clock = &RealClock{}
+	}
+	return &LatencyRecorder{
+		clock: clock,
+		ops:   make(map[string]*[latencySlots]latencySlot),
+	}
+}
+
+// Record adds the duration of one operation of the given kind.
+func (l *LatencyRecorder) Record(kind string, d time.Duration) {
+	slotLen := LatencyWindow / latencySlots
+	now := l.clock.Now()
+	start := now.Truncate(slotLen)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	slots, ok := l.ops[kind]
+	if !ok {
+		slots = new([latencySlots]latencySlot)
+		l.ops[kind] = slots
+	}
+
+	slot := &slots[int(start.UnixNano()/int64(slotLen))%latencySlots]
+	if !slot.start.Equal(start) {
+		*slot = latencySlot{start: start, counts: make([]int, len(latencyBounds)+1)}
+	}
+
+	bucket := len(latencyBounds)
+	for i, bound := range latencyBounds {
+		if d <= bound {
+			bucket = i
+			break
+		}
+	}
+	slot.counts[bucket]++
+	if d > slot.max {
+		slot.max = d
+	}
+}
+
+// Stats returns the stats of every kind recorded within the window.
+func (l *LatencyRecorder) Stats() map[string]LatencyStats {
+	cutoff := l.clock.Now().Add(-LatencyWindow)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	stats := make(map[string]LatencyStats, len(l.ops))
+	for kind, slots := range l.ops {
+		counts := make([]int, len(latencyBounds)+1)
+		var s LatencyStats
+		for _, slot := range slots {
+			if slot.counts == nil || !slot.start.After(cutoff) {
+				continue
+			}
+			for i, n := range slot.counts {
+				counts[i] += n
+				s.Count += n
+			}
+			if slot.max > s.Max {
+				s.Max = slot.max
+			}
+		}
+		if s.Count == 0 {
+			continue
+		}
+		s.P50 = percentile(counts, s.Count, 0.50, s.Max)
+		s.P95 = percentile(counts, s.Count, 0.95, s.Max)
+		s.P99 = percentile(counts, s.Count, 0.99, s.Max)
+		stats[kind] = s
+	}
+	return stats
+}
+
+// percentile estimates the q-th quantile from bucket counts by interpolating
+// linearly within the bucket it falls in. The overflow bucket ends at max, and
+// no estimate exceeds max.
+func percentile(counts []int, total int, q float64, max time.Duration) time.Duration {
+	rank := int(math.Ceil(q * float64(total)))
+	seen := 0
+	for i, n := range counts {
+		if n == 0 || seen+n < rank {
+			seen += n
+			continue
+		}
+
+		var lower, upper time.Duration
+		if i > 0 {
+			lower = latencyBounds[i-1]
+		}
+		if i < len(latencyBounds) {
+			upper = latencyBounds[i]
+		} else {
+			upper = max
+		}
+		if upper > max {
+			upper = max
+		}
+		if lower > upper {
+			lower = upper
+		}
+
+		frac := float64(rank-seen) / float64(n)
+		return lower + time.Duration(frac*float64(upper-lower))
+	}
+	return max
+}
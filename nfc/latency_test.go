@@ -0,0 +1,46 @@
+package nfc
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLatencyRecorder(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	l := NewLatencyRecorder(clock)
+
+	for i := 1; i <= 100; i++ {
+		l.Record(LatencyRead, time.Duration(i)*time.Millisecond)
+	}
+	l.Record(LatencyWrite, 20*time.Second)
+
+	stats := l.Stats()
+	read := stats[LatencyRead]
+	want := LatencyStats{Count: 100, P50: 50 * time.Millisecond, P95: 95 * time.Millisecond, P99: 99 * time.Millisecond, Max: 100 * time.Millisecond}
+	if read != want {
+		t.Errorf("read stats = %+v, want %+v", read, want)
+	}
+
+	// The overflow bucket ends at the slowest operation
+	if write := stats[LatencyWrite]; write.Count != 1 || write.P50 != 20*time.Second || write.P99 != 20*time.Second {
+		t.Errorf("write stats = %+v, want every percentile at 20s", write)
+	}
+	if _, ok := stats[LatencyOperation]; ok {
+		t.Error("Expected no stats for a kind without operations")
+	}
+
+	// Older slots roll out of the window
+	clock.Advance(LatencyWindow - time.Minute)
+	l.Record(LatencyRead, 3*time.Millisecond)
+	if n := l.Stats()[LatencyRead].Count; n != 101 {
+		t.Errorf("Count within the window = %d, want 101", n)
+	}
+	clock.Advance(time.Minute)
+	if read := l.Stats()[LatencyRead]; read.Count != 1 || read.Max != 3*time.Millisecond {
+		t.Errorf("Stats after the window moved = %+v, want only the last read", read)
+	}
+	clock.Advance(LatencyWindow)
+	if len(l.Stats()) != 0 {
+		t.Errorf("Expected no stats once the window has passed, got %+v", l.Stats())
+	}
+}
@@ -0,0 +1,316 @@
+package nfc
+
+import (
+	"errors"
+	"fmt"
+)
+
+// MIFARE Application Directory (MAD) constants, per NXP AN10787.
+const (
+	// MADAIDNDEF is the NFC Forum application ID stored in the MAD for NDEF sectors
+	MADAIDNDEF uint16 = 0xE103
+
+	// madCRCPreset is the initial value of the MAD CRC-8
+	madCRCPreset = 0xC7
+	// madCRCPoly is the MAD CRC-8 polynomial (x^8 + x^4 + x^3 + x^2 + 1)
+	madCRCPoly = 0x1D
+
+	// madInfoByte points at the card publisher sector (sector 1 by convention)
+	madInfoByte = 0x01
+
+	// madGPBv1 and madGPBv2 are the general purpose bytes marking a MAD v1 (1K) or v2 (4K) card
+	madGPBv1 = 0xC1
+	madGPBv2 = 0xC2
+
+	// ndefSectorGPB is the general purpose byte for NDEF sectors (version 1.0, read/write)
+	ndefSectorGPB = 0x40
+
+	// madGPBDA is the GPB "MAD available" bit; the low two bits hold the MAD version
+	madGPBDA = 0x80
+
+	// trailerGPBOffset is the position of the general purpose byte in a sector trailer
+	trailerGPBOffset = 9
+
+	// mad1Sectors and mad2Sectors are the number of sectors each MAD covers
+	mad1Sectors = 15
+	mad2Sectors = 23
+)
+
+// ErrMADCRC indicates a MAD whose stored CRC does not match its contents.
+var ErrMADCRC = errors.New("MAD CRC mismatch")
+
+// MADEntry maps one sector to the application ID the MAD assigns it.
+type MADEntry struct {
+	Sector int
+	AID    uint16
+}
+
+// Application describes the AID: one of the administration codes of NXP
+// AN10787, "NDEF" for the NFC Forum AID, or "" for other applications.
+func (e MADEntry) Application() string {
+	switch e.AID {
+	case 0x0000:
+		return "free"
+	case 0x0001:
+		return "defect"
+	case 0x0002:
+		return "reserved"
+	case 0x0003:
+		return "additional directory info"
+	case 0x0004:
+		return "card holder info"
+	case 0x0005:
+		return "not applicable"
+	case MADAIDNDEF:
+		return "NDEF"
+	}
+	return ""
+}
+
+// parseMAD decodes MAD bytes ([CRC][info][AID]...) covering count sectors
+// from firstSector. The entries are returned even when the CRC does not
+// match, together with an error wrapping ErrMADCRC.
+func parseMAD(mad []byte, firstSector, count int) ([]MADEntry, error) {
+	if len(mad) < 2+count*2 {
+		return nil, fmt.Errorf("MAD must be %d bytes, got %d", 2+count*2, len(mad))
+	}
+
+	entries := make([]MADEntry, count)
+	for i := range entries {
+		entries[i] = MADEntry{
+			Sector: firstSector + i,
+			AID:    uint16(mad[3+i*2])<<8 | uint16(mad[2+i*2]),
+		}
+	}
+
+	if crc := madCRC(mad[1 : 2+count*2]); crc != mad[0] {
+		return entries, fmt.Errorf("%w: stored 0x%02X, computed 0x%02X", ErrMADCRC, mad[0], crc)
+	}
+	return entries, nil
+}
+
+// readMADEntries reads the MAD of a Classic card with sectors sectors through
+// read, authenticating with the public MAD key. MAD1 lives in sector 0 and, on
+// cards whose GPB announces MAD v2, MAD2 in sector 16. Entries for sectors the
+// card does not have are dropped. As with parseMAD, a CRC mismatch returns the
+// entries along with an error wrapping ErrMADCRC.
+func readMADEntries(read func(sector, block uint8) ([]byte, error), sectors int) ([]MADEntry, error) {
+	trailer, err := read(0, 3)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sector 0 trailer: %w", err)
+	}
+	if len(trailer) < 16 {
+		return nil, fmt.Errorf("sector trailer must be 16 bytes, got %d", len(trailer))
+	}
+	gpb := trailer[9]
+	if gpb&madGPBDA == 0 {
+		return nil, fmt.Errorf("card has no MAD (GPB 0x%02X)", gpb)
+	}
+
+	readMADBlocks := func(sector uint8, blocks ...uint8) ([]byte, error) {
+		var data []byte
+		for _, block := range blocks {
+			b, err := read(sector, block)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read MAD block %d of sector %d: %w", block, sector, err)
+			}
+			data = append(data, b...)
+		}
+		return data, nil
+	}
+
+	mad1, err := readMADBlocks(0, 1, 2)
+	if err != nil {
+		return nil, err
+	}
+	entries, crcErr := parseMAD(mad1, 1, mad1Sectors)
+	if entries == nil {
+		return nil, crcErr
+	}
+	if crcErr != nil {
+		crcErr = fmt.Errorf("MAD1: %w", crcErr)
+	}
+
+	if gpb&0x03 == 0x02 && sectors > 16 {
+		mad2, err := readMADBlocks(16, 0, 1, 2)
+		if err != nil {
+			return nil, err
+		}
+		entries2, err := parseMAD(mad2, 17, mad2Sectors)
+		if entries2 == nil {
+			return nil, err
+		}
+		entries = append(entries, entries2...)
+		if err != nil && crcErr == nil {
+			crcErr = fmt.Errorf("MAD2: %w", err)
+		}
+	}
+
+	for len(entries) > 0 && entries[len(entries)-1].Sector >= sectors {
+		entries = entries[:len(entries)-1]
+	}
+	return entries, crcErr
+}
+
+// classicTrailerBlock returns the sector-relative block of a sector's
+// trailer: 15 in the large sectors 32-39 of 4K cards, 3 elsewhere.
+func classicTrailerBlock(sector uint8) uint8 {
+	if sector >= 32 {
+		return 15
+	}
+	return 3
+}
+
+// readTrailerGPB returns the general purpose byte of a sector trailer read
+// with read.
+func readTrailerGPB(read func(sector, block uint8) ([]byte, error), sector uint8) (byte, error) {
+	trailer, err := read(sector, classicTrailerBlock(sector))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read sector %d trailer: %w", sector, err)
+	}
+	if len(trailer) < 16 {
+		return 0, fmt.Errorf("sector trailer must be 16 bytes, got %d", len(trailer))
+	}
+	return trailer[trailerGPBOffset], nil
+}
+
+// gpbKeys are the keys readSectorGPBs tries as key A, in order: the MAD key
+// and NFC Forum key that formatting writes, then the factory key.
+var gpbKeys = [][]byte{KeyMAD, KeyNFCForum, KeyDefault}
+
+// readSectorGPBs reads the general purpose byte of every sector of tag whose
+// trailer can be read with one of gpbKeys. Sectors with other keys are left
+// out.
+func readSectorGPBs(tag ClassicTag, sectors int) map[int]byte {
+	gpbs := make(map[int]byte, sectors)
+	for sector := range sectors {
+		for _, key := range gpbKeys {
+			if gpb, err := tag.ReadGPB(uint8(sector), key, KeyTypeA); err == nil {
+				gpbs[sector] = gpb
+				break
+			}
+		}
+	}
+	return gpbs
+}
+
+// madCRC computes the MAD CRC-8 over data (info byte followed by the AIDs).
+func madCRC(data []byte) byte {
+	crc := byte(madCRCPreset)
+	for _, b := range data {
+		crc ^= b
+		for i := 0; i < 8; i++ {
+			if crc&0x80 != 0 {
+				crc = (crc << 1) ^ madCRCPoly
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+// buildMAD returns the MAD bytes ([CRC][info][AID]...) for the given number of
+// application sectors, all assigned to the NDEF AID. MAD1 covers 15 sectors
+// (32 bytes, blocks 1-2 of sector 0) and MAD2 covers 23 sectors (48 bytes,
+// blocks 0-2 of sector 16).
+func buildMAD(sectors int) []byte {
+	aids := make([]uint16, sectors)
+	for i := range aids {
+		aids[i] = MADAIDNDEF
+	}
+	return encodeMAD(madInfoByte, aids)
+}
+
+// encodeMAD returns the MAD bytes ([CRC][info][AID]...) assigning aids to
+// consecutive sectors.
+func encodeMAD(info byte, aids []uint16) []byte {
+	mad := make([]byte, 2+len(aids)*2)
+	mad[1] = info
+	for i, aid := range aids {
+		mad[2+i*2] = byte(aid & 0xFF)
+		mad[3+i*2] = byte(aid >> 8)
+	}
+	mad[0] = madCRC(mad[1:])
+	return mad
+}
+
+// buildSectorTrailer returns a 16-byte sector trailer: Key A, access bits, GPB, Key B.
+func buildSectorTrailer(keyA []byte, access [3]byte, gpb byte, keyB []byte) []byte {
+	trailer := make([]byte, 0, 16)
+	trailer = append(trailer, keyA...)
+	trailer = append(trailer, access[:]...)
+	trailer = append(trailer, gpb)
+	return append(trailer, keyB...)
+}
+
+// Access bits used when formatting for NDEF:
+//   - MAD sectors: data readable with Key A/B, writable with Key B only
+//   - NDEF sectors: data readable and writable with Key A/B, trailer writable with Key B
+var (
+	madSectorAccess  = [3]byte{0x78, 0x77, 0x88}
+	ndefSectorAccess = [3]byte{0x7F, 0x07, 0x88}
+)
+
+// Access bits used when making NDEF sectors read-only:
+//   - locked: data readable with Key A/B, never writable; trailer permanently locked
+//   - admin: data readable with Key A/B, never writable; trailer still writable with Key B
+var (
+	ndefReadOnlyAccess      = [3]byte{0x07, 0x8F, 0x0F}
+	ndefReadOnlyAdminAccess = [3]byte{0x0F, 0x07, 0x8F}
+)
+
+// classicDataBlocksWritable reports whether the access bits of a sector
+// trailer (bytes 6-8) let some key write every data block of the sector.
+// Access conditions 010, 001, 101 and 111 allow no writes at all.
+func classicDataBlocksWritable(access []byte) bool {
+	for group := 0; group < 3; group++ {
+		c1 := access[1] >> (4 + group) & 1
+		c2 := access[2] >> group & 1
+		c3 := access[2] >> (4 + group) & 1
+		switch c1<<2 | c2<<1 | c3 {
+		case 0b010, 0b001, 0b101, 0b111:
+			return false
+		}
+	}
+	return true
+}
+
+// ndefReadOnlyGPB is the general purpose byte for read-only NDEF sectors (version 1.0, read-only)
+const ndefReadOnlyGPB = 0x43
+
+// readOnlyTrailer resolves opts and returns the sector trailer written to lock
+// an NDEF sector, together with the key used to authenticate before writing it.
+func readOnlyTrailer(opts ReadOnlyOptions) (trailer, authKey []byte, authKeyType int, err error) {
+	authKey = opts.TrailerKey
+	if authKey == nil {
+		authKey = KeyDefault
+	}
+	authKeyType = opts.TrailerKeyType
+	if authKeyType == 0 {
+		authKeyType = KeyTypeB
+	}
+	if len(authKey) != 6 {
+		return nil, nil, 0, fmt.Errorf("trailer key must be 6 bytes, got %d", len(authKey))
+	}
+	if authKeyType != KeyTypeA && authKeyType != KeyTypeB {
+		return nil, nil, 0, fmt.Errorf("invalid trailer key type: must be KeyTypeA (0x60) or KeyTypeB (0x61)")
+	}
+
+	if !opts.KeepKeyB {
+		keyB := opts.KeyB
+		if keyB == nil {
+			keyB = authKey
+		}
+		return buildSectorTrailer(KeyNFCForum, ndefReadOnlyAccess, ndefReadOnlyGPB, keyB), authKey, authKeyType, nil
+	}
+
+	keyB := opts.KeyB
+	if keyB == nil && authKeyType == KeyTypeB {
+		keyB = authKey
+	}
+	if len(keyB) != 6 {
+		return nil, nil, 0, fmt.Errorf("KeepKeyB requires a 6-byte KeyB")
+	}
+	return buildSectorTrailer(KeyNFCForum, ndefReadOnlyAdminAccess, ndefReadOnlyGPB, keyB), authKey, authKeyType, nil
+}
@@ -0,0 +1,188 @@
+package nfc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestBuildMAD1(t *testing.T) {
+	mad := buildMAD(15)
+
+	if len(mad) != 32 {
+		t.Fatalf("Expected MAD1 length 32, got %d", len(mad))
+	}
+
+	// Reference MAD1 for a fully NDEF-formatted 1K card (NXP AN1304)
+	expected := []byte{0x14, 0x01}
+	for i := 0; i < 15; i++ {
+		expected = append(expected, 0x03, 0xE1)
+	}
+
+	if !bytes.Equal(mad, expected) {
+		t.Errorf("MAD1 mismatch:\n got  %X\n want %X", mad, expected)
+	}
+}
+
+func TestBuildMAD2(t *testing.T) {
+	mad := buildMAD(23)
+
+	if len(mad) != 48 {
+		t.Fatalf("Expected MAD2 length 48, got %d", len(mad))
+	}
+	if mad[0] != madCRC(mad[1:]) {
+		t.Errorf("MAD2 CRC mismatch: got 0x%02X, want 0x%02X", mad[0], madCRC(mad[1:]))
+	}
+}
+
+func TestBuildSectorTrailer(t *testing.T) {
+	trailer := buildSectorTrailer(KeyNFCForum, ndefSectorAccess, ndefSectorGPB, KeyDefault)
+
+	expected := []byte{
+		0xD3, 0xF7, 0xD3, 0xF7, 0xD3, 0xF7,
+		0x7F, 0x07, 0x88, 0x40,
+		0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF,
+	}
+	if !bytes.Equal(trailer, expected) {
+		t.Errorf("Trailer mismatch:\n got  %X\n want %X", trailer, expected)
+	}
+}
+
+// accessConditions decodes the C1C2C3 bits of each block (0-2, trailer) from
+// the three access bytes, failing if the inverted copies don't match.
+func accessConditions(t *testing.T, access []byte) [4]byte {
+	t.Helper()
+	c1, c2, c3 := access[1]>>4, access[2]&0x0F, access[2]>>4
+	if ^access[0]&0x0F != c1 || ^access[0]>>4 != c2 || ^access[1]&0x0F != c3 {
+		t.Fatalf("access bytes %X have inconsistent inverted bits", access)
+	}
+	var conds [4]byte
+	for i := 0; i < 4; i++ {
+		conds[i] = (c1>>i&1)<<2 | (c2>>i&1)<<1 | c3>>i&1
+	}
+	return conds
+}
+
+func TestReadOnlyTrailer(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		trailer, key, keyType, err := readOnlyTrailer(ReadOnlyOptions{})
+		if err != nil {
+			t.Fatalf("readOnlyTrailer() error = %v", err)
+		}
+		if !bytes.Equal(key, KeyDefault) || keyType != KeyTypeB {
+			t.Errorf("auth key = %X/0x%02X, want KeyDefault/KeyTypeB", key, keyType)
+		}
+		// Data blocks read-only (010), trailer permanently locked (110)
+		if got := accessConditions(t, trailer[6:9]); got != [4]byte{2, 2, 2, 6} {
+			t.Errorf("access conditions = %v, want [2 2 2 6]", got)
+		}
+		if trailer[9] != ndefReadOnlyGPB {
+			t.Errorf("GPB = 0x%02X, want 0x%02X", trailer[9], ndefReadOnlyGPB)
+		}
+	})
+
+	t.Run("keep key B", func(t *testing.T) {
+		trailer, key, keyType, err := readOnlyTrailer(ReadOnlyOptions{
+			TrailerKey:     KeyDefault,
+			TrailerKeyType: KeyTypeB,
+			KeepKeyB:       true,
+		})
+		if err != nil {
+			t.Fatalf("readOnlyTrailer() error = %v", err)
+		}
+		if !bytes.Equal(key, KeyDefault) || keyType != KeyTypeB {
+			t.Errorf("auth key = %X/0x%02X, want KeyDefault/KeyTypeB", key, keyType)
+		}
+		// Data blocks read-only (010), trailer writable with Key B (011)
+		if got := accessConditions(t, trailer[6:9]); got != [4]byte{2, 2, 2, 3} {
+			t.Errorf("access conditions = %v, want [2 2 2 3]", got)
+		}
+		if !bytes.Equal(trailer[10:], KeyDefault) {
+			t.Errorf("Key B = %X, want %X", trailer[10:], KeyDefault)
+		}
+	})
+
+	t.Run("keep key B without key", func(t *testing.T) {
+		if _, _, _, err := readOnlyTrailer(ReadOnlyOptions{TrailerKeyType: KeyTypeA, KeepKeyB: true}); err == nil {
+			t.Error("Expected error when KeepKeyB has no Key B to keep")
+		}
+	})
+
+	t.Run("invalid key", func(t *testing.T) {
+		if _, _, _, err := readOnlyTrailer(ReadOnlyOptions{TrailerKey: []byte{0x01}}); err == nil {
+			t.Error("Expected error for short trailer key")
+		}
+	})
+}
+
+// madCard returns a read function over a card formatted with buildMAD and
+// the given sector 0 GPB, and the blocks map for tampering with it.
+func madCard(gpb byte) (func(sector, block uint8) ([]byte, error), map[string][]byte) {
+	blocks := map[string][]byte{
+		"0:3": buildSectorTrailer(KeyMAD, madSectorAccess, gpb, KeyDefault),
+	}
+	mad1, mad2 := buildMAD(mad1Sectors), buildMAD(mad2Sectors)
+	mad1[4], mad1[5] = 0x04, 0x00 // sector 2: card holder info
+	mad1[0] = madCRC(mad1[1:])
+	blocks["0:1"], blocks["0:2"] = mad1[:16], mad1[16:]
+	blocks["16:0"], blocks["16:1"], blocks["16:2"] = mad2[:16], mad2[16:32], mad2[32:]
+
+	return func(sector, block uint8) ([]byte, error) {
+		data, ok := blocks[fmt.Sprintf("%d:%d", sector, block)]
+		if !ok {
+			return nil, fmt.Errorf("authentication failed for sector %d", sector)
+		}
+		return data, nil
+	}, blocks
+}
+
+func TestReadMADEntries(t *testing.T) {
+	t.Run("MAD1", func(t *testing.T) {
+		read, _ := madCard(madGPBv1)
+		entries, err := readMADEntries(read, 16)
+		if err != nil {
+			t.Fatalf("readMADEntries() error = %v", err)
+		}
+		if len(entries) != 15 || entries[0].Sector != 1 || entries[14].Sector != 15 {
+			t.Fatalf("Expected sectors 1-15, got %+v", entries)
+		}
+		if entries[1].AID != 0x0004 || entries[1].Application() != "card holder info" {
+			t.Errorf("Sector 2 = %+v, want card holder info", entries[1])
+		}
+		if entries[0].AID != MADAIDNDEF || entries[0].Application() != "NDEF" {
+			t.Errorf("Sector 1 = %+v, want NDEF", entries[0])
+		}
+	})
+
+	t.Run("MAD2 on 4K", func(t *testing.T) {
+		read, _ := madCard(madGPBv2)
+		entries, err := readMADEntries(read, 40)
+		if err != nil {
+			t.Fatalf("readMADEntries() error = %v", err)
+		}
+		if len(entries) != 38 || entries[15].Sector != 17 || entries[37].Sector != 39 {
+			t.Errorf("Expected sectors 1-15 and 17-39, got %d entries", len(entries))
+		}
+	})
+
+	t.Run("CRC mismatch is reported with the entries", func(t *testing.T) {
+		read, blocks := madCard(madGPBv2)
+		blocks["16:0"][0] ^= 0xFF
+
+		entries, err := readMADEntries(read, 40)
+		if !errors.Is(err, ErrMADCRC) {
+			t.Fatalf("Expected ErrMADCRC, got %v", err)
+		}
+		if len(entries) != 38 {
+			t.Errorf("Expected the entries despite the mismatch, got %d", len(entries))
+		}
+	})
+
+	t.Run("no MAD", func(t *testing.T) {
+		read, _ := madCard(0x00)
+		if _, err := readMADEntries(read, 16); err == nil || errors.Is(err, ErrMADCRC) {
+			t.Errorf("Expected a no-MAD error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,147 @@
+package nfc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+// MagicInfo is the result of probing a MIFARE Classic card for the gen1a
+// "magic" backdoor.
+type MagicInfo struct {
+	// Magic is set when the card acknowledged the gen1a unlock commands
+	// (0x40 then 0x43), which genuine cards ignore.
+	Magic bool
+
+	// Block0Writable is set when the unlocked card also returned block 0
+	// without authentication, so it would accept a write to block 0 (the UID).
+	// Nothing is written to find out.
+	Block0Writable bool
+}
+
+// PN53x registers and commands used to send the 7-bit unlock frame through
+// readers built on the NXP PN53x (ACR122U and similar).
+const (
+	pn53xCmdWriteRegister     = 0x08
+	pn53xCmdInCommunicateThru = 0x42
+
+	pn53xRegTxMode     = 0x6302 // Bit 7 enables the TX CRC
+	pn53xRegRxMode     = 0x6303 // Bit 7 enables the RX CRC
+	pn53xRegBitFraming = 0x633D // Bits 0-2 are the bits of the last byte sent
+)
+
+// MIFARE Classic commands of the gen1a probe
+const (
+	classicCmdHalt         = 0x50
+	classicCmdRead         = 0x30
+	classicCmdGen1aUnlock1 = 0x40 // Sent as a 7-bit frame
+	classicCmdGen1aUnlock2 = 0x43
+	classicACK             = 0x0A
+)
+
+// errNoPN53xResponse is returned for PN53x answers that are not a response
+// to the command sent.
+var errNoPN53xResponse = errors.New("unexpected PN53x response")
+
+// pn53xAPDU wraps a PN53x command (without the 0xD4 host-to-chip prefix) in
+// the PC/SC direct transmit pseudo-APDU.
+func pn53xAPDU(cmd ...byte) []byte {
+	return BuildAPDU(CLAPCSC, INSDirectCmd, 0x00, 0x00, append([]byte{0xD4}, cmd...), nil)
+}
+
+// pn53xRegister is a PN53x register address and the value to write to it.
+type pn53xRegister struct {
+	addr  uint16
+	value byte
+}
+
+// pn53xWriteRegisters returns the APDU writing each register in turn.
+func pn53xWriteRegisters(regs ...pn53xRegister) []byte {
+	cmd := []byte{pn53xCmdWriteRegister}
+	for _, reg := range regs {
+		cmd = append(cmd, byte(reg.addr>>8), byte(reg.addr), reg.value)
+	}
+	return pn53xAPDU(cmd...)
+}
+
+// crcA computes the ISO/IEC 14443-3 Type A CRC of data, least significant
+// byte first, for frames sent while the reader's own CRC is off.
+func crcA(data []byte) []byte {
+	crc := uint16(0x6363)
+	for _, b := range data {
+		b ^= byte(crc)
+		b ^= b << 4
+		crc = crc>>8 ^ uint16(b)<<8 ^ uint16(b)<<3 ^ uint16(b)>>4
+	}
+	return []byte{byte(crc), byte(crc >> 8)}
+}
+
+// communicateThru sends frame to the card with InCommunicateThru and returns
+// the card's answer.
+func (t *pcscClassicTag) communicateThru(frame ...byte) ([]byte, error) {
+	resp, err := t.transceive(pn53xAPDU(append([]byte{pn53xCmdInCommunicateThru}, frame...)...))
+	if err != nil {
+		return nil, err
+	}
+	if len(resp) < 3 || resp[0] != 0xD5 || resp[1] != pn53xCmdInCommunicateThru+1 {
+		return nil, fmt.Errorf("%w: % X", errNoPN53xResponse, resp)
+	}
+	if status := resp[2] & 0x3F; status != 0 {
+		return nil, fmt.Errorf("card did not answer (PN53x status 0x%02X)", status)
+	}
+	return resp[3:], nil
+}
+
+// ProbeMagic checks whether the card is a gen1a "magic" card. It halts the
+// card and sends the gen1a unlock sequence, then tries to read block 0
+// without authentication. Nothing is written, but the card is left halted and
+// is picked up again by the next poll.
+//
+// The unlock needs a 7-bit frame, which is only possible through readers
+// built on the PN53x chip such as the ACR122U; on other readers the probe
+// fails rather than reporting the card as genuine.
+func (t *pcscClassicTag) ProbeMagic() (MagicInfo, error) {
+	// Raw frames: the CRC is added here, not by the reader
+	crcOff := pn53xWriteRegisters(pn53xRegister{pn53xRegTxMode, 0x00}, pn53xRegister{pn53xRegRxMode, 0x00})
+	if _, err := t.transceive(crcOff); err != nil {
+		return MagicInfo{}, fmt.Errorf("reader does not accept PN53x commands: %w", err)
+	}
+	defer func() {
+		_, _ = t.transceive(pn53xWriteRegisters(
+			pn53xRegister{pn53xRegTxMode, 0x80},
+			pn53xRegister{pn53xRegRxMode, 0x80},
+			pn53xRegister{pn53xRegBitFraming, 0x00},
+		))
+	}()
+
+	// HALT has no answer; a timeout status is expected
+	halt := []byte{classicCmdHalt, 0x00}
+	_, _ = t.communicateThru(append(halt, crcA(halt)...)...)
+
+	if _, err := t.transceive(pn53xWriteRegisters(pn53xRegister{pn53xRegBitFraming, 0x07})); err != nil {
+		return MagicInfo{}, fmt.Errorf("failed to set 7-bit framing: %w", err)
+	}
+	resp, err := t.communicateThru(classicCmdGen1aUnlock1)
+	if errors.Is(err, errNoPN53xResponse) {
+		return MagicInfo{}, err
+	}
+	if err != nil || !bytes.Equal(resp, []byte{classicACK}) {
+		return MagicInfo{}, nil // Genuine cards stay silent
+	}
+
+	if _, err := t.transceive(pn53xWriteRegisters(pn53xRegister{pn53xRegBitFraming, 0x00})); err != nil {
+		return MagicInfo{}, fmt.Errorf("failed to restore 8-bit framing: %w", err)
+	}
+	resp, err = t.communicateThru(classicCmdGen1aUnlock2)
+	if err != nil || !bytes.Equal(resp, []byte{classicACK}) {
+		return MagicInfo{}, nil
+	}
+	info := MagicInfo{Magic: true}
+
+	read := []byte{classicCmdRead, 0x00}
+	resp, err = t.communicateThru(append(read, crcA(read)...)...)
+	if err == nil && len(resp) == 18 && bytes.Equal(crcA(resp[:16]), resp[16:]) {
+		info.Block0Writable = true
+	}
+	return info, nil
+}
@@ -0,0 +1,93 @@
+package nfc
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestCRCA(t *testing.T) {
+	tests := []struct {
+		data string
+		want string
+	}{
+		{"5000", "57CD"}, // HALT
+		{"3000", "02A8"}, // READ block 0
+	}
+	for _, tt := range tests {
+		data, _ := hex.DecodeString(tt.data)
+		if got := strings.ToUpper(hex.EncodeToString(crcA(data))); got != tt.want {
+			t.Errorf("crcA(%s) = %s, want %s", tt.data, got, tt.want)
+		}
+	}
+}
+
+// addMagicProbe scripts a PN53x reader for ProbeMagic. unlockResp is the
+// InCommunicateThru answer to the 7-bit 0x40; block0 is the answer to the
+// unauthenticated read of block 0.
+func (m *mockScardCard) addMagicProbe(unlockResp, block0 string) {
+	apdu := func(cmd ...byte) string { return hex.EncodeToString(pn53xAPDU(cmd...)) }
+	thru := func(frame ...byte) string {
+		return apdu(append([]byte{pn53xCmdInCommunicateThru}, frame...)...)
+	}
+
+	m.addResponse(hex.EncodeToString(pn53xWriteRegisters(pn53xRegister{pn53xRegTxMode, 0x00}, pn53xRegister{pn53xRegRxMode, 0x00})), "D5099000")
+	m.addResponse(hex.EncodeToString(pn53xWriteRegisters(pn53xRegister{pn53xRegBitFraming, 0x07})), "D5099000")
+	m.addResponse(hex.EncodeToString(pn53xWriteRegisters(pn53xRegister{pn53xRegBitFraming, 0x00})), "D5099000")
+	m.addResponse(thru(0x50, 0x00, 0x57, 0xCD), "D543019000")
+	m.addResponse(thru(classicCmdGen1aUnlock1), unlockResp)
+	m.addResponse(thru(classicCmdGen1aUnlock2), "D543000A9000")
+	m.addResponse(thru(0x30, 0x00, 0x02, 0xA8), block0)
+}
+
+func TestPCSCClassicTag_ProbeMagic(t *testing.T) {
+	block0, _ := hex.DecodeString("DEADBEEF220804000102030405060708")
+	block0Resp := "D54300" + hex.EncodeToString(append(block0, crcA(block0)...)) + "9000"
+
+	tests := []struct {
+		name    string
+		unlock  string
+		block0  string
+		want    MagicInfo
+		wantErr bool
+	}{
+		{"genuine card stays silent", "D543019000", block0Resp, MagicInfo{}, false},
+		{"gen1a card", "D543000A9000", block0Resp, MagicInfo{Magic: true, Block0Writable: true}, false},
+		{"block 0 read refused", "D543000A9000", "D543019000", MagicInfo{Magic: true}, false},
+		{"not a PN53x reader", "", "", MagicInfo{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			card := newMockScardCard()
+			if tt.unlock != "" {
+				card.addMagicProbe(tt.unlock, tt.block0)
+			}
+			tag := newPCSCClassicTag(newMockPCSCDevice(card, pcscATR(0x01)), "DEADBEEF", DetectedClassic1K)
+
+			got, err := tag.ProbeMagic()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ProbeMagic() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ProbeMagic() = %+v, want %+v", got, tt.want)
+			}
+
+			// The reader's CRC and framing are restored after a probe that got going
+			restore := hex.EncodeToString(pn53xWriteRegisters(
+				pn53xRegister{pn53xRegTxMode, 0x80},
+				pn53xRegister{pn53xRegRxMode, 0x80},
+				pn53xRegister{pn53xRegBitFraming, 0x00},
+			))
+			last := hex.EncodeToString(card.callLog[len(card.callLog)-1])
+			if !tt.wantErr && last != restore {
+				t.Errorf("Last command = %s, want register restore %s", last, restore)
+			}
+			for _, cmd := range card.callLog {
+				if cmd[1] == INSUpdateBin {
+					t.Errorf("ProbeMagic() wrote to the card: % X", cmd)
+				}
+			}
+		})
+	}
+}
@@ -1,5 +1,10 @@
 package nfc
 
+import (
+	"fmt"
+	"time"
+)
+
 // Manager handles NFC device discovery.
 //
 // Manager provides methods to list available NFC readers and open connections
@@ -23,6 +28,107 @@ type DeviceChangeNotifier interface {
 	DeviceChanges() <-chan struct{}
 }
 
+// EnumerationConfigurer is optionally implemented by Managers that retry
+// device enumeration, such as the PC/SC manager.
+type EnumerationConfigurer interface {
+	// SetEnumerationRetry sets how many times ListDevices tries to enumerate
+	// readers and how long it waits between attempts. Non-positive values
+	// restore the defaults (DeviceEnumRetries and DeviceEnumDelay).
+	SetEnumerationRetry(retries int, delay time.Duration)
+}
+
+// DeviceResetter is optionally implemented by Managers that can reset reader
+// hardware below the level of closing and reopening it, e.g. with a USB port
+// reset, to recover readers that stop responding until they are replugged.
+type DeviceResetter interface {
+	// ResetDevice resets the reader deviceStr names, as passed to OpenDevice.
+	ResetDevice(deviceStr string) error
+}
+
+// UnsupportedTagPolicy selects how a device reports a card it cannot read.
+type UnsupportedTagPolicy int
+
+const (
+	// UnsupportedTagError reports the card once as an unsupported tag error (default).
+	UnsupportedTagError UnsupportedTagPolicy = iota
+	// UnsupportedTagIgnore reports no tags, as if no card were present.
+	UnsupportedTagIgnore
+	// UnsupportedTagRaw reports a minimal tag of type CardTypeUnknown carrying
+	// only the UID and ATR, so clients can show that a card is present.
+	UnsupportedTagRaw
+	// UnsupportedTagEvent reports the card once like UnsupportedTagError and
+	// also emits an NFCData carrying its UID and ATR in Unsupported.
+	UnsupportedTagEvent
+)
+
+// String returns the policy name as accepted by ParseUnsupportedTagPolicy.
+func (p UnsupportedTagPolicy) String() string {
+	switch p {
+	case UnsupportedTagError:
+		return "error"
+	case UnsupportedTagIgnore:
+		return "ignore"
+	case UnsupportedTagRaw:
+		return "raw"
+	case UnsupportedTagEvent:
+		return "event"
+	default:
+		return fmt.Sprintf("UnsupportedTagPolicy(%d)", int(p))
+	}
+}
+
+// ParseUnsupportedTagPolicy parses "error", "ignore", "raw" or "event" into an UnsupportedTagPolicy.
+func ParseUnsupportedTagPolicy(s string) (UnsupportedTagPolicy, error) {
+	switch s {
+	case "error", "":
+		return UnsupportedTagError, nil
+	case "ignore":
+		return UnsupportedTagIgnore, nil
+	case "raw":
+		return UnsupportedTagRaw, nil
+	case "event":
+		return UnsupportedTagEvent, nil
+	default:
+		return UnsupportedTagError, fmt.Errorf("unknown unsupported tag policy %q (expected error, ignore, raw or event)", s)
+	}
+}
+
+// UnsupportedTagConfigurer is optionally implemented by Managers whose devices
+// detect the tag type themselves, such as the PC/SC manager.
+type UnsupportedTagConfigurer interface {
+	// SetUnsupportedTagPolicy sets how devices opened from now on report
+	// cards they cannot read.
+	SetUnsupportedTagPolicy(p UnsupportedTagPolicy)
+}
+
+// Type4PreSelectConfigurer is optionally implemented by Managers that talk
+// to Type 4 tags over APDUs, such as the PC/SC manager.
+type Type4PreSelectConfigurer interface {
+	// SetType4PreSelect sets APDUs sent, in order, to Type 4 tags on devices
+	// opened from now on before the NDEF application is selected. Closed
+	// systems use this to select a proprietary application and verify a PIN
+	// before NDEF becomes selectable. A nil list disables the sequence.
+	SetType4PreSelect(apdus [][]byte)
+}
+
+// MonitorStopConfigurer is optionally implemented by Managers whose devices
+// watch for card removal in the background, such as the PC/SC manager.
+type MonitorStopConfigurer interface {
+	// SetMonitorStopTimeout sets how long closing a device opened from now on
+	// waits for its card monitor to stop. Non-positive values restore the
+	// default (DefaultMonitorStopTimeout).
+	SetMonitorStopTimeout(timeout time.Duration)
+}
+
+// ClassicWriteDelayConfigurer is optionally implemented by Managers that
+// write MIFARE Classic cards block by block, such as the PC/SC manager.
+type ClassicWriteDelayConfigurer interface {
+	// SetClassicWriteDelay sets a pause between block writes to Classic cards
+	// on devices opened from now on, for clones that drop back-to-back
+	// writes. Zero, the default, writes without pausing.
+	SetClassicWriteDelay(delay time.Duration)
+}
+
 // NewManager creates a new Manager using the PC/SC implementation.
 //
 // Example:
@@ -31,6 +31,13 @@ type MockManager struct {
 	// OpenDeviceError, if set, will be returned by OpenDevice()
 	OpenDeviceError error
 
+	// ResetDeviceError, if set, will be returned by ResetDevice()
+	ResetDeviceError error
+
+	// OpenDeviceBlock, if set, makes OpenDevice() wait until it is closed,
+	// simulating a reader that hangs while opening
+	OpenDeviceBlock chan struct{}
+
 	// CallLog tracks all method calls for verification in tests
 	CallLog []string
 
@@ -48,6 +55,13 @@ func NewMockManager() *MockManager {
 
 // OpenDevice simulates opening an NFC device.
 func (m *MockManager) OpenDevice(deviceStr string) (Device, error) {
+	m.mu.Lock()
+	block := m.OpenDeviceBlock
+	m.mu.Unlock()
+	if block != nil {
+		<-block
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -61,10 +75,23 @@ func (m *MockManager) OpenDevice(deviceStr string) (Device, error) {
 		m.MockDevice = NewMockDevice()
 	}
 
+	// Opening yields an open device, as a real manager's would
+	m.MockDevice.mu.Lock()
 	m.MockDevice.DeviceConnection = deviceStr
+	m.MockDevice.IsOpen = true
+	m.MockDevice.mu.Unlock()
 	return m.MockDevice, nil
 }
 
+// ResetDevice simulates a hardware reset (implements DeviceResetter).
+func (m *MockManager) ResetDevice(deviceStr string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.CallLog = append(m.CallLog, fmt.Sprintf("ResetDevice(%s)", deviceStr))
+	return m.ResetDeviceError
+}
+
 // ListDevices simulates listing available NFC devices.
 func (m *MockManager) ListDevices() ([]string, error) {
 	m.mu.Lock()
@@ -14,11 +14,80 @@ type pcscManager struct {
 	ctx       *scard.Context
 	ctxMu     sync.Mutex
 	lastCheck time.Time
+
+	enumRetries int
+	enumDelay   time.Duration
+
+	unsupportedPolicy  UnsupportedTagPolicy
+	type4PreSelect     [][]byte
+	monitorStopTimeout time.Duration
+	classicWriteDelay  time.Duration
 }
 
 // newPCSCManager creates a new PC/SC manager
 func newPCSCManager() *pcscManager {
-	return &pcscManager{}
+	return &pcscManager{
+		enumRetries:        DeviceEnumRetries,
+		enumDelay:          DeviceEnumDelay,
+		monitorStopTimeout: DefaultMonitorStopTimeout,
+	}
+}
+
+// SetEnumerationRetry configures the ListDevices retry count and delay.
+// Non-positive values restore the defaults.
+func (m *pcscManager) SetEnumerationRetry(retries int, delay time.Duration) {
+	if retries <= 0 {
+		retries = DeviceEnumRetries
+	}
+	if delay <= 0 {
+		delay = DeviceEnumDelay
+	}
+
+	m.ctxMu.Lock()
+	m.enumRetries = retries
+	m.enumDelay = delay
+	m.ctxMu.Unlock()
+}
+
+// SetUnsupportedTagPolicy sets how devices opened from now on report cards
+// they cannot read.
+func (m *pcscManager) SetUnsupportedTagPolicy(p UnsupportedTagPolicy) {
+	m.ctxMu.Lock()
+	m.unsupportedPolicy = p
+	m.ctxMu.Unlock()
+}
+
+// SetType4PreSelect sets the APDUs sent to Type 4 tags before the NDEF
+// application is selected, for devices opened from now on.
+func (m *pcscManager) SetType4PreSelect(apdus [][]byte) {
+	m.ctxMu.Lock()
+	m.type4PreSelect = apdus
+	m.ctxMu.Unlock()
+}
+
+// SetMonitorStopTimeout sets how long closing a device opened from now on
+// waits for its card monitor to stop. Non-positive values restore
+// DefaultMonitorStopTimeout.
+func (m *pcscManager) SetMonitorStopTimeout(timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = DefaultMonitorStopTimeout
+	}
+
+	m.ctxMu.Lock()
+	m.monitorStopTimeout = timeout
+	m.ctxMu.Unlock()
+}
+
+// SetClassicWriteDelay sets the pause between block writes to Classic cards
+// on devices opened from now on. Negative values are treated as zero.
+func (m *pcscManager) SetClassicWriteDelay(delay time.Duration) {
+	if delay < 0 {
+		delay = 0
+	}
+
+	m.ctxMu.Lock()
+	m.classicWriteDelay = delay
+	m.ctxMu.Unlock()
 }
 
 // ensureContext ensures we have a valid PC/SC context
@@ -54,6 +123,10 @@ func (m *pcscManager) OpenDevice(deviceStr string) (Device, error) {
 
 	m.ctxMu.Lock()
 	ctx := m.ctx
+	unsupportedPolicy := m.unsupportedPolicy
+	type4PreSelect := m.type4PreSelect
+	monitorStopTimeout := m.monitorStopTimeout
+	classicWriteDelay := m.classicWriteDelay
 	m.ctxMu.Unlock()
 
 	// If no device specified, use the first available reader
@@ -71,6 +144,11 @@ func (m *pcscManager) OpenDevice(deviceStr string) (Device, error) {
 		}
 
 		readerName = readers[0]
+	} else if readers, err := ctx.ListReaders(); err == nil {
+		// Resolve partial or differently-cased names (e.g. "acr122") to the full reader name
+		if match, ok := MatchReaderName(readers, deviceStr); ok {
+			readerName = match
+		}
 	}
 
 	// Check if a card is present before attempting to connect
@@ -100,15 +178,51 @@ func (m *pcscManager) OpenDevice(deviceStr string) (Device, error) {
 	}
 
 	// Create device wrapper
-	dev, err := newPCSCDevice(ctx, card, readerName)
+	dev, err := newPCSCDevice(ctx, card, readerName, unsupportedPolicy)
 	if err != nil {
 		card.Disconnect(scard.LeaveCard)
 		return nil, fmt.Errorf("failed to initialize device: %w", err)
 	}
+	dev.type4PreSelect = type4PreSelect
+	dev.monitorStopTimeout = monitorStopTimeout
+	dev.classicWriteDelay = classicWriteDelay
 
 	return dev, nil
 }
 
+// ResetDevice resets the reader's USB device (Linux only). The reader drops
+// off the bus and comes back as if replugged; PC/SC picks it up again once
+// it has re-enumerated.
+func (m *pcscManager) ResetDevice(deviceStr string) error {
+	if err := m.ensureContext(); err != nil {
+		return err
+	}
+
+	m.ctxMu.Lock()
+	ctx := m.ctx
+	m.ctxMu.Unlock()
+
+	readers, err := ctx.ListReaders()
+	if err != nil {
+		return fmt.Errorf("failed to list readers: %w", err)
+	}
+
+	// Resolve the reader the same way OpenDevice does
+	var readerName string
+	if deviceStr == "" {
+		if contactless := filterContactlessReaders(readers); len(contactless) > 0 {
+			readerName = contactless[0]
+		}
+	} else {
+		readerName, _ = MatchReaderName(readers, deviceStr)
+	}
+	if readerName == "" {
+		return fmt.Errorf("reader %q not found", deviceStr)
+	}
+
+	return usbResetReader(readerName)
+}
+
 // isCardPresent checks if a card is present in the reader using GetStatusChange
 // with a very short timeout to avoid blocking.
 func (m *pcscManager) isCardPresent(ctx *scard.Context, readerName string) (bool, error) {
@@ -141,10 +255,14 @@ func (m *pcscManager) ListDevices() ([]string, error) {
 	var readers []string
 	var lastErr error
 
-	for i := 0; i < DeviceEnumRetries; i++ {
+	m.ctxMu.Lock()
+	retries, delay := m.enumRetries, m.enumDelay
+	m.ctxMu.Unlock()
+
+	for i := 0; i < retries; i++ {
 		if err := m.ensureContext(); err != nil {
 			lastErr = err
-			time.Sleep(time.Millisecond * 100)
+			time.Sleep(delay)
 			continue
 		}
 
@@ -156,7 +274,7 @@ func (m *pcscManager) ListDevices() ([]string, error) {
 		readers, err = ctx.ListReaders()
 		if err != nil {
 			lastErr = err
-			time.Sleep(time.Millisecond * 100)
+			time.Sleep(delay)
 			continue
 		}
 
@@ -165,7 +283,7 @@ func (m *pcscManager) ListDevices() ([]string, error) {
 		return readers, nil
 	}
 
-	return nil, fmt.Errorf("failed to list PC/SC readers after %d retries: %w", DeviceEnumRetries, lastErr)
+	return nil, fmt.Errorf("failed to list PC/SC readers after %d retries: %w", retries, lastErr)
 }
 
 // DeviceChanges returns a channel that signals when devices change
@@ -3,6 +3,7 @@ package nfc
 import (
 	"fmt"
 	"testing"
+	"time"
 )
 
 func TestMockManager_ListDevices(t *testing.T) {
@@ -160,3 +161,50 @@ func TestMockManager_CallLog(t *testing.T) {
 		t.Errorf("Expected device call log to contain 'GetTags', got %v", deviceCallLog)
 	}
 }
+
+func TestPCSCManager_SetEnumerationRetry(t *testing.T) {
+	m := newPCSCManager()
+	if m.enumRetries != DeviceEnumRetries || m.enumDelay != DeviceEnumDelay {
+		t.Fatalf("Expected defaults %d/%v, got %d/%v", DeviceEnumRetries, DeviceEnumDelay, m.enumRetries, m.enumDelay)
+	}
+
+	var _ EnumerationConfigurer = m
+
+	m.SetEnumerationRetry(10, 500*time.Millisecond)
+	if m.enumRetries != 10 || m.enumDelay != 500*time.Millisecond {
+		t.Errorf("Expected 10/500ms, got %d/%v", m.enumRetries, m.enumDelay)
+	}
+
+	m.SetEnumerationRetry(0, -1)
+	if m.enumRetries != DeviceEnumRetries || m.enumDelay != DeviceEnumDelay {
+		t.Errorf("Expected non-positive values to restore defaults, got %d/%v", m.enumRetries, m.enumDelay)
+	}
+}
+
+func TestParseUnsupportedTagPolicy(t *testing.T) {
+	for _, policy := range []UnsupportedTagPolicy{UnsupportedTagError, UnsupportedTagIgnore, UnsupportedTagRaw, UnsupportedTagEvent} {
+		got, err := ParseUnsupportedTagPolicy(policy.String())
+		if err != nil || got != policy {
+			t.Errorf("ParseUnsupportedTagPolicy(%q) = %v, %v; want %v", policy.String(), got, err, policy)
+		}
+	}
+	if _, err := ParseUnsupportedTagPolicy("skip"); err == nil {
+		t.Error("Expected error for unknown policy")
+	}
+
+	var _ UnsupportedTagConfigurer = newPCSCManager()
+}
+
+func TestPCSCManager_SetClassicWriteDelay(t *testing.T) {
+	m := newPCSCManager()
+	var _ ClassicWriteDelayConfigurer = m
+
+	m.SetClassicWriteDelay(10 * time.Millisecond)
+	if m.classicWriteDelay != 10*time.Millisecond {
+		t.Errorf("classicWriteDelay = %v, want 10ms", m.classicWriteDelay)
+	}
+	m.SetClassicWriteDelay(-time.Second)
+	if m.classicWriteDelay != 0 {
+		t.Errorf("Expected a negative delay to be treated as zero, got %v", m.classicWriteDelay)
+	}
+}
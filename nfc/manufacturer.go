@@ -0,0 +1,48 @@
+package nfc
+
+import "fmt"
+
+// ManufacturerInfo holds the manufacturer data decoded from block 0 of a MIFARE Classic card.
+type ManufacturerInfo struct {
+	UID      []byte // UID as stored in block 0
+	SAK      []byte // Select acknowledge byte
+	ATQA     []byte // Answer to request (2 bytes, as stored)
+	BCCValid bool   // Whether the stored BCC matches the UID (always true for 7-byte UIDs, which store no BCC)
+}
+
+// computeBCC returns the block check character: the XOR of all UID bytes.
+func computeBCC(uid []byte) byte {
+	var bcc byte
+	for _, b := range uid {
+		bcc ^= b
+	}
+	return bcc
+}
+
+// ParseManufacturerBlock decodes block 0 of a MIFARE Classic card.
+//
+// Layout for 4-byte UIDs: UID(4) BCC(1) SAK(1) ATQA(2) manufacturer data(8).
+// Layout for 7-byte UIDs: UID(7) SAK(1) ATQA(2) manufacturer data(6). No BCC is
+// stored for 7-byte UIDs, so bccValid is reported as true.
+func ParseManufacturerBlock(block []byte, uidLen int) (uid, sak, atqa []byte, bccValid bool, err error) {
+	if len(block) < 16 {
+		return nil, nil, nil, false, fmt.Errorf("block 0 must be 16 bytes, got %d", len(block))
+	}
+
+	switch uidLen {
+	case 4:
+		uid = block[0:4]
+		bccValid = computeBCC(uid) == block[4]
+		sak = block[5:6]
+		atqa = block[6:8]
+	case 7:
+		uid = block[0:7]
+		bccValid = true
+		sak = block[7:8]
+		atqa = block[8:10]
+	default:
+		return nil, nil, nil, false, fmt.Errorf("unsupported UID length %d", uidLen)
+	}
+
+	return uid, sak, atqa, bccValid, nil
+}
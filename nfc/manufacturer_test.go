@@ -0,0 +1,58 @@
+package nfc
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestParseManufacturerBlock(t *testing.T) {
+	// 4-byte UID: DE AD BE EF, BCC = 0x22, SAK 08, ATQA 04 00
+	block4 := []byte{0xDE, 0xAD, 0xBE, 0xEF, 0x22, 0x08, 0x04, 0x00,
+		0x62, 0x63, 0x64, 0x65, 0x66, 0x67, 0x68, 0x69}
+	badBCC := append([]byte(nil), block4...)
+	badBCC[4] = 0x00
+	// 7-byte UID: 04 11 22 33 44 55 66, SAK 08, ATQA 44 00
+	block7 := []byte{0x04, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x08, 0x44, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00}
+
+	tests := []struct {
+		name     string
+		block    []byte
+		uidLen   int
+		wantUID  []byte
+		wantSAK  []byte
+		wantATQA []byte
+		wantBCC  bool
+		wantErr  bool
+	}{
+		{"4-byte UID", block4, 4, block4[0:4], []byte{0x08}, []byte{0x04, 0x00}, true, false},
+		{"4-byte UID bad BCC", badBCC, 4, block4[0:4], []byte{0x08}, []byte{0x04, 0x00}, false, false},
+		{"7-byte UID", block7, 7, block7[0:7], []byte{0x08}, []byte{0x44, 0x00}, true, false},
+		{"short block", block4[:8], 4, nil, nil, nil, false, true},
+		{"10-byte UID", block4, 10, nil, nil, nil, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uid, sak, atqa, bccValid, err := ParseManufacturerBlock(tt.block, tt.uidLen)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseManufacturerBlock() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !bytes.Equal(uid, tt.wantUID) {
+				t.Errorf("uid = %X, want %X", uid, tt.wantUID)
+			}
+			if !bytes.Equal(sak, tt.wantSAK) {
+				t.Errorf("sak = %X, want %X", sak, tt.wantSAK)
+			}
+			if !bytes.Equal(atqa, tt.wantATQA) {
+				t.Errorf("atqa = %X, want %X", atqa, tt.wantATQA)
+			}
+			if bccValid != tt.wantBCC {
+				t.Errorf("bccValid = %v, want %v", bccValid, tt.wantBCC)
+			}
+		})
+	}
+}
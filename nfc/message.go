@@ -1,6 +1,11 @@
 package nfc
 
-import "fmt"
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
 
 // Message represents data that can be written to/read from a card.
 // Different implementations handle different encoding schemes.
@@ -56,10 +61,27 @@ func (t *TextMessage) Bytes() []byte {
 	return t.Data
 }
 
+// NDEF is a second-chance parse of the raw bytes as an NDEF message, for
+// cards whose NDEF data reached the fallback path, e.g. still wrapped in its
+// NDEF Message TLV. It reports false if neither the bytes nor such a TLV in
+// them hold NDEF records.
+func (t *TextMessage) NDEF() (*NDEFMessage, bool) {
+	if records, err := parseNDEFRecords(t.Data); err == nil {
+		return &NDEFMessage{records: records}, true
+	}
+	if value, ok := TLVFindNDEF(t.Data); ok {
+		if records, err := parseNDEFRecords(value); err == nil {
+			return &NDEFMessage{records: records}, true
+		}
+	}
+	return nil, false
+}
+
 // NDEFMessage represents a structured NDEF message with multiple records.
 // This allows complex messages with multiple record types (text, URI, MIME, etc.)
 type NDEFMessage struct {
 	records []NDEFRecord
+	raw     []byte // Exact encoding from ParseRawNDEF, written verbatim until records change
 }
 
 // NDEFRecord represents a single NDEF record within a message.
@@ -96,6 +118,52 @@ func (r *NDEFRecord) GetURI() (string, bool) {
 	return uri, true
 }
 
+// URIComponents is the URI of a URI record broken into its parts.
+// When ParseError is set, URI holds the raw string and the components are
+// empty or, for a malformed query, partial.
+type URIComponents struct {
+	URI        string              `json:"uri"`                // Expanded URI, including the abbreviated prefix
+	Scheme     string              `json:"scheme,omitempty"`   // e.g. "https", "mailto", "tel"
+	Host       string              `json:"host,omitempty"`     // Host and port, if any
+	Path       string              `json:"path,omitempty"`     // Decoded path
+	Opaque     string              `json:"opaque,omitempty"`   // Scheme-specific part of URIs like "mailto:a@b.c"
+	Query      map[string][]string `json:"query,omitempty"`    // Decoded query parameters
+	Fragment   string              `json:"fragment,omitempty"` // Decoded fragment
+	ParseError bool                `json:"parseError,omitempty"`
+}
+
+// GetURIComponents parses the URI of a URI Record with net/url.
+// Returns (components, true) if this is a URI record, or ({}, false) otherwise.
+// A URI that does not parse is returned as URI with ParseError set.
+func (r *NDEFRecord) GetURIComponents() (URIComponents, bool) {
+	uri, ok := r.GetURI()
+	if !ok {
+		return URIComponents{}, false
+	}
+
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		return URIComponents{URI: uri, ParseError: true}, true
+	}
+
+	components := URIComponents{
+		URI:      uri,
+		Scheme:   parsed.Scheme,
+		Host:     parsed.Host,
+		Path:     parsed.Path,
+		Opaque:   parsed.Opaque,
+		Fragment: parsed.Fragment,
+	}
+	query, err := url.ParseQuery(parsed.RawQuery)
+	if err != nil {
+		components.ParseError = true
+	}
+	if len(query) > 0 {
+		components.Query = query
+	}
+	return components, true
+}
+
 // IsTextRecord returns true if this is a Text Record.
 func (r *NDEFRecord) IsTextRecord() bool {
 	return r.TNF == 0x01 && len(r.Type) == 1 && r.Type[0] == 'T'
@@ -114,6 +182,7 @@ func NewNDEFMessage() *NDEFMessage {
 // AddRecord adds a raw NDEF record to the message.
 func (m *NDEFMessage) AddRecord(record NDEFRecord) *NDEFMessage {
 	m.records = append(m.records, record)
+	m.raw = nil
 	return m
 }
 
@@ -123,6 +192,7 @@ func (m *NDEFMessage) AddText(text, langCode string) *NDEFMessage {
 		langCode = "en"
 	}
 	payload := MakeTextRecordPayload(text, langCode)
+	m.raw = nil
 	m.records = append(m.records, NDEFRecord{
 		TNF:     0x01, // Well Known
 		Type:    []byte("T"),
@@ -131,9 +201,40 @@ func (m *NDEFMessage) AddText(text, langCode string) *NDEFMessage {
 	return m
 }
 
+// NewLocalizedTextMessage returns a message with one text record per
+// language in texts, so readers can pick the record matching their locale.
+// Records are ordered by language code, so the same texts always encode the
+// same way. Every language code is checked with ValidateLanguageCode, and
+// codes differing only in case are refused, as language tags ignore case.
+func NewLocalizedTextMessage(texts map[string]string) (*NDEFMessage, error) {
+	if len(texts) == 0 {
+		return nil, fmt.Errorf("no texts given")
+	}
+	langs := make([]string, 0, len(texts))
+	seen := make(map[string]string, len(texts))
+	for lang := range texts {
+		if err := ValidateLanguageCode(lang); err != nil {
+			return nil, err
+		}
+		if other, ok := seen[strings.ToLower(lang)]; ok {
+			return nil, fmt.Errorf("language codes %q and %q are the same language", other, lang)
+		}
+		seen[strings.ToLower(lang)] = lang
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+
+	msg := NewNDEFMessage()
+	for _, lang := range langs {
+		msg.AddText(texts[lang], lang)
+	}
+	return msg, nil
+}
+
 // AddURI adds an NDEF URI Record to the message.
 func (m *NDEFMessage) AddURI(uri string) *NDEFMessage {
 	payload := MakeURIRecordPayload(uri)
+	m.raw = nil
 	m.records = append(m.records, NDEFRecord{
 		TNF:     0x01, // Well Known
 		Type:    []byte("U"),
@@ -147,6 +248,9 @@ func (m *NDEFMessage) Encode() ([]byte, error) {
 	if len(m.records) == 0 {
 		return nil, fmt.Errorf("cannot encode empty NDEF message")
 	}
+	if m.raw != nil {
+		return append([]byte(nil), m.raw...), nil
+	}
 	return encodeNDEFRecords(m.records)
 }
 
@@ -190,12 +294,68 @@ func DecodeNDEF(data []byte) (*NDEFMessage, error) {
 	return &NDEFMessage{records: records}, nil
 }
 
+// ParseRawNDEF parses an NDEF message supplied as raw bytes, e.g. by a client
+// that builds its own messages. Unlike DecodeNDEF it requires the bytes to be
+// exactly one well-formed message: MB set on the first record only, ME on the
+// last record only, no chunked records or reserved TNF, and nothing after the
+// last record. The returned message encodes to data unchanged.
+func ParseRawNDEF(data []byte) (*NDEFMessage, error) {
+	records, err := parseNDEFRecords(data)
+	if err != nil {
+		return nil, err
+	}
+
+	offset := 0
+	for i, record := range records {
+		header := data[offset]
+		if mb := header&0x80 != 0; mb != (i == 0) {
+			return nil, fmt.Errorf("invalid NDEF message: record %d has MB=%v", i, mb)
+		}
+		if me := header&0x40 != 0; me != (i == len(records)-1) {
+			return nil, fmt.Errorf("invalid NDEF message: record %d has ME=%v", i, me)
+		}
+		if header&0x20 != 0 {
+			return nil, fmt.Errorf("invalid NDEF message: record %d is chunked", i)
+		}
+		if record.TNF == 0x07 {
+			return nil, fmt.Errorf("invalid NDEF message: record %d uses reserved TNF 0x07", i)
+		}
+
+		offset += 2 // Header and type length
+		if header&0x10 != 0 {
+			offset++
+		} else {
+			offset += 4
+		}
+		if header&0x08 != 0 {
+			offset++
+		}
+		offset += len(record.Type) + len(record.ID) + len(record.Payload)
+	}
+	if offset != len(data) {
+		return nil, fmt.Errorf("invalid NDEF message: %d trailing bytes after the last record", len(data)-offset)
+	}
+
+	return &NDEFMessage{records: records, raw: append([]byte(nil), data...)}, nil
+}
+
 // DecodeText creates a TextMessage from raw bytes (no parsing).
 // This is used for cards that don't support NDEF.
 func DecodeText(data []byte) *TextMessage {
 	return NewTextMessage(data)
 }
 
+// uriPrefixes are the URI record identifier codes of the NFC Forum URI RTD,
+// indexed by code. Code 0x00 means no abbreviation.
+var uriPrefixes = []string{
+	"", "http://www.", "https://www.", "http://", "https://", "tel:", "mailto:",
+	"ftp://anonymous:anonymous@", "ftp://ftp.", "ftps://", "sftp://", "smb://",
+	"nfs://", "ftp://", "dav://", "news:", "telnet://", "imap:", "rtsp://", "urn:",
+	"pop:", "sip:", "sips:", "tftp:", "btspp://", "btl2cap://", "btgoep://",
+	"tcpobex://", "irdaobex://", "file://", "urn:epc:id:", "urn:epc:tag:",
+	"urn:epc:pat:", "urn:epc:raw:", "urn:epc:", "urn:nfc:",
+}
+
 // MakeURIRecordPayload creates the payload for an NDEF URI record.
 func MakeURIRecordPayload(uri string) []byte {
 	// URI record format: [identifier code][URI string]
@@ -206,6 +366,18 @@ func MakeURIRecordPayload(uri string) []byte {
 	return payload
 }
 
+// MakeAbbreviatedURIRecordPayload creates the payload for an NDEF URI record,
+// replacing the longest known prefix of uri with its identifier code.
+func MakeAbbreviatedURIRecordPayload(uri string) []byte {
+	code := 0
+	for i, prefix := range uriPrefixes {
+		if strings.HasPrefix(uri, prefix) && len(prefix) > len(uriPrefixes[code]) {
+			code = i
+		}
+	}
+	return append([]byte{byte(code)}, uri[len(uriPrefixes[code]):]...)
+}
+
 // parseURIRecordPayload extracts URI from an NDEF URI record payload.
 func parseURIRecordPayload(payload []byte) (string, error) {
 	if len(payload) < 1 {
@@ -215,22 +387,10 @@ func parseURIRecordPayload(payload []byte) (string, error) {
 	identifierCode := payload[0]
 	uriBytes := payload[1:]
 
-	// Handle URI prefix abbreviations
+	// Handle URI prefix abbreviations; reserved codes have no prefix
 	var prefix string
-	switch identifierCode {
-	case 0x00:
-		prefix = ""
-	case 0x01:
-		prefix = "http://www."
-	case 0x02:
-		prefix = "https://www."
-	case 0x03:
-		prefix = "http://"
-	case 0x04:
-		prefix = "https://"
-	// Add more as needed
-	default:
-		prefix = ""
+	if int(identifierCode) < len(uriPrefixes) {
+		prefix = uriPrefixes[identifierCode]
 	}
 
 	return prefix + string(uriBytes), nil
@@ -251,32 +411,54 @@ func parseURIRecordPayload(payload []byte) (string, error) {
 type NDEFText struct {
 	Content  string
 	Language string // Optional, defaults to "en"
+	Encoding string // Optional, TextEncodingUTF8 (default) or TextEncodingUTF16
 }
 
-// ToRecord converts NDEFText to NDEFRecord.
-func (t *NDEFText) ToRecord() NDEFRecord {
+// EncodeRecord converts NDEFText to NDEFRecord, failing on an unknown encoding.
+func (t *NDEFText) EncodeRecord() (NDEFRecord, error) {
 	lang := t.Language
 	if lang == "" {
 		lang = "en"
 	}
+	payload, err := MakeTextRecordPayloadWithEncoding(t.Content, lang, t.Encoding)
+	if err != nil {
+		return NDEFRecord{}, err
+	}
 	return NDEFRecord{
 		TNF:     0x01, // Well Known
 		Type:    []byte("T"),
-		Payload: MakeTextRecordPayload(t.Content, lang),
+		Payload: payload,
+	}, nil
+}
+
+// ToRecord converts NDEFText to NDEFRecord. An unknown encoding falls back to
+// UTF-8; NDEFMessageBuilder.Build reports it instead.
+func (t *NDEFText) ToRecord() NDEFRecord {
+	record, err := t.EncodeRecord()
+	if err != nil {
+		fallback := *t
+		fallback.Encoding = TextEncodingUTF8
+		record, _ = fallback.EncodeRecord()
 	}
+	return record
 }
 
 // NDEFURI represents a high-level URI record.
 type NDEFURI struct {
-	Content string
+	Content    string
+	Abbreviate bool // Optional, stores a known prefix such as "https://" as its identifier code
 }
 
 // ToRecord converts NDEFURI to NDEFRecord.
 func (u *NDEFURI) ToRecord() NDEFRecord {
+	payload := MakeURIRecordPayload(u.Content)
+	if u.Abbreviate {
+		payload = MakeAbbreviatedURIRecordPayload(u.Content)
+	}
 	return NDEFRecord{
 		TNF:     0x01, // Well Known
 		Type:    []byte("U"),
-		Payload: MakeURIRecordPayload(u.Content),
+		Payload: payload,
 	}
 }
 
@@ -327,6 +509,12 @@ type NDEFRecordBuilder interface {
 	ToRecord() NDEFRecord
 }
 
+// ndefRecordEncoder is implemented by builders whose conversion can fail,
+// such as NDEFExternalRecord. Build uses it in place of ToRecord.
+type ndefRecordEncoder interface {
+	EncodeRecord() (NDEFRecord, error)
+}
+
 // NDEFMessageBuilder provides a declarative way to construct NDEF messages.
 //
 // Example:
@@ -337,8 +525,25 @@ type NDEFRecordBuilder interface {
 //	        &nfc.NDEFURI{Content: "https://example.com"},
 //	    },
 //	}.Build()
+//
+// DefaultLanguage and DefaultEncoding save repeating the same values on every
+// text record:
+//
+//	msg := &nfc.NDEFMessageBuilder{
+//	    DefaultLanguage: "fr",
+//	    Records: []nfc.NDEFRecordBuilder{
+//	        &nfc.NDEFText{Content: "Bonjour"},
+//	        &nfc.NDEFText{Content: "Hello", Language: "en"}, // Record value wins
+//	    },
+//	}.Build()
 type NDEFMessageBuilder struct {
 	Records []NDEFRecordBuilder
+
+	// DefaultLanguage and DefaultEncoding apply during Build to NDEFText
+	// records that leave Language or Encoding empty. The records themselves
+	// are not modified.
+	DefaultLanguage string
+	DefaultEncoding string
 }
 
 func (b *NDEFMessageBuilder) Encode() ([]byte, error) {
@@ -358,14 +563,46 @@ func (b *NDEFMessageBuilder) Build() (*NDEFMessage, error) {
 	if len(b.Records) == 0 {
 		return nil, fmt.Errorf("cannot build empty NDEF message (no records provided)")
 	}
+	if err := checkRecordCount(len(b.Records)); err != nil {
+		return nil, err
+	}
 
 	msg := NewNDEFMessage()
-	for _, record := range b.Records {
-		msg.AddRecord(record.ToRecord())
+	for i, record := range b.Records {
+		r, err := buildRecord(b.withDefaults(record))
+		if err != nil {
+			return nil, fmt.Errorf("record %d: %w", i, err)
+		}
+		msg.AddRecord(r)
 	}
 	return msg, nil
 }
 
+// buildRecord converts a single builder, preferring EncodeRecord when the
+// builder can report a failure.
+func buildRecord(builder NDEFRecordBuilder) (NDEFRecord, error) {
+	if enc, ok := builder.(ndefRecordEncoder); ok {
+		return enc.EncodeRecord()
+	}
+	return builder.ToRecord(), nil
+}
+
+// withDefaults returns record with the message-level text defaults filled in.
+func (b *NDEFMessageBuilder) withDefaults(record NDEFRecordBuilder) NDEFRecordBuilder {
+	text, ok := record.(*NDEFText)
+	if !ok || (b.DefaultLanguage == "" && b.DefaultEncoding == "") {
+		return record
+	}
+	filled := *text
+	if filled.Language == "" {
+		filled.Language = b.DefaultLanguage
+	}
+	if filled.Encoding == "" {
+		filled.Encoding = b.DefaultEncoding
+	}
+	return &filled
+}
+
 // MustBuild is like Build but panics on error.
 func (b *NDEFMessageBuilder) MustBuild() *NDEFMessage {
 	msg, err := b.Build()
@@ -427,14 +664,18 @@ func recordToBuilder(record NDEFRecord) NDEFRecordBuilder {
 				if len(record.Payload) > 0 {
 					statusByte := record.Payload[0]
 					langLen := int(statusByte & 0x3F) // Lower 6 bits
-					if langLen > 0 && len(record.Payload) > 1+langLen {
+					if langLen > 0 && len(record.Payload) >= 1+langLen {
 						lang = string(record.Payload[1 : 1+langLen])
 					}
 				}
-				return &NDEFText{
+				builder := &NDEFText{
 					Content:  text,
 					Language: lang,
 				}
+				if record.Payload[0]&0x80 != 0 {
+					builder.Encoding = TextEncodingUTF16
+				}
+				return builder
 
 			case 'U': // URI Record
 				uri, err := parseURIRecordPayload(record.Payload)
@@ -454,6 +695,12 @@ func recordToBuilder(record NDEFRecord) NDEFRecordBuilder {
 		}
 
 	case 0x04: // External Type
+		if fields, ok := record.ExternalFields(); ok {
+			return &NDEFExternalRecord{
+				Name:   string(record.Type),
+				Fields: fields,
+			}
+		}
 		return &NDEFExternal{
 			Domain: string(record.Type),
 			Data:   record.Payload,
@@ -1,6 +1,10 @@
 package nfc
 
 import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -83,6 +87,62 @@ func TestRecordToBuilder_TextRecord(t *testing.T) {
 	}
 }
 
+// TestNDEFMessageBuilder_TextDefaults tests that message-level language and
+// encoding apply to text records without their own, and that record values win.
+func TestNDEFMessageBuilder_TextDefaults(t *testing.T) {
+	own := &NDEFText{Content: "Hello", Language: "en", Encoding: TextEncodingUTF8}
+	builder := &NDEFMessageBuilder{
+		DefaultLanguage: "fr",
+		DefaultEncoding: TextEncodingUTF16,
+		Records: []NDEFRecordBuilder{
+			&NDEFText{Content: "Bonjour"},
+			own,
+			&NDEFURI{Content: "https://example.com"},
+		},
+	}
+	msg := builder.MustBuild()
+
+	back := msg.ToBuilder()
+	first, ok := back.Records[0].(*NDEFText)
+	if !ok || first.Content != "Bonjour" || first.Language != "fr" || first.Encoding != TextEncodingUTF16 {
+		t.Errorf("First record = %+v, want Bonjour in fr as UTF-16", back.Records[0])
+	}
+	second, ok := back.Records[1].(*NDEFText)
+	if !ok || second.Content != "Hello" || second.Language != "en" || second.Encoding != "" {
+		t.Errorf("Second record = %+v, want Hello in en as UTF-8", back.Records[1])
+	}
+
+	// The builder's records are left as they were
+	if text := builder.Records[0].(*NDEFText); text.Language != "" || text.Encoding != "" {
+		t.Errorf("Build modified the record: %+v", text)
+	}
+}
+
+// TestNDEFText_Encoding tests UTF-16 payloads and unknown encodings.
+func TestNDEFText_Encoding(t *testing.T) {
+	record := (&NDEFText{Content: "Grüße", Language: "de", Encoding: TextEncodingUTF16}).ToRecord()
+	want := []byte{0x82, 'd', 'e', 0xFF, 0xFE, 'G', 0, 'r', 0, 0xFC, 0, 0xDF, 0, 'e', 0}
+	if !reflect.DeepEqual(record.Payload, want) {
+		t.Errorf("Payload = % X, want % X", record.Payload, want)
+	}
+	if text, err := parseTextRecordPayload(record.Payload); err != nil || text != "Grüße" {
+		t.Errorf("parseTextRecordPayload() = %q, %v", text, err)
+	}
+
+	// Big-endian with a byte order mark
+	if text, err := parseTextRecordPayload([]byte{0x80, 0xFE, 0xFF, 0, 'H', 0, 'i'}); err != nil || text != "Hi" {
+		t.Errorf("parseTextRecordPayload(big-endian) = %q, %v", text, err)
+	}
+
+	_, err := (&NDEFMessageBuilder{
+		DefaultEncoding: "latin-1",
+		Records:         []NDEFRecordBuilder{&NDEFText{Content: "x"}},
+	}).Build()
+	if err == nil {
+		t.Error("Expected Build() to reject an unknown encoding")
+	}
+}
+
 // TestRecordToBuilder_URIRecord tests conversion of URI records
 func TestRecordToBuilder_URIRecord(t *testing.T) {
 	uri := &NDEFURI{Content: "https://test.com"}
@@ -341,3 +401,212 @@ func BenchmarkRecordToBuilder(b *testing.B) {
 		_ = recordToBuilder(record)
 	}
 }
+
+// TestGetURIComponents tests splitting URI records into their components
+func TestGetURIComponents(t *testing.T) {
+	tests := []struct {
+		name string
+		uri  string
+		want URIComponents
+	}{
+		{
+			name: "https with query",
+			uri:  "https://example.com/menu?table=4&lang=en#top",
+			want: URIComponents{
+				URI:      "https://example.com/menu?table=4&lang=en#top",
+				Scheme:   "https",
+				Host:     "example.com",
+				Path:     "/menu",
+				Query:    map[string][]string{"table": {"4"}, "lang": {"en"}},
+				Fragment: "top",
+			},
+		},
+		{
+			name: "mailto",
+			uri:  "mailto:info@example.com",
+			want: URIComponents{URI: "mailto:info@example.com", Scheme: "mailto", Opaque: "info@example.com"},
+		},
+		{
+			name: "malformed",
+			uri:  "http://[::1",
+			want: URIComponents{URI: "http://[::1", ParseError: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record := (&NDEFURI{Content: tt.uri}).ToRecord()
+
+			got, ok := record.GetURIComponents()
+			if !ok {
+				t.Fatal("GetURIComponents() returned false for a URI record")
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetURIComponents() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	text := (&NDEFText{Content: "hello"}).ToRecord()
+	if _, ok := text.GetURIComponents(); ok {
+		t.Error("GetURIComponents() returned true for a text record")
+	}
+}
+
+// TestToPayload_URIComponents tests that URI records carry their components
+func TestToPayload_URIComponents(t *testing.T) {
+	msg := &NDEFMessage{}
+	msg.AddURI("https://example.com/a?b=c")
+
+	payload := msg.ToPayload()
+	if len(payload.Records) != 1 || payload.Records[0].URI == nil {
+		t.Fatalf("Expected URI components in payload, got %+v", payload.Records)
+	}
+	if uri := payload.Records[0].URI; uri.Host != "example.com" || uri.Query["b"][0] != "c" {
+		t.Errorf("Unexpected URI components: %+v", uri)
+	}
+}
+
+// TestToJSONMap_RawRecordFields tests that every record reports its TNF and raw
+// type, and that unknown records keep their payload.
+func TestToJSONMap_RawRecordFields(t *testing.T) {
+	msg := &NDEFMessage{}
+	msg.AddText("Hi", "en")
+	msg.records = append(msg.records,
+		NDEFRecord{TNF: 0x02, Type: []byte("application/x-demo"), Payload: []byte{0x00, 0xFF}},
+		NDEFRecord{TNF: 0x05, Type: []byte{0x01, 0xAB}, Payload: []byte("raw")},
+	)
+
+	data, err := json.Marshal(msg.ToJSONMap())
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	var decoded struct {
+		Records []map[string]any `json:"records"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	want := []map[string]any{
+		{"type": "text", "tnf": 1.0, "typeName": "T"},
+		{"type": "application/x-demo", "tnf": 2.0, "typeName": "application/x-demo", "payload": "AP8="},
+		{"type": "01AB", "tnf": 5.0, "typeName": "01AB", "typeNameHex": true, "payload": "cmF3"},
+	}
+	if len(decoded.Records) != len(want) {
+		t.Fatalf("Got %d records, want %d: %s", len(decoded.Records), len(want), data)
+	}
+	for i, fields := range want {
+		for key, value := range fields {
+			if got := decoded.Records[i][key]; got != value {
+				t.Errorf("record %d %s = %v, want %v", i, key, got, value)
+			}
+		}
+	}
+	if _, ok := decoded.Records[0]["typeNameHex"]; ok {
+		t.Error("typeNameHex set for a printable type")
+	}
+}
+
+// TestParseRawNDEF tests that raw messages are validated and kept byte for byte
+func TestParseRawNDEF(t *testing.T) {
+	// A long-form (SR=0) record, which re-encoding would shorten
+	long := []byte{0xC1, 0x01, 0x00, 0x00, 0x00, 0x04, 'T', 0x02, 'e', 'n', 'h'}
+	msg, err := ParseRawNDEF(long)
+	if err != nil {
+		t.Fatalf("ParseRawNDEF() error = %v", err)
+	}
+	if text, _ := msg.GetText(); text != "h" {
+		t.Errorf("GetText() = %q, want %q", text, "h")
+	}
+	if got, _ := msg.Encode(); !bytes.Equal(got, long) {
+		t.Errorf("Encode() = % X, want % X", got, long)
+	}
+
+	// Changing the records drops the raw encoding
+	msg.AddText("more", "en")
+	if got, _ := msg.Encode(); bytes.Equal(got[:len(long)], long) {
+		t.Error("Encode() still returned the raw bytes after AddText()")
+	}
+
+	short := []byte{0xD1, 0x01, 0x04, 'T', 0x02, 'e', 'n', 'h'}
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"empty", nil, "empty"},
+		{"truncated", []byte{0xD1, 0x01, 0x09, 'T', 0x02}, "truncated"},
+		{"trailing bytes", append(append([]byte(nil), short...), 0x00), "trailing"},
+		{"missing ME", []byte{0x91, 0x01, 0x04, 'T', 0x02, 'e', 'n', 'h'}, "ME=false"},
+		{"MB on second record", append([]byte{0x91, 0x01, 0x04, 'T', 0x02, 'e', 'n', 'h'}, short...), "MB=true"},
+		{"chunked", []byte{0xF1, 0x01, 0x04, 'T', 0x02, 'e', 'n', 'h'}, "chunked"},
+		{"reserved TNF", []byte{0xD7, 0x01, 0x04, 'T', 0x02, 'e', 'n', 'h'}, "reserved TNF"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseRawNDEF(tt.data); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ParseRawNDEF() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewLocalizedTextMessage(t *testing.T) {
+	msg, err := NewLocalizedTextMessage(map[string]string{"fr": "Bonjour", "en": "Hello", "es-MX": "Hola"})
+	if err != nil {
+		t.Fatalf("NewLocalizedTextMessage() error = %v", err)
+	}
+	want := []struct{ lang, text string }{{"en", "Hello"}, {"es-MX", "Hola"}, {"fr", "Bonjour"}}
+	records := msg.Records()
+	if len(records) != len(want) {
+		t.Fatalf("Got %d records, want %d", len(records), len(want))
+	}
+	for i, w := range want {
+		text, _ := records[i].GetText()
+		if lang := extractLanguageFromTextRecord(records[i].Payload); lang != w.lang || text != w.text {
+			t.Errorf("Record %d = %s %q, want %s %q", i, lang, text, w.lang, w.text)
+		}
+	}
+
+	for name, texts := range map[string]map[string]string{
+		"empty":             {},
+		"bad code":          {"english": "Hello"},
+		"digit in language": {"e1": "Hello"},
+		"empty subtag":      {"en-": "Hello"},
+		"same language":     {"en": "Hello", "EN": "Hello"},
+	} {
+		if _, err := NewLocalizedTextMessage(texts); err == nil {
+			t.Errorf("NewLocalizedTextMessage(%s) expected error", name)
+		}
+	}
+}
+
+func TestTextMessage_NDEF(t *testing.T) {
+	ndef := EncodeNdefMessageWithTextRecord("Hello", "en")
+
+	tests := []struct {
+		name string
+		data []byte
+		want bool
+	}{
+		{"bare message", ndef, true},
+		{"wrapped in a TLV", TLVEncode(ndef, TLVNDEF), true},
+		{"plain text", []byte("Hello"), false},
+		{"empty", nil, false},
+	}
+
+	for _, tt := range tests {
+		msg, ok := NewTextMessage(tt.data).NDEF()
+		if ok != tt.want {
+			t.Errorf("%s: NDEF() ok = %v, want %v", tt.name, ok, tt.want)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if text, _ := msg.GetText(); text != "Hello" {
+			t.Errorf("%s: recovered text %q, want Hello", tt.name, text)
+		}
+	}
+}
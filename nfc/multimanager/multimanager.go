@@ -142,6 +142,20 @@ func (mm *MultiManager) GetManager(name string) (nfc.Manager, bool) {
 	return manager, exists
 }
 
+// lookupManager finds a manager by name, ignoring case and surrounding whitespace.
+func lookupManager(managers map[string]nfc.Manager, name string) (nfc.Manager, bool) {
+	if manager, exists := managers[name]; exists {
+		return manager, true
+	}
+	normalized := nfc.NormalizeDeviceName(name)
+	for k, manager := range managers {
+		if nfc.NormalizeDeviceName(k) == normalized {
+			return manager, true
+		}
+	}
+	return nil, false
+}
+
 // OpenDevice opens a device using the appropriate manager.
 // Device string format:
 //   - "manager:deviceID" - explicit manager (e.g., "smartphone:abc123", "hardware:pn532")
@@ -169,7 +183,7 @@ func (mm *MultiManager) OpenDevice(deviceStr string) (nfc.Device, error) {
 
 		// Only treat as manager prefix if it's actually a registered manager
 		// This handles cases where device IDs contain colons (e.g., "acr122_usb:001:003")
-		if manager, exists := managers[managerName]; exists {
+		if manager, exists := lookupManager(managers, managerName); exists {
 			device, err := manager.OpenDevice(deviceID)
 			if err != nil {
 				return nil, fmt.Errorf("failed to open device '%s' with manager '%s': %w", deviceID, managerName, err)
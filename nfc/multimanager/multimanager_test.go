@@ -226,6 +226,24 @@ func TestMultiManagerOpenDeviceWithPrefix(t *testing.T) {
 	}
 }
 
+// TestMultiManagerOpenDeviceWithPrefixCaseInsensitive tests that manager
+// prefixes match regardless of case and surrounding whitespace.
+func TestMultiManagerOpenDeviceWithPrefixCaseInsensitive(t *testing.T) {
+	mm := NewMultiManager()
+	mm.AddManager("hardware", &mockManager{name: "mock1", devices: []string{"device1"}})
+
+	for _, deviceStr := range []string{"Hardware:device1", " HARDWARE :device1"} {
+		device, err := mm.OpenDevice(deviceStr)
+		if err != nil {
+			t.Errorf("OpenDevice(%q) failed: %v", deviceStr, err)
+			continue
+		}
+		if device.Connection() != "device1" {
+			t.Errorf("OpenDevice(%q) connection = %v, want device1", deviceStr, device.Connection())
+		}
+	}
+}
+
 // TestMultiManagerOpenDeviceWithColonInDeviceID tests that device IDs containing colons
 // (like libnfc format "acr122_usb:001:003") are handled correctly when the first part
 // is NOT a registered manager name.
@@ -0,0 +1,163 @@
+package nfc
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrUnknownReader is returned when an operation names a reader ID that is
+// not part of the MultiReader.
+var ErrUnknownReader = errors.New("unknown reader")
+
+// ReaderEntry names an NFCReader for MultiReader initialization.
+type ReaderEntry struct {
+	ID     string
+	Reader *NFCReader
+}
+
+// MultiReader runs several NFCReaders side by side, e.g. one per lane, and
+// merges their events. Every event on Data and StatusUpdates carries the ID of
+// the reader that produced it in ReaderID. Operations are routed to a single
+// reader by ID.
+//
+// The merged channels are drained one event at a time per reader, so a slow
+// consumer backs up into each reader's own buffer, where its DataDropPolicy
+// applies.
+type MultiReader struct {
+	readers    map[string]*NFCReader
+	order      []string
+	dataChan   chan NFCData
+	statusChan chan DeviceStatus
+	stopChan   chan struct{}
+	closeOnce  sync.Once
+	wg         sync.WaitGroup
+}
+
+// NewMultiReader creates a MultiReader over the given readers and starts
+// merging their events. IDs must be unique and non-empty.
+//
+// Example:
+//
+//	mr, err := nfc.NewMultiReader(
+//	    nfc.ReaderEntry{ID: "lane1", Reader: lane1},
+//	    nfc.ReaderEntry{ID: "lane2", Reader: lane2},
+//	)
+func NewMultiReader(entries ...ReaderEntry) (*MultiReader, error) {
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("at least one reader is required")
+	}
+
+	mr := &MultiReader{
+		readers:    make(map[string]*NFCReader, len(entries)),
+		dataChan:   make(chan NFCData, len(entries)),
+		statusChan: make(chan DeviceStatus, len(entries)),
+		stopChan:   make(chan struct{}),
+	}
+
+	for _, entry := range entries {
+		if entry.ID == "" || entry.Reader == nil {
+			return nil, fmt.Errorf("reader entry needs an ID and a reader")
+		}
+		if _, exists := mr.readers[entry.ID]; exists {
+			return nil, fmt.Errorf("duplicate reader ID %q", entry.ID)
+		}
+		mr.readers[entry.ID] = entry.Reader
+		mr.order = append(mr.order, entry.ID)
+	}
+
+	for _, id := range mr.order {
+		mr.wg.Add(1)
+		go mr.forward(id, mr.readers[id])
+	}
+
+	return mr, nil
+}
+
+// forward tags events from one reader with its ID and merges them.
+func (mr *MultiReader) forward(id string, r *NFCReader) {
+	defer mr.wg.Done()
+
+	for {
+		select {
+		case <-mr.stopChan:
+			return
+		case data := <-r.Data():
+			data.ReaderID = id
+			select {
+			case mr.dataChan <- data:
+			case <-mr.stopChan:
+				return
+			}
+		case status := <-r.StatusUpdates():
+			status.ReaderID = id
+			select {
+			case mr.statusChan <- status:
+			case <-mr.stopChan:
+				return
+			}
+		}
+	}
+}
+
+// IDs returns the reader IDs in the order they were given.
+func (mr *MultiReader) IDs() []string {
+	return append([]string(nil), mr.order...)
+}
+
+// Reader returns the reader with the given ID.
+func (mr *MultiReader) Reader(id string) (*NFCReader, bool) {
+	r, ok := mr.readers[id]
+	return r, ok
+}
+
+// Data returns the merged tag events of all readers.
+func (mr *MultiReader) Data() <-chan NFCData {
+	return mr.dataChan
+}
+
+// StatusUpdates returns the merged device status updates of all readers.
+func (mr *MultiReader) StatusUpdates() <-chan DeviceStatus {
+	return mr.statusChan
+}
+
+// Start starts every reader.
+func (mr *MultiReader) Start() {
+	for _, id := range mr.order {
+		mr.readers[id].Start()
+	}
+}
+
+// Stop stops every reader. Events keep being merged until Close.
+func (mr *MultiReader) Stop() {
+	for _, id := range mr.order {
+		mr.readers[id].Stop()
+	}
+}
+
+// Close stops merging events and releases every reader's resources.
+func (mr *MultiReader) Close() {
+	mr.closeOnce.Do(func() {
+		close(mr.stopChan)
+		mr.wg.Wait()
+		for _, id := range mr.order {
+			mr.readers[id].Close()
+		}
+	})
+}
+
+// SetAllowedCardTypes applies the card type allowlist to every reader.
+func (mr *MultiReader) SetAllowedCardTypes(types map[string]bool) {
+	for _, id := range mr.order {
+		mr.readers[id].SetAllowedCardTypes(types)
+	}
+}
+
+// WriteMessageWithOptions writes msg through the reader with the given ID.
+func (mr *MultiReader) WriteMessageWithOptions(id string, msg *NDEFMessage, opts WriteOptions) error {
+	r, ok := mr.readers[id]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownReader, id)
+	}
+	return r.WriteMessageWithOptions(msg, opts)
+}
@@ -0,0 +1,85 @@
+package nfc
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newLaneReader(t *testing.T) *NFCReader {
+	t.Helper()
+	reader, err := NewNFCReader("mock:usb:001", NewMockManager(), 5*time.Second)
+	if err != nil {
+		t.Fatalf("Failed to create NFCReader: %v", err)
+	}
+	return reader
+}
+
+// TestMultiReader_MergesEvents tests that events from each reader arrive on
+// the merged channels tagged with that reader's ID.
+func TestMultiReader_MergesEvents(t *testing.T) {
+	lane1, lane2 := newLaneReader(t), newLaneReader(t)
+	mr, err := NewMultiReader(ReaderEntry{ID: "lane1", Reader: lane1}, ReaderEntry{ID: "lane2", Reader: lane2})
+	if err != nil {
+		t.Fatalf("NewMultiReader() error = %v", err)
+	}
+	defer mr.Close()
+
+	lane2.sendData(NFCData{Card: &Card{UID: "CARD2"}})
+	select {
+	case data := <-mr.Data():
+		if data.ReaderID != "lane2" || data.Card.UID != "CARD2" {
+			t.Errorf("Expected CARD2 from lane2, got %s from %q", data.Card.UID, data.ReaderID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timeout waiting for merged tag data")
+	}
+
+	lane1.sendDeviceStatus(DeviceStatus{Connected: true, Message: "lane one"})
+	select {
+	case status := <-mr.StatusUpdates():
+		if status.ReaderID != "lane1" || status.Message != "lane one" {
+			t.Errorf("Expected lane1 status, got %+v", status)
+		}
+		encoded, err := json.Marshal(status)
+		if err != nil {
+			t.Fatalf("Marshal failed: %v", err)
+		}
+		if !strings.Contains(string(encoded), `"readerId":"lane1"`) {
+			t.Errorf("Expected readerId in encoded status, got %s", encoded)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timeout waiting for merged status")
+	}
+
+	if r, ok := mr.Reader("lane1"); !ok || r != lane1 {
+		t.Error("Reader(lane1) should return the first reader")
+	}
+	if ids := mr.IDs(); len(ids) != 2 || ids[0] != "lane1" || ids[1] != "lane2" {
+		t.Errorf("IDs() = %v, want [lane1 lane2]", ids)
+	}
+}
+
+func TestMultiReader_Errors(t *testing.T) {
+	lane := newLaneReader(t)
+	defer lane.Close()
+
+	if _, err := NewMultiReader(); err == nil {
+		t.Error("Expected error without readers")
+	}
+	if _, err := NewMultiReader(ReaderEntry{ID: "a", Reader: lane}, ReaderEntry{ID: "a", Reader: lane}); err == nil {
+		t.Error("Expected error for duplicate IDs")
+	}
+
+	mr, err := NewMultiReader(ReaderEntry{ID: "lane1", Reader: lane})
+	if err != nil {
+		t.Fatalf("NewMultiReader() error = %v", err)
+	}
+	defer mr.Close()
+
+	if err := mr.WriteMessageWithOptions("lane9", &NDEFMessage{}, WriteOptions{}); !errors.Is(err, ErrUnknownReader) {
+		t.Errorf("Expected ErrUnknownReader, got %v", err)
+	}
+}
@@ -4,9 +4,47 @@ import (
 	"encoding/binary"
 	"fmt"
 	"strings"
+	"sync/atomic"
 	"unicode/utf16"
 )
 
+// DefaultMaxNDEFRecords is the default limit on the number of records in one
+// NDEF message.
+const DefaultMaxNDEFRecords = 256
+
+// maxNDEFRecords is the limit in effect, see SetMaxNDEFRecords.
+var maxNDEFRecords atomic.Int64
+
+func init() {
+	maxNDEFRecords.Store(DefaultMaxNDEFRecords)
+}
+
+// SetMaxNDEFRecords limits how many records an NDEF message may hold, so a
+// malformed or hostile card packed with tiny records cannot make parsing
+// allocate without bound. Parsing a longer message and encoding or building
+// one fail with ErrTooManyRecords. Zero or less restores
+// DefaultMaxNDEFRecords. The limit applies process-wide.
+func SetMaxNDEFRecords(n int) {
+	if n <= 0 {
+		n = DefaultMaxNDEFRecords
+	}
+	maxNDEFRecords.Store(int64(n))
+}
+
+// MaxNDEFRecords returns the limit set with SetMaxNDEFRecords.
+func MaxNDEFRecords() int {
+	return int(maxNDEFRecords.Load())
+}
+
+// checkRecordCount returns an ErrTooManyRecords error if n records exceed
+// the limit.
+func checkRecordCount(n int) error {
+	if limit := MaxNDEFRecords(); n > limit {
+		return fmt.Errorf("%w: message has more than %d", ErrTooManyRecords, limit)
+	}
+	return nil
+}
+
 // ParseNdefMessageForTextRecord parses an NDEF message and returns the text from the first Text Record.
 // This is a convenience function that uses the record-based parsing internally.
 func ParseNdefMessageForTextRecord(ndefMessage []byte) (string, error) {
@@ -120,9 +158,18 @@ func decodeUTF16Internal(b []byte) string {
 	if len(b)%2 != 0 || len(b) == 0 {
 		return ""
 	}
+	// Honor a byte order mark; without one, keep reading little-endian
+	var order binary.ByteOrder = binary.LittleEndian
+	switch {
+	case b[0] == 0xFE && b[1] == 0xFF:
+		order = binary.BigEndian
+		b = b[2:]
+	case b[0] == 0xFF && b[1] == 0xFE:
+		b = b[2:]
+	}
 	u16s := make([]uint16, len(b)/2)
 	for i := 0; i < len(b)/2; i++ {
-		u16s[i] = binary.LittleEndian.Uint16(b[i*2 : (i*2)+2])
+		u16s[i] = order.Uint16(b[i*2 : (i*2)+2])
 	}
 	return strings.TrimSpace(string(utf16.Decode(u16s)))
 }
@@ -145,6 +192,58 @@ func MakeTextRecordPayload(text string, langCodeStr string) []byte {
 	return payload
 }
 
+// ValidateLanguageCode checks that code is an IANA language tag as text
+// records expect: a 2-3 letter language followed by optional subtags of 1-8
+// letters or digits, e.g. "en", "fr-CA" or "zh-Hant". The status byte limits
+// the code to 63 bytes.
+func ValidateLanguageCode(code string) error {
+	if code == "" || len(code) > 0x3F {
+		return fmt.Errorf("invalid language code %q: must be 1-63 characters", code)
+	}
+	for i, subtag := range strings.Split(code, "-") {
+		minLen, maxLen := 1, 8
+		if i == 0 {
+			minLen, maxLen = 2, 3
+		}
+		if len(subtag) < minLen || len(subtag) > maxLen {
+			return fmt.Errorf("invalid language code %q", code)
+		}
+		for _, c := range subtag {
+			isLetter := (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+			if !isLetter && (i == 0 || c < '0' || c > '9') {
+				return fmt.Errorf("invalid language code %q", code)
+			}
+		}
+	}
+	return nil
+}
+
+// Text record encodings, selected by bit 7 of the status byte
+const (
+	TextEncodingUTF8  = "utf-8"
+	TextEncodingUTF16 = "utf-16"
+)
+
+// MakeTextRecordPayloadWithEncoding is like MakeTextRecordPayload but takes the
+// text encoding: TextEncodingUTF8 (or empty) or TextEncodingUTF16. UTF-16 text
+// is written little-endian after a byte order mark.
+func MakeTextRecordPayloadWithEncoding(text string, langCodeStr string, encoding string) ([]byte, error) {
+	switch strings.ToLower(encoding) {
+	case "", TextEncodingUTF8:
+		return MakeTextRecordPayload(text, langCodeStr), nil
+	case TextEncodingUTF16:
+		payload := MakeTextRecordPayload("", langCodeStr)
+		payload[0] |= 0x80
+		payload = append(payload, 0xFF, 0xFE)
+		for _, u := range utf16.Encode([]rune(text)) {
+			payload = binary.LittleEndian.AppendUint16(payload, u)
+		}
+		return payload, nil
+	default:
+		return nil, fmt.Errorf("unknown text encoding %q (expected %s or %s)", encoding, TextEncodingUTF8, TextEncodingUTF16)
+	}
+}
+
 // GetLengthFieldSize returns the size of the TLV length field.
 func GetLengthFieldSize(length int) int {
 	if length > 0xFF {
@@ -164,6 +263,9 @@ func parseNDEFRecords(ndefMessage []byte) ([]NDEFRecord, error) {
 	offset := 0
 
 	for offset < len(ndefMessage) {
+		if err := checkRecordCount(len(records) + 1); err != nil {
+			return nil, fmt.Errorf("invalid NDEF message: %w", err)
+		}
 		if offset+1 > len(ndefMessage) {
 			return nil, fmt.Errorf("invalid NDEF message: truncated record header at offset %d", offset)
 		}
@@ -262,6 +364,9 @@ func encodeNDEFRecords(records []NDEFRecord) ([]byte, error) {
 	if len(records) == 0 {
 		return nil, fmt.Errorf("cannot encode empty record list")
 	}
+	if err := checkRecordCount(len(records)); err != nil {
+		return nil, err
+	}
 
 	var result []byte
 
@@ -0,0 +1,38 @@
+package nfc
+
+// rawRecordBuilder passes a record the builders have no type for through
+// unchanged.
+type rawRecordBuilder struct {
+	record NDEFRecord
+}
+
+// ToRecord returns the wrapped record.
+func (r rawRecordBuilder) ToRecord() NDEFRecord {
+	return r.record
+}
+
+// CanonicalNDEF re-encodes msg through the record builders: text and URI
+// records are rebuilt from their content, URIs with their prefix abbreviated,
+// and every record gets the shortest length fields and fresh MB/ME flags when
+// encoded. Records the builders do not know are kept as they are, as are
+// record IDs.
+func CanonicalNDEF(msg *NDEFMessage) *NDEFMessage {
+	canonical := NewNDEFMessage()
+	for _, record := range msg.Records() {
+		builder := recordToBuilder(record)
+		if uri, ok := builder.(*NDEFURI); ok {
+			uri.Abbreviate = true
+		}
+		if builder == nil {
+			builder = rawRecordBuilder{record}
+		}
+
+		rebuilt, err := buildRecord(builder)
+		if err != nil {
+			rebuilt = record
+		}
+		rebuilt.ID = record.ID
+		canonical.AddRecord(rebuilt)
+	}
+	return canonical
+}
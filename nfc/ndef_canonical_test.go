@@ -0,0 +1,74 @@
+package nfc
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCanonicalNDEF(t *testing.T) {
+	// A URI record without abbreviation, written with a 4-byte payload length
+	uri := []byte("https://example.com")
+	original := append([]byte{0xC1, 0x01, 0x00, 0x00, 0x00, byte(1 + len(uri)), 'U', 0x00}, uri...)
+	msg, err := DecodeNDEF(original)
+	if err != nil {
+		t.Fatalf("DecodeNDEF() failed: %v", err)
+	}
+
+	got, err := CanonicalNDEF(msg).Encode()
+	if err != nil {
+		t.Fatalf("Encode() failed: %v", err)
+	}
+	want := append([]byte{0xD1, 0x01, byte(1 + len("example.com")), 'U', 0x04}, "example.com"...)
+	if !bytes.Equal(got, want) {
+		t.Errorf("CanonicalNDEF() = % X, want % X", got, want)
+	}
+
+	// Canonical messages are left as they are
+	again, _ := CanonicalNDEF(CanonicalNDEF(msg)).Encode()
+	if !bytes.Equal(again, want) {
+		t.Errorf("CanonicalNDEF() is not idempotent: % X", again)
+	}
+}
+
+func TestCanonicalNDEF_KeepsUnknownRecordsAndIDs(t *testing.T) {
+	records := []NDEFRecord{
+		{TNF: 0x01, Type: []byte("Sp"), Payload: []byte{0x01, 0x02}},
+		{TNF: 0x01, Type: []byte("T"), ID: []byte("id"), Payload: MakeTextRecordPayload("", "fr")},
+	}
+	msg := NewNDEFMessage()
+	for _, r := range records {
+		msg.AddRecord(r)
+	}
+
+	got := CanonicalNDEF(msg).Records()
+	if len(got) != len(records) {
+		t.Fatalf("CanonicalNDEF() has %d records, want %d", len(got), len(records))
+	}
+	for i := range records {
+		if !bytes.Equal(got[i].Type, records[i].Type) || !bytes.Equal(got[i].ID, records[i].ID) || !bytes.Equal(got[i].Payload, records[i].Payload) {
+			t.Errorf("Record %d = %+v, want %+v", i, got[i], records[i])
+		}
+	}
+}
+
+func TestMakeAbbreviatedURIRecordPayload(t *testing.T) {
+	tests := []struct {
+		uri  string
+		want []byte
+	}{
+		{"https://www.example.com", append([]byte{0x02}, "example.com"...)},
+		{"urn:epc:id:sgtin:1", append([]byte{0x1E}, "sgtin:1"...)},
+		{"tel:+123", append([]byte{0x05}, "+123"...)},
+		{"custom:thing", append([]byte{0x00}, "custom:thing"...)},
+	}
+
+	for _, tt := range tests {
+		got := MakeAbbreviatedURIRecordPayload(tt.uri)
+		if !bytes.Equal(got, tt.want) {
+			t.Errorf("MakeAbbreviatedURIRecordPayload(%q) = % X, want % X", tt.uri, got, tt.want)
+		}
+		if decoded, _ := parseURIRecordPayload(got); decoded != tt.uri {
+			t.Errorf("parseURIRecordPayload() = %q, want %q", decoded, tt.uri)
+		}
+	}
+}
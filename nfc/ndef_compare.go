@@ -0,0 +1,47 @@
+package nfc
+
+import "bytes"
+
+// NDEFComparison is the result of comparing an NDEF message with a reference.
+type NDEFComparison struct {
+	BytesMatch bool // Encodings are identical
+
+	// RecordsMatch is set when both messages hold the same records (TNF, type,
+	// ID and payload) in the same order. It can hold while BytesMatch does
+	// not, e.g. when one message uses long length fields for short records.
+	RecordsMatch bool
+
+	// Mismatched lists the indices of records that differ, including records
+	// only one of the messages has. Nil when either message does not decode.
+	Mismatched []int
+}
+
+// CompareNDEF compares the NDEF message data with reference, byte for byte
+// and record by record. Data that does not decode as NDEF only matches a
+// byte-identical reference.
+func CompareNDEF(data, reference []byte) NDEFComparison {
+	result := NDEFComparison{BytesMatch: bytes.Equal(data, reference)}
+
+	got, errGot := DecodeNDEF(data)
+	want, errWant := DecodeNDEF(reference)
+	if errGot != nil || errWant != nil {
+		result.RecordsMatch = result.BytesMatch
+		return result
+	}
+
+	gotRecords, wantRecords := got.Records(), want.Records()
+	result.Mismatched = []int{}
+	for i := range max(len(gotRecords), len(wantRecords)) {
+		if i >= len(gotRecords) || i >= len(wantRecords) || !recordsEqual(gotRecords[i], wantRecords[i]) {
+			result.Mismatched = append(result.Mismatched, i)
+		}
+	}
+	result.RecordsMatch = len(result.Mismatched) == 0
+	return result
+}
+
+// recordsEqual reports whether two records carry the same TNF, type, ID and
+// payload.
+func recordsEqual(a, b NDEFRecord) bool {
+	return a.TNF == b.TNF && bytes.Equal(a.Type, b.Type) && bytes.Equal(a.ID, b.ID) && bytes.Equal(a.Payload, b.Payload)
+}
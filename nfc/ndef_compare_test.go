@@ -0,0 +1,33 @@
+package nfc
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCompareNDEF(t *testing.T) {
+	hi := []byte{0xD1, 0x01, 0x05, 'T', 0x02, 'e', 'n', 'H', 'i'}
+	hiLong := []byte{0xC1, 0x01, 0x00, 0x00, 0x00, 0x05, 'T', 0x02, 'e', 'n', 'H', 'i'}
+	ho := []byte{0xD1, 0x01, 0x05, 'T', 0x02, 'e', 'n', 'H', 'o'}
+
+	tests := []struct {
+		name       string
+		data, ref  []byte
+		bytesMatch bool
+		records    bool
+		mismatched []int
+	}{
+		{"identical", hi, hi, true, true, []int{}},
+		{"long length field", hiLong, hi, false, true, []int{}},
+		{"different payload", ho, hi, false, false, []int{0}},
+		{"not NDEF", []byte{0x00, 0x01}, hi, false, false, nil},
+		{"identical non-NDEF", []byte{0x00, 0x01}, []byte{0x00, 0x01}, true, true, nil},
+	}
+
+	for _, tt := range tests {
+		got := CompareNDEF(tt.data, tt.ref)
+		if got.BytesMatch != tt.bytesMatch || got.RecordsMatch != tt.records || !slices.Equal(got.Mismatched, tt.mismatched) {
+			t.Errorf("%s: CompareNDEF() = %+v", tt.name, got)
+		}
+	}
+}
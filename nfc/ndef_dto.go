@@ -1,14 +1,27 @@
 package nfc
 
+import (
+	"encoding/hex"
+	"strings"
+)
+
 // NDEFRecordPayload represents an NDEF record in JSON-friendly format.
 // This structure is used for serialization to WebSocket clients and API responses.
 type NDEFRecordPayload struct {
-	Type     string `json:"type"`              // Record type: "text", "uri", etc. (human-readable)
-	Content  string `json:"content,omitempty"` // Decoded content (text or URI)
-	Language string `json:"language,omitempty"`// Language code for text records
-	TNF      uint8  `json:"tnf"`               // Type Name Format (technical detail)
-	ID       string `json:"id,omitempty"`      // Record ID (optional)
-	Payload  []byte `json:"payload"`           // Raw payload data
+	Type        string `json:"type"`                  // Record type: "text", "uri", etc. (human-readable)
+	Content     string `json:"content,omitempty"`     // Decoded content (text or URI)
+	Language    string `json:"language,omitempty"`    // Language code for text records
+	TNF         uint8  `json:"tnf"`                   // Type Name Format (technical detail)
+	TypeName    string `json:"typeName"`              // Raw record type, as text or hex (see TypeNameHex)
+	TypeNameHex bool   `json:"typeNameHex,omitempty"` // TypeName is uppercase hex because the type is not printable ASCII
+	ID          string `json:"id,omitempty"`          // Record ID (optional)
+	Payload     []byte `json:"payload"`               // Raw payload data
+
+	// Fields holds the decoded payload of External Type records with a registered codec
+	Fields map[string]any `json:"fields,omitempty"`
+
+	// URI breaks the URI of URI records into its components
+	URI *URIComponents `json:"uri,omitempty"`
 }
 
 // NDEFMessagePayload represents an NDEF message in JSON-friendly format.
@@ -35,6 +48,7 @@ func (m *NDEFMessage) ToPayload() *NDEFMessagePayload {
 			TNF:     record.TNF,
 			Payload: record.Payload,
 		}
+		recordPayload.TypeName, recordPayload.TypeNameHex = recordTypeName(record.Type)
 
 		// Add ID if present
 		if len(record.ID) > 0 {
@@ -50,9 +64,15 @@ func (m *NDEFMessage) ToPayload() *NDEFMessagePayload {
 		} else if recordURI, ok := record.GetURI(); ok {
 			recordPayload.Type = "uri"
 			recordPayload.Content = recordURI
-		} else {
-			// Unknown type - use raw type field
+			if components, ok := record.GetURIComponents(); ok {
+				recordPayload.URI = &components
+			}
+		} else if fields, ok := record.ExternalFields(); ok {
 			recordPayload.Type = string(record.Type)
+			recordPayload.Fields = fields
+		} else {
+			// Unknown type - use raw type field; the payload is kept as is
+			recordPayload.Type = recordPayload.TypeName
 		}
 
 		payload.Records = append(payload.Records, recordPayload)
@@ -61,6 +81,18 @@ func (m *NDEFMessage) ToPayload() *NDEFMessagePayload {
 	return payload
 }
 
+// recordTypeName returns a record type as text if it is printable ASCII, as
+// well-known, media, absolute URI and external types are, and as uppercase
+// hex otherwise, reporting which.
+func recordTypeName(recordType []byte) (name string, isHex bool) {
+	for _, b := range recordType {
+		if b < 0x20 || b > 0x7E {
+			return strings.ToUpper(hex.EncodeToString(recordType)), true
+		}
+	}
+	return string(recordType), false
+}
+
 // ToJSONMap converts an NDEFMessage to a map suitable for JSON serialization.
 // This is useful for building WebSocket/API responses.
 func (m *NDEFMessage) ToJSONMap() map[string]interface{} {
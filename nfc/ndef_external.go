@@ -0,0 +1,131 @@
+package nfc
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// tnfExternal is the Type Name Format of NFC Forum External Type records.
+const tnfExternal = 0x04
+
+// ExternalTypeCodec converts the payload of one External Type record to and
+// from structured fields.
+type ExternalTypeCodec struct {
+	// Decode parses a record payload into fields.
+	Decode func(payload []byte) (map[string]any, error)
+	// Encode builds a record payload from fields.
+	Encode func(fields map[string]any) ([]byte, error)
+}
+
+// externalTypes holds the registered codecs keyed by lowercased type name.
+var externalTypes = struct {
+	sync.RWMutex
+	codecs map[string]ExternalTypeCodec
+}{codecs: make(map[string]ExternalTypeCodec)}
+
+// normalizeExternalType lowercases an External Type name, since the NFC Forum
+// RTD compares them case-insensitively.
+func normalizeExternalType(name string) string {
+	return strings.ToLower(name)
+}
+
+// RegisterExternalType registers a codec for the External Type name
+// ("domain:type"), replacing any codec already registered for it. Records of
+// that type are then decoded into fields when read and can be built from
+// fields with NDEFExternalRecord.
+//
+// Example:
+//
+//	nfc.RegisterExternalType("example.com:badge", nfc.ExternalTypeCodec{
+//	    Decode: func(p []byte) (map[string]any, error) { return map[string]any{"id": string(p)}, nil },
+//	    Encode: func(f map[string]any) ([]byte, error) { return []byte(fmt.Sprint(f["id"])), nil },
+//	})
+func RegisterExternalType(name string, codec ExternalTypeCodec) error {
+	domain, typ, ok := strings.Cut(name, ":")
+	if !ok || domain == "" || typ == "" {
+		return fmt.Errorf("invalid external type name %q (expected domain:type)", name)
+	}
+	if codec.Decode == nil || codec.Encode == nil {
+		return fmt.Errorf("external type %q: codec needs both Decode and Encode", name)
+	}
+
+	externalTypes.Lock()
+	defer externalTypes.Unlock()
+	externalTypes.codecs[normalizeExternalType(name)] = codec
+	return nil
+}
+
+// UnregisterExternalType removes the codec for name. Records of that type
+// fall back to raw payloads.
+func UnregisterExternalType(name string) {
+	externalTypes.Lock()
+	defer externalTypes.Unlock()
+	delete(externalTypes.codecs, normalizeExternalType(name))
+}
+
+// IsExternalTypeRegistered reports whether a codec is registered for name.
+func IsExternalTypeRegistered(name string) bool {
+	_, ok := lookupExternalType(name)
+	return ok
+}
+
+func lookupExternalType(name string) (ExternalTypeCodec, bool) {
+	externalTypes.RLock()
+	defer externalTypes.RUnlock()
+	codec, ok := externalTypes.codecs[normalizeExternalType(name)]
+	return codec, ok
+}
+
+// ExternalFields decodes the record with the codec registered for its type.
+// It returns false for records that are not External Type records, have no
+// registered codec, or fail to decode.
+func (r *NDEFRecord) ExternalFields() (map[string]any, bool) {
+	if r.TNF != tnfExternal {
+		return nil, false
+	}
+	codec, ok := lookupExternalType(string(r.Type))
+	if !ok {
+		return nil, false
+	}
+	fields, err := codec.Decode(r.Payload)
+	if err != nil {
+		return nil, false
+	}
+	return fields, true
+}
+
+// NDEFExternalRecord is a high-level External Type record whose payload is
+// encoded from Fields by the codec registered for Name.
+type NDEFExternalRecord struct {
+	Name   string // e.g., "example.com:badge"
+	Fields map[string]any
+}
+
+// EncodeRecord converts NDEFExternalRecord to NDEFRecord, failing if no codec
+// is registered for Name or the codec rejects the fields.
+func (e *NDEFExternalRecord) EncodeRecord() (NDEFRecord, error) {
+	codec, ok := lookupExternalType(e.Name)
+	if !ok {
+		return NDEFRecord{}, fmt.Errorf("no codec registered for external type %q", e.Name)
+	}
+	payload, err := codec.Encode(e.Fields)
+	if err != nil {
+		return NDEFRecord{}, fmt.Errorf("external type %q: %w", e.Name, err)
+	}
+	return NDEFRecord{
+		TNF:     tnfExternal,
+		Type:    []byte(e.Name),
+		Payload: payload,
+	}, nil
+}
+
+// ToRecord converts NDEFExternalRecord to NDEFRecord. Encoding errors yield a
+// record with an empty payload; NDEFMessageBuilder.Build reports them instead.
+func (e *NDEFExternalRecord) ToRecord() NDEFRecord {
+	record, err := e.EncodeRecord()
+	if err != nil {
+		return NDEFRecord{TNF: tnfExternal, Type: []byte(e.Name)}
+	}
+	return record
+}
@@ -0,0 +1,128 @@
+package nfc
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// registerBadgeType registers a test codec for "example.com:badge" whose
+// payload is "<id>;<level>".
+func registerBadgeType(t *testing.T) {
+	t.Helper()
+
+	err := RegisterExternalType("example.com:badge", ExternalTypeCodec{
+		Decode: func(payload []byte) (map[string]any, error) {
+			id, level, ok := strings.Cut(string(payload), ";")
+			if !ok {
+				return nil, fmt.Errorf("malformed badge payload")
+			}
+			return map[string]any{"id": id, "level": level}, nil
+		},
+		Encode: func(fields map[string]any) ([]byte, error) {
+			id, ok := fields["id"].(string)
+			if !ok || id == "" {
+				return nil, fmt.Errorf("badge needs an id")
+			}
+			return []byte(fmt.Sprintf("%s;%v", id, fields["level"])), nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("RegisterExternalType() error = %v", err)
+	}
+	t.Cleanup(func() { UnregisterExternalType("example.com:badge") })
+}
+
+func TestExternalType_RoundTrip(t *testing.T) {
+	registerBadgeType(t)
+
+	msg, err := (&NDEFMessageBuilder{
+		Records: []NDEFRecordBuilder{
+			&NDEFText{Content: "Visitor", Language: "en"},
+			&NDEFExternalRecord{Name: "example.com:badge", Fields: map[string]any{"id": "B-42", "level": "staff"}},
+		},
+	}).Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	data, err := msg.Encode()
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	decoded, err := DecodeNDEF(data)
+	if err != nil {
+		t.Fatalf("DecodeNDEF() error = %v", err)
+	}
+
+	if string(decoded.Records()[1].Payload) != "B-42;staff" {
+		t.Errorf("Expected encoded payload B-42;staff, got %q", decoded.Records()[1].Payload)
+	}
+
+	builder := decoded.ToBuilder()
+	ext, ok := builder.Records[1].(*NDEFExternalRecord)
+	if !ok {
+		t.Fatalf("Expected *NDEFExternalRecord, got %T", builder.Records[1])
+	}
+	if ext.Fields["id"] != "B-42" || ext.Fields["level"] != "staff" {
+		t.Errorf("Unexpected fields: %v", ext.Fields)
+	}
+
+	payload := decoded.ToPayload()
+	if rec := payload.Records[1]; rec.Type != "example.com:badge" || rec.Fields["id"] != "B-42" {
+		t.Errorf("Expected badge fields in payload, got %+v", rec)
+	}
+
+	// Names are matched case-insensitively
+	if !IsExternalTypeRegistered("Example.COM:Badge") {
+		t.Error("Expected lookup to ignore case")
+	}
+}
+
+func TestExternalType_Fallbacks(t *testing.T) {
+	registerBadgeType(t)
+
+	// A payload the codec rejects stays raw
+	malformed := (&NDEFExternal{Domain: "example.com:badge", Data: []byte("no-separator")}).ToRecord()
+	if _, ok := malformed.ExternalFields(); ok {
+		t.Error("Expected malformed payload not to decode")
+	}
+
+	// Unregistered types stay raw
+	other := NewNDEFMessage().AddRecord((&NDEFExternal{Domain: "example.com:other", Data: []byte{0x01}}).ToRecord())
+	if _, ok := other.ToBuilder().Records[0].(*NDEFExternal); !ok {
+		t.Errorf("Expected *NDEFExternal for unregistered type, got %T", other.ToBuilder().Records[0])
+	}
+	if fields := other.ToPayload().Records[0].Fields; fields != nil {
+		t.Errorf("Expected no fields for unregistered type, got %v", fields)
+	}
+
+	// Encoding errors surface from Build
+	_, err := (&NDEFMessageBuilder{
+		Records: []NDEFRecordBuilder{&NDEFExternalRecord{Name: "example.com:badge"}},
+	}).Build()
+	if err == nil {
+		t.Error("Expected Build() to fail when the codec rejects the fields")
+	}
+
+	UnregisterExternalType("example.com:badge")
+	if IsExternalTypeRegistered("example.com:badge") {
+		t.Error("Expected type to be unregistered")
+	}
+}
+
+func TestRegisterExternalType_Invalid(t *testing.T) {
+	codec := ExternalTypeCodec{
+		Decode: func([]byte) (map[string]any, error) { return nil, nil },
+		Encode: func(map[string]any) ([]byte, error) { return nil, nil },
+	}
+
+	for _, name := range []string{"", "badge", ":badge", "example.com:"} {
+		if err := RegisterExternalType(name, codec); err == nil {
+			t.Errorf("RegisterExternalType(%q) expected error", name)
+		}
+	}
+	if err := RegisterExternalType("example.com:badge", ExternalTypeCodec{}); err == nil {
+		t.Error("Expected error for codec without Decode/Encode")
+	}
+}
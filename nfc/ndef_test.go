@@ -2,6 +2,7 @@ package nfc
 
 import (
 	"bytes"
+	"errors"
 	"testing"
 )
 
@@ -405,3 +406,47 @@ func BenchmarkEncodeMultipleRecords(b *testing.B) {
 		_, _ = encodeNDEFRecords(records)
 	}
 }
+
+// Test the record count limit on parsing, encoding and building
+func TestMaxNDEFRecords(t *testing.T) {
+	SetMaxNDEFRecords(3)
+	defer SetMaxNDEFRecords(0)
+
+	records := make([]NDEFRecord, 4)
+	builder := &NDEFMessageBuilder{}
+	for i := range records {
+		records[i] = NDEFRecord{TNF: 0x00}
+		builder.Records = append(builder.Records, &NDEFEmpty{})
+	}
+
+	if _, err := encodeNDEFRecords(records); !errors.Is(err, ErrTooManyRecords) {
+		t.Errorf("encoding 4 records: expected ErrTooManyRecords, got %v", err)
+	}
+	if _, err := builder.Build(); !errors.Is(err, ErrTooManyRecords) {
+		t.Errorf("building 4 records: expected ErrTooManyRecords, got %v", err)
+	}
+
+	encoded, err := encodeNDEFRecords(records[:3])
+	if err != nil {
+		t.Fatalf("encoding 3 records failed: %v", err)
+	}
+	if decoded, err := parseNDEFRecords(encoded); err != nil || len(decoded) != 3 {
+		t.Errorf("parsing 3 records: got %d records, err %v", len(decoded), err)
+	}
+
+	// A message written under a higher limit
+	SetMaxNDEFRecords(4)
+	encoded, err = encodeNDEFRecords(records)
+	if err != nil {
+		t.Fatalf("encoding 4 records failed: %v", err)
+	}
+	SetMaxNDEFRecords(3)
+	if _, err := parseNDEFRecords(encoded); !errors.Is(err, ErrTooManyRecords) {
+		t.Errorf("parsing 4 records: expected ErrTooManyRecords, got %v", err)
+	}
+
+	SetMaxNDEFRecords(0)
+	if got := MaxNDEFRecords(); got != DefaultMaxNDEFRecords {
+		t.Errorf("MaxNDEFRecords() after reset = %d, want %d", got, DefaultMaxNDEFRecords)
+	}
+}
@@ -0,0 +1,32 @@
+package nfc
+
+import "strings"
+
+// NormalizeDeviceName folds a reader or device name for comparison: it is
+// lowercased, trimmed, and runs of whitespace collapse to a single space.
+func NormalizeDeviceName(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), " ")
+}
+
+// MatchReaderName resolves a user-supplied device hint (e.g. the -device flag)
+// against the available reader names. An exact match wins; otherwise the first
+// reader whose normalized name contains the normalized hint is returned, so
+// "acr122" matches "ACS ACR122U PICC Interface 00 00".
+func MatchReaderName(readers []string, hint string) (string, bool) {
+	for _, r := range readers {
+		if r == hint {
+			return r, true
+		}
+	}
+
+	needle := NormalizeDeviceName(hint)
+	if needle == "" {
+		return "", false
+	}
+	for _, r := range readers {
+		if strings.Contains(NormalizeDeviceName(r), needle) {
+			return r, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,50 @@
+package nfc
+
+import "testing"
+
+func TestNormalizeDeviceName(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"ACR122U", "acr122u"},
+		{"  ACS  ACR122U\tPICC Interface  ", "acs acr122u picc interface"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeDeviceName(tt.input); got != tt.want {
+			t.Errorf("NormalizeDeviceName(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestMatchReaderName(t *testing.T) {
+	readers := []string{
+		"ACS ACR122U PICC Interface 00 00",
+		"Identiv uTrust 3700 F CL Reader 01 00",
+	}
+
+	tests := []struct {
+		name  string
+		hint  string
+		want  string
+		found bool
+	}{
+		{"exact", "ACS ACR122U PICC Interface 00 00", readers[0], true},
+		{"lowercase substring", "acr122", readers[0], true},
+		{"extra whitespace", "  uTrust   3700 ", readers[1], true},
+		{"mixed case", "IDENTIV", readers[1], true},
+		{"no match", "pn532", "", false},
+		{"blank hint", "   ", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found := MatchReaderName(readers, tt.hint)
+			if got != tt.want || found != tt.found {
+				t.Errorf("MatchReaderName(%q) = %q, %v; want %q, %v", tt.hint, got, found, tt.want, tt.found)
+			}
+		})
+	}
+}
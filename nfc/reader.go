@@ -1,8 +1,14 @@
 package nfc
 
 import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"log"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 )
@@ -32,6 +38,110 @@ const (
 	ModeWriteOnly
 )
 
+// DataDropPolicy selects which tag event is discarded when the data channel is full.
+type DataDropPolicy int
+
+const (
+	// DropOldest discards the queued event so the latest read is delivered (default).
+	DropOldest DataDropPolicy = iota
+	// DropNewest discards the incoming event and keeps the queued one.
+	DropNewest
+)
+
+// String returns the policy name as accepted by ParseDataDropPolicy.
+func (p DataDropPolicy) String() string {
+	switch p {
+	case DropOldest:
+		return "oldest"
+	case DropNewest:
+		return "newest"
+	default:
+		return fmt.Sprintf("DataDropPolicy(%d)", int(p))
+	}
+}
+
+// ParseDataDropPolicy parses "oldest" or "newest" into a DataDropPolicy.
+func ParseDataDropPolicy(s string) (DataDropPolicy, error) {
+	switch s {
+	case "oldest", "":
+		return DropOldest, nil
+	case "newest":
+		return DropNewest, nil
+	default:
+		return DropOldest, fmt.Errorf("unknown data drop policy %q (expected oldest or newest)", s)
+	}
+}
+
+// BlankCardPolicy selects how cards without NDEF data (factory-fresh or
+// formatted but empty) are reported on the data channel.
+type BlankCardPolicy int
+
+const (
+	// BlankCardsAnnounce reports blank cards like any other card (default).
+	BlankCardsAnnounce BlankCardPolicy = iota
+	// BlankCardsSuppress does not report blank cards. They are still tracked as
+	// the card in the field, so they can be written to and WaitForCard returns them.
+	BlankCardsSuppress
+	// BlankCardsFlag reports blank cards with NFCData.Blank set.
+	BlankCardsFlag
+)
+
+// String returns the policy name as accepted by ParseBlankCardPolicy.
+func (p BlankCardPolicy) String() string {
+	switch p {
+	case BlankCardsAnnounce:
+		return "announce"
+	case BlankCardsSuppress:
+		return "suppress"
+	case BlankCardsFlag:
+		return "flag"
+	default:
+		return fmt.Sprintf("BlankCardPolicy(%d)", int(p))
+	}
+}
+
+// ParseBlankCardPolicy parses "announce", "suppress" or "flag" into a BlankCardPolicy.
+func ParseBlankCardPolicy(s string) (BlankCardPolicy, error) {
+	switch s {
+	case "announce", "":
+		return BlankCardsAnnounce, nil
+	case "suppress":
+		return BlankCardsSuppress, nil
+	case "flag":
+		return BlankCardsFlag, nil
+	default:
+		return BlankCardsAnnounce, fmt.Errorf("unknown blank card policy %q (expected announce, suppress or flag)", s)
+	}
+}
+
+// Default channel buffer sizes
+const (
+	DefaultDataBufferSize   = 1
+	DefaultStatusBufferSize = 1
+)
+
+// ReaderOptions tunes an NFCReader at construction. Zero fields keep the defaults.
+//
+// Sends to both channels never block the reader. When the data channel is full
+// an event is dropped according to the DataDropPolicy, and a full status
+// channel drops the new status. A larger DataBufferSize lets a briefly slow
+// consumer catch up on a burst of scans without losing any; with DropOldest
+// the consumer may then see older cards before the most recent one.
+type ReaderOptions struct {
+	DataBufferSize   int // Capacity of the Data() channel (default DefaultDataBufferSize)
+	StatusBufferSize int // Capacity of the StatusUpdates() channel (default DefaultStatusBufferSize)
+
+	// Context, if set, bounds the initial connection attempt made by the
+	// constructor. Cancelling it abandons a slow device open so shutdown is not
+	// held up; the worker retries the connection once started.
+	Context context.Context
+
+	// DeviceSelection chooses the device to open when deviceStr is empty, on
+	// the first connect and on every reconnect. The zero value opens the first
+	// device in sorted order.
+	DeviceSelection DeviceSelection
+}
+
 // NFCReader manages NFC device interactions and broadcasts tag data.
 type NFCReader struct {
 	deviceManager    *DeviceManager
@@ -40,24 +150,51 @@ type NFCReader struct {
 	stopChan         chan struct{}     // Signals the worker to stop
 	cache            *TagCache         // Caches tag data
 	mode             ReaderMode        // Access mode for the reader
+	dataDropPolicy   DataDropPolicy    // Which event to drop when dataChan is full
+	blankCardPolicy  BlankCardPolicy   // How cards without NDEF data are reported
+	wearTracker      *WearTracker      // Counts successful writes per UID (optional)
+	signatureKey     *SignatureKey     // Verifies NTAG originality signatures (optional)
+	magicProbe       bool              // Probe MIFARE Classic cards for the gen1a backdoor in ReadCardInfo
+	allowedTypes     map[string]bool   // Card types read during polling (empty = all)
+	queueBusyWrites  bool              // Let writes wait while the device is busy instead of failing fast
+	wipeTrailing     bool              // Zero-fill the NDEF area after every written message
+	refreshOnRemoval bool              // Reconnect and poll at once after a write fails because the card left
+	removalGrace     time.Duration     // How long a removed card may take to come back before its removal is reported
+	retapCooldown    time.Duration     // How long after a UID is announced that re-taps of it are not announced
+	latency          *LatencyRecorder  // Rolling read/write duration histograms
 	clock            Clock             // Clock abstraction for time operations
 	statusMux        sync.RWMutex
 	cardPresent      bool           // Internal tracking of card presence
+	removedAt        time.Time      // When the present card left the field, zero unless a removal is held back
 	isWriting        bool           // Tracks if a write operation is in progress
+	paused           bool           // Tag polling suspended by Pause; device hot-plug is still handled
+	idle             bool           // Tag polling suspended by SetIdle while nobody is listening
 	operationMutex   sync.Mutex     // Protects tag operations (read/write)
 	operationTimeout time.Duration  // Timeout for tag operations
 	cardCheckTicker  Ticker         // Ticker for periodic card presence checks (based on cache)
 	workerWg         sync.WaitGroup // Tracks worker goroutine completion
+	cardWaiters      []chan NFCData // Pending WaitForCard calls
+	waitMux          sync.Mutex     // Protects cardWaiters
+
+	// When each UID was last announced, for retapCooldown; guarded by statusMux
+	announcedAt map[string]time.Time
+
+	// Open BeginTransaction calls and the device guarding them; guarded by
+	// statusMux. pollMu is held while a poll talks to the card.
+	transactions      int
+	transactionDevice TransactionGuard
+	pollMu            sync.Mutex
 }
 
 // NewNFCReader creates and initializes a new NFCReader instance with default ModeReadWrite.
-func NewNFCReader(deviceStr string, manager Manager, opTimeout time.Duration) (*NFCReader, error) {
-	return NewNFCReaderWithClock(deviceStr, manager, opTimeout, nil)
+// An optional ReaderOptions sets the channel buffer sizes.
+func NewNFCReader(deviceStr string, manager Manager, opTimeout time.Duration, opts ...ReaderOptions) (*NFCReader, error) {
+	return NewNFCReaderWithClock(deviceStr, manager, opTimeout, nil, opts...)
 }
 
 // NewNFCReaderWithClock creates and initializes a new NFCReader with a custom clock.
 // If clock is nil, uses RealClock.
-func NewNFCReaderWithClock(deviceStr string, manager Manager, opTimeout time.Duration, clock Clock) (*NFCReader, error) {
+func NewNFCReaderWithClock(deviceStr string, manager Manager, opTimeout time.Duration, clock Clock, opts ...ReaderOptions) (*NFCReader, error) {
 	if manager == nil {
 		return nil, fmt.Errorf("NFCManager cannot be nil")
 	}
@@ -68,23 +205,40 @@ func NewNFCReaderWithClock(deviceStr string, manager Manager, opTimeout time.Dur
 		clock = NewRealClock()
 	}
 
+	var options ReaderOptions
+	if len(opts) > 0 {
+		options = opts[0]
+	}
+	if options.DataBufferSize <= 0 {
+		options.DataBufferSize = DefaultDataBufferSize
+	}
+	if options.StatusBufferSize <= 0 {
+		options.StatusBufferSize = DefaultStatusBufferSize
+	}
+
 	deviceManager := NewDeviceManager(manager, deviceStr, clock)
+	deviceManager.SetDeviceSelection(options.DeviceSelection)
 
 	reader := &NFCReader{
 		deviceManager:    deviceManager,
-		dataChan:         make(chan NFCData, 1),      // Buffered to prevent blocking on send if no listener
-		statusChan:       make(chan DeviceStatus, 1), // Buffered for status updates
+		dataChan:         make(chan NFCData, options.DataBufferSize),        // Buffered to prevent blocking on send if no listener
+		statusChan:       make(chan DeviceStatus, options.StatusBufferSize), // Buffered for status updates
 		stopChan:         make(chan struct{}),
 		cache:            NewTagCache(),
 		mode:             ModeReadWrite, // Default to read/write mode
 		clock:            clock,
+		latency:          NewLatencyRecorder(clock),
 		cardPresent:      false,
 		operationTimeout: opTimeout,
 	}
 
 	// Attempt initial connection synchronously
 	// If it fails, the worker will retry via device check ticker
-	_ = deviceManager.EnsureConnected(make(chan struct{}))
+	var connectStop <-chan struct{}
+	if options.Context != nil {
+		connectStop = options.Context.Done()
+	}
+	_ = deviceManager.EnsureConnected(connectStop)
 
 	return reader, nil
 }
@@ -97,6 +251,204 @@ func (r *NFCReader) SetMode(mode ReaderMode) {
 	log.Printf("Reader mode changed to: %v", mode)
 }
 
+// SetDataDropPolicy sets which tag event is dropped when the consumer falls behind.
+func (r *NFCReader) SetDataDropPolicy(policy DataDropPolicy) {
+	r.statusMux.Lock()
+	defer r.statusMux.Unlock()
+	r.dataDropPolicy = policy
+}
+
+// SetBlankCardPolicy sets how cards without NDEF data are reported.
+func (r *NFCReader) SetBlankCardPolicy(policy BlankCardPolicy) {
+	r.statusMux.Lock()
+	defer r.statusMux.Unlock()
+	r.blankCardPolicy = policy
+}
+
+// SetWearTracker sets the tracker that counts successful writes per card UID.
+// Passing nil disables tracking.
+func (r *NFCReader) SetWearTracker(w *WearTracker) {
+	r.statusMux.Lock()
+	defer r.statusMux.Unlock()
+	r.wearTracker = w
+}
+
+// SetQueueWritesWhileBusy controls writes started while the device is
+// reconnecting or in cooldown. By default they fail at once with
+// ErrDeviceBusy or ErrDeviceCooldown; with queue set they wait for the
+// operation slot like any other write, and may end in an operation timeout.
+func (r *NFCReader) SetQueueWritesWhileBusy(queue bool) {
+	r.statusMux.Lock()
+	defer r.statusMux.Unlock()
+	r.queueBusyWrites = queue
+}
+
+// SetHardwareReset enables resetting a wedged reader at the hardware level
+// (see DeviceManager.SetHardwareReset), both automatically after repeated
+// cooldowns and through ResetDevice.
+func (r *NFCReader) SetHardwareReset(enabled bool) {
+	r.deviceManager.SetHardwareReset(enabled)
+}
+
+// SetSignatureKey sets the public key ReadCardInfo checks NTAG21x
+// originality signatures against. Passing nil reports the raw signature only.
+func (r *NFCReader) SetSignatureKey(key *SignatureKey) {
+	r.statusMux.Lock()
+	defer r.statusMux.Unlock()
+	r.signatureKey = key
+}
+
+// SetAllowedCardTypes limits polling to the given card types (as reported by
+// Tag.Type). Tags of other types are not read, saving the device traffic of
+// reading cards nobody will use. An empty set allows every type. The set is
+// copied, so later changes to types need another call.
+func (r *NFCReader) SetAllowedCardTypes(types map[string]bool) {
+	allowed := make(map[string]bool, len(types))
+	for cardType, ok := range types {
+		if ok {
+			allowed[cardType] = true
+		}
+	}
+
+	r.statusMux.Lock()
+	defer r.statusMux.Unlock()
+	r.allowedTypes = allowed
+}
+
+// SetWipeTrailing makes every write zero-fill the card's NDEF area after the
+// new message, as WriteOptions.WipeTrailing does for a single write. It is off
+// by default, since it writes every remaining block or page of the card.
+func (r *NFCReader) SetWipeTrailing(enabled bool) {
+	r.statusMux.Lock()
+	defer r.statusMux.Unlock()
+	r.wipeTrailing = enabled
+}
+
+// SetRefreshOnWriteRemoval makes a write that fails because the card left the
+// field close the device, reconnect it and poll once right away, instead of
+// leaving that to the next poll tick, so the reader is ready for the next card
+// as soon as the failed write returns. Off by default.
+func (r *NFCReader) SetRefreshOnWriteRemoval(enabled bool) {
+	r.statusMux.Lock()
+	defer r.statusMux.Unlock()
+	r.refreshOnRemoval = enabled
+}
+
+// SetRemovalGrace sets how long a card that left the field may take to come
+// back before its removal is reported. A card that briefly loses contact, e.g.
+// from vibration on a counter, and returns with the same UID within the window
+// is neither removed from the cache nor announced again. A different card ends
+// the window at once. Zero, the default, reports every removal immediately.
+func (r *NFCReader) SetRemovalGrace(grace time.Duration) {
+	r.statusMux.Lock()
+	defer r.statusMux.Unlock()
+	r.removalGrace = grace
+}
+
+// SetRetapCooldown sets how long after a card is announced that the same UID
+// is not announced again, even if the card is removed and presented anew, so
+// a double tap in a payment or attendance flow is processed once. Unlike the
+// cache, which only dedupes a card while it stays on the reader, the window
+// spans removal and re-tap. WaitForCard still returns suppressed cards. Zero,
+// the default, announces every tap.
+func (r *NFCReader) SetRetapCooldown(cooldown time.Duration) {
+	r.statusMux.Lock()
+	defer r.statusMux.Unlock()
+	r.retapCooldown = cooldown
+	r.announcedAt = nil
+}
+
+// inRetapCooldown reports whether uid was announced within the retap
+// cooldown, and otherwise records it as announced now. Expired entries are
+// dropped along the way.
+func (r *NFCReader) inRetapCooldown(uid string) bool {
+	r.statusMux.Lock()
+	defer r.statusMux.Unlock()
+	if r.retapCooldown <= 0 || uid == "" {
+		return false
+	}
+
+	now := r.clock.Now()
+	for seen, at := range r.announcedAt {
+		if now.Sub(at) >= r.retapCooldown {
+			delete(r.announcedAt, seen)
+		}
+	}
+	if _, ok := r.announcedAt[uid]; ok {
+		return true
+	}
+	if r.announcedAt == nil {
+		r.announcedAt = make(map[string]time.Time)
+	}
+	r.announcedAt[uid] = now
+	return false
+}
+
+// SetMagicProbe enables probing MIFARE Classic cards for the gen1a "magic"
+// backdoor in ReadCardInfo. It is off by default, since the probe sends
+// non-standard commands that halt the card and needs a PN53x-based reader.
+func (r *NFCReader) SetMagicProbe(enabled bool) {
+	r.statusMux.Lock()
+	defer r.statusMux.Unlock()
+	r.magicProbe = enabled
+}
+
+// AllowedCardTypes returns the card types polling reads, sorted. An empty
+// result means every type is allowed.
+func (r *NFCReader) AllowedCardTypes() []string {
+	r.statusMux.RLock()
+	defer r.statusMux.RUnlock()
+	types := make([]string, 0, len(r.allowedTypes))
+	for cardType := range r.allowedTypes {
+		types = append(types, cardType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// isCardTypeAllowed reports whether polling should read a tag of cardType.
+func (r *NFCReader) isCardTypeAllowed(cardType string) bool {
+	r.statusMux.RLock()
+	defer r.statusMux.RUnlock()
+	return len(r.allowedTypes) == 0 || r.allowedTypes[cardType]
+}
+
+// WearStats returns the recorded write history for uid. With no tracker set,
+// every card reports zero writes.
+func (r *NFCReader) WearStats(uid string) WearStats {
+	r.statusMux.RLock()
+	w := r.wearTracker
+	r.statusMux.RUnlock()
+
+	if w == nil {
+		return WearStats{UID: normalizeWearUID(uid)}
+	}
+	return w.Stats(uid)
+}
+
+// recordWrite counts a successful write to the card with the given UID.
+// Persistence failures are logged; wear tracking is advisory.
+func (r *NFCReader) recordWrite(uid string) {
+	r.statusMux.RLock()
+	w := r.wearTracker
+	r.statusMux.RUnlock()
+
+	if w == nil {
+		return
+	}
+	if err := w.RecordWrite(uid, r.clock.Now()); err != nil {
+		log.Printf("Warning: failed to record write for UID %s: %v", uid, err)
+	}
+}
+
+// LatencyStats returns rolling duration percentiles of card reads while
+// polling (LatencyRead), writes and formats (LatencyWrite) and other tag
+// operations (LatencyOperation) over the last LatencyWindow. Kinds with no
+// operations in the window are omitted.
+func (r *NFCReader) LatencyStats() map[string]LatencyStats {
+	return r.latency.Stats()
+}
+
 // GetMode returns the current reader mode.
 func (r *NFCReader) GetMode() ReaderMode {
 	r.statusMux.RLock()
@@ -129,6 +481,26 @@ func (r *NFCReader) Stop() {
 	// Worker's defer will handle device closing and final status.
 }
 
+// WaitIdle waits until no tag operation (write, format, card info, ...) holds
+// the device, so it can be closed without cutting one short. Call Pause first
+// to keep polling from starting new reads. It returns ctx.Err() if ctx ends
+// first.
+func (r *NFCReader) WaitIdle(ctx context.Context) error {
+	idle := make(chan struct{})
+	go func() {
+		r.operationMutex.Lock()
+		r.operationMutex.Unlock()
+		close(idle)
+	}()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 // Start begins the NFC reading process in a separate goroutine.
 func (r *NFCReader) Start() {
 	log.Println("NFCReader Start called, starting worker.")
@@ -136,6 +508,59 @@ func (r *NFCReader) Start() {
 	go r.worker()
 }
 
+// Pause stops polling for tags while the worker keeps running, so the reader
+// still connects and reconnects devices but does not touch cards. Explicit
+// operations such as writes still run while paused. The flag is separate from
+// the one writes set, so a write finishing does not resume polling and pausing
+// does not interrupt a write in flight.
+func (r *NFCReader) Pause() {
+	r.statusMux.Lock()
+	defer r.statusMux.Unlock()
+	if !r.paused {
+		r.paused = true
+		log.Println("NFCReader paused: tag polling suspended.")
+	}
+}
+
+// Resume restarts tag polling after Pause. The tag cache is cleared, since
+// cards may have come and gone unseen, so the card on the reader is broadcast
+// again.
+func (r *NFCReader) Resume() {
+	r.statusMux.Lock()
+	defer r.statusMux.Unlock()
+	if r.paused {
+		r.paused = false
+		r.cache.Clear()
+		if r.idle {
+			log.Println("NFCReader resumed: tag polling stays suspended while idle.")
+		} else {
+			log.Println("NFCReader resumed: tag polling restarted.")
+		}
+	}
+}
+
+// SetIdle suspends tag polling like Pause while nothing consumes tag data,
+// e.g. no client is connected. It is tracked apart from Pause, so an explicit
+// pause is kept when consumers come and go, and polling only restarts once
+// the reader is neither paused nor idle.
+func (r *NFCReader) SetIdle(idle bool) {
+	r.statusMux.Lock()
+	defer r.statusMux.Unlock()
+	if r.idle != idle {
+		r.idle = idle
+		if !idle {
+			r.cache.Clear()
+		}
+	}
+}
+
+// IsPaused reports whether tag polling is suspended.
+func (r *NFCReader) IsPaused() bool {
+	r.statusMux.RLock()
+	defer r.statusMux.RUnlock()
+	return r.paused
+}
+
 // Data returns a channel that provides NFCData as tags are read.
 func (r *NFCReader) Data() <-chan NFCData {
 	return r.dataChan
@@ -164,11 +589,56 @@ func (r *NFCReader) GetDeviceStatus() DeviceStatus {
 		message = "Not connected"
 	}
 
+	info := r.ReaderInfo()
 	return DeviceStatus{
-		Connected:   connected,
-		Message:     message,
-		CardPresent: cardPres,
+		Connected:      connected,
+		Message:        message,
+		CardPresent:    cardPres,
+		ReaderModel:    info.Model,
+		ReaderFirmware: info.Firmware,
+	}
+}
+
+// ReaderInfo returns the model and firmware of the connected reader. Fields
+// are empty without a device or when the reader did not report them.
+func (r *NFCReader) ReaderInfo() ReaderInfo {
+	if info, ok := r.deviceManager.Device().(ReaderInfoProvider); ok {
+		return info.ReaderInfo()
+	}
+	return ReaderInfo{}
+}
+
+// DeviceCapabilities returns the capabilities of the connected device, and
+// false with the best-effort defaults of BuildDeviceCapabilities when no
+// device is connected (PC/SC readers only have one while a card is present).
+func (r *NFCReader) DeviceCapabilities() (DeviceCapabilities, bool) {
+	dev := r.deviceManager.Device()
+	if dev == nil {
+		return BuildDeviceCapabilities(nil), false
+	}
+	return BuildDeviceCapabilities(dev), true
+}
+
+// ReadATR returns the Answer To Reset of the card on the reader. Only PC/SC
+// readers report one; for other backends a not-supported error is returned.
+func (r *NFCReader) ReadATR() ([]byte, error) {
+	if err := r.requireDevice(); err != nil {
+		return nil, err
+	}
+	device := r.deviceManager.Device()
+	provider, ok := device.(CardATRProvider)
+	if !ok {
+		backend := "this"
+		if info, ok := device.(DeviceInfoProvider); ok {
+			backend = "the " + info.DeviceType()
+		}
+		return nil, &NFCError{Code: ErrCodeNotSupported, Op: "ReadATR", Message: fmt.Sprintf("ATR not available for %s backend", backend)}
 	}
+	atr := provider.CardATR()
+	if len(atr) == 0 {
+		return nil, fmt.Errorf("no card on the reader")
+	}
+	return atr, nil
 }
 
 // readCardPresent safely reads the cardPresent flag.
@@ -210,6 +680,14 @@ func (r *NFCReader) handleDeviceEvent(event DeviceEvent) {
 		log.Printf("Device event: Error - %s", event.Message)
 		// Error already handled by DeviceManager
 
+	case DeviceReconnected:
+		log.Printf("Device event: Reconnected - %s (reason: %s)", event.Message, event.Reason)
+		status := r.GetDeviceStatus()
+		status.Message = event.Message
+		status.Event = DeviceStatusEventReconnected
+		status.Reason = event.Reason
+		r.sendDeviceStatus(status)
+
 	default:
 		log.Printf("Device event: Unknown type %d - %s", event.Type, event.Message)
 	}
@@ -217,9 +695,16 @@ func (r *NFCReader) handleDeviceEvent(event DeviceEvent) {
 
 // handleCardCheck updates card presence based on cache status.
 func (r *NFCReader) handleCardCheck() {
+	// Polling is suspended during a transaction, so the cache goes stale
+	if r.InTransaction() {
+		return
+	}
 	currentCacheCardPresent := r.cache.IsCardPresent()
 	cardPres := r.readCardPresent()
 	if cardPres != currentCacheCardPresent {
+		if !currentCacheCardPresent && r.holdCardRemoval() {
+			return
+		}
 		r.setCardPresent(currentCacheCardPresent)
 		if currentCacheCardPresent {
 			uid := r.cache.GetLastScanned()
@@ -242,19 +727,26 @@ func (r *NFCReader) handleDeviceErrors(err error) bool {
 	// Handle card removal specially - close device to allow reconnection
 	if IsCardRemovedError(err) {
 		log.Println("Card was removed, closing device for reconnection")
+		r.notifyCardWaiters(NFCData{Err: ErrCardRemovedDuringRead})
 		r.deviceManager.Close()
-		r.setCardPresent(false)
-		r.broadcastDeviceStatus("Card removed, waiting for new card")
+		if !r.holdCardRemoval() {
+			r.setCardPresent(false)
+			r.broadcastDeviceStatus("Card removed, waiting for new card")
+		}
 		return true
 	}
 
 	// Handle unsupported tags - don't close device, just wait for card removal
 	// Closing would cause immediate reconnection to the same unsupported tag
-	if IsUnsupportedTagError(err) {
+	var unsupported *unsupportedTagError
+	if errors.As(err, &unsupported) {
 		// Error is only returned once per card by the device, so just log it
 		log.Printf("Unsupported tag detected: %v - waiting for card removal", err)
 		r.setCardPresent(true) // Card is present, just not supported
 		r.broadcastDeviceStatus("Unsupported tag, please use a different card")
+		if unsupported.Event {
+			r.sendData(NFCData{Unsupported: &UnsupportedCard{UID: unsupported.UID, ATR: unsupported.ATR}})
+		}
 		// Don't close - the card removal detection will handle when the card is removed
 		return true
 	}
@@ -276,7 +768,7 @@ func (r *NFCReader) handleDeviceErrors(err error) bool {
 	// For unhandled errors, send to data channel
 	if !IsIOError(err) && !IsDeviceConfigError(err) && !IsTimeoutError(err) && !IsDeviceClosedError(err) {
 		log.Printf("Unhandled error from getTags: %v. Sending to dataChan.", err)
-		r.dataChan <- NFCData{Card: nil, Err: fmt.Errorf("get tags error: %v", err)}
+		r.sendData(NFCData{Card: nil, Err: fmt.Errorf("get tags error: %v", err)})
 		r.clock.Sleep(UnhandledErrorRetryInterval)
 	}
 
@@ -295,6 +787,7 @@ func (r *NFCReader) handleTagPolling(tags []Tag) {
 		for _, tag := range tags {
 			uid := tag.UID()
 			if uid != "" {
+				r.endCardRemovalHold(uid)
 				r.cache.UpdateLastSeenTime(uid)
 				// Mark as seen so writes can proceed
 				r.cache.HasChanged(uid)
@@ -307,35 +800,95 @@ func (r *NFCReader) handleTagPolling(tags []Tag) {
 		uid := tag.UID()
 
 		if uid != "" {
+			r.endCardRemovalHold(uid)
 			r.cache.UpdateLastSeenTime(uid)
 		}
 
+		// Skip disallowed types before touching the card, reporting each card once
+		if !r.isCardTypeAllowed(tag.Type()) {
+			if r.cache.HasChanged(uid) {
+				log.Printf("Card type '%s' not in allowed list, ignoring UID %s", tag.Type(), uid)
+				r.sendData(NFCData{Err: fmt.Errorf("card type '%s' %w", tag.Type(), ErrCardTypeNotAllowed)})
+			}
+			continue
+		}
+
 		// Create Card wrapper
 		card := NewCard(tag)
-		if _, err := card.ReadMessage(); err != nil {
+		readStart := r.clock.Now()
+		_, err := card.ReadMessage()
+		r.latency.Record(LatencyRead, r.clock.Now().Sub(readStart))
+		if err != nil {
 			// Check if this is a card removal error - if so, close the device
-			if IsCardRemovedError(err) {
+			if r.cardLeftField(err) {
 				log.Println("Card was removed during read, closing device for reconnection")
+				r.notifyCardWaiters(NFCData{Card: card, Err: ErrCardRemovedDuringRead})
 				r.deviceManager.Close()
-				r.setCardPresent(false)
-				r.broadcastDeviceStatus("Card removed, waiting for new card")
+				if !r.holdCardRemoval() {
+					r.setCardPresent(false)
+					r.broadcastDeviceStatus("Card removed, waiting for new card")
+				}
 				return
 			}
 			log.Printf("Error reading data for card UID %s (Type: %s): %v", uid, card.Type, err)
 			// Send card with error
-			r.dataChan <- NFCData{Card: card, Err: err}
+			r.sendData(NFCData{Card: card, Err: err})
 			continue
 		}
 
+		// The cache records blank cards whatever the policy, so a suppressed
+		// card is still the one writes are checked against
 		if r.cache.HasChanged(uid) {
-			log.Printf("Card data changed or new card: UID %s (Type: %s)", uid, card.Type)
-			r.dataChan <- NFCData{Card: card, Err: nil}
+			r.announceCard(card)
 		}
 
 		r.clock.Sleep(DefaultPollingInterval)
 	}
 }
 
+// announceCard sends a newly read card unless it was re-tapped within the
+// retap cooldown.
+func (r *NFCReader) announceCard(card *Card) {
+	if r.inRetapCooldown(card.UID) {
+		log.Printf("Card re-tapped within the retap cooldown, not announced: UID %s", card.UID)
+		r.notifyCardWaiters(NFCData{Card: card})
+		return
+	}
+	log.Printf("Card data changed or new card: UID %s (Type: %s)", card.UID, card.Type)
+	r.sendCard(card)
+}
+
+// sendCard sends a read card, applying the blank card policy. Suppressed
+// blank cards still wake WaitForCard callers.
+func (r *NFCReader) sendCard(card *Card) {
+	r.statusMux.RLock()
+	policy := r.blankCardPolicy
+	r.statusMux.RUnlock()
+
+	switch blank := card.IsBlank(); {
+	case blank && policy == BlankCardsSuppress:
+		log.Printf("Blank card not announced: UID %s (Type: %s)", card.UID, card.Type)
+		r.notifyCardWaiters(NFCData{Card: card})
+	case blank && policy == BlankCardsFlag:
+		log.Printf("Blank card: UID %s (Type: %s)", card.UID, card.Type)
+		r.sendData(NFCData{Card: card, Blank: true})
+	default:
+		r.sendData(NFCData{Card: card})
+	}
+}
+
+// cardLeftField reports whether a read error means the card was removed. Besides
+// explicit removal errors, this covers a tag that GetTags returned just as it
+// was taken away: the device still listed it, but the first access fails with
+// a generic error and the card is no longer in the field.
+func (r *NFCReader) cardLeftField(err error) bool {
+	if IsCardRemovedError(err) {
+		return true
+	}
+	checker, ok := r.deviceManager.Device().(CardPresenceChecker)
+	return ok && !checker.IsCardPresent()
+}
+
 func (r *NFCReader) worker() {
 	log.Println("NFCReader worker started.")
 	defer log.Println("NFCReader worker stopped.")
@@ -405,6 +958,7 @@ func (r *NFCReader) doPoll() {
 
 	r.statusMux.RLock()
 	isWrite := r.isWriting
+	paused := r.paused || r.idle
 	r.statusMux.RUnlock()
 
 	if inCool {
@@ -413,7 +967,7 @@ func (r *NFCReader) doPoll() {
 
 	// Try to connect if no device
 	if !hasDev {
-		if err := r.deviceManager.TryConnect(); err != nil {
+		if err := r.deviceManager.TryConnect(r.stopChan); err != nil {
 			// No card present is normal - just wait and retry
 			if !IsNoCardError(err) {
 				log.Printf("Connection attempt failed: %v", err)
@@ -422,7 +976,15 @@ func (r *NFCReader) doPoll() {
 		return
 	}
 
-	if isWrite {
+	if isWrite || paused {
+		return
+	}
+
+	// BeginTransaction waits for a poll holding pollMu, so one begun after
+	// the checks above is seen here
+	r.pollMu.Lock()
+	defer r.pollMu.Unlock()
+	if r.InTransaction() {
 		return
 	}
 
@@ -448,6 +1010,94 @@ func (r *NFCReader) broadcastDeviceStatus(customMessage ...string) {
 		status.Message = customMessage[0]
 	}
 
+	r.sendDeviceStatus(status)
+}
+
+// sendData pushes tag data to the data channel without blocking the worker.
+// When the channel is full, an event is dropped according to the drop policy.
+func (r *NFCReader) sendData(data NFCData) {
+	if data.Card != nil {
+		r.notifyCardWaiters(data)
+	}
+
+	select {
+	case r.dataChan <- data:
+		return
+	default:
+	}
+
+	r.statusMux.RLock()
+	policy := r.dataDropPolicy
+	r.statusMux.RUnlock()
+
+	if policy == DropNewest {
+		log.Println("Warning: Data channel full, dropping newest tag event.")
+		return
+	}
+
+	// Drop the queued event to make room; the consumer may have taken it meanwhile
+	select {
+	case <-r.dataChan:
+		log.Println("Warning: Data channel full, dropping oldest tag event.")
+	default:
+	}
+
+	select {
+	case r.dataChan <- data:
+	default:
+		log.Println("Warning: Data channel still full, dropping newest tag event.")
+	}
+}
+
+// WaitForCard blocks until the next card is read and returns it. If a card is
+// tapped but removed before the read completes, it returns
+// ErrCardRemovedDuringRead right away instead of waiting for the timeout.
+// A card already resting on the reader does not count; it must be tapped again.
+func (r *NFCReader) WaitForCard(timeout time.Duration) (*Card, error) {
+	ch := make(chan NFCData, 1)
+
+	r.waitMux.Lock()
+	r.cardWaiters = append(r.cardWaiters, ch)
+	r.waitMux.Unlock()
+
+	defer r.removeCardWaiter(ch)
+
+	select {
+	case data := <-ch:
+		return data.Card, data.Err
+	case <-r.clock.After(timeout):
+		return nil, ErrWaitTimeout
+	case <-r.stopChan:
+		return nil, fmt.Errorf("reader stopped while waiting for card")
+	}
+}
+
+// removeCardWaiter unregisters a WaitForCard channel.
+func (r *NFCReader) removeCardWaiter(ch chan NFCData) {
+	r.waitMux.Lock()
+	defer r.waitMux.Unlock()
+	for i, waiter := range r.cardWaiters {
+		if waiter == ch {
+			r.cardWaiters = append(r.cardWaiters[:i], r.cardWaiters[i+1:]...)
+			return
+		}
+	}
+}
+
+// notifyCardWaiters resolves all pending WaitForCard calls with data.
+func (r *NFCReader) notifyCardWaiters(data NFCData) {
+	r.waitMux.Lock()
+	waiters := r.cardWaiters
+	r.cardWaiters = nil
+	r.waitMux.Unlock()
+
+	for _, ch := range waiters {
+		ch <- data // Buffered; each waiter is notified at most once
+	}
+}
+
+// sendDeviceStatus pushes a status update to the status channel without blocking.
+func (r *NFCReader) sendDeviceStatus(status DeviceStatus) {
 	select {
 	case r.statusChan <- status:
 	default:
@@ -472,8 +1122,41 @@ func (r *NFCReader) GetLastScannedData() string {
 	return r.cache.GetLastScanned()
 }
 
+// ClearCache forgets the cached card so the next poll repopulates it from the
+// tag actually on the reader. It recovers from a cache holding a UID that no
+// longer matches, which otherwise fails writes until the card is removed.
+// Returns ErrOperationInProgress instead of clearing under a running write.
+func (r *NFCReader) ClearCache() error {
+	if !r.operationMutex.TryLock() {
+		return ErrOperationInProgress
+	}
+	defer r.operationMutex.Unlock()
+
+	log.Printf("Clearing tag cache (was UID: %s)", r.cache.GetLastScanned())
+	r.cache.Clear()
+	return nil
+}
+
+// ResetDevice resets the reader hardware, for readers that stop responding
+// until they are replugged. Polling picks the reader up again once it is back.
+// Returns ErrHardwareResetDisabled unless SetHardwareReset(true) was called.
+func (r *NFCReader) ResetDevice() error {
+	err := r.withTagOperation(func() error {
+		return r.deviceManager.ResetDevice()
+	})
+	if err != nil {
+		return err
+	}
+
+	r.cache.Clear()
+	r.setCardPresent(false)
+	r.broadcastDeviceStatus("Reader reset, waiting for it to come back")
+	return nil
+}
+
 func (r *NFCReader) setCardPresent(present bool) {
 	r.statusMux.Lock()
+	r.removedAt = time.Time{}
 	if r.cardPresent == present { // Avoid redundant updates
 		r.statusMux.Unlock()
 		return
@@ -499,7 +1182,43 @@ func (r *NFCReader) setCardPresent(present bool) {
 	r.broadcastDeviceStatus(message)
 }
 
-// WriteOptions controls how data is written to NFC cards at the reader level.
+// holdCardRemoval starts or continues the removal grace window for the
+// present card. It returns true while the removal should not be reported
+// yet, and false once the window has passed or when none is configured.
+func (r *NFCReader) holdCardRemoval() bool {
+	r.statusMux.Lock()
+	defer r.statusMux.Unlock()
+	if r.removalGrace <= 0 || !r.cardPresent {
+		return false
+	}
+	now := r.clock.Now()
+	if r.removedAt.IsZero() {
+		r.removedAt = now
+		log.Printf("Card left the field, holding its removal for %v", r.removalGrace)
+	}
+	return now.Sub(r.removedAt) < r.removalGrace
+}
+
+// endCardRemovalHold ends a held-back removal when a card with uid is
+// detected. If it is the card that left, the removal is dropped; any other
+// card first completes the removal, so it is announced as a new card.
+func (r *NFCReader) endCardRemovalHold(uid string) {
+	r.statusMux.Lock()
+	held := !r.removedAt.IsZero()
+	r.removedAt = time.Time{}
+	r.statusMux.Unlock()
+	if !held {
+		return
+	}
+
+	if uid == r.cache.GetLastScanned() {
+		log.Printf("Card UID %s came back within the removal grace window", uid)
+		return
+	}
+	r.setCardPresent(false)
+}
+
+// WriteOptions controls how data is written to NFC cards at the reader level.
 type WriteOptions struct {
 	// Overwrite completely replaces card data. If false, performs partial update.
 	// Partial updates only work if the card already contains valid NDEF data.
@@ -514,6 +1233,28 @@ type WriteOptions struct {
 	// contain existing data. WARNING: This will erase all existing data on the card.
 	// Only set this to true if you explicitly want to wipe and reinitialize the card.
 	ForceInitialize bool
+
+	// SectorKeys supplies per-sector authentication keys for MIFARE Classic writes.
+	// Sectors without a configured key fall back to the default keys.
+	SectorKeys ClassicKeyProvider
+
+	// LockAfterWrite makes the card read-only once the write has been read back
+	// and verified. The card is left writable if the write or verification fails.
+	// WARNING: Locking is permanent.
+	LockAfterWrite bool
+
+	// Trace, if set, records the APDUs exchanged during the write on devices
+	// that implement APDUTracer.
+	Trace *APDUTrace
+
+	// WipeTrailing zero-fills the card's NDEF area after the written message,
+	// so stale bytes of a longer earlier message do not remain. It is also
+	// applied to every write when enabled with SetWipeTrailing.
+	WipeTrailing bool
+
+	// ClassicSectors restricts the NDEF data of MIFARE Classic cards to these
+	// sectors (see TagWriteOptions.ClassicSectors).
+	ClassicSectors []int
 }
 
 // WriteCardData attempts to write data to a detected NFC card using default options (overwrite mode).
@@ -542,8 +1283,8 @@ func (r *NFCReader) prepareCardForWrite() (*Card, error) {
 		return nil, fmt.Errorf("reader is in read-only mode, write operations are not allowed")
 	}
 
-	if !r.deviceManager.HasDevice() {
-		return nil, fmt.Errorf("no NFC device connected")
+	if err := r.requireDevice(); err != nil {
+		return nil, err
 	}
 
 	r.statusMux.Lock()
@@ -576,7 +1317,7 @@ func (r *NFCReader) prepareCardForWrite() (*Card, error) {
 		r.cache.HasChanged(tag.UID())
 	} else if currentPresentCardUID != tag.UID() {
 		// Cache has a different card - unsafe to proceed
-		return nil, fmt.Errorf("tag UID mismatch: cache has %s but detected tag is %s", currentPresentCardUID, tag.UID())
+		return nil, fmt.Errorf("%w: cache has %s but detected tag is %s", ErrUIDMismatch, currentPresentCardUID, tag.UID())
 	}
 
 	// Create Card wrapper for the tag
@@ -586,10 +1327,22 @@ func (r *NFCReader) prepareCardForWrite() (*Card, error) {
 
 // writeMessageToCard performs the actual write operation with NDEF message handling.
 // Supports overwrite mode and partial update (append/replace at index).
+// Must be called within withTagOperation so the read-merge-write is not interleaved.
 func (r *NFCReader) writeMessageToCard(card *Card, msg *NDEFMessage, opts WriteOptions) error {
 	log.Printf("writeMessageToCard (UID: %s, Type: %s): overwrite=%v, index=%d",
 		card.UID, card.Type, opts.Overwrite, opts.Index)
 
+	// Refuse up front rather than write a card that can't then be locked
+	if opts.LockAfterWrite {
+		canLock, err := card.tag.CanMakeReadOnly()
+		if err != nil {
+			return fmt.Errorf("writeMessageToCard (UID: %s): cannot check lock support: %w", card.UID, err)
+		}
+		if !canLock {
+			return fmt.Errorf("writeMessageToCard (UID: %s): card cannot be made read-only", card.UID)
+		}
+	}
+
 	// Read current message to determine behavior
 	cachedMsg, cardReadErr := card.ReadMessage()
 	if cachedMsg == nil && cardReadErr == nil {
@@ -605,68 +1358,232 @@ func (r *NFCReader) writeMessageToCard(card *Card, msg *NDEFMessage, opts WriteO
 
 	if opts.Overwrite {
 		// Direct overwrite with provided message
-		data, err := msg.Encode()
-		if err != nil {
-			return fmt.Errorf("writeMessageToCard (UID: %s): error encoding message: %w", card.UID, err)
-		}
-
-		// If tag supports AdvancedWriter interface and ForceInitialize is set, use it
-		if opts.ForceInitialize {
-			if advWriter, ok := card.tag.(AdvancedWriter); ok {
-				tagOpts := TagWriteOptions{
-					ForceInitialize: opts.ForceInitialize,
-				}
-				if err := advWriter.WriteDataWithOptions(data, tagOpts); err != nil {
-					return fmt.Errorf("writeMessageToCard (UID: %s): error from WriteDataWithOptions: %w", card.UID, err)
-				}
-				log.Printf("writeMessageToCard (UID: %s): card write with ForceInitialize completed successfully.", card.UID)
-				return nil
-			}
-			log.Printf("writeMessageToCard (UID: %s): ForceInitialize requested but tag doesn't support AdvancedWriter, using standard write", card.UID)
-		}
-
-		// Standard write path
-		if err := card.WriteMessage(msg); err != nil {
-			return fmt.Errorf("writeMessageToCard (UID: %s): error from card.WriteMessage: %w", card.UID, err)
+		if err := r.writeWithTagOptions(card, msg, opts, nil); err != nil {
+			return fmt.Errorf("writeMessageToCard (UID: %s): %w", card.UID, err)
 		}
 
+		r.recordWrite(card.UID)
 		log.Printf("writeMessageToCard (UID: %s): card write completed successfully.", card.UID)
-		return nil
+		return r.lockIfRequested(card, msg, opts)
 	}
 
 	// Partial update mode: merge records from provided message into existing message
 	log.Printf("writeMessageToCard (UID: %s): attempting NDEF partial update", card.UID)
 
-	cachedMsgBuilder := cachedNdef.ToBuilder()
-	newMsgBuilder := msg.ToBuilder()
-
-	if opts.Index <= -1 || opts.Index >= len(cachedMsgBuilder.Records) {
-		// Append mode: add all new records
-		log.Printf("writeMessageToCard (UID: %s): appending %d new record(s)", card.UID, len(newMsgBuilder.Records))
-		cachedMsgBuilder.Records = append(cachedMsgBuilder.Records, newMsgBuilder.Records...)
+	if opts.Index <= -1 || opts.Index >= len(cachedNdef.Records()) {
+		log.Printf("writeMessageToCard (UID: %s): appending %d new record(s)", card.UID, len(msg.Records()))
 	} else {
-		// Replace mode: replace record at index with first new record
 		log.Printf("writeMessageToCard (UID: %s): replacing record at index %d", card.UID, opts.Index)
-		if len(newMsgBuilder.Records) > 0 {
-			cachedMsgBuilder.Records[opts.Index] = newMsgBuilder.Records[0]
-		}
 	}
 
-	// Build and write updated message
-	updatedMsg := cachedMsgBuilder.MustBuild()
+	// Build and write updated message. The layout of the read lets tags that
+	// support it rewrite only the blocks that change.
+	updatedMsg, err := mergeNDEF(cachedNdef, msg, opts.Index)
+	if err != nil {
+		return fmt.Errorf("writeMessageToCard (UID: %s): %w", card.UID, err)
+	}
+	layout := card.layout
 	card.Reset()
-	if err := card.WriteMessage(updatedMsg); err != nil {
+	if err := r.writeWithTagOptions(card, updatedMsg, opts, layout); err != nil {
 		log.Printf("writeMessageToCard (UID: %s): NDEF partial write failed: %v", card.UID, err)
 		return fmt.Errorf("writeMessageToCard (UID: %s): partial write failed: %w", card.UID, err)
 	}
 
+	r.recordWrite(card.UID)
 	log.Printf("writeMessageToCard (UID: %s): NDEF partial write succeeded", card.UID)
+	return r.lockIfRequested(card, updatedMsg, opts)
+}
+
+// WriteAndRead writes msg like WriteMessageWithOptions and reads the card
+// back within the same tag operation, so the card cannot be swapped between
+// the write and the read. With opts.Overwrite the message read back must
+// match msg. Errors name the stage that failed: write errors are those of
+// WriteMessageWithOptions, and read-back errors wrap ErrReadBackFailed (and
+// ErrVerifyFailed for a mismatch), as the card has been written by then.
+func (r *NFCReader) WriteAndRead(msg *NDEFMessage, opts WriteOptions) (ReadResult, error) {
+	var result ReadResult
+	err := r.withWriteOperation(func() error {
+		defer r.startTrace(opts.Trace)()
+
+		card, err := r.prepareCardForWrite()
+		if err != nil {
+			return err
+		}
+
+		defer func() {
+			r.statusMux.Lock()
+			r.isWriting = false
+			r.statusMux.Unlock()
+		}()
+
+		if err := r.writeMessageToCard(card, msg, opts); err != nil {
+			return fmt.Errorf("write failed: failed to write to card UID %s (Type: %s): %w", card.UID, card.Type, err)
+		}
+
+		data, err := card.tag.ReadData()
+		if err != nil {
+			return fmt.Errorf("card UID %s was written but %w: %w", card.UID, ErrReadBackFailed, err)
+		}
+		readBack, err := DecodeNDEF(data)
+		if err != nil {
+			return fmt.Errorf("card UID %s was written but %w: card data is not NDEF: %w", card.UID, ErrReadBackFailed, err)
+		}
+		if opts.Overwrite {
+			want, err := msg.Encode()
+			if err != nil || !bytes.Equal(data, want) {
+				return fmt.Errorf("card UID %s was written but %w: %w: card data does not match", card.UID, ErrReadBackFailed, ErrVerifyFailed)
+			}
+		}
+
+		log.Printf("Wrote and read back NDEF message on card UID: %s", card.UID)
+		result = ReadResult{UID: card.UID, Type: card.Type, Data: data, Message: readBack}
+		return nil
+	})
+	if err != nil {
+		return ReadResult{}, err
+	}
+	return result, nil
+}
+
+// mergeNDEF returns the message a partial update writes: the records of msg
+// appended to current, or with index in range, the record at index replaced
+// by the first record of msg. Records are kept as read, whatever tag family
+// they came from, so records the builder does not model survive the update.
+// It fails when the result would exceed MaxNDEFRecords.
+func mergeNDEF(current, msg *NDEFMessage, index int) (*NDEFMessage, error) {
+	records := append([]NDEFRecord(nil), current.Records()...)
+	added := msg.Records()
+
+	if index <= -1 || index >= len(records) {
+		records = append(records, added...)
+	} else if len(added) > 0 {
+		records[index] = added[0]
+	}
+	if err := checkRecordCount(len(records)); err != nil {
+		return nil, err
+	}
+	return &NDEFMessage{records: records}, nil
+}
+
+// lockIfRequested makes the card read-only when opts.LockAfterWrite is set,
+// but only after reading the card back and confirming it holds written.
+func (r *NFCReader) lockIfRequested(card *Card, written *NDEFMessage, opts WriteOptions) error {
+	if !opts.LockAfterWrite {
+		return nil
+	}
+
+	want, err := written.Encode()
+	if err != nil {
+		return fmt.Errorf("writeMessageToCard (UID: %s): error encoding message for verification: %w", card.UID, err)
+	}
+
+	data, err := card.tag.ReadData()
+	if err != nil {
+		return fmt.Errorf("writeMessageToCard (UID: %s): %w: read back failed, card not locked: %v", card.UID, ErrVerifyFailed, err)
+	}
+	readBack, err := DecodeNDEF(data)
+	if err != nil {
+		return fmt.Errorf("writeMessageToCard (UID: %s): %w: card data is not NDEF, card not locked", card.UID, ErrVerifyFailed)
+	}
+	got, err := readBack.Encode()
+	if err != nil || !bytes.Equal(got, want) {
+		return fmt.Errorf("writeMessageToCard (UID: %s): %w: card data does not match, card not locked", card.UID, ErrVerifyFailed)
+	}
+
+	// Sector keys configured for the write also unlock the Classic trailers
+	lock := card.tag.MakeReadOnly
+	if locker, ok := card.tag.(AdvancedLocker); ok && opts.SectorKeys != nil {
+		lock = func() error { return locker.MakeReadOnlyWithOptions(ReadOnlyOptions{SectorKeys: opts.SectorKeys}) }
+	}
+	if err := lock(); err != nil {
+		return fmt.Errorf("writeMessageToCard (UID: %s): write verified but lock failed: %w", card.UID, err)
+	}
+
+	log.Printf("writeMessageToCard (UID: %s): write verified, card is now read-only", card.UID)
+	return nil
+}
+
+// confirmSameCard checks that card is still the only card in the field and
+// returns ErrUIDMismatch otherwise. Devices implementing UIDReader are asked
+// for a fresh UID; others report the tags they currently see.
+func (r *NFCReader) confirmSameCard(card *Card) error {
+	if reader, ok := r.deviceManager.Device().(UIDReader); ok {
+		uid, err := reader.ReadUID()
+		if err != nil {
+			return fmt.Errorf("failed to confirm card UID: %w", err)
+		}
+		if !strings.EqualFold(uid, card.UID) {
+			return fmt.Errorf("%w: write prepared for %s but card in field is %s", ErrUIDMismatch, card.UID, uid)
+		}
+		return nil
+	}
+
+	tags, err := r.GetTags()
+	if err != nil {
+		return fmt.Errorf("failed to confirm card UID: %w", err)
+	}
+	if len(tags) != 1 {
+		return fmt.Errorf("%w: write prepared for %s but %d cards are in the field", ErrUIDMismatch, card.UID, len(tags))
+	}
+	if uid := tags[0].UID(); !strings.EqualFold(uid, card.UID) {
+		return fmt.Errorf("%w: write prepared for %s but card in field is %s", ErrUIDMismatch, card.UID, uid)
+	}
+	return nil
+}
+
+// writeWithTagOptions writes msg to the card, routing through AdvancedWriter when
+// tag-level options (ForceInitialize, SectorKeys, WipeTrailing, ClassicSectors) or a layout from the previous read
+// are set and the tag supports them. Without a layout every block is rewritten.
+// The card's UID is confirmed right before and after the write so data meant for
+// one card is never committed to, or reported as written on, a swapped card.
+func (r *NFCReader) writeWithTagOptions(card *Card, msg *NDEFMessage, opts WriteOptions, layout *TLVLayout) error {
+	if err := r.confirmSameCard(card); err != nil {
+		return err
+	}
+	if err := r.writeToTag(card, msg, opts, layout); err != nil {
+		return err
+	}
+	return r.confirmSameCard(card)
+}
+
+// writeToTag performs the write for writeWithTagOptions.
+func (r *NFCReader) writeToTag(card *Card, msg *NDEFMessage, opts WriteOptions, layout *TLVLayout) error {
+	r.statusMux.RLock()
+	opts.WipeTrailing = opts.WipeTrailing || r.wipeTrailing
+	r.statusMux.RUnlock()
+
+	if opts.ForceInitialize || opts.SectorKeys != nil || opts.WipeTrailing || len(opts.ClassicSectors) > 0 || layout != nil {
+		if advWriter, ok := card.tag.(AdvancedWriter); ok {
+			data, err := msg.Encode()
+			if err != nil {
+				return fmt.Errorf("error encoding message: %w", err)
+			}
+			tagOpts := TagWriteOptions{
+				ForceInitialize: opts.ForceInitialize,
+				SectorKeys:      opts.SectorKeys,
+				WipeTrailing:    opts.WipeTrailing,
+				Layout:          layout,
+				ClassicSectors:  opts.ClassicSectors,
+			}
+			if err := advWriter.WriteDataWithOptions(data, tagOpts); err != nil {
+				return fmt.Errorf("error from WriteDataWithOptions: %w", err)
+			}
+			return nil
+		}
+		log.Printf("writeMessageToCard (UID: %s): tag write options requested but tag doesn't support AdvancedWriter, using standard write", card.UID)
+	}
+
+	if err := card.WriteMessage(msg); err != nil {
+		return fmt.Errorf("error from card.WriteMessage: %w", err)
+	}
 	return nil
 }
 
 // WriteMessageWithOptions writes an NDEF message to a detected NFC card with options for record manipulation.
 func (r *NFCReader) WriteMessageWithOptions(msg *NDEFMessage, opts WriteOptions) error {
-	return r.withTagOperation(func() error {
+	return r.withWriteOperation(func() error {
+		defer r.startTrace(opts.Trace)()
+
 		card, err := r.prepareCardForWrite()
 		if err != nil {
 			return err
@@ -688,14 +1605,587 @@ func (r *NFCReader) WriteMessageWithOptions(msg *NDEFMessage, opts WriteOptions)
 	})
 }
 
+// CheckWrite reports whether msg could be written to the card on the reader
+// with opts, listing every reason it could not (see Card.WriteEligibility).
+// Read-only mode is one of the reasons, but the card is still checked so all
+// of them are reported together. Polling is paused for the check.
+func (r *NFCReader) CheckWrite(msg *NDEFMessage, opts WriteOptions) (ok bool, reasons []string, err error) {
+	r.statusMux.RLock()
+	mode := r.mode
+	r.statusMux.RUnlock()
+
+	if mode == ModeReadOnly {
+		reasons = append(reasons, "reader is in read-only mode")
+	}
+
+	err = r.withSingleTag(func(tag Tag) error {
+		_, cardReasons, err := NewCard(tag).WriteEligibility(msg, opts)
+		reasons = append(reasons, cardReasons...)
+		return err
+	})
+	if err != nil {
+		return false, reasons, err
+	}
+	return len(reasons) == 0, reasons, nil
+}
+
+// QuickWritable reports whether the card on the reader can be written, with
+// the cheap check of Card.QuickWritable, and the card's UID. In read-only
+// mode it reports false without touching the card. Polling is paused for the
+// check.
+func (r *NFCReader) QuickWritable() (uid string, writable bool, err error) {
+	r.statusMux.RLock()
+	mode := r.mode
+	r.statusMux.RUnlock()
+
+	err = r.withSingleTag(func(tag Tag) error {
+		uid = tag.UID()
+		if mode == ModeReadOnly {
+			return nil
+		}
+		writable, err = NewCard(tag).QuickWritable()
+		return err
+	})
+	if err != nil {
+		return "", false, err
+	}
+	return uid, writable, nil
+}
+
+// FormatNDEF initializes the detected card to an empty NDEF message.
+// Cards that are not in factory state are refused unless force is true.
+func (r *NFCReader) FormatNDEF(force bool) error {
+	return r.withWriteOperation(func() error {
+		card, err := r.prepareCardForWrite()
+		if err != nil {
+			return err
+		}
+
+		defer func() {
+			r.statusMux.Lock()
+			r.isWriting = false
+			r.statusMux.Unlock()
+		}()
+
+		formatter, ok := card.tag.(NDEFFormatter)
+		if !ok {
+			return NewNotSupportedError("FormatNDEF")
+		}
+
+		log.Printf("Formatting card UID: %s, Type: %s for NDEF (force=%v)", card.UID, card.Type, force)
+		if err := formatter.FormatNDEF(force); err != nil {
+			return fmt.Errorf("failed to format card UID %s (Type: %s): %w", card.UID, card.Type, err)
+		}
+		r.recordWrite(card.UID)
+
+		log.Printf("Successfully formatted card UID: %s", card.UID)
+		return nil
+	})
+}
+
+// ReadManufacturerBlock reads and decodes block 0 of the detected MIFARE Classic card.
+// Polling is paused for the duration of the read.
+func (r *NFCReader) ReadManufacturerBlock() (ManufacturerInfo, error) {
+	var info ManufacturerInfo
+	err := r.withSingleTag(func(tag Tag) error {
+		classic, ok := tag.(ClassicTag)
+		if !ok {
+			return NewNotSupportedError("ReadManufacturerBlock")
+		}
+
+		uid, sak, atqa, bccValid, err := classic.ReadManufacturerBlock()
+		if err != nil {
+			return fmt.Errorf("failed to read manufacturer block of card UID %s: %w", tag.UID(), err)
+		}
+
+		info = ManufacturerInfo{UID: uid, SAK: sak, ATQA: atqa, BCCValid: bccValid}
+		return nil
+	})
+	if err != nil {
+		// info may still be written by a timed-out operation, so don't touch it
+		return ManufacturerInfo{}, err
+	}
+	return info, nil
+}
+
+// ReadCardInfo identifies the detected card. For DESFire cards it also reads
+// the version data and free memory, which need no authentication. For NTAG21x
+// cards it reads the originality signature and, if a SignatureKey is set,
+// checks it. For MIFARE Classic cards it reads the general purpose byte of
+// each sector trailer, and with the magic probe enabled checks for the gen1a
+// backdoor. Polling is paused for the duration of the read.
+func (r *NFCReader) ReadCardInfo() (CardInfo, error) {
+	r.statusMux.RLock()
+	signatureKey := r.signatureKey
+	magicProbe := r.magicProbe
+	r.statusMux.RUnlock()
+
+	var info CardInfo
+	err := r.withSingleTag(func(tag Tag) error {
+		result := CardInfo{UID: tag.UID(), Type: tag.Type(), Reader: r.ReaderInfo()}
+
+		if df, ok := tag.(DESFireTag); ok {
+			version, err := df.GetVersion()
+			if err != nil {
+				return fmt.Errorf("failed to read version of card UID %s: %w", tag.UID(), err)
+			}
+			free, err := df.FreeMemory()
+			if err != nil {
+				// EV0 cards and some configurations refuse FreeMem; the version is still useful
+				log.Printf("ReadCardInfo (UID: %s): %v", tag.UID(), err)
+				free = -1
+			}
+			result.DESFire = &DESFireInfo{Version: version, FreeMemory: free}
+		}
+
+		if st, ok := tag.(SignatureTag); ok {
+			sig, err := st.ReadSignature()
+			if err != nil {
+				// Clones and some older tags refuse READ_SIG; the UID and type are still useful
+				log.Printf("ReadCardInfo (UID: %s): %v", tag.UID(), err)
+			} else {
+				result.Signature = sig
+			}
+			if signatureKey != nil {
+				result.Originality = OriginalityUnknown
+				if uid, err := hex.DecodeString(tag.UID()); err == nil && signatureKey.Verify(uid, sig) {
+					result.Originality = OriginalityGenuine
+				}
+			}
+		}
+
+		if ct, ok := tag.(ClassicTag); ok {
+			sectors := 16
+			if tag.Type() == CardTypeMifareClassic4K {
+				sectors = 40
+			}
+			result.GPB = readSectorGPBs(ct, sectors)
+		}
+
+		if mt, ok := tag.(MagicTag); ok && magicProbe {
+			magic, err := mt.ProbeMagic()
+			if err != nil {
+				// Readers without PN53x passthrough cannot send the unlock frame
+				log.Printf("ReadCardInfo (UID: %s): magic probe failed: %v", tag.UID(), err)
+			} else {
+				result.Magic = &magic
+			}
+		}
+
+		info = result
+		return nil
+	})
+	if err != nil {
+		return CardInfo{}, err
+	}
+	return info, nil
+}
+
+// ListTags returns the UID and type of every tag in the field, without
+// selecting or reading any of them. Only UID and Type are set. An empty
+// field yields an empty list rather than an error.
+func (r *NFCReader) ListTags() ([]CardInfo, error) {
+	infos := []CardInfo{}
+	err := r.withTags(func(tags []Tag) error {
+		for _, tag := range tags {
+			infos = append(infos, CardInfo{UID: tag.UID(), Type: tag.Type()})
+		}
+		return nil
+	})
+	if err != nil && !IsNoCardError(err) {
+		return nil, err
+	}
+	return infos, nil
+}
+
+// ReadOptions controls how ReadWithOptions reads the card.
+type ReadOptions struct {
+	// BestEffort continues past sectors that cannot be authenticated or read,
+	// on tags that support it (MIFARE Classic), and returns what could be read.
+	// Other tags are read normally.
+	BestEffort bool
+
+	// Trace, if set, records the APDUs exchanged during the read on devices
+	// that implement APDUTracer.
+	Trace *APDUTrace
+}
+
+// ReadResult is the NDEF data read by ReadWithOptions.
+type ReadResult struct {
+	UID            string
+	Type           string
+	Data           []byte       // Raw NDEF message; skipped sectors are zero-filled
+	Message        *NDEFMessage // Decoded message, nil if a partial read does not decode
+	SkippedSectors []int        // Sectors skipped by a best-effort read
+}
+
+// Partial reports whether sectors were skipped, so Data may be incomplete.
+func (r ReadResult) Partial() bool {
+	return len(r.SkippedSectors) > 0
+}
+
+// ReadWithOptions reads the NDEF message from the single card on the reader.
+// A partial best-effort read whose data no longer decodes is returned with a
+// nil Message rather than an error.
+func (r *NFCReader) ReadWithOptions(opts ReadOptions) (ReadResult, error) {
+	var result ReadResult
+	err := r.withSingleTag(func(tag Tag) error {
+		defer r.startTrace(opts.Trace)()
+
+		res := ReadResult{UID: tag.UID(), Type: tag.Type()}
+
+		var err error
+		if ber, ok := tag.(BestEffortReader); ok && opts.BestEffort {
+			res.Data, res.SkippedSectors, err = ber.ReadDataBestEffort()
+		} else {
+			res.Data, err = tag.ReadData()
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read card UID %s: %w", tag.UID(), err)
+		}
+		if res.Partial() {
+			log.Printf("ReadWithOptions (UID: %s): skipped unreadable sectors %v", tag.UID(), res.SkippedSectors)
+		}
+
+		msg, err := DecodeNDEF(res.Data)
+		if err == nil {
+			res.Message = msg
+		} else if !res.Partial() {
+			return fmt.Errorf("failed to decode NDEF from card UID %s: %w", tag.UID(), err)
+		}
+
+		result = res
+		return nil
+	})
+	if err != nil {
+		return ReadResult{}, err
+	}
+	return result, nil
+}
+
+// Rescan reads the single card on the reader afresh and announces it with
+// tagData even if it is the card last announced, for cards whose contents
+// changed while they stayed in the field. The blank card policy applies, the
+// retap cooldown does not.
+func (r *NFCReader) Rescan() (*Card, error) {
+	var card *Card
+	err := r.withSingleTag(func(tag Tag) error {
+		card = NewCard(tag)
+		card.Reset()
+		if _, err := card.ReadMessage(); err != nil {
+			return fmt.Errorf("failed to read card UID %s: %w", tag.UID(), err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	r.cache.HasChanged(card.UID)
+
+	log.Printf("Card rescanned: UID %s (Type: %s)", card.UID, card.Type)
+	r.sendCard(card)
+	return card, nil
+}
+
+// startTrace attaches trace to the current device if it supports tracing and
+// returns a function that detaches it again.
+func (r *NFCReader) startTrace(trace *APDUTrace) func() {
+	if trace == nil {
+		return func() {}
+	}
+	tracer, ok := r.deviceManager.Device().(APDUTracer)
+	if !ok {
+		log.Printf("APDU trace requested but the device does not support tracing")
+		return func() {}
+	}
+	tracer.SetTrace(trace)
+	return func() { tracer.SetTrace(nil) }
+}
+
+// ReadNDEFRange reads up to length bytes of the NDEF message starting at offset,
+// for tags that support partial reads (Type 4). Offsets at or beyond the message
+// length return an empty slice.
+func (r *NFCReader) ReadNDEFRange(offset, length int) ([]byte, error) {
+	var data []byte
+	err := r.withSingleTag(func(tag Tag) error {
+		rr, ok := tag.(NDEFRangeReader)
+		if !ok {
+			return NewNotSupportedError("ReadNDEFRange")
+		}
+		chunk, err := rr.ReadNDEFRange(offset, length)
+		if err != nil {
+			return fmt.Errorf("failed to read NDEF range of card UID %s: %w", tag.UID(), err)
+		}
+		data = chunk
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// RecordRead selects a file and reads one record from it on a Type 4 tag.
+type RecordRead struct {
+	AID    []byte   // Application to select first (optional)
+	FIDs   [][]byte // Files to select in order after the AID, e.g. a DF then an EF (optional)
+	Record byte     // Record number (1-254)
+	SFI    byte     // Short file identifier of the EF, or 0 for the last selected EF
+}
+
+// Steps of a RecordRead, reported in RecordResult.Step
+const (
+	RecordStepSelectAID  = "selectAID"
+	RecordStepSelectFID  = "selectFID"
+	RecordStepReadRecord = "readRecord"
+)
+
+// RecordResult is the response of the last APDU a RecordRead sent. A SELECT
+// that fails ends the sequence, so Step tells which APDU answered.
+type RecordResult struct {
+	Step     string // One of the RecordStep constants
+	Response APDUResponse
+}
+
+// ReadRecord performs req on the detected Type 4 tag within one tag operation,
+// so polling cannot select the NDEF application in between. Status words
+// from the card are returned in the result; errors are transport or
+// parameter failures.
+func (r *NFCReader) ReadRecord(req RecordRead) (RecordResult, error) {
+	var result RecordResult
+	err := r.withSingleTag(func(tag Tag) error {
+		isoTag, ok := tag.(ISO14443Tag)
+		if !ok {
+			return NewNotSupportedError("ReadRecord")
+		}
+
+		if len(req.AID) > 0 {
+			resp, err := isoTag.SelectByAID(req.AID)
+			if err != nil {
+				return fmt.Errorf("failed to select AID %X: %w", req.AID, err)
+			}
+			result = RecordResult{Step: RecordStepSelectAID, Response: resp}
+			if !resp.IsSuccess() {
+				return nil
+			}
+		}
+		for _, fid := range req.FIDs {
+			resp, err := isoTag.SelectByFID(fid)
+			if err != nil {
+				return fmt.Errorf("failed to select file %X: %w", fid, err)
+			}
+			result = RecordResult{Step: RecordStepSelectFID, Response: resp}
+			if !resp.IsSuccess() {
+				return nil
+			}
+		}
+
+		resp, err := isoTag.ReadRecord(req.Record, req.SFI)
+		if err != nil {
+			return fmt.Errorf("failed to read record %d of card UID %s: %w", req.Record, tag.UID(), err)
+		}
+		result = RecordResult{Step: RecordStepReadRecord, Response: resp}
+		return nil
+	})
+	if err != nil {
+		return RecordResult{}, err
+	}
+	return result, nil
+}
+
+// ReadMADInfo reads the MIFARE Application Directory of the detected MIFARE
+// Classic card. On a MAD CRC mismatch the entries are returned together with
+// an error wrapping ErrMADCRC. Polling is paused for the duration of the read.
+func (r *NFCReader) ReadMADInfo() ([]MADEntry, error) {
+	var entries []MADEntry
+	err := r.withSingleTag(func(tag Tag) error {
+		classic, ok := tag.(ClassicTag)
+		if !ok {
+			return NewNotSupportedError("ReadMADInfo")
+		}
+		var err error
+		entries, err = classic.ReadMADInfo()
+		if err != nil {
+			return fmt.Errorf("failed to read MAD of card UID %s: %w", tag.UID(), err)
+		}
+		return nil
+	})
+	return entries, err
+}
+
+// ReadPages reads count raw pages starting at start from the detected Type 2 tag
+// (MIFARE Ultralight or NTAG). Polling is paused for the duration of the read.
+func (r *NFCReader) ReadPages(start, count int) ([]byte, error) {
+	var data []byte
+	err := r.withPageTag("ReadPages", func(tag PageTag) error {
+		pages, err := tag.ReadPages(start, count)
+		if err != nil {
+			return fmt.Errorf("failed to read pages of card UID %s: %w", tag.UID(), err)
+		}
+		data = pages
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// WritePage writes one raw page to the detected Type 2 tag (MIFARE Ultralight or NTAG).
+func (r *NFCReader) WritePage(page int, data [4]byte) error {
+	return r.withPageTag("WritePage", func(tag PageTag) error {
+		if err := tag.WritePage(page, data); err != nil {
+			return fmt.Errorf("failed to write page %d of card UID %s: %w", page, tag.UID(), err)
+		}
+		r.recordWrite(tag.UID())
+		return nil
+	})
+}
+
+// withPageTag runs fn against the single detected tag if it supports raw page access.
+func (r *NFCReader) withPageTag(op string, fn func(PageTag) error) error {
+	return r.withSingleTag(func(tag Tag) error {
+		pageTag, ok := tag.(PageTag)
+		if !ok {
+			return NewNotSupportedError(op)
+		}
+		return fn(pageTag)
+	})
+}
+
+// requireDevice reports why tag operations cannot run: the device is in
+// cooldown (a *CooldownError) or not connected (ErrDeviceBusy). It returns
+// nil otherwise.
+func (r *NFCReader) requireDevice() error {
+	if r.deviceManager.InCooldown() {
+		return &CooldownError{Remaining: r.deviceManager.CooldownRemaining()}
+	}
+	if !r.deviceManager.HasDevice() {
+		return fmt.Errorf("no NFC device connected: %w", ErrDeviceBusy)
+	}
+	return nil
+}
+
+// withWriteOperation runs a write as a protected tag operation. Unless busy
+// writes are queued, a write is refused before waiting for the operation
+// slot when the device cannot take it, since an operation stuck on a lost
+// device may hold the slot until it gives up.
+func (r *NFCReader) withWriteOperation(operation func() error) error {
+	r.statusMux.RLock()
+	queue := r.queueBusyWrites
+	refresh := r.refreshOnRemoval
+	r.statusMux.RUnlock()
+
+	if !queue {
+		if err := r.requireDevice(); err != nil {
+			return err
+		}
+	}
+	if !refresh {
+		return r.withTimedOperation(LatencyWrite, operation)
+	}
+
+	// Refresh while still holding the operation slot, so no other operation
+	// finds the device of the card that left
+	return r.withTimedOperation(LatencyWrite, func() error {
+		err := operation()
+		if err != nil && r.cardLeftField(err) {
+			r.refreshAfterWriteRemoval()
+		}
+		return err
+	})
+}
+
+// refreshAfterWriteRemoval runs the recovery the poll loop would run on its
+// next tick after a card left the field during a write: the device is closed
+// and reopened, and a card already in the field is read and announced.
+func (r *NFCReader) refreshAfterWriteRemoval() {
+	log.Println("Card was removed during write, refreshing device")
+
+	r.pollMu.Lock()
+	defer r.pollMu.Unlock()
+
+	r.deviceManager.Close()
+	if !r.holdCardRemoval() {
+		r.setCardPresent(false)
+		r.broadcastDeviceStatus("Card removed, waiting for new card")
+	}
+
+	if err := r.deviceManager.TryConnect(r.stopChan); err != nil {
+		if !IsNoCardError(err) {
+			log.Printf("Reconnect after card removal failed: %v", err)
+		}
+		return
+	}
+	tags, err := r.GetTags()
+	if err != nil {
+		log.Printf("Poll after card removal failed: %v", err)
+		return
+	}
+	if len(tags) > 0 {
+		r.handleTagPolling(tags)
+	}
+}
+
+// withSingleTag runs fn against the single tag on the reader as a protected
+// tag operation, with polling paused.
+func (r *NFCReader) withSingleTag(fn func(Tag) error) error {
+	return r.withTags(func(tags []Tag) error {
+		if len(tags) != 1 {
+			return fmt.Errorf("expected exactly one card, detected %d", len(tags))
+		}
+		return fn(tags[0])
+	})
+}
+
+// withTags runs fn against all tags in the field as a protected tag
+// operation, with polling paused.
+func (r *NFCReader) withTags(fn func([]Tag) error) error {
+	return r.withTagOperation(func() error {
+		if err := r.requireDevice(); err != nil {
+			return err
+		}
+
+		r.statusMux.Lock()
+		r.isWriting = true
+		r.statusMux.Unlock()
+
+		defer func() {
+			r.statusMux.Lock()
+			r.isWriting = false
+			r.statusMux.Unlock()
+		}()
+
+		tags, err := r.GetTags()
+		if err != nil {
+			return fmt.Errorf("failed to get tags: %w", err)
+		}
+
+		return fn(tags)
+	})
+}
+
 // withTagOperation performs a protected tag operation with timeout.
+// The operation mutex is held until the operation itself returns, even if the
+// caller gives up on a timeout, so a read-merge-write can never interleave with
+// another operation still running on the tag.
 func (r *NFCReader) withTagOperation(operation func() error) error {
+	return r.withTimedOperation(LatencyOperation, operation)
+}
+
+// withTimedOperation is withTagOperation, recording how long the operation
+// held the device under the given latency kind.
+func (r *NFCReader) withTimedOperation(kind string, operation func() error) error {
 	r.operationMutex.Lock()
-	defer r.operationMutex.Unlock()
 
 	done := make(chan error, 1)
 	go func() {
-		done <- operation()
+		defer r.operationMutex.Unlock()
+		start := r.clock.Now()
+		err := operation()
+		r.latency.Record(kind, r.clock.Now().Sub(start))
+		done <- err
 	}()
 
 	select {
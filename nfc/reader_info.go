@@ -0,0 +1,80 @@
+package nfc
+
+import (
+	"bytes"
+	"strings"
+)
+
+// ReaderInfo identifies the reader hardware. Firmware versions differ in
+// their quirks, so recovery heuristics can key off it. Fields are empty when
+// the reader did not report them.
+type ReaderInfo struct {
+	Model    string // e.g. "ACR122U"
+	Firmware string // Version string as reported by the reader, e.g. "ACR122U207"
+}
+
+// ReaderInfoProvider is an optional interface for devices that know the model
+// and firmware of their reader.
+type ReaderInfoProvider interface {
+	ReaderInfo() ReaderInfo
+}
+
+// apduGetFirmwareVersion is the ACS pseudo-APDU asking the reader (not the
+// card) for its firmware version. The ACR122U answers with the bare ASCII
+// version; some firmware appends 90 00.
+var apduGetFirmwareVersion = []byte{CLAPCSC, 0x00, 0x48, 0x00, 0x00}
+
+// queryReaderInfo asks ACS readers for their firmware version. Other readers
+// are not sent the pseudo-APDU, as its meaning is vendor specific; their
+// ReaderInfo only carries a model when the reader name gives one.
+func queryReaderInfo(card scardCard, readerName string) ReaderInfo {
+	info := ReaderInfo{Model: readerModel(readerName)}
+	if !isACSReader(readerName) {
+		return info
+	}
+
+	resp, err := safeTransmit(card, apduGetFirmwareVersion)
+	if err != nil {
+		return info
+	}
+	info.Firmware = parseReaderFirmware(resp)
+	if info.Model == "" && info.Firmware != "" {
+		info.Model = strings.TrimRight(info.Firmware, "0123456789.")
+	}
+	return info
+}
+
+// isACSReader reports whether the PC/SC reader name is an ACS reader.
+func isACSReader(readerName string) bool {
+	upper := strings.ToUpper(readerName)
+	return strings.HasPrefix(upper, "ACS ") || strings.Contains(upper, "ACR")
+}
+
+// readerModel returns the ACS model in a PC/SC reader name such as
+// "ACS ACR122U PICC Interface 00 00", or "" if it has none.
+func readerModel(readerName string) string {
+	for _, word := range strings.Fields(readerName) {
+		if strings.HasPrefix(strings.ToUpper(word), "ACR") {
+			return word
+		}
+	}
+	return ""
+}
+
+// parseReaderFirmware returns the version string in a firmware answer, or ""
+// for error status words and answers that are not printable ASCII.
+func parseReaderFirmware(resp []byte) string {
+	if n := len(resp); n >= 2 && resp[n-2] == 0x90 && resp[n-1] == 0x00 {
+		resp = resp[:n-2]
+	}
+	resp = bytes.TrimSpace(resp)
+	if len(resp) < 3 {
+		return "" // Empty, or a bare error status word
+	}
+	for _, b := range resp {
+		if b < 0x20 || b > 0x7E {
+			return ""
+		}
+	}
+	return string(resp)
+}
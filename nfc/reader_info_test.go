@@ -0,0 +1,54 @@
+package nfc
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/ebfe/scard"
+)
+
+func TestQueryReaderInfo(t *testing.T) {
+	tests := []struct {
+		name       string
+		readerName string
+		resp       string // Answer to the firmware pseudo-APDU, "" for none
+		want       ReaderInfo
+		wantSent   bool
+	}{
+		{"ACR122U bare version", "ACS ACR122U PICC Interface 00 00", hex.EncodeToString([]byte("ACR122U207")), ReaderInfo{Model: "ACR122U", Firmware: "ACR122U207"}, true},
+		{"version with status word", "ACS ACR122U PICC Interface 00 00", hex.EncodeToString([]byte("ACR122U215")) + "9000", ReaderInfo{Model: "ACR122U", Firmware: "ACR122U215"}, true},
+		{"model from firmware", "ACS Reader 00 00", hex.EncodeToString([]byte("ACR122U201")), ReaderInfo{Model: "ACR122U", Firmware: "ACR122U201"}, true},
+		{"command refused", "ACS ACR1252 1S CL Reader PICC 0", "6a81", ReaderInfo{Model: "ACR1252"}, true},
+		{"other vendor not queried", "Identiv uTrust 3700 F CL Reader 0", "", ReaderInfo{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			card := newMockScardCard()
+			if tt.resp != "" {
+				card.addResponse(hex.EncodeToString(apduGetFirmwareVersion), tt.resp)
+			}
+
+			if got := queryReaderInfo(card, tt.readerName); got != tt.want {
+				t.Errorf("queryReaderInfo() = %+v, want %+v", got, tt.want)
+			}
+			if sent := len(card.callLog) > 0; sent != tt.wantSent {
+				t.Errorf("Firmware query sent = %v, want %v", sent, tt.wantSent)
+			}
+		})
+	}
+}
+
+func TestQueryReaderInfo_InvalidProtocol(t *testing.T) {
+	card := newMockScardCard()
+	card.protocol = scard.ProtocolUndefined
+	card.addResponse(hex.EncodeToString(apduGetFirmwareVersion), hex.EncodeToString([]byte("ACR122U207")))
+
+	want := ReaderInfo{Model: "ACR122U"}
+	if got := queryReaderInfo(card, "ACS ACR122U PICC Interface 00 00"); got != want {
+		t.Errorf("queryReaderInfo() = %+v, want %+v", got, want)
+	}
+	if len(card.callLog) != 0 {
+		t.Errorf("Expected no transmit on a reset card, got %d", len(card.callLog))
+	}
+}
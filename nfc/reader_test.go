@@ -1,7 +1,13 @@
 package nfc
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -644,6 +650,104 @@ func TestNFCReader_SingleCardWriteSucceeds(t *testing.T) {
 	t.Log("Write succeeded as expected")
 }
 
+// swapOnReadTag is a MockTag that runs onRead before each ReadData, letting
+// tests swap the card between preparing a write and committing it.
+type swapOnReadTag struct {
+	*MockTag
+	onRead func()
+}
+
+func (t *swapOnReadTag) ReadData() ([]byte, error) {
+	t.onRead()
+	return t.MockTag.ReadData()
+}
+
+// TestNFCReader_CardSwappedDuringWrite tests that a card swapped after the write
+// was prepared is detected before and after the data is committed.
+func TestNFCReader_CardSwappedDuringWrite(t *testing.T) {
+	tests := []struct {
+		name        string
+		swapOnRead  bool // Swap before the write is committed
+		wantWritten bool // Whether card A's data is written before the swap is noticed
+	}{
+		{name: "swapped before write", swapOnRead: true, wantWritten: false},
+		{name: "swapped during write", swapOnRead: false, wantWritten: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := NewMockManager()
+			manager.DevicesList = []string{"mock:usb:001"}
+
+			var mu sync.Mutex
+			swapped := false
+			swap := func() {
+				mu.Lock()
+				swapped = true
+				mu.Unlock()
+			}
+
+			tagA := NewMockTag("04A1B2C3")
+			tagA.TagType = "MIFARE Classic 1K"
+			tagA.IsConnected = true
+			tagA.Data = EncodeNdefMessageWithTextRecord("Hello", "en")
+			tagB := NewMockTag("04B2C3D4")
+			tagB.TagType = "MIFARE Classic 1K"
+			tagB.IsConnected = true
+
+			cardA := &swapOnReadTag{MockTag: tagA, onRead: func() {}}
+			if tt.swapOnRead {
+				cardA.onRead = swap
+			} else {
+				tagA.WriteDataFunc = func(data []byte) error {
+					tagA.Data = data
+					swap()
+					return nil
+				}
+			}
+
+			mockDevice := NewMockDevice()
+			mockDevice.GetTagsFunc = func() ([]Tag, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				if swapped {
+					return []Tag{tagB}, nil
+				}
+				return []Tag{cardA}, nil
+			}
+			manager.MockDevice = mockDevice
+
+			reader, err := NewNFCReader("mock:usb:001", manager, 5*time.Second)
+			if err != nil {
+				t.Fatalf("Failed to create NFCReader: %v", err)
+			}
+			defer reader.Close()
+
+			reader.cache.HasChanged("04A1B2C3")
+			reader.cache.UpdateLastSeenTime("04A1B2C3")
+			time.Sleep(100 * time.Millisecond)
+
+			err = reader.WriteCardData("Meant for A")
+			if !errors.Is(err, ErrUIDMismatch) {
+				t.Fatalf("Expected ErrUIDMismatch, got %v", err)
+			}
+
+			written := false
+			for _, call := range tagA.CallLog {
+				if strings.HasPrefix(call, "WriteData") {
+					written = true
+				}
+			}
+			if written != tt.wantWritten {
+				t.Errorf("Card A written = %v, want %v", written, tt.wantWritten)
+			}
+			if len(tagB.Data) != 0 {
+				t.Error("Card B must not be written")
+			}
+		})
+	}
+}
+
 // Helper function to check if string contains substring
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(s) > len(substr) && (s[:len(substr)] == substr || s[len(s)-len(substr):] == substr || findSubstring(s, substr)))
@@ -916,6 +1020,70 @@ func TestNFCReader_WriteMessageWithOptions_AppendMode(t *testing.T) {
 	}
 }
 
+// TestNFCReader_WriteMessageWithOptions_ConcurrentAppends tests that two appends
+// fired at once are linearized, even when the first one outlives the operation timeout.
+func TestNFCReader_WriteMessageWithOptions_ConcurrentAppends(t *testing.T) {
+	manager := NewMockManager()
+	manager.DevicesList = []string{"mock:usb:001"}
+
+	mockTag := NewMockClassicTag("04C3D4E6")
+	mockTag.IsConnected = true
+	mockTag.Data = EncodeNdefMessageWithTextRecord("First Record", "en")
+	mockTag.WriteDelay = 150 * time.Millisecond
+
+	mockDevice := NewMockDevice()
+	mockDevice.SetTags([]Tag{mockTag})
+	manager.MockDevice = mockDevice
+
+	// Timeout shorter than the write so the first caller gives up mid-write
+	reader, err := NewNFCReader("mock:usb:001", manager, 50*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Failed to create NFCReader: %v", err)
+	}
+	defer reader.Close()
+
+	time.Sleep(100 * time.Millisecond)
+
+	var wg sync.WaitGroup
+	for _, text := range []string{"Second Record", "Third Record"} {
+		wg.Add(1)
+		go func(text string) {
+			defer wg.Done()
+			msg := (&NDEFMessageBuilder{
+				Records: []NDEFRecordBuilder{&NDEFText{Content: text, Language: "en"}},
+			}).MustBuild()
+			// Either caller may time out; the writes themselves must still complete in order
+			_ = reader.WriteMessageWithOptions(msg, WriteOptions{Overwrite: false, Index: -1})
+		}(text)
+	}
+	wg.Wait()
+
+	// Wait for the second operation to acquire the lock and finish its write
+	reader.operationMutex.Lock()
+	reader.operationMutex.Unlock()
+
+	data, _ := mockTag.ReadData()
+	records, err := parseNDEFRecords(data)
+	if err != nil {
+		t.Fatalf("Failed to parse written NDEF: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("Expected 3 records after two appends, got %d", len(records))
+	}
+
+	texts := make(map[string]bool)
+	for _, rec := range records {
+		if text, ok := rec.GetText(); ok {
+			texts[text] = true
+		}
+	}
+	for _, want := range []string{"First Record", "Second Record", "Third Record"} {
+		if !texts[want] {
+			t.Errorf("Record %q lost after concurrent appends", want)
+		}
+	}
+}
+
 // TestNFCReader_WriteMessageWithOptions_ReplaceAtIndex tests replacing a record at specific index.
 func TestNFCReader_WriteMessageWithOptions_ReplaceAtIndex(t *testing.T) {
 	// Create mock manager
@@ -1091,3 +1259,1689 @@ func TestNFCReader_WriteMessageWithOptions_NoCard(t *testing.T) {
 		t.Errorf("Expected error about no card, got: %v", err)
 	}
 }
+
+// TestNFCReader_FormatNDEF tests formatting refuses non-factory cards unless forced.
+func TestNFCReader_FormatNDEF(t *testing.T) {
+	manager := NewMockManager()
+	manager.DevicesList = []string{"mock:usb:001"}
+
+	mockTag := NewMockClassicTag("04A1B2C3")
+	mockTag.TagType = "MIFARE Classic 1K"
+	mockTag.IsConnected = true
+	mockTag.Data = EncodeNdefMessageWithTextRecord("Hello", "en")
+
+	mockDevice := NewMockDevice()
+	mockDevice.SetTags([]Tag{mockTag})
+	manager.MockDevice = mockDevice
+
+	reader, err := NewNFCReader("mock:usb:001", manager, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Failed to create NFCReader: %v", err)
+	}
+	defer reader.Close()
+
+	tracker, err := NewWearTracker(nil)
+	if err != nil {
+		t.Fatalf("NewWearTracker() failed: %v", err)
+	}
+	reader.SetWearTracker(tracker)
+
+	reader.cache.HasChanged("04A1B2C3")
+	reader.cache.UpdateLastSeenTime("04A1B2C3")
+	time.Sleep(100 * time.Millisecond)
+
+	if err := reader.FormatNDEF(false); err == nil {
+		t.Error("Expected format to be refused for a card that is not in factory state")
+	}
+
+	if err := reader.FormatNDEF(true); err != nil {
+		t.Fatalf("Expected forced format to succeed, got: %v", err)
+	}
+
+	data, _ := mockTag.ReadData()
+	if len(data) != 0 {
+		t.Errorf("Expected empty NDEF message after format, got %d bytes", len(data))
+	}
+
+	// Only the forced format wrote the card
+	if stats := reader.WearStats("04A1B2C3"); stats.Writes != 1 {
+		t.Errorf("WearStats().Writes = %d, want 1", stats.Writes)
+	}
+}
+
+// TestNFCReader_SendDataDropPolicy tests that a full data channel never blocks
+// and drops events according to the configured policy.
+func TestNFCReader_SendDataDropPolicy(t *testing.T) {
+	tests := []struct {
+		policy  DataDropPolicy
+		wantUID string
+	}{
+		{DropOldest, "SECOND"},
+		{DropNewest, "FIRST"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy.String(), func(t *testing.T) {
+			reader, err := NewNFCReader("mock:usb:001", NewMockManager(), 5*time.Second)
+			if err != nil {
+				t.Fatalf("Failed to create NFCReader: %v", err)
+			}
+			defer reader.Close()
+			reader.SetDataDropPolicy(tt.policy)
+
+			done := make(chan struct{})
+			go func() {
+				// No consumer: the second send must not block
+				reader.sendData(NFCData{Card: &Card{UID: "FIRST"}})
+				reader.sendData(NFCData{Card: &Card{UID: "SECOND"}})
+				close(done)
+			}()
+
+			select {
+			case <-done:
+			case <-time.After(time.Second):
+				t.Fatal("sendData blocked on a full channel")
+			}
+
+			data := <-reader.Data()
+			if data.Card.UID != tt.wantUID {
+				t.Errorf("Queued event UID = %s, want %s", data.Card.UID, tt.wantUID)
+			}
+		})
+	}
+}
+
+// TestNFCReader_BufferSizes tests that ReaderOptions sizes the channels and
+// that a larger data buffer holds a burst of events before the drop policy applies.
+func TestNFCReader_BufferSizes(t *testing.T) {
+	reader, err := NewNFCReader("mock:usb:001", NewMockManager(), 5*time.Second)
+	if err != nil {
+		t.Fatalf("Failed to create NFCReader: %v", err)
+	}
+	if cap(reader.dataChan) != DefaultDataBufferSize || cap(reader.statusChan) != DefaultStatusBufferSize {
+		t.Errorf("Default capacities = %d/%d, want %d/%d", cap(reader.dataChan), cap(reader.statusChan), DefaultDataBufferSize, DefaultStatusBufferSize)
+	}
+	reader.Close()
+
+	reader, err = NewNFCReader("mock:usb:001", NewMockManager(), 5*time.Second, ReaderOptions{DataBufferSize: 3, StatusBufferSize: 8})
+	if err != nil {
+		t.Fatalf("Failed to create NFCReader: %v", err)
+	}
+	defer reader.Close()
+	if cap(reader.statusChan) != 8 {
+		t.Errorf("Status capacity = %d, want 8", cap(reader.statusChan))
+	}
+
+	for _, uid := range []string{"FIRST", "SECOND", "THIRD", "FOURTH"} {
+		reader.sendData(NFCData{Card: &Card{UID: uid}})
+	}
+
+	// The oldest event is dropped only once the buffer of 3 is full
+	for _, want := range []string{"SECOND", "THIRD", "FOURTH"} {
+		if data := <-reader.Data(); data.Card.UID != want {
+			t.Errorf("Queued event UID = %s, want %s", data.Card.UID, want)
+		}
+	}
+}
+
+func TestParseDataDropPolicy(t *testing.T) {
+	for _, policy := range []DataDropPolicy{DropOldest, DropNewest} {
+		got, err := ParseDataDropPolicy(policy.String())
+		if err != nil || got != policy {
+			t.Errorf("ParseDataDropPolicy(%q) = %v, %v; want %v", policy.String(), got, err, policy)
+		}
+	}
+	if _, err := ParseDataDropPolicy("latest"); err == nil {
+		t.Error("Expected error for unknown policy")
+	}
+}
+
+func TestParseBlankCardPolicy(t *testing.T) {
+	for _, policy := range []BlankCardPolicy{BlankCardsAnnounce, BlankCardsSuppress, BlankCardsFlag} {
+		got, err := ParseBlankCardPolicy(policy.String())
+		if err != nil || got != policy {
+			t.Errorf("ParseBlankCardPolicy(%q) = %v, %v; want %v", policy.String(), got, err, policy)
+		}
+	}
+	if _, err := ParseBlankCardPolicy("hide"); err == nil {
+		t.Error("Expected error for unknown policy")
+	}
+}
+
+// TestNFCReader_BlankCardPolicy tests that blank cards are announced, suppressed
+// or flagged, and that suppressed cards are still tracked by the cache.
+func TestNFCReader_BlankCardPolicy(t *testing.T) {
+	tests := []struct {
+		name      string
+		policy    BlankCardPolicy
+		data      []byte
+		wantData  bool
+		wantBlank bool
+	}{
+		{"announce blank", BlankCardsAnnounce, nil, true, false},
+		{"suppress blank", BlankCardsSuppress, nil, false, false},
+		{"suppress empty NDEF", BlankCardsSuppress, []byte{0xD0, 0x00, 0x00}, false, false},
+		{"flag blank", BlankCardsFlag, nil, true, true},
+		{"flag card with data", BlankCardsFlag, []byte("Hello"), true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := NewMockManager()
+			manager.DevicesList = []string{"mock:usb:001"}
+
+			mockTag := NewMockTag("04A1B2C3")
+			mockTag.IsConnected = true
+			mockTag.Data = tt.data
+
+			mockDevice := NewMockDevice()
+			mockDevice.SetTags([]Tag{mockTag})
+			manager.MockDevice = mockDevice
+
+			reader, err := NewNFCReader("mock:usb:001", manager, 5*time.Second)
+			if err != nil {
+				t.Fatalf("Failed to create NFCReader: %v", err)
+			}
+			defer reader.Close()
+			defer reader.Stop()
+
+			reader.SetBlankCardPolicy(tt.policy)
+			reader.Start()
+
+			select {
+			case data := <-reader.Data():
+				if !tt.wantData {
+					t.Fatalf("Expected blank card to be suppressed, got %+v", data)
+				}
+				if data.Card == nil || data.Card.UID != "04A1B2C3" {
+					t.Fatalf("Expected card 04A1B2C3, got %+v", data)
+				}
+				if data.Blank != tt.wantBlank {
+					t.Errorf("Blank = %v, want %v", data.Blank, tt.wantBlank)
+				}
+			case <-time.After(500 * time.Millisecond):
+				if tt.wantData {
+					t.Fatal("Timeout waiting for card data")
+				}
+				if got := reader.cache.GetLastScanned(); got != "04A1B2C3" {
+					t.Errorf("Expected suppressed card in cache, got %q", got)
+				}
+			}
+		})
+	}
+}
+
+// TestNFCReader_WaitForCard tests that WaitForCard resolves on a tap, on removal
+// mid-read and on timeout.
+func TestNFCReader_WaitForCard(t *testing.T) {
+	tests := []struct {
+		name    string
+		readErr error
+		wantErr error
+	}{
+		{"card read", nil, nil},
+		{"card removed during read", NewCardRemovedError(fmt.Errorf("transmit failed")), ErrCardRemovedDuringRead},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := NewMockManager()
+			manager.DevicesList = []string{"mock:usb:001"}
+
+			mockTag := NewMockTag("04A1B2C3")
+			mockTag.TagType = "MIFARE Classic 1K"
+			mockTag.IsConnected = true
+			mockTag.Data = EncodeNdefMessageWithTextRecord("Hello", "en")
+			mockTag.ReadDataError = tt.readErr
+
+			mockDevice := NewMockDevice()
+			mockDevice.SetTags([]Tag{mockTag})
+			manager.MockDevice = mockDevice
+
+			reader, err := NewNFCReader("mock:usb:001", manager, 5*time.Second)
+			if err != nil {
+				t.Fatalf("Failed to create NFCReader: %v", err)
+			}
+			defer reader.Close()
+			defer reader.Stop()
+
+			type result struct {
+				card *Card
+				err  error
+			}
+			done := make(chan result, 1)
+			go func() {
+				card, err := reader.WaitForCard(3 * time.Second)
+				done <- result{card, err}
+			}()
+			time.Sleep(20 * time.Millisecond)
+
+			reader.Start()
+
+			select {
+			case res := <-done:
+				if !errors.Is(res.err, tt.wantErr) {
+					t.Fatalf("WaitForCard() error = %v, want %v", res.err, tt.wantErr)
+				}
+				if tt.wantErr == nil && (res.card == nil || res.card.UID != "04A1B2C3") {
+					t.Errorf("WaitForCard() card = %v, want UID 04A1B2C3", res.card)
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatal("WaitForCard() did not resolve before its timeout")
+			}
+		})
+	}
+}
+
+// TestNFCReader_WaitForCardTimeout tests that WaitForCard gives up after the timeout.
+func TestNFCReader_WaitForCardTimeout(t *testing.T) {
+	reader, err := NewNFCReader("mock:usb:001", NewMockManager(), 5*time.Second)
+	if err != nil {
+		t.Fatalf("Failed to create NFCReader: %v", err)
+	}
+	defer reader.Close()
+
+	if _, err := reader.WaitForCard(50 * time.Millisecond); !errors.Is(err, ErrWaitTimeout) {
+		t.Errorf("WaitForCard() error = %v, want ErrWaitTimeout", err)
+	}
+
+	reader.waitMux.Lock()
+	pending := len(reader.cardWaiters)
+	reader.waitMux.Unlock()
+	if pending != 0 {
+		t.Errorf("Expected waiter to be removed after timeout, %d pending", pending)
+	}
+}
+
+// TestNFCReader_ReadManufacturerBlock tests decoding block 0 of a Classic card.
+func TestNFCReader_ReadManufacturerBlock(t *testing.T) {
+	manager := NewMockManager()
+	manager.DevicesList = []string{"mock:usb:001"}
+
+	mockTag := NewMockClassicTag("DEADBEEF")
+	mockTag.TagType = "MIFARE Classic 1K"
+	mockTag.IsConnected = true
+	mockTag.SetBlockData(0, 0, []byte{0xDE, 0xAD, 0xBE, 0xEF, 0x22, 0x08, 0x04, 0x00,
+		0x62, 0x63, 0x64, 0x65, 0x66, 0x67, 0x68, 0x69})
+
+	mockDevice := NewMockDevice()
+	mockDevice.SetTags([]Tag{mockTag})
+	manager.MockDevice = mockDevice
+
+	reader, err := NewNFCReader("mock:usb:001", manager, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Failed to create NFCReader: %v", err)
+	}
+	defer reader.Close()
+
+	time.Sleep(100 * time.Millisecond)
+
+	info, err := reader.ReadManufacturerBlock()
+	if err != nil {
+		t.Fatalf("ReadManufacturerBlock() failed: %v", err)
+	}
+	if fmt.Sprintf("%X", info.UID) != "DEADBEEF" {
+		t.Errorf("UID = %X, want DEADBEEF", info.UID)
+	}
+	if !info.BCCValid {
+		t.Error("Expected valid BCC")
+	}
+	if fmt.Sprintf("%X", info.SAK) != "08" || fmt.Sprintf("%X", info.ATQA) != "0400" {
+		t.Errorf("SAK/ATQA = %X/%X, want 08/0400", info.SAK, info.ATQA)
+	}
+}
+
+// TestNFCReader_WriteDuringCooldown tests that writes during a device cooldown
+// fail with ErrDeviceCooldown and the time left, not "no device connected".
+func TestNFCReader_WriteDuringCooldown(t *testing.T) {
+	manager := NewMockManager()
+	manager.DevicesList = []string{"mock:usb:001"}
+
+	clock := NewFakeClock(time.Now())
+	reader, err := NewNFCReaderWithClock("mock:usb:001", manager, 5*time.Second, clock)
+	if err != nil {
+		t.Fatalf("Failed to create NFCReader: %v", err)
+	}
+	defer reader.Close()
+
+	acr122Error := fmt.Errorf("%w: %w", ErrIO, ErrACR122Specific)
+	if !reader.deviceManager.HandleError(acr122Error, make(chan struct{})) {
+		t.Fatal("Expected the ACR122 error to start a cooldown")
+	}
+	clock.Advance(time.Second)
+
+	err = reader.WriteCardData("Hello")
+	if !errors.Is(err, ErrDeviceCooldown) {
+		t.Fatalf("Expected ErrDeviceCooldown, got %v", err)
+	}
+	var cooldown *CooldownError
+	if !errors.As(err, &cooldown) || cooldown.Remaining != DeviceErrorCooldownPeriod-time.Second {
+		t.Errorf("Expected %v remaining, got %+v", DeviceErrorCooldownPeriod-time.Second, cooldown)
+	}
+}
+
+// TestNFCReader_WriteWhileBusy tests that writes without a device fail at once
+// with ErrDeviceBusy, even while another operation holds the operation slot,
+// unless busy writes are queued.
+func TestNFCReader_WriteWhileBusy(t *testing.T) {
+	manager := NewMockManager()
+	manager.DevicesList = []string{"mock:usb:001"}
+	manager.OpenDeviceError = fmt.Errorf("device unplugged")
+
+	reader, err := NewNFCReader("mock:usb:001", manager, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Failed to create NFCReader: %v", err)
+	}
+	defer reader.Close()
+
+	// A stuck operation holds the slot
+	reader.operationMutex.Lock()
+
+	start := time.Now()
+	err = reader.WriteCardData("Hello")
+	if !errors.Is(err, ErrDeviceBusy) {
+		t.Fatalf("Expected ErrDeviceBusy, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Write took %v, expected it to fail fast", elapsed)
+	}
+	if err := reader.FormatNDEF(false); !errors.Is(err, ErrDeviceBusy) {
+		t.Errorf("FormatNDEF(): expected ErrDeviceBusy, got %v", err)
+	}
+
+	reader.SetQueueWritesWhileBusy(true)
+	done := make(chan error, 1)
+	go func() { done <- reader.WriteCardData("Hello") }()
+
+	select {
+	case err := <-done:
+		t.Fatalf("Queued write returned %v while the slot was held", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	reader.operationMutex.Unlock()
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrDeviceBusy) {
+			t.Errorf("Queued write: expected ErrDeviceBusy, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Queued write did not finish after the slot was released")
+	}
+}
+
+// TestNFCReader_ReadWithOptions tests that tags without sector-level reads
+// ignore BestEffort and are read normally.
+func TestNFCReader_ReadWithOptions(t *testing.T) {
+	manager := NewMockManager()
+	manager.DevicesList = []string{"mock:usb:001"}
+
+	mockTag := NewMockTag("04A1B2C3")
+	mockTag.TagType = "NTAG215"
+	mockTag.IsConnected = true
+	mockTag.Data = EncodeNdefMessageWithTextRecord("Hello", "en")
+
+	mockDevice := NewMockDevice()
+	mockDevice.SetTags([]Tag{mockTag})
+	manager.MockDevice = mockDevice
+
+	reader, err := NewNFCReader("mock:usb:001", manager, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Failed to create NFCReader: %v", err)
+	}
+	defer reader.Close()
+
+	time.Sleep(100 * time.Millisecond)
+
+	result, err := reader.ReadWithOptions(ReadOptions{BestEffort: true})
+	if err != nil {
+		t.Fatalf("ReadWithOptions() failed: %v", err)
+	}
+	if result.Partial() || result.Message == nil {
+		t.Fatalf("Expected a complete decoded read, got %+v", result)
+	}
+	if text, _ := result.Message.GetText(); text != "Hello" {
+		t.Errorf("Expected text Hello, got %q", text)
+	}
+}
+
+// TestNFCReader_ListTags tests that every tag in the field is listed, that an
+// empty field is not an error, and that single-card commands still refuse
+// several tags.
+func TestNFCReader_ListTags(t *testing.T) {
+	manager := NewMockManager()
+	manager.DevicesList = []string{"mock:usb:001"}
+
+	first := NewMockTag("04A1B2C3")
+	first.TagType = "NTAG215"
+	first.IsConnected = true
+	second := NewMockTag("DEADBEEF")
+	second.TagType = "MIFARE Classic 1K"
+	second.IsConnected = true
+
+	mockDevice := NewMockDevice()
+	mockDevice.SetTags([]Tag{first, second})
+	manager.MockDevice = mockDevice
+
+	reader, err := NewNFCReader("mock:usb:001", manager, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Failed to create NFCReader: %v", err)
+	}
+	defer reader.Close()
+
+	time.Sleep(100 * time.Millisecond)
+
+	tags, err := reader.ListTags()
+	if err != nil {
+		t.Fatalf("ListTags() failed: %v", err)
+	}
+	want := []CardInfo{{UID: "04A1B2C3", Type: "NTAG215"}, {UID: "DEADBEEF", Type: "MIFARE Classic 1K"}}
+	if !reflect.DeepEqual(tags, want) {
+		t.Errorf("ListTags() = %+v, want %+v", tags, want)
+	}
+
+	if _, err := reader.ReadCardInfo(); err == nil {
+		t.Error("Expected ReadCardInfo() to refuse two cards")
+	}
+
+	mockDevice.GetTagsError = &noCardError{}
+	tags, err = reader.ListTags()
+	if err != nil || len(tags) != 0 {
+		t.Errorf("ListTags() on an empty field = %+v, %v, want empty list", tags, err)
+	}
+}
+
+// TestNFCReader_PauseResume tests that a paused reader neither polls nor
+// broadcasts, that writes still work while paused without resuming polling,
+// and that Resume picks the card up again.
+func TestNFCReader_PauseResume(t *testing.T) {
+	manager := NewMockManager()
+	manager.DevicesList = []string{"mock:usb:001"}
+
+	mockTag := NewMockTag("04A1B2C3")
+	mockTag.TagType = "MIFARE Classic 1K"
+	mockTag.IsConnected = true
+	mockTag.Data = EncodeNdefMessageWithTextRecord("Hello", "en")
+
+	mockDevice := NewMockDevice()
+	mockDevice.SetTags([]Tag{mockTag})
+	manager.MockDevice = mockDevice
+
+	reader, err := NewNFCReader("mock:usb:001", manager, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Failed to create NFCReader: %v", err)
+	}
+	defer reader.Close()
+	defer reader.Stop()
+
+	reader.Pause()
+	reader.Start()
+
+	select {
+	case data := <-reader.Data():
+		t.Fatalf("Expected no tag data while paused, got %+v", data)
+	case <-time.After(5 * DefaultPollingInterval):
+	}
+
+	if err := reader.WriteCardData("Written while paused"); err != nil {
+		t.Fatalf("WriteCardData() while paused failed: %v", err)
+	}
+	if !reader.IsPaused() {
+		t.Error("Expected reader to stay paused after a write")
+	}
+
+	reader.Resume()
+
+	select {
+	case data := <-reader.Data():
+		if data.Card == nil || data.Card.UID != "04A1B2C3" {
+			t.Errorf("Expected card 04A1B2C3 after resume, got %+v", data)
+		}
+	case <-time.After(2 * time.Second):
+		t.Error("Timeout waiting for tag data after resume")
+	}
+}
+
+// TestNFCReader_ReadCardInfo tests that non-DESFire cards report only UID and type.
+func TestNFCReader_ReadCardInfo(t *testing.T) {
+	manager := NewMockManager()
+	manager.DevicesList = []string{"mock:usb:001"}
+
+	mockTag := NewMockTag("04A1B2C3")
+	mockTag.TagType = "NTAG215"
+	mockTag.IsConnected = true
+
+	mockDevice := NewMockDevice()
+	mockDevice.SetTags([]Tag{mockTag})
+	manager.MockDevice = mockDevice
+
+	reader, err := NewNFCReader("mock:usb:001", manager, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Failed to create NFCReader: %v", err)
+	}
+	defer reader.Close()
+
+	time.Sleep(100 * time.Millisecond)
+
+	info, err := reader.ReadCardInfo()
+	if err != nil {
+		t.Fatalf("ReadCardInfo() failed: %v", err)
+	}
+	if info.UID != "04A1B2C3" || info.Type != "NTAG215" || info.DESFire != nil {
+		t.Errorf("ReadCardInfo() = %+v", info)
+	}
+}
+
+// TestNFCReader_ReadCardInfoSignature tests that NTAG cards report their
+// originality signature, checked against the configured key.
+func TestNFCReader_ReadCardInfoSignature(t *testing.T) {
+	manager := NewMockManager()
+	manager.DevicesList = []string{"mock:usb:001"}
+
+	uid := []byte{0x04, 0xA1, 0xB2, 0xC3, 0xD4, 0xE5, 0xF6}
+	mockTag := NewMockNtagTag("04A1B2C3D4E5F6")
+	mockTag.IsConnected = true
+	mockTag.Signature = testSign(42, 4242, uid)
+
+	mockDevice := NewMockDevice()
+	mockDevice.SetTags([]Tag{mockTag})
+	manager.MockDevice = mockDevice
+
+	reader, err := NewNFCReader("mock:usb:001", manager, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Failed to create NFCReader: %v", err)
+	}
+	defer reader.Close()
+
+	time.Sleep(100 * time.Millisecond)
+
+	info, err := reader.ReadCardInfo()
+	if err != nil {
+		t.Fatalf("ReadCardInfo() failed: %v", err)
+	}
+	if !reflect.DeepEqual(info.Signature, mockTag.Signature) || info.Originality != "" {
+		t.Errorf("Without key: ReadCardInfo() = %+v", info)
+	}
+
+	key, _ := ParseSignatureKey(testSignatureKey(42))
+	reader.SetSignatureKey(key)
+	if info, _ := reader.ReadCardInfo(); info.Originality != OriginalityGenuine {
+		t.Errorf("Originality = %q, want genuine", info.Originality)
+	}
+
+	otherKey, _ := ParseSignatureKey(testSignatureKey(43))
+	reader.SetSignatureKey(otherKey)
+	if info, _ := reader.ReadCardInfo(); info.Originality != OriginalityUnknown {
+		t.Errorf("Originality = %q, want unknown", info.Originality)
+	}
+
+	mockTag.ReadSignatureError = fmt.Errorf("NAK")
+	info, err = reader.ReadCardInfo()
+	if err != nil {
+		t.Fatalf("ReadCardInfo() should tolerate READ_SIG failure: %v", err)
+	}
+	if info.Signature != nil || info.Originality != OriginalityUnknown {
+		t.Errorf("After READ_SIG failure: ReadCardInfo() = %+v", info)
+	}
+}
+
+// TestNFCReader_ReadCardInfoMagic tests that MIFARE Classic cards are only
+// probed for the gen1a backdoor when the probe is enabled.
+func TestNFCReader_ReadCardInfoMagic(t *testing.T) {
+	manager := NewMockManager()
+	manager.DevicesList = []string{"mock:usb:001"}
+
+	mockTag := NewMockClassicTag("DEADBEEF")
+	mockTag.TagType = CardTypeMifareClassic1K
+	mockTag.IsConnected = true
+	mockTag.Magic = MagicInfo{Magic: true, Block0Writable: true}
+
+	mockDevice := NewMockDevice()
+	mockDevice.SetTags([]Tag{mockTag})
+	manager.MockDevice = mockDevice
+
+	reader, err := NewNFCReader("mock:usb:001", manager, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Failed to create NFCReader: %v", err)
+	}
+	defer reader.Close()
+
+	time.Sleep(100 * time.Millisecond)
+
+	info, err := reader.ReadCardInfo()
+	if err != nil {
+		t.Fatalf("ReadCardInfo() failed: %v", err)
+	}
+	if info.Magic != nil {
+		t.Errorf("Probe disabled: Magic = %+v, want nil", info.Magic)
+	}
+
+	reader.SetMagicProbe(true)
+	info, err = reader.ReadCardInfo()
+	if err != nil {
+		t.Fatalf("ReadCardInfo() failed: %v", err)
+	}
+	if info.Magic == nil || *info.Magic != mockTag.Magic {
+		t.Errorf("Probe enabled: Magic = %+v, want %+v", info.Magic, mockTag.Magic)
+	}
+}
+
+// TestNFCReader_ReadCardInfoGPB tests that Classic cards report the GPB of
+// every sector, including the large sectors of 4K cards.
+func TestNFCReader_ReadCardInfoGPB(t *testing.T) {
+	manager := NewMockManager()
+	manager.DevicesList = []string{"mock:usb:001"}
+
+	mockTag := NewMockClassicTag("DEADBEEF")
+	mockTag.TagType = CardTypeMifareClassic4K
+	mockTag.IsConnected = true
+	mockTag.SetBlockData(0, 3, buildSectorTrailer(KeyMAD, madSectorAccess, madGPBv2, KeyDefault))
+	mockTag.SetBlockData(1, 3, buildSectorTrailer(KeyNFCForum, ndefSectorAccess, ndefSectorGPB, KeyDefault))
+	mockTag.SetBlockData(39, 15, buildSectorTrailer(KeyNFCForum, ndefSectorAccess, ndefSectorGPB, KeyDefault))
+
+	mockDevice := NewMockDevice()
+	mockDevice.SetTags([]Tag{mockTag})
+	manager.MockDevice = mockDevice
+
+	reader, err := NewNFCReader("mock:usb:001", manager, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Failed to create NFCReader: %v", err)
+	}
+	defer reader.Close()
+
+	info, err := reader.ReadCardInfo()
+	if err != nil {
+		t.Fatalf("ReadCardInfo() failed: %v", err)
+	}
+	if len(info.GPB) != 40 {
+		t.Fatalf("GPB has %d sectors, want 40", len(info.GPB))
+	}
+	if info.GPB[0] != madGPBv2 || info.GPB[1] != ndefSectorGPB || info.GPB[39] != ndefSectorGPB {
+		t.Errorf("GPB = %02X %02X ... %02X, want C2 40 ... 40", info.GPB[0], info.GPB[1], info.GPB[39])
+	}
+}
+
+// TestNFCReader_WriteRecordsWear tests that successful writes are counted per UID
+// and failed writes are not.
+func TestNFCReader_WriteRecordsWear(t *testing.T) {
+	manager := NewMockManager()
+	manager.DevicesList = []string{"mock:usb:001"}
+
+	mockTag := NewMockTag("04A1B2C3")
+	mockTag.TagType = "MIFARE Classic 1K"
+	mockTag.IsConnected = true
+	mockTag.Data = EncodeNdefMessageWithTextRecord("Hello", "en")
+
+	mockDevice := NewMockDevice()
+	mockDevice.SetTags([]Tag{mockTag})
+	manager.MockDevice = mockDevice
+
+	reader, err := NewNFCReader("mock:usb:001", manager, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Failed to create NFCReader: %v", err)
+	}
+	defer reader.Close()
+
+	tracker, err := NewWearTracker(nil)
+	if err != nil {
+		t.Fatalf("NewWearTracker() failed: %v", err)
+	}
+	reader.SetWearTracker(tracker)
+
+	time.Sleep(100 * time.Millisecond)
+
+	msg := (&NDEFMessageBuilder{
+		Records: []NDEFRecordBuilder{&NDEFText{Content: "Updated", Language: "en"}},
+	}).MustBuild()
+
+	for i := 0; i < 2; i++ {
+		if err := reader.WriteMessageWithOptions(msg, WriteOptions{Overwrite: true, Index: -1}); err != nil {
+			t.Fatalf("WriteMessageWithOptions() failed: %v", err)
+		}
+	}
+
+	mockTag.WriteDataError = fmt.Errorf("write failed")
+	_ = reader.WriteMessageWithOptions(msg, WriteOptions{Overwrite: true, Index: -1})
+
+	if stats := reader.WearStats("04a1b2c3"); stats.Writes != 2 {
+		t.Errorf("WearStats().Writes = %d, want 2", stats.Writes)
+	}
+}
+
+// TestNFCReader_ReadNDEFRange tests partial NDEF reads on a Type 4 tag.
+func TestNFCReader_ReadNDEFRange(t *testing.T) {
+	manager := NewMockManager()
+	manager.DevicesList = []string{"mock:usb:001"}
+
+	mockTag := NewMockISO14443Tag("04AABBCCDDEEFF")
+	mockTag.TagType = CardTypeType4
+	mockTag.IsConnected = true
+	mockTag.Data = []byte("0123456789")
+
+	mockDevice := NewMockDevice()
+	mockDevice.SetTags([]Tag{mockTag})
+	manager.MockDevice = mockDevice
+
+	reader, err := NewNFCReader("mock:usb:001", manager, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Failed to create NFCReader: %v", err)
+	}
+	defer reader.Close()
+
+	time.Sleep(100 * time.Millisecond)
+
+	tests := []struct {
+		name   string
+		offset int
+		length int
+		want   string
+	}{
+		{"start", 0, 4, "0123"},
+		{"middle", 3, 4, "3456"},
+		{"truncated at end", 8, 256, "89"},
+		{"at end", 10, 4, ""},
+		{"past end", 500, 4, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := reader.ReadNDEFRange(tt.offset, tt.length)
+			if err != nil {
+				t.Fatalf("ReadNDEFRange(%d, %d) failed: %v", tt.offset, tt.length, err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("ReadNDEFRange(%d, %d) = %q, want %q", tt.offset, tt.length, data, tt.want)
+			}
+		})
+	}
+}
+
+// TestNFCReader_ReadNDEFRange_NotSupported tests partial reads on tags without range support.
+func TestNFCReader_ReadNDEFRange_NotSupported(t *testing.T) {
+	manager := NewMockManager()
+	manager.DevicesList = []string{"mock:usb:001"}
+
+	mockTag := NewMockClassicTag("DEADBEEF")
+	mockTag.IsConnected = true
+
+	mockDevice := NewMockDevice()
+	mockDevice.SetTags([]Tag{mockTag})
+	manager.MockDevice = mockDevice
+
+	reader, err := NewNFCReader("mock:usb:001", manager, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Failed to create NFCReader: %v", err)
+	}
+	defer reader.Close()
+
+	time.Sleep(100 * time.Millisecond)
+
+	if _, err := reader.ReadNDEFRange(0, 16); !IsNotSupportedError(err) {
+		t.Errorf("ReadNDEFRange() error = %v, want not-supported error", err)
+	}
+}
+
+// TestNFCReader_RawPages tests raw page reads and writes on a Type 2 tag.
+func TestNFCReader_RawPages(t *testing.T) {
+	manager := NewMockManager()
+	manager.DevicesList = []string{"mock:usb:001"}
+
+	mockTag := NewMockNtagTag("04112233445566")
+	mockTag.IsConnected = true
+	mockTag.SetPageData(5, [4]byte{0xCA, 0xFE, 0xBA, 0xBE})
+
+	mockDevice := NewMockDevice()
+	mockDevice.SetTags([]Tag{mockTag})
+	manager.MockDevice = mockDevice
+
+	reader, err := NewNFCReader("mock:usb:001", manager, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Failed to create NFCReader: %v", err)
+	}
+	defer reader.Close()
+
+	tracker, err := NewWearTracker(nil)
+	if err != nil {
+		t.Fatalf("NewWearTracker() failed: %v", err)
+	}
+	reader.SetWearTracker(tracker)
+
+	time.Sleep(100 * time.Millisecond)
+
+	if err := reader.WritePage(6, [4]byte{0x01, 0x02, 0x03, 0x04}); err != nil {
+		t.Fatalf("WritePage() failed: %v", err)
+	}
+	if stats := reader.WearStats("04112233445566"); stats.Writes != 1 {
+		t.Errorf("WearStats().Writes after WritePage = %d, want 1", stats.Writes)
+	}
+
+	data, err := reader.ReadPages(5, 2)
+	if err != nil {
+		t.Fatalf("ReadPages() failed: %v", err)
+	}
+	if got := fmt.Sprintf("%X", data); got != "CAFEBABE01020304" {
+		t.Errorf("ReadPages() = %s, want CAFEBABE01020304", got)
+	}
+
+	if _, err := reader.ReadPages(134, 2); !errors.Is(err, ErrPageOutOfRange) {
+		t.Errorf("ReadPages() past end error = %v, want ErrPageOutOfRange", err)
+	}
+}
+
+// TestNFCReader_RawPages_NotSupported tests raw page access on a non-Type 2 tag.
+func TestNFCReader_RawPages_NotSupported(t *testing.T) {
+	manager := NewMockManager()
+	manager.DevicesList = []string{"mock:usb:001"}
+
+	mockTag := NewMockClassicTag("DEADBEEF")
+	mockTag.IsConnected = true
+
+	mockDevice := NewMockDevice()
+	mockDevice.SetTags([]Tag{mockTag})
+	manager.MockDevice = mockDevice
+
+	reader, err := NewNFCReader("mock:usb:001", manager, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Failed to create NFCReader: %v", err)
+	}
+	defer reader.Close()
+
+	time.Sleep(100 * time.Millisecond)
+
+	if _, err := reader.ReadPages(4, 1); !IsNotSupportedError(err) {
+		t.Errorf("ReadPages() error = %v, want not-supported error", err)
+	}
+}
+
+// TestNFCReader_FormatNDEF_NotSupported tests formatting tags without NDEFFormatter.
+func TestNFCReader_FormatNDEF_NotSupported(t *testing.T) {
+	manager := NewMockManager()
+	manager.DevicesList = []string{"mock:usb:001"}
+
+	mockTag := NewMockTag("04A1B2C3")
+	mockTag.IsConnected = true
+
+	mockDevice := NewMockDevice()
+	mockDevice.SetTags([]Tag{mockTag})
+	manager.MockDevice = mockDevice
+
+	reader, err := NewNFCReader("mock:usb:001", manager, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Failed to create NFCReader: %v", err)
+	}
+	defer reader.Close()
+
+	time.Sleep(100 * time.Millisecond)
+
+	if err := reader.FormatNDEF(true); !IsNotSupportedError(err) {
+		t.Errorf("Expected not supported error, got: %v", err)
+	}
+}
+
+// TestNFCReader_ClearCache tests recovering from a cache poisoned with a UID
+// that no longer matches the card, and that clearing is refused mid-operation.
+func TestNFCReader_ClearCache(t *testing.T) {
+	manager := NewMockManager()
+	manager.DevicesList = []string{"mock:usb:001"}
+
+	mockTag := NewMockTag("04A1B2C3")
+	mockTag.TagType = "MIFARE Classic 1K"
+	mockTag.IsConnected = true
+
+	mockDevice := NewMockDevice()
+	mockDevice.SetTags([]Tag{mockTag})
+	manager.MockDevice = mockDevice
+
+	reader, err := NewNFCReader("mock:usb:001", manager, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Failed to create NFCReader: %v", err)
+	}
+	defer reader.Close()
+
+	time.Sleep(100 * time.Millisecond)
+
+	reader.cache.HasChanged("DEADBEEF")
+	if err := reader.WriteCardData("Hello"); err == nil {
+		t.Fatal("Expected UID mismatch with a poisoned cache")
+	}
+
+	// A running tag operation must not have the cache cleared under it
+	reader.operationMutex.Lock()
+	err = reader.ClearCache()
+	reader.operationMutex.Unlock()
+	if !errors.Is(err, ErrOperationInProgress) {
+		t.Fatalf("ClearCache() during operation = %v, want ErrOperationInProgress", err)
+	}
+
+	if err := reader.ClearCache(); err != nil {
+		t.Fatalf("ClearCache() failed: %v", err)
+	}
+	if uid := reader.GetLastScannedData(); uid != "" {
+		t.Errorf("Expected empty cache, got UID %s", uid)
+	}
+	if err := reader.WriteCardData("Hello"); err != nil {
+		t.Errorf("Write after ClearCache() failed: %v", err)
+	}
+}
+
+// TestNFCReader_LockAfterWrite tests that a card is only locked once the write
+// has been read back and verified.
+func TestNFCReader_LockAfterWrite(t *testing.T) {
+	tests := []struct {
+		name       string
+		setup      func(tag *MockTag)
+		wantErr    bool
+		wantVerify bool
+		wantLocked bool
+	}{
+		{
+			name:       "verified write is locked",
+			wantLocked: true,
+		},
+		{
+			name:    "failed write is not locked",
+			setup:   func(tag *MockTag) { tag.WriteDataError = fmt.Errorf("write failed") },
+			wantErr: true,
+		},
+		{
+			name: "mismatched read back is not locked",
+			setup: func(tag *MockTag) {
+				// The tag acknowledges the write but keeps its old data
+				tag.WriteDataFunc = func([]byte) error { return nil }
+			},
+			wantErr:    true,
+			wantVerify: true,
+		},
+		{
+			name:    "unlockable card is not written",
+			setup:   func(tag *MockTag) { tag.CanMakeReadOnlyFunc = func() (bool, error) { return false, nil } },
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := NewMockManager()
+			manager.DevicesList = []string{"mock:usb:001"}
+
+			mockTag := NewMockTag("04A1B2C3")
+			mockTag.TagType = "NTAG215"
+			mockTag.IsConnected = true
+			if tt.setup != nil {
+				tt.setup(mockTag)
+			}
+
+			mockDevice := NewMockDevice()
+			mockDevice.SetTags([]Tag{mockTag})
+			manager.MockDevice = mockDevice
+
+			reader, err := NewNFCReader("mock:usb:001", manager, 5*time.Second)
+			if err != nil {
+				t.Fatalf("Failed to create NFCReader: %v", err)
+			}
+			defer reader.Close()
+
+			time.Sleep(100 * time.Millisecond)
+
+			msg := (&NDEFMessageBuilder{
+				Records: []NDEFRecordBuilder{&NDEFText{Content: "Sealed", Language: "en"}},
+			}).MustBuild()
+			err = reader.WriteMessageWithOptions(msg, WriteOptions{Overwrite: true, Index: -1, LockAfterWrite: true})
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("WriteMessageWithOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if errors.Is(err, ErrVerifyFailed) != tt.wantVerify {
+				t.Errorf("Expected ErrVerifyFailed = %v, got %v", tt.wantVerify, err)
+			}
+			if mockTag.IsReadOnly != tt.wantLocked {
+				t.Errorf("Expected locked = %v, got %v", tt.wantLocked, mockTag.IsReadOnly)
+			}
+		})
+	}
+}
+
+// TestNFCReader_AllowedCardTypes tests that polling skips reading disallowed
+// tag types and reports each such card once.
+func TestNFCReader_AllowedCardTypes(t *testing.T) {
+	reader, err := NewNFCReader("mock:usb:001", NewMockManager(), 5*time.Second, ReaderOptions{DataBufferSize: 4})
+	if err != nil {
+		t.Fatalf("Failed to create NFCReader: %v", err)
+	}
+	defer reader.Close()
+
+	reader.SetAllowedCardTypes(map[string]bool{CardTypeNtag213: true, CardTypeDesfire: false})
+
+	classic := NewMockTag("CLASSIC01")
+	classic.TagType = CardTypeMifareClassic1K
+	classic.IsConnected = true
+
+	reader.handleTagPolling([]Tag{classic})
+	reader.handleTagPolling([]Tag{classic})
+
+	for _, call := range classic.GetCallLog() {
+		if call == "ReadData" {
+			t.Fatal("Disallowed tag should not be read")
+		}
+	}
+
+	data := <-reader.Data()
+	if !errors.Is(data.Err, ErrCardTypeNotAllowed) || data.Card != nil {
+		t.Errorf("Expected ErrCardTypeNotAllowed without a card, got %+v", data)
+	}
+	if !strings.Contains(data.Err.Error(), CardTypeMifareClassic1K) {
+		t.Errorf("Expected the card type in the error, got %v", data.Err)
+	}
+	select {
+	case data := <-reader.Data():
+		t.Errorf("Disallowed card should be reported once, got another event %+v", data)
+	default:
+	}
+
+	// An allowed type is read as usual
+	ntag := NewMockTag("NTAG01")
+	ntag.TagType = CardTypeNtag213
+	ntag.IsConnected = true
+	reader.handleTagPolling([]Tag{ntag})
+	if data := <-reader.Data(); data.Card == nil || data.Card.UID != "NTAG01" {
+		t.Errorf("Expected the NTAG213 to be read, got %+v", data)
+	}
+
+	// Clearing the allowlist allows every type
+	reader.SetAllowedCardTypes(nil)
+	if !reader.isCardTypeAllowed(CardTypeMifareClassic1K) {
+		t.Error("Empty allowlist should allow every type")
+	}
+}
+
+// TestNFCReader_TagGoneBeforeRead tests that a tag listed by GetTags but gone
+// by the time it is read is handled as a removal, not a read error.
+func TestNFCReader_TagGoneBeforeRead(t *testing.T) {
+	manager := NewMockManager()
+	manager.DevicesList = []string{"mock:usb:001"}
+
+	mockTag := NewMockTag("04A1B2C3")
+	mockTag.IsConnected = true
+	mockTag.ReadDataError = fmt.Errorf("transceive failed")
+
+	mockDevice := NewMockDevice()
+	mockDevice.CardAbsent = true
+	mockDevice.SetTags([]Tag{mockTag})
+	manager.MockDevice = mockDevice
+
+	reader, err := NewNFCReader("mock:usb:001", manager, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Failed to create NFCReader: %v", err)
+	}
+	defer reader.Close()
+	defer reader.Stop()
+
+	reader.Start()
+
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case data := <-reader.Data():
+			t.Fatalf("Expected no data for a removed card, got %+v", data)
+		case status := <-reader.StatusUpdates():
+			if status.Message != "Card removed, waiting for new card" {
+				continue
+			}
+			if status.CardPresent {
+				t.Error("Expected CardPresent to be false after removal")
+			}
+			return
+		case <-timeout:
+			t.Fatal("Timeout waiting for card removal status")
+		}
+	}
+}
+
+// TestNFCReader_RemovalGrace tests that a card returning within the removal
+// grace window is neither removed nor announced again, while another card or
+// an expired window completes the removal.
+func TestNFCReader_RemovalGrace(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	reader, err := NewNFCReaderWithClock("mock:usb:001", NewMockManager(), 5*time.Second, clock, ReaderOptions{DataBufferSize: 4, StatusBufferSize: 16})
+	if err != nil {
+		t.Fatalf("Failed to create NFCReader: %v", err)
+	}
+	defer reader.Close()
+	reader.SetRemovalGrace(2 * time.Second)
+
+	statuses := func() []string {
+		var messages []string
+		for {
+			select {
+			case status := <-reader.StatusUpdates():
+				messages = append(messages, status.Message)
+			default:
+				return messages
+			}
+		}
+	}
+	removed := NewCardRemovedError(fmt.Errorf("card removed during read"))
+
+	card := NewMockTag("04A1B2C3")
+	card.IsConnected = true
+	reader.handleTagPolling([]Tag{card})
+	if data := <-reader.Data(); data.Card == nil || data.Card.UID != "04A1B2C3" {
+		t.Fatalf("Expected the card to be announced, got %+v", data)
+	}
+	reader.setCardPresent(true)
+	statuses()
+
+	// The same card back within the window is a glitch
+	reader.handleDeviceErrors(removed)
+	clock.Advance(time.Second)
+	reader.handleTagPolling([]Tag{card})
+	select {
+	case data := <-reader.Data():
+		t.Errorf("Returning card should not be announced again, got %+v", data)
+	default:
+	}
+	if got := statuses(); slices.Contains(got, "Card removed") || slices.Contains(got, "Card removed, waiting for new card") {
+		t.Errorf("Statuses = %q, want no removal", got)
+	}
+	if !reader.readCardPresent() || reader.cache.GetLastScanned() != "04A1B2C3" {
+		t.Error("Expected the card to stay present and cached")
+	}
+
+	// A different card completes the removal and is announced
+	reader.handleDeviceErrors(removed)
+	other := NewMockTag("04D5E6F7")
+	other.IsConnected = true
+	reader.handleTagPolling([]Tag{other})
+	if data := <-reader.Data(); data.Card == nil || data.Card.UID != "04D5E6F7" {
+		t.Errorf("Expected the other card to be announced, got %+v", data)
+	}
+	if got := statuses(); !slices.Contains(got, "Card removed") {
+		t.Errorf("Statuses = %q, want the first card removed", got)
+	}
+
+	// A card that does not come back is removed once the window has passed
+	reader.setCardPresent(true)
+	if !reader.holdCardRemoval() {
+		t.Fatal("Expected the removal to be held at first")
+	}
+	clock.Advance(2 * time.Second)
+	if reader.holdCardRemoval() {
+		t.Error("Expected the removal to be reported after the window")
+	}
+}
+
+// TestNFCReader_LatencyStats tests that polling reads and writes are timed.
+func TestNFCReader_LatencyStats(t *testing.T) {
+	manager := NewMockManager()
+	manager.DevicesList = []string{"mock:usb:001"}
+
+	mockTag := NewMockTag("04A1B2C3")
+	mockTag.IsConnected = true
+	mockTag.Data = EncodeNdefMessageWithTextRecord("Hello", "en")
+
+	mockDevice := NewMockDevice()
+	mockDevice.SetTags([]Tag{mockTag})
+	manager.MockDevice = mockDevice
+
+	reader, err := NewNFCReader("mock:usb:001", manager, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Failed to create NFCReader: %v", err)
+	}
+	defer reader.Close()
+	defer reader.Stop()
+
+	reader.Start()
+	select {
+	case <-reader.Data():
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timeout waiting for tag data")
+	}
+	if err := reader.WriteCardData("World"); err != nil {
+		t.Fatalf("WriteCardData() error = %v", err)
+	}
+
+	stats := reader.LatencyStats()
+	if stats[LatencyRead].Count == 0 {
+		t.Error("Expected polling reads to be recorded")
+	}
+	if stats[LatencyWrite].Count != 1 {
+		t.Errorf("Expected 1 write, got %d", stats[LatencyWrite].Count)
+	}
+}
+
+// TestNFCReader_ReadRecord tests that a record read selects the application and
+// files in order and stops at the first failing selection.
+func TestNFCReader_ReadRecord(t *testing.T) {
+	manager := NewMockManager()
+	manager.DevicesList = []string{"mock:usb:001"}
+
+	mockTag := NewMockISO14443Tag("04A1B2C3D4E5F6")
+	mockTag.IsConnected = true
+	mockTag.Applications = map[string]bool{"A0000002471001": true}
+	mockTag.Files = map[string]bool{"011E": true}
+	mockTag.Records = map[byte][][]byte{1: {{0x61, 0x62}}}
+
+	mockDevice := NewMockDevice()
+	mockDevice.SetTags([]Tag{mockTag})
+	manager.MockDevice = mockDevice
+
+	reader, err := NewNFCReader("mock:usb:001", manager, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Failed to create NFCReader: %v", err)
+	}
+	defer reader.Close()
+
+	aid := []byte{0xA0, 0x00, 0x00, 0x02, 0x47, 0x10, 0x01}
+	result, err := reader.ReadRecord(RecordRead{AID: aid, FIDs: [][]byte{{0x01, 0x1E}}, Record: 1, SFI: 1})
+	if err != nil {
+		t.Fatalf("ReadRecord() failed: %v", err)
+	}
+	if result.Step != RecordStepReadRecord || !result.Response.IsSuccess() || string(result.Response.Data) != "ab" {
+		t.Errorf("ReadRecord() = %+v, want record data from readRecord", result)
+	}
+
+	result, err = reader.ReadRecord(RecordRead{AID: aid, FIDs: [][]byte{{0x01, 0x1F}}, Record: 1, SFI: 1})
+	if err != nil {
+		t.Fatalf("ReadRecord() failed: %v", err)
+	}
+	if result.Step != RecordStepSelectFID || result.Response.StatusWord() != 0x6A82 {
+		t.Errorf("ReadRecord() = %+v, want 6A82 from selectFID", result)
+	}
+	if got := mockTag.CallLog[len(mockTag.CallLog)-1]; got != "SelectByFID(011F)" {
+		t.Errorf("Expected no record read after a failed selection, last call %q", got)
+	}
+
+	// Tags without ISO 7816 file access are not supported
+	mockDevice.SetTags([]Tag{NewMockTag("04A1B2C3")})
+	if _, err := reader.ReadRecord(RecordRead{Record: 1}); !IsNotSupportedError(err) {
+		t.Errorf("Expected not supported error, got %v", err)
+	}
+}
+
+// TestNFCReader_WaitIdle tests that WaitIdle waits for a running tag operation
+// and gives up when its context ends.
+func TestNFCReader_WaitIdle(t *testing.T) {
+	manager := NewMockManager()
+	manager.DevicesList = []string{"mock:usb:001"}
+	manager.MockDevice = NewMockDevice()
+
+	reader, err := NewNFCReader("mock:usb:001", manager, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Failed to create NFCReader: %v", err)
+	}
+	defer reader.Close()
+
+	if err := reader.WaitIdle(context.Background()); err != nil {
+		t.Fatalf("WaitIdle() on an idle reader = %v", err)
+	}
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	go reader.withTagOperation(func() error {
+		close(started)
+		<-release
+		return nil
+	})
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := reader.WaitIdle(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("WaitIdle() during an operation = %v, want deadline exceeded", err)
+	}
+
+	close(release)
+	ctx, cancel = context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := reader.WaitIdle(ctx); err != nil {
+		t.Errorf("WaitIdle() after the operation = %v", err)
+	}
+}
+
+// TestNFCReader_PauseWhileIdle tests that an explicit pause outlasts the
+// reader going idle and back.
+func TestNFCReader_PauseWhileIdle(t *testing.T) {
+	manager := NewMockManager()
+	manager.DevicesList = []string{"mock:usb:001"}
+
+	mockTag := NewMockTag("04A1B2C3")
+	mockTag.IsConnected = true
+	mockTag.Data = EncodeNdefMessageWithTextRecord("Hello", "en")
+
+	mockDevice := NewMockDevice()
+	mockDevice.SetTags([]Tag{mockTag})
+	manager.MockDevice = mockDevice
+
+	reader, err := NewNFCReader("mock:usb:001", manager, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Failed to create NFCReader: %v", err)
+	}
+	defer reader.Close()
+	defer reader.Stop()
+
+	reader.SetIdle(true)
+	reader.Pause()
+	reader.Start()
+	reader.SetIdle(false)
+
+	select {
+	case data := <-reader.Data():
+		t.Fatalf("Expected no tag data while paused, got %+v", data)
+	case <-time.After(5 * DefaultPollingInterval):
+	}
+
+	reader.SetIdle(true)
+	reader.Resume()
+
+	select {
+	case data := <-reader.Data():
+		t.Fatalf("Expected no tag data while idle, got %+v", data)
+	case <-time.After(5 * DefaultPollingInterval):
+	}
+
+	reader.SetIdle(false)
+
+	select {
+	case data := <-reader.Data():
+		if data.Card == nil || data.Card.UID != "04A1B2C3" {
+			t.Errorf("Expected card 04A1B2C3 once neither paused nor idle, got %+v", data)
+		}
+	case <-time.After(2 * time.Second):
+		t.Error("Timeout waiting for tag data")
+	}
+}
+
+// TestNFCReader_RetapCooldown tests that a card removed and tapped again is
+// only announced again once the cooldown has passed, while other cards are
+// announced at once
+func TestNFCReader_RetapCooldown(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	reader, err := NewNFCReaderWithClock("mock:usb:001", NewMockManager(), 5*time.Second, clock, ReaderOptions{DataBufferSize: 4})
+	if err != nil {
+		t.Fatalf("Failed to create NFCReader: %v", err)
+	}
+	defer reader.Close()
+	reader.SetRetapCooldown(5 * time.Second)
+
+	announced := func() []string {
+		var uids []string
+		for {
+			select {
+			case data := <-reader.Data():
+				uids = append(uids, data.Card.UID)
+			default:
+				return uids
+			}
+		}
+	}
+	tap := func(tag *MockTag) {
+		reader.handleTagPolling([]Tag{tag})
+		reader.setCardPresent(true)
+		reader.setCardPresent(false) // Clears the cache, as a removal does
+	}
+
+	card := NewMockTag("04A1B2C3")
+	card.IsConnected = true
+	other := NewMockTag("04D5E6F7")
+	other.IsConnected = true
+
+	tap(card)
+	clock.Advance(2 * time.Second)
+	tap(card)
+	tap(other)
+	if got := announced(); !slices.Equal(got, []string{"04A1B2C3", "04D5E6F7"}) {
+		t.Errorf("Announced %q, want the re-tap suppressed", got)
+	}
+
+	// The window counts from the announcement, not from the suppressed re-tap
+	clock.Advance(3 * time.Second)
+	tap(card)
+	if got := announced(); !slices.Equal(got, []string{"04A1B2C3"}) {
+		t.Errorf("Announced %q after the cooldown, want the card again", got)
+	}
+
+	reader.SetRetapCooldown(0)
+	tap(card)
+	if got := announced(); !slices.Equal(got, []string{"04A1B2C3"}) {
+		t.Errorf("Announced %q without a cooldown, want every tap", got)
+	}
+}
+
+// TestNFCReader_Rescan tests that a rescan reads changed contents of the card
+// already announced and announces it again
+func TestNFCReader_Rescan(t *testing.T) {
+	manager := NewMockManager()
+	manager.DevicesList = []string{"mock:usb:001"}
+
+	mockTag := NewMockTag("04A1B2C3")
+	mockTag.IsConnected = true
+	mockTag.Data = EncodeNdefMessageWithTextRecord("Before", "en")
+
+	mockDevice := NewMockDevice()
+	mockDevice.SetTags([]Tag{mockTag})
+	manager.MockDevice = mockDevice
+
+	reader, err := NewNFCReader("mock:usb:001", manager, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Failed to create NFCReader: %v", err)
+	}
+	defer reader.Close()
+	defer reader.Stop()
+	reader.Start()
+
+	select {
+	case <-reader.Data():
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the card to be announced")
+	}
+
+	// Another terminal rewrites the card while it stays in the field
+	if err := mockTag.WriteData(EncodeNdefMessageWithTextRecord("After", "en")); err != nil {
+		t.Fatalf("WriteData() failed: %v", err)
+	}
+	select {
+	case data := <-reader.Data():
+		t.Fatalf("Expected the unchanged UID not to be announced again, got %+v", data)
+	case <-time.After(5 * DefaultPollingInterval):
+	}
+
+	card, err := reader.Rescan()
+	if err != nil {
+		t.Fatalf("Rescan() failed: %v", err)
+	}
+	msg, _ := card.ReadMessage()
+	if ndef, ok := msg.(*NDEFMessage); !ok {
+		t.Fatalf("Rescan() message = %T, want *NDEFMessage", msg)
+	} else if text, _ := ndef.GetText(); text != "After" {
+		t.Errorf("Rescan() text = %q, want After", text)
+	}
+
+	select {
+	case data := <-reader.Data():
+		if data.Card == nil || data.Card.UID != "04A1B2C3" {
+			t.Errorf("Expected the rescanned card to be announced, got %+v", data)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the rescanned card to be announced")
+	}
+}
+
+// TestNFCReader_PartialUpdateAcrossFamilies tests that appending and replacing
+// a record read the existing message through each tag family's adapter and
+// write the merged message back: MIFARE Classic, Type 2 and Type 4.
+func TestNFCReader_PartialUpdateAcrossFamilies(t *testing.T) {
+	families := []struct {
+		name   string
+		newTag func() Tag
+	}{
+		{"MIFARE Classic", func() Tag {
+			card := newMockScardCard()
+			card.classicMem = make([]byte, 64*16)
+			return newPCSCClassicTag(newMockPCSCDevice(card, pcscATR(0x01)), "04A1B2C3", DetectedClassic1K)
+		}},
+		{"Type 2", func() Tag {
+			tag, _ := newBlankType2Tag(DetectedNTAG215, 135)
+			return tag.(Tag)
+		}},
+		{"Type 4", func() Tag {
+			card := newMockScardCard()
+			card.type4File = make([]byte, 0xFF)
+			return newPCSCISO14443Tag(newMockPCSCDevice(card, unknownATR), "04112233445566")
+		}},
+	}
+
+	texts := func(t *testing.T, tag Tag) []string {
+		t.Helper()
+		data, err := tag.ReadData()
+		if err != nil {
+			t.Fatalf("ReadData() failed: %v", err)
+		}
+		records, err := parseNDEFRecords(data)
+		if err != nil {
+			t.Fatalf("Failed to parse written NDEF: %v", err)
+		}
+		var got []string
+		for _, record := range records {
+			text, _ := record.GetText()
+			got = append(got, text)
+		}
+		return got
+	}
+
+	for _, family := range families {
+		t.Run(family.name, func(t *testing.T) {
+			tag := family.newTag()
+			if err := tag.WriteData(EncodeNdefMessageWithTextRecord("First", "en")); err != nil {
+				t.Fatalf("Initial WriteData() failed: %v", err)
+			}
+
+			manager := NewMockManager()
+			manager.DevicesList = []string{"mock:usb:001"}
+			mockDevice := NewMockDevice()
+			mockDevice.SetTags([]Tag{tag})
+			manager.MockDevice = mockDevice
+
+			reader, err := NewNFCReader("mock:usb:001", manager, 5*time.Second)
+			if err != nil {
+				t.Fatalf("Failed to create NFCReader: %v", err)
+			}
+			defer reader.Close()
+
+			appended := NewNDEFMessage().AddText("Second", "en")
+			if err := reader.WriteMessageWithOptions(appended, WriteOptions{Index: -1}); err != nil {
+				t.Fatalf("Append failed: %v", err)
+			}
+			if got := texts(t, tag); !reflect.DeepEqual(got, []string{"First", "Second"}) {
+				t.Fatalf("Records after append = %q, want [First Second]", got)
+			}
+
+			replacement := NewNDEFMessage().AddText("Replaced", "en")
+			if err := reader.WriteMessageWithOptions(replacement, WriteOptions{Index: 0}); err != nil {
+				t.Fatalf("Replace failed: %v", err)
+			}
+			if got := texts(t, tag); !reflect.DeepEqual(got, []string{"Replaced", "Second"}) {
+				t.Errorf("Records after replace = %q, want [Replaced Second]", got)
+			}
+		})
+	}
+}
+
+// TestMergeNDEF_RecordLimit tests that an append past MaxNDEFRecords is an
+// error rather than a panic, while a replacement at the limit is fine
+func TestMergeNDEF_RecordLimit(t *testing.T) {
+	SetMaxNDEFRecords(2)
+	defer SetMaxNDEFRecords(0)
+
+	current := NewNDEFMessage().AddText("a", "en").AddText("b", "en")
+	added := NewNDEFMessage().AddText("c", "en")
+
+	if _, err := mergeNDEF(current, added, -1); !errors.Is(err, ErrTooManyRecords) {
+		t.Errorf("mergeNDEF() append error = %v, want ErrTooManyRecords", err)
+	}
+	merged, err := mergeNDEF(current, added, 1)
+	if err != nil {
+		t.Fatalf("mergeNDEF() replace failed: %v", err)
+	}
+	if text, _ := merged.Records()[1].GetText(); text != "c" || len(merged.Records()) != 2 {
+		t.Errorf("mergeNDEF() replace = %v, want [a c]", merged.Records())
+	}
+}
+
+// TestNFCReader_RefreshOnWriteRemoval tests that a write failing because the
+// card left reconnects the device and announces the next card at once, with
+// no poll loop running
+func TestNFCReader_RefreshOnWriteRemoval(t *testing.T) {
+	manager := NewMockManager()
+	manager.DevicesList = []string{"mock:usb:001"}
+
+	nextTag := NewMockTag("04D5E6F7")
+	nextTag.IsConnected = true
+	nextTag.Data = EncodeNdefMessageWithTextRecord("Next", "en")
+
+	mockDevice := NewMockDevice()
+	pulledTag := NewMockTag("04A1B2C3")
+	pulledTag.IsConnected = true
+	pulledTag.WriteDataFunc = func([]byte) error {
+		// The card is swapped for another while the write runs
+		mockDevice.SetTags([]Tag{nextTag})
+		return NewCardRemovedError(errors.New("card pulled"))
+	}
+	mockDevice.SetTags([]Tag{pulledTag})
+	manager.MockDevice = mockDevice
+
+	reader, err := NewNFCReader("mock:usb:001", manager, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Failed to create NFCReader: %v", err)
+	}
+	defer reader.Close()
+	reader.SetRefreshOnWriteRemoval(true)
+	manager.ClearCallLog()
+
+	err = reader.WriteMessageWithOptions(NewNDEFMessage().AddText("Hi", "en"), WriteOptions{Overwrite: true, Index: -1})
+	if !IsCardRemovedError(err) {
+		t.Fatalf("Expected a card removed error, got %v", err)
+	}
+	if !slices.Contains(manager.GetCallLog(), "OpenDevice(mock:usb:001)") {
+		t.Errorf("Expected the device to be reopened, calls: %v", manager.GetCallLog())
+	}
+
+	select {
+	case data := <-reader.Data():
+		if data.Card == nil || data.Card.UID != "04D5E6F7" {
+			t.Errorf("Expected the next card to be announced, got %+v", data)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the next card to be announced without a poll loop")
+	}
+}
+
+// TestNFCReader_UnsupportedCardEvent tests that an unsupported tag error from
+// the UnsupportedTagEvent policy is announced as an UnsupportedCard, while
+// the default error only updates the status.
+func TestNFCReader_UnsupportedCardEvent(t *testing.T) {
+	reader, err := NewNFCReader("mock:usb:001", NewMockManager(), 5*time.Second, ReaderOptions{DataBufferSize: 4, StatusBufferSize: 16})
+	if err != nil {
+		t.Fatalf("Failed to create NFCReader: %v", err)
+	}
+	defer reader.Close()
+
+	reader.handleDeviceErrors(NewUnsupportedTagError("3B00"))
+	select {
+	case data := <-reader.Data():
+		t.Errorf("Default policy should not announce the card, got %+v", data)
+	default:
+	}
+	if !reader.readCardPresent() {
+		t.Error("Expected the unsupported card to be present")
+	}
+
+	reader.handleDeviceErrors(NewUnsupportedTagEvent("04A1B2C3", "3B00"))
+	select {
+	case data := <-reader.Data():
+		if data.Card != nil || data.Unsupported == nil || *data.Unsupported != (UnsupportedCard{UID: "04A1B2C3", ATR: "3B00"}) {
+			t.Errorf("Expected an unsupported card event, got %+v", data)
+		}
+	default:
+		t.Fatal("Expected an unsupported card event")
+	}
+}
@@ -9,6 +9,7 @@ const (
 	TagChannelBuffer  = 10                     // Tag channel buffer size
 	GetTagsTimeout    = 500 * time.Millisecond // GetTags blocking timeout
 	CleanupInterval   = 15 * time.Second       // Cleanup check interval
+	DefaultMaxDevices = 32                     // Default cap on registered devices
 )
 
 // WebSocket message types for smartphone device communication
@@ -19,5 +20,6 @@ const (
 	MessageTypeTagRemoved             = "tagRemoved"
 	MessageTypeDeviceHeartbeat        = "deviceHeartbeat"
 	MessageTypeWriteResponse          = "writeResponse"
+	MessageTypeServerShutdown         = "serverShutdown"
 	MessageTypeError                  = "error"
 )
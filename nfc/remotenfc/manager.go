@@ -1,6 +1,7 @@
 package remotenfc
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"strings"
@@ -8,9 +9,16 @@ import (
 	"time"
 
 	"github.com/dotside-studios/davi-nfc-agent/nfc"
+	"github.com/dotside-studios/davi-nfc-agent/protocol"
 	"github.com/google/uuid"
 )
 
+// ErrTooManyDevices is returned by RegisterDevice when the device limit is reached.
+var ErrTooManyDevices = errors.New("too many registered devices")
+
+// DeviceSender delivers a message to a registered device's connection.
+type DeviceSender func(deviceID string, message any) error
+
 // Manager implements the nfc.Manager interface for managing smartphone connections.
 type Manager struct {
 	devices           map[string]*Device // deviceID -> device
@@ -18,9 +26,11 @@ type Manager struct {
 	cleanupTicker     *time.Ticker       // Periodic cleanup of inactive devices
 	stopCleanup       chan struct{}      // Stop cleanup goroutine
 	inactivityTimeout time.Duration      // Device timeout duration
+	maxDevices        int                // Maximum registered devices (0 = unlimited)
 	closed            bool               // Whether Close() has been called
 	dataChan          chan nfc.NFCData   // Channel for broadcasting tag data to server
 	deviceChangeChan  chan struct{}      // Channel for device registration/unregistration events
+	sender            DeviceSender       // Delivers server-initiated messages (nil = none)
 }
 
 // NewManager creates a new smartphone manager.
@@ -32,6 +42,7 @@ func NewManager(inactivityTimeout time.Duration) *Manager {
 	m := &Manager{
 		devices:           make(map[string]*Device),
 		inactivityTimeout: inactivityTimeout,
+		maxDevices:        DefaultMaxDevices,
 		stopCleanup:       make(chan struct{}),
 		dataChan:          make(chan nfc.NFCData, 10), // Buffered to prevent blocking
 		deviceChangeChan:  make(chan struct{}, 1),     // Buffered to prevent blocking
@@ -43,6 +54,25 @@ func NewManager(inactivityTimeout time.Duration) *Manager {
 	return m
 }
 
+// SetMaxDevices sets the maximum number of registered devices.
+// A value of 0 or less disables the limit.
+func (m *Manager) SetMaxDevices(max int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if max < 0 {
+		max = 0
+	}
+	m.maxDevices = max
+}
+
+// SetSender sets how server-initiated messages such as serverShutdown reach
+// devices. The device server wires this to its connections.
+func (m *Manager) SetSender(send DeviceSender) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sender = send
+}
+
 // OpenDevice opens connection to a registered smartphone device by ID.
 // Format: "smartphone:{deviceID}" or just "{deviceID}"
 func (m *Manager) OpenDevice(deviceStr string) (nfc.Device, error) {
@@ -98,8 +128,19 @@ func (m *Manager) RegisterDevice(req DeviceRegistrationRequest) (*Device, error)
 	// Create device
 	device := NewDevice(deviceID, req)
 
-	// Register device
+	// Register device, evicting stale devices first if the limit is reached
 	m.mu.Lock()
+	evicted := 0
+	if m.maxDevices > 0 && len(m.devices) >= m.maxDevices {
+		evicted = m.evictInactiveLocked()
+	}
+	if m.maxDevices > 0 && len(m.devices) >= m.maxDevices {
+		m.mu.Unlock()
+		if evicted > 0 {
+			m.notifyDeviceChange()
+		}
+		return nil, fmt.Errorf("%w: limit is %d", ErrTooManyDevices, m.maxDevices)
+	}
 	m.devices[deviceID] = device
 	m.mu.Unlock()
 
@@ -248,6 +289,39 @@ func (m *Manager) Close() {
 	log.Printf("[smartphone] Manager closed")
 }
 
+// Shutdown tells every registered device that the server is going away, then
+// unregisters it so its heartbeats are no longer tracked. Devices can then
+// reconnect or rediscover the agent instead of sending scans to a dead socket.
+// Unlike Close, the manager stays usable for devices registering again after
+// a server restart.
+func (m *Manager) Shutdown() {
+	m.mu.Lock()
+	send := m.sender
+	devices := m.devices
+	m.devices = make(map[string]*Device)
+	m.mu.Unlock()
+
+	for deviceID, device := range devices {
+		if send != nil {
+			err := send(deviceID, protocol.WebSocketMessage{
+				Type:    MessageTypeServerShutdown,
+				Payload: map[string]any{"deviceID": deviceID},
+			})
+			if err != nil {
+				log.Printf("[smartphone] Failed to notify device %s of shutdown: %v", deviceID, err)
+			}
+		}
+		if err := device.Close(); err != nil {
+			log.Printf("[smartphone] Error closing device %s: %v", deviceID, err)
+		}
+	}
+
+	if len(devices) > 0 {
+		log.Printf("[smartphone] Notified and unregistered %d device(s) on shutdown", len(devices))
+		m.notifyDeviceChange()
+	}
+}
+
 // startCleanupRoutine starts a background goroutine to cleanup inactive devices.
 func (m *Manager) startCleanupRoutine() {
 	m.cleanupTicker = time.NewTicker(CleanupInterval)
@@ -267,7 +341,19 @@ func (m *Manager) startCleanupRoutine() {
 // cleanupInactiveDevices removes devices that exceeded inactivity timeout.
 func (m *Manager) cleanupInactiveDevices() {
 	m.mu.Lock()
+	removedCount := m.evictInactiveLocked()
+	m.mu.Unlock()
+
+	// Notify listeners if any devices were removed
+	if removedCount > 0 {
+		m.notifyDeviceChange()
+	}
+}
 
+// evictInactiveLocked closes and removes devices whose last heartbeat is older
+// than the inactivity timeout. Returns the number of removed devices.
+// Caller must hold m.mu.
+func (m *Manager) evictInactiveLocked() int {
 	now := time.Now()
 	removedCount := 0
 	for deviceID, device := range m.devices {
@@ -283,12 +369,7 @@ func (m *Manager) cleanupInactiveDevices() {
 			removedCount++
 		}
 	}
-	m.mu.Unlock()
-
-	// Notify listeners if any devices were removed
-	if removedCount > 0 {
-		m.notifyDeviceChange()
-	}
+	return removedCount
 }
 
 // GetDeviceCount returns the number of registered devices.
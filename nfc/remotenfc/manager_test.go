@@ -1,10 +1,12 @@
 package remotenfc
 
 import (
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/dotside-studios/davi-nfc-agent/nfc"
+	"github.com/dotside-studios/davi-nfc-agent/protocol"
 )
 
 func TestNewManager(t *testing.T) {
@@ -347,6 +349,70 @@ func TestManagerCleanupInactiveDevices(t *testing.T) {
 	}
 }
 
+func TestManagerRegisterDeviceLimit(t *testing.T) {
+	m := NewManager(30 * time.Second)
+	defer m.Close()
+	m.SetMaxDevices(2)
+
+	req := DeviceRegistrationRequest{
+		DeviceName: "Test Device",
+		Platform:   "android",
+		AppVersion: "1.0.0",
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := m.RegisterDevice(req); err != nil {
+			t.Fatalf("RegisterDevice() #%d failed: %v", i+1, err)
+		}
+	}
+
+	_, err := m.RegisterDevice(req)
+	if !errors.Is(err, ErrTooManyDevices) {
+		t.Fatalf("RegisterDevice() over limit error = %v, want ErrTooManyDevices", err)
+	}
+	if m.GetDeviceCount() != 2 {
+		t.Errorf("Manager should have 2 devices, got %d", m.GetDeviceCount())
+	}
+
+	// Disabling the limit allows further registrations
+	m.SetMaxDevices(0)
+	if _, err := m.RegisterDevice(req); err != nil {
+		t.Errorf("RegisterDevice() with no limit failed: %v", err)
+	}
+}
+
+func TestManagerRegisterDeviceLimitEvictsStale(t *testing.T) {
+	m := NewManager(30 * time.Second)
+	defer m.Close()
+	m.SetMaxDevices(1)
+
+	req := DeviceRegistrationRequest{
+		DeviceName: "Test Device",
+		Platform:   "ios",
+		AppVersion: "1.0.0",
+	}
+
+	stale, err := m.RegisterDevice(req)
+	if err != nil {
+		t.Fatalf("RegisterDevice() failed: %v", err)
+	}
+	stale.mu.Lock()
+	stale.lastSeen = time.Now().Add(-time.Minute)
+	stale.mu.Unlock()
+
+	fresh, err := m.RegisterDevice(req)
+	if err != nil {
+		t.Fatalf("RegisterDevice() should evict the stale device, got: %v", err)
+	}
+
+	if _, exists := m.GetDevice(stale.DeviceID()); exists {
+		t.Error("Stale device should have been evicted")
+	}
+	if _, exists := m.GetDevice(fresh.DeviceID()); !exists {
+		t.Error("New device should be registered")
+	}
+}
+
 func TestManagerGetDevice(t *testing.T) {
 	m := NewManager(30 * time.Second)
 	defer m.Close()
@@ -408,3 +474,40 @@ func TestManagerClose(t *testing.T) {
 		t.Errorf("Should have 0 devices after close, got %d", m.GetDeviceCount())
 	}
 }
+
+func TestManagerShutdown(t *testing.T) {
+	m := NewManager(30 * time.Second)
+	defer m.Close()
+
+	var notified []string
+	m.SetSender(func(deviceID string, message any) error {
+		msg, ok := message.(protocol.WebSocketMessage)
+		if !ok || msg.Type != MessageTypeServerShutdown {
+			t.Errorf("Unexpected shutdown message: %+v", message)
+		}
+		notified = append(notified, deviceID)
+		return nil
+	})
+
+	device, err := m.RegisterDevice(DeviceRegistrationRequest{DeviceName: "Device", Platform: "ios"})
+	if err != nil {
+		t.Fatalf("RegisterDevice() failed: %v", err)
+	}
+
+	m.Shutdown()
+
+	if len(notified) != 1 || notified[0] != device.DeviceID() {
+		t.Errorf("Expected %s to be notified, got %v", device.DeviceID(), notified)
+	}
+	if m.GetDeviceCount() != 0 {
+		t.Errorf("Should have 0 devices after shutdown, got %d", m.GetDeviceCount())
+	}
+	if err := m.UpdateHeartbeat(device.DeviceID()); err == nil {
+		t.Error("Heartbeats from a shut down device should be rejected")
+	}
+
+	// The manager stays usable for devices registering after a restart
+	if _, err := m.RegisterDevice(DeviceRegistrationRequest{DeviceName: "Device", Platform: "ios"}); err != nil {
+		t.Errorf("RegisterDevice() after shutdown failed: %v", err)
+	}
+}
@@ -51,6 +51,12 @@ func TestParseUID(t *testing.T) {
 			want:    "04:AB:CD:12:34:56:78",
 			wantErr: false,
 		},
+		{
+			name:    "10-byte UID",
+			input:   "04AB CD12 3456 7890 12",
+			want:    "04:AB:CD:12:34:56:78:90:12",
+			wantErr: false,
+		},
 		{
 			name:    "empty UID",
 			input:   "",
@@ -91,6 +97,34 @@ func TestParseUID(t *testing.T) {
 	}
 }
 
+func TestConvertTagData_UIDLengths(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"8A1B2C3D", "8A:1B:2C:3D"},
+		{"04112233445566", "04:11:22:33:44:55:66"},
+		{"04112233445566778899", "04:11:22:33:44:55:66:77:88:99"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			tag, err := ConvertTagData(TagData{
+				DeviceID:   "device-123",
+				UID:        tt.input,
+				Technology: "ISO14443A",
+				Type:       "NTAG215",
+			})
+			if err != nil {
+				t.Fatalf("ConvertTagData() error = %v", err)
+			}
+			if tag.UID() != tt.want {
+				t.Errorf("UID() = %s, want %s", tag.UID(), tt.want)
+			}
+		})
+	}
+}
+
 func TestConvertNDEFRecordInput(t *testing.T) {
 	tnf01 := uint8(0x01)
 	tnf08 := uint8(0x08)
@@ -0,0 +1,184 @@
+package nfc
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// NTAG21x originality signature constants
+const (
+	// ntagCmdReadSig is the native READ_SIG command; its argument is always 0x00
+	ntagCmdReadSig = 0x3C
+
+	// NTAGSignatureLen is the length of the ECC originality signature (r || s)
+	NTAGSignatureLen = 32
+)
+
+// Originality reports the result of checking an originality signature.
+const (
+	OriginalityGenuine = "genuine" // Signature verified against the configured key
+	OriginalityUnknown = "unknown" // Signature missing or not made with the configured key
+)
+
+// secp128r1 domain parameters (SEC 2), the curve NXP signs NTAG21x UIDs on.
+// Go's crypto/elliptic does not include it.
+var secp128r1 = struct {
+	p, a, b, n, gx, gy *big.Int
+}{
+	p:  mustHexInt("FFFFFFFDFFFFFFFFFFFFFFFFFFFFFFFF"),
+	a:  mustHexInt("FFFFFFFDFFFFFFFFFFFFFFFFFFFFFFFC"),
+	b:  mustHexInt("E87579C11079F43DD824993C2CEE5ED3"),
+	n:  mustHexInt("FFFFFFFE0000000075A30D1B9038A115"),
+	gx: mustHexInt("161FF7528B899B2D0C28607CA52C5B86"),
+	gy: mustHexInt("CF5AC8395BAFEB13C02DA292DDED7A83"),
+}
+
+func mustHexInt(s string) *big.Int {
+	v, ok := new(big.Int).SetString(s, 16)
+	if !ok {
+		panic("invalid curve constant " + s)
+	}
+	return v
+}
+
+// SignatureKey is a secp128r1 public key used to verify NTAG21x originality
+// signatures, such as the one NXP publishes for its NTAG21x products.
+type SignatureKey struct {
+	x, y *big.Int
+}
+
+// ParseSignatureKey parses an uncompressed secp128r1 public key given as hex
+// (04 || X || Y, 33 bytes). Spaces and colons are ignored.
+func ParseSignatureKey(s string) (*SignatureKey, error) {
+	s = strings.NewReplacer(" ", "", ":", "").Replace(s)
+	raw, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid hex: %w", err)
+	}
+	if len(raw) != 33 || raw[0] != 0x04 {
+		return nil, fmt.Errorf("key must be an uncompressed point of 33 bytes starting with 04, got %d bytes", len(raw))
+	}
+
+	key := &SignatureKey{
+		x: new(big.Int).SetBytes(raw[1:17]),
+		y: new(big.Int).SetBytes(raw[17:]),
+	}
+	if !onCurve(key.x, key.y) {
+		return nil, fmt.Errorf("key is not a point on secp128r1")
+	}
+	return key, nil
+}
+
+// Verify reports whether sig (r || s, 32 bytes) is a valid ECDSA signature of
+// uid under the key. NXP signs the raw UID without hashing it.
+func (k *SignatureKey) Verify(uid, sig []byte) bool {
+	if k == nil || len(sig) != NTAGSignatureLen {
+		return false
+	}
+	curve := secp128r1
+	r := new(big.Int).SetBytes(sig[:16])
+	s := new(big.Int).SetBytes(sig[16:])
+	if r.Sign() == 0 || s.Sign() == 0 || r.Cmp(curve.n) >= 0 || s.Cmp(curve.n) >= 0 {
+		return false
+	}
+
+	e := signatureDigest(uid)
+	w := new(big.Int).ModInverse(s, curve.n)
+	u1 := new(big.Int).Mul(e, w)
+	u1.Mod(u1, curve.n)
+	u2 := new(big.Int).Mul(r, w)
+	u2.Mod(u2, curve.n)
+
+	x1, y1 := scalarMult(curve.gx, curve.gy, u1)
+	x2, y2 := scalarMult(k.x, k.y, u2)
+	x, _ := pointAdd(x1, y1, x2, y2)
+	if x == nil {
+		return false
+	}
+	return new(big.Int).Mod(x, curve.n).Cmp(r) == 0
+}
+
+// signatureDigest converts the signed message to an integer, keeping the
+// leftmost bits that fit the curve order as ECDSA requires.
+func signatureDigest(msg []byte) *big.Int {
+	e := new(big.Int).SetBytes(msg)
+	if excess := len(msg)*8 - secp128r1.n.BitLen(); excess > 0 {
+		e.Rsh(e, uint(excess))
+	}
+	return e
+}
+
+// onCurve reports whether (x, y) satisfies y² = x³ + ax + b mod p.
+func onCurve(x, y *big.Int) bool {
+	curve := secp128r1
+	if x.Cmp(curve.p) >= 0 || y.Cmp(curve.p) >= 0 {
+		return false
+	}
+	lhs := new(big.Int).Mul(y, y)
+	lhs.Mod(lhs, curve.p)
+
+	rhs := new(big.Int).Mul(x, x)
+	rhs.Mul(rhs, x)
+	rhs.Add(rhs, new(big.Int).Mul(curve.a, x))
+	rhs.Add(rhs, curve.b)
+	rhs.Mod(rhs, curve.p)
+
+	return lhs.Cmp(rhs) == 0
+}
+
+// pointAdd adds two affine points. nil coordinates stand for the point at
+// infinity.
+func pointAdd(x1, y1, x2, y2 *big.Int) (*big.Int, *big.Int) {
+	if x1 == nil {
+		return x2, y2
+	}
+	if x2 == nil {
+		return x1, y1
+	}
+	p := secp128r1.p
+
+	var slope *big.Int
+	if x1.Cmp(x2) == 0 {
+		sum := new(big.Int).Add(y1, y2)
+		if sum.Mod(sum, p).Sign() == 0 {
+			return nil, nil // P + (-P)
+		}
+		// Doubling: (3x² + a) / 2y
+		num := new(big.Int).Mul(x1, x1)
+		num.Mul(num, big.NewInt(3))
+		num.Add(num, secp128r1.a)
+		den := new(big.Int).Lsh(y1, 1)
+		slope = num.Mul(num, new(big.Int).ModInverse(den.Mod(den, p), p))
+	} else {
+		num := new(big.Int).Sub(y2, y1)
+		den := new(big.Int).Sub(x2, x1)
+		slope = num.Mul(num, new(big.Int).ModInverse(den.Mod(den, p), p))
+	}
+	slope.Mod(slope, p)
+
+	x3 := new(big.Int).Mul(slope, slope)
+	x3.Sub(x3, x1)
+	x3.Sub(x3, x2)
+	x3.Mod(x3, p)
+
+	y3 := new(big.Int).Sub(x1, x3)
+	y3.Mul(y3, slope)
+	y3.Sub(y3, y1)
+	y3.Mod(y3, p)
+
+	return x3, y3
+}
+
+// scalarMult computes k·(x, y) by double-and-add.
+func scalarMult(x, y, k *big.Int) (*big.Int, *big.Int) {
+	var rx, ry *big.Int
+	for i := k.BitLen() - 1; i >= 0; i-- {
+		rx, ry = pointAdd(rx, ry, rx, ry)
+		if k.Bit(i) == 1 {
+			rx, ry = pointAdd(rx, ry, x, y)
+		}
+	}
+	return rx, ry
+}
@@ -0,0 +1,122 @@
+package nfc
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+// testSignatureKey derives a secp128r1 key pair from the private scalar d
+// and returns the public key as hex, in the format ParseSignatureKey takes.
+func testSignatureKey(d int64) string {
+	x, y := scalarMult(secp128r1.gx, secp128r1.gy, big.NewInt(d))
+	return fmt.Sprintf("04%032X%032X", x, y)
+}
+
+// testSign signs msg with private scalar d and nonce k, returning r || s.
+func testSign(d, k int64, msg []byte) []byte {
+	n := secp128r1.n
+	x, _ := scalarMult(secp128r1.gx, secp128r1.gy, big.NewInt(k))
+	r := new(big.Int).Mod(x, n)
+	s := new(big.Int).Mul(r, big.NewInt(d))
+	s.Add(s, signatureDigest(msg))
+	s.Mul(s, new(big.Int).ModInverse(big.NewInt(k), n))
+	s.Mod(s, n)
+
+	sig := make([]byte, NTAGSignatureLen)
+	r.FillBytes(sig[:16])
+	s.FillBytes(sig[16:])
+	return sig
+}
+
+func TestSecp128r1(t *testing.T) {
+	if !onCurve(secp128r1.gx, secp128r1.gy) {
+		t.Fatal("Generator is not on the curve")
+	}
+	if x, _ := scalarMult(secp128r1.gx, secp128r1.gy, secp128r1.n); x != nil {
+		t.Error("n·G is not the point at infinity")
+	}
+}
+
+func TestSignatureKey_Verify(t *testing.T) {
+	key, err := ParseSignatureKey(testSignatureKey(0x1234567))
+	if err != nil {
+		t.Fatalf("ParseSignatureKey() error = %v", err)
+	}
+	uid := []byte{0x04, 0xA1, 0xB2, 0xC3, 0xD4, 0xE5, 0xF6}
+	sig := testSign(0x1234567, 0x7654321, uid)
+
+	if !key.Verify(uid, sig) {
+		t.Error("Verify() = false for a valid signature")
+	}
+
+	otherUID := append([]byte(nil), uid...)
+	otherUID[6] ^= 0x01
+	if key.Verify(otherUID, sig) {
+		t.Error("Verify() = true for another UID")
+	}
+
+	tampered := append([]byte(nil), sig...)
+	tampered[31] ^= 0x01
+	if key.Verify(uid, tampered) {
+		t.Error("Verify() = true for a tampered signature")
+	}
+
+	otherKey, _ := ParseSignatureKey(testSignatureKey(0x89ABCDE))
+	if otherKey.Verify(uid, sig) {
+		t.Error("Verify() = true under another key")
+	}
+
+	if key.Verify(uid, make([]byte, NTAGSignatureLen)) || key.Verify(uid, sig[:16]) {
+		t.Error("Verify() = true for a zero or short signature")
+	}
+}
+
+func TestParseSignatureKey_Errors(t *testing.T) {
+	valid := testSignatureKey(5)
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"not hex", "04ZZ", "invalid hex"},
+		{"short", valid[:40], "33 bytes"},
+		{"compressed", "02" + valid[2:], "33 bytes"},
+		{"off curve", valid[:len(valid)-2] + "00", "not a point"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseSignatureKey(tt.in); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ParseSignatureKey() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+
+	spaced := valid[:2] + " " + valid[2:10] + ":" + valid[10:]
+	if _, err := ParseSignatureKey(spaced); err != nil {
+		t.Errorf("ParseSignatureKey() with separators error = %v", err)
+	}
+}
+
+func TestPCSCNtagTag_ReadSignature(t *testing.T) {
+	sig := bytes.Repeat([]byte{0xA5}, NTAGSignatureLen)
+	card := newMockScardCard()
+	card.addResponse("ff000000023c0000", strings.ToLower(hex.EncodeToString(sig))+"9000")
+	tag := newPCSCNtagTag(newMockPCSCDevice(card, pcscATR(0x03)), "04A1B2C3D4E5F6", DetectedNTAG215)
+
+	got, err := tag.ReadSignature()
+	if err != nil {
+		t.Fatalf("ReadSignature() error = %v", err)
+	}
+	if !bytes.Equal(got, sig) {
+		t.Errorf("ReadSignature() = %X, want %X", got, sig)
+	}
+
+	card.addResponse("ff000000023c0000", "01029000")
+	if _, err := tag.ReadSignature(); err == nil {
+		t.Error("Expected ReadSignature() to refuse a short response")
+	}
+}
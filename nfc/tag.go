@@ -55,6 +55,13 @@ type TagLocker interface {
 	MakeReadOnly() error
 }
 
+// ATRProvider is implemented by tags that report the reader's ATR, such as
+// the raw tag surfaced for unsupported cards.
+type ATRProvider interface {
+	// ATR returns the Answer To Reset as an uppercase hex string.
+	ATR() string
+}
+
 // Tag represents an NFC tag at the hardware protocol level.
 //
 // Tag provides a unified interface for reading and writing NDEF data
@@ -91,6 +98,75 @@ type TagWriteOptions struct {
 	// WARNING: This will erase all existing data on the tag.
 	// Only use this if you explicitly want to wipe and reinitialize the tag.
 	ForceInitialize bool
+
+	// SectorKeys supplies the authentication key for each MIFARE Classic sector
+	// written. Sectors for which it returns ok=false fall back to the default keys.
+	// Ignored by non-Classic tags.
+	SectorKeys ClassicKeyProvider
+
+	// WipeTrailing zero-fills the rest of the NDEF area after the written
+	// message, so no bytes of an earlier, longer message remain. Slower, as
+	// every remaining block or page is written. Supported by MIFARE Classic and
+	// Type 2 (NTAG, Ultralight) tags.
+	WipeTrailing bool
+
+	// Layout is the layout of the tag's current contents, as returned by
+	// ReadDataWithLayout. Tags that support it then only rewrite the blocks
+	// that change, keeping the NDEF TLV at its current offset. When nil, or
+	// with ForceInitialize, every block is rewritten.
+	Layout *TLVLayout
+
+	// ClassicSectors restricts the NDEF data of a MIFARE Classic card to these
+	// sectors, leaving the others to other applications. Each must be assigned
+	// to NDEF or free in the card's MAD, which is updated to list exactly these
+	// sectors as NDEF. Writes that do not fit fail with ErrCardFull. When empty,
+	// the sectors the MAD assigns to NDEF are used. Ignored by other tags.
+	ClassicSectors []int
+}
+
+// TLVLayout records where the NDEF Message TLV was found in a tag's user
+// area, so a later write can leave the surrounding bytes in place.
+type TLVLayout struct {
+	// UserArea holds the raw bytes read, from the first user data block on.
+	UserArea []byte
+	// Offset is the position of the NDEF TLV's type byte in UserArea
+	Offset int
+	// Length is the size of the NDEF TLV, including its type and length bytes
+	Length int
+}
+
+// LayoutReader is an optional interface for tags that can report the TLV
+// layout their NDEF data was read from. A nil layout means none is available.
+type LayoutReader interface {
+	ReadDataWithLayout() ([]byte, *TLVLayout, error)
+}
+
+// ClassicSectorKey is a MIFARE Classic authentication key and its type.
+type ClassicSectorKey struct {
+	// Key is the 6-byte authentication key
+	Key []byte
+	// KeyType is KeyTypeA or KeyTypeB
+	KeyType int
+}
+
+// ClassicKeyProvider returns the key to use when authenticating a MIFARE Classic sector.
+// Returning ok=false makes the write path fall back to the default keys.
+type ClassicKeyProvider func(sector int) (key ClassicSectorKey, ok bool)
+
+// ClassicKeyMap returns a ClassicKeyProvider backed by a fixed sector→key map.
+//
+// Example:
+//
+//	opts := nfc.TagWriteOptions{
+//	    SectorKeys: nfc.ClassicKeyMap(map[int]nfc.ClassicSectorKey{
+//	        1: {Key: customKey, KeyType: nfc.KeyTypeB},
+//	    }),
+//	}
+func ClassicKeyMap(keys map[int]ClassicSectorKey) ClassicKeyProvider {
+	return func(sector int) (ClassicSectorKey, bool) {
+		key, ok := keys[sector]
+		return key, ok
+	}
 }
 
 // AdvancedWriter is an optional interface that tags can implement to support
@@ -100,6 +176,112 @@ type AdvancedWriter interface {
 	WriteDataWithOptions(data []byte, opts TagWriteOptions) error
 }
 
+// ReadOnlyOptions configures how a MIFARE Classic card is locked by
+// MakeReadOnlyWithOptions. The zero value matches MakeReadOnly.
+type ReadOnlyOptions struct {
+	// TrailerKey authenticates each sector before its trailer is rewritten.
+	// Defaults to KeyDefault, the Key B FormatNDEF writes. NDEF sector
+	// trailers (access bits 7F0788) can only be rewritten with Key B.
+	TrailerKey []byte
+
+	// TrailerKeyType is KeyTypeA or KeyTypeB. Defaults to KeyTypeB.
+	TrailerKeyType int
+
+	// SectorKeys supplies per-sector keys; a Key B configured for a sector
+	// is used instead of TrailerKey. Key A entries are skipped since they
+	// cannot rewrite NDEF sector trailers.
+	SectorKeys ClassicKeyProvider
+
+	// KeepKeyB leaves the locked sector trailers writable with Key B so the
+	// card can be unlocked later by an admin. Data blocks are read-only either way.
+	KeepKeyB bool
+
+	// KeyB is the Key B written into the locked trailers. Required with KeepKeyB
+	// unless TrailerKeyType is KeyTypeB, in which case TrailerKey is kept.
+	KeyB []byte
+}
+
+// AdvancedLocker is an optional interface for tags whose read-only locking
+// can be configured.
+type AdvancedLocker interface {
+	MakeReadOnlyWithOptions(opts ReadOnlyOptions) error
+}
+
+// NDEFRangeReader is an optional interface for tags that can read part of the
+// NDEF message without reading all of it, such as Type 4 tags.
+type NDEFRangeReader interface {
+	// ReadNDEFRange reads up to length bytes of the NDEF message starting at
+	// offset. Offsets at or beyond the message length return an empty slice.
+	ReadNDEFRange(offset, length int) ([]byte, error)
+}
+
+// BestEffortReader is an optional interface for tags whose data is split
+// into independently protected sectors, such as MIFARE Classic.
+type BestEffortReader interface {
+	// ReadDataBestEffort reads NDEF data like ReadData but continues past
+	// sectors that fail to authenticate or read. Their bytes are zero-filled
+	// and their numbers are returned in skipped.
+	ReadDataBestEffort() (data []byte, skipped []int, err error)
+}
+
+// NDEFFormatter is an optional interface for tags that can be initialized to an
+// empty NDEF layout without writing content.
+type NDEFFormatter interface {
+	// FormatNDEF prepares the tag for NDEF and leaves an empty NDEF message.
+	// Tags that are not in factory state are refused unless force is true.
+	// WARNING: with force, all existing data on the tag is erased.
+	FormatNDEF(force bool) error
+}
+
+// ISO14443Tag provides ISO 7816-4 file access on Type 4 tags beyond the NDEF
+// application, e.g. for eID-style cards. The methods return the card's data
+// and status word as is; only transport failures are returned as errors, so
+// callers must check the response's SW1/SW2.
+type ISO14443Tag interface {
+	Tag
+
+	// SelectByAID selects an application (DF) by its 5-16 byte AID.
+	SelectByAID(aid []byte) (APDUResponse, error)
+	// SelectByFID selects an EF or DF in the current application by its
+	// 2-byte file identifier.
+	SelectByFID(fid []byte) (APDUResponse, error)
+	// ReadRecord reads record recordNo (1-254) of the EF with short file
+	// identifier sfi (1-30), or of the currently selected EF if sfi is 0.
+	ReadRecord(recordNo, sfi byte) (APDUResponse, error)
+}
+
+// DESFireTag provides DESFire identification commands that work without
+// authentication.
+type DESFireTag interface {
+	Tag
+
+	// GetVersion reads the hardware and software versions and production data.
+	GetVersion() (DESFireVersion, error)
+
+	// FreeMemory returns the free EEPROM in bytes.
+	FreeMemory() (int, error)
+}
+
+// SignatureTag provides the ECC originality signature of NTAG21x tags,
+// which NXP computes over the UID at production.
+type SignatureTag interface {
+	Tag
+
+	// ReadSignature reads the 32-byte originality signature with READ_SIG.
+	ReadSignature() ([]byte, error)
+}
+
+// MagicTag probes MIFARE Classic cards for the gen1a "magic" backdoor used
+// by clone cards whose block 0 (the UID) can be rewritten.
+type MagicTag interface {
+	Tag
+
+	// ProbeMagic reports whether the card accepts the gen1a unlock commands
+	// and would let block 0 be written. It writes nothing, but leaves the card
+	// halted until the next poll selects it again.
+	ProbeMagic() (MagicInfo, error)
+}
+
 // ClassicTag provides MIFARE Classic specific operations.
 // This interface extends Tag with sector/block-level access using authentication keys.
 //
@@ -136,4 +318,44 @@ type ClassicTag interface {
 	// key: 6-byte authentication key
 	// keyType: KeyTypeA or KeyTypeB
 	Write(sector, block uint8, data []byte, key []byte, keyType int) error
+
+	// ReadManufacturerBlock reads block 0 with the factory key and decodes the
+	// UID, SAK and ATQA, validating the BCC over the UID bytes.
+	// Block 0 is read-only on genuine cards but writable on gen-1a "magic" cards.
+	ReadManufacturerBlock() (uid []byte, sak, atqa []byte, bccValid bool, err error)
+
+	// ReadMADInfo reads the MIFARE Application Directory with the public MAD key
+	// and returns the application ID assigned to each sector (MAD2 included on
+	// 4K cards). If a MAD CRC does not match, the entries are still returned,
+	// together with an error wrapping ErrMADCRC.
+	ReadMADInfo() ([]MADEntry, error)
+
+	// ReadGPB reads the General Purpose Byte (byte 9) of the sector trailer
+	// using the provided key, e.g. 0xC1 for a MAD v1 sector or 0x40 for an
+	// NDEF sector as formatting writes them.
+	ReadGPB(sector uint8, key []byte, keyType int) (byte, error)
+}
+
+// PageTag provides raw page access for NFC Forum Type 2 tags (MIFARE Ultralight
+// and NTAG), for proprietary data stored outside the NDEF message.
+//
+// Example:
+//
+//	if pt, ok := tag.(nfc.PageTag); ok {
+//	    data, err := pt.ReadPages(4, 4) // 16 bytes from pages 4-7
+//	    if err != nil {
+//	        log.Fatal(err)
+//	    }
+//	}
+type PageTag interface {
+	Tag
+
+	// PageCount returns the total number of 4-byte pages on the tag.
+	PageCount() int
+
+	// ReadPages reads count pages starting at start using the Type 2 READ command.
+	ReadPages(start, count int) ([]byte, error)
+
+	// WritePage writes one page using the Type 2 WRITE command.
+	WritePage(page int, data [4]byte) error
 }
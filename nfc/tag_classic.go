@@ -1,7 +1,12 @@
 package nfc
 
 import (
+	"bytes"
+	"encoding/hex"
 	"fmt"
+	"log"
+	"slices"
+	"time"
 )
 
 // Default MIFARE keys to try during authentication
@@ -24,7 +29,9 @@ func newPCSCClassicTag(dev *pcscDevice, uid string, tagType DetectedTagType) *pc
 			uid:          uid,
 			detectedType: tagType,
 		},
-		is4K: tagType == DetectedClassic4K,
+		// MIFARE Plus 2K in SL1 is handled with the 1K layout: the MAD v1
+		// written by FormatNDEF only covers the first 16 sectors anyway.
+		is4K: tagType == DetectedClassic4K || tagType == DetectedPlus4K,
 	}
 }
 
@@ -135,15 +142,32 @@ func (t *pcscClassicTag) readBlock(block int, lastAuthSector *int) ([]byte, erro
 	return parsed.Data, nil
 }
 
+// authenticateSectorForWrite authenticates to a sector using the key supplied by
+// keys, falling back to the default keys when none is configured for the sector.
+func (t *pcscClassicTag) authenticateSectorForWrite(sector int, keys ClassicKeyProvider) error {
+	if keys != nil {
+		if sectorKey, ok := keys(sector); ok {
+			if err := t.authenticateWithKey(sector, sectorKey.Key, sectorKey.KeyType); err != nil {
+				if IsCardRemovedError(err) {
+					return err
+				}
+				return fmt.Errorf("authentication failed for sector %d with configured key: %w", sector, err)
+			}
+			return nil
+		}
+	}
+	return t.authenticateSector(sector)
+}
+
 // writeBlock writes 16 bytes to the specified block, authenticating if needed
-func (t *pcscClassicTag) writeBlock(block int, data []byte, lastAuthSector *int) error {
+func (t *pcscClassicTag) writeBlock(block int, data []byte, lastAuthSector *int, keys ClassicKeyProvider) error {
 	if len(data) != 16 {
 		return fmt.Errorf("block data must be 16 bytes, got %d", len(data))
 	}
 
 	sector := block / 4
 	if *lastAuthSector != sector {
-		if err := t.authenticateSector(sector); err != nil {
+		if err := t.authenticateSectorForWrite(sector, keys); err != nil {
 			return err
 		}
 		*lastAuthSector = sector
@@ -167,23 +191,95 @@ func (t *pcscClassicTag) writeBlock(block int, data []byte, lastAuthSector *int)
 	return nil
 }
 
+// pauseBetweenWrites waits the device's Classic write delay, if any, before
+// the next block write.
+func (t *pcscClassicTag) pauseBetweenWrites() {
+	if t.device.classicWriteDelay > 0 {
+		time.Sleep(t.device.classicWriteDelay)
+	}
+}
+
 func (t *pcscClassicTag) ReadData() ([]byte, error) {
-	var allData []byte
+	data, _, err := t.ReadDataWithLayout()
+	return data, err
+}
+
+// ReadDataWithLayout reads NDEF data like ReadData and also returns the raw
+// user area it was found in (implements LayoutReader).
+func (t *pcscClassicTag) ReadDataWithLayout() ([]byte, *TLVLayout, error) {
+	allData, _, err := t.readUserBlocks(false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Parse TLV to extract NDEF message
+	if ndefData, found := TLVFindNDEF(allData); found {
+		return ndefData, classicLayout(allData, ndefData), nil
+	}
+	if !TLVNDEFTruncated(allData) {
+		return nil, nil, fmt.Errorf("no NDEF message found")
+	}
+
+	// The NDEF TLV declares more bytes than were read. This is usually a transient
+	// misread on partially-formatted cards, so re-read once before giving up.
+	log.Printf("Classic tag %s: NDEF TLV truncated after %d bytes, re-reading", t.uid, len(allData))
+	retryData, _, err := t.readUserBlocks(false)
+	if err != nil {
+		return nil, nil, err
+	}
+	if ndefData, found := TLVFindNDEF(retryData); found {
+		return ndefData, classicLayout(retryData, ndefData), nil
+	}
+	if bytes.Equal(allData, retryData) {
+		return nil, nil, fmt.Errorf("malformed NDEF TLV: declared length exceeds readable data (%d bytes)", len(retryData))
+	}
+	return nil, nil, fmt.Errorf("no NDEF message found after re-read")
+}
+
+// classicLayout returns the layout of the NDEF TLV holding ndefData in
+// userArea, or nil if it cannot be located.
+func classicLayout(userArea, ndefData []byte) *TLVLayout {
+	offset, found := TLVLocateNDEF(userArea)
+	if !found {
+		return nil
+	}
+	_, fvs := TLVRecordLength(userArea[offset:])
+	return &TLVLayout{UserArea: userArea, Offset: offset, Length: fvs + len(ndefData)}
+}
+
+// ReadDataBestEffort reads NDEF data like ReadData, but skips sectors that
+// fail to authenticate or read instead of stopping. Skipped sectors are
+// zero-filled so the rest of the message keeps its offsets.
+func (t *pcscClassicTag) ReadDataBestEffort() ([]byte, []int, error) {
+	allData, skipped, err := t.readUserBlocks(true)
+	if err != nil {
+		return nil, skipped, err
+	}
+	if ndefData, found := TLVFindNDEF(allData); found {
+		return ndefData, skipped, nil
+	}
+	return nil, skipped, fmt.Errorf("no NDEF message found (skipped sectors: %v)", skipped)
+}
+
+// readUserBlocks reads the data blocks of the NDEF sectors until a block
+// containing the TLV terminator is seen or a read fails. With bestEffort, a
+// failed sector is zero-filled and reported in skipped, and reading goes on
+// with the next sector.
+func (t *pcscClassicTag) readUserBlocks(bestEffort bool) (allData []byte, skipped []int, err error) {
 	lastAuthSector := -1
+	skipSector := -1
+	readAny := false
 
-	// Determine max blocks based on card type
-	maxBlocks := 64 // MIFARE Classic 1K: 16 sectors × 4 blocks
-	if t.is4K {
-		maxBlocks = 256 // MIFARE Classic 4K: 32 sectors × 4 blocks + 8 sectors × 16 blocks
+	sectors, _, err := t.ndefSectors()
+	if err != nil {
+		return nil, nil, err
 	}
 
-	// Read from block 4 onwards (skip sector 0/MAD)
 	var lastError error
-	for blockNum := 4; blockNum < maxBlocks; blockNum++ {
-		// Skip sector trailers (last block in each sector)
-		// For 1K: blocks 3, 7, 11, 15, ... (every 4th block starting at 3)
-		// For 4K: same for first 32 sectors, then every 16th for large sectors
-		if t.isSectorTrailer(blockNum) {
+	for _, blockNum := range t.dataBlocks(sectors) {
+		sector := t.blockSector(blockNum)
+		if sector == skipSector {
+			allData = append(allData, make([]byte, 16)...)
 			continue
 		}
 
@@ -191,89 +287,311 @@ func (t *pcscClassicTag) ReadData() ([]byte, error) {
 		if err != nil {
 			// If card was removed, propagate that error immediately
 			if IsCardRemovedError(err) {
-				return nil, err
+				return nil, skipped, err
 			}
-			// For other errors, record and stop reading
 			lastError = err
+			if bestEffort {
+				log.Printf("Classic tag %s: skipping unreadable sector %d: %v", t.uid, sector, err)
+				skipped = append(skipped, sector)
+				skipSector = sector
+				lastAuthSector = -1
+				allData = append(allData, make([]byte, 16)...)
+				continue
+			}
+			// For other errors, record and stop reading
 			break
 		}
+		readAny = true
 		allData = append(allData, blockData...)
 
 		// Check for NDEF terminator (0xFE)
-		for _, b := range blockData {
-			if b == 0xFE {
-				goto done
-			}
+		if bytes.IndexByte(blockData, TLVTerminator) >= 0 {
+			break
 		}
 	}
-done:
 
-	if len(allData) == 0 {
+	if !readAny {
 		// Check if error was due to card removal (APDU errors when card is gone)
 		if lastError != nil && !t.device.IsCardPresent() {
-			return nil, NewCardRemovedError(fmt.Errorf("card removed during read"))
+			return nil, skipped, NewCardRemovedError(fmt.Errorf("card removed during read"))
 		}
 		if lastError != nil {
-			return nil, fmt.Errorf("failed to read any data from tag: %w", lastError)
+			return nil, skipped, fmt.Errorf("failed to read any data from tag: %w", lastError)
 		}
-		return nil, fmt.Errorf("failed to read any data from tag")
-	}
-
-	// Parse TLV to extract NDEF message
-	ndefData, found := TLVFindNDEF(allData)
-	if !found {
-		return nil, fmt.Errorf("no NDEF message found")
+		return nil, skipped, fmt.Errorf("failed to read any data from tag")
 	}
 
-	return ndefData, nil
+	return allData, skipped, nil
 }
 
 func (t *pcscClassicTag) WriteData(data []byte) error {
-	// Wrap NDEF data in TLV structure
+	return t.WriteDataWithOptions(data, TagWriteOptions{})
+}
+
+// WriteDataWithOptions writes NDEF data, authenticating each sector with the
+// key from opts.SectorKeys when one is configured, and zero-filling every
+// later data block with opts.WipeTrailing. With opts.ClassicSectors the data
+// only goes to those sectors and the MAD is updated to match (implements
+// AdvancedWriter).
+func (t *pcscClassicTag) WriteDataWithOptions(data []byte, opts TagWriteOptions) error {
+	if opts.ForceInitialize {
+		if err := t.FormatNDEF(true); err != nil {
+			return fmt.Errorf("failed to initialize card: %w", err)
+		}
+	}
+
+	sectors, entries, err := t.ndefSectors()
+	if err != nil {
+		return err
+	}
+	var madUpdate []MADEntry
+	if len(opts.ClassicSectors) > 0 {
+		if sectors, madUpdate, err = t.restrictSectors(opts.ClassicSectors, entries); err != nil {
+			return err
+		}
+		opts.Layout = nil // The layout was read from the old sectors
+	}
+
+	// Wrap NDEF data in TLV structure. With a layout from the last read, keep
+	// whatever preceded the NDEF TLV and compare against what is on the card.
 	tlvPayload := TLVEncode(data, TLVNDEF)
+	var previous []byte
+	if layout := opts.Layout; layout != nil && !opts.ForceInitialize && layout.Offset <= len(layout.UserArea) {
+		tlvPayload = append(append([]byte(nil), layout.UserArea[:layout.Offset]...), tlvPayload...)
+		previous = layout.UserArea
+	}
 
 	// Pad to 16-byte blocks
 	for len(tlvPayload)%16 != 0 {
 		tlvPayload = append(tlvPayload, 0x00)
 	}
 
-	// Determine max usable blocks
-	maxBlocks := 64
-	if t.is4K {
-		maxBlocks = 256
+	blocks := t.dataBlocks(sectors)
+	blocksNeeded := len(tlvPayload) / 16
+	if blocksNeeded > len(blocks) {
+		return fmt.Errorf("%w: need %d blocks, have %d usable blocks in sectors %v", ErrCardFull, blocksNeeded, len(blocks), sectors)
 	}
 
-	// Calculate blocks needed (excluding sector trailers)
-	usableBlocks := 0
-	for blockNum := 4; blockNum < maxBlocks; blockNum++ {
-		if !t.isSectorTrailer(blockNum) {
-			usableBlocks++
+	lastAuthSector := -1
+	written := 0
+
+	for i, blockNum := range blocks[:blocksNeeded] {
+		offset := i * 16
+		block := tlvPayload[offset : offset+16]
+		if offset+16 <= len(previous) && bytes.Equal(block, previous[offset:offset+16]) {
+			continue
+		}
+
+		if written > 0 {
+			t.pauseBetweenWrites()
+		}
+		if err := t.writeBlock(blockNum, block, &lastAuthSector, opts.SectorKeys); err != nil {
+			return fmt.Errorf("failed to write block %d: %w", blockNum, err)
 		}
+		written++
 	}
 
-	blocksNeeded := len(tlvPayload) / 16
-	if blocksNeeded > usableBlocks {
-		return fmt.Errorf("data too large: need %d blocks, have %d usable blocks", blocksNeeded, usableBlocks)
+	if previous != nil {
+		log.Printf("Classic tag %s: rewrote %d of %d blocks", t.uid, written, blocksNeeded)
 	}
 
-	blockNum := 4 // Start at sector 1
-	lastAuthSector := -1
+	if opts.WipeTrailing {
+		empty := make([]byte, 16)
+		for i, blockNum := range blocks[blocksNeeded:] {
+			if written > 0 || i > 0 {
+				t.pauseBetweenWrites()
+			}
+			if err := t.writeBlock(blockNum, empty, &lastAuthSector, opts.SectorKeys); err != nil {
+				return fmt.Errorf("failed to wipe block %d: %w", blockNum, err)
+			}
+		}
+	}
 
-	for offset := 0; offset < len(tlvPayload); offset += 16 {
-		// Skip sector trailers
-		for t.isSectorTrailer(blockNum) {
-			blockNum++
+	// The MAD is only changed once the data is in place, so a failed write
+	// leaves it describing the previous message.
+	if madUpdate != nil {
+		if err := t.writeMAD(madUpdate, opts.SectorKeys); err != nil {
+			return fmt.Errorf("failed to update MAD: %w", err)
 		}
+	}
+	return nil
+}
 
-		if err := t.writeBlock(blockNum, tlvPayload[offset:offset+16], &lastAuthSector); err != nil {
-			return fmt.Errorf("failed to write block %d: %w", blockNum, err)
+// ndefSectors returns the sectors holding NDEF data, in order: those the MAD
+// assigns to NDEF, or every sector after sector 0 on cards without a valid
+// MAD listing any. The MAD entries are returned as well, nil without a valid
+// MAD. Only card removal is reported as an error.
+func (t *pcscClassicTag) ndefSectors() (sectors []int, entries []MADEntry, err error) {
+	entries, err = t.ReadMADInfo()
+	if err != nil {
+		if IsCardRemovedError(err) {
+			return nil, nil, err
+		}
+		entries = nil
+	}
+
+	for _, entry := range entries {
+		if entry.AID == MADAIDNDEF {
+			sectors = append(sectors, entry.Sector)
+		}
+	}
+	if len(sectors) == 0 {
+		for sector := 1; sector < t.sectorCount(); sector++ {
+			sectors = append(sectors, sector)
 		}
-		blockNum++
 	}
+	return sectors, entries, nil
+}
 
+// restrictSectors validates the sectors NDEF data is restricted to against
+// the card's MAD entries. It returns them sorted, along with the MAD entries
+// to write so that exactly these sectors are assigned to NDEF, or nil if the
+// MAD already says so. Sectors assigned to other applications are refused.
+func (t *pcscClassicTag) restrictSectors(allowed []int, entries []MADEntry) ([]int, []MADEntry, error) {
+	if entries == nil {
+		return nil, nil, fmt.Errorf("restricting NDEF data to sectors %v requires a card with a valid MAD", allowed)
+	}
+
+	sectors := append([]int(nil), allowed...)
+	slices.Sort(sectors)
+	sectors = slices.Compact(sectors)
+
+	updated := append([]MADEntry(nil), entries...)
+	changed := false
+	for i, entry := range updated {
+		want := entry.AID
+		if _, found := slices.BinarySearch(sectors, entry.Sector); found {
+			if entry.AID != MADAIDNDEF && entry.AID != 0x0000 {
+				return nil, nil, fmt.Errorf("sector %d is assigned to application 0x%04X in the MAD", entry.Sector, entry.AID)
+			}
+			want = MADAIDNDEF
+		} else if entry.AID == MADAIDNDEF {
+			want = 0x0000 // Release NDEF sectors outside the selection
+		}
+		if want != entry.AID {
+			updated[i].AID = want
+			changed = true
+		}
+	}
+
+	for _, sector := range sectors {
+		if !slices.ContainsFunc(entries, func(e MADEntry) bool { return e.Sector == sector }) {
+			return nil, nil, fmt.Errorf("sector %d cannot hold NDEF data (the card has sectors 1-%d, MAD sectors excluded)", sector, t.sectorCount()-1)
+		}
+	}
+
+	if !changed {
+		return sectors, nil, nil
+	}
+	return sectors, updated, nil
+}
+
+// writeMAD rewrites the MAD so it assigns each sector the AID in entries,
+// keeping the info byte of each MAD. MAD sectors are only writable with
+// Key B, so the key from keys is used when one is configured and otherwise
+// every known key is tried as Key B and A.
+func (t *pcscClassicTag) writeMAD(entries []MADEntry, keys ClassicKeyProvider) error {
+	aids := make(map[int]uint16, len(entries))
+	for _, entry := range entries {
+		aids[entry.Sector] = entry.AID
+	}
+
+	mads := []struct{ sector, firstBlock, firstEntry, count int }{{0, 1, 1, mad1Sectors}}
+	if t.is4K {
+		mads = append(mads, struct{ sector, firstBlock, firstEntry, count int }{16, 64, 17, mad2Sectors})
+	}
+
+	for _, m := range mads {
+		if _, ok := aids[m.firstEntry]; !ok {
+			continue // No MAD2 on this card
+		}
+		authenticate := t.authenticateForFormat
+		if keys != nil {
+			if _, ok := keys(m.sector); ok {
+				authenticate = func(sector int, _ bool) error { return t.authenticateSectorForWrite(sector, keys) }
+			}
+		}
+		if err := authenticate(m.sector, true); err != nil {
+			return err
+		}
+
+		lastAuthSector := m.sector
+		first, err := t.readBlock(m.firstBlock, &lastAuthSector)
+		if err != nil {
+			return fmt.Errorf("failed to read MAD of sector %d: %w", m.sector, err)
+		}
+		aidList := make([]uint16, m.count)
+		for i := range aidList {
+			aidList[i] = aids[m.firstEntry+i]
+		}
+		mad := encodeMAD(first[1], aidList)
+
+		for i := 0; i*16 < len(mad); i++ {
+			if err := t.updateBlock(m.firstBlock+i, mad[i*16:(i+1)*16]); err != nil {
+				return fmt.Errorf("failed to write MAD block %d: %w", m.firstBlock+i, err)
+			}
+		}
+	}
 	return nil
 }
 
+// dataBlocks returns the data blocks of sectors in order, leaving out the
+// sector trailers.
+func (t *pcscClassicTag) dataBlocks(sectors []int) []int {
+	var blocks []int
+	for _, sector := range sectors {
+		first := t.sectorFirstBlock(sector)
+		for block := first; block < first+t.sectorBlockCount(sector)-1; block++ {
+			blocks = append(blocks, block)
+		}
+	}
+	return blocks
+}
+
+// LockedSectors returns the sectors a TLV-wrapped NDEF message of size bytes
+// would occupy that cannot be written: sectors none of the keys open, and
+// sectors whose access bits forbid writing their data blocks (implements
+// LockedSectorChecker).
+func (t *pcscClassicTag) LockedSectors(size int, keys ClassicKeyProvider) ([]int, error) {
+	sectors, _, err := t.ndefSectors()
+	if err != nil {
+		return nil, err
+	}
+	blocks := t.dataBlocks(sectors)
+	if blocksNeeded := (len(TLVEncode(make([]byte, size), TLVNDEF)) + 15) / 16; blocksNeeded < len(blocks) {
+		blocks = blocks[:blocksNeeded]
+	}
+
+	var locked []int
+	checked := -1
+	for _, blockNum := range blocks {
+
+		sector := t.blockSector(blockNum)
+		if sector == checked {
+			continue
+		}
+		checked = sector
+
+		if err := t.authenticateSectorForWrite(sector, keys); err != nil {
+			if IsCardRemovedError(err) {
+				return nil, err
+			}
+			locked = append(locked, sector)
+			continue
+		}
+		trailer := t.sectorFirstBlock(sector) + t.sectorBlockCount(sector) - 1
+		resp, err := t.transmitRaw(ReadBinaryAPDU(byte(trailer), 16))
+		if err != nil {
+			return nil, err
+		}
+		parsed, err := ParseAPDUResponse(resp)
+		if err != nil || !parsed.IsSuccess() || len(parsed.Data) < 9 || !classicDataBlocksWritable(parsed.Data[6:9]) {
+			locked = append(locked, sector)
+		}
+	}
+	return locked, nil
+}
+
 // isSectorTrailer returns true if the block is a sector trailer
 func (t *pcscClassicTag) isSectorTrailer(block int) bool {
 	if t.is4K && block >= 128 {
@@ -285,6 +603,144 @@ func (t *pcscClassicTag) isSectorTrailer(block int) bool {
 	return (block+1)%4 == 0
 }
 
+// sectorCount returns the number of sectors on the card
+func (t *pcscClassicTag) sectorCount() int {
+	if t.is4K {
+		return 40
+	}
+	return 16
+}
+
+// blockSector returns the sector an absolute block belongs to
+func (t *pcscClassicTag) blockSector(block int) int {
+	if t.is4K && block >= 128 {
+		return 32 + (block-128)/16
+	}
+	return block / 4
+}
+
+// sectorFirstBlock returns the absolute block number of the first block in a sector
+func (t *pcscClassicTag) sectorFirstBlock(sector int) int {
+	if sector >= 32 {
+		return 128 + (sector-32)*16
+	}
+	return sector * 4
+}
+
+// sectorBlockCount returns the number of blocks (including the trailer) in a sector
+func (t *pcscClassicTag) sectorBlockCount(sector int) int {
+	if sector >= 32 {
+		return 16
+	}
+	return 4
+}
+
+// updateBlock writes 16 bytes to an absolute block in an already-authenticated sector
+func (t *pcscClassicTag) updateBlock(block int, data []byte) error {
+	resp, err := t.transmitRaw(UpdateBinaryAPDU(byte(block), data))
+	if err != nil {
+		return err
+	}
+	parsed, err := ParseAPDUResponse(resp)
+	if err != nil {
+		return err
+	}
+	if !parsed.IsSuccess() {
+		return parsed.Error()
+	}
+	return nil
+}
+
+// authenticateForFormat authenticates to a sector before formatting. Without
+// force only the factory key is accepted, so cards that were already
+// provisioned are refused; with force every known key is tried as Key A and B.
+func (t *pcscClassicTag) authenticateForFormat(sector int, force bool) error {
+	if !force {
+		if err := t.authenticateWithKey(sector, KeyDefault, KeyTypeA); err != nil {
+			if IsCardRemovedError(err) {
+				return err
+			}
+			return fmt.Errorf("sector %d is not in factory state (set force to reformat): %w", sector, err)
+		}
+		return nil
+	}
+
+	var lastErr error
+	for _, key := range classicDefaultKeys {
+		for _, keyType := range []int{KeyTypeB, KeyTypeA} {
+			err := t.authenticateWithKey(sector, key, keyType)
+			if err == nil {
+				return nil
+			}
+			if IsCardRemovedError(err) {
+				return err
+			}
+			lastErr = err
+		}
+	}
+	return fmt.Errorf("authentication failed for sector %d: no valid key found: %w", sector, lastErr)
+}
+
+// FormatNDEF initializes the card for NDEF: it writes the MAD (and MAD2 on 4K
+// cards), sets NFC Forum sector trailers, clears the data blocks and leaves an
+// empty NDEF message. Cards not in factory state are refused unless force is set.
+// This implements the NDEFFormatter interface.
+func (t *pcscClassicTag) FormatNDEF(force bool) error {
+	gpb := byte(madGPBv1)
+	if t.is4K {
+		gpb = madGPBv2
+	}
+	madTrailer := buildSectorTrailer(KeyMAD, madSectorAccess, gpb, KeyDefault)
+	ndefTrailer := buildSectorTrailer(KeyNFCForum, ndefSectorAccess, ndefSectorGPB, KeyDefault)
+
+	// Verify every sector is accessible before touching anything, so a
+	// non-factory card is rejected without being partially formatted.
+	for sector := 0; sector < t.sectorCount(); sector++ {
+		if err := t.authenticateForFormat(sector, force); err != nil {
+			return err
+		}
+	}
+
+	for sector := 0; sector < t.sectorCount(); sector++ {
+		if err := t.authenticateForFormat(sector, force); err != nil {
+			return err
+		}
+
+		first := t.sectorFirstBlock(sector)
+		trailer := first + t.sectorBlockCount(sector) - 1
+
+		var data []byte
+		sectorTrailer := ndefTrailer
+		switch {
+		case sector == 0:
+			data = buildMAD(15)
+			sectorTrailer = madTrailer
+			first = 1 // Block 0 holds the manufacturer data
+		case sector == 16 && t.is4K:
+			data = buildMAD(23)
+			sectorTrailer = madTrailer
+		case sector == 1:
+			data = []byte{TLVNDEF, 0x00, TLVTerminator}
+		}
+
+		for block := first; block < trailer; block++ {
+			blockData := make([]byte, 16)
+			if offset := (block - first) * 16; offset < len(data) {
+				copy(blockData, data[offset:])
+			}
+			if err := t.updateBlock(block, blockData); err != nil {
+				return fmt.Errorf("failed to write block %d: %w", block, err)
+			}
+		}
+
+		if err := t.updateBlock(trailer, sectorTrailer); err != nil {
+			return fmt.Errorf("failed to write sector %d trailer: %w", sector, err)
+		}
+	}
+
+	return nil
+}
+
 func (t *pcscClassicTag) IsWritable() (bool, error) {
 	// Try to authenticate to sector 1 to check if we can access it
 	lastAuthSector := -1
@@ -292,12 +748,71 @@ func (t *pcscClassicTag) IsWritable() (bool, error) {
 	return err == nil, nil
 }
 
+// QuickWritable authenticates to sector 1, the first NDEF sector, without
+// reading from it (implements QuickWritabilityChecker).
+func (t *pcscClassicTag) QuickWritable() (bool, error) {
+	if err := t.authenticateSector(1); err != nil {
+		return false, quickWritableError(err)
+	}
+	return true, nil
+}
+
 func (t *pcscClassicTag) CanMakeReadOnly() (bool, error) {
 	return true, nil
 }
 
+// MakeReadOnly locks all NDEF sectors, authenticating with KeyDefault as Key B.
 func (t *pcscClassicTag) MakeReadOnly() error {
-	return fmt.Errorf("MIFARE Classic MakeReadOnly not yet implemented")
+	return t.MakeReadOnlyWithOptions(ReadOnlyOptions{})
+}
+
+// MakeReadOnlyWithOptions rewrites the trailer of every NDEF sector (all sectors
+// except the MAD sectors) with read-only access bits. Every sector is
+// authenticated first so a wrong key leaves the card untouched.
+// This implements the AdvancedLocker interface.
+//
+// The trailers are authenticated with Key B: opts.SectorKeys' Key B for the
+// sector when there is one, otherwise opts.TrailerKey.
+func (t *pcscClassicTag) MakeReadOnlyWithOptions(opts ReadOnlyOptions) error {
+	trailer, key, keyType, err := readOnlyTrailer(opts)
+	if err != nil {
+		return err
+	}
+
+	var sectors []int
+	for sector := 1; sector < t.sectorCount(); sector++ {
+		if sector == 16 && t.is4K {
+			continue // MAD2
+		}
+		sectors = append(sectors, sector)
+	}
+
+	authenticate := func(sector int) error {
+		if opts.SectorKeys != nil {
+			if sectorKey, ok := opts.SectorKeys(sector); ok && sectorKey.KeyType == KeyTypeB {
+				return t.authenticateWithKey(sector, sectorKey.Key, sectorKey.KeyType)
+			}
+		}
+		return t.authenticateWithKey(sector, key, keyType)
+	}
+
+	for _, sector := range sectors {
+		if err := authenticate(sector); err != nil {
+			return fmt.Errorf("cannot lock sector %d: %w", sector, err)
+		}
+	}
+
+	for _, sector := range sectors {
+		if err := authenticate(sector); err != nil {
+			return fmt.Errorf("cannot lock sector %d: %w", sector, err)
+		}
+		block := t.sectorFirstBlock(sector) + t.sectorBlockCount(sector) - 1
+		if err := t.updateBlock(block, trailer); err != nil {
+			return fmt.Errorf("failed to write sector %d trailer: %w", sector, err)
+		}
+	}
+
+	return nil
 }
 
 // authenticateWithKey authenticates to a sector using a specific key and key type
@@ -452,5 +967,42 @@ func (t *pcscClassicTag) Write(sector, block uint8, data []byte, key []byte, key
 	return nil
 }
 
-// Ensure pcscClassicTag implements ClassicTag interface
-var _ ClassicTag = (*pcscClassicTag)(nil)
+// ReadManufacturerBlock reads block 0 with the factory key and decodes it.
+// This implements the ClassicTag interface.
+func (t *pcscClassicTag) ReadManufacturerBlock() (uid []byte, sak, atqa []byte, bccValid bool, err error) {
+	tagUID, err := hex.DecodeString(t.uid)
+	if err != nil {
+		return nil, nil, nil, false, fmt.Errorf("invalid tag UID %q: %w", t.uid, err)
+	}
+
+	block, err := t.Read(0, 0, FactoryKey[:], KeyTypeA)
+	if err != nil {
+		return nil, nil, nil, false, fmt.Errorf("failed to read manufacturer block: %w", err)
+	}
+
+	return ParseManufacturerBlock(block, len(tagUID))
+}
+
+// ReadMADInfo reads and decodes the MAD with the public MAD key.
+// This implements the ClassicTag interface.
+func (t *pcscClassicTag) ReadMADInfo() ([]MADEntry, error) {
+	return readMADEntries(func(sector, block uint8) ([]byte, error) {
+		return t.Read(sector, block, KeyMAD, KeyTypeA)
+	}, t.sectorCount())
+}
+
+// ReadGPB reads the general purpose byte of a sector trailer.
+// This implements the ClassicTag interface.
+func (t *pcscClassicTag) ReadGPB(sector uint8, key []byte, keyType int) (byte, error) {
+	return readTrailerGPB(func(sector, block uint8) ([]byte, error) {
+		return t.Read(sector, block, key, keyType)
+	}, sector)
+}
+
+// Ensure pcscClassicTag implements the optional tag interfaces
+var (
+	_ ClassicTag     = (*pcscClassicTag)(nil)
+	_ AdvancedWriter = (*pcscClassicTag)(nil)
+	_ AdvancedLocker = (*pcscClassicTag)(nil)
+	_ NDEFFormatter  = (*pcscClassicTag)(nil)
+)
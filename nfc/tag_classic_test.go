@@ -3,49 +3,252 @@ package nfc
 import (
 	"bytes"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/ebfe/scard"
 )
 
 // mockScardCard simulates a scard.Card for testing
 type mockScardCard struct {
 	// responses maps command hex strings to response hex strings
 	responses map[string]string
+	// queued maps command hex strings to responses returned once each, in
+	// order, before falling back to responses
+	queued map[string][]string
 	// callLog records all transmitted commands
 	callLog [][]byte
 	// authenticatedSector tracks which sector is currently authenticated
 	authenticatedSector int
+	// protocol is reported by ActiveProtocol
+	protocol scard.Protocol
+	// transmitErr, if set, is returned by every Transmit call
+	transmitErr error
+	// atr is reported by Status
+	atr []byte
+	// pages, if set, backs PC/SC READ/UPDATE BINARY of 4-byte Type 2 pages
+	pages []byte
+	// type1Mem, if set, backs Type 1 RALL and WRITE-E sent via direct transmit
+	type1Mem []byte
+	// acceptUpdates answers 90 00 to every unscripted UPDATE BINARY
+	acceptUpdates bool
+	// classicMem, if set, backs MIFARE Classic READ/UPDATE BINARY of 16-byte
+	// blocks and accepts every key. Trailers with the NDEF access bits
+	// (7F0788) can only be written after authenticating with Key B.
+	classicMem []byte
+	// classicKeyType is the key type of the last classicMem authentication
+	classicKeyType byte
+	// type4File, if set, backs the Type 4 NDEF file E104 behind a CC naming it
+	type4File []byte
+	// type4Selected is the Type 4 file last selected
+	type4Selected string
 }
 
 func newMockScardCard() *mockScardCard {
 	return &mockScardCard{
 		responses:           make(map[string]string),
+		queued:              make(map[string][]string),
 		callLog:             make([][]byte, 0),
 		authenticatedSector: -1,
+		protocol:            scard.ProtocolT1,
 	}
 }
 
+// ActiveProtocol reports the negotiated protocol
+func (m *mockScardCard) ActiveProtocol() scard.Protocol {
+	return m.protocol
+}
+
+// Status reports the mock ATR
+func (m *mockScardCard) Status() (*scard.CardStatus, error) {
+	return &scard.CardStatus{Atr: m.atr}, nil
+}
+
+// Disconnect is a no-op
+func (m *mockScardCard) Disconnect(d scard.Disposition) error {
+	return nil
+}
+
 // Transmit simulates sending an APDU command
 func (m *mockScardCard) Transmit(cmd []byte) ([]byte, error) {
 	m.callLog = append(m.callLog, cmd)
 
+	if m.transmitErr != nil {
+		return nil, m.transmitErr
+	}
+
+	if m.pages != nil {
+		if resp, ok := m.transmitPages(cmd); ok {
+			return resp, nil
+		}
+	}
+
+	if m.type1Mem != nil {
+		if resp, ok := m.transmitType1(cmd); ok {
+			return resp, nil
+		}
+	}
+
+	if m.classicMem != nil {
+		if resp, ok := m.transmitClassic(cmd); ok {
+			return resp, nil
+		}
+	}
+
+	if m.type4File != nil {
+		if resp, ok := m.transmitType4(cmd); ok {
+			return resp, nil
+		}
+	}
+
 	// Convert command to hex for lookup
 	cmdHex := hex.EncodeToString(cmd)
 
+	if queue := m.queued[cmdHex]; len(queue) > 0 {
+		m.queued[cmdHex] = queue[1:]
+		return hex.DecodeString(queue[0])
+	}
+
 	// Check for exact match first
 	if respHex, ok := m.responses[cmdHex]; ok {
 		return hex.DecodeString(respHex)
 	}
 
+	if m.acceptUpdates && len(cmd) > 1 && cmd[0] == CLAPCSC && cmd[1] == INSUpdateBin {
+		return []byte{0x90, 0x00}, nil
+	}
+
 	// Default: return error status
 	return []byte{0x6A, 0x82}, nil
 }
 
+// transmitPages serves READ BINARY (FF B0 00 page 04) and UPDATE BINARY
+// (FF D6 00 page 04 data) from pages. Pages beyond the memory answer 6A82.
+func (m *mockScardCard) transmitPages(cmd []byte) ([]byte, bool) {
+	if len(cmd) < 5 || cmd[0] != CLAPCSC || (cmd[1] != INSReadBinary && cmd[1] != INSUpdateBin) {
+		return nil, false
+	}
+	offset := int(cmd[3]) * 4
+	if offset+4 > len(m.pages) {
+		return []byte{0x6A, 0x82}, true
+	}
+	if cmd[1] == INSReadBinary {
+		return append(append([]byte{}, m.pages[offset:offset+4]...), 0x90, 0x00), true
+	}
+	if len(cmd) != 9 {
+		return []byte{0x67, 0x00}, true
+	}
+	copy(m.pages[offset:offset+4], cmd[5:9])
+	return []byte{0x90, 0x00}, true
+}
+
+// transmitType1 serves Type 1 RALL and WRITE-E wrapped in direct transmit
+// (FF 00 00 00 Lc cmd 00) from type1Mem, reporting HR0 HR1 = 11 48.
+func (m *mockScardCard) transmitType1(cmd []byte) ([]byte, bool) {
+	if len(cmd) < 6 || cmd[0] != CLAPCSC || cmd[1] != INSDirectCmd {
+		return nil, false
+	}
+	native := cmd[5 : len(cmd)-1]
+	switch native[0] {
+	case type1CmdRALL:
+		resp := append([]byte{0x11, 0x48}, m.type1Mem...)
+		return append(resp, 0x90, 0x00), true
+	case type1CmdWriteE:
+		addr := int(native[1])
+		if addr >= len(m.type1Mem) {
+			return []byte{0x6A, 0x82}, true
+		}
+		m.type1Mem[addr] = native[2]
+		return []byte{native[1], native[2], 0x90, 0x00}, true
+	}
+	return nil, false
+}
+
+// transmitClassic serves LOAD KEY and GENERAL AUTHENTICATE with success, and
+// READ BINARY (FF B0 00 block 10) and UPDATE BINARY (FF D6 00 block 10 data)
+// from classicMem.
+func (m *mockScardCard) transmitClassic(cmd []byte) ([]byte, bool) {
+	if len(cmd) < 5 || cmd[0] != CLAPCSC {
+		return nil, false
+	}
+	switch cmd[1] {
+	case INSLoadKey:
+		return []byte{0x90, 0x00}, true
+	case INSAuth:
+		if len(cmd) >= 9 {
+			m.classicKeyType = cmd[8]
+		}
+		return []byte{0x90, 0x00}, true
+	case INSReadBinary, INSUpdateBin:
+	default:
+		return nil, false
+	}
+	offset := int(cmd[3]) * 16
+	if offset+16 > len(m.classicMem) {
+		return []byte{0x6A, 0x82}, true
+	}
+	if cmd[1] == INSReadBinary {
+		return append(append([]byte{}, m.classicMem[offset:offset+16]...), 0x90, 0x00), true
+	}
+	if len(cmd) != 21 {
+		return []byte{0x67, 0x00}, true
+	}
+	if cmd[3]%4 == 3 && bytes.Equal(m.classicMem[offset+6:offset+9], ndefSectorAccess[:]) && m.classicKeyType != KeyTypeB {
+		return []byte{0x69, 0x82}, true
+	}
+	copy(m.classicMem[offset:offset+16], cmd[5:21])
+	return []byte{0x90, 0x00}, true
+}
+
+// type4CC is a CC naming a 255-byte NDEF file E104 with free read and write access.
+var type4CC = []byte{0x00, 0x0F, 0x20, 0x00, 0x3B, 0x00, 0x34, 0x04, 0x06, 0xE1, 0x04, 0x00, 0xFF, 0x00, 0x00}
+
+// transmitType4 serves SELECT, READ BINARY and UPDATE BINARY of the CC and
+// NDEF files from type4CC and type4File. Every other SELECT succeeds.
+func (m *mockScardCard) transmitType4(cmd []byte) ([]byte, bool) {
+	if len(cmd) < 5 || cmd[0] != CLAStandard {
+		return nil, false
+	}
+	offset := int(cmd[2])<<8 | int(cmd[3])
+	switch cmd[1] {
+	case INSSelectFile:
+		m.type4Selected = hex.EncodeToString(cmd[5 : 5+int(cmd[4])])
+		return []byte{0x90, 0x00}, true
+	case INSReadBinary:
+		file := m.type4File
+		if m.type4Selected == "e103" {
+			file = type4CC
+		}
+		if offset >= len(file) {
+			return []byte{0x6B, 0x00}, true
+		}
+		end := min(offset+int(cmd[4]), len(file))
+		return append(append([]byte{}, file[offset:end]...), 0x90, 0x00), true
+	case INSUpdateBin:
+		data := cmd[5 : 5+int(cmd[4])]
+		if m.type4Selected != "e104" || offset+len(data) > len(m.type4File) {
+			return []byte{0x6A, 0x82}, true
+		}
+		copy(m.type4File[offset:], data)
+		return []byte{0x90, 0x00}, true
+	}
+	return nil, false
+}
+
 // addResponse adds a response for a command
 func (m *mockScardCard) addResponse(cmdHex, respHex string) {
 	m.responses[cmdHex] = respHex
 }
 
+// queueResponses queues responses returned in order for repeated sends of a command
+func (m *mockScardCard) queueResponses(cmdHex string, respHex ...string) {
+	m.queued[cmdHex] = append(m.queued[cmdHex], respHex...)
+}
+
 // setupClassicTagResponses sets up responses for a basic MIFARE Classic tag
 func (m *mockScardCard) setupClassicTagResponses() {
 	// Load key command: FF 82 00 00 06 [key]
@@ -300,6 +503,8 @@ func TestNewPCSCClassicTag(t *testing.T) {
 	}{
 		{"1K tag", DetectedClassic1K, false},
 		{"4K tag", DetectedClassic4K, true},
+		{"Plus 2K SL1 tag", DetectedPlus2K, false},
+		{"Plus 4K SL1 tag", DetectedPlus4K, true},
 	}
 
 	for _, tt := range tests {
@@ -461,3 +666,431 @@ func TestMockClassicTagImplementsInterface(t *testing.T) {
 		t.Errorf("Written data mismatch")
 	}
 }
+
+func TestClassicKeyMap(t *testing.T) {
+	customKey := []byte{0x11, 0x22, 0x33, 0x44, 0x55, 0x66}
+	provider := ClassicKeyMap(map[int]ClassicSectorKey{
+		2: {Key: customKey, KeyType: KeyTypeB},
+	})
+
+	key, ok := provider(2)
+	if !ok {
+		t.Fatal("Expected key for sector 2")
+	}
+	if !bytes.Equal(key.Key, customKey) {
+		t.Errorf("Expected key %X, got %X", customKey, key.Key)
+	}
+	if key.KeyType != KeyTypeB {
+		t.Errorf("Expected KeyTypeB, got 0x%02X", key.KeyType)
+	}
+
+	if _, ok := provider(1); ok {
+		t.Error("Expected no key for sector 1 so the default keys are used")
+	}
+}
+
+// TestClassicTag_WriteSectorKeys tests that a write authenticates a sector
+// with its configured key and key type, and other sectors with the default keys.
+func TestClassicTag_WriteSectorKeys(t *testing.T) {
+	customKey := []byte{0x11, 0x22, 0x33, 0x44, 0x55, 0x66}
+	card := newMockScardCard()
+	card.classicMem = make([]byte, 64*16)
+	tag := newPCSCClassicTag(newMockPCSCDevice(card, pcscATR(0x01)), "04A1B2C3", DetectedClassic1K)
+
+	// 60 characters spill from sector 1 into sector 2
+	data := EncodeNdefMessageWithTextRecord(strings.Repeat("A", 60), "en")
+	opts := TagWriteOptions{SectorKeys: ClassicKeyMap(map[int]ClassicSectorKey{
+		2: {Key: customKey, KeyType: KeyTypeB},
+	})}
+	if err := tag.WriteDataWithOptions(data, opts); err != nil {
+		t.Fatalf("WriteDataWithOptions() failed: %v", err)
+	}
+
+	// Record the key loaded before each authentication, by trailer block
+	type auth struct {
+		key     string
+		keyType byte
+	}
+	auths := make(map[byte]auth)
+	var loaded string
+	for _, cmd := range card.callLog {
+		switch cmd[1] {
+		case INSLoadKey:
+			loaded = hex.EncodeToString(cmd[5:11])
+		case INSAuth:
+			auths[cmd[7]] = auth{loaded, cmd[8]}
+		}
+	}
+
+	if got, want := auths[7], (auth{hex.EncodeToString(classicDefaultKeys[0]), KeyTypeA}); got != want {
+		t.Errorf("Sector 1 authenticated with %+v, want default %+v", got, want)
+	}
+	if got, want := auths[11], (auth{hex.EncodeToString(customKey), KeyTypeB}); got != want {
+		t.Errorf("Sector 2 authenticated with %+v, want configured %+v", got, want)
+	}
+
+	tlv := TLVEncode(data, TLVNDEF)
+	if !bytes.Equal(card.classicMem[4*16:7*16], tlv[:48]) || !bytes.Equal(card.classicMem[8*16:8*16+len(tlv)-48], tlv[48:]) {
+		t.Error("NDEF TLV was not written across sectors 1 and 2")
+	}
+}
+
+// TestClassicTag_ReadDataBestEffort tests that an unreadable sector in the
+// middle of the message fails a normal read but is skipped and zero-filled by a
+// best-effort read.
+func TestClassicTag_ReadDataBestEffort(t *testing.T) {
+	ndef := EncodeNdefMessageWithTextRecord(strings.Repeat("A", 100), "en")
+	area := TLVEncode(ndef, TLVNDEF)
+	for len(area)%16 != 0 {
+		area = append(area, 0x00)
+	}
+
+	card := newMockScardCard()
+	card.addResponse(hex.EncodeToString(LoadKeyAPDU(0x00, classicDefaultKeys[0])), "9000")
+	card.addResponse(hex.EncodeToString(GetUIDAPDU()), "04a1b2c39000")
+
+	// Sectors 1 and 3 authenticate; sector 2 (blocks 8-10) accepts no key
+	blocks := []int{4, 5, 6, 8, 9, 10, 12, 13, 14}
+	for i, block := range blocks {
+		if block/4 == 2 || (i+1)*16 > len(area) {
+			continue
+		}
+		card.addResponse(hex.EncodeToString(ReadBinaryAPDU(byte(block), 16)), hex.EncodeToString(area[i*16:(i+1)*16])+"9000")
+	}
+	for _, sector := range []int{1, 3} {
+		card.addResponse(hex.EncodeToString(MIFAREAuthAPDU(byte(sector*4+3), MIFAREKeyA, 0x00)), "9000")
+	}
+
+	dev := newMockPCSCDevice(card, pcscATR(0x01))
+	dev.ctx = &scard.Context{} // checkCardPresence only requires a context
+	tag := newPCSCClassicTag(dev, "04A1B2C3", DetectedClassic1K)
+
+	if _, err := tag.ReadData(); err == nil {
+		t.Fatal("Expected ReadData to fail on the unreadable sector")
+	}
+
+	data, skipped, err := tag.ReadDataBestEffort()
+	if err != nil {
+		t.Fatalf("ReadDataBestEffort() failed: %v", err)
+	}
+	if len(skipped) != 1 || skipped[0] != 2 {
+		t.Errorf("skipped = %v, want [2]", skipped)
+	}
+	if len(data) != len(ndef) {
+		t.Fatalf("data length = %d, want %d", len(data), len(ndef))
+	}
+
+	// Sector 2 covers bytes 48-95 of the data area; the TLV header is 2 bytes
+	want := append([]byte{}, ndef...)
+	copy(want[46:94], make([]byte, 48))
+	if !bytes.Equal(data, want) {
+		t.Errorf("data = %X\nwant   %X", data, want)
+	}
+}
+
+// TestClassicTag_WriteWithLayout tests that a write given the layout of the
+// last read keeps the TLVs before the NDEF TLV and only rewrites changed blocks.
+func TestClassicTag_WriteWithLayout(t *testing.T) {
+	ndef := EncodeNdefMessageWithTextRecord(strings.Repeat("A", 60), "en")
+	area := append([]byte{TLVLockCtrl, 0x03, 0xA0, 0x10, 0x44}, TLVEncode(ndef, TLVNDEF)...)
+	for len(area)%16 != 0 {
+		area = append(area, 0x00)
+	}
+
+	card := newMockScardCard()
+	card.addResponse(hex.EncodeToString(LoadKeyAPDU(0x00, classicDefaultKeys[0])), "9000")
+	card.addResponse(hex.EncodeToString(MIFAREAuthAPDU(7, MIFAREKeyA, 0x00)), "9000")
+	card.addResponse(hex.EncodeToString(MIFAREAuthAPDU(11, MIFAREKeyA, 0x00)), "9000")
+	blocks := []int{4, 5, 6, 8, 9}
+	for i, block := range blocks {
+		card.addResponse(hex.EncodeToString(ReadBinaryAPDU(byte(block), 16)), hex.EncodeToString(area[i*16:(i+1)*16])+"9000")
+	}
+	tag := newPCSCClassicTag(newMockPCSCDevice(card, pcscATR(0x01)), "04A1B2C3", DetectedClassic1K)
+
+	data, layout, err := tag.ReadDataWithLayout()
+	if err != nil {
+		t.Fatalf("ReadDataWithLayout() failed: %v", err)
+	}
+	if !bytes.Equal(data, ndef) {
+		t.Fatalf("data = %X, want %X", data, ndef)
+	}
+	if layout == nil || layout.Offset != 5 || layout.Length != len(ndef)+2 {
+		t.Fatalf("layout = %+v, want offset 5 and length %d", layout, len(ndef)+2)
+	}
+
+	// Change the last character of the text, which lives in block 9
+	updated := append([]byte{}, ndef...)
+	updated[len(updated)-1] = 'B'
+	changed := append([]byte{}, area...)
+	changed[5+2+len(ndef)-1] = 'B'
+	card.addResponse(hex.EncodeToString(UpdateBinaryAPDU(9, changed[64:80])), "9000")
+
+	card.callLog = nil
+	if err := tag.WriteDataWithOptions(updated, TagWriteOptions{Layout: layout}); err != nil {
+		t.Fatalf("WriteDataWithOptions() failed: %v", err)
+	}
+	var writes []byte
+	for _, cmd := range card.callLog {
+		if cmd[1] == INSUpdateBin {
+			writes = append(writes, cmd[3])
+		}
+	}
+	if !bytes.Equal(writes, []byte{9}) {
+		t.Errorf("Blocks written = %v, want [9]", writes)
+	}
+}
+
+// TestClassicTag_WriteWipeTrailing tests that WipeTrailing zero-fills every
+// data block after the message, leaving sector trailers alone.
+func TestClassicTag_WriteWipeTrailing(t *testing.T) {
+	card := newMockScardCard()
+	card.addResponse(hex.EncodeToString(LoadKeyAPDU(0x00, classicDefaultKeys[0])), "9000")
+	for sector := 1; sector < 16; sector++ {
+		card.addResponse(hex.EncodeToString(MIFAREAuthAPDU(byte(sector*4+3), MIFAREKeyA, 0x00)), "9000")
+	}
+
+	data := EncodeNdefMessageWithTextRecord("Hi", "en")
+	block := append(TLVEncode(data, TLVNDEF), make([]byte, 16)...)[:16]
+	card.addResponse(hex.EncodeToString(UpdateBinaryAPDU(4, block)), "9000")
+	for b := 5; b < 64; b++ {
+		card.addResponse(hex.EncodeToString(UpdateBinaryAPDU(byte(b), make([]byte, 16))), "9000")
+	}
+	tag := newPCSCClassicTag(newMockPCSCDevice(card, pcscATR(0x01)), "04A1B2C3", DetectedClassic1K)
+
+	if err := tag.WriteDataWithOptions(data, TagWriteOptions{WipeTrailing: true}); err != nil {
+		t.Fatalf("WriteDataWithOptions() failed: %v", err)
+	}
+
+	var written []int
+	for _, cmd := range card.callLog {
+		if cmd[1] == INSUpdateBin {
+			written = append(written, int(cmd[3]))
+		}
+	}
+	if len(written) != 45 || written[0] != 4 || written[len(written)-1] != 62 {
+		t.Errorf("Blocks written = %v, want every data block from 4 to 62", written)
+	}
+	for _, b := range written {
+		if (b+1)%4 == 0 {
+			t.Errorf("Sector trailer %d was written", b)
+		}
+	}
+}
+
+// TestClassicTag_WriteDelay tests that the device's Classic write delay is
+// waited between block writes but not before the first.
+func TestClassicTag_WriteDelay(t *testing.T) {
+	card := newMockScardCard()
+	card.classicMem = make([]byte, 64*16)
+	dev := newMockPCSCDevice(card, pcscATR(0x01))
+	dev.classicWriteDelay = 20 * time.Millisecond
+	tag := newPCSCClassicTag(dev, "04A1B2C3", DetectedClassic1K)
+
+	// 3 blocks of TLV
+	data := EncodeNdefMessageWithTextRecord(strings.Repeat("a", 30), "en")
+	start := time.Now()
+	if err := tag.WriteData(data); err != nil {
+		t.Fatalf("WriteData() failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("Writing 3 blocks took %v, want at least two 20ms delays", elapsed)
+	}
+
+	got, err := tag.ReadData()
+	if err != nil || !bytes.Equal(got, data) {
+		t.Errorf("ReadData() = %X, %v, want %X", got, err, data)
+	}
+}
+
+// TestClassicTag_MakeReadOnlyNDEFTrailer tests that NDEF-formatted sectors,
+// whose trailers (access bits 7F0788) only accept Key B, are locked with the
+// default Key B or a Key B configured for the sector.
+func TestClassicTag_MakeReadOnlyNDEFTrailer(t *testing.T) {
+	customKeyB := []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06}
+	newCard := func() *mockScardCard {
+		card := newMockScardCard()
+		card.classicMem = make([]byte, 64*16)
+		for sector := 1; sector < 16; sector++ {
+			copy(card.classicMem[(sector*4+3)*16:], buildSectorTrailer(KeyNFCForum, ndefSectorAccess, ndefSectorGPB, KeyDefault))
+		}
+		return card
+	}
+	lockedSectors := func(card *mockScardCard) int {
+		locked := 0
+		for sector := 1; sector < 16; sector++ {
+			offset := (sector*4 + 3) * 16
+			if bytes.Equal(card.classicMem[offset+6:offset+9], ndefReadOnlyAccess[:]) {
+				locked++
+			}
+		}
+		return locked
+	}
+
+	t.Run("default key", func(t *testing.T) {
+		card := newCard()
+		tag := newPCSCClassicTag(newMockPCSCDevice(card, pcscATR(0x01)), "04A1B2C3", DetectedClassic1K)
+		if err := tag.MakeReadOnly(); err != nil {
+			t.Fatalf("MakeReadOnly() failed: %v", err)
+		}
+		if got := lockedSectors(card); got != 15 {
+			t.Errorf("Locked %d sectors, want 15", got)
+		}
+	})
+
+	t.Run("key A fails", func(t *testing.T) {
+		card := newCard()
+		tag := newPCSCClassicTag(newMockPCSCDevice(card, pcscATR(0x01)), "04A1B2C3", DetectedClassic1K)
+		err := tag.MakeReadOnlyWithOptions(ReadOnlyOptions{TrailerKey: KeyNFCForum, TrailerKeyType: KeyTypeA})
+		if err == nil {
+			t.Fatal("Expected locking with Key A to fail")
+		}
+		if got := lockedSectors(card); got != 0 {
+			t.Errorf("Locked %d sectors, want none", got)
+		}
+	})
+
+	t.Run("configured sector key", func(t *testing.T) {
+		card := newCard()
+		tag := newPCSCClassicTag(newMockPCSCDevice(card, pcscATR(0x01)), "04A1B2C3", DetectedClassic1K)
+		keys := ClassicKeyMap(map[int]ClassicSectorKey{
+			1: {Key: customKeyB, KeyType: KeyTypeB},
+			2: {Key: KeyNFCForum, KeyType: KeyTypeA}, // Cannot write the trailer, skipped
+		})
+		if err := tag.MakeReadOnlyWithOptions(ReadOnlyOptions{SectorKeys: keys}); err != nil {
+			t.Fatalf("MakeReadOnlyWithOptions() failed: %v", err)
+		}
+		var used []string
+		for _, cmd := range card.callLog {
+			if cmd[1] == INSLoadKey {
+				used = append(used, hex.EncodeToString(cmd[5:]))
+			}
+		}
+		if !slices.Contains(used, hex.EncodeToString(customKeyB)) || slices.Contains(used, hex.EncodeToString(KeyNFCForum)) {
+			t.Errorf("Loaded keys %v, want the configured Key B and no Key A", used)
+		}
+		if got := lockedSectors(card); got != 15 {
+			t.Errorf("Locked %d sectors, want 15", got)
+		}
+	})
+}
+
+// addMAD scripts a 1K card whose MAD assigns sectors 1-15 the AIDs in aids
+// (NDEF for sectors not listed).
+func (m *mockScardCard) addMAD(aids map[int]uint16) {
+	list := make([]uint16, mad1Sectors)
+	for i := range list {
+		list[i] = MADAIDNDEF
+		if aid, ok := aids[i+1]; ok {
+			list[i] = aid
+		}
+	}
+	mad := encodeMAD(madInfoByte, list)
+	trailer := buildSectorTrailer(KeyMAD, madSectorAccess, madGPBv1, KeyDefault)
+
+	m.addResponse(hex.EncodeToString(LoadKeyAPDU(0x00, KeyMAD)), "9000")
+	m.addResponse(hex.EncodeToString(MIFAREAuthAPDU(3, MIFAREKeyA, 0x00)), "9000")
+	m.addResponse(hex.EncodeToString(ReadBinaryAPDU(3, 16)), hex.EncodeToString(trailer)+"9000")
+	m.addResponse(hex.EncodeToString(ReadBinaryAPDU(1, 16)), hex.EncodeToString(mad[:16])+"9000")
+	m.addResponse(hex.EncodeToString(ReadBinaryAPDU(2, 16)), hex.EncodeToString(mad[16:])+"9000")
+}
+
+// TestClassicTag_WriteClassicSectors tests that ClassicSectors keeps the NDEF
+// data within the selected sectors and updates the MAD to list only them.
+func TestClassicTag_WriteClassicSectors(t *testing.T) {
+	newTag := func(aids map[int]uint16) (*pcscClassicTag, *mockScardCard) {
+		card := newMockScardCard()
+		card.addMAD(aids)
+		card.addResponse(hex.EncodeToString(LoadKeyAPDU(0x00, classicDefaultKeys[0])), "9000")
+		card.addResponse(hex.EncodeToString(MIFAREAuthAPDU(3, MIFAREKeyB, 0x00)), "9000")
+		for sector := 1; sector < 16; sector++ {
+			card.addResponse(hex.EncodeToString(MIFAREAuthAPDU(byte(sector*4+3), MIFAREKeyA, 0x00)), "9000")
+		}
+		card.acceptUpdates = true
+		return newPCSCClassicTag(newMockPCSCDevice(card, pcscATR(0x01)), "04A1B2C3", DetectedClassic1K), card
+	}
+	writes := func(card *mockScardCard) map[int][]byte {
+		written := make(map[int][]byte)
+		for _, cmd := range card.callLog {
+			if cmd[1] == INSUpdateBin {
+				written[int(cmd[3])] = cmd[5:]
+			}
+		}
+		return written
+	}
+
+	t.Run("writes selected sectors and updates MAD", func(t *testing.T) {
+		tag, card := newTag(map[int]uint16{2: 0x4801})
+
+		data := EncodeNdefMessageWithTextRecord(strings.Repeat("A", 60), "en")
+		if err := tag.WriteDataWithOptions(data, TagWriteOptions{ClassicSectors: []int{6, 5}}); err != nil {
+			t.Fatalf("WriteDataWithOptions() failed: %v", err)
+		}
+
+		written := writes(card)
+		for block := range written {
+			if block > 2 && (block < 20 || block > 26) {
+				t.Errorf("Block %d outside sectors 5-6 was written", block)
+			}
+		}
+		area := TLVEncode(data, TLVNDEF)
+		if got := written[20]; !bytes.Equal(got, area[:16]) {
+			t.Errorf("Block 20 = %X, want %X", got, area[:16])
+		}
+
+		aids := make([]uint16, mad1Sectors)
+		aids[1] = 0x4801
+		aids[4], aids[5] = MADAIDNDEF, MADAIDNDEF
+		mad := encodeMAD(madInfoByte, aids)
+		if got := append(written[1], written[2]...); !bytes.Equal(got, mad) {
+			t.Errorf("MAD = %X, want %X", got, mad)
+		}
+	})
+
+	t.Run("message too large", func(t *testing.T) {
+		tag, card := newTag(nil)
+		data := EncodeNdefMessageWithTextRecord(strings.Repeat("A", 100), "en")
+		err := tag.WriteDataWithOptions(data, TagWriteOptions{ClassicSectors: []int{5, 6}})
+		if !errors.Is(err, ErrCardFull) {
+			t.Fatalf("WriteDataWithOptions() error = %v, want ErrCardFull", err)
+		}
+		if written := writes(card); len(written) > 0 {
+			t.Errorf("Blocks written = %v, want none", written)
+		}
+	})
+
+	t.Run("sector of another application", func(t *testing.T) {
+		tag, _ := newTag(map[int]uint16{5: 0x4801})
+		err := tag.WriteDataWithOptions([]byte{0xD0, 0x00, 0x00}, TagWriteOptions{ClassicSectors: []int{5, 6}})
+		if err == nil || !strings.Contains(err.Error(), "sector 5 is assigned to application 0x4801") {
+			t.Errorf("WriteDataWithOptions() error = %v, want sector 5 refused", err)
+		}
+	})
+}
+
+// TestClassicTag_ReadGPB tests reading trailer GPBs, and that sectors whose
+// trailer no known key opens are left out.
+func TestClassicTag_ReadGPB(t *testing.T) {
+	card := newMockScardCard()
+	card.addResponse(hex.EncodeToString(LoadKeyAPDU(0x00, KeyNFCForum)), "9000")
+	card.addResponse(hex.EncodeToString(MIFAREAuthAPDU(0x07, MIFAREKeyA, 0x00)), "9000")
+	trailer := buildSectorTrailer(KeyNFCForum, ndefSectorAccess, ndefSectorGPB, KeyDefault)
+	card.addResponse(hex.EncodeToString(ReadBinaryAPDU(0x07, 16)), hex.EncodeToString(trailer)+"9000")
+	tag := newPCSCClassicTag(newMockPCSCDevice(card, pcscATR(0x01)), "04A1B2C3", DetectedClassic1K)
+
+	gpb, err := tag.ReadGPB(1, KeyNFCForum, KeyTypeA)
+	if err != nil {
+		t.Fatalf("ReadGPB() error = %v", err)
+	}
+	if gpb != ndefSectorGPB {
+		t.Errorf("ReadGPB() = %02X, want %02X", gpb, ndefSectorGPB)
+	}
+
+	if _, err := tag.ReadGPB(2, KeyNFCForum, KeyTypeA); err == nil {
+		t.Error("Expected an error for a sector that does not authenticate")
+	}
+
+	gpbs := readSectorGPBs(tag, 16)
+	if len(gpbs) != 1 || gpbs[1] != ndefSectorGPB {
+		t.Errorf("readSectorGPBs() = %v, want only sector 1", gpbs)
+	}
+}
@@ -111,3 +111,57 @@ func (t *pcscDESFireTag) CanMakeReadOnly() (bool, error) {
 func (t *pcscDESFireTag) MakeReadOnly() error {
 	return fmt.Errorf("DESFire MakeReadOnly not supported")
 }
+
+// command sends a wrapped DESFire native command and collects the response,
+// following additional frames. Native status is reported as SW1=91 SW2=status,
+// which transceive would reject, so the device is used directly.
+func (t *pcscDESFireTag) command(apdu []byte) ([]byte, error) {
+	var data []byte
+	for {
+		resp, err := t.device.Transceive(apdu)
+		if err != nil {
+			return nil, err
+		}
+		parsed, err := ParseAPDUResponse(resp)
+		if err != nil {
+			return nil, err
+		}
+		if parsed.SW1 != SW1DESFire {
+			return nil, fmt.Errorf("unexpected DESFire response: SW1=%02X SW2=%02X", parsed.SW1, parsed.SW2)
+		}
+
+		data = append(data, parsed.Data...)
+		switch parsed.SW2 {
+		case DFStatusOK:
+			return data, nil
+		case DFStatusAdditionalFrame:
+			apdu = DESFireAdditionalFrameAPDU(nil)
+		default:
+			return nil, fmt.Errorf("DESFire error: status %02X", parsed.SW2)
+		}
+	}
+}
+
+// GetVersion reads the card's hardware and software versions and production
+// data. No authentication is needed.
+func (t *pcscDESFireTag) GetVersion() (DESFireVersion, error) {
+	data, err := t.command(DESFireGetVersionAPDU())
+	if err != nil {
+		return DESFireVersion{}, fmt.Errorf("GetVersion failed: %w", err)
+	}
+	return ParseDESFireVersion(data)
+}
+
+// FreeMemory returns the free EEPROM in bytes. EV0 cards do not support the
+// command.
+func (t *pcscDESFireTag) FreeMemory() (int, error) {
+	data, err := t.command(DESFireFreeMemAPDU())
+	if err != nil {
+		return 0, fmt.Errorf("FreeMem failed: %w", err)
+	}
+	if len(data) != 3 {
+		return 0, fmt.Errorf("FreeMem response must be 3 bytes, got %d", len(data))
+	}
+	// 24-bit little-endian
+	return int(data[0]) | int(data[1])<<8 | int(data[2])<<16, nil
+}
@@ -0,0 +1,112 @@
+package nfc
+
+import (
+	"encoding/hex"
+	"fmt"
+	"testing"
+)
+
+// desfireEV2Frames is a GetVersion response from a DESFire EV2 4K, split into
+// its three frames.
+var desfireEV2Frames = []string{
+	"0401011200180591af",
+	"0401010201180591af",
+	"04112233445566ba654b717025199100",
+}
+
+func TestParseDESFireVersion(t *testing.T) {
+	data, _ := hex.DecodeString("04010112001805" + "04010102011805" + "04112233445566ba654b71702519")
+
+	v, err := ParseDESFireVersion(data)
+	if err != nil {
+		t.Fatalf("ParseDESFireVersion() failed: %v", err)
+	}
+	if v.Variant() != "MIFARE DESFire EV2" {
+		t.Errorf("Variant() = %q, want MIFARE DESFire EV2", v.Variant())
+	}
+	if v.Hardware.StorageBytes() != 4096 {
+		t.Errorf("StorageBytes() = %d, want 4096", v.Hardware.StorageBytes())
+	}
+	if v.Software.Major != 0x02 || v.Software.Minor != 0x01 {
+		t.Errorf("Software version = %d.%d, want 2.1", v.Software.Major, v.Software.Minor)
+	}
+	if fmt.Sprintf("%X", v.UID) != "04112233445566" || fmt.Sprintf("%X", v.BatchNo) != "BA654B7170" {
+		t.Errorf("UID/BatchNo = %X/%X", v.UID, v.BatchNo)
+	}
+	if v.ProductionWeek != 25 || v.ProductionYear != 19 {
+		t.Errorf("Production = week %d of %d, want week 25 of 19", v.ProductionWeek, v.ProductionYear)
+	}
+
+	if _, err := ParseDESFireVersion(data[:27]); err == nil {
+		t.Error("Expected error for truncated response")
+	}
+}
+
+func TestDESFireVersion_Variant(t *testing.T) {
+	tests := []struct {
+		typ, major byte
+		want       string
+	}{
+		{0x01, 0x00, "MIFARE DESFire EV0"},
+		{0x01, 0x01, "MIFARE DESFire EV1"},
+		{0x01, 0x22, "MIFARE DESFire EV2 XL"},
+		{0x01, 0x33, "MIFARE DESFire EV3"},
+		{0x08, 0x30, "MIFARE DESFire Light"},
+		{0x01, 0x42, "MIFARE DESFire (hardware 66.0)"},
+	}
+
+	for _, tt := range tests {
+		v := DESFireVersion{Hardware: DESFireVersionPart{Type: tt.typ, Major: tt.major}}
+		if got := v.Variant(); got != tt.want {
+			t.Errorf("Variant() for type %02X major %02X = %q, want %q", tt.typ, tt.major, got, tt.want)
+		}
+	}
+}
+
+func TestPCSCDESFireTag_GetVersion(t *testing.T) {
+	card := newMockScardCard()
+	card.addResponse("9060000000", desfireEV2Frames[0])
+	card.queueResponses("90af000000", desfireEV2Frames[1:]...)
+	tag := newPCSCDESFireTag(newMockPCSCDevice(card, unknownATR), "04112233445566")
+
+	v, err := tag.GetVersion()
+	if err != nil {
+		t.Fatalf("GetVersion() failed: %v", err)
+	}
+	if v.Variant() != "MIFARE DESFire EV2" || fmt.Sprintf("%X", v.UID) != "04112233445566" {
+		t.Errorf("GetVersion() = %+v", v)
+	}
+	if len(card.callLog) != 3 {
+		t.Errorf("Expected 3 frames exchanged, got %d", len(card.callLog))
+	}
+}
+
+func TestPCSCDESFireTag_FreeMemory(t *testing.T) {
+	tests := []struct {
+		name    string
+		resp    string
+		want    int
+		wantErr bool
+	}{
+		{name: "free bytes", resp: "000a009100", want: 2560},
+		{name: "DESFire error", resp: "91ae", wantErr: true},
+		{name: "ISO error", resp: "6a82", wantErr: true},
+		{name: "short response", resp: "0a9100", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			card := newMockScardCard()
+			card.addResponse("906e000000", tt.resp)
+			tag := newPCSCDESFireTag(newMockPCSCDevice(card, unknownATR), "04112233445566")
+
+			got, err := tag.FreeMemory()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FreeMemory() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("FreeMemory() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
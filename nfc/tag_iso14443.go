@@ -7,18 +7,37 @@ import (
 // NDEF Application AID for Type 4 tags
 var ndefAppAID = []byte{0xD2, 0x76, 0x00, 0x00, 0x85, 0x01, 0x01}
 
+// ISO-DEP chunking limits for READ/UPDATE BINARY
+const (
+	defaultType4Chunk = 253 // Smallest chunk unless the CC's MLe/MLc asks for less
+	maxType4Chunk     = 255 // Largest Lc in a short APDU
+	isoDepOverhead    = 8   // PCB (1) + CRC (2) + APDU header (5) per frame
+	maxGetResponses   = 32  // GET RESPONSE rounds before a 61xx chain is given up
+)
+
+// fscTable maps FSCI (ATS T0 low nibble) to the card's max frame size in bytes.
+var fscTable = [...]int{16, 24, 32, 40, 48, 64, 96, 128, 256}
+
 type pcscISO14443Tag struct {
 	pcscBaseTag
+	frameSize int      // Max frame size from the ATS; 0 if unknown
+	frameRead bool     // Whether the ATS was queried for frameSize
+	mle, mlc  int      // CC's max READ/UPDATE BINARY data length; 0 until the CC is read
+	preSelect [][]byte // APDUs sent before selecting the NDEF application
 }
 
 func newPCSCISO14443Tag(dev *pcscDevice, uid string) *pcscISO14443Tag {
-	return &pcscISO14443Tag{
+	tag := &pcscISO14443Tag{
 		pcscBaseTag: pcscBaseTag{
 			device:       dev,
 			uid:          uid,
 			detectedType: DetectedISO14443_4,
 		},
 	}
+	if dev != nil {
+		tag.preSelect = dev.type4PreSelect
+	}
+	return tag
 }
 
 func (t *pcscISO14443Tag) Type() string {
@@ -37,33 +56,108 @@ func (t *pcscISO14443Tag) Transceive(data []byte) ([]byte, error) {
 	return t.transceive(data)
 }
 
-func (t *pcscISO14443Tag) ReadData() ([]byte, error) {
+// parseATSFrameSize returns the max frame size (FSC) the card announces in its
+// ATS, or 0 if the ATS has no format byte. FSCI values above 8 are RFU in
+// older revisions of ISO 14443-4 and are treated as 256.
+func parseATSFrameSize(ats []byte) int {
+	// TL (1) | T0 (1) | TA, TB, TC | historical bytes
+	if len(ats) < 2 || ats[0] < 2 {
+		return 0
+	}
+	fsci := int(ats[1] & 0x0F)
+	if fsci >= len(fscTable) {
+		return fscTable[len(fscTable)-1]
+	}
+	return fscTable[fsci]
+}
+
+// type4ChunkSize returns the READ/UPDATE BINARY data length for a card with
+// frames of frameSize bytes (0 if unknown) whose CC allows ml bytes per
+// command (MLe for reads, MLc for writes; 0 if unknown). Frames holding more
+// than defaultType4Chunk raise it up to maxType4Chunk; only ml lowers it,
+// since the reader chains frames smaller than a command.
+func type4ChunkSize(frameSize, ml int) int {
+	chunk := min(max(frameSize-isoDepOverhead, defaultType4Chunk), maxType4Chunk)
+	if ml > 0 {
+		chunk = min(chunk, ml)
+	}
+	return chunk
+}
+
+// readChunk returns the READ BINARY length for this card.
+func (t *pcscISO14443Tag) readChunk() int {
+	return type4ChunkSize(t.atsFrameSize(), t.mle)
+}
+
+// writeChunk returns the UPDATE BINARY data length for this card.
+func (t *pcscISO14443Tag) writeChunk() int {
+	return type4ChunkSize(t.atsFrameSize(), t.mlc)
+}
+
+// atsFrameSize returns the card's max frame size, reading the ATS through the
+// reader on first use. Readers that can't report the ATS get 0.
+func (t *pcscISO14443Tag) atsFrameSize() int {
+	if t.frameRead {
+		return t.frameSize
+	}
+	t.frameRead = true
+
+	if t.device != nil {
+		if resp, err := t.transmitRaw(GetATSAPDU()); err == nil {
+			if parsed, err := ParseAPDUResponse(resp); err == nil && parsed.IsSuccess() {
+				t.frameSize = parseATSFrameSize(parsed.Data)
+			}
+		}
+	}
+	return t.frameSize
+}
+
+// runPreSelect sends the configured pre-SELECT APDUs in order. It runs before
+// every selection of the NDEF application; any APDU failing aborts it.
+func (t *pcscISO14443Tag) runPreSelect() error {
+	for i, apdu := range t.preSelect {
+		if _, err := t.transceive(apdu); err != nil {
+			return fmt.Errorf("pre-select APDU %d failed: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// selectNDEFFile selects the NDEF application and the NDEF file named in the CC,
+// after the configured pre-SELECT APDUs.
+func (t *pcscISO14443Tag) selectNDEFFile() error {
+	if err := t.runPreSelect(); err != nil {
+		return err
+	}
+
 	// Select NDEF application
 	selectAppCmd := SelectFileByAIDAPDU(ndefAppAID)
 	_, err := t.transceive(selectAppCmd)
 	if err != nil {
-		return nil, fmt.Errorf("failed to select NDEF application: %w", err)
+		return fmt.Errorf("failed to select NDEF application: %w", err)
 	}
 
 	// Select CC file (E103)
 	selectCCCmd := SelectFileAPDU([]byte{0xE1, 0x03})
 	_, err = t.transceive(selectCCCmd)
 	if err != nil {
-		return nil, fmt.Errorf("failed to select CC file: %w", err)
+		return fmt.Errorf("failed to select CC file: %w", err)
 	}
 
 	// Read CC file
 	readCCCmd := ReadBinaryExtAPDU(0, 15)
 	ccData, err := t.transceive(readCCCmd)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read CC: %w", err)
+		return fmt.Errorf("failed to read CC: %w", err)
 	}
 
 	// Parse CC to find NDEF file ID
-	// CC format: CCLEN (1) | Version (1) | MLe (2) | MLc (2) | TLVs...
+	// CC format: CCLEN (2) | Version (1) | MLe (2) | MLc (2) | TLVs...
 	if len(ccData) < 7 {
-		return nil, fmt.Errorf("CC file too short")
+		return fmt.Errorf("CC file too short")
 	}
+	t.mle = int(ccData[3])<<8 | int(ccData[4])
+	t.mlc = int(ccData[5])<<8 | int(ccData[6])
 
 	// Find NDEF File Control TLV (Tag 0x04)
 	ndefFileID := []byte{0xE1, 0x04} // Default
@@ -82,26 +176,33 @@ func (t *pcscISO14443Tag) ReadData() ([]byte, error) {
 	selectNDEFCmd := SelectFileAPDU(ndefFileID)
 	_, err = t.transceive(selectNDEFCmd)
 	if err != nil {
-		return nil, fmt.Errorf("failed to select NDEF file: %w", err)
+		return fmt.Errorf("failed to select NDEF file: %w", err)
 	}
 
-	// Read NLEN (2 bytes)
+	return nil
+}
+
+// readNLEN reads the 2-byte NDEF length from the selected NDEF file.
+func (t *pcscISO14443Tag) readNLEN() (int, error) {
 	readNLENCmd := ReadBinaryExtAPDU(0, 2)
 	nlenData, err := t.transceive(readNLENCmd)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read NLEN: %w", err)
+		return 0, fmt.Errorf("failed to read NLEN: %w", err)
 	}
-
-	nlen := int(nlenData[0])<<8 | int(nlenData[1])
-	if nlen == 0 {
-		return nil, fmt.Errorf("empty NDEF message")
+	if len(nlenData) < 2 {
+		return 0, fmt.Errorf("invalid NLEN data")
 	}
 
-	// Read NDEF message in chunks
-	var ndefData []byte
-	offset := uint16(2)
-	remaining := nlen
-	maxRead := 253 // Max Le for single read
+	return int(nlenData[0])<<8 | int(nlenData[1]), nil
+}
+
+// readNDEFBytes reads length bytes of the NDEF message starting at offset
+// (relative to the message, after NLEN) in READ BINARY chunks.
+func (t *pcscISO14443Tag) readNDEFBytes(offset, length int) ([]byte, error) {
+	ndefData := make([]byte, 0, length)
+	fileOffset := uint16(2 + offset)
+	remaining := length
+	maxRead := t.readChunk()
 
 	for remaining > 0 {
 		toRead := remaining
@@ -109,45 +210,85 @@ func (t *pcscISO14443Tag) ReadData() ([]byte, error) {
 			toRead = maxRead
 		}
 
-		readCmd := ReadBinaryExtAPDU(offset, byte(toRead))
+		readCmd := ReadBinaryExtAPDU(fileOffset, byte(toRead))
 		chunk, err := t.transceive(readCmd)
 		if err != nil {
-			return nil, fmt.Errorf("failed to read NDEF chunk at offset %d: %w", offset, err)
+			return nil, fmt.Errorf("failed to read NDEF chunk at offset %d: %w", fileOffset, err)
+		}
+		if len(chunk) == 0 {
+			return nil, fmt.Errorf("empty NDEF chunk at offset %d", fileOffset)
 		}
 
 		ndefData = append(ndefData, chunk...)
-		offset += uint16(len(chunk))
+		fileOffset += uint16(len(chunk))
 		remaining -= len(chunk)
 	}
 
 	return ndefData, nil
 }
 
-func (t *pcscISO14443Tag) WriteData(data []byte) error {
-	// Select NDEF application
-	selectAppCmd := SelectFileByAIDAPDU(ndefAppAID)
-	_, err := t.transceive(selectAppCmd)
+func (t *pcscISO14443Tag) ReadData() ([]byte, error) {
+	if err := t.selectNDEFFile(); err != nil {
+		return nil, err
+	}
+
+	nlen, err := t.readNLEN()
 	if err != nil {
-		return fmt.Errorf("failed to select NDEF application: %w", err)
+		return nil, err
+	}
+	if nlen == 0 {
+		return nil, fmt.Errorf("empty NDEF message")
 	}
 
-	// Select NDEF file (E104)
-	selectNDEFCmd := SelectFileAPDU([]byte{0xE1, 0x04})
-	_, err = t.transceive(selectNDEFCmd)
+	return t.readNDEFBytes(0, nlen)
+}
+
+// ReadNDEFRange reads up to length bytes of the NDEF message starting at offset,
+// without reading the rest of the file. Ranges past NLEN are truncated, and an
+// offset at or beyond NLEN returns an empty slice.
+func (t *pcscISO14443Tag) ReadNDEFRange(offset, length int) ([]byte, error) {
+	if offset < 0 || length < 0 {
+		return nil, fmt.Errorf("invalid range: offset %d, length %d", offset, length)
+	}
+
+	if err := t.selectNDEFFile(); err != nil {
+		return nil, err
+	}
+
+	nlen, err := t.readNLEN()
 	if err != nil {
-		return fmt.Errorf("failed to select NDEF file: %w", err)
+		return nil, err
+	}
+
+	start, end := clampNDEFRange(offset, length, nlen)
+	return t.readNDEFBytes(start, end-start)
+}
+
+// clampNDEFRange limits [offset, offset+length) to an NDEF message of nlen bytes.
+func clampNDEFRange(offset, length, nlen int) (start, end int) {
+	start = min(offset, nlen)
+	end = min(start+length, nlen)
+	return start, end
+}
+
+// WriteData writes data to the NDEF file the CC names, selected like ReadData
+// selects it, so a message read, changed and written back goes to the file it
+// came from.
+func (t *pcscISO14443Tag) WriteData(data []byte) error {
+	if err := t.selectNDEFFile(); err != nil {
+		return err
 	}
 
 	// Write NLEN = 0 first (clear)
 	writeNLENCmd := UpdateBinaryExtAPDU(0, []byte{0x00, 0x00})
-	_, err = t.transceive(writeNLENCmd)
+	_, err := t.transceive(writeNLENCmd)
 	if err != nil {
 		return fmt.Errorf("failed to clear NLEN: %w", err)
 	}
 
 	// Write NDEF data in chunks
 	offset := uint16(2)
-	maxWrite := 253
+	maxWrite := t.writeChunk()
 	for i := 0; i < len(data); i += maxWrite {
 		end := i + maxWrite
 		if end > len(data) {
@@ -176,6 +317,10 @@ func (t *pcscISO14443Tag) WriteData(data []byte) error {
 }
 
 func (t *pcscISO14443Tag) IsWritable() (bool, error) {
+	if err := t.runPreSelect(); err != nil {
+		return false, nil
+	}
+
 	// Select NDEF application and check CC WriteAccess byte
 	selectAppCmd := SelectFileByAIDAPDU(ndefAppAID)
 	_, err := t.transceive(selectAppCmd)
@@ -211,6 +356,35 @@ func (t *pcscISO14443Tag) IsWritable() (bool, error) {
 	return false, nil
 }
 
+// type4CCWriteAccess is the offset in the CC file of the write access byte
+// of the NDEF File Control TLV, which mapping version 2.0 places first.
+const type4CCWriteAccess = 14
+
+// QuickWritable reads only the write access byte of the CC file, rather than
+// the whole CC (implements QuickWritabilityChecker). Cards without the NDEF
+// application or CC file are reported as not writable.
+func (t *pcscISO14443Tag) QuickWritable() (bool, error) {
+	if err := t.runPreSelect(); err != nil {
+		return false, quickWritableError(err)
+	}
+
+	if _, err := t.transceive(SelectFileByAIDAPDU(ndefAppAID)); err != nil {
+		return false, quickWritableError(err)
+	}
+	if _, err := t.transceive(SelectFileAPDU([]byte{0xE1, 0x03})); err != nil {
+		return false, quickWritableError(err)
+	}
+
+	access, err := t.transceive(ReadBinaryExtAPDU(type4CCWriteAccess, 1))
+	if err != nil {
+		return false, quickWritableError(err)
+	}
+	if len(access) < 1 {
+		return false, nil
+	}
+	return access[0] == 0x00, nil
+}
+
 func (t *pcscISO14443Tag) CanMakeReadOnly() (bool, error) {
 	writable, err := t.IsWritable()
 	if err != nil {
@@ -224,3 +398,86 @@ func (t *pcscISO14443Tag) MakeReadOnly() error {
 	// This is a simplified implementation
 	return fmt.Errorf("ISO14443-4 MakeReadOnly not yet implemented")
 }
+
+// SelectByAID selects an application by AID. The NDEF application is selected
+// again by the next ReadData or WriteData.
+func (t *pcscISO14443Tag) SelectByAID(aid []byte) (APDUResponse, error) {
+	if err := validateAID(aid); err != nil {
+		return APDUResponse{}, err
+	}
+	return t.exchange(SelectFileByAIDAPDU(aid))
+}
+
+// SelectByFID selects a file in the current application by file identifier.
+func (t *pcscISO14443Tag) SelectByFID(fid []byte) (APDUResponse, error) {
+	if len(fid) != 2 {
+		return APDUResponse{}, fmt.Errorf("file identifier must be 2 bytes, got %d", len(fid))
+	}
+	return t.exchange(SelectFileByFIDAPDU(fid))
+}
+
+// ReadRecord reads one record of a record-based EF.
+func (t *pcscISO14443Tag) ReadRecord(recordNo, sfi byte) (APDUResponse, error) {
+	if err := validateRecord(recordNo, sfi); err != nil {
+		return APDUResponse{}, err
+	}
+	return t.exchange(ReadRecordAPDU(recordNo, sfi))
+}
+
+// exchange sends an APDU ending in Le and returns the complete response. A
+// wrong-length status (6Cxx) is retried with the length the card asked for,
+// and pending data (61xx) is collected with GET RESPONSE, for at most
+// maxGetResponses rounds so a card that keeps answering 61xx cannot stall it.
+func (t *pcscISO14443Tag) exchange(cmd []byte) (APDUResponse, error) {
+	resp, err := t.exchangeOnce(cmd)
+	if err != nil {
+		return APDUResponse{}, err
+	}
+	if resp.SW1 == SW1WrongLength {
+		retry := append(append([]byte(nil), cmd[:len(cmd)-1]...), resp.SW2)
+		if resp, err = t.exchangeOnce(retry); err != nil {
+			return APDUResponse{}, err
+		}
+	}
+
+	data := resp.Data
+	for rounds := 0; resp.HasMoreData(); rounds++ {
+		if rounds == maxGetResponses {
+			return APDUResponse{}, fmt.Errorf("card still has data after %d GET RESPONSE rounds", maxGetResponses)
+		}
+		if resp, err = t.exchangeOnce(GetResponseAPDU(resp.SW2)); err != nil {
+			return APDUResponse{}, err
+		}
+		data = append(data, resp.Data...)
+	}
+	resp.Data = data
+	return resp, nil
+}
+
+func (t *pcscISO14443Tag) exchangeOnce(cmd []byte) (APDUResponse, error) {
+	raw, err := t.transmitRaw(cmd)
+	if err != nil {
+		return APDUResponse{}, err
+	}
+	return ParseAPDUResponse(raw)
+}
+
+// validateAID checks an application identifier's length (ISO 7816-4: 5-16 bytes).
+func validateAID(aid []byte) error {
+	if len(aid) < 5 || len(aid) > 16 {
+		return fmt.Errorf("AID must be 5-16 bytes, got %d", len(aid))
+	}
+	return nil
+}
+
+// validateRecord checks READ RECORD parameters: record numbers 0x00 and 0xFF
+// are reserved and short file identifiers are 5 bits, with 31 reserved.
+func validateRecord(recordNo, sfi byte) error {
+	if recordNo == 0 || recordNo == 0xFF {
+		return fmt.Errorf("record number must be 1-254, got %d", recordNo)
+	}
+	if sfi > 30 {
+		return fmt.Errorf("short file identifier must be 0-30, got %d", sfi)
+	}
+	return nil
+}
@@ -0,0 +1,225 @@
+package nfc
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestParseATSFrameSize(t *testing.T) {
+	tests := []struct {
+		name string
+		ats  []byte
+		want int
+	}{
+		{"DESFire EV1 (FSCI 5)", []byte{0x06, 0x75, 0x77, 0x81, 0x02, 0x80}, 64},
+		{"FSCI 8", []byte{0x05, 0x78, 0x33, 0x92, 0x03}, 256},
+		{"FSCI 0", []byte{0x02, 0x00}, 16},
+		{"RFU FSCI treated as 256", []byte{0x02, 0x0C}, 256},
+		{"no format byte", []byte{0x01}, 0},
+		{"empty", nil, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseATSFrameSize(tt.ats); got != tt.want {
+				t.Errorf("parseATSFrameSize(% X) = %d, want %d", tt.ats, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestType4ChunkSize(t *testing.T) {
+	tests := []struct {
+		frameSize int
+		ml        int
+		want      int
+	}{
+		{0, 0, defaultType4Chunk},
+		{16, 0, defaultType4Chunk},
+		{128, 0, defaultType4Chunk},
+		{256, 0, defaultType4Chunk},
+		{1024, 0, maxType4Chunk},
+		{256, 0x3B, 0x3B},
+		{1024, 0xFFFF, maxType4Chunk},
+		{0, 254, defaultType4Chunk},
+	}
+
+	for _, tt := range tests {
+		if got := type4ChunkSize(tt.frameSize, tt.ml); got != tt.want {
+			t.Errorf("type4ChunkSize(%d, %d) = %d, want %d", tt.frameSize, tt.ml, got, tt.want)
+		}
+	}
+}
+
+func TestISO14443Tag_ChunkWithoutDevice(t *testing.T) {
+	tag := &pcscISO14443Tag{}
+	if got := tag.readChunk(); got != defaultType4Chunk {
+		t.Errorf("readChunk() = %d, want fallback %d", got, defaultType4Chunk)
+	}
+}
+
+// TestISO14443Tag_ChunkFromCC tests that reads and writes are split by the
+// CC's MLe and MLc.
+func TestISO14443Tag_ChunkFromCC(t *testing.T) {
+	card := newMockScardCard()
+	card.type4File = make([]byte, 0xFF)
+	tag := newPCSCISO14443Tag(newMockPCSCDevice(card, unknownATR), "04112233445566")
+
+	data := make([]byte, 120)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	if err := tag.WriteData(data); err != nil {
+		t.Fatalf("WriteData() failed: %v", err)
+	}
+	got, err := tag.ReadData()
+	if err != nil || !bytes.Equal(got, data) {
+		t.Fatalf("ReadData() = %X, %v, want %X", got, err, data)
+	}
+
+	// type4CC has MLe 0x3B and MLc 0x34
+	for _, cmd := range card.callLog {
+		if len(cmd) < 5 || cmd[0] != 0x00 {
+			continue
+		}
+		switch {
+		case cmd[1] == INSReadBinary && len(cmd) == 5 && int(cmd[4]) > 0x3B:
+			t.Errorf("READ BINARY of %d bytes exceeds MLe", cmd[4])
+		case cmd[1] == INSUpdateBin && int(cmd[4]) > 0x34:
+			t.Errorf("UPDATE BINARY of %d bytes exceeds MLc", cmd[4])
+		}
+	}
+}
+
+func TestParseAPDUList(t *testing.T) {
+	apdus, err := ParseAPDUList("00A40400 05 F001020304, 0020000004 31323334")
+	if err != nil {
+		t.Fatalf("ParseAPDUList() failed: %v", err)
+	}
+	if len(apdus) != 2 || hex.EncodeToString(apdus[0]) != "00a4040005f001020304" || hex.EncodeToString(apdus[1]) != "002000000431323334" {
+		t.Errorf("ParseAPDUList() = % X", apdus)
+	}
+
+	if apdus, err := ParseAPDUList(""); err != nil || apdus != nil {
+		t.Errorf("ParseAPDUList(\"\") = %v, %v; want nil, nil", apdus, err)
+	}
+
+	for _, bad := range []string{"00A4,", "00A4040", "00A4", "00A404ZZ"} {
+		if _, err := ParseAPDUList(bad); err == nil {
+			t.Errorf("ParseAPDUList(%q) expected error", bad)
+		}
+	}
+}
+
+func TestISO14443Tag_PreSelect(t *testing.T) {
+	preSelect := [][]byte{
+		{0x00, 0xA4, 0x04, 0x00, 0x05, 0xF0, 0x01, 0x02, 0x03, 0x04}, // Proprietary application
+		{0x00, 0x20, 0x00, 0x00, 0x04, 0x31, 0x32, 0x33, 0x34},       // VERIFY PIN
+	}
+
+	newTag := func(verifyResp string) (*pcscISO14443Tag, *mockScardCard) {
+		card := newMockScardCard()
+		card.addResponse("00a4040005f001020304", "9000")
+		card.addResponse("002000000431323334", verifyResp)
+		card.addResponse("00a4040007d276000085010100", "9000")
+		card.addResponse("00a4040002e10300", "9000")
+		card.addResponse("00b000000f", "000f20003b00340406e10400ff00009000")
+		card.addResponse("00a4040002e10400", "9000")
+
+		dev := newMockPCSCDevice(card, unknownATR)
+		dev.type4PreSelect = preSelect
+		return newPCSCISO14443Tag(dev, "04112233445566"), card
+	}
+
+	tag, card := newTag("9000")
+	if err := tag.selectNDEFFile(); err != nil {
+		t.Fatalf("selectNDEFFile() failed: %v", err)
+	}
+	if len(card.callLog) != 6 {
+		t.Fatalf("Expected 6 APDUs, got %d", len(card.callLog))
+	}
+	for i, want := range []string{"00a4040005f001020304", "002000000431323334", "00a4040007d276000085010100"} {
+		if got := hex.EncodeToString(card.callLog[i]); got != want {
+			t.Errorf("APDU %d = %s, want %s", i+1, got, want)
+		}
+	}
+
+	// Checking writability selects the NDEF application the same way
+	for name, check := range map[string]func(*pcscISO14443Tag) (bool, error){
+		"IsWritable":      (*pcscISO14443Tag).IsWritable,
+		"CanMakeReadOnly": (*pcscISO14443Tag).CanMakeReadOnly,
+		"QuickWritable":   (*pcscISO14443Tag).QuickWritable,
+	} {
+		tag, card = newTag("9000")
+		card.addResponse("00b0000e01", "009000")
+		if ok, err := check(tag); !ok || err != nil {
+			t.Errorf("%s() = %v, %v, want true", name, ok, err)
+		}
+		if len(card.callLog) < 3 || hex.EncodeToString(card.callLog[2]) != "00a4040007d276000085010100" {
+			t.Errorf("%s() did not send the pre-select APDUs first", name)
+		}
+	}
+
+	// A rejected PIN stops before the NDEF application is selected
+	tag, card = newTag("63c2")
+	if err := tag.selectNDEFFile(); err == nil {
+		t.Fatal("Expected error for rejected PIN")
+	}
+	if len(card.callLog) != 2 {
+		t.Errorf("Expected 2 APDUs before aborting, got %d", len(card.callLog))
+	}
+}
+
+func TestISO14443Tag_ReadRecord(t *testing.T) {
+	card := newMockScardCard()
+	card.addResponse("00a4040007a000000247100100", "6a82")
+	card.addResponse("00a4000002011e00", "6112")
+	card.addResponse("00c0000012", "6f108408a000000247100101a5049f6501ff9000")
+	card.addResponse("00b2010c00", "6c04")
+	card.addResponse("00b2010c04", "010203049000")
+	tag := newPCSCISO14443Tag(newMockPCSCDevice(card, unknownATR), "04112233445566")
+
+	// Status words other than 9000 are returned, not turned into errors
+	resp, err := tag.SelectByAID([]byte{0xA0, 0x00, 0x00, 0x02, 0x47, 0x10, 0x01})
+	if err != nil {
+		t.Fatalf("SelectByAID() failed: %v", err)
+	}
+	if resp.StatusWord() != 0x6A82 {
+		t.Errorf("SelectByAID() SW = %04X, want 6A82", resp.StatusWord())
+	}
+
+	// 61xx is completed with GET RESPONSE
+	resp, err = tag.SelectByFID([]byte{0x01, 0x1E})
+	if err != nil {
+		t.Fatalf("SelectByFID() failed: %v", err)
+	}
+	if !resp.IsSuccess() || len(resp.Data) != 18 {
+		t.Errorf("SelectByFID() = % X %04X, want 18 bytes of FCI and 9000", resp.Data, resp.StatusWord())
+	}
+
+	// 6Cxx is retried with the length the card asked for
+	resp, err = tag.ReadRecord(1, 1)
+	if err != nil {
+		t.Fatalf("ReadRecord() failed: %v", err)
+	}
+	if !resp.IsSuccess() || hex.EncodeToString(resp.Data) != "01020304" {
+		t.Errorf("ReadRecord() = % X %04X, want 01 02 03 04 and 9000", resp.Data, resp.StatusWord())
+	}
+
+	for _, bad := range []struct{ record, sfi byte }{{0, 1}, {0xFF, 1}, {1, 31}} {
+		if _, err := tag.ReadRecord(bad.record, bad.sfi); err == nil {
+			t.Errorf("ReadRecord(%d, %d) expected error", bad.record, bad.sfi)
+		}
+	}
+	// A card that never stops answering 61xx is given up on
+	card.addResponse("00a4000002011f00", "6110")
+	card.addResponse("00c0000010", "00112233445566778899aabbccddeeff6110")
+	if _, err := tag.SelectByFID([]byte{0x01, 0x1F}); err == nil {
+		t.Error("Expected error for an endless GET RESPONSE chain")
+	}
+
+	if _, err := tag.SelectByFID([]byte{0x01}); err == nil {
+		t.Error("SelectByFID() expected error for a 1-byte FID")
+	}
+}
@@ -1,8 +1,11 @@
 package nfc
 
 import (
+	"encoding/hex"
 	"fmt"
+	"strings"
 	"sync"
+	"time"
 )
 
 // MockTag is a test implementation of Tag that simulates NFC tag behavior.
@@ -37,6 +40,13 @@ type MockTag struct {
 	// WriteDataError, if set, will be returned by WriteData()
 	WriteDataError error
 
+	// WriteDataFunc allows custom WriteData behavior
+	// If nil, stores the data in Data or returns WriteDataError
+	WriteDataFunc func([]byte) error
+
+	// WriteDelay, if set, makes WriteData block for this long to simulate a slow tag
+	WriteDelay time.Duration
+
 	// TransceiveFunc allows custom transceive behavior
 	// If nil, returns TransceiveResponse or TransceiveError
 	TransceiveFunc func([]byte) ([]byte, error)
@@ -166,6 +176,10 @@ func (m *MockTag) ReadData() ([]byte, error) {
 
 // WriteData simulates writing data to the tag.
 func (m *MockTag) WriteData(data []byte) error {
+	if m.WriteDelay > 0 {
+		time.Sleep(m.WriteDelay)
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -179,6 +193,10 @@ func (m *MockTag) WriteData(data []byte) error {
 		return fmt.Errorf("tag is read-only")
 	}
 
+	if m.WriteDataFunc != nil {
+		return m.WriteDataFunc(data)
+	}
+
 	if m.WriteDataError != nil {
 		return m.WriteDataError
 	}
@@ -349,6 +367,12 @@ type MockClassicTag struct {
 	// WriteError, if set, will be returned by Write()
 	WriteError error
 
+	// FormatError, if set, will be returned by FormatNDEF()
+	FormatError error
+
+	// Magic is returned by ProbeMagic()
+	Magic MagicInfo
+
 	mu sync.Mutex
 }
 
@@ -410,6 +434,77 @@ func (m *MockClassicTag) Write(sector, block uint8, data []byte, key []byte, key
 	return nil
 }
 
+// FormatNDEF simulates formatting the tag for NDEF (implements NDEFFormatter).
+// A tag holding data is treated as not in factory state and is refused unless force is set.
+func (m *MockClassicTag) FormatNDEF(force bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.CallLog = append(m.CallLog, fmt.Sprintf("FormatNDEF(force:%v)", force))
+
+	if !m.IsConnected {
+		return fmt.Errorf("tag not connected")
+	}
+
+	if m.FormatError != nil {
+		return m.FormatError
+	}
+
+	if !force && (len(m.Data) > 0 || len(m.BlockData) > 0) {
+		return fmt.Errorf("tag is not in factory state")
+	}
+
+	m.Data = []byte{}
+	m.BlockData = make(map[string][]byte)
+	return nil
+}
+
+// ReadManufacturerBlock decodes the block stored at sector 0, block 0.
+func (m *MockClassicTag) ReadManufacturerBlock() (uid []byte, sak, atqa []byte, bccValid bool, err error) {
+	tagUID, err := hex.DecodeString(m.TagUID)
+	if err != nil {
+		return nil, nil, nil, false, fmt.Errorf("invalid tag UID %q: %w", m.TagUID, err)
+	}
+
+	block, err := m.Read(0, 0, FactoryKey[:], KeyTypeA)
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+
+	return ParseManufacturerBlock(block, len(tagUID))
+}
+
+// ReadMADInfo decodes the MAD stored in the block data.
+func (m *MockClassicTag) ReadMADInfo() ([]MADEntry, error) {
+	sectors := 16
+	if strings.Contains(m.TagType, "4K") {
+		sectors = 40
+	}
+	return readMADEntries(func(sector, block uint8) ([]byte, error) {
+		return m.Read(sector, block, KeyMAD, KeyTypeA)
+	}, sectors)
+}
+
+// ReadGPB returns byte 9 of the trailer stored in the block data.
+func (m *MockClassicTag) ReadGPB(sector uint8, key []byte, keyType int) (byte, error) {
+	return readTrailerGPB(func(sector, block uint8) ([]byte, error) {
+		return m.Read(sector, block, key, keyType)
+	}, sector)
+}
+
+// ProbeMagic returns Magic.
+func (m *MockClassicTag) ProbeMagic() (MagicInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.CallLog = append(m.CallLog, "ProbeMagic")
+
+	if !m.IsConnected {
+		return MagicInfo{}, fmt.Errorf("tag not connected")
+	}
+	return m.Magic, nil
+}
+
 // SetBlockData sets the data for a specific sector/block combination.
 func (m *MockClassicTag) SetBlockData(sector, block uint8, data []byte) {
 	m.mu.Lock()
@@ -438,8 +533,15 @@ func (m *MockClassicTag) GetBlockData(sector, block uint8) ([]byte, bool) {
 }
 
 // MockISO14443Tag is a test implementation of ISO14443Tag for Type 4 tags.
+//
+// Selecting an AID or FID that is not listed answers 6A82 (file not found),
+// and reading a missing record answers 6A83 (record not found).
 type MockISO14443Tag struct {
 	*MockTag
+
+	Applications map[string]bool   // Selectable AIDs (uppercase hex)
+	Files        map[string]bool   // Selectable FIDs (uppercase hex)
+	Records      map[byte][][]byte // Records by SFI, record n at index n-1
 }
 
 // NewMockISO14443Tag creates a new MockISO14443Tag with default values.
@@ -449,7 +551,80 @@ func NewMockISO14443Tag(uid string) *MockISO14443Tag {
 	}
 }
 
-// MockNtagTag is a test implementation of NtagTag for NTAG21x tags.
+// SelectByAID simulates selecting an application listed in Applications.
+func (m *MockISO14443Tag) SelectByAID(aid []byte) (APDUResponse, error) {
+	return m.selectFile(fmt.Sprintf("SelectByAID(%X)", aid), m.Applications[fmt.Sprintf("%X", aid)])
+}
+
+// SelectByFID simulates selecting a file listed in Files.
+func (m *MockISO14443Tag) SelectByFID(fid []byte) (APDUResponse, error) {
+	return m.selectFile(fmt.Sprintf("SelectByFID(%X)", fid), m.Files[fmt.Sprintf("%X", fid)])
+}
+
+func (m *MockISO14443Tag) selectFile(call string, found bool) (APDUResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.CallLog = append(m.CallLog, call)
+
+	if !m.IsConnected {
+		return APDUResponse{}, fmt.Errorf("tag not connected")
+	}
+	if !found {
+		return APDUResponse{SW1: 0x6A, SW2: 0x82}, nil
+	}
+	return APDUResponse{SW1: SW1Success, SW2: SW2Success}, nil
+}
+
+// ReadRecord simulates reading a record from Records.
+func (m *MockISO14443Tag) ReadRecord(recordNo, sfi byte) (APDUResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.CallLog = append(m.CallLog, fmt.Sprintf("ReadRecord(%d, %d)", recordNo, sfi))
+
+	if !m.IsConnected {
+		return APDUResponse{}, fmt.Errorf("tag not connected")
+	}
+	if err := validateRecord(recordNo, sfi); err != nil {
+		return APDUResponse{}, err
+	}
+
+	records := m.Records[sfi]
+	if int(recordNo) > len(records) {
+		return APDUResponse{SW1: 0x6A, SW2: 0x83}, nil
+	}
+	data := make([]byte, len(records[recordNo-1]))
+	copy(data, records[recordNo-1])
+	return APDUResponse{Data: data, SW1: SW1Success, SW2: SW2Success}, nil
+}
+
+// ReadNDEFRange simulates a partial NDEF read, treating Data as the NDEF message.
+func (m *MockISO14443Tag) ReadNDEFRange(offset, length int) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.CallLog = append(m.CallLog, fmt.Sprintf("ReadNDEFRange(%d, %d)", offset, length))
+
+	if !m.IsConnected {
+		return nil, fmt.Errorf("tag not connected")
+	}
+
+	if m.ReadDataError != nil {
+		return nil, m.ReadDataError
+	}
+
+	if offset < 0 || length < 0 {
+		return nil, fmt.Errorf("invalid range: offset %d, length %d", offset, length)
+	}
+
+	start, end := clampNDEFRange(offset, length, len(m.Data))
+	data := make([]byte, end-start)
+	copy(data, m.Data[start:end])
+	return data, nil
+}
+
+// MockNtagTag is a test implementation of PageTag for NTAG21x tags.
 //
 // MockNtagTag simulates page-based memory operations for NTAG213/215/216 tags.
 //
@@ -474,6 +649,12 @@ type MockNtagTag struct {
 	// MaxPages defines the maximum page number (default 135 for NTAG215)
 	MaxPages byte
 
+	// Signature is returned by ReadSignature()
+	Signature []byte
+
+	// ReadSignatureError, if set, will be returned by ReadSignature()
+	ReadSignatureError error
+
 	mu sync.Mutex
 }
 
@@ -518,8 +699,56 @@ func (m *MockNtagTag) ReadPage(page byte) ([4]byte, error) {
 	return data, nil
 }
 
+// ReadSignature returns Signature.
+func (m *MockNtagTag) ReadSignature() ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.CallLog = append(m.CallLog, "ReadSignature")
+
+	if !m.IsConnected {
+		return nil, fmt.Errorf("tag not connected")
+	}
+	if m.ReadSignatureError != nil {
+		return nil, m.ReadSignatureError
+	}
+	return append([]byte(nil), m.Signature...), nil
+}
+
+// PageCount returns MaxPages.
+func (m *MockNtagTag) PageCount() int {
+	return int(m.MaxPages)
+}
+
+// ReadPages simulates reading count pages starting at start.
+func (m *MockNtagTag) ReadPages(start, count int) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.CallLog = append(m.CallLog, fmt.Sprintf("ReadPages(%d, %d)", start, count))
+
+	if !m.IsConnected {
+		return nil, fmt.Errorf("tag not connected")
+	}
+
+	if err := checkPageRange(start, count, int(m.MaxPages)); err != nil {
+		return nil, err
+	}
+
+	if m.ReadPageError != nil {
+		return nil, m.ReadPageError
+	}
+
+	data := make([]byte, 0, count*4)
+	for page := start; page < start+count; page++ {
+		pageData := m.PageData[byte(page)]
+		data = append(data, pageData[:]...)
+	}
+	return data, nil
+}
+
 // WritePage simulates writing a 4-byte page to the NTAG tag.
-func (m *MockNtagTag) WritePage(page byte, data [4]byte) error {
+func (m *MockNtagTag) WritePage(page int, data [4]byte) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -533,8 +762,8 @@ func (m *MockNtagTag) WritePage(page byte, data [4]byte) error {
 		return fmt.Errorf("tag is read-only")
 	}
 
-	if page >= m.MaxPages {
-		return fmt.Errorf("page %d out of range (max %d)", page, m.MaxPages-1)
+	if err := checkPageRange(page, 1, int(m.MaxPages)); err != nil {
+		return err
 	}
 
 	// Pages 0-3 are typically read-only (UID, lock bytes, CC)
@@ -546,7 +775,7 @@ func (m *MockNtagTag) WritePage(page byte, data [4]byte) error {
 		return m.WritePageError
 	}
 
-	m.PageData[page] = data
+	m.PageData[byte(page)] = data
 	return nil
 }
 
@@ -70,6 +70,33 @@ func (t *pcscNtagTag) writePage(page byte, data []byte) error {
 	return err
 }
 
+// ReadSignature reads the 32-byte ECC originality signature with READ_SIG.
+func (t *pcscNtagTag) ReadSignature() ([]byte, error) {
+	sig, err := t.transceive(DirectTransmitAPDU([]byte{ntagCmdReadSig, 0x00}))
+	if err != nil {
+		return nil, fmt.Errorf("READ_SIG failed: %w", err)
+	}
+	if len(sig) != NTAGSignatureLen {
+		return nil, fmt.Errorf("READ_SIG returned %d bytes, want %d", len(sig), NTAGSignatureLen)
+	}
+	return sig, nil
+}
+
+// PageCount returns the number of pages for the detected NTAG variant.
+func (t *pcscNtagTag) PageCount() int {
+	return int(t.maxPages)
+}
+
+// ReadPages reads count raw pages starting at start.
+func (t *pcscNtagTag) ReadPages(start, count int) ([]byte, error) {
+	return readType2Pages(&t.pcscBaseTag, start, count, t.PageCount())
+}
+
+// WritePage writes one raw page.
+func (t *pcscNtagTag) WritePage(page int, data [4]byte) error {
+	return writeType2Page(&t.pcscBaseTag, page, data, t.PageCount())
+}
+
 func (t *pcscNtagTag) ReadData() ([]byte, error) {
 	// Read pages 4 to maxPages-5 (user data area, excluding config pages)
 	var allData []byte
@@ -110,34 +137,20 @@ func (t *pcscNtagTag) ReadData() ([]byte, error) {
 	return ndefData, nil
 }
 
-func (t *pcscNtagTag) WriteData(data []byte) error {
-	// Build TLV payload
-	tlvPayload := TLVEncode(data, TLVNDEF)
-
-	// Calculate required pages
-	totalBytes := len(tlvPayload)
-	requiredPages := (totalBytes + 3) / 4
-
-	// Check if it fits
-	userPages := int(t.maxPages) - 5 - 4 // Subtract config pages and reserved pages
-	if requiredPages > userPages {
-		return fmt.Errorf("data too large: need %d pages, have %d", requiredPages, userPages)
-	}
-
-	// Pad to 4-byte boundary
-	for len(tlvPayload)%4 != 0 {
-		tlvPayload = append(tlvPayload, 0x00)
-	}
+// userPages returns the number of data pages, excluding the 4 header pages
+// and the 5 configuration pages at the end of memory.
+func (t *pcscNtagTag) userPages() int {
+	return int(t.maxPages) - 4 - 5
+}
 
-	// Write pages starting at page 4
-	for i := 0; i < len(tlvPayload); i += 4 {
-		page := byte(4 + i/4)
-		if err := t.writePage(page, tlvPayload[i:i+4]); err != nil {
-			return fmt.Errorf("failed to write page %d: %w", page, err)
-		}
-	}
+func (t *pcscNtagTag) WriteData(data []byte) error {
+	return writeType2NDEF(t, data, t.userPages(), false)
+}
 
-	return nil
+// WriteDataWithOptions writes NDEF data, zero-filling the rest of the data
+// area with opts.WipeTrailing (implements AdvancedWriter).
+func (t *pcscNtagTag) WriteDataWithOptions(data []byte, opts TagWriteOptions) error {
+	return writeType2NDEF(t, data, t.userPages(), opts.WipeTrailing)
 }
 
 func (t *pcscNtagTag) IsWritable() (bool, error) {
@@ -146,6 +159,12 @@ func (t *pcscNtagTag) IsWritable() (bool, error) {
 	return err == nil, nil
 }
 
+// QuickWritable checks the write access byte of the Capability Container
+// (implements QuickWritabilityChecker).
+func (t *pcscNtagTag) QuickWritable() (bool, error) {
+	return quickWritableType2(t)
+}
+
 func (t *pcscNtagTag) CanMakeReadOnly() (bool, error) {
 	return true, nil
 }
@@ -0,0 +1,157 @@
+package nfc
+
+import "fmt"
+
+// checkPageRange validates that count pages starting at start fit within pageCount.
+func checkPageRange(start, count, pageCount int) error {
+	if start < 0 || count <= 0 || start+count > pageCount {
+		return fmt.Errorf("%w: pages %d-%d requested, tag has %d pages", ErrPageOutOfRange, start, start+count-1, pageCount)
+	}
+	return nil
+}
+
+// readType2Pages reads count pages starting at start with READ (0x30). READ
+// always returns four pages, so the range is fetched in 4-page steps and trimmed to what was asked.
+func readType2Pages(t *pcscBaseTag, start, count, pageCount int) ([]byte, error) {
+	if err := checkPageRange(start, count, pageCount); err != nil {
+		return nil, err
+	}
+
+	data := make([]byte, 0, count*4)
+	for page := start; page < start+count; page += 4 {
+		resp, err := t.transceive(UltralightReadAPDU(byte(page)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read page %d: %w", page, err)
+		}
+		if len(resp) < 16 {
+			return nil, fmt.Errorf("short READ response for page %d: %d bytes", page, len(resp))
+		}
+
+		n := min(4, start+count-page)
+		data = append(data, resp[:n*4]...)
+	}
+
+	return data, nil
+}
+
+// writeType2Page writes one page with WRITE (0xA2).
+func writeType2Page(t *pcscBaseTag, page int, data [4]byte, pageCount int) error {
+	if err := checkPageRange(page, 1, pageCount); err != nil {
+		return err
+	}
+
+	if _, err := t.transceive(UltralightWriteAPDU(byte(page), data[:])); err != nil {
+		return fmt.Errorf("failed to write page %d: %w", page, err)
+	}
+	return nil
+}
+
+// Type 2 Capability Container (page 3) fields
+const (
+	type2CCPage       = 3
+	type2DataPage     = 4    // First page of the data area
+	type2NDEFMagic    = 0xE1 // CC byte 0: NDEF formatted
+	type2NDEFVersion  = 0x10 // CC byte 1: mapping version 1.0
+	type2AccessWrite  = 0x00 // CC byte 3: read/write access
+	type2AccessLocked = 0x0F // CC byte 3 low nibble: no write access
+)
+
+// type2PageIO reads and writes single 4-byte pages of a Type 2 tag.
+type type2PageIO interface {
+	readPage(page byte) ([]byte, error)
+	writePage(page byte, data []byte) error
+}
+
+// type2CC returns the Capability Container for a data area of dataPages pages.
+func type2CC(dataPages int) []byte {
+	return []byte{type2NDEFMagic, type2NDEFVersion, type2CCSize(dataPages), type2AccessWrite}
+}
+
+// type2CCSize returns CC byte 2, the data area size in 8-byte units. NTAG215
+// and NTAG216 ship with the smaller sizes their datasheets give (496 and 872
+// bytes) rather than their full user memory.
+func type2CCSize(dataPages int) byte {
+	switch dataPages {
+	case 126: // NTAG215
+		return 0x3E
+	case 222: // NTAG216
+		return 0x6D
+	default:
+		return byte(dataPages * 4 / 8)
+	}
+}
+
+// quickWritableType2 reports whether a Type 2 tag accepts writes from its
+// Capability Container alone, as writeType2NDEF would judge it: a blank CC
+// is initialized on write, one that is not NDEF formatted or denies writes
+// is refused.
+func quickWritableType2(tag type2PageIO) (bool, error) {
+	cc, err := tag.readPage(type2CCPage)
+	if err != nil {
+		return false, fmt.Errorf("failed to read capability container: %w", err)
+	}
+	if len(cc) < 4 {
+		return false, fmt.Errorf("short capability container: %d bytes", len(cc))
+	}
+	switch {
+	case cc[0] == 0 && cc[1] == 0 && cc[2] == 0 && cc[3] == 0:
+		return true, nil
+	case cc[0] != type2NDEFMagic:
+		return false, nil
+	default:
+		return cc[3]&type2AccessLocked != type2AccessLocked, nil
+	}
+}
+
+// writeType2NDEF writes data as an NDEF Message TLV followed by a Terminator
+// TLV from page 4, the start of the data area of dataPages pages. A blank CC
+// is initialized first; a CC that is not NDEF formatted or denies writes is
+// refused, as CC bits are one-time programmable. With wipe, the rest of the
+// data area is zero-filled.
+func writeType2NDEF(tag type2PageIO, data []byte, dataPages int, wipe bool) error {
+	tlv := TLVEncode(data, TLVNDEF) // Short length below 0xFF bytes, 3-byte long form above
+	requiredPages := (len(tlv) + 3) / 4
+	if requiredPages > dataPages {
+		return fmt.Errorf("data too large: need %d pages, have %d", requiredPages, dataPages)
+	}
+
+	cc, err := tag.readPage(type2CCPage)
+	if err != nil {
+		return fmt.Errorf("failed to read capability container: %w", err)
+	}
+	if len(cc) < 4 {
+		return fmt.Errorf("short capability container: %d bytes", len(cc))
+	}
+	switch {
+	case cc[0] == 0 && cc[1] == 0 && cc[2] == 0 && cc[3] == 0:
+		if err := tag.writePage(type2CCPage, type2CC(dataPages)); err != nil {
+			return fmt.Errorf("failed to write capability container: %w", err)
+		}
+	case cc[0] != type2NDEFMagic:
+		return fmt.Errorf("tag is not NDEF formatted (CC % X)", cc[:4])
+	case cc[3]&type2AccessLocked == type2AccessLocked:
+		return fmt.Errorf("tag is read-only (CC % X)", cc[:4])
+	}
+
+	// Pad to a whole page
+	for len(tlv)%4 != 0 {
+		tlv = append(tlv, 0x00)
+	}
+
+	for i := 0; i < len(tlv); i += 4 {
+		page := byte(type2DataPage + i/4)
+		if err := tag.writePage(page, tlv[i:i+4]); err != nil {
+			return fmt.Errorf("failed to write page %d: %w", page, err)
+		}
+	}
+
+	if wipe {
+		for page := type2DataPage + len(tlv)/4; page < type2DataPage+dataPages; page++ {
+			if err := tag.writePage(byte(page), make([]byte, 4)); err != nil {
+				return fmt.Errorf("failed to wipe page %d: %w", page, err)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,156 @@
+package nfc
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCheckPageRange(t *testing.T) {
+	tests := []struct {
+		name      string
+		start     int
+		count     int
+		pageCount int
+		wantErr   bool
+	}{
+		{"first page", 0, 1, 16, false},
+		{"whole tag", 0, 16, 16, false},
+		{"last page", 15, 1, 16, false},
+		{"past end", 14, 3, 16, true},
+		{"start past end", 16, 1, 16, true},
+		{"negative start", -1, 2, 16, true},
+		{"zero count", 4, 0, 16, true},
+		{"ntag216 user area", 4, 222, 231, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkPageRange(tt.start, tt.count, tt.pageCount)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkPageRange(%d, %d, %d) error = %v, wantErr %v", tt.start, tt.count, tt.pageCount, err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrPageOutOfRange) {
+				t.Errorf("Expected ErrPageOutOfRange, got %v", err)
+			}
+		})
+	}
+}
+
+// type2Tag is the part of the Type 2 wrappers exercised by the write tests.
+type type2Tag interface {
+	ReadData() ([]byte, error)
+	WriteData(data []byte) error
+}
+
+// newBlankType2Tag returns a Type 2 tag of the given type backed by zeroed memory.
+func newBlankType2Tag(tagType DetectedTagType, pageCount int) (type2Tag, *mockScardCard) {
+	card := newMockScardCard()
+	card.pages = make([]byte, pageCount*4)
+	dev := newMockPCSCDevice(card, unknownATR)
+
+	switch tagType {
+	case DetectedUltralight, DetectedUltralightC:
+		return newPCSCUltralightTag(dev, "04A1B2C3D4E5F6", tagType), card
+	default:
+		return newPCSCNtagTag(dev, "04A1B2C3D4E5F6", tagType), card
+	}
+}
+
+func TestType2WriteData_RoundTrip(t *testing.T) {
+	tests := []struct {
+		name      string
+		tagType   DetectedTagType
+		pageCount int
+		text      string
+		wantCC    []byte
+		wantTLV   []byte // Type and length bytes at page 4
+	}{
+		{"Ultralight short TLV", DetectedUltralight, 16, "Hi", []byte{0xE1, 0x10, 0x06, 0x00}, []byte{0x03, 0x09}},
+		{"NTAG213 short TLV", DetectedNTAG213, 45, strings.Repeat("a", 100), []byte{0xE1, 0x10, 0x12, 0x00}, []byte{0x03, 0x6B}},
+		{"NTAG215 long TLV", DetectedNTAG215, 135, strings.Repeat("b", 400), []byte{0xE1, 0x10, 0x3E, 0x00}, []byte{0x03, 0xFF, 0x01, 0x9A}},
+		{"NTAG216 long TLV", DetectedNTAG216, 231, strings.Repeat("c", 800), []byte{0xE1, 0x10, 0x6D, 0x00}, []byte{0x03, 0xFF, 0x03, 0x2A}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tag, card := newBlankType2Tag(tt.tagType, tt.pageCount)
+			data := EncodeNdefMessageWithTextRecord(tt.text, "en")
+
+			if err := tag.WriteData(data); err != nil {
+				t.Fatalf("WriteData() failed: %v", err)
+			}
+
+			if cc := card.pages[12:16]; !bytes.Equal(cc, tt.wantCC) {
+				t.Errorf("CC = % X, want % X", cc, tt.wantCC)
+			}
+			if header := card.pages[16 : 16+len(tt.wantTLV)]; !bytes.Equal(header, tt.wantTLV) {
+				t.Errorf("TLV header = % X, want % X", header, tt.wantTLV)
+			}
+			if end := 16 + len(tt.wantTLV) + len(data); card.pages[end] != TLVTerminator {
+				t.Errorf("Expected terminator TLV after the message, got %02X", card.pages[end])
+			}
+
+			got, err := tag.ReadData()
+			if err != nil {
+				t.Fatalf("ReadData() failed: %v", err)
+			}
+			if !bytes.Equal(got, data) {
+				t.Errorf("ReadData() returned %d bytes, want the %d written", len(got), len(data))
+			}
+		})
+	}
+}
+
+func TestType2WriteData_Refused(t *testing.T) {
+	tests := []struct {
+		name    string
+		cc      []byte
+		text    string
+		wantErr string
+	}{
+		{"larger than data area", nil, strings.Repeat("x", 60), "data too large"},
+		{"not NDEF formatted", []byte{0x01, 0x02, 0x03, 0x04}, "Hi", "not NDEF formatted"},
+		{"read-only", []byte{0xE1, 0x10, 0x06, 0x0F}, "Hi", "read-only"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tag, card := newBlankType2Tag(DetectedUltralight, 16)
+			copy(card.pages[12:16], tt.cc)
+			before := append([]byte{}, card.pages...)
+
+			err := tag.WriteData(EncodeNdefMessageWithTextRecord(tt.text, "en"))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("WriteData() error = %v, want %q", err, tt.wantErr)
+			}
+			if !bytes.Equal(card.pages, before) {
+				t.Error("Refused write must not change tag memory")
+			}
+		})
+	}
+}
+
+func TestType2WriteData_WipeTrailing(t *testing.T) {
+	for _, wipe := range []bool{false, true} {
+		tag, card := newBlankType2Tag(DetectedNTAG213, 45)
+		if err := tag.WriteData(EncodeNdefMessageWithTextRecord(strings.Repeat("a", 100), "en")); err != nil {
+			t.Fatalf("WriteData() failed: %v", err)
+		}
+
+		data := EncodeNdefMessageWithTextRecord("Hi", "en")
+		if err := tag.(AdvancedWriter).WriteDataWithOptions(data, TagWriteOptions{WipeTrailing: wipe}); err != nil {
+			t.Fatalf("WriteDataWithOptions() failed: %v", err)
+		}
+
+		// The new TLV and terminator fill pages 4-6; the data area ends at page 39
+		stale := !bytes.Equal(card.pages[7*4:40*4], make([]byte, 33*4))
+		if stale == wipe {
+			t.Errorf("WipeTrailing=%v: stale bytes after the message = %v", wipe, stale)
+		}
+		if !bytes.Equal(card.pages[40*4:], make([]byte, 5*4)) {
+			t.Errorf("WipeTrailing=%v: configuration pages were written", wipe)
+		}
+	}
+}
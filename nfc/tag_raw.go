@@ -0,0 +1,66 @@
+package nfc
+
+import (
+	"fmt"
+)
+
+// pcscRawTag stands in for a card the agent cannot read under the
+// UnsupportedTagRaw policy. It carries the UID and ATR only; reads return no
+// data and every other operation fails.
+type pcscRawTag struct {
+	pcscBaseTag
+	atr []byte
+}
+
+func newPCSCRawTag(dev *pcscDevice, uid string, atr []byte) *pcscRawTag {
+	return &pcscRawTag{
+		pcscBaseTag: pcscBaseTag{
+			device:       dev,
+			uid:          uid,
+			detectedType: DetectedUnknown,
+		},
+		atr: atr,
+	}
+}
+
+func (t *pcscRawTag) Type() string {
+	return CardTypeUnknown
+}
+
+func (t *pcscRawTag) NumericType() int {
+	return detectedTypeNumeric(t.detectedType)
+}
+
+// ATR returns the card's Answer To Reset (implements ATRProvider).
+func (t *pcscRawTag) ATR() string {
+	return BytesToHex(t.atr)
+}
+
+func (t *pcscRawTag) Capabilities() TagCapabilities {
+	return TagCapabilities{CanRead: true, TagFamily: CardTypeUnknown}
+}
+
+// ReadData returns no data: the card's contents can't be interpreted.
+func (t *pcscRawTag) ReadData() ([]byte, error) {
+	return []byte{}, nil
+}
+
+func (t *pcscRawTag) WriteData(data []byte) error {
+	return fmt.Errorf("cannot write to unsupported card (ATR %s)", t.ATR())
+}
+
+func (t *pcscRawTag) Transceive(data []byte) ([]byte, error) {
+	return nil, fmt.Errorf("transceive not supported for unsupported card (ATR %s)", t.ATR())
+}
+
+func (t *pcscRawTag) IsWritable() (bool, error) {
+	return false, nil
+}
+
+func (t *pcscRawTag) CanMakeReadOnly() (bool, error) {
+	return false, nil
+}
+
+func (t *pcscRawTag) MakeReadOnly() error {
+	return fmt.Errorf("cannot lock unsupported card (ATR %s)", t.ATR())
+}
@@ -0,0 +1,214 @@
+package nfc
+
+import (
+	"encoding/hex"
+	"fmt"
+)
+
+// Type 1 (Topaz / Jewel) constants
+const (
+	// Native Type 1 commands, sent through the reader's direct transmit
+	type1CmdRALL   = 0x00 // Read all static memory: HR0 HR1 + 120 bytes
+	type1CmdWriteE = 0x53 // Write one byte with erase
+
+	// type1StaticSize is the size of the static memory returned by RALL
+	type1StaticSize = 120
+
+	// Static memory layout: CC at bytes 8-11, data area bytes 12-103
+	type1CCOffset   = 8
+	type1DataOffset = 12
+	type1DataEnd    = 104
+
+	// type1NDEFMagic is the NDEF Magic Number in CC byte 0
+	type1NDEFMagic = 0xE1
+
+	// type1HR0NDEF is the high nibble of HR0 for NDEF capable Type 1 tags
+	type1HR0NDEF = 0x10
+)
+
+// pcscType1Tag implements Tag for NFC Forum Type 1 (Topaz / Jewel) tags.
+//
+// The tag is driven with its native RALL and WRITE-E commands, wrapped in
+// the reader's direct transmit APDU. Only the 120-byte static memory is
+// used, so on Topaz 512 the NDEF message must fit in the static data area.
+type pcscType1Tag struct {
+	pcscBaseTag
+}
+
+func newPCSCType1Tag(dev *pcscDevice, uid string) *pcscType1Tag {
+	return &pcscType1Tag{
+		pcscBaseTag: pcscBaseTag{
+			device:       dev,
+			uid:          uid,
+			detectedType: DetectedTopaz,
+		},
+	}
+}
+
+func (t *pcscType1Tag) Type() string {
+	return CardTypeType1
+}
+
+func (t *pcscType1Tag) NumericType() int {
+	return detectedTypeNumeric(t.detectedType)
+}
+
+func (t *pcscType1Tag) Capabilities() TagCapabilities {
+	return InferTagCapabilities(t.Type())
+}
+
+func (t *pcscType1Tag) Transceive(data []byte) ([]byte, error) {
+	return t.transceive(data)
+}
+
+// uidEcho returns UID0-UID3, which every Type 1 command carries
+func (t *pcscType1Tag) uidEcho() ([]byte, error) {
+	uid, err := hex.DecodeString(t.uid)
+	if err != nil || len(uid) < 4 {
+		return nil, fmt.Errorf("invalid Type 1 UID %q", t.uid)
+	}
+	return uid[:4], nil
+}
+
+// readAll reads the static memory with RALL, checking the header ROM
+func (t *pcscType1Tag) readAll() ([]byte, error) {
+	uid, err := t.uidEcho()
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := append([]byte{type1CmdRALL, 0x00, 0x00}, uid...)
+	data, err := t.transceive(DirectTransmitAPDU(cmd))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 2+type1StaticSize {
+		return nil, fmt.Errorf("short RALL response: got %d bytes", len(data))
+	}
+	if data[0]&0xF0 != type1HR0NDEF {
+		return nil, fmt.Errorf("tag is not NDEF capable (HR0 0x%02X)", data[0])
+	}
+	return data[2 : 2+type1StaticSize], nil
+}
+
+// writeByte writes one byte of static memory with WRITE-E
+func (t *pcscType1Tag) writeByte(addr int, value byte) error {
+	uid, err := t.uidEcho()
+	if err != nil {
+		return err
+	}
+
+	cmd := append([]byte{type1CmdWriteE, byte(addr), value}, uid...)
+	resp, err := t.transceive(DirectTransmitAPDU(cmd))
+	if err != nil {
+		return err
+	}
+	// The tag echoes ADD DATA
+	if len(resp) < 2 || resp[1] != value {
+		return fmt.Errorf("write to byte %d not confirmed", addr)
+	}
+	return nil
+}
+
+// checkType1CC verifies the Capability Container in static memory
+func checkType1CC(mem []byte) error {
+	if mem[type1CCOffset] != type1NDEFMagic {
+		return fmt.Errorf("tag is not NDEF formatted (CC magic 0x%02X)", mem[type1CCOffset])
+	}
+	return nil
+}
+
+// type1ReadOnly reports whether the CC write access nibble forbids writes
+func type1ReadOnly(mem []byte) bool {
+	return mem[type1CCOffset+3]&0x0F != 0x00
+}
+
+// type1NDEFOffset returns the offset in the data area where the NDEF TLV
+// goes, after any Lock Control and Memory Control TLVs that must be kept.
+func type1NDEFOffset(area []byte) int {
+	offset := 0
+	for offset < len(area) {
+		switch area[offset] {
+		case TLVNull:
+			offset++
+		case TLVLockCtrl, TLVMemCtrl:
+			if offset+1 >= len(area) {
+				return offset
+			}
+			offset += 2 + int(area[offset+1])
+		default:
+			return offset
+		}
+	}
+	return offset
+}
+
+func (t *pcscType1Tag) ReadData() ([]byte, error) {
+	mem, err := t.readAll()
+	if err != nil {
+		return nil, err
+	}
+	if err := checkType1CC(mem); err != nil {
+		return nil, err
+	}
+
+	if ndefData, found := TLVFindNDEF(mem[type1DataOffset:type1DataEnd]); found {
+		return ndefData, nil
+	}
+	return nil, fmt.Errorf("no NDEF message found")
+}
+
+// WriteData writes the NDEF TLV following the NFC Forum sequence: the NDEF
+// magic is cleared first and restored last, so a torn write leaves the tag
+// unformatted rather than holding a half-written message.
+func (t *pcscType1Tag) WriteData(data []byte) error {
+	mem, err := t.readAll()
+	if err != nil {
+		return err
+	}
+	if err := checkType1CC(mem); err != nil {
+		return err
+	}
+	if type1ReadOnly(mem) {
+		return fmt.Errorf("tag is read-only")
+	}
+
+	start := type1DataOffset + type1NDEFOffset(mem[type1DataOffset:type1DataEnd])
+	tlvPayload := TLVEncode(data, TLVNDEF)
+	if start+len(tlvPayload) > type1DataEnd {
+		return fmt.Errorf("data too large: need %d bytes, have %d", len(tlvPayload), type1DataEnd-start)
+	}
+
+	if err := t.writeByte(type1CCOffset, 0x00); err != nil {
+		return fmt.Errorf("failed to clear NDEF magic: %w", err)
+	}
+	for i, b := range tlvPayload {
+		if err := t.writeByte(start+i, b); err != nil {
+			return fmt.Errorf("failed to write byte %d: %w", start+i, err)
+		}
+	}
+	if err := t.writeByte(type1CCOffset, type1NDEFMagic); err != nil {
+		return fmt.Errorf("failed to restore NDEF magic: %w", err)
+	}
+
+	return nil
+}
+
+func (t *pcscType1Tag) IsWritable() (bool, error) {
+	mem, err := t.readAll()
+	if err != nil {
+		return false, err
+	}
+	if err := checkType1CC(mem); err != nil {
+		return false, err
+	}
+	return !type1ReadOnly(mem), nil
+}
+
+func (t *pcscType1Tag) CanMakeReadOnly() (bool, error) {
+	return false, nil
+}
+
+func (t *pcscType1Tag) MakeReadOnly() error {
+	return fmt.Errorf("Type 1 MakeReadOnly not yet implemented")
+}
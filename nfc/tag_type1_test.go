@@ -0,0 +1,120 @@
+package nfc
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// newType1Memory returns blank static Topaz memory with an NDEF CC
+func newType1Memory() []byte {
+	mem := make([]byte, type1StaticSize)
+	copy(mem, []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07})
+	copy(mem[type1CCOffset:], []byte{0xE1, 0x10, 0x0E, 0x00})
+	copy(mem[type1DataOffset:], []byte{TLVNDEF, 0x00, TLVTerminator})
+	return mem
+}
+
+func newMockType1Tag(mem []byte) (*pcscType1Tag, *mockScardCard) {
+	card := newMockScardCard()
+	card.type1Mem = mem
+	dev := newMockPCSCDevice(card, pcscATR(0x30))
+	return newPCSCType1Tag(dev, "01020304"), card
+}
+
+func TestPCSCType1Tag_WriteReadRoundTrip(t *testing.T) {
+	tag, card := newMockType1Tag(newType1Memory())
+	ndef := []byte{0xD1, 0x01, 0x05, 0x54, 0x02, 0x65, 0x6E, 0x68, 0x69}
+
+	if err := tag.WriteData(ndef); err != nil {
+		t.Fatalf("WriteData() error = %v", err)
+	}
+
+	got, err := tag.ReadData()
+	if err != nil {
+		t.Fatalf("ReadData() error = %v", err)
+	}
+	if !bytes.Equal(got, ndef) {
+		t.Errorf("ReadData() = %X, want %X", got, ndef)
+	}
+
+	// The NDEF magic is cleared before the message and restored after it
+	var writes [][]byte
+	for _, cmd := range card.callLog {
+		if cmd[1] == INSDirectCmd && cmd[5] == type1CmdWriteE {
+			writes = append(writes, cmd[6:8])
+		}
+	}
+	if len(writes) < 2 {
+		t.Fatalf("Expected WRITE-E commands, got %d", len(writes))
+	}
+	if first := writes[0]; first[0] != type1CCOffset || first[1] != 0x00 {
+		t.Errorf("First write = %X, want NDEF magic cleared", first)
+	}
+	if last := writes[len(writes)-1]; last[0] != type1CCOffset || last[1] != type1NDEFMagic {
+		t.Errorf("Last write = %X, want NDEF magic restored", last)
+	}
+}
+
+func TestPCSCType1Tag_KeepsLockControlTLV(t *testing.T) {
+	mem := newType1Memory()
+	lockTLV := []byte{TLVLockCtrl, 0x03, 0xF2, 0x30, 0x33}
+	copy(mem[type1DataOffset:], append(lockTLV, TLVNDEF, 0x00, TLVTerminator))
+	tag, _ := newMockType1Tag(mem)
+
+	if err := tag.WriteData([]byte{0xD0, 0x00, 0x00}); err != nil {
+		t.Fatalf("WriteData() error = %v", err)
+	}
+	if !bytes.Equal(mem[type1DataOffset:type1DataOffset+len(lockTLV)], lockTLV) {
+		t.Errorf("Lock Control TLV overwritten: %X", mem[type1DataOffset:type1DataOffset+len(lockTLV)])
+	}
+	if got, err := tag.ReadData(); err != nil || !bytes.Equal(got, []byte{0xD0, 0x00, 0x00}) {
+		t.Errorf("ReadData() = %X, %v", got, err)
+	}
+}
+
+func TestPCSCType1Tag_Refusals(t *testing.T) {
+	t.Run("read-only", func(t *testing.T) {
+		mem := newType1Memory()
+		mem[type1CCOffset+3] = 0x0F
+		tag, _ := newMockType1Tag(mem)
+
+		if writable, err := tag.IsWritable(); err != nil || writable {
+			t.Errorf("IsWritable() = %v, %v; want false", writable, err)
+		}
+		if err := tag.WriteData([]byte{0xD0, 0x00, 0x00}); err == nil || !strings.Contains(err.Error(), "read-only") {
+			t.Errorf("WriteData() error = %v, want read-only", err)
+		}
+	})
+
+	t.Run("not formatted", func(t *testing.T) {
+		mem := newType1Memory()
+		mem[type1CCOffset] = 0x00
+		tag, _ := newMockType1Tag(mem)
+
+		if _, err := tag.ReadData(); err == nil || !strings.Contains(err.Error(), "not NDEF formatted") {
+			t.Errorf("ReadData() error = %v, want not NDEF formatted", err)
+		}
+	})
+
+	t.Run("too large", func(t *testing.T) {
+		mem := newType1Memory()
+		tag, card := newMockType1Tag(mem)
+
+		err := tag.WriteData(make([]byte, 90))
+		if err == nil || !strings.Contains(err.Error(), "data too large") {
+			t.Errorf("WriteData() error = %v, want data too large", err)
+		}
+		if len(card.callLog) != 1 {
+			t.Errorf("Expected only the RALL before refusing, got %d commands", len(card.callLog))
+		}
+	})
+}
+
+func TestDetectTagTypeFromATR_Topaz(t *testing.T) {
+	for _, name := range []byte{0x2F, 0x30} {
+		if got := detectTagTypeFromATR(pcscATR(name)); got != DetectedTopaz {
+			t.Errorf("card name 0x%02X: detectTagTypeFromATR() = %s, want Topaz", name, detectedTypeName(got))
+		}
+	}
+}
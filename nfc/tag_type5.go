@@ -0,0 +1,277 @@
+package nfc
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+)
+
+// Type 5 (ISO15693 / NFC-V) constants
+const (
+	// type5BlockSize is the block size used by the common ISO15693 tags (ICODE SLIX, Tag-it)
+	type5BlockSize = 4
+
+	// type5CCMagic1 marks a CC using 1-byte block addressing (4-byte CC)
+	type5CCMagic1 = 0xE1
+	// type5CCMagic2 marks a CC using 2-byte block addressing (8-byte CC)
+	type5CCMagic2 = 0xE2
+
+	// type5AccessReadOnly is the CC write-access nibble for a read-only tag
+	type5AccessReadOnly = 0x03
+
+	// ISO15693 extended commands taking a 2-byte block number, sent by direct
+	// transmit for blocks beyond READ BINARY's 1-byte address
+	type5FlagHighDataRate  = 0x02
+	type5FlagError         = 0x01
+	type5CmdExtReadSingle  = 0x30
+	type5CmdExtWriteSingle = 0x31
+	type5MaxBlock          = 0xFFFF
+)
+
+// pcscType5Tag implements Tag for NFC Forum Type 5 (ISO15693) tags.
+//
+// Blocks are accessed with PC/SC READ BINARY / UPDATE BINARY, which PC/SC
+// readers translate into ISO15693 READ SINGLE BLOCK / WRITE SINGLE BLOCK.
+// Blocks above 255, on large tags such as ICODE SLIX2 or ST25DV, use the
+// extended commands sent by direct transmit.
+type pcscType5Tag struct {
+	pcscBaseTag
+}
+
+func newPCSCType5Tag(dev *pcscDevice, uid string) *pcscType5Tag {
+	return &pcscType5Tag{
+		pcscBaseTag: pcscBaseTag{
+			device:       dev,
+			uid:          uid,
+			detectedType: DetectedISO15693,
+		},
+	}
+}
+
+func (t *pcscType5Tag) Type() string {
+	return CardTypeType5
+}
+
+func (t *pcscType5Tag) NumericType() int {
+	return detectedTypeNumeric(t.detectedType)
+}
+
+func (t *pcscType5Tag) Capabilities() TagCapabilities {
+	return InferTagCapabilities(t.Type())
+}
+
+func (t *pcscType5Tag) Transceive(data []byte) ([]byte, error) {
+	return t.transceive(data)
+}
+
+// readBlock reads a single block (READ SINGLE BLOCK, or EXTENDED READ SINGLE
+// BLOCK above block 255)
+func (t *pcscType5Tag) readBlock(block int) ([]byte, error) {
+	if block > 0xFF {
+		return t.readBlockExtended(block)
+	}
+	data, err := t.transceive(ReadBinaryAPDU(byte(block), type5BlockSize))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < type5BlockSize {
+		return nil, fmt.Errorf("short read from block %d: got %d bytes", block, len(data))
+	}
+	return data[:type5BlockSize], nil
+}
+
+// writeBlock writes a single block (WRITE SINGLE BLOCK, or EXTENDED WRITE
+// SINGLE BLOCK above block 255)
+func (t *pcscType5Tag) writeBlock(block int, data []byte) error {
+	if len(data) != type5BlockSize {
+		return fmt.Errorf("block data must be %d bytes, got %d", type5BlockSize, len(data))
+	}
+	if block > 0xFF {
+		return t.writeBlockExtended(block, data)
+	}
+	_, err := t.transceive(UpdateBinaryAPDU(byte(block), data))
+	return err
+}
+
+// extendedBlockCommand sends an ISO15693 extended command for block, with
+// the block number least significant byte first, and returns the response
+// after its flags byte.
+func (t *pcscType5Tag) extendedBlockCommand(cmd byte, block int, data []byte) ([]byte, error) {
+	if block > type5MaxBlock {
+		return nil, fmt.Errorf("block %d out of range (max %d)", block, type5MaxBlock)
+	}
+	native := append([]byte{type5FlagHighDataRate, cmd, byte(block), byte(block >> 8)}, data...)
+	resp, err := t.transceive(DirectTransmitAPDU(native))
+	if err != nil {
+		return nil, err
+	}
+	if len(resp) == 0 {
+		return nil, fmt.Errorf("empty response for block %d", block)
+	}
+	if resp[0]&type5FlagError != 0 {
+		code := byte(0)
+		if len(resp) > 1 {
+			code = resp[1]
+		}
+		return nil, fmt.Errorf("block %d: tag error 0x%02X", block, code)
+	}
+	return resp[1:], nil
+}
+
+// readBlockExtended reads a block with EXTENDED READ SINGLE BLOCK.
+func (t *pcscType5Tag) readBlockExtended(block int) ([]byte, error) {
+	data, err := t.extendedBlockCommand(type5CmdExtReadSingle, block, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < type5BlockSize {
+		return nil, fmt.Errorf("short read from block %d: got %d bytes", block, len(data))
+	}
+	return data[:type5BlockSize], nil
+}
+
+// writeBlockExtended writes a block with EXTENDED WRITE SINGLE BLOCK.
+func (t *pcscType5Tag) writeBlockExtended(block int, data []byte) error {
+	_, err := t.extendedBlockCommand(type5CmdExtWriteSingle, block, data)
+	return err
+}
+
+// type5CC holds the parsed Capability Container of a Type 5 tag
+type type5CC struct {
+	firstDataBlock int  // Block where the TLV area starts
+	dataSize       int  // Size of the TLV area in bytes
+	readOnly       bool // Write access nibble indicates read-only
+}
+
+// parseType5CC parses the Capability Container from the first CC block(s).
+// cc must hold at least 4 bytes (8 when MLEN is 0).
+func parseType5CC(cc []byte) (type5CC, error) {
+	if len(cc) < 4 {
+		return type5CC{}, fmt.Errorf("capability container too short")
+	}
+	if cc[0] != type5CCMagic1 && cc[0] != type5CCMagic2 {
+		return type5CC{}, fmt.Errorf("tag is not NDEF formatted (CC magic 0x%02X)", cc[0])
+	}
+
+	parsed := type5CC{readOnly: cc[1]&0x03 == type5AccessReadOnly}
+
+	// The magic only selects the block addressing mode; an MLEN of 0 is
+	// what signals the 8-byte CC carrying the size in bytes 6-7
+	if !type5CCExtended(cc) {
+		// 4-byte CC: MLEN is the data area size in 8-byte units
+		parsed.dataSize = int(cc[2]) * 8
+		parsed.firstDataBlock = 4 / type5BlockSize
+		return parsed, nil
+	}
+
+	if len(cc) < 8 {
+		return type5CC{}, fmt.Errorf("8-byte capability container truncated")
+	}
+	parsed.dataSize = (int(cc[6])<<8 | int(cc[7])) * 8
+	parsed.firstDataBlock = 8 / type5BlockSize
+	return parsed, nil
+}
+
+// type5CCExtended reports whether cc starts an 8-byte Capability Container
+func type5CCExtended(cc []byte) bool {
+	return (cc[0] == type5CCMagic1 || cc[0] == type5CCMagic2) && cc[2] == 0
+}
+
+// readCC reads and parses the Capability Container
+func (t *pcscType5Tag) readCC() (type5CC, error) {
+	cc, err := t.readBlock(0)
+	if err != nil {
+		return type5CC{}, fmt.Errorf("failed to read capability container: %w", err)
+	}
+
+	if type5CCExtended(cc) {
+		ext, err := t.readBlock(1)
+		if err != nil {
+			return type5CC{}, fmt.Errorf("failed to read capability container: %w", err)
+		}
+		cc = append(cc, ext...)
+	}
+
+	return parseType5CC(cc)
+}
+
+func (t *pcscType5Tag) ReadData() ([]byte, error) {
+	cc, err := t.readCC()
+	if err != nil {
+		return nil, err
+	}
+
+	lastBlock := cc.firstDataBlock + (cc.dataSize+type5BlockSize-1)/type5BlockSize
+	var allData []byte
+
+	for block := cc.firstDataBlock; block < lastBlock; block++ {
+		data, err := t.readBlock(block)
+		if err != nil {
+			if IsCardRemovedError(err) {
+				return nil, err
+			}
+			log.Printf("Error reading Type 5 block %d: %v", block, err)
+			break
+		}
+		allData = append(allData, data...)
+
+		if ndefData, found := TLVFindNDEF(allData); found {
+			return ndefData, nil
+		}
+		// Stop at the terminator unless an NDEF TLV is still being read
+		if !TLVNDEFTruncated(allData) && bytes.IndexByte(data, TLVTerminator) >= 0 {
+			break
+		}
+	}
+
+	if len(allData) == 0 && !t.device.IsCardPresent() {
+		return nil, NewCardRemovedError(fmt.Errorf("card removed during read"))
+	}
+
+	return nil, fmt.Errorf("no NDEF message found")
+}
+
+func (t *pcscType5Tag) WriteData(data []byte) error {
+	cc, err := t.readCC()
+	if err != nil {
+		return err
+	}
+	if cc.readOnly {
+		return fmt.Errorf("tag is read-only")
+	}
+
+	tlvPayload := TLVEncode(data, TLVNDEF)
+	if len(tlvPayload) > cc.dataSize {
+		return fmt.Errorf("data too large: need %d bytes, have %d", len(tlvPayload), cc.dataSize)
+	}
+
+	// Pad to block boundary
+	for len(tlvPayload)%type5BlockSize != 0 {
+		tlvPayload = append(tlvPayload, 0x00)
+	}
+
+	for i := 0; i < len(tlvPayload); i += type5BlockSize {
+		block := cc.firstDataBlock + i/type5BlockSize
+		if err := t.writeBlock(block, tlvPayload[i:i+type5BlockSize]); err != nil {
+			return fmt.Errorf("failed to write block %d: %w", block, err)
+		}
+	}
+
+	return nil
+}
+
+func (t *pcscType5Tag) IsWritable() (bool, error) {
+	cc, err := t.readCC()
+	if err != nil {
+		return false, err
+	}
+	return !cc.readOnly, nil
+}
+
+func (t *pcscType5Tag) CanMakeReadOnly() (bool, error) {
+	return false, nil
+}
+
+func (t *pcscType5Tag) MakeReadOnly() error {
+	return fmt.Errorf("Type 5 MakeReadOnly not yet implemented")
+}
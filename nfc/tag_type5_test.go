@@ -0,0 +1,140 @@
+package nfc
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestParseType5CC(t *testing.T) {
+	tests := []struct {
+		name      string
+		cc        []byte
+		wantBlock int
+		wantSize  int
+		wantRO    bool
+		wantErr   bool
+	}{
+		{"4-byte CC", []byte{0xE1, 0x40, 0x0E, 0x01}, 1, 112, false, false},
+		{"4-byte CC read-only", []byte{0xE1, 0x43, 0x0E, 0x01}, 1, 112, true, false},
+		{"8-byte CC", []byte{0xE2, 0x40, 0x00, 0x01, 0x00, 0x00, 0x01, 0x00}, 2, 2048, false, false},
+		{"4-byte E2 CC", []byte{0xE2, 0x40, 0x3F, 0x01, 0x03, 0x10, 0xD1, 0x01}, 1, 504, false, false},
+		{"E1 with zero MLEN", []byte{0xE1, 0x40, 0x00, 0x01, 0x00, 0x00, 0x00, 0x40}, 2, 512, false, false},
+		{"8-byte CC truncated", []byte{0xE2, 0x40, 0x00, 0x01}, 0, 0, false, true},
+		{"not formatted", []byte{0x00, 0x00, 0x00, 0x00}, 0, 0, false, true},
+		{"too short", []byte{0xE1}, 0, 0, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cc, err := parseType5CC(tt.cc)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseType5CC() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if cc.firstDataBlock != tt.wantBlock {
+				t.Errorf("firstDataBlock = %d, want %d", cc.firstDataBlock, tt.wantBlock)
+			}
+			if cc.dataSize != tt.wantSize {
+				t.Errorf("dataSize = %d, want %d", cc.dataSize, tt.wantSize)
+			}
+			if cc.readOnly != tt.wantRO {
+				t.Errorf("readOnly = %v, want %v", cc.readOnly, tt.wantRO)
+			}
+		})
+	}
+}
+
+func TestDetectTagTypeFromATR_ISO15693(t *testing.T) {
+	// ICODE SLIX as reported by a PC/SC reader (standard byte 0x0B = ISO15693 part 3)
+	atr := []byte{0x3B, 0x8F, 0x80, 0x01, 0x80, 0x4F, 0x0C, 0xA0, 0x00, 0x00, 0x03, 0x06,
+		0x0B, 0x00, 0x14, 0x00, 0x00, 0x00, 0x00, 0x77}
+
+	if got := detectTagTypeFromATR(atr); got != DetectedISO15693 {
+		t.Errorf("detectTagTypeFromATR() = %s, want %s", detectedTypeName(got), detectedTypeName(DetectedISO15693))
+	}
+
+	// Classic 1K must still be detected from the card name bytes
+	atr[12] = 0x03
+	atr[14] = 0x01
+	if got := detectTagTypeFromATR(atr); got != DetectedClassic1K {
+		t.Errorf("detectTagTypeFromATR() = %s, want %s", detectedTypeName(got), detectedTypeName(DetectedClassic1K))
+	}
+}
+
+func TestDetectTagTypeFromATR_MifarePlusSL1(t *testing.T) {
+	// MIFARE Plus SL1 2K as reported by a PC/SC reader (card name 0x0036)
+	atr := []byte{0x3B, 0x8F, 0x80, 0x01, 0x80, 0x4F, 0x0C, 0xA0, 0x00, 0x00, 0x03, 0x06,
+		0x03, 0x00, 0x36, 0x00, 0x00, 0x00, 0x00, 0x68}
+
+	tagType := detectTagTypeFromATR(atr)
+	if tagType != DetectedPlus2K {
+		t.Fatalf("detectTagTypeFromATR() = %s, want %s", detectedTypeName(tagType), detectedTypeName(DetectedPlus2K))
+	}
+
+	// SL1 cards must be handled by the Classic read path
+	var tag Tag = newPCSCClassicTag(nil, "04112233", tagType)
+	if _, ok := tag.(ClassicTag); !ok {
+		t.Fatal("MIFARE Plus SL1 tag does not implement ClassicTag")
+	}
+	if tag.Type() != CardTypeMifareClassic1K {
+		t.Errorf("Type() = %s, want %s", tag.Type(), CardTypeMifareClassic1K)
+	}
+	if tag.NumericType() != 0x08 {
+		t.Errorf("NumericType() = %#x, want 0x08", tag.NumericType())
+	}
+
+	// SL2 is not Classic compatible and must not be reported as SL1
+	atr[14] = 0x38
+	if got := detectTagTypeFromATR(atr); got != DetectedPlus2KSL2 {
+		t.Errorf("detectTagTypeFromATR() = %s, want %s", detectedTypeName(got), detectedTypeName(DetectedPlus2KSL2))
+	}
+}
+
+// TestType5Tag_ReadCCFourByteE2 tests that a 2-byte addressing tag with a
+// nonzero MLEN keeps its 4-byte CC and never reads block 1 as CC data.
+func TestType5Tag_ReadCCFourByteE2(t *testing.T) {
+	card := newMockScardCard()
+	card.addResponse(hex.EncodeToString(ReadBinaryAPDU(0, type5BlockSize)), "E2403F019000")
+	tag := newPCSCType5Tag(newMockPCSCDevice(card, pcscATR(0x01)), "E004010000000000")
+
+	cc, err := tag.readCC()
+	if err != nil {
+		t.Fatalf("readCC() failed: %v", err)
+	}
+	if cc.firstDataBlock != 1 || cc.dataSize != 504 {
+		t.Errorf("readCC() = block %d size %d, want block 1 size 504", cc.firstDataBlock, cc.dataSize)
+	}
+	if len(card.callLog) != 1 {
+		t.Errorf("readCC() sent %d commands, want 1", len(card.callLog))
+	}
+}
+
+// TestType5Tag_ExtendedBlocks tests that blocks above 255 are read and
+// written with the extended commands instead of wrapping the block number.
+func TestType5Tag_ExtendedBlocks(t *testing.T) {
+	card := newMockScardCard()
+	card.addResponse(hex.EncodeToString(DirectTransmitAPDU([]byte{0x02, 0x30, 0x2C, 0x01})), "00DEADBEEF9000")
+	card.addResponse(hex.EncodeToString(DirectTransmitAPDU([]byte{0x02, 0x31, 0x2C, 0x01, 0x01, 0x02, 0x03, 0x04})), "009000")
+	card.addResponse(hex.EncodeToString(DirectTransmitAPDU([]byte{0x02, 0x30, 0x2D, 0x01})), "01109000")
+	card.addResponse(hex.EncodeToString(ReadBinaryAPDU(0x2C, type5BlockSize)), "CAFEF00D9000")
+	tag := newPCSCType5Tag(newMockPCSCDevice(card, pcscATR(0x01)), "E004010000000000")
+
+	if data, err := tag.readBlock(300); err != nil || !bytes.Equal(data, []byte{0xDE, 0xAD, 0xBE, 0xEF}) {
+		t.Errorf("readBlock(300) = %X, %v, want DEADBEEF", data, err)
+	}
+	if data, err := tag.readBlock(0x2C); err != nil || !bytes.Equal(data, []byte{0xCA, 0xFE, 0xF0, 0x0D}) {
+		t.Errorf("readBlock(44) = %X, %v, want CAFEF00D", data, err)
+	}
+	if err := tag.writeBlock(300, []byte{0x01, 0x02, 0x03, 0x04}); err != nil {
+		t.Errorf("writeBlock(300) failed: %v", err)
+	}
+	if _, err := tag.readBlock(301); err == nil {
+		t.Error("Expected the tag's error flag to fail readBlock(301)")
+	}
+	if _, err := tag.readBlock(type5MaxBlock + 1); err == nil {
+		t.Error("Expected an error for a block beyond 2-byte addressing")
+	}
+}
@@ -53,6 +53,24 @@ func (t *pcscUltralightTag) writePage(page byte, data []byte) error {
 	return err
 }
 
+// PageCount returns the number of pages: 16 for Ultralight, 48 for Ultralight C.
+func (t *pcscUltralightTag) PageCount() int {
+	if t.isC {
+		return 48
+	}
+	return 16
+}
+
+// ReadPages reads count raw pages starting at start.
+func (t *pcscUltralightTag) ReadPages(start, count int) ([]byte, error) {
+	return readType2Pages(&t.pcscBaseTag, start, count, t.PageCount())
+}
+
+// WritePage writes one raw page.
+func (t *pcscUltralightTag) WritePage(page int, data [4]byte) error {
+	return writeType2Page(&t.pcscBaseTag, page, data, t.PageCount())
+}
+
 func (t *pcscUltralightTag) ReadData() ([]byte, error) {
 	// Read pages 4 onwards (user data area)
 	var allData []byte
@@ -96,37 +114,23 @@ func (t *pcscUltralightTag) ReadData() ([]byte, error) {
 	return ndefData, nil
 }
 
-func (t *pcscUltralightTag) WriteData(data []byte) error {
-	// Build TLV payload
-	tlvPayload := TLVEncode(data, TLVNDEF)
-
-	// Calculate required pages
-	totalBytes := len(tlvPayload)
-	requiredPages := (totalBytes + 3) / 4
-
-	// Check if it fits
-	maxPages := 12 // Pages 4-15 for Ultralight
+// userPages returns the number of data pages: pages 4-15 for Ultralight and
+// 4-39 for Ultralight C, whose later pages hold the lock and auth configuration.
+func (t *pcscUltralightTag) userPages() int {
 	if t.isC {
-		maxPages = 36 // Pages 4-39 for Ultralight C (excluding auth pages)
-	}
-	if requiredPages > maxPages {
-		return fmt.Errorf("data too large: need %d pages, have %d", requiredPages, maxPages)
-	}
-
-	// Pad to 4-byte boundary
-	for len(tlvPayload)%4 != 0 {
-		tlvPayload = append(tlvPayload, 0x00)
+		return 36
 	}
+	return 12
+}
 
-	// Write pages starting at page 4
-	for i := 0; i < len(tlvPayload); i += 4 {
-		page := byte(4 + i/4)
-		if err := t.writePage(page, tlvPayload[i:i+4]); err != nil {
-			return fmt.Errorf("failed to write page %d: %w", page, err)
-		}
-	}
+func (t *pcscUltralightTag) WriteData(data []byte) error {
+	return writeType2NDEF(t, data, t.userPages(), false)
+}
 
-	return nil
+// WriteDataWithOptions writes NDEF data, zero-filling the rest of the data
+// area with opts.WipeTrailing (implements AdvancedWriter).
+func (t *pcscUltralightTag) WriteDataWithOptions(data []byte, opts TagWriteOptions) error {
+	return writeType2NDEF(t, data, t.userPages(), opts.WipeTrailing)
 }
 
 func (t *pcscUltralightTag) IsWritable() (bool, error) {
@@ -135,6 +139,12 @@ func (t *pcscUltralightTag) IsWritable() (bool, error) {
 	return err == nil, nil
 }
 
+// QuickWritable checks the write access byte of the Capability Container
+// (implements QuickWritabilityChecker).
+func (t *pcscUltralightTag) QuickWritable() (bool, error) {
+	return quickWritableType2(t)
+}
+
 func (t *pcscUltralightTag) CanMakeReadOnly() (bool, error) {
 	return true, nil
 }
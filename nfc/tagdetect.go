@@ -19,8 +19,12 @@ const (
 	DetectedDESFireEV1
 	DetectedDESFireEV2
 	DetectedISO14443_4
-	DetectedPlus2K
-	DetectedPlus4K
+	DetectedPlus2K // MIFARE Plus 2K in SL1 (Classic compatible)
+	DetectedPlus4K // MIFARE Plus 4K in SL1 (Classic compatible)
+	DetectedISO15693
+	DetectedPlus2KSL2
+	DetectedPlus4KSL2
+	DetectedTopaz // NFC Forum Type 1 (Topaz / Jewel)
 )
 
 // ATR historical byte patterns for tag type detection
@@ -31,11 +35,17 @@ var atrPatterns = map[byte]DetectedTagType{
 	0x03: DetectedUltralight,
 	0x04: DetectedMini,
 	0x05: DetectedUltralightC,
-	0x06: DetectedPlus2K,  // MIFARE Plus 2K in SL1
-	0x07: DetectedPlus4K,  // MIFARE Plus 4K in SL1
-	0x0A: DetectedPlus2K,  // MIFARE Plus 2K in SL2
-	0x0B: DetectedPlus4K,  // MIFARE Plus 4K in SL2
-	0x26: DetectedDESFire, // DESFire (various versions)
+	0x06: DetectedPlus2K,    // MIFARE Plus 2K in SL1
+	0x07: DetectedPlus4K,    // MIFARE Plus 4K in SL1
+	0x0A: DetectedPlus2KSL2, // MIFARE Plus 2K in SL2
+	0x0B: DetectedPlus4KSL2, // MIFARE Plus 4K in SL2
+	0x26: DetectedDESFire,   // DESFire (various versions)
+	0x2F: DetectedTopaz,     // Jewel (PC/SC registry name)
+	0x30: DetectedTopaz,     // Topaz (PC/SC registry name)
+	0x36: DetectedPlus2K,    // MIFARE Plus SL1 2K (PC/SC registry name)
+	0x37: DetectedPlus4K,    // MIFARE Plus SL1 4K (PC/SC registry name)
+	0x38: DetectedPlus2KSL2, // MIFARE Plus SL2 2K (PC/SC registry name)
+	0x39: DetectedPlus4KSL2, // MIFARE Plus SL2 4K (PC/SC registry name)
 }
 
 // detectTagTypeFromATR parses ATR and returns detected tag type
@@ -70,6 +80,11 @@ func detectTagTypeFromATR(atr []byte) DetectedTagType {
 				histBytes[i+5] == 0x00 &&
 				histBytes[i+6] == 0x03 &&
 				histBytes[i+7] == 0x06 {
+				// Standard byte at offset +8: 0x09-0x0C are ISO15693 parts 1-4
+				if ss := histBytes[i+8]; ss >= 0x09 && ss <= 0x0C {
+					return DetectedISO15693
+				}
+
 				// Found the pattern, card type is at offset +10
 				cardType := histBytes[i+10]
 				if t, ok := atrPatterns[cardType]; ok {
@@ -265,6 +280,14 @@ func detectedTypeName(tagType DetectedTagType) string {
 		return "MIFARE Plus 2K"
 	case DetectedPlus4K:
 		return "MIFARE Plus 4K"
+	case DetectedPlus2KSL2:
+		return "MIFARE Plus 2K (SL2)"
+	case DetectedPlus4KSL2:
+		return "MIFARE Plus 4K (SL2)"
+	case DetectedISO15693:
+		return "ISO15693"
+	case DetectedTopaz:
+		return "Topaz"
 	default:
 		return "Unknown"
 	}
@@ -274,10 +297,14 @@ func detectedTypeName(tagType DetectedTagType) string {
 // These match the SAK values used for tag identification
 func detectedTypeNumeric(tagType DetectedTagType) int {
 	switch tagType {
-	case DetectedClassic1K:
-		return 0x08 // Classic 1K SAK
-	case DetectedClassic4K:
-		return 0x18 // Classic 4K SAK
+	case DetectedClassic1K, DetectedPlus2K:
+		return 0x08 // Classic 1K SAK (MIFARE Plus 2K in SL1 reports the same)
+	case DetectedClassic4K, DetectedPlus4K:
+		return 0x18 // Classic 4K SAK (MIFARE Plus 4K in SL1 reports the same)
+	case DetectedPlus2KSL2:
+		return 0x10 // MIFARE Plus 2K SL2 SAK
+	case DetectedPlus4KSL2:
+		return 0x11 // MIFARE Plus 4K SL2 SAK
 	case DetectedUltralight, DetectedUltralightC, DetectedUltralightEV1:
 		return 0x00 // Ultralight SAK
 	case DetectedNTAG213, DetectedNTAG215, DetectedNTAG216:
@@ -210,6 +210,70 @@ func TLVFindNDEF(data []byte) ([]byte, bool) {
 	return nil, false
 }
 
+// TLVLocateNDEF returns the offset of the NDEF Message TLV in a TLV block,
+// i.e. where its type byte is, if the whole TLV fits in data.
+func TLVLocateNDEF(data []byte) (offset int, found bool) {
+	for offset < len(data) {
+		switch data[offset] {
+		case TLVNull:
+			offset++
+			continue
+
+		case TLVTerminator:
+			return 0, false
+
+		default:
+			fls, fvs := TLVRecordLength(data[offset:])
+			if fls == 0 || fvs == 0 {
+				return 0, false
+			}
+			end := offset + fvs + TLVGetLength(data[offset:])
+			if data[offset] == TLVNDEF {
+				if end > len(data) {
+					return 0, false
+				}
+				return offset, true
+			}
+			offset = end
+		}
+	}
+
+	return 0, false
+}
+
+// TLVNDEFTruncated reports whether data contains an NDEF Message TLV whose
+// declared length runs past the end of the buffer, i.e. the read came up short.
+func TLVNDEFTruncated(data []byte) bool {
+	offset := 0
+
+	for offset < len(data) {
+		switch data[offset] {
+		case TLVNull:
+			offset++
+			continue
+
+		case TLVTerminator:
+			return false
+
+		case TLVNDEF:
+			fls, fvs := TLVRecordLength(data[offset:])
+			if fls == 0 || fvs == 0 {
+				return true
+			}
+			return offset+fvs+TLVGetLength(data[offset:]) > len(data)
+
+		default:
+			fls, fvs := TLVRecordLength(data[offset:])
+			if fls == 0 || fvs == 0 {
+				return false
+			}
+			offset += fvs + TLVGetLength(data[offset:])
+		}
+	}
+
+	return false
+}
+
 // ParseTLVBlock parses all TLVs in a block and returns a map of type -> value
 // Useful for parsing Capability Container TLVs
 func ParseTLVBlock(data []byte) map[byte][]byte {
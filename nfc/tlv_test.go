@@ -199,3 +199,50 @@ func TestTLVGetLength_Long(t *testing.T) {
 		t.Errorf("Expected length 256, got %d", length)
 	}
 }
+
+func TestTLVNDEFTruncated(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want bool
+	}{
+		{"complete", []byte{0x03, 0x02, 0xAA, 0xBB, 0xFE}, false},
+		{"short value", []byte{0x03, 0x10, 0xAA, 0xBB}, true},
+		{"short long-format header", []byte{0x03, 0xFF, 0x01}, true},
+		{"after lock control", []byte{0x01, 0x01, 0x00, 0x03, 0x05, 0xAA}, true},
+		{"terminator only", []byte{0x00, 0xFE}, false},
+		{"empty", []byte{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TLVNDEFTruncated(tt.data); got != tt.want {
+				t.Errorf("TLVNDEFTruncated(%X) = %v, want %v", tt.data, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTLVLocateNDEF(t *testing.T) {
+	tests := []struct {
+		name       string
+		data       []byte
+		wantOffset int
+		wantFound  bool
+	}{
+		{"at start", []byte{0x03, 0x02, 0xAA, 0xBB, 0xFE}, 0, true},
+		{"after nulls", []byte{0x00, 0x00, 0x03, 0x01, 0xAA, 0xFE}, 2, true},
+		{"after lock control", []byte{0x01, 0x01, 0x00, 0x03, 0x01, 0xAA}, 3, true},
+		{"truncated", []byte{0x03, 0x10, 0xAA, 0xBB}, 0, false},
+		{"terminator only", []byte{0x00, 0xFE}, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			offset, found := TLVLocateNDEF(tt.data)
+			if offset != tt.wantOffset || found != tt.wantFound {
+				t.Errorf("TLVLocateNDEF(%X) = %d, %v; want %d, %v", tt.data, offset, found, tt.wantOffset, tt.wantFound)
+			}
+		})
+	}
+}
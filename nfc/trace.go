@@ -0,0 +1,128 @@
+package nfc
+
+import (
+	"sync"
+	"time"
+)
+
+// MaxTraceEntries caps the exchanges kept by an APDUTrace. Later exchanges are
+// counted in Dropped instead of recorded.
+const MaxTraceEntries = 1024
+
+// insVerify is the ISO 7816-4 VERIFY instruction, which carries a PIN.
+const insVerify = 0x20
+
+// TraceEntry is one APDU exchange recorded by an APDUTrace.
+type TraceEntry struct {
+	TX       []byte        // Command sent to the card (key and PIN bytes redacted)
+	RX       []byte        // Raw response including SW1 SW2, nil on error
+	Err      error         // Transmit error, if any
+	Duration time.Duration // Time spent waiting for the response
+}
+
+// APDUTrace records the APDUs exchanged with the card during one operation,
+// for diagnosing a failing card without access to the agent's logs.
+// Attach it with WriteOptions.Trace or ReadOptions.Trace.
+type APDUTrace struct {
+	mu         sync.Mutex
+	entries    []TraceEntry
+	dropped    int
+	redactData bool
+}
+
+// NewAPDUTrace creates an empty trace.
+func NewAPDUTrace() *APDUTrace {
+	return &APDUTrace{}
+}
+
+// NewRedactedAPDUTrace creates an empty trace that masks card data: commands
+// keep only CLA, INS, P1, P2 and their lengths, and responses only their
+// status word.
+func NewRedactedAPDUTrace() *APDUTrace {
+	return &APDUTrace{redactData: true}
+}
+
+// Record appends an exchange. The slices are copied.
+func (t *APDUTrace) Record(tx, rx []byte, err error, d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.entries) >= MaxTraceEntries {
+		t.dropped++
+		return
+	}
+	if t.redactData {
+		tx, rx = maskAPDUData(tx), maskResponseData(rx)
+	}
+	t.entries = append(t.entries, TraceEntry{
+		TX:       append([]byte(nil), tx...),
+		RX:       append([]byte(nil), rx...),
+		Err:      err,
+		Duration: d,
+	})
+}
+
+// Entries returns the recorded exchanges in order.
+func (t *APDUTrace) Entries() []TraceEntry {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]TraceEntry(nil), t.entries...)
+}
+
+// Dropped returns the number of exchanges not recorded because the trace was full.
+func (t *APDUTrace) Dropped() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.dropped
+}
+
+// redactAPDU returns cmd with secrets replaced by 0xFF bytes: the key of a
+// PC/SC LOAD KEY and the PIN of an ISO 7816 VERIFY.
+func redactAPDU(cmd []byte) []byte {
+	if len(cmd) <= 5 {
+		return cmd
+	}
+	isLoadKey := cmd[0] == CLAPCSC && cmd[1] == INSLoadKey
+	isVerify := cmd[0]&0xF0 == CLAStandard && cmd[1] == insVerify
+	if !isLoadKey && !isVerify {
+		return cmd
+	}
+
+	redacted := append([]byte(nil), cmd...)
+	for i := 5; i < len(redacted) && i < 5+int(cmd[4]); i++ {
+		redacted[i] = 0xFF
+	}
+	return redacted
+}
+
+// maskAPDUData returns cmd with its data field replaced by 0xFF bytes, keeping
+// the header, Lc and Le. Commands without a data field are returned as is.
+func maskAPDUData(cmd []byte) []byte {
+	if len(cmd) <= 5 {
+		return cmd
+	}
+	start, n := 5, int(cmd[4])
+	if cmd[4] == 0 && len(cmd) >= 7 {
+		// Extended length: 00 followed by a 2-byte Lc
+		start, n = 7, int(cmd[5])<<8|int(cmd[6])
+	}
+
+	masked := append([]byte(nil), cmd...)
+	for i := start; i < len(masked) && i < start+n; i++ {
+		masked[i] = 0xFF
+	}
+	return masked
+}
+
+// maskResponseData returns resp with everything but the status word replaced
+// by 0xFF bytes.
+func maskResponseData(resp []byte) []byte {
+	if len(resp) <= 2 {
+		return resp
+	}
+	masked := append([]byte(nil), resp...)
+	for i := range masked[:len(masked)-2] {
+		masked[i] = 0xFF
+	}
+	return masked
+}
@@ -0,0 +1,112 @@
+package nfc
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+func TestAPDUTrace_Limit(t *testing.T) {
+	trace := NewAPDUTrace()
+	for i := 0; i < MaxTraceEntries+3; i++ {
+		trace.Record([]byte{0xFF, 0xCA, 0x00, 0x00, 0x00}, []byte{0x90, 0x00}, nil, 0)
+	}
+
+	if got := len(trace.Entries()); got != MaxTraceEntries {
+		t.Errorf("Expected %d entries, got %d", MaxTraceEntries, got)
+	}
+	if trace.Dropped() != 3 {
+		t.Errorf("Expected 3 dropped entries, got %d", trace.Dropped())
+	}
+}
+
+func TestRedactAPDU(t *testing.T) {
+	tests := []struct {
+		name string
+		cmd  string
+		want string
+	}{
+		{"load key", "ff82000006a0a1a2a3a4a5", "ff82000006ffffffffffff"},
+		{"verify PIN", "002000000431323334", "0020000004ffffffff"},
+		{"read binary", "ffb0000410", "ffb0000410"},
+		{"select", "00a4040007d276000085010100", "00a4040007d276000085010100"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, _ := hex.DecodeString(tt.cmd)
+			if got := hex.EncodeToString(redactAPDU(cmd)); got != tt.want {
+				t.Errorf("redactAPDU(%s) = %s, want %s", tt.cmd, got, tt.want)
+			}
+			if hex.EncodeToString(cmd) != tt.cmd {
+				t.Error("redactAPDU must not modify the command")
+			}
+		})
+	}
+}
+
+func TestPCSCDevice_Trace(t *testing.T) {
+	card := newMockScardCard()
+	card.addResponse("ffb0000410", "00112233445566778899aabbccddeeff9000")
+	dev := newMockPCSCDevice(card, pcscATR(0x01))
+
+	trace := NewAPDUTrace()
+	dev.SetTrace(trace)
+	dev.Transceive(LoadKeyAPDU(0x00, []byte{0xA0, 0xA1, 0xA2, 0xA3, 0xA4, 0xA5}))
+	dev.Transceive(ReadBinaryAPDU(0x04, 0x10))
+	dev.SetTrace(nil)
+	dev.Transceive(ReadBinaryAPDU(0x04, 0x10))
+
+	entries := trace.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 traced exchanges, got %d", len(entries))
+	}
+	if got := hex.EncodeToString(entries[0].TX); got != "ff82000006ffffffffffff" {
+		t.Errorf("Expected the key to be redacted, got TX %s", got)
+	}
+	if got := hex.EncodeToString(entries[1].RX); got != "00112233445566778899aabbccddeeff9000" {
+		t.Errorf("Unexpected RX %s", got)
+	}
+
+	// Transmit errors are traced too
+	card.transmitErr = errors.New("transmit failed")
+	dev.SetTrace(trace)
+	dev.Transceive(ReadBinaryAPDU(0x05, 0x10))
+	if entries := trace.Entries(); len(entries) != 3 || entries[2].Err == nil || entries[2].RX != nil {
+		t.Errorf("Expected a traced transmit error, got %+v", entries[len(entries)-1])
+	}
+}
+
+func TestRedactedAPDUTrace(t *testing.T) {
+	tests := []struct {
+		name   string
+		tx, rx string
+		wantTX string
+		wantRX string
+	}{
+		{"read binary", "ffb0000410", "00112233445566778899aabbccddeeff9000", "ffb0000410", "ffffffffffffffffffffffffffffffff9000"},
+		{"update binary", "ffd6000404deadbeef", "9000", "ffd6000404ffffffff", "9000"},
+		{"select with Le", "00a4040007d276000085010100", "6a82", "00a4040007ffffffffffffff00", "6a82"},
+		{"extended length", "00d600000000030102030000", "9000", "00d60000000003ffffff0000", "9000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx, _ := hex.DecodeString(tt.tx)
+			rx, _ := hex.DecodeString(tt.rx)
+			trace := NewRedactedAPDUTrace()
+			trace.Record(tx, rx, nil, 0)
+
+			entry := trace.Entries()[0]
+			if got := hex.EncodeToString(entry.TX); got != tt.wantTX {
+				t.Errorf("TX = %s, want %s", got, tt.wantTX)
+			}
+			if got := hex.EncodeToString(entry.RX); got != tt.wantRX {
+				t.Errorf("RX = %s, want %s", got, tt.wantRX)
+			}
+			if hex.EncodeToString(tx) != tt.tx || hex.EncodeToString(rx) != tt.rx {
+				t.Error("Record must not modify the exchange it is given")
+			}
+		})
+	}
+}
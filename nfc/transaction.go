@@ -0,0 +1,65 @@
+package nfc
+
+// TransactionGuard is implemented by devices that can hold back background
+// card probing, such as removal monitoring, while a multi-APDU transaction
+// runs. NFCReader.BeginTransaction and EndTransaction call it.
+type TransactionGuard interface {
+	BeginTransaction()
+	EndTransaction()
+}
+
+// BeginTransaction suspends tag polling and cache-based presence checks until
+// the matching EndTransaction, so that a command chain (e.g. a DESFire
+// authentication) is not interleaved with probe commands. It waits for a poll
+// already in progress. Transactions nest; polling resumes when the last one
+// ends.
+func (r *NFCReader) BeginTransaction() {
+	r.pollMu.Lock()
+	defer r.pollMu.Unlock()
+
+	r.statusMux.Lock()
+	defer r.statusMux.Unlock()
+	r.transactions++
+	if r.transactions > 1 {
+		return
+	}
+	if guard, ok := r.deviceManager.Device().(TransactionGuard); ok {
+		guard.BeginTransaction()
+		r.transactionDevice = guard
+	}
+}
+
+// EndTransaction ends a transaction begun with BeginTransaction. Calls without
+// a matching BeginTransaction are ignored.
+func (r *NFCReader) EndTransaction() {
+	r.statusMux.Lock()
+	if r.transactions == 0 {
+		r.statusMux.Unlock()
+		return
+	}
+	r.transactions--
+	if r.transactions > 0 {
+		r.statusMux.Unlock()
+		return
+	}
+	guard := r.transactionDevice
+	r.transactionDevice = nil
+	r.statusMux.Unlock()
+
+	if guard != nil {
+		guard.EndTransaction()
+	}
+	// Polling did not refresh the cache during the transaction; do not let
+	// the presence check time the card out before the next poll
+	if uid := r.cache.GetLastScanned(); uid != "" {
+		r.cache.UpdateLastSeenTime(uid)
+	}
+}
+
+// InTransaction reports whether a transaction begun with BeginTransaction is
+// open.
+func (r *NFCReader) InTransaction() bool {
+	r.statusMux.RLock()
+	defer r.statusMux.RUnlock()
+	return r.transactions > 0
+}
@@ -0,0 +1,90 @@
+package nfc
+
+import (
+	"testing"
+	"time"
+)
+
+// TestNFCReader_Transaction tests that polling stops for the duration of a
+// transaction and resumes when the last nested one ends
+func TestNFCReader_Transaction(t *testing.T) {
+	manager := NewMockManager()
+	manager.DevicesList = []string{"mock:usb:001"}
+
+	mockTag := NewMockTag("04A1B2C3")
+	mockTag.IsConnected = true
+	mockTag.Data = EncodeNdefMessageWithTextRecord("Hello", "en")
+
+	mockDevice := NewMockDevice()
+	mockDevice.SetTags([]Tag{mockTag})
+	manager.MockDevice = mockDevice
+
+	reader, err := NewNFCReader("mock:usb:001", manager, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Failed to create NFCReader: %v", err)
+	}
+	defer reader.Close()
+	defer reader.Stop()
+
+	reader.BeginTransaction()
+	reader.BeginTransaction()
+	if !reader.InTransaction() {
+		t.Fatal("Expected InTransaction() after BeginTransaction")
+	}
+	reader.Start()
+
+	select {
+	case data := <-reader.Data():
+		t.Fatalf("Expected no tag data during a transaction, got %+v", data)
+	case <-time.After(5 * DefaultPollingInterval):
+	}
+
+	reader.EndTransaction()
+	if !reader.InTransaction() {
+		t.Fatal("Expected the outer transaction to stay open")
+	}
+	select {
+	case data := <-reader.Data():
+		t.Fatalf("Expected no tag data during the outer transaction, got %+v", data)
+	case <-time.After(3 * DefaultPollingInterval):
+	}
+
+	reader.EndTransaction()
+	reader.EndTransaction() // Unmatched, ignored
+	if reader.InTransaction() {
+		t.Fatal("Expected no transaction after the last EndTransaction")
+	}
+
+	select {
+	case data := <-reader.Data():
+		if data.Card == nil || data.Card.UID != "04A1B2C3" {
+			t.Errorf("Expected card 04A1B2C3 after the transaction, got %+v", data)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected polling to resume after the transaction")
+	}
+}
+
+// TestPCSCDevice_TransactionDefersRemoval tests that a monitor removal signal
+// does not fail commands inside a transaction and is delivered when it ends
+func TestPCSCDevice_TransactionDefersRemoval(t *testing.T) {
+	dev := newMockPCSCDevice(newMockScardCard(), []byte{0x3B, 0x8F})
+
+	dev.BeginTransaction()
+	dev.signalRemoval()
+	if _, err := dev.Transceive([]byte{0xFF, 0xCA, 0x00, 0x00, 0x00}); err != nil {
+		t.Fatalf("Transceive during the transaction failed: %v", err)
+	}
+
+	dev.EndTransaction()
+	_, err := dev.Transceive([]byte{0xFF, 0xCA, 0x00, 0x00, 0x00})
+	if !IsCardRemovedError(err) {
+		t.Fatalf("Expected a card removed error after the transaction, got %v", err)
+	}
+
+	// Outside a transaction the signal is immediate
+	dev.signalRemoval()
+	if _, err := dev.Transceive([]byte{0xFF, 0xCA, 0x00, 0x00, 0x00}); !IsCardRemovedError(err) {
+		t.Errorf("Expected a card removed error, got %v", err)
+	}
+}
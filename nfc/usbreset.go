@@ -0,0 +1,58 @@
+package nfc
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// sysfsUSBDevices is where Linux lists USB devices.
+const sysfsUSBDevices = "/sys/bus/usb/devices"
+
+// findUSBDevice returns the usbfs node (/dev/bus/usb/BBB/DDD) of the USB
+// device behind a PC/SC reader. PC/SC names readers after the USB product
+// string ("ACS ACR122U PICC Interface 00 00" for product "ACR122U PICC
+// Interface"), so the device whose product string the reader name contains is
+// taken. Several matches, e.g. two readers of the same model, are refused
+// rather than resetting the wrong one.
+func findUSBDevice(sysfsRoot, readerName string) (string, error) {
+	entries, err := os.ReadDir(sysfsRoot)
+	if err != nil {
+		return "", fmt.Errorf("cannot list USB devices: %w", err)
+	}
+
+	var matches []string
+	for _, entry := range entries {
+		dir := filepath.Join(sysfsRoot, entry.Name())
+		product := readSysfsAttr(dir, "product")
+		if product == "" || !strings.Contains(readerName, product) {
+			continue
+		}
+		bus, errBus := strconv.Atoi(readSysfsAttr(dir, "busnum"))
+		dev, errDev := strconv.Atoi(readSysfsAttr(dir, "devnum"))
+		if errBus != nil || errDev != nil {
+			continue
+		}
+		matches = append(matches, fmt.Sprintf("/dev/bus/usb/%03d/%03d", bus, dev))
+	}
+
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("no USB device found for reader %q", readerName)
+	case 1:
+		return matches[0], nil
+	default:
+		return "", fmt.Errorf("%d USB devices match reader %q, not resetting any", len(matches), readerName)
+	}
+}
+
+// readSysfsAttr returns a trimmed sysfs attribute, or "" if it can't be read.
+func readSysfsAttr(dir, name string) string {
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
@@ -0,0 +1,35 @@
+//go:build linux
+
+package nfc
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"syscall"
+)
+
+// usbdevfsReset is USBDEVFS_RESET, _IO('U', 20).
+const usbdevfsReset = 0x5514
+
+// usbResetReader power-cycles the reader's USB port, as replugging it would.
+// It needs write access to the device's usbfs node, which usually means root
+// or a udev rule.
+func usbResetReader(readerName string) error {
+	node, err := findUSBDevice(sysfsUSBDevices, readerName)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(node, os.O_WRONLY, 0)
+	if err != nil {
+		return fmt.Errorf("cannot open %s for reset: %w", node, err)
+	}
+	defer f.Close()
+
+	log.Printf("Resetting USB device %s (%s)", node, readerName)
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), usbdevfsReset, 0); errno != 0 {
+		return fmt.Errorf("USB reset of %s failed: %w", node, errno)
+	}
+	return nil
+}
@@ -0,0 +1,8 @@
+//go:build !linux
+
+package nfc
+
+// usbResetReader is only implemented on Linux.
+func usbResetReader(readerName string) error {
+	return NewNotSupportedError("USB reset")
+}
@@ -0,0 +1,46 @@
+package nfc
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeSysfsDevice creates a fake sysfs USB device directory.
+func writeSysfsDevice(t *testing.T, root, name string, attrs map[string]string) {
+	t.Helper()
+	dir := filepath.Join(root, name)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for attr, value := range attrs {
+		if err := os.WriteFile(filepath.Join(dir, attr), []byte(value+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestFindUSBDevice(t *testing.T) {
+	root := t.TempDir()
+	writeSysfsDevice(t, root, "1-1", map[string]string{"product": "ACR122U PICC Interface", "busnum": "1", "devnum": "5"})
+	writeSysfsDevice(t, root, "1-1:1.0", nil) // Interface, no product
+	writeSysfsDevice(t, root, "1-2", map[string]string{"product": "USB Keyboard", "busnum": "1", "devnum": "7"})
+
+	got, err := findUSBDevice(root, "ACS ACR122U PICC Interface 00 00")
+	if err != nil {
+		t.Fatalf("findUSBDevice() error = %v", err)
+	}
+	if got != "/dev/bus/usb/001/005" {
+		t.Errorf("findUSBDevice() = %q, want /dev/bus/usb/001/005", got)
+	}
+
+	if _, err := findUSBDevice(root, "Identiv uTrust 3700 F"); err == nil || !strings.Contains(err.Error(), "no USB device") {
+		t.Errorf("Expected no match, got %v", err)
+	}
+
+	writeSysfsDevice(t, root, "2-1", map[string]string{"product": "ACR122U PICC Interface", "busnum": "2", "devnum": "3"})
+	if _, err := findUSBDevice(root, "ACS ACR122U PICC Interface 00 00"); err == nil || !strings.Contains(err.Error(), "2 USB devices") {
+		t.Errorf("Expected an ambiguous match to be refused, got %v", err)
+	}
+}
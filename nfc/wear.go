@@ -0,0 +1,107 @@
+package nfc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dotside-studios/davi-nfc-agent/store"
+)
+
+// WearStatsKey is the store key the write counts are kept under.
+const WearStatsKey = "wear-stats.json"
+
+// WearStats is the write history recorded for one card.
+// Counts are advisory: writes made by other tools are not seen.
+type WearStats struct {
+	UID       string     `json:"uid"`
+	Writes    uint64     `json:"writes"`
+	LastWrite *time.Time `json:"lastWrite,omitempty"` // nil if never written
+}
+
+// WearTracker counts successful writes per card UID, as a rough estimate of
+// EEPROM wear, and persists the counts as JSON keyed by UID under WearStatsKey.
+type WearTracker struct {
+	store store.Store
+	mu    sync.Mutex
+	stats map[string]WearStats
+}
+
+// NewWearTracker creates a tracker backed by s, loading any counts already
+// stored there. A nil store keeps counts in memory only.
+func NewWearTracker(s store.Store) (*WearTracker, error) {
+	if s == nil {
+		s = store.NewMemoryStore()
+	}
+	w := &WearTracker{
+		store: s,
+		stats: make(map[string]WearStats),
+	}
+
+	data, err := s.Get(WearStatsKey)
+	if errors.Is(err, store.ErrNotFound) {
+		return w, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read wear stats: %w", err)
+	}
+	if err := json.Unmarshal(data, &w.stats); err != nil {
+		return nil, fmt.Errorf("failed to parse wear stats: %w", err)
+	}
+
+	return w, nil
+}
+
+// normalizeWearUID folds UID formats ("04:a1:b2", "04A1B2") to one key.
+func normalizeWearUID(uid string) string {
+	return strings.ToUpper(strings.NewReplacer(":", "", "-", "", " ", "").Replace(uid))
+}
+
+// RecordWrite increments the write count for uid and saves the store.
+// The in-memory count is updated even if saving fails.
+func (w *WearTracker) RecordWrite(uid string, at time.Time) error {
+	key := normalizeWearUID(uid)
+	if key == "" {
+		return fmt.Errorf("empty UID")
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	s := w.stats[key]
+	s.UID = key
+	s.Writes++
+	last := at.UTC()
+	s.LastWrite = &last
+	w.stats[key] = s
+
+	return w.saveLocked()
+}
+
+// Stats returns the recorded history for uid. Unknown cards report zero writes.
+func (w *WearTracker) Stats(uid string) WearStats {
+	key := normalizeWearUID(uid)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if s, ok := w.stats[key]; ok {
+		return s
+	}
+	return WearStats{UID: key}
+}
+
+// saveLocked writes all counts to the store. Caller must hold w.mu.
+func (w *WearTracker) saveLocked() error {
+	data, err := json.MarshalIndent(w.stats, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode wear stats: %w", err)
+	}
+	if err := w.store.Set(WearStatsKey, data); err != nil {
+		return fmt.Errorf("failed to save wear stats: %w", err)
+	}
+	return nil
+}
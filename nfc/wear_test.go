@@ -0,0 +1,64 @@
+package nfc
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dotside-studios/davi-nfc-agent/store"
+)
+
+func TestWearTracker_RecordWritePersists(t *testing.T) {
+	s := store.NewFileStore(t.TempDir())
+
+	w, err := NewWearTracker(s)
+	if err != nil {
+		t.Fatalf("NewWearTracker() failed: %v", err)
+	}
+
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		if err := w.RecordWrite("04:a1:b2:c3", at); err != nil {
+			t.Fatalf("RecordWrite() failed: %v", err)
+		}
+	}
+
+	reloaded, err := NewWearTracker(s)
+	if err != nil {
+		t.Fatalf("NewWearTracker() reload failed: %v", err)
+	}
+
+	stats := reloaded.Stats("04A1B2C3")
+	if stats.Writes != 3 {
+		t.Errorf("Writes = %d, want 3", stats.Writes)
+	}
+	if stats.LastWrite == nil || !stats.LastWrite.Equal(at) {
+		t.Errorf("LastWrite = %v, want %v", stats.LastWrite, at)
+	}
+	if stats.UID != "04A1B2C3" {
+		t.Errorf("UID = %q, want 04A1B2C3", stats.UID)
+	}
+}
+
+func TestWearTracker_UnknownUID(t *testing.T) {
+	w, err := NewWearTracker(nil)
+	if err != nil {
+		t.Fatalf("NewWearTracker() failed: %v", err)
+	}
+
+	if stats := w.Stats("DEADBEEF"); stats.Writes != 0 || stats.LastWrite != nil {
+		t.Errorf("Expected no history for unknown UID, got %+v", stats)
+	}
+}
+
+func TestWearTracker_CorruptFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, WearStatsKey), []byte("not json"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewWearTracker(store.NewFileStore(dir)); err == nil {
+		t.Error("Expected error for corrupt wear stats file")
+	}
+}
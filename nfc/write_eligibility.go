@@ -0,0 +1,121 @@
+package nfc
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// LockedSectorChecker is implemented by tags whose memory is split into
+// sectors with their own keys and write permissions, such as MIFARE Classic.
+type LockedSectorChecker interface {
+	// LockedSectors returns the sectors an NDEF message of size bytes would
+	// occupy that cannot be written with keys or the default keys.
+	LockedSectors(size int, keys ClassicKeyProvider) ([]int, error)
+}
+
+// QuickWritabilityChecker is implemented by tags with a cheaper writability
+// check than IsWritable, such as reading a single Capability Container byte.
+type QuickWritabilityChecker interface {
+	// QuickWritable reports whether the tag accepts writes. err is only set
+	// when the tag could not be checked, e.g. because it was removed.
+	QuickWritable() (bool, error)
+}
+
+// quickWritableError keeps card removal as an error for QuickWritable
+// implementations, and reads any other failure as a card that cannot be
+// written.
+func quickWritableError(err error) error {
+	if IsCardRemovedError(err) {
+		return err
+	}
+	return nil
+}
+
+// QuickWritable reports whether the card can be written, with the cheapest
+// check its tag type offers: the Capability Container write access byte on
+// Type 2 and Type 4 cards, or a single sector authentication on MIFARE
+// Classic. Other cards fall back to IsWritable. Unlike WriteEligibility it
+// does not read the card's message, so it is fast enough to run on every
+// detection, but it cannot tell whether a particular message would fit.
+func (c *Card) QuickWritable() (bool, error) {
+	if !GetTagCapabilities(c.tag).CanWrite {
+		return false, nil
+	}
+	if checker, ok := c.tag.(QuickWritabilityChecker); ok {
+		return checker.QuickWritable()
+	}
+	return c.tag.IsWritable()
+}
+
+// WriteEligibility reports whether msg could be written to the card with
+// opts. Rather than stopping at the first problem, it collects every
+// condition that would make the write fail: a card type that cannot be
+// written, a read-only card, a message too large for the card, sectors that
+// cannot be written, and LockAfterWrite on a card that cannot be locked.
+// Nothing is written. err is only set when the card could not be checked,
+// e.g. because it was removed.
+//
+// Without opts.Overwrite, the size checked is that of the card's current
+// message with msg merged in, as a partial update would write it.
+func (c *Card) WriteEligibility(msg *NDEFMessage, opts WriteOptions) (ok bool, reasons []string, err error) {
+	caps := GetTagCapabilities(c.tag)
+	if !caps.CanWrite {
+		return false, []string{fmt.Sprintf("%s cards cannot be written", c.Type)}, nil
+	}
+
+	writable, err := c.tag.IsWritable()
+	if err != nil {
+		return false, nil, fmt.Errorf("failed to check whether card UID %s is writable: %w", c.UID, err)
+	}
+	if !writable {
+		reasons = append(reasons, "card is read-only")
+	}
+
+	if opts.LockAfterWrite {
+		canLock, err := c.tag.CanMakeReadOnly()
+		if err != nil {
+			return false, nil, fmt.Errorf("failed to check lock support of card UID %s: %w", c.UID, err)
+		}
+		if !canLock {
+			reasons = append(reasons, "card cannot be made read-only")
+		}
+	}
+
+	toWrite := msg
+	if !opts.Overwrite && writable {
+		if current, err := c.ReadMessage(); err == nil {
+			if ndef, ok := current.(*NDEFMessage); ok && len(ndef.Records()) > 0 {
+				merged, err := mergeNDEF(ndef, msg, opts.Index)
+				if err != nil {
+					return false, append(reasons, fmt.Sprintf("message cannot be merged: %v", err)), nil
+				}
+				toWrite = merged
+			}
+		}
+	}
+	data, err := toWrite.Encode()
+	if err != nil {
+		return false, append(reasons, fmt.Sprintf("message cannot be encoded: %v", err)), nil
+	}
+
+	if limit := caps.MaxNDEFSize; limit > 0 && len(data) > limit {
+		reasons = append(reasons, fmt.Sprintf("message is %d bytes but the card holds at most %d", len(data), limit))
+	}
+
+	if checker, ok := c.tag.(LockedSectorChecker); ok && writable {
+		locked, err := checker.LockedSectors(len(data), opts.SectorKeys)
+		if err != nil {
+			return false, nil, fmt.Errorf("failed to check sectors of card UID %s: %w", c.UID, err)
+		}
+		if len(locked) > 0 {
+			names := make([]string, len(locked))
+			for i, sector := range locked {
+				names[i] = strconv.Itoa(sector)
+			}
+			reasons = append(reasons, fmt.Sprintf("sectors %s are locked or use unknown keys", strings.Join(names, ", ")))
+		}
+	}
+
+	return len(reasons) == 0, reasons, nil
+}
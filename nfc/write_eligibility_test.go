@@ -0,0 +1,193 @@
+package nfc
+
+import (
+	"encoding/hex"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCard_WriteEligibility(t *testing.T) {
+	small := NewNDEFMessage().AddText("Hello", "en")
+	large := NewNDEFMessage().AddText(strings.Repeat("A", 200), "en")
+
+	tests := []struct {
+		name     string
+		tagType  string
+		readOnly bool
+		msg      *NDEFMessage
+		opts     WriteOptions
+		want     []string
+	}{
+		{"writable", "NTAG213", false, small, WriteOptions{Overwrite: true, LockAfterWrite: true}, nil},
+		{"every reason at once", "NTAG213", true, large, WriteOptions{Overwrite: true, LockAfterWrite: true}, []string{
+			"card is read-only",
+			"card cannot be made read-only",
+			"message is 207 bytes but the card holds at most 144",
+		}},
+		{"unwritable type", "Mock Tag", false, small, WriteOptions{Overwrite: true}, []string{"Mock Tag cards cannot be written"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tag := NewMockTag("04A1B2C3")
+			tag.IsConnected = true
+			tag.TagType = tt.tagType
+			tag.IsReadOnly = tt.readOnly
+
+			ok, reasons, err := NewCard(tag).WriteEligibility(tt.msg, tt.opts)
+			if err != nil {
+				t.Fatalf("WriteEligibility() error = %v", err)
+			}
+			if ok != (len(tt.want) == 0) || !reflect.DeepEqual(reasons, tt.want) {
+				t.Errorf("WriteEligibility() = %v, %q; want %q", ok, reasons, tt.want)
+			}
+		})
+	}
+}
+
+// TestCard_WriteEligibility_PartialUpdate tests that without Overwrite the
+// size checked includes the records already on the card.
+func TestCard_WriteEligibility_PartialUpdate(t *testing.T) {
+	tag := NewMockTag("04A1B2C3")
+	tag.IsConnected = true
+	tag.TagType = "NTAG213"
+	tag.Data, _ = NewNDEFMessage().AddText(strings.Repeat("A", 100), "en").Encode()
+	msg := NewNDEFMessage().AddText(strings.Repeat("B", 60), "en")
+
+	if ok, reasons, _ := NewCard(tag).WriteEligibility(msg, WriteOptions{Overwrite: true}); !ok {
+		t.Errorf("Overwrite: WriteEligibility() reasons = %q, want none", reasons)
+	}
+	if ok, _, _ := NewCard(tag).WriteEligibility(msg, WriteOptions{Index: -1}); ok {
+		t.Error("Append: WriteEligibility() = true, want false for a merged message over capacity")
+	}
+}
+
+func TestClassicTag_LockedSectors(t *testing.T) {
+	trailer := func(access [3]byte) string {
+		return hex.EncodeToString(buildSectorTrailer(make([]byte, 6), access, 0x40, make([]byte, 6))) + "9000"
+	}
+
+	card := newMockScardCard()
+	card.addResponse(hex.EncodeToString(LoadKeyAPDU(0x00, classicDefaultKeys[0])), "9000")
+	for sector := 1; sector <= 3; sector++ {
+		card.addResponse(hex.EncodeToString(MIFAREAuthAPDU(byte(sector*4+3), MIFAREKeyA, 0x00)), "9000")
+	}
+	card.addResponse(hex.EncodeToString(ReadBinaryAPDU(7, 16)), trailer(ndefSectorAccess))
+	card.addResponse(hex.EncodeToString(ReadBinaryAPDU(11, 16)), trailer(ndefReadOnlyAccess))
+	card.addResponse(hex.EncodeToString(ReadBinaryAPDU(15, 16)), trailer(ndefReadOnlyAdminAccess))
+	tag := newPCSCClassicTag(newMockPCSCDevice(card, pcscATR(0x01)), "04A1B2C3", DetectedClassic1K)
+
+	// 100 bytes fit in sectors 1-3 (48 bytes each); 40 only need sector 1
+	for size, want := range map[int][]int{40: nil, 100: {2, 3}} {
+		locked, err := tag.LockedSectors(size, nil)
+		if err != nil {
+			t.Fatalf("LockedSectors(%d) error = %v", size, err)
+		}
+		if !reflect.DeepEqual(locked, want) {
+			t.Errorf("LockedSectors(%d) = %v, want %v", size, locked, want)
+		}
+	}
+}
+
+// TestCard_QuickWritable tests the fallback to IsWritable for tags without a
+// cheaper check, and that unwritable types are not probed.
+func TestCard_QuickWritable(t *testing.T) {
+	tests := []struct {
+		name     string
+		tagType  string
+		readOnly bool
+		want     bool
+	}{
+		{"writable", "NTAG213", false, true},
+		{"read-only", "NTAG213", true, false},
+		{"unwritable type", "Mock Tag", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tag := NewMockTag("04A1B2C3")
+			tag.IsConnected = true
+			tag.TagType = tt.tagType
+			tag.IsReadOnly = tt.readOnly
+
+			got, err := NewCard(tag).QuickWritable()
+			if err != nil {
+				t.Fatalf("QuickWritable() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("QuickWritable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestType2Tag_QuickWritable tests that Type 2 tags are judged by their CC
+// with a single read.
+func TestType2Tag_QuickWritable(t *testing.T) {
+	tests := []struct {
+		name string
+		cc   []byte
+		want bool
+	}{
+		{"blank", []byte{0x00, 0x00, 0x00, 0x00}, true},
+		{"formatted", []byte{0xE1, 0x10, 0x06, 0x00}, true},
+		{"read-only", []byte{0xE1, 0x10, 0x06, 0x0F}, false},
+		{"not NDEF formatted", []byte{0x01, 0x02, 0x03, 0x04}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tag, card := newBlankType2Tag(DetectedNTAG213, 45)
+			copy(card.pages[12:16], tt.cc)
+
+			got, err := tag.(QuickWritabilityChecker).QuickWritable()
+			if err != nil {
+				t.Fatalf("QuickWritable() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("QuickWritable() = %v, want %v", got, tt.want)
+			}
+			if len(card.callLog) != 1 {
+				t.Errorf("Expected 1 APDU, got %d", len(card.callLog))
+			}
+		})
+	}
+}
+
+// TestISO14443Tag_QuickWritable tests that Type 4 tags read only the CC
+// write access byte.
+func TestISO14443Tag_QuickWritable(t *testing.T) {
+	tests := []struct {
+		name   string
+		access string // Response to READ BINARY of the write access byte, "" for no CC file
+		want   bool
+	}{
+		{"writable", "009000", true},
+		{"read-only", "ff9000", false},
+		{"no CC file", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			card := newMockScardCard()
+			card.addResponse("00a4040007d276000085010100", "9000")
+			if tt.access != "" {
+				card.addResponse("00a4040002e10300", "9000")
+				card.addResponse("00b0000e01", tt.access)
+			}
+			tag := newPCSCISO14443Tag(newMockPCSCDevice(card, unknownATR), "04112233445566")
+
+			got, err := tag.QuickWritable()
+			if err != nil {
+				t.Fatalf("QuickWritable() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("QuickWritable() = %v, want %v", got, tt.want)
+			}
+			if got := hex.EncodeToString(card.callLog[len(card.callLog)-1]); tt.access != "" && got != "00b0000e01" {
+				t.Errorf("Last APDU = %s, want a 1-byte CC read", got)
+			}
+		})
+	}
+}
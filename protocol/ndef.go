@@ -24,12 +24,14 @@ type NDEFRecordInput struct {
 // NDEFRecordPayload is the JSON-friendly representation of an NDEF record.
 // Used in WebSocket broadcasts and API responses.
 type NDEFRecordPayload struct {
-	Type     string `json:"type"`              // "text", "uri", etc.
-	Content  string `json:"content,omitempty"` // Decoded content
-	Language string `json:"language,omitempty"`
-	TNF      uint8  `json:"tnf"`
-	ID       string `json:"id,omitempty"`
-	Payload  []byte `json:"payload"`
+	Type        string `json:"type"`              // "text", "uri", etc.
+	Content     string `json:"content,omitempty"` // Decoded content
+	Language    string `json:"language,omitempty"`
+	TNF         uint8  `json:"tnf"`
+	TypeName    string `json:"typeName"`              // Raw record type, as text or hex
+	TypeNameHex bool   `json:"typeNameHex,omitempty"` // TypeName is hex because the type is not printable ASCII
+	ID          string `json:"id,omitempty"`
+	Payload     []byte `json:"payload"` // Raw payload (base64 in JSON)
 }
 
 // NDEFMessagePayload is the JSON-friendly representation of an NDEF message.
@@ -0,0 +1,27 @@
+package protocol
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// SignPayload returns the signature of a broadcast message: the lowercase
+// hex HMAC-SHA256 of its payload JSON, exactly as it appears in the message,
+// under key.
+func SignPayload(key, payload []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyPayload reports whether sig is the signature of payload under key.
+func VerifyPayload(key, payload []byte, sig string) bool {
+	want, err := hex.DecodeString(sig)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(payload)
+	return hmac.Equal(mac.Sum(nil), want)
+}
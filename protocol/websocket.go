@@ -7,6 +7,109 @@ const (
 	WSTypeWriteRequest  = "writeRequest"
 	WSTypeWriteResponse = "writeResponse"
 	WSTypeError         = "error"
+	WSTypeReady         = "ready"
+
+	WSTypeFormatNDEF         = "formatNdef"
+	WSTypeFormatNDEFResponse = "formatNdefResponse"
+
+	WSTypeReadManufacturerBlock         = "readManufacturerBlock"
+	WSTypeReadManufacturerBlockResponse = "readManufacturerBlockResponse"
+
+	WSTypeGetVersion         = "getVersion"
+	WSTypeGetVersionResponse = "getVersionResponse"
+
+	WSTypeWaitForCard         = "waitForCard"
+	WSTypeWaitForCardResponse = "waitForCardResponse"
+
+	WSTypeReadRange         = "readRange"
+	WSTypeReadRangeResponse = "readRangeResponse"
+
+	WSTypeSubscribe         = "subscribe"
+	WSTypeSubscribeResponse = "subscribeResponse"
+
+	WSTypeGetWearStats         = "getWearStats"
+	WSTypeGetWearStatsResponse = "getWearStatsResponse"
+
+	WSTypeDeviceStatusPatch = "deviceStatusPatch"
+
+	WSTypeCardHeartbeat = "cardHeartbeat"
+
+	WSTypeUnsupportedCard = "unsupportedCard"
+
+	WSTypeClearCache         = "clearCache"
+	WSTypeClearCacheResponse = "clearCacheResponse"
+
+	WSTypeGetCardInfo         = "getCardInfo"
+	WSTypeGetCardInfoResponse = "getCardInfoResponse"
+
+	WSTypeListTags         = "listTags"
+	WSTypeListTagsResponse = "listTagsResponse"
+
+	WSTypeReadCard         = "readCard"
+	WSTypeReadCardResponse = "readCardResponse"
+
+	WSTypeWriteRaw         = "writeRaw"
+	WSTypeWriteRawResponse = "writeRawResponse"
+
+	WSTypeResetDevice         = "resetDevice"
+	WSTypeResetDeviceResponse = "resetDeviceResponse"
+
+	WSTypeGetLatencyStats         = "getLatencyStats"
+	WSTypeGetLatencyStatsResponse = "getLatencyStatsResponse"
+
+	WSTypeGetAllowedTypes         = "getAllowedTypes"
+	WSTypeGetAllowedTypesResponse = "getAllowedTypesResponse"
+	WSTypeSetAllowedTypes         = "setAllowedTypes"
+	WSTypeSetAllowedTypesResponse = "setAllowedTypesResponse"
+
+	WSTypeNormalize         = "normalize"
+	WSTypeNormalizeResponse = "normalizeResponse"
+
+	WSTypePause          = "pause"
+	WSTypePauseResponse  = "pauseResponse"
+	WSTypeResume         = "resume"
+	WSTypeResumeResponse = "resumeResponse"
+
+	WSTypeWriteLocalizedText         = "writeLocalizedText"
+	WSTypeWriteLocalizedTextResponse = "writeLocalizedTextResponse"
+
+	WSTypeCheckWrite         = "checkWrite"
+	WSTypeCheckWriteResponse = "checkWriteResponse"
+
+	WSTypeGetATR         = "getATR"
+	WSTypeGetATRResponse = "getATRResponse"
+
+	WSTypeWriteAndRead         = "writeAndRead"
+	WSTypeWriteAndReadResponse = "writeAndReadResponse"
+
+	WSTypeCaptureReference           = "captureReference"
+	WSTypeCaptureReferenceResponse   = "captureReferenceResponse"
+	WSTypeCompareToReference         = "compareToReference"
+	WSTypeCompareToReferenceResponse = "compareToReferenceResponse"
+
+	WSTypeQuickWritable         = "quickWritable"
+	WSTypeQuickWritableResponse = "quickWritableResponse"
+
+	WSTypeRescan         = "rescan"
+	WSTypeRescanResponse = "rescanResponse"
+
+	WSTypeGetCapabilities         = "getCapabilities"
+	WSTypeGetCapabilitiesResponse = "getCapabilitiesResponse"
+
+	WSTypeReadPages          = "readPages"
+	WSTypeReadPagesResponse  = "readPagesResponse"
+	WSTypeWritePage          = "writePage"
+	WSTypeWritePageResponse  = "writePageResponse"
+	WSTypeReadMAD            = "readMAD"
+	WSTypeReadMADResponse    = "readMADResponse"
+	WSTypeReadRecord         = "readRecord"
+	WSTypeReadRecordResponse = "readRecordResponse"
+)
+
+// Session roles reported to clients in the ready handshake
+const (
+	SessionRoleWriter = "writer"
+	SessionRoleReader = "reader"
 )
 
 // WebSocket message type constants for device communication
@@ -18,6 +121,7 @@ const (
 	WSTypeDeviceHeartbeat        = "deviceHeartbeat"
 	WSTypeDeviceWriteRequest     = "deviceWriteRequest"
 	WSTypeDeviceWriteResponse    = "deviceWriteResponse"
+	WSTypeServerShutdown         = "serverShutdown"
 )
 
 // WebSocketMessage is the generic message envelope for WebSocket communication.
@@ -25,6 +129,7 @@ type WebSocketMessage struct {
 	ID      string `json:"id,omitempty"`
 	Type    string `json:"type"`
 	Payload any    `json:"payload"`
+	Sig     string `json:"sig,omitempty"` // Payload signature when the agent signs broadcasts (see SignPayload)
 }
 
 // WebSocketRequest is for incoming requests from WebSocket clients.
@@ -54,11 +159,31 @@ type TagDataPayload struct {
 	Error      *string                `json:"err"`
 }
 
+// ReadyPayload is sent once right after a client connects, before any other message.
+type ReadyPayload struct {
+	ServerVersion string `json:"serverVersion"`
+	SessionRole   string `json:"sessionRole"` // "writer" or "reader"
+}
+
+// VersionPayload describes the agent build and the protocol features it supports.
+type VersionPayload struct {
+	Name      string   `json:"name"`
+	Version   string   `json:"version"`
+	Commit    string   `json:"commit,omitempty"`
+	BuildTime string   `json:"buildTime,omitempty"`
+	Features  []string `json:"features"`
+}
+
 // DeviceStatusPayload is the payload for device status updates.
 type DeviceStatusPayload struct {
 	Connected   bool   `json:"connected"`
 	Message     string `json:"message"`
 	CardPresent bool   `json:"cardPresent"`
+	Event       string `json:"event,omitempty"`  // "reconnected" after recovering from a device error
+	Reason      string `json:"reason,omitempty"` // Error category that triggered the event
+
+	ReaderModel    string `json:"readerModel,omitempty"`    // e.g. "ACR122U", when the reader reports it
+	ReaderFirmware string `json:"readerFirmware,omitempty"` // e.g. "ACR122U207", when the reader reports it
 }
 
 // WriteRequestPayload is the payload for write requests.
@@ -66,6 +191,307 @@ type WriteRequestPayload struct {
 	Records []WriteRecord `json:"records"`
 }
 
+// WriteRawPayload is the payload for writing a client-built NDEF message.
+type WriteRawPayload struct {
+	NDEFHex string `json:"ndefHex"` // Complete NDEF message as hex, without the TLV wrapper
+}
+
+// WriteLocalizedTextPayload is the payload for writing one text record per
+// language.
+type WriteLocalizedTextPayload struct {
+	Texts map[string]string `json:"texts"` // Text by language code, e.g. {"en": "Hello", "fr": "Bonjour"}
+}
+
+// FormatNDEFPayload is the payload for format requests.
+type FormatNDEFPayload struct {
+	Force bool `json:"force,omitempty"` // Reformat cards that are not in factory state
+}
+
+// WaitForCardPayload is the payload for wait-for-card requests.
+type WaitForCardPayload struct {
+	Timeout int `json:"timeout,omitempty"` // Milliseconds to wait (default 30000, max 300000)
+}
+
+// ReadRangePayload is the payload for partial NDEF reads.
+type ReadRangePayload struct {
+	Offset   int    `json:"offset"`             // Byte offset into the NDEF message (after NLEN)
+	Length   int    `json:"length,omitempty"`   // Bytes to read (default 256, max 4096)
+	Encoding string `json:"encoding,omitempty"` // "hex" (default) or "base64"
+}
+
+// ReadRangeResponsePayload is the response payload for partial NDEF reads.
+// Length is the number of bytes actually returned, which is 0 past the end of the message.
+type ReadRangeResponsePayload struct {
+	Offset   int    `json:"offset"`
+	Length   int    `json:"length"`
+	Encoding string `json:"encoding"`
+	Data     string `json:"data"`
+}
+
+// ReadCardPayload is the response payload for read card requests.
+type ReadCardPayload struct {
+	UID            string           `json:"uid"`
+	Type           string           `json:"type"`
+	Data           string           `json:"data"`              // Raw NDEF message, uppercase hex
+	Message        map[string]any   `json:"message,omitempty"` // Decoded message as in tagData, omitted if it does not decode
+	Text           string           `json:"text"`
+	Partial        bool             `json:"partial"`         // Sectors were skipped, so data may be incomplete
+	SkippedSectors []int            `json:"skippedSectors"`  // Sectors that could not be authenticated or read
+	Trace          []APDUTraceEntry `json:"trace,omitempty"` // APDUs exchanged, when requested with "trace"
+}
+
+// NormalizePayload is the response to normalize: the card's NDEF message as
+// read and as re-encoded canonically. Nothing is written to the card.
+type NormalizePayload struct {
+	UID       string         `json:"uid"`
+	Type      string         `json:"type"`
+	Original  string         `json:"original"`  // NDEF message as read, uppercase hex
+	Canonical string         `json:"canonical"` // Canonical re-encoding, uppercase hex
+	Changed   bool           `json:"changed"`   // Canonical differs from original
+	Message   map[string]any `json:"message"`   // Decoded canonical message as in tagData
+}
+
+// CompareReferencePayload is the response to compareToReference: whether the
+// NDEF message on the card matches the reference captured earlier.
+type CompareReferencePayload struct {
+	UID               string `json:"uid"`
+	ReferenceUID      string `json:"referenceUid"` // Card the reference was captured from
+	Mode              string `json:"mode"`         // "bytes" or "records", the comparison Match reports
+	Match             bool   `json:"match"`
+	BytesMatch        bool   `json:"bytesMatch"`        // Messages are byte-for-byte identical
+	RecordsMatch      bool   `json:"recordsMatch"`      // Same records in the same order
+	MismatchedRecords []int  `json:"mismatchedRecords"` // Indices of differing records, empty if either message does not decode
+	Data              string `json:"data"`              // NDEF message on the card, uppercase hex
+}
+
+// CheckWritePayload is the response to checkWrite: whether the records would
+// be written to the card on the reader, and every reason they would not.
+type CheckWritePayload struct {
+	OK      bool     `json:"ok"`
+	Reasons []string `json:"reasons"` // Empty when OK
+}
+
+// CapabilitiesPayload is the response to getCapabilities: what the connected
+// device supports. Without a connected device, Connected is false and the
+// fields hold best-effort defaults.
+type CapabilitiesPayload struct {
+	Connected         bool     `json:"connected"`
+	DeviceType        string   `json:"deviceType"`
+	SupportedTagTypes []string `json:"supportedTagTypes"`
+	CanTransceive     bool     `json:"canTransceive"`
+	CanPoll           bool     `json:"canPoll"`
+	SupportsEvents    bool     `json:"supportsEvents"`
+}
+
+// QuickWritablePayload is the response to quickWritable: whether the card on
+// the reader accepts writes, from a check that does not read its message.
+type QuickWritablePayload struct {
+	UID      string `json:"uid"`
+	Writable bool   `json:"writable"`
+}
+
+// ATRPayload is the response to getATR: the card's Answer To Reset and its
+// decode. Byte fields are uppercase hex strings. Note describes why decoding
+// stopped early for malformed ATRs.
+type ATRPayload struct {
+	ATR             string             `json:"atr"`
+	TS              string             `json:"ts"`
+	T0              string             `json:"t0"`
+	InterfaceBytes  []ATRInterfaceByte `json:"interfaceBytes"`
+	Protocols       []string           `json:"protocols"` // e.g. "T=1"
+	HistoricalBytes string             `json:"historicalBytes"`
+	TCK             string             `json:"tck,omitempty"`
+	TCKValid        *bool              `json:"tckValid,omitempty"`
+	Standard        string             `json:"standard,omitempty"`     // PC/SC Part 3, e.g. "ISO 14443 A, part 3"
+	CardNameCode    string             `json:"cardNameCode,omitempty"` // PC/SC Part 3, e.g. "0001"
+	CardName        string             `json:"cardName,omitempty"`     // e.g. "MIFARE Classic 1K"
+	Note            string             `json:"note,omitempty"`
+}
+
+// ATRInterfaceByte is one interface byte of an ATR.
+type ATRInterfaceByte struct {
+	Name  string `json:"name"` // e.g. "TD1"
+	Value string `json:"value"`
+}
+
+// APDUTraceEntry is one APDU exchange in a trace requested with "trace": true.
+// Key and PIN bytes in TX are replaced with FF.
+type APDUTraceEntry struct {
+	TX         string `json:"tx"`              // Command, uppercase hex
+	RX         string `json:"rx,omitempty"`    // Response including SW1 SW2, uppercase hex
+	Error      string `json:"error,omitempty"` // Transmit error, if any
+	DurationUs int64  `json:"durationUs"`      // Time waiting for the response in microseconds
+}
+
+// SubscribePayload is the payload for subscribe requests.
+type SubscribePayload struct {
+	Replay   string `json:"replay,omitempty"`   // "uid" (default), "full" or "none"
+	Compress *bool  `json:"compress,omitempty"` // Gzip large tagData messages; unchanged if omitted
+
+	// HeartbeatMs is the cardHeartbeat interval while a card is present;
+	// 0 turns heartbeats off, omitted leaves them unchanged
+	HeartbeatMs *int `json:"heartbeatMs,omitempty"`
+}
+
+// CardHeartbeatPayload is the payload of cardHeartbeat messages.
+type CardHeartbeatPayload struct {
+	UID     string `json:"uid"`
+	DwellMs int64  `json:"dwellMs"` // Time since the card was detected
+}
+
+// UnsupportedCardPayload is the payload of unsupportedCard messages.
+type UnsupportedCardPayload struct {
+	UID      string `json:"uid,omitempty"` // Omitted if the reader could not get it
+	ATR      string `json:"atr"`
+	ReaderID string `json:"readerId,omitempty"`
+}
+
+// WearStatsPayload is the response payload for write wear statistics.
+// Counts only include writes made through this agent.
+type WearStatsPayload struct {
+	UID       string `json:"uid"`
+	Writes    uint64 `json:"writes"`
+	LastWrite string `json:"lastWrite,omitempty"` // RFC3339, omitted if never written
+}
+
+// LatencyStatsPayload is the response payload for operation latency
+// statistics over the rolling window.
+type LatencyStatsPayload struct {
+	WindowMs   int64                         `json:"windowMs"`
+	Operations map[string]OperationLatencies `json:"operations"` // Keyed by "read", "write" or "operation"
+}
+
+// OperationLatencies are the duration percentiles of one kind of operation,
+// in milliseconds. Percentiles are estimated from fixed histogram buckets.
+type OperationLatencies struct {
+	Count int     `json:"count"`
+	P50Ms float64 `json:"p50Ms"`
+	P95Ms float64 `json:"p95Ms"`
+	P99Ms float64 `json:"p99Ms"`
+	MaxMs float64 `json:"maxMs"`
+}
+
+// AllowedTypesPayload is the payload of setAllowedTypes and the response to
+// both allowed type commands. An empty Types allows every card type.
+type AllowedTypesPayload struct {
+	Types []string `json:"types"`
+	Known []string `json:"known,omitempty"` // Every card type name, in responses only
+}
+
+// ReadPagesPayload is the payload for raw Type 2 page reads.
+type ReadPagesPayload struct {
+	Start int `json:"start"`
+	Count int `json:"count"`
+}
+
+// WritePagePayload is the payload for raw Type 2 page writes.
+type WritePagePayload struct {
+	Page int    `json:"page"`
+	Data string `json:"data"` // 4 bytes as hex (8 characters)
+}
+
+// PagesPayload is the response payload for raw page reads and writes.
+// Data is an uppercase hex string.
+type PagesPayload struct {
+	Start int    `json:"start"`
+	Count int    `json:"count"`
+	Data  string `json:"data,omitempty"`
+}
+
+// ReadRecordPayload is the payload for ISO 7816-4 record reads on Type 4 tags.
+type ReadRecordPayload struct {
+	AID    string   `json:"aid,omitempty"`  // Application to select first (hex)
+	FIDs   []string `json:"fids,omitempty"` // Files to select in order after the AID (2 bytes hex each)
+	Record int      `json:"record"`         // Record number (1-254)
+	SFI    int      `json:"sfi,omitempty"`  // Short file identifier (1-30), or 0 for the last selected file
+}
+
+// RecordPayload is the response payload for record reads. Step names the APDU
+// that answered: "selectAID" or "selectFID" when a selection failed, otherwise
+// "readRecord". Data and SW are uppercase hex.
+type RecordPayload struct {
+	Step string `json:"step"`
+	Data string `json:"data"`
+	SW   string `json:"sw"` // SW1SW2, e.g. "9000"
+}
+
+// MADEntryPayload is one sector of a MIFARE Application Directory.
+type MADEntryPayload struct {
+	Sector      int    `json:"sector"`
+	AID         string `json:"aid"`                   // 4 uppercase hex characters, e.g. "E103"
+	Application string `json:"application,omitempty"` // "NDEF", "free", ... for well-known AIDs
+}
+
+// MADPayload is the response payload for MAD reads. CRCValid is false, with
+// the mismatch in Note, when a stored MAD CRC does not match its contents.
+type MADPayload struct {
+	Entries  []MADEntryPayload `json:"entries"`
+	CRCValid bool              `json:"crcValid"`
+	Note     string            `json:"note,omitempty"`
+}
+
+// ManufacturerBlockPayload is the response payload for manufacturer block reads.
+// Byte fields are uppercase hex strings.
+type ManufacturerBlockPayload struct {
+	UID      string `json:"uid"`
+	SAK      string `json:"sak"`
+	ATQA     string `json:"atqa"`
+	BCCValid bool   `json:"bccValid"`
+	Note     string `json:"note"` // Caveat about block 0 provenance (gen-1a cards)
+}
+
+// CardInfoPayload is the response payload for card info requests.
+type CardInfoPayload struct {
+	UID     string              `json:"uid"`
+	Type    string              `json:"type"`
+	DESFire *DESFireInfoPayload `json:"desfire,omitempty"` // Only for DESFire cards
+
+	// Signature is the NTAG21x originality signature as uppercase hex
+	Signature   string `json:"signature,omitempty"`
+	Originality string `json:"originality,omitempty"` // "genuine" or "unknown", only with a signature key
+
+	// Magic and Block0Writable are the gen1a probe result for MIFARE Classic
+	// cards, only when the agent runs with the probe enabled
+	Magic          *bool `json:"magic,omitempty"`
+	Block0Writable *bool `json:"block0Writable,omitempty"`
+
+	// GPB is the general purpose byte of each MIFARE Classic sector trailer
+	// that could be read, as uppercase hex keyed by sector number
+	GPB map[int]string `json:"gpb,omitempty"`
+
+	// Reader model and firmware version, when the reader reports them
+	ReaderModel    string `json:"readerModel,omitempty"`
+	ReaderFirmware string `json:"readerFirmware,omitempty"`
+}
+
+// ListTagsPayload is the response payload for list tags requests.
+type ListTagsPayload struct {
+	Tags []CardInfoPayload `json:"tags"` // Every tag in the field, only uid and type set
+}
+
+// DESFireInfoPayload is the version and memory data a DESFire card reports.
+// Byte fields are uppercase hex strings.
+type DESFireInfoPayload struct {
+	Variant        string                `json:"variant"` // e.g. "MIFARE DESFire EV2"
+	Hardware       DESFireVersionPayload `json:"hardware"`
+	Software       DESFireVersionPayload `json:"software"`
+	UID            string                `json:"uid"`
+	BatchNo        string                `json:"batchNo"`
+	ProductionWeek int                   `json:"productionWeek"`
+	ProductionYear int                   `json:"productionYear"` // Two-digit year
+	FreeMemory     *int                  `json:"freeMemory"`     // Free EEPROM bytes, null if the card refused FreeMem
+}
+
+// DESFireVersionPayload is the hardware or software half of a DESFire version.
+type DESFireVersionPayload struct {
+	VendorID     string `json:"vendorId"`
+	Type         string `json:"type"`
+	Subtype      string `json:"subtype"`
+	Version      string `json:"version"`      // "major.minor"
+	StorageBytes int    `json:"storageBytes"` // Lower bound of the storage size
+	Protocol     string `json:"protocol"`
+}
+
 // WriteRecord represents a single NDEF record in a write request.
 type WriteRecord struct {
 	Type     string `json:"type"`               // "text" or "uri"
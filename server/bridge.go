@@ -14,6 +14,9 @@ type ServerBridge struct {
 	// DeviceStatus flows from Device -> Client for device state updates
 	DeviceStatus chan nfc.DeviceStatus
 
+	// Command flows from Client -> Device for non-write operations (format, queries, ...)
+	Command chan CommandMessage
+
 	// done signals when the bridge should stop
 	done chan struct{}
 }
@@ -48,12 +51,46 @@ type WriteResponseMessage struct {
 	Payload any
 }
 
+// CommandMessage wraps a client command addressed to the device server.
+type CommandMessage struct {
+	// RequestID correlates request with response
+	RequestID string
+
+	// ClientID identifies the requesting client
+	ClientID string
+
+	// Type is the WebSocket message type of the command
+	Type string
+
+	// Payload contains the command parameters
+	Payload map[string]any
+
+	// ResponseCh receives the command result (buffered, size 1)
+	ResponseCh chan CommandResponseMessage
+}
+
+// CommandResponseMessage wraps command results.
+type CommandResponseMessage struct {
+	// RequestID correlates with the original request
+	RequestID string
+
+	// Success indicates if the command succeeded
+	Success bool
+
+	// Error contains error message if Success is false
+	Error string
+
+	// Payload contains additional response data
+	Payload any
+}
+
 // NewServerBridge creates a new bridge with buffered channels.
 func NewServerBridge() *ServerBridge {
 	return &ServerBridge{
 		TagData:      make(chan nfc.NFCData, 10),
 		WriteRequest: make(chan WriteRequestMessage, 10),
 		DeviceStatus: make(chan nfc.DeviceStatus, 10),
+		Command:      make(chan CommandMessage, 10),
 		done:         make(chan struct{}),
 	}
 }
@@ -64,6 +101,7 @@ func (b *ServerBridge) Close() {
 	close(b.TagData)
 	close(b.WriteRequest)
 	close(b.DeviceStatus)
+	close(b.Command)
 }
 
 // Done returns a channel that's closed when the bridge is shutting down.
@@ -121,6 +159,28 @@ func (b *ServerBridge) SendWriteRequest(msg WriteRequestMessage) (WriteResponseM
 	}
 }
 
+// SendCommand sends a command to the device server and waits for response.
+// Returns the response or an error if the bridge is closed.
+func (b *ServerBridge) SendCommand(msg CommandMessage) (CommandResponseMessage, error) {
+	// Ensure response channel is created
+	if msg.ResponseCh == nil {
+		msg.ResponseCh = make(chan CommandResponseMessage, 1)
+	}
+
+	select {
+	case <-b.done:
+		return CommandResponseMessage{}, ErrBridgeClosed
+	case b.Command <- msg:
+		// Wait for response
+		select {
+		case <-b.done:
+			return CommandResponseMessage{}, ErrBridgeClosed
+		case resp := <-msg.ResponseCh:
+			return resp, nil
+		}
+	}
+}
+
 // ErrBridgeClosed is returned when operations are attempted on a closed bridge.
 var ErrBridgeClosed = &BridgeError{Message: "bridge is closed"}
 
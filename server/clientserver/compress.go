@@ -0,0 +1,54 @@
+package clientserver
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+
+	"github.com/gorilla/websocket"
+)
+
+// DefaultCompressThreshold is the encoded size in bytes above which tagData
+// messages are gzipped for clients that subscribed with compress.
+const DefaultCompressThreshold = 4096
+
+// encodedMessage is a message encoded once and shared by every client it is
+// sent to. The gzipped form is only made if a compressing client needs it.
+type encodedMessage struct {
+	json      []byte
+	threshold int
+	gzipped   []byte
+}
+
+// encodeMessage encodes msg as JSON. Encodings longer than threshold are sent
+// gzipped to clients that asked for compression; 0 never compresses.
+func encodeMessage(msg any, threshold int) (*encodedMessage, error) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	return &encodedMessage{json: data, threshold: threshold}, nil
+}
+
+// writeTo sends the message to conn: as a binary frame holding the gzipped
+// JSON if compress is set and the message is over the threshold, otherwise
+// as a text frame like WriteJSON. Callers go through Server.writeMessage so
+// the write holds the connection's write lock.
+func (m *encodedMessage) writeTo(conn *websocket.Conn, compress bool) error {
+	if !compress || m.threshold <= 0 || len(m.json) <= m.threshold {
+		return conn.WriteMessage(websocket.TextMessage, m.json)
+	}
+
+	if m.gzipped == nil {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(m.json); err != nil {
+			return err
+		}
+		if err := zw.Close(); err != nil {
+			return err
+		}
+		m.gzipped = buf.Bytes()
+	}
+	return conn.WriteMessage(websocket.BinaryMessage, m.gzipped)
+}
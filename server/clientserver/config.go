@@ -1,5 +1,7 @@
 package clientserver
 
+import "github.com/dotside-studios/davi-nfc-agent/server"
+
 // Config holds configuration for the Client Server.
 type Config struct {
 	// Port is the HTTP/WebSocket port to listen on
@@ -11,6 +13,50 @@ type Config struct {
 	// TLS configuration (optional)
 	CertFile string // Path to TLS certificate file
 	KeyFile  string // Path to TLS private key file
+
+	// TimestampFormat controls how timestamps are encoded in payloads
+	// (default: RFC3339 strings)
+	TimestampFormat server.TimestampFormat
+
+	// DebugCommands enables raw tag access commands (readPages, writePage,
+	// readMAD, readRecord, readManufacturerBlock)
+	DebugCommands bool
+
+	// ExclusiveWriter lets only the first connected client write: it holds the
+	// writer session, and clients connecting while it is held join as readers
+	// whose write requests fail with READ_ONLY_SESSION. When false every
+	// client is a writer.
+	ExclusiveWriter bool
+
+	// WriterDisconnect decides whether the writer session stays held while an
+	// operation of a disconnected writer is still running (default: WriterHold).
+	// Only used with ExclusiveWriter.
+	WriterDisconnect WriterDisconnectPolicy
+
+	// CompressThreshold is the encoded size in bytes above which tagData
+	// messages are gzipped for clients that subscribe with compress
+	// (0 disables compression; see DefaultCompressThreshold)
+	CompressThreshold int
+
+	// SigningKey, when set, signs broadcast messages (tagData, deviceStatus,
+	// deviceStatusPatch and cardHeartbeat) with an HMAC-SHA256 of their
+	// payload in a sig field
+	SigningKey []byte
+
+	// Events is served at /api/v1/events when set
+	Events *server.EventLog
+
+	// OnClientCountChange, when set, is called with the new number of
+	// connected clients each time a client connects or disconnects. Calls are
+	// made in order while the client list is locked, so it must not block.
+	OnClientCountChange func(clients int)
+
+	// OnWriterChange, when set, is called with true when a client claims the
+	// writer session and false when the session is released (ExclusiveWriter
+	// only). Calls are made
+	// in order while the client list is locked, before the claiming client's
+	// ready message is sent, so it must not block.
+	OnWriterChange func(held bool)
 }
 
 // TLSEnabled returns true if TLS is configured.
@@ -0,0 +1,183 @@
+package clientserver
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dotside-studios/davi-nfc-agent/nfc"
+	"github.com/dotside-studios/davi-nfc-agent/protocol"
+	"github.com/dotside-studios/davi-nfc-agent/server"
+	"github.com/gorilla/websocket"
+)
+
+// testHarness wires a client Server to a MockManager-backed reader over an
+// httptest WebSocket listener, so tests can drive the full handler stack.
+type testHarness struct {
+	t          *testing.T
+	server     *Server
+	bridge     *server.ServerBridge
+	reader     *nfc.NFCReader
+	tag        *nfc.MockClassicTag
+	httpServer *httptest.Server
+
+	writeMu          sync.Mutex
+	writeResponder   func(server.WriteRequestMessage) server.WriteResponseMessage
+	commandResponder func(server.CommandMessage) server.CommandResponseMessage
+}
+
+// respondToWrites makes the device side answer write requests with fn instead
+// of writing to the mock reader.
+func (h *testHarness) respondToWrites(fn func(server.WriteRequestMessage) server.WriteResponseMessage) {
+	h.writeMu.Lock()
+	defer h.writeMu.Unlock()
+	h.writeResponder = fn
+}
+
+// respondToCommands makes the device side answer commands with fn. Without
+// it commands fail.
+func (h *testHarness) respondToCommands(fn func(server.CommandMessage) server.CommandResponseMessage) {
+	h.writeMu.Lock()
+	defer h.writeMu.Unlock()
+	h.commandResponder = fn
+}
+
+// newTestHarness starts a client server with the given config. The device side
+// of the bridge is served by the reader, the same way the device server does.
+func newTestHarness(t *testing.T, config Config) *testHarness {
+	t.Helper()
+
+	manager := nfc.NewMockManager()
+	manager.DevicesList = []string{"mock:usb:001"}
+
+	tag := nfc.NewMockClassicTag("04A1B2C3")
+	tag.IsConnected = true
+	tag.Data = nfc.EncodeNdefMessageWithTextRecord("Hello", "en")
+
+	device := nfc.NewMockDevice()
+	device.SetTags([]nfc.Tag{tag})
+	manager.MockDevice = device
+
+	reader, err := nfc.NewNFCReader("mock:usb:001", manager, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Failed to create NFCReader: %v", err)
+	}
+
+	bridge := server.NewServerBridge()
+	s := New(config, bridge)
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	go s.listenBridgeTagData()
+	go s.listenBridgeDeviceStatus()
+
+	h := &testHarness{
+		t:          t,
+		server:     s,
+		bridge:     bridge,
+		reader:     reader,
+		tag:        tag,
+		httpServer: httptest.NewServer(s.routes()),
+	}
+	go h.serveWriteRequests()
+
+	t.Cleanup(func() {
+		h.httpServer.Close()
+		s.cancel()
+		reader.Close()
+	})
+
+	return h
+}
+
+// serveWriteRequests answers bridge write requests using the mock reader,
+// and commands with the command responder.
+func (h *testHarness) serveWriteRequests() {
+	for {
+		select {
+		case <-h.server.ctx.Done():
+			return
+		case msg := <-h.bridge.Command:
+			h.writeMu.Lock()
+			respond := h.commandResponder
+			h.writeMu.Unlock()
+			if respond == nil {
+				msg.ResponseCh <- server.CommandResponseMessage{RequestID: msg.RequestID, Error: "no command responder"}
+				continue
+			}
+			msg.ResponseCh <- respond(msg)
+		case msg := <-h.bridge.WriteRequest:
+			h.writeMu.Lock()
+			respond := h.writeResponder
+			h.writeMu.Unlock()
+			if respond != nil {
+				msg.ResponseCh <- respond(msg)
+				continue
+			}
+
+			resp := server.WriteResponseMessage{RequestID: msg.RequestID, Success: true}
+			if err := server.HandleWriteRequest(h.reader, msg.Request); err != nil {
+				resp.Success = false
+				resp.Error = err.Error()
+			}
+			msg.ResponseCh <- resp
+		}
+	}
+}
+
+// dial opens a WebSocket connection with the given query string.
+func (h *testHarness) dial(query string) (*websocket.Conn, *http.Response, error) {
+	url := "ws" + strings.TrimPrefix(h.httpServer.URL, "http") + "/ws"
+	if query != "" {
+		url += "?" + query
+	}
+	return websocket.DefaultDialer.Dial(url, nil)
+}
+
+// connect dials the server with the given query string and consumes the ready
+// handshake, returning the connection and the session role it was assigned.
+func (h *testHarness) connect(query string) (*websocket.Conn, string) {
+	h.t.Helper()
+
+	conn, _, err := h.dial(query)
+	if err != nil {
+		h.t.Fatalf("Failed to dial: %v", err)
+	}
+	h.t.Cleanup(func() { conn.Close() })
+
+	var ready struct {
+		Type    string                `json:"type"`
+		Payload protocol.ReadyPayload `json:"payload"`
+	}
+	h.readJSON(conn, &ready)
+	if ready.Type != server.WSMessageTypeReady {
+		h.t.Fatalf("Expected %q handshake, got %q", server.WSMessageTypeReady, ready.Type)
+	}
+
+	return conn, ready.Payload.SessionRole
+}
+
+// readJSON reads the next message from conn into v, failing the test after a second.
+func (h *testHarness) readJSON(conn *websocket.Conn, v any) {
+	h.t.Helper()
+
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	if err := conn.ReadJSON(v); err != nil {
+		h.t.Fatalf("Failed to read message: %v", err)
+	}
+}
+
+// request sends a request and returns the next response on conn.
+func (h *testHarness) request(conn *websocket.Conn, req protocol.WebSocketRequest) protocol.WebSocketResponse {
+	h.t.Helper()
+
+	if err := conn.WriteJSON(req); err != nil {
+		h.t.Fatalf("Failed to send request: %v", err)
+	}
+
+	var resp protocol.WebSocketResponse
+	h.readJSON(conn, &resp)
+	return resp
+}
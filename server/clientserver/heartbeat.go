@@ -0,0 +1,106 @@
+package clientserver
+
+import (
+	"log"
+	"time"
+
+	"github.com/dotside-studios/davi-nfc-agent/nfc"
+	"github.com/dotside-studios/davi-nfc-agent/protocol"
+	"github.com/dotside-studios/davi-nfc-agent/server"
+	"github.com/gorilla/websocket"
+)
+
+// MinHeartbeatInterval is the shortest cardHeartbeat interval a client can
+// subscribe with.
+const MinHeartbeatInterval = 100 * time.Millisecond
+
+// cardPresence is the card currently on the reader, as seen by the client
+// server: set by tagData, cleared by a status without cardPresent.
+type cardPresence struct {
+	uid   string
+	since time.Time // When the card was first reported
+}
+
+// trackPresence updates the present card from a tagData event. A card that
+// is read again keeps its original detection time.
+func (s *Server) trackPresence(data nfc.NFCData) {
+	if data.Card == nil {
+		return
+	}
+	s.cardMu.Lock()
+	defer s.cardMu.Unlock()
+	if s.presence == nil || s.presence.uid != data.Card.UID {
+		s.presence = &cardPresence{uid: data.Card.UID, since: time.Now()}
+	}
+}
+
+// trackStatusPresence clears the present card once the reader reports none.
+func (s *Server) trackStatusPresence(status nfc.DeviceStatus) {
+	if status.Connected && status.CardPresent {
+		return
+	}
+	s.cardMu.Lock()
+	s.presence = nil
+	s.cardMu.Unlock()
+}
+
+// heartbeat is a client's cardHeartbeat subscription.
+type heartbeat struct {
+	interval time.Duration
+	stop     chan struct{} // Closed to end the subscription
+}
+
+// setHeartbeat starts sending cardHeartbeat messages to conn every interval
+// while a card is present, replacing an earlier subscription. A zero
+// interval stops them. Callers must hold clientsMux.
+func (s *Server) setHeartbeat(conn *websocket.Conn, interval time.Duration) {
+	if hb, ok := s.heartbeats[conn]; ok {
+		close(hb.stop)
+		delete(s.heartbeats, conn)
+	}
+	if interval <= 0 {
+		return
+	}
+
+	hb := &heartbeat{interval: interval, stop: make(chan struct{})}
+	s.heartbeats[conn] = hb
+	go s.runHeartbeat(conn, interval, hb.stop)
+}
+
+// runHeartbeat sends cardHeartbeat messages to conn until stop is closed.
+func (s *Server) runHeartbeat(conn *websocket.Conn, interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			s.cardMu.RLock()
+			presence := s.presence
+			s.cardMu.RUnlock()
+			if presence == nil {
+				continue
+			}
+
+			message := s.signMessage(protocol.WebSocketMessage{
+				Type: server.WSMessageTypeCardHeartbeat,
+				Payload: protocol.CardHeartbeatPayload{
+					UID:     presence.uid,
+					DwellMs: now.Sub(presence.since).Milliseconds(),
+				},
+			})
+
+			// Skip the send if the subscription just ended
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			if err := s.writeJSON(conn, message); err != nil {
+				log.Printf("[client] Failed to send card heartbeat: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package clientserver
+
+import (
+	"log"
+
+	"github.com/dotside-studios/davi-nfc-agent/protocol"
+	"github.com/dotside-studios/davi-nfc-agent/server"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+// cardReference is an NDEF message captured with captureReference, kept for
+// the connection that captured it until it disconnects.
+type cardReference struct {
+	uid  string
+	data string // NDEF message, uppercase hex
+}
+
+// handleCaptureReference reads the card on the reader and stores its NDEF
+// message as the connection's reference, replacing an earlier one.
+func (s *Server) handleCaptureReference(conn *websocket.Conn, clientID string, req protocol.WebSocketRequest) {
+	requestID := req.ID
+	if requestID == "" {
+		requestID = uuid.New().String()
+	}
+
+	response, err := s.bridge.SendCommand(server.CommandMessage{
+		RequestID:  requestID,
+		ClientID:   clientID,
+		Type:       req.Type,
+		Payload:    req.Payload,
+		ResponseCh: make(chan server.CommandResponseMessage, 1),
+	})
+	if err != nil {
+		log.Printf("[client] %s request failed: %v", req.Type, err)
+		s.sendErrorResponse(conn, req.ID, "COMMAND_FAILED", err.Error())
+		return
+	}
+
+	// The client may have disconnected during the read; its cleanup already
+	// ran, so a reference stored now would never be removed
+	if card, ok := response.Payload.(protocol.ReadCardPayload); ok && response.Success {
+		s.clientsMux.Lock()
+		if _, connected := s.clients[conn]; connected {
+			s.references[conn] = cardReference{uid: card.UID, data: card.Data}
+		}
+		s.clientsMux.Unlock()
+	}
+
+	wsResponse := protocol.WebSocketResponse{
+		ID:      req.ID,
+		Type:    server.WSMessageTypeCaptureReferenceResponse,
+		Success: response.Success,
+		Payload: response.Payload,
+		Error:   response.Error,
+	}
+	if err := s.writeJSON(conn, wsResponse); err != nil {
+		log.Printf("[client] Failed to send %s: %v", wsResponse.Type, err)
+	}
+}
+
+// handleCompareToReference compares the card on the reader with the
+// connection's reference. The reference travels to the device server with
+// the command, which reads the card and compares.
+func (s *Server) handleCompareToReference(conn *websocket.Conn, clientID string, req protocol.WebSocketRequest) {
+	s.clientsMux.RLock()
+	reference, ok := s.references[conn]
+	s.clientsMux.RUnlock()
+	if !ok {
+		s.sendErrorResponse(conn, req.ID, "NO_REFERENCE", "No reference captured; send captureReference first")
+		return
+	}
+
+	payload := make(map[string]any, len(req.Payload)+2)
+	if mode, ok := req.Payload["mode"]; ok {
+		payload["mode"] = mode
+	}
+	payload["reference"] = reference.data
+	payload["referenceUid"] = reference.uid
+	req.Payload = payload
+
+	s.handleCommand(conn, clientID, req, server.WSMessageTypeCompareToReferenceResponse)
+}
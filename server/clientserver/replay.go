@@ -0,0 +1,75 @@
+package clientserver
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/dotside-studios/davi-nfc-agent/protocol"
+	"github.com/dotside-studios/davi-nfc-agent/server"
+	"github.com/gorilla/websocket"
+)
+
+// ReplayPolicy selects what a client receives about the last scanned card when
+// it connects or subscribes.
+type ReplayPolicy string
+
+const (
+	// ReplayUID sends the card identity (uid, type, technology, scannedAt)
+	// with empty text and no records (default).
+	ReplayUID ReplayPolicy = "uid"
+	// ReplayFull sends the last card exactly as it was broadcast, records and text included.
+	ReplayFull ReplayPolicy = "full"
+	// ReplayNone sends nothing.
+	ReplayNone ReplayPolicy = "none"
+)
+
+// ParseReplayPolicy parses "uid", "full" or "none"; empty selects ReplayUID.
+func ParseReplayPolicy(s string) (ReplayPolicy, error) {
+	switch p := ReplayPolicy(s); p {
+	case "":
+		return ReplayUID, nil
+	case ReplayUID, ReplayFull, ReplayNone:
+		return p, nil
+	default:
+		return ReplayUID, fmt.Errorf("unknown replay policy %q (expected uid, full or none)", s)
+	}
+}
+
+// replayLastCard sends the last broadcast card to conn according to policy.
+func (s *Server) replayLastCard(conn *websocket.Conn, policy ReplayPolicy) {
+	if policy == ReplayNone {
+		return
+	}
+
+	s.cardMu.RLock()
+	last := s.lastPayload
+	s.cardMu.RUnlock()
+	if last == nil {
+		return
+	}
+
+	payload := make(map[string]interface{}, len(last))
+	for k, v := range last {
+		payload[k] = v
+	}
+	if policy == ReplayUID {
+		payload["text"] = ""
+		delete(payload, "message")
+	}
+
+	message, err := encodeMessage(s.signMessage(protocol.WebSocketMessage{
+		Type:    server.WSMessageTypeTagData,
+		Payload: payload,
+	}), s.config.CompressThreshold)
+	if err != nil {
+		log.Printf("[client] Failed to encode last card: %v", err)
+		return
+	}
+
+	s.clientsMux.RLock()
+	compress := s.compressClients[conn]
+	s.clientsMux.RUnlock()
+	if err := s.writeMessage(conn, message, compress); err != nil {
+		log.Printf("[client] Failed to replay last card: %v", err)
+	}
+}
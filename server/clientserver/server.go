@@ -4,12 +4,16 @@ package clientserver
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"math"
 	"net/http"
+	"strconv"
 	"sync"
 	"time"
 
+	"github.com/dotside-studios/davi-nfc-agent/buildinfo"
 	"github.com/dotside-studios/davi-nfc-agent/nfc"
 	"github.com/dotside-studios/davi-nfc-agent/protocol"
 	"github.com/dotside-studios/davi-nfc-agent/server"
@@ -31,19 +35,43 @@ type Server struct {
 
 	// Client connections (multiple allowed)
 	clients    map[*websocket.Conn]string // conn -> clientID
+	writerConn *websocket.Conn            // client holding the writer session
 	clientsMux sync.RWMutex
 
+	// Device status delivery; guarded by clientsMux
+	deltaClients map[*websocket.Conn]bool // clients receiving deviceStatusPatch
+	lastStatus   *nfc.DeviceStatus        // last broadcast status, baseline for patches
+
+	// Clients that subscribed with compress; guarded by clientsMux
+	compressClients map[*websocket.Conn]bool
+
+	// Clients that subscribed with heartbeatMs; guarded by clientsMux
+	heartbeats map[*websocket.Conn]*heartbeat
+
+	// NDEF references stored with captureReference; guarded by clientsMux
+	references map[*websocket.Conn]cardReference
+
+	// Write lock of each connection (*websocket.Conn -> *sync.Mutex), from
+	// upgrade until every goroutine serving it is done
+	writeLocks sync.Map
+
 	// Last received data for late joiners
-	lastCard *nfc.Card
-	cardMu   sync.RWMutex
+	lastCard    *nfc.Card
+	lastPayload map[string]interface{} // tagData payload as broadcast for lastCard
+	presence    *cardPresence          // Card on the reader, nil if none
+	cardMu      sync.RWMutex
 }
 
 // New creates a new client server instance.
 func New(config Config, bridge *server.ServerBridge) *Server {
 	return &Server{
-		config:  config,
-		bridge:  bridge,
-		clients: make(map[*websocket.Conn]string),
+		config:          config,
+		bridge:          bridge,
+		clients:         make(map[*websocket.Conn]string),
+		deltaClients:    make(map[*websocket.Conn]bool),
+		compressClients: make(map[*websocket.Conn]bool),
+		heartbeats:      make(map[*websocket.Conn]*heartbeat),
+		references:      make(map[*websocket.Conn]cardReference),
 		upgrader: websocket.Upgrader{
 			CheckOrigin: func(r *http.Request) bool {
 				return true
@@ -59,38 +87,10 @@ func (s *Server) Start() error {
 	// Create context
 	s.ctx, s.cancel = context.WithCancel(context.Background())
 
-	// Set up HTTP routes
-	mux := http.NewServeMux()
-
-	// WebSocket endpoint for clients
-	mux.HandleFunc("/ws", s.enableCORS(func(w http.ResponseWriter, r *http.Request) {
-		s.handleWebSocket(w, r)
-	}))
-
-	// Health check
-	mux.HandleFunc("/api/v1/health", s.enableCORS(func(w http.ResponseWriter, r *http.Request) {
-		if r.Method != http.MethodGet && r.Method != http.MethodOptions {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-			return
-		}
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"status":    "ok",
-			"type":      "client",
-			"timestamp": time.Now().Format("2006-01-02T15:04:05Z07:00"),
-			"clients":   s.clientCount(),
-		})
-	}))
-
-	// Root
-	mux.HandleFunc("/", s.enableCORS(func(w http.ResponseWriter, r *http.Request) {
-		w.Write([]byte("NFC Client Server"))
-	}))
-
 	// Create HTTP server
 	s.httpServer = &http.Server{
 		Addr:    fmt.Sprintf(":%d", s.config.Port),
-		Handler: mux,
+		Handler: s.routes(),
 	}
 
 	// Start HTTP server in goroutine
@@ -119,6 +119,95 @@ func (s *Server) Start() error {
 	return nil
 }
 
+// routes builds the HTTP handler serving the WebSocket and REST endpoints.
+func (s *Server) routes() *http.ServeMux {
+	mux := http.NewServeMux()
+
+	// WebSocket endpoint for clients
+	mux.HandleFunc("/ws", s.enableCORS(func(w http.ResponseWriter, r *http.Request) {
+		s.handleWebSocket(w, r)
+	}))
+
+	// Health check
+	mux.HandleFunc("/api/v1/health", s.enableCORS(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodOptions {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		health := map[string]interface{}{
+			"status":  "ok",
+			"type":    "client",
+			"clients": s.clientCount(),
+		}
+		server.SetTimestamp(health, "timestamp", time.Now(), s.config.TimestampFormat)
+		json.NewEncoder(w).Encode(health)
+	}))
+
+	// Version and feature flags
+	mux.HandleFunc("/api/v1/version", s.enableCORS(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodOptions {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(server.VersionInfo())
+	}))
+
+	// Recent events for troubleshooting
+	mux.HandleFunc("/api/v1/events", s.enableCORS(s.handleEvents))
+
+	// Root
+	mux.HandleFunc("/", s.enableCORS(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("NFC Client Server"))
+	}))
+
+	return mux
+}
+
+// handleEvents returns the recorded events, optionally only those after the
+// since query parameter (RFC3339 or epoch milliseconds).
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.config.APISecret != "" && r.URL.Query().Get("secret") != s.config.APISecret {
+		http.Error(w, "Unauthorized: Invalid API secret", http.StatusUnauthorized)
+		return
+	}
+	if s.config.Events == nil {
+		http.Error(w, "Event log is disabled", http.StatusNotFound)
+		return
+	}
+
+	var since time.Time
+	if v := r.URL.Query().Get("since"); v != "" {
+		t, err := parseSince(v)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		since = t
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"events": s.config.Events.Since(since),
+	})
+}
+
+// parseSince parses an RFC3339 timestamp or integer epoch milliseconds.
+func parseSince(v string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+		return t, nil
+	}
+	if ms, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return time.UnixMilli(ms), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid since %q (expected RFC3339 or epoch milliseconds)", v)
+}
+
 // Stop stops the client server.
 func (s *Server) Stop() {
 	if s.httpServer != nil {
@@ -132,6 +221,16 @@ func (s *Server) Stop() {
 	}
 }
 
+// StopAccepting closes the listener so no new clients connect, waiting until
+// ctx ends for plain HTTP requests in flight. Connected WebSocket clients are
+// kept until Stop.
+func (s *Server) StopAccepting(ctx context.Context) error {
+	if s.httpServer == nil {
+		return nil
+	}
+	return s.httpServer.Shutdown(ctx)
+}
+
 // clientCount returns the number of connected clients.
 func (s *Server) clientCount() int {
 	s.clientsMux.RLock()
@@ -139,6 +238,25 @@ func (s *Server) clientCount() int {
 	return len(s.clients)
 }
 
+// notifyClientCount reports the current client count to the configured
+// callback. Callers must hold clientsMux.
+func (s *Server) notifyClientCount() {
+	if s.config.OnClientCountChange != nil {
+		s.config.OnClientCountChange(len(s.clients))
+	}
+}
+
+// setWriter hands the writer session to conn, or releases it when conn is
+// nil, and reports the change to the configured callback. Callers must hold
+// clientsMux.
+func (s *Server) setWriter(conn *websocket.Conn) {
+	held := s.writerConn != nil
+	s.writerConn = conn
+	if s.config.OnWriterChange != nil && held != (conn != nil) {
+		s.config.OnWriterChange(conn != nil)
+	}
+}
+
 // GetLastCard returns the last received card data.
 func (s *Server) GetLastCard() *nfc.Card {
 	s.cardMu.RLock()
@@ -158,6 +276,18 @@ func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	replay, err := ParseReplayPolicy(r.URL.Query().Get("replay"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	statusMode, err := ParseStatusMode(r.URL.Query().Get("status"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	conn, err := s.upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		log.Printf("[client] WebSocket upgrade error: %v", err)
@@ -166,28 +296,96 @@ func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 
 	clientID := uuid.New().String()
 
-	// Add to clients map
-	s.clientsMux.Lock()
-	s.clients[conn] = clientID
-	s.clientsMux.Unlock()
+	// Registered first so it is removed last, after the writer queue is done
+	s.writeLocks.Store(conn, &sync.Mutex{})
+	defer s.writeLocks.Delete(conn)
+
+	// With ExclusiveWriter the first client claims the writer session and
+	// later clients are readers; otherwise every client may write
+	role := protocol.SessionRoleWriter
+	if s.config.ExclusiveWriter {
+		s.clientsMux.Lock()
+		role = protocol.SessionRoleReader
+		if s.writerConn == nil {
+			s.setWriter(conn)
+			role = protocol.SessionRoleWriter
+		}
+		s.clientsMux.Unlock()
+	}
 
-	log.Printf("[client] Client connected: %s (total: %d)", clientID[:8], s.clientCount())
+	// Writer operations run off the read loop so a dropped writer is noticed
+	// while one is still in flight
+	var writerOps *writerQueue
+	if role == protocol.SessionRoleWriter {
+		writerOps = newWriterQueue()
+	}
 
 	defer func() {
 		conn.Close()
 		s.clientsMux.Lock()
 		delete(s.clients, conn)
+		delete(s.deltaClients, conn)
+		delete(s.compressClients, conn)
+		s.setHeartbeat(conn, 0)
+		delete(s.references, conn)
+		hold := s.writerConn == conn && s.config.WriterDisconnect != WriterRelease
+		if s.writerConn == conn && !hold {
+			s.setWriter(nil)
+		}
+		s.notifyClientCount()
 		s.clientsMux.Unlock()
 		log.Printf("[client] Client disconnected: %s (total: %d)", clientID[:8], s.clientCount())
+
+		if writerOps == nil {
+			return
+		}
+		writerOps.close()
+		if !hold {
+			return
+		}
+		// Keep other clients from claiming the session until the in-flight
+		// operation has finished with the card
+		if writerOps.busy() {
+			log.Printf("[client] Holding writer session of %s until its operation finishes", clientID[:8])
+		}
+		writerOps.wait()
+		s.clientsMux.Lock()
+		if s.writerConn == conn {
+			s.setWriter(nil)
+		}
+		s.clientsMux.Unlock()
 	}()
 
-	// Send last card data if available
-	s.cardMu.RLock()
-	lastCard := s.lastCard
-	s.cardMu.RUnlock()
-	if lastCard != nil {
-		s.sendTagDataToClient(conn, nfc.NFCData{Card: lastCard})
+	// Send handshake before registering so no broadcast can precede it
+	ready := protocol.WebSocketMessage{
+		Type: server.WSMessageTypeReady,
+		Payload: protocol.ReadyPayload{
+			ServerVersion: buildinfo.Version,
+			SessionRole:   role,
+		},
 	}
+	if err := s.writeJSON(conn, ready); err != nil {
+		log.Printf("[client] Failed to send ready message: %v", err)
+		return
+	}
+
+	// Add to clients map
+	s.clientsMux.Lock()
+	s.clients[conn] = clientID
+	s.notifyClientCount()
+	if statusMode == StatusDelta {
+		// Patches are relative to this baseline, so send it before any broadcast can
+		s.deltaClients[conn] = true
+		if s.lastStatus != nil {
+			s.sendDeviceStatus(conn, *s.lastStatus)
+		}
+	}
+	s.clientsMux.Unlock()
+
+	log.Printf("[client] Client connected: %s as %s (total: %d)", clientID[:8], role, s.clientCount())
+
+	// Send last card data if available
+	s.replayLastCard(conn, replay)
 
 	// Handle incoming messages
 	for {
@@ -206,10 +404,105 @@ func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 			continue
 		}
 
+		if writerOnlyTypes[req.Type] && role != protocol.SessionRoleWriter {
+			s.sendErrorResponse(conn, req.ID, "READ_ONLY_SESSION", "Another client holds the writer session")
+			continue
+		}
+
 		// Handle message types
 		switch req.Type {
 		case server.WSMessageTypeWriteRequest:
-			s.handleWriteRequest(conn, clientID, req)
+			writerOps.enqueue(func() { s.handleWriteRequest(conn, clientID, req) })
+		case server.WSMessageTypeFormatNDEF:
+			writerOps.enqueue(func() { s.handleCommand(conn, clientID, req, server.WSMessageTypeFormatNDEFResponse) })
+		case server.WSMessageTypeWriteAndRead:
+			writerOps.enqueue(func() { s.handleCommand(conn, clientID, req, server.WSMessageTypeWriteAndReadResponse) })
+		case server.WSMessageTypeCaptureReference:
+			writerOps.enqueue(func() { s.handleCaptureReference(conn, clientID, req) })
+		case server.WSMessageTypeCompareToReference:
+			writerOps.enqueue(func() { s.handleCompareToReference(conn, clientID, req) })
+		case server.WSMessageTypeWriteRaw:
+			writerOps.enqueue(func() { s.handleCommand(conn, clientID, req, server.WSMessageTypeWriteRawResponse) })
+		case server.WSMessageTypeWriteLocalizedText:
+			writerOps.enqueue(func() { s.handleCommand(conn, clientID, req, server.WSMessageTypeWriteLocalizedTextResponse) })
+		case server.WSMessageTypeClearCache:
+			writerOps.enqueue(func() { s.handleCommand(conn, clientID, req, server.WSMessageTypeClearCacheResponse) })
+		case server.WSMessageTypeResetDevice:
+			writerOps.enqueue(func() { s.handleCommand(conn, clientID, req, server.WSMessageTypeResetDeviceResponse) })
+		case server.WSMessageTypeReadManufacturerBlock:
+			if !s.config.DebugCommands {
+				s.sendErrorResponse(conn, req.ID, "DEBUG_DISABLED", "Debug commands are disabled")
+				continue
+			}
+			s.handleCommand(conn, clientID, req, server.WSMessageTypeReadManufacturerBlockResponse)
+		case server.WSMessageTypeGetCardInfo:
+			s.handleCommand(conn, clientID, req, server.WSMessageTypeGetCardInfoResponse)
+		case server.WSMessageTypeListTags:
+			s.handleCommand(conn, clientID, req, server.WSMessageTypeListTagsResponse)
+		case server.WSMessageTypeReadCard:
+			if trace, _ := req.Payload["trace"].(bool); trace && !s.config.DebugCommands {
+				s.sendErrorResponse(conn, req.ID, "DEBUG_DISABLED", "APDU traces require debug commands")
+				continue
+			}
+			s.handleCommand(conn, clientID, req, server.WSMessageTypeReadCardResponse)
+		case server.WSMessageTypeNormalize:
+			s.handleCommand(conn, clientID, req, server.WSMessageTypeNormalizeResponse)
+		case server.WSMessageTypeCheckWrite:
+			s.handleCommand(conn, clientID, req, server.WSMessageTypeCheckWriteResponse)
+		case server.WSMessageTypeQuickWritable:
+			s.handleCommand(conn, clientID, req, server.WSMessageTypeQuickWritableResponse)
+		case server.WSMessageTypeRescan:
+			s.handleCommand(conn, clientID, req, server.WSMessageTypeRescanResponse)
+		case server.WSMessageTypeGetCapabilities:
+			s.handleCommand(conn, clientID, req, server.WSMessageTypeGetCapabilitiesResponse)
+		case server.WSMessageTypeGetATR:
+			s.handleCommand(conn, clientID, req, server.WSMessageTypeGetATRResponse)
+		case server.WSMessageTypeGetWearStats:
+			s.handleCommand(conn, clientID, req, server.WSMessageTypeGetWearStatsResponse)
+		case server.WSMessageTypeGetLatencyStats:
+			s.handleCommand(conn, clientID, req, server.WSMessageTypeGetLatencyStatsResponse)
+		case server.WSMessageTypeGetAllowedTypes:
+			s.handleCommand(conn, clientID, req, server.WSMessageTypeGetAllowedTypesResponse)
+		case server.WSMessageTypeSetAllowedTypes:
+			writerOps.enqueue(func() { s.handleCommand(conn, clientID, req, server.WSMessageTypeSetAllowedTypesResponse) })
+		case server.WSMessageTypePause:
+			writerOps.enqueue(func() { s.handleCommand(conn, clientID, req, server.WSMessageTypePauseResponse) })
+		case server.WSMessageTypeResume:
+			writerOps.enqueue(func() { s.handleCommand(conn, clientID, req, server.WSMessageTypeResumeResponse) })
+		case server.WSMessageTypeReadRange:
+			s.handleCommand(conn, clientID, req, server.WSMessageTypeReadRangeResponse)
+		case server.WSMessageTypeWaitForCard:
+			// Runs in the background so the client can keep sending requests
+			// while waiting; the reply goes through the connection's write lock
+			go s.handleCommand(conn, clientID, req, server.WSMessageTypeWaitForCardResponse)
+		case server.WSMessageTypeReadPages:
+			if !s.config.DebugCommands {
+				s.sendErrorResponse(conn, req.ID, "DEBUG_DISABLED", "Debug commands are disabled")
+				continue
+			}
+			s.handleCommand(conn, clientID, req, server.WSMessageTypeReadPagesResponse)
+		case server.WSMessageTypeReadMAD:
+			if !s.config.DebugCommands {
+				s.sendErrorResponse(conn, req.ID, "DEBUG_DISABLED", "Debug commands are disabled")
+				continue
+			}
+			s.handleCommand(conn, clientID, req, server.WSMessageTypeReadMADResponse)
+		case server.WSMessageTypeReadRecord:
+			if !s.config.DebugCommands {
+				s.sendErrorResponse(conn, req.ID, "DEBUG_DISABLED", "Debug commands are disabled")
+				continue
+			}
+			s.handleCommand(conn, clientID, req, server.WSMessageTypeReadRecordResponse)
+		case server.WSMessageTypeWritePage:
+			if !s.config.DebugCommands {
+				s.sendErrorResponse(conn, req.ID, "DEBUG_DISABLED", "Debug commands are disabled")
+				continue
+			}
+			writerOps.enqueue(func() { s.handleCommand(conn, clientID, req, server.WSMessageTypeWritePageResponse) })
+		case server.WSMessageTypeSubscribe:
+			s.handleSubscribe(conn, req)
+		case server.WSMessageTypeGetVersion:
+			s.handleGetVersion(conn, req)
 		default:
 			log.Printf("[client] Unknown message type: %s", req.Type)
 			s.sendErrorResponse(conn, req.ID, "UNKNOWN_TYPE", fmt.Sprintf("Unknown message type: %s", req.Type))
@@ -217,6 +510,24 @@ func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// writerOnlyTypes are the requests that change the card or the reader. Only
+// writer sessions may send them, and they run in order on the writer queue.
+var writerOnlyTypes = map[string]bool{
+	server.WSMessageTypeWriteRequest:       true,
+	server.WSMessageTypeFormatNDEF:         true,
+	server.WSMessageTypeWriteAndRead:       true,
+	server.WSMessageTypeCaptureReference:   true,
+	server.WSMessageTypeCompareToReference: true,
+	server.WSMessageTypeWriteRaw:           true,
+	server.WSMessageTypeWriteLocalizedText: true,
+	server.WSMessageTypeClearCache:         true,
+	server.WSMessageTypeResetDevice:        true,
+	server.WSMessageTypeSetAllowedTypes:    true,
+	server.WSMessageTypePause:              true,
+	server.WSMessageTypeResume:             true,
+	server.WSMessageTypeWritePage:          true,
+}
+
 // handleWriteRequest handles write requests from clients.
 func (s *Server) handleWriteRequest(conn *websocket.Conn, clientID string, req protocol.WebSocketRequest) {
 	// Parse write request from payload
@@ -233,6 +544,10 @@ func (s *Server) handleWriteRequest(conn *websocket.Conn, clientID string, req p
 		s.sendErrorResponse(conn, req.ID, "INVALID_WRITE_REQUEST", "Failed to parse write request")
 		return
 	}
+	if writeReq.Trace && !s.config.DebugCommands {
+		s.sendErrorResponse(conn, req.ID, "DEBUG_DISABLED", "APDU traces require debug commands")
+		return
+	}
 
 	// Create request message
 	requestID := req.ID
@@ -262,21 +577,141 @@ func (s *Server) handleWriteRequest(conn *websocket.Conn, clientID string, req p
 		Success: response.Success,
 	}
 	if response.Success {
-		wsResponse.Payload = map[string]interface{}{
+		payload := map[string]interface{}{
 			"message": "Write operation completed successfully",
 		}
+		// APDU trace requested with "trace": true
+		if devicePayload, ok := response.Payload.(map[string]any); ok && devicePayload["trace"] != nil {
+			payload["trace"] = devicePayload["trace"]
+		}
+		wsResponse.Payload = payload
 	} else {
 		wsResponse.Error = response.Error
 		wsResponse.Payload = map[string]interface{}{
 			"code": "WRITE_FAILED",
 		}
+		// Keep a more specific code and its details from the device server
+		// (e.g. DEVICE_TIMEOUT, DEVICE_COOLDOWN with retryAfterMs, an APDU trace)
+		if payload, ok := response.Payload.(map[string]any); ok {
+			merged := map[string]interface{}{"code": "WRITE_FAILED"}
+			for k, v := range payload {
+				merged[k] = v
+			}
+			wsResponse.Payload = merged
+		}
 	}
 
-	if err := conn.WriteJSON(wsResponse); err != nil {
+	if err := s.writeJSON(conn, wsResponse); err != nil {
 		log.Printf("[client] Failed to send write response: %v", err)
 	}
 }
 
+// handleSubscribe acknowledges a subscribe message, applies its compress and
+// heartbeat options and replays the last card with the requested policy.
+func (s *Server) handleSubscribe(conn *websocket.Conn, req protocol.WebSocketRequest) {
+	policyStr, _ := req.Payload["replay"].(string)
+	policy, err := ParseReplayPolicy(policyStr)
+	if err != nil {
+		s.sendErrorResponse(conn, req.ID, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	compressOpt, hasCompress := req.Payload["compress"]
+	compress, ok := compressOpt.(bool)
+	if hasCompress && !ok {
+		s.sendErrorResponse(conn, req.ID, "INVALID_REQUEST", "compress must be a boolean")
+		return
+	}
+
+	heartbeatOpt, hasHeartbeat := req.Payload["heartbeatMs"]
+	heartbeatMs, ok := heartbeatOpt.(float64)
+	heartbeatInterval := time.Duration(heartbeatMs) * time.Millisecond
+	if hasHeartbeat && (!ok || heartbeatMs != math.Trunc(heartbeatMs) || (heartbeatMs != 0 && heartbeatInterval < MinHeartbeatInterval)) {
+		s.sendErrorResponse(conn, req.ID, "INVALID_REQUEST",
+			fmt.Sprintf("heartbeatMs must be 0 or a whole number of at least %d", MinHeartbeatInterval.Milliseconds()))
+		return
+	}
+
+	// Without a threshold nothing is compressed, so report the option as off
+	s.clientsMux.Lock()
+	if hasCompress {
+		if compress && s.config.CompressThreshold > 0 {
+			s.compressClients[conn] = true
+		} else {
+			delete(s.compressClients, conn)
+		}
+	}
+	compress = s.compressClients[conn]
+	if hasHeartbeat {
+		s.setHeartbeat(conn, heartbeatInterval)
+	}
+	heartbeatInterval = 0
+	if hb := s.heartbeats[conn]; hb != nil {
+		heartbeatInterval = hb.interval
+	}
+	s.clientsMux.Unlock()
+
+	response := protocol.WebSocketResponse{
+		ID:      req.ID,
+		Type:    server.WSMessageTypeSubscribeResponse,
+		Success: true,
+		Payload: map[string]interface{}{"replay": string(policy), "compress": compress, "heartbeatMs": heartbeatInterval.Milliseconds()},
+	}
+	if err := s.writeJSON(conn, response); err != nil {
+		log.Printf("[client] Failed to send subscribe response: %v", err)
+		return
+	}
+
+	s.replayLastCard(conn, policy)
+}
+
+// handleGetVersion replies with the agent version and supported features.
+func (s *Server) handleGetVersion(conn *websocket.Conn, req protocol.WebSocketRequest) {
+	response := protocol.WebSocketResponse{
+		ID:      req.ID,
+		Type:    server.WSMessageTypeGetVersionResponse,
+		Success: true,
+		Payload: server.VersionInfo(),
+	}
+
+	if err := s.writeJSON(conn, response); err != nil {
+		log.Printf("[client] Failed to send version response: %v", err)
+	}
+}
+
+// handleCommand forwards a command to the device server and relays the result.
+func (s *Server) handleCommand(conn *websocket.Conn, clientID string, req protocol.WebSocketRequest, responseType string) {
+	requestID := req.ID
+	if requestID == "" {
+		requestID = uuid.New().String()
+	}
+
+	response, err := s.bridge.SendCommand(server.CommandMessage{
+		RequestID:  requestID,
+		ClientID:   clientID,
+		Type:       req.Type,
+		Payload:    req.Payload,
+		ResponseCh: make(chan server.CommandResponseMessage, 1),
+	})
+	if err != nil {
+		log.Printf("[client] %s request failed: %v", req.Type, err)
+		s.sendErrorResponse(conn, req.ID, "COMMAND_FAILED", err.Error())
+		return
+	}
+
+	wsResponse := protocol.WebSocketResponse{
+		ID:      req.ID,
+		Type:    responseType,
+		Success: response.Success,
+		Payload: response.Payload,
+		Error:   response.Error,
+	}
+
+	if err := s.writeJSON(conn, wsResponse); err != nil {
+		log.Printf("[client] Failed to send %s: %v", responseType, err)
+	}
+}
+
 // listenBridgeTagData listens for tag data from the bridge and broadcasts to clients.
 func (s *Server) listenBridgeTagData() {
 	for {
@@ -287,14 +722,23 @@ func (s *Server) listenBridgeTagData() {
 			if !ok {
 				return
 			}
+			if data.Unsupported != nil {
+				s.broadcastUnsupportedCard(data)
+				continue
+			}
+			payload := s.tagDataPayload(data)
+
+			s.trackPresence(data)
+
 			// Store last card
 			if data.Card != nil {
 				s.cardMu.Lock()
 				s.lastCard = data.Card
+				s.lastPayload = payload
 				s.cardMu.Unlock()
 			}
 			// Broadcast to all clients
-			s.broadcastTagData(data)
+			s.broadcastTagPayload(payload)
 		}
 	}
 }
@@ -309,23 +753,72 @@ func (s *Server) listenBridgeDeviceStatus() {
 			if !ok {
 				return
 			}
+			s.trackStatusPresence(status)
 			s.broadcastDeviceStatus(status)
 		}
 	}
 }
 
-// broadcastTagData sends tag data to all connected clients.
-func (s *Server) broadcastTagData(data nfc.NFCData) {
-	s.clientsMux.RLock()
-	defer s.clientsMux.RUnlock()
+// broadcastTagPayload sends a tagData payload to all connected clients.
+func (s *Server) broadcastTagPayload(payload map[string]interface{}) {
+	message, err := encodeMessage(s.signMessage(protocol.WebSocketMessage{
+		Type:    server.WSMessageTypeTagData,
+		Payload: payload,
+	}), s.config.CompressThreshold)
+	if err != nil {
+		log.Printf("[client] Failed to encode tag data: %v", err)
+		return
+	}
 
+	s.broadcastMessage(message, "tag data")
+}
+
+// broadcastUnsupportedCard sends an unsupportedCard message for data to all
+// connected clients.
+func (s *Server) broadcastUnsupportedCard(data nfc.NFCData) {
+	message, err := encodeMessage(s.signMessage(protocol.WebSocketMessage{
+		Type: server.WSMessageTypeUnsupportedCard,
+		Payload: protocol.UnsupportedCardPayload{
+			UID:      data.Unsupported.UID,
+			ATR:      data.Unsupported.ATR,
+			ReaderID: data.ReaderID,
+		},
+	}), s.config.CompressThreshold)
+	if err != nil {
+		log.Printf("[client] Failed to encode unsupported card: %v", err)
+		return
+	}
+
+	s.broadcastMessage(message, "unsupported card")
+}
+
+// broadcastMessage writes message to all connected clients, compressed for
+// those that asked for it. Like broadcastDeviceStatus it copies the clients
+// under the lock and writes outside it, so a slow client does not hold up the
+// others.
+func (s *Server) broadcastMessage(message *encodedMessage, what string) {
+	type target struct {
+		conn     *websocket.Conn
+		compress bool
+	}
+
+	s.clientsMux.RLock()
+	targets := make([]target, 0, len(s.clients))
 	for conn := range s.clients {
-		s.sendTagDataToClient(conn, data)
+		targets = append(targets, target{conn, s.compressClients[conn]})
+	}
+	s.clientsMux.RUnlock()
+
+	for _, t := range targets {
+		if err := s.writeMessage(t.conn, message, t.compress); err != nil {
+			log.Printf("[client] Failed to send %s: %v", what, err)
+		}
 	}
 }
 
-// sendTagDataToClient sends tag data to a specific client.
-func (s *Server) sendTagDataToClient(conn *websocket.Conn, data nfc.NFCData) {
+// tagDataPayload builds the tagData payload for data, reading the card's
+// message once so every client receives the same snapshot.
+func (s *Server) tagDataPayload(data nfc.NFCData) map[string]interface{} {
 	var errStr *string
 	if data.Err != nil {
 		e := data.Err.Error()
@@ -339,9 +832,12 @@ func (s *Server) sendTagDataToClient(conn *websocket.Conn, data nfc.NFCData) {
 			"uid":        data.Card.UID,
 			"type":       data.Card.Type,
 			"technology": data.Card.Technology,
-			"scannedAt":  data.Card.ScannedAt.Format("2006-01-02T15:04:05Z07:00"),
 			"err":        errStr,
 		}
+		server.SetTimestamp(payload, "scannedAt", data.Card.ScannedAt, s.config.TimestampFormat)
+		if p, ok := data.Card.GetUnderlyingTag().(nfc.ATRProvider); ok {
+			payload["atr"] = p.ATR()
+		}
 
 		// Try to read and parse message from card
 		if msg, err := data.Card.ReadMessage(); err == nil {
@@ -352,10 +848,16 @@ func (s *Server) sendTagDataToClient(conn *websocket.Conn, data nfc.NFCData) {
 				text, _ = ndefMsg.GetText()
 				messageInfo = ndefMsg.ToJSONMap()
 			} else if textMsg, ok := msg.(*nfc.TextMessage); ok {
-				text = textMsg.Text
-				messageInfo = map[string]interface{}{
-					"type": "raw",
-					"data": textMsg.Bytes(),
+				if recovered, ok := textMsg.NDEF(); ok {
+					text, _ = recovered.GetText()
+					messageInfo = recovered.ToJSONMap()
+					messageInfo["recovered"] = true
+				} else {
+					text = textMsg.Text
+					messageInfo = map[string]interface{}{
+						"type": "raw",
+						"data": textMsg.Bytes(),
+					}
 				}
 			}
 
@@ -372,33 +874,87 @@ func (s *Server) sendTagDataToClient(conn *websocket.Conn, data nfc.NFCData) {
 		}
 	}
 
-	message := protocol.WebSocketMessage{
-		Type:    server.WSMessageTypeTagData,
-		Payload: payload,
+	if data.Blank {
+		payload["blank"] = true
 	}
 
-	if err := conn.WriteJSON(message); err != nil {
-		log.Printf("[client] Failed to send tag data: %v", err)
+	// Lane readers merged by a MultiReader identify themselves
+	if data.ReaderID != "" {
+		payload["readerId"] = data.ReaderID
 	}
+
+	return payload
 }
 
-// broadcastDeviceStatus sends device status to all connected clients.
+// broadcastDeviceStatus sends device status to all connected clients. Clients
+// in StatusDelta mode receive only the fields that changed since the previous
+// status, and nothing if none did. The clients are copied under the lock and
+// written to outside it, so a slow client does not hold up the others.
 func (s *Server) broadcastDeviceStatus(status nfc.DeviceStatus) {
-	s.clientsMux.RLock()
-	defer s.clientsMux.RUnlock()
+	type target struct {
+		conn  *websocket.Conn
+		delta bool
+	}
+
+	s.clientsMux.Lock()
+	prev := s.lastStatus
+	s.lastStatus = &status
+	targets := make([]target, 0, len(s.clients))
+	hasDelta := false
+	for conn := range s.clients {
+		delta := s.deltaClients[conn]
+		hasDelta = hasDelta || delta
+		targets = append(targets, target{conn, delta})
+	}
+	s.clientsMux.Unlock()
 
-	message := protocol.WebSocketMessage{
+	message := s.signMessage(protocol.WebSocketMessage{
 		Type:    server.WSMessageTypeDeviceStatus,
 		Payload: status,
+	})
+
+	// Without a baseline (or if diffing fails) delta clients get the full status
+	var patchMessage *protocol.WebSocketMessage
+	unchanged := false
+	if prev != nil && hasDelta {
+		patch, err := statusPatch(*prev, status)
+		if err != nil {
+			log.Printf("[client] Failed to compute status patch: %v", err)
+		} else {
+			unchanged = len(patch) == 0
+			signed := s.signMessage(protocol.WebSocketMessage{
+				Type:    server.WSMessageTypeDeviceStatusPatch,
+				Payload: patch,
+			})
+			patchMessage = &signed
+		}
 	}
 
-	for conn := range s.clients {
-		if err := conn.WriteJSON(message); err != nil {
+	for _, t := range targets {
+		msg := message
+		if t.delta && patchMessage != nil {
+			if unchanged {
+				continue
+			}
+			msg = *patchMessage
+		}
+		if err := s.writeJSON(t.conn, msg); err != nil {
 			log.Printf("[client] Failed to send device status: %v", err)
 		}
 	}
 }
 
+// sendDeviceStatus sends the full device status to a single client.
+func (s *Server) sendDeviceStatus(conn *websocket.Conn, status nfc.DeviceStatus) {
+	message := s.signMessage(protocol.WebSocketMessage{
+		Type:    server.WSMessageTypeDeviceStatus,
+		Payload: status,
+	})
+	if err := s.writeJSON(conn, message); err != nil {
+		log.Printf("[client] Failed to send device status: %v", err)
+	}
+}
+
 // sendErrorResponse sends an error response to a WebSocket client.
 func (s *Server) sendErrorResponse(conn *websocket.Conn, requestID string, errorCode string, message string) {
 	response := protocol.WebSocketResponse{
@@ -411,11 +967,37 @@ func (s *Server) sendErrorResponse(conn *websocket.Conn, requestID string, error
 		},
 	}
 
-	if err := conn.WriteJSON(response); err != nil {
+	if err := s.writeJSON(conn, response); err != nil {
 		log.Printf("[client] Failed to send error response: %v", err)
 	}
 }
 
+// errConnClosed is returned for writes to a connection that is no longer served.
+var errConnClosed = errors.New("connection closed")
+
+// withWriteLock runs write while holding conn's write lock. gorilla/websocket
+// allows one writer per connection, and broadcasts, heartbeats and command
+// replies are sent from different goroutines.
+func (s *Server) withWriteLock(conn *websocket.Conn, write func() error) error {
+	mu, ok := s.writeLocks.Load(conn)
+	if !ok {
+		return errConnClosed
+	}
+	mu.(*sync.Mutex).Lock()
+	defer mu.(*sync.Mutex).Unlock()
+	return write()
+}
+
+// writeJSON sends v to conn as JSON under the connection's write lock.
+func (s *Server) writeJSON(conn *websocket.Conn, v any) error {
+	return s.withWriteLock(conn, func() error { return conn.WriteJSON(v) })
+}
+
+// writeMessage sends an encoded message to conn under the connection's write lock.
+func (s *Server) writeMessage(conn *websocket.Conn, message *encodedMessage, compress bool) error {
+	return s.withWriteLock(conn, func() error { return message.writeTo(conn, compress) })
+}
+
 // enableCORS adds CORS headers.
 func (s *Server) enableCORS(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
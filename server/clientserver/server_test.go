@@ -0,0 +1,849 @@
+package clientserver
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dotside-studios/davi-nfc-agent/nfc"
+	"github.com/dotside-studios/davi-nfc-agent/protocol"
+	"github.com/dotside-studios/davi-nfc-agent/server"
+	"github.com/gorilla/websocket"
+)
+
+// TestServer_WriteRequest tests the write path end to end: the writer session's
+// request reaches the reader and the response carries the request ID.
+func TestServer_WriteRequest(t *testing.T) {
+	h := newTestHarness(t, Config{})
+
+	conn, role := h.connect("")
+	if role != protocol.SessionRoleWriter {
+		t.Fatalf("Expected first client to be the writer, got %q", role)
+	}
+
+	resp := h.request(conn, protocol.WebSocketRequest{
+		ID:   "req_1",
+		Type: server.WSMessageTypeWriteRequest,
+		Payload: map[string]any{
+			"records": []map[string]any{
+				{"type": "text", "content": "Written over the wire"},
+			},
+		},
+	})
+
+	if resp.Type != server.WSMessageTypeWriteResponse {
+		t.Fatalf("Expected %q, got %q (error: %s)", server.WSMessageTypeWriteResponse, resp.Type, resp.Error)
+	}
+	if resp.ID != "req_1" {
+		t.Errorf("Expected response ID req_1, got %q", resp.ID)
+	}
+	if !resp.Success {
+		t.Fatalf("Expected write to succeed, got error: %s", resp.Error)
+	}
+
+	data, err := h.tag.ReadData()
+	if err != nil {
+		t.Fatalf("Failed to read mock tag: %v", err)
+	}
+	msg, err := nfc.DecodeNDEF(data)
+	if err != nil {
+		t.Fatalf("Failed to parse written NDEF: %v", err)
+	}
+	if text, _ := msg.GetText(); text != "Written over the wire" {
+		t.Errorf("Expected tag text %q, got %q", "Written over the wire", text)
+	}
+}
+
+// TestServer_WriteRequestErrorDetails tests that a failed write keeps the
+// device server's error code and details, such as the cooldown retry time.
+func TestServer_WriteRequestErrorDetails(t *testing.T) {
+	h := newTestHarness(t, Config{})
+	h.respondToWrites(func(msg server.WriteRequestMessage) server.WriteResponseMessage {
+		return server.WriteResponseMessage{
+			RequestID: msg.RequestID,
+			Error:     "device in cooldown, retry in 3s",
+			Payload:   map[string]any{"code": "DEVICE_COOLDOWN", "retryAfterMs": int64(3000)},
+		}
+	})
+	conn, _ := h.connect("")
+
+	resp := h.request(conn, protocol.WebSocketRequest{
+		ID:   "req_cooldown",
+		Type: server.WSMessageTypeWriteRequest,
+		Payload: map[string]any{
+			"records": []map[string]any{{"type": "text", "content": "later"}},
+		},
+	})
+
+	if resp.Success {
+		t.Fatal("Expected write to fail")
+	}
+	payload := resp.Payload.(map[string]any)
+	if payload["code"] != "DEVICE_COOLDOWN" || payload["retryAfterMs"] != float64(3000) {
+		t.Errorf("Expected DEVICE_COOLDOWN with retryAfterMs 3000, got %v", payload)
+	}
+}
+
+// TestServer_WriteRequestReadOnlySession tests that only the writer session may write.
+func TestServer_WriteRequestReadOnlySession(t *testing.T) {
+	h := newTestHarness(t, Config{ExclusiveWriter: true})
+
+	h.connect("")
+	conn, role := h.connect("")
+	if role != protocol.SessionRoleReader {
+		t.Fatalf("Expected second client to be a reader, got %q", role)
+	}
+
+	resp := h.request(conn, protocol.WebSocketRequest{
+		ID:   "req_2",
+		Type: server.WSMessageTypeWriteRequest,
+		Payload: map[string]any{
+			"records": []map[string]any{{"type": "text", "content": "nope"}},
+		},
+	})
+
+	if resp.Success || resp.ID != "req_2" {
+		t.Fatalf("Expected failed response for req_2, got %+v", resp)
+	}
+	if code := resp.Payload.(map[string]any)["code"]; code != "READ_ONLY_SESSION" {
+		t.Errorf("Expected READ_ONLY_SESSION, got %v", code)
+	}
+}
+
+// TestServer_SharedWriterByDefault tests that without ExclusiveWriter every
+// client joins as a writer and may write.
+func TestServer_SharedWriterByDefault(t *testing.T) {
+	h := newTestHarness(t, Config{})
+
+	h.connect("")
+	conn, role := h.connect("")
+	if role != protocol.SessionRoleWriter {
+		t.Fatalf("Expected second client to be a writer, got %q", role)
+	}
+
+	h.respondToCommands(func(msg server.CommandMessage) server.CommandResponseMessage {
+		return server.CommandResponseMessage{RequestID: msg.RequestID, Success: true}
+	})
+	resp := h.request(conn, protocol.WebSocketRequest{ID: "req_2", Type: server.WSMessageTypeClearCache})
+	if !resp.Success {
+		t.Errorf("Expected second client to be allowed to write, got %+v", resp)
+	}
+}
+
+// TestServer_WriterDisconnectDuringWrite tests that a writer dropping during a
+// slow write keeps the session held until the write finishes, unless the
+// release policy is configured.
+func TestServer_WriterDisconnectDuringWrite(t *testing.T) {
+	tests := []struct {
+		policy     WriterDisconnectPolicy
+		wantDuring string
+	}{
+		{WriterHold, protocol.SessionRoleReader},
+		{WriterRelease, protocol.SessionRoleWriter},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			h := newTestHarness(t, Config{ExclusiveWriter: true, WriterDisconnect: tt.policy})
+			started := make(chan struct{})
+			finish := make(chan struct{})
+			h.respondToWrites(func(msg server.WriteRequestMessage) server.WriteResponseMessage {
+				close(started)
+				<-finish
+				return server.WriteResponseMessage{RequestID: msg.RequestID, Success: true}
+			})
+
+			writer, _ := h.connect("")
+			if err := writer.WriteJSON(protocol.WebSocketRequest{
+				ID:   "req_slow",
+				Type: server.WSMessageTypeWriteRequest,
+				Payload: map[string]any{
+					"records": []map[string]any{{"type": "text", "content": "slow"}},
+				},
+			}); err != nil {
+				t.Fatalf("Failed to send request: %v", err)
+			}
+			<-started
+
+			writer.Close()
+			waitFor(t, func() bool { return h.server.clientCount() == 0 })
+
+			conn, role := h.connect("")
+			if role != tt.wantDuring {
+				t.Errorf("Role while write in flight = %q, want %q", role, tt.wantDuring)
+			}
+			conn.Close()
+			waitFor(t, func() bool { return h.server.clientCount() == 0 })
+
+			close(finish)
+			waitFor(t, func() bool {
+				h.server.clientsMux.RLock()
+				defer h.server.clientsMux.RUnlock()
+				return h.server.writerConn == nil
+			})
+			if _, role := h.connect(""); role != protocol.SessionRoleWriter {
+				t.Errorf("Role after write finished = %q, want writer", role)
+			}
+		})
+	}
+}
+
+// waitFor polls cond until it holds, failing the test after a second.
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("Condition not met within a second")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// TestServer_DebugCommandsDisabled tests that debug commands are rejected
+// unless debug commands are enabled.
+func TestServer_DebugCommandsDisabled(t *testing.T) {
+	h := newTestHarness(t, Config{})
+	conn, _ := h.connect("")
+
+	for _, msgType := range []string{server.WSMessageTypeReadPages, server.WSMessageTypeWritePage, server.WSMessageTypeReadMAD, server.WSMessageTypeReadRecord, server.WSMessageTypeReadManufacturerBlock} {
+		resp := h.request(conn, protocol.WebSocketRequest{ID: msgType, Type: msgType})
+		if resp.Success || resp.ID != msgType {
+			t.Fatalf("Expected failed response for %s, got %+v", msgType, resp)
+		}
+		if code := resp.Payload.(map[string]any)["code"]; code != "DEBUG_DISABLED" {
+			t.Errorf("%s: expected DEBUG_DISABLED, got %v", msgType, code)
+		}
+	}
+}
+
+// TestServer_TraceRequiresDebugCommands tests that APDU traces are only
+// returned when debug commands are enabled.
+func TestServer_TraceRequiresDebugCommands(t *testing.T) {
+	h := newTestHarness(t, Config{})
+	conn, _ := h.connect("")
+
+	requests := []protocol.WebSocketRequest{
+		{ID: "read", Type: server.WSMessageTypeReadCard, Payload: map[string]any{"trace": true}},
+		{ID: "write", Type: server.WSMessageTypeWriteRequest, Payload: map[string]any{
+			"records": []map[string]any{{"type": "text", "content": "traced"}},
+			"trace":   true,
+		}},
+	}
+	for _, req := range requests {
+		resp := h.request(conn, req)
+		if resp.Success || resp.ID != req.ID {
+			t.Fatalf("Expected failed response for %s, got %+v", req.ID, resp)
+		}
+		if code := resp.Payload.(map[string]any)["code"]; code != "DEBUG_DISABLED" {
+			t.Errorf("%s: expected DEBUG_DISABLED, got %v", req.ID, code)
+		}
+	}
+}
+
+// TestServer_APISecret tests that connections without the configured secret are rejected.
+func TestServer_APISecret(t *testing.T) {
+	h := newTestHarness(t, Config{APISecret: "s3cret"})
+
+	_, resp, err := h.dial("secret=wrong")
+	if err == nil {
+		t.Fatal("Expected dial with wrong secret to fail")
+	}
+	if resp == nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected 401 response, got %v", resp)
+	}
+
+	if _, role := h.connect("secret=s3cret"); role != protocol.SessionRoleWriter {
+		t.Errorf("Expected writer session with valid secret, got %q", role)
+	}
+}
+
+// TestServer_OnClientCountChange tests that connects and disconnects are
+// reported in order.
+func TestServer_OnClientCountChange(t *testing.T) {
+	counts := make(chan int, 10)
+	h := newTestHarness(t, Config{OnClientCountChange: func(n int) { counts <- n }})
+
+	first, _ := h.connect("")
+	second, _ := h.connect("")
+	first.Close()
+	second.Close()
+
+	for _, want := range []int{1, 2, 1, 0} {
+		select {
+		case got := <-counts:
+			if got != want {
+				t.Fatalf("Expected client count %d, got %d", want, got)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Timeout waiting for client count %d", want)
+		}
+	}
+}
+
+// TestServer_OnWriterChange tests that claiming and releasing the writer
+// session are reported, and reader sessions are not.
+func TestServer_OnWriterChange(t *testing.T) {
+	changes := make(chan bool, 10)
+	h := newTestHarness(t, Config{ExclusiveWriter: true, OnWriterChange: func(held bool) { changes <- held }})
+
+	expect := func(want bool) {
+		t.Helper()
+		select {
+		case got := <-changes:
+			if got != want {
+				t.Fatalf("Expected writer held = %v, got %v", want, got)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Timeout waiting for writer held = %v", want)
+		}
+	}
+
+	writer, _ := h.connect("")
+	expect(true)
+	reader, _ := h.connect("")
+	reader.Close()
+	writer.Close()
+	expect(false)
+
+	next, role := h.connect("")
+	if role != protocol.SessionRoleWriter {
+		t.Fatalf("Expected the next connection to claim the writer session, got %q", role)
+	}
+	expect(true)
+	next.Close()
+	expect(false)
+
+	select {
+	case held := <-changes:
+		t.Errorf("Unexpected writer change %v", held)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// tagDataMessage is a tagData broadcast as received by a client.
+type tagDataMessage struct {
+	Type    string         `json:"type"`
+	Payload map[string]any `json:"payload"`
+}
+
+// TestServer_ReplayPolicy tests what late joiners receive for each replay policy.
+func TestServer_ReplayPolicy(t *testing.T) {
+	h := newTestHarness(t, Config{})
+
+	first, _ := h.connect("")
+	h.bridge.SendTagData(nfc.NFCData{Card: nfc.NewCard(h.tag)})
+
+	var live tagDataMessage
+	h.readJSON(first, &live)
+	if live.Type != server.WSMessageTypeTagData || live.Payload["text"] != "Hello" {
+		t.Fatalf("Expected live tagData with text Hello, got %+v", live)
+	}
+
+	full, _ := h.connect("replay=full")
+	var replayed tagDataMessage
+	h.readJSON(full, &replayed)
+	if replayed.Payload["uid"] != "04A1B2C3" || replayed.Payload["text"] != "Hello" || replayed.Payload["message"] == nil {
+		t.Errorf("Expected full replay with text and records, got %+v", replayed.Payload)
+	}
+
+	uidOnly, _ := h.connect("replay=uid")
+	replayed = tagDataMessage{}
+	h.readJSON(uidOnly, &replayed)
+	if replayed.Payload["uid"] != "04A1B2C3" || replayed.Payload["text"] != "" || replayed.Payload["message"] != nil {
+		t.Errorf("Expected uid-only replay, got %+v", replayed.Payload)
+	}
+
+	none, _ := h.connect("replay=none")
+	resp := h.request(none, protocol.WebSocketRequest{ID: "v", Type: server.WSMessageTypeGetVersion})
+	if resp.Type != server.WSMessageTypeGetVersionResponse {
+		t.Fatalf("Expected no replay before getVersionResponse, got %q", resp.Type)
+	}
+
+	resp = h.request(none, protocol.WebSocketRequest{
+		ID:      "sub",
+		Type:    server.WSMessageTypeSubscribe,
+		Payload: map[string]any{"replay": "full"},
+	})
+	if !resp.Success || resp.Type != server.WSMessageTypeSubscribeResponse {
+		t.Fatalf("Expected successful subscribeResponse, got %+v", resp)
+	}
+	replayed = tagDataMessage{}
+	h.readJSON(none, &replayed)
+	if replayed.Payload["text"] != "Hello" {
+		t.Errorf("Expected full replay after subscribe, got %+v", replayed.Payload)
+	}
+
+	if _, httpResp, err := h.dial("replay=bogus"); err == nil || httpResp == nil || httpResp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected 400 for unknown replay policy, got %v", httpResp)
+	}
+}
+
+// TestServer_StatusDelta tests that delta clients get the full status once at
+// connect and then only changed fields, while full clients are unaffected.
+func TestServer_StatusDelta(t *testing.T) {
+	h := newTestHarness(t, Config{})
+
+	full, _ := h.connect("")
+	status := nfc.DeviceStatus{Connected: true, Message: "Device connected"}
+	h.bridge.SendDeviceStatus(status)
+
+	var msg tagDataMessage
+	h.readJSON(full, &msg)
+	if msg.Type != server.WSMessageTypeDeviceStatus {
+		t.Fatalf("Expected %q, got %q", server.WSMessageTypeDeviceStatus, msg.Type)
+	}
+
+	delta, _ := h.connect("status=delta")
+	msg = tagDataMessage{}
+	h.readJSON(delta, &msg)
+	if msg.Type != server.WSMessageTypeDeviceStatus || msg.Payload["Message"] != "Device connected" {
+		t.Fatalf("Expected full status at connect, got %+v", msg)
+	}
+
+	// Only CardPresent changes
+	status.CardPresent = true
+	h.bridge.SendDeviceStatus(status)
+
+	msg = tagDataMessage{}
+	h.readJSON(full, &msg)
+	if msg.Type != server.WSMessageTypeDeviceStatus || len(msg.Payload) < 3 {
+		t.Errorf("Expected full status for default client, got %+v", msg)
+	}
+
+	msg = tagDataMessage{}
+	h.readJSON(delta, &msg)
+	if msg.Type != server.WSMessageTypeDeviceStatusPatch {
+		t.Fatalf("Expected %q, got %q", server.WSMessageTypeDeviceStatusPatch, msg.Type)
+	}
+	if len(msg.Payload) != 1 || msg.Payload["CardPresent"] != true {
+		t.Errorf("Expected patch with only CardPresent, got %+v", msg.Payload)
+	}
+
+	// An unchanged status sends nothing, so the next message is the following change
+	h.bridge.SendDeviceStatus(status)
+	status.Message = "Card detected"
+	h.bridge.SendDeviceStatus(status)
+
+	msg = tagDataMessage{}
+	h.readJSON(delta, &msg)
+	if len(msg.Payload) != 1 || msg.Payload["Message"] != "Card detected" {
+		t.Errorf("Expected patch with only Message, got %+v", msg.Payload)
+	}
+
+	if _, httpResp, err := h.dial("status=bogus"); err == nil || httpResp == nil || httpResp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected 400 for unknown status mode, got %v", httpResp)
+	}
+}
+
+// TestServer_Events tests the event log endpoint, including API secret gating
+// and the since filter.
+func TestServer_Events(t *testing.T) {
+	events := server.NewEventLog(10)
+	h := newTestHarness(t, Config{APISecret: "s3cret", Events: events})
+
+	old := time.Now().Add(-time.Minute)
+	events.Add(server.Event{Time: old, Level: server.EventLevelInfo, Category: "agent", Message: "old"})
+	events.Add(server.Event{Time: time.Now(), Level: server.EventLevelError, Category: "device", Message: "new"})
+
+	get := func(query string) (*http.Response, []server.Event) {
+		t.Helper()
+		resp, err := http.Get(h.httpServer.URL + "/api/v1/events?" + query)
+		if err != nil {
+			t.Fatalf("GET /api/v1/events failed: %v", err)
+		}
+		defer resp.Body.Close()
+
+		var body struct {
+			Events []server.Event `json:"events"`
+		}
+		if resp.StatusCode == http.StatusOK {
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatalf("Failed to decode events: %v", err)
+			}
+		}
+		return resp, body.Events
+	}
+
+	if resp, _ := get("secret=wrong"); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without the secret, got %d", resp.StatusCode)
+	}
+
+	if _, all := get("secret=s3cret"); len(all) != 2 {
+		t.Errorf("Expected 2 events, got %+v", all)
+	}
+
+	since := old.Add(time.Second).Format(time.RFC3339Nano)
+	if _, recent := get("secret=s3cret&since=" + url.QueryEscape(since)); len(recent) != 1 || recent[0].Message != "new" {
+		t.Errorf("Expected only the new event, got %+v", recent)
+	}
+
+	if resp, _ := get("secret=s3cret&since=yesterday"); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected 400 for invalid since, got %d", resp.StatusCode)
+	}
+}
+
+// TestServer_TagDataReaderID tests that tag data from a lane reader carries
+// its reader ID, and that single-reader tag data does not.
+func TestServer_TagDataReaderID(t *testing.T) {
+	h := newTestHarness(t, Config{})
+	conn, _ := h.connect("")
+
+	h.bridge.SendTagData(nfc.NFCData{Card: nfc.NewCard(h.tag), ReaderID: "lane2"})
+	var msg tagDataMessage
+	h.readJSON(conn, &msg)
+	if msg.Payload["readerId"] != "lane2" {
+		t.Errorf("Expected readerId lane2, got %v", msg.Payload["readerId"])
+	}
+
+	h.bridge.SendTagData(nfc.NFCData{Card: nfc.NewCard(h.tag)})
+	msg = tagDataMessage{}
+	h.readJSON(conn, &msg)
+	if _, ok := msg.Payload["readerId"]; ok {
+		t.Errorf("Expected no readerId without a MultiReader, got %v", msg.Payload["readerId"])
+	}
+}
+
+// TestServer_Compress tests that only clients that subscribed with compress
+// receive large tagData messages gzipped, as binary frames.
+func TestServer_Compress(t *testing.T) {
+	h := newTestHarness(t, Config{CompressThreshold: 16})
+
+	plain, _ := h.connect("replay=none")
+	compressed, _ := h.connect("replay=none")
+	resp := h.request(compressed, protocol.WebSocketRequest{
+		ID:      "sub",
+		Type:    server.WSMessageTypeSubscribe,
+		Payload: map[string]any{"replay": "none", "compress": true},
+	})
+	if !resp.Success || resp.Payload.(map[string]any)["compress"] != true {
+		t.Fatalf("Expected compress to be enabled, got %+v", resp)
+	}
+
+	h.bridge.SendTagData(nfc.NFCData{Card: nfc.NewCard(h.tag)})
+
+	var msg tagDataMessage
+	h.readJSON(plain, &msg)
+	if msg.Payload["text"] != "Hello" {
+		t.Errorf("Expected plain tagData, got %+v", msg)
+	}
+
+	compressed.SetReadDeadline(time.Now().Add(time.Second))
+	frameType, data, err := compressed.ReadMessage()
+	if err != nil {
+		t.Fatalf("Failed to read message: %v", err)
+	}
+	if frameType != websocket.BinaryMessage {
+		t.Fatalf("Expected a binary frame, got type %d", frameType)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Expected gzip data: %v", err)
+	}
+	msg = tagDataMessage{}
+	if err := json.NewDecoder(zr).Decode(&msg); err != nil {
+		t.Fatalf("Failed to decode gzipped message: %v", err)
+	}
+	if msg.Type != server.WSMessageTypeTagData || msg.Payload["text"] != "Hello" {
+		t.Errorf("Expected gzipped tagData, got %+v", msg)
+	}
+
+	// Compression cannot be enabled while the server has it off
+	off := newTestHarness(t, Config{})
+	conn, _ := off.connect("replay=none")
+	resp = off.request(conn, protocol.WebSocketRequest{
+		ID:      "sub",
+		Type:    server.WSMessageTypeSubscribe,
+		Payload: map[string]any{"replay": "none", "compress": true},
+	})
+	if !resp.Success || resp.Payload.(map[string]any)["compress"] != false {
+		t.Errorf("Expected compress to stay off, got %+v", resp)
+	}
+}
+
+// TestServer_CardHeartbeat tests that subscribed clients get heartbeats while
+// a card is present and none once it is removed, and that others get none.
+func TestServer_CardHeartbeat(t *testing.T) {
+	h := newTestHarness(t, Config{})
+
+	plain, _ := h.connect("replay=none")
+	subscribed, _ := h.connect("replay=none")
+
+	resp := h.request(subscribed, protocol.WebSocketRequest{
+		ID:      "sub",
+		Type:    server.WSMessageTypeSubscribe,
+		Payload: map[string]any{"replay": "none", "heartbeatMs": 50},
+	})
+	if resp.Success || resp.Payload.(map[string]any)["code"] != "INVALID_REQUEST" {
+		t.Fatalf("Expected INVALID_REQUEST below the minimum interval, got %+v", resp)
+	}
+	resp = h.request(subscribed, protocol.WebSocketRequest{
+		ID:      "sub",
+		Type:    server.WSMessageTypeSubscribe,
+		Payload: map[string]any{"replay": "none", "heartbeatMs": 100},
+	})
+	if !resp.Success || resp.Payload.(map[string]any)["heartbeatMs"] != float64(100) {
+		t.Fatalf("Expected heartbeats to be enabled, got %+v", resp)
+	}
+
+	h.bridge.SendTagData(nfc.NFCData{Card: nfc.NewCard(h.tag)})
+	var msg tagDataMessage
+	h.readJSON(plain, &msg)
+	if msg.Type != server.WSMessageTypeTagData {
+		t.Fatalf("Expected tagData, got %q", msg.Type)
+	}
+	msg = tagDataMessage{}
+	h.readJSON(subscribed, &msg)
+	if msg.Type != server.WSMessageTypeTagData {
+		t.Fatalf("Expected tagData, got %q", msg.Type)
+	}
+
+	var lastDwell float64 = -1
+	for range 2 {
+		msg = tagDataMessage{}
+		h.readJSON(subscribed, &msg)
+		if msg.Type != server.WSMessageTypeCardHeartbeat || msg.Payload["uid"] != "04A1B2C3" {
+			t.Fatalf("Expected cardHeartbeat for 04A1B2C3, got %+v", msg)
+		}
+		dwell, _ := msg.Payload["dwellMs"].(float64)
+		if dwell <= lastDwell {
+			t.Errorf("Expected dwellMs to grow, got %v after %v", dwell, lastDwell)
+		}
+		lastDwell = dwell
+	}
+
+	// After the removal status no more heartbeats arrive
+	h.bridge.SendDeviceStatus(nfc.DeviceStatus{Connected: true, Message: "Card removed"})
+	for msg.Type != server.WSMessageTypeDeviceStatus {
+		msg = tagDataMessage{}
+		h.readJSON(subscribed, &msg)
+	}
+	subscribed.SetReadDeadline(time.Now().Add(300 * time.Millisecond))
+	if err := subscribed.ReadJSON(&msg); err == nil {
+		t.Errorf("Expected no message after removal, got %+v", msg)
+	}
+
+	// The unsubscribed client only saw the status
+	msg = tagDataMessage{}
+	h.readJSON(plain, &msg)
+	if msg.Type != server.WSMessageTypeDeviceStatus {
+		t.Errorf("Expected deviceStatus without heartbeats, got %q", msg.Type)
+	}
+}
+
+// TestServer_CompareToReference tests that the reference captured by the
+// writer is sent along with its comparisons and dropped on disconnect.
+func TestServer_CompareToReference(t *testing.T) {
+	h := newTestHarness(t, Config{ExclusiveWriter: true})
+
+	var mu sync.Mutex
+	var compared map[string]any
+	h.respondToCommands(func(msg server.CommandMessage) server.CommandResponseMessage {
+		resp := server.CommandResponseMessage{RequestID: msg.RequestID, Success: true}
+		switch msg.Type {
+		case server.WSMessageTypeCaptureReference:
+			resp.Payload = protocol.ReadCardPayload{UID: "04A1B2C3", Data: "D101055402656E4869"}
+		case server.WSMessageTypeCompareToReference:
+			mu.Lock()
+			compared = msg.Payload
+			mu.Unlock()
+			resp.Payload = protocol.CompareReferencePayload{Match: true}
+		}
+		return resp
+	})
+
+	writer, _ := h.connect("replay=none")
+	reader, _ := h.connect("replay=none")
+
+	compare := protocol.WebSocketRequest{ID: "cmp", Type: server.WSMessageTypeCompareToReference, Payload: map[string]any{"mode": "records"}}
+	if resp := h.request(writer, compare); resp.Success || resp.Payload.(map[string]any)["code"] != "NO_REFERENCE" {
+		t.Fatalf("Expected NO_REFERENCE before capture, got %+v", resp)
+	}
+
+	capture := protocol.WebSocketRequest{ID: "cap", Type: server.WSMessageTypeCaptureReference}
+	if resp := h.request(reader, capture); resp.Payload.(map[string]any)["code"] != "READ_ONLY_SESSION" {
+		t.Fatalf("Expected READ_ONLY_SESSION for a reader, got %+v", resp)
+	}
+	if resp := h.request(writer, capture); !resp.Success || resp.Type != server.WSMessageTypeCaptureReferenceResponse {
+		t.Fatalf("Expected captureReferenceResponse, got %+v", resp)
+	}
+
+	resp := h.request(writer, compare)
+	if !resp.Success || resp.Type != server.WSMessageTypeCompareToReferenceResponse {
+		t.Fatalf("Expected compareToReferenceResponse, got %+v", resp)
+	}
+	mu.Lock()
+	got := compared
+	mu.Unlock()
+	if got["reference"] != "D101055402656E4869" || got["referenceUid"] != "04A1B2C3" || got["mode"] != "records" {
+		t.Errorf("Device side got payload %+v", got)
+	}
+
+	// The next writer session starts without a reference
+	writer.Close()
+	reader.Close()
+	deadline := time.Now().Add(time.Second)
+	for h.server.clientCount() > 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	next, role := h.connect("replay=none")
+	if role != protocol.SessionRoleWriter {
+		t.Fatalf("Expected the next connection to be the writer, got %s", role)
+	}
+	if resp := h.request(next, compare); resp.Payload.(map[string]any)["code"] != "NO_REFERENCE" {
+		t.Errorf("Expected NO_REFERENCE in a new session, got %+v", resp)
+	}
+}
+
+// TestServer_CaptureReferenceAfterDisconnect tests that a capture finishing
+// after its client disconnected does not leave a reference behind.
+func TestServer_CaptureReferenceAfterDisconnect(t *testing.T) {
+	h := newTestHarness(t, Config{})
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	h.respondToCommands(func(msg server.CommandMessage) server.CommandResponseMessage {
+		close(started)
+		<-release
+		return server.CommandResponseMessage{
+			RequestID: msg.RequestID,
+			Success:   true,
+			Payload:   protocol.ReadCardPayload{UID: "04A1B2C3", Data: "D101055402656E4869"},
+		}
+	})
+
+	writer, _ := h.connect("replay=none")
+	if err := writer.WriteJSON(protocol.WebSocketRequest{ID: "cap", Type: server.WSMessageTypeCaptureReference}); err != nil {
+		t.Fatalf("Failed to send captureReference: %v", err)
+	}
+	<-started
+	writer.Close()
+
+	deadline := time.Now().Add(time.Second)
+	for h.server.clientCount() > 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	close(release)
+
+	// The held writer session is released once the capture has finished
+	for time.Now().Before(deadline.Add(time.Second)) {
+		h.server.clientsMux.RLock()
+		done := h.server.writerConn == nil
+		h.server.clientsMux.RUnlock()
+		if done {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	h.server.clientsMux.RLock()
+	defer h.server.clientsMux.RUnlock()
+	if h.server.writerConn != nil {
+		t.Fatal("Expected the writer session to be released")
+	}
+	if len(h.server.references) != 0 {
+		t.Errorf("Expected no references after disconnect, got %d", len(h.server.references))
+	}
+}
+
+// TestServer_SignedBroadcasts tests that broadcasts carry a signature over
+// their payload as sent when a signing key is configured, and none otherwise.
+func TestServer_SignedBroadcasts(t *testing.T) {
+	key := []byte("shared-secret")
+	h := newTestHarness(t, Config{SigningKey: key})
+	conn, _ := h.connect("replay=none")
+
+	type signedMessage struct {
+		Type    string          `json:"type"`
+		Payload json.RawMessage `json:"payload"`
+		Sig     string          `json:"sig"`
+	}
+
+	broadcasts := []func(){
+		func() { h.bridge.SendTagData(nfc.NFCData{Card: nfc.NewCard(h.tag)}) },
+		func() {
+			h.bridge.SendDeviceStatus(nfc.DeviceStatus{Connected: true, Message: "Card <detected> & read", CardPresent: true})
+		},
+	}
+	for i, want := range []string{server.WSMessageTypeTagData, server.WSMessageTypeDeviceStatus} {
+		broadcasts[i]()
+		var msg signedMessage
+		h.readJSON(conn, &msg)
+		if msg.Type != want {
+			t.Fatalf("Expected %s, got %s", want, msg.Type)
+		}
+		if !protocol.VerifyPayload(key, msg.Payload, msg.Sig) {
+			t.Errorf("%s: signature %q does not verify over %s", msg.Type, msg.Sig, msg.Payload)
+		}
+		if protocol.VerifyPayload([]byte("other"), msg.Payload, msg.Sig) {
+			t.Errorf("%s: signature verifies under another key", msg.Type)
+		}
+	}
+
+	plain := newTestHarness(t, Config{})
+	conn, _ = plain.connect("replay=none")
+	plain.bridge.SendTagData(nfc.NFCData{Card: nfc.NewCard(plain.tag)})
+	var msg signedMessage
+	plain.readJSON(conn, &msg)
+	if msg.Sig != "" {
+		t.Errorf("Expected no sig without a signing key, got %q", msg.Sig)
+	}
+}
+
+// TestServer_UnsupportedCard tests that an unsupported card is sent as an
+// unsupportedCard message and not stored as the last card.
+func TestServer_UnsupportedCard(t *testing.T) {
+	h := newTestHarness(t, Config{})
+	conn, _ := h.connect("")
+
+	h.bridge.SendTagData(nfc.NFCData{Unsupported: &nfc.UnsupportedCard{UID: "04A1B2C3", ATR: "3B00"}})
+
+	var msg tagDataMessage
+	h.readJSON(conn, &msg)
+	if msg.Type != server.WSMessageTypeUnsupportedCard {
+		t.Fatalf("Expected %s, got %s", server.WSMessageTypeUnsupportedCard, msg.Type)
+	}
+	if msg.Payload["uid"] != "04A1B2C3" || msg.Payload["atr"] != "3B00" {
+		t.Errorf("Expected uid 04A1B2C3 and atr 3B00, got %v", msg.Payload)
+	}
+	if h.server.GetLastCard() != nil {
+		t.Error("Unsupported card should not be stored as the last card")
+	}
+}
+
+// TestServer_TagDataRecoveredNDEF tests that raw card data holding an NDEF
+// message is sent as structured records.
+func TestServer_TagDataRecoveredNDEF(t *testing.T) {
+	h := newTestHarness(t, Config{})
+	conn, _ := h.connect("")
+
+	ndef := nfc.EncodeNdefMessageWithTextRecord("Hello", "en")
+	card := nfc.NewCard(h.tag)
+	card.MessageData = nfc.NewTextMessage(nfc.TLVEncode(ndef, nfc.TLVNDEF))
+	h.bridge.SendTagData(nfc.NFCData{Card: card})
+
+	var msg tagDataMessage
+	h.readJSON(conn, &msg)
+	message, _ := msg.Payload["message"].(map[string]interface{})
+	if message["type"] != "ndef" || message["recovered"] != true {
+		t.Errorf("Expected a recovered NDEF message, got %v", message)
+	}
+	if msg.Payload["text"] != "Hello" {
+		t.Errorf("Expected text Hello, got %v", msg.Payload["text"])
+	}
+
+	card = nfc.NewCard(h.tag)
+	card.MessageData = nfc.NewTextMessage([]byte("plain"))
+	h.bridge.SendTagData(nfc.NFCData{Card: card})
+	msg = tagDataMessage{}
+	h.readJSON(conn, &msg)
+	message, _ = msg.Payload["message"].(map[string]interface{})
+	if message["type"] != "raw" || msg.Payload["text"] != "plain" {
+		t.Errorf("Expected a raw message with text plain, got %v", msg.Payload)
+	}
+}
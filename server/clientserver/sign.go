@@ -0,0 +1,26 @@
+package clientserver
+
+import (
+	"encoding/json"
+	"log"
+
+	"github.com/dotside-studios/davi-nfc-agent/protocol"
+)
+
+// signMessage signs a broadcast message with the configured signing key. The
+// payload is replaced by its JSON encoding, which the message encodes
+// unchanged, so the signed bytes are exactly the ones clients receive.
+// Without a key the message is returned as is.
+func (s *Server) signMessage(msg protocol.WebSocketMessage) protocol.WebSocketMessage {
+	if len(s.config.SigningKey) == 0 {
+		return msg
+	}
+	payload, err := json.Marshal(msg.Payload)
+	if err != nil {
+		log.Printf("[client] Failed to sign %s: %v", msg.Type, err)
+		return msg
+	}
+	msg.Payload = json.RawMessage(payload)
+	msg.Sig = protocol.SignPayload(s.config.SigningKey, payload)
+	return msg
+}
@@ -0,0 +1,70 @@
+package clientserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// StatusMode selects how a client receives device status updates.
+type StatusMode string
+
+const (
+	// StatusFull sends the whole status with every deviceStatus message (default).
+	StatusFull StatusMode = "full"
+	// StatusDelta sends the whole status once at connect, then only the
+	// changed fields as deviceStatusPatch messages.
+	StatusDelta StatusMode = "delta"
+)
+
+// ParseStatusMode parses "full" or "delta"; empty selects StatusFull.
+func ParseStatusMode(s string) (StatusMode, error) {
+	switch m := StatusMode(s); m {
+	case "":
+		return StatusFull, nil
+	case StatusFull, StatusDelta:
+		return m, nil
+	default:
+		return StatusFull, fmt.Errorf("unknown status mode %q (expected full or delta)", s)
+	}
+}
+
+// statusPatch returns the fields of next that differ from prev, keyed as they
+// appear on the wire. It follows JSON Merge Patch (RFC 7396): a field present
+// in prev but omitted from next is set to nil. An empty patch means no change.
+func statusPatch(prev, next any) (map[string]any, error) {
+	prevFields, err := jsonFields(prev)
+	if err != nil {
+		return nil, err
+	}
+	nextFields, err := jsonFields(next)
+	if err != nil {
+		return nil, err
+	}
+
+	patch := make(map[string]any)
+	for k, v := range nextFields {
+		if old, ok := prevFields[k]; !ok || !reflect.DeepEqual(old, v) {
+			patch[k] = v
+		}
+	}
+	for k := range prevFields {
+		if _, ok := nextFields[k]; !ok {
+			patch[k] = nil
+		}
+	}
+	return patch, nil
+}
+
+// jsonFields returns v as its JSON object fields.
+func jsonFields(v any) (map[string]any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
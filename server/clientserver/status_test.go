@@ -0,0 +1,63 @@
+package clientserver
+
+import (
+	"testing"
+
+	"github.com/dotside-studios/davi-nfc-agent/nfc"
+	"github.com/dotside-studios/davi-nfc-agent/protocol"
+)
+
+func TestStatusPatch(t *testing.T) {
+	prev := protocol.DeviceStatusPayload{Connected: true, Message: "Reader recovered", Event: "reconnected", Reason: "timeout"}
+	next := protocol.DeviceStatusPayload{Connected: true, Message: "Reader recovered", CardPresent: true}
+
+	patch, err := statusPatch(prev, next)
+	if err != nil {
+		t.Fatalf("statusPatch() error = %v", err)
+	}
+
+	want := map[string]any{"cardPresent": true, "event": nil, "reason": nil}
+	if len(patch) != len(want) {
+		t.Fatalf("statusPatch() = %v, want %v", patch, want)
+	}
+	for k, v := range want {
+		if got, ok := patch[k]; !ok || got != v {
+			t.Errorf("patch[%q] = %v, want %v", k, got, v)
+		}
+	}
+
+	if patch, _ := statusPatch(next, next); len(patch) != 0 {
+		t.Errorf("Expected empty patch for unchanged status, got %v", patch)
+	}
+}
+
+// TestStatusPatch_DeviceStatus tests that the reader's own status type is
+// diffed under the documented event and reason keys.
+func TestStatusPatch_DeviceStatus(t *testing.T) {
+	prev := nfc.DeviceStatus{Connected: true, Message: "Reader recovered", Event: nfc.DeviceStatusEventReconnected, Reason: "timeout"}
+	next := nfc.DeviceStatus{Connected: true, Message: "Reader recovered"}
+
+	patch, err := statusPatch(prev, next)
+	if err != nil {
+		t.Fatalf("statusPatch() error = %v", err)
+	}
+	for _, k := range []string{"event", "reason"} {
+		if got, ok := patch[k]; !ok || got != nil {
+			t.Errorf("patch[%q] = %v, want nil", k, got)
+		}
+	}
+	if len(patch) != 2 {
+		t.Errorf("statusPatch() = %v, want only event and reason", patch)
+	}
+}
+
+func TestParseStatusMode(t *testing.T) {
+	for in, want := range map[string]StatusMode{"": StatusFull, "full": StatusFull, "delta": StatusDelta} {
+		if got, err := ParseStatusMode(in); err != nil || got != want {
+			t.Errorf("ParseStatusMode(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	if _, err := ParseStatusMode("diff"); err == nil {
+		t.Error("Expected error for unknown status mode")
+	}
+}
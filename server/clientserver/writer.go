@@ -0,0 +1,90 @@
+package clientserver
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// WriterDisconnectPolicy selects what happens to the writer session when the
+// writer disconnects while one of its operations is still running.
+type WriterDisconnectPolicy string
+
+const (
+	// WriterHold keeps the session until the running operation finishes, so
+	// no other client can start a conflicting write meanwhile (default).
+	WriterHold WriterDisconnectPolicy = "hold"
+	// WriterRelease frees the session at once; the running operation still
+	// completes, but its response is lost.
+	WriterRelease WriterDisconnectPolicy = "release"
+)
+
+// ParseWriterDisconnectPolicy parses "hold" or "release"; empty selects WriterHold.
+func ParseWriterDisconnectPolicy(s string) (WriterDisconnectPolicy, error) {
+	switch p := WriterDisconnectPolicy(s); p {
+	case "":
+		return WriterHold, nil
+	case WriterHold, WriterRelease:
+		return p, nil
+	default:
+		return WriterHold, fmt.Errorf("unknown writer disconnect policy %q (expected hold or release)", s)
+	}
+}
+
+// writerQueueSize is the number of writer operations a connection can queue
+// before its read loop waits.
+const writerQueueSize = 16
+
+// writerQueue runs a writer connection's operations one at a time, in the
+// order they were received, off the connection's read loop. This keeps the
+// read loop free to notice a disconnect while an operation is in flight.
+// Operations reply through Server.writeJSON, as the read loop and the
+// broadcasts write to the same connection meanwhile.
+type writerQueue struct {
+	ops     chan func()
+	done    chan struct{}
+	closed  atomic.Bool
+	running atomic.Bool
+}
+
+func newWriterQueue() *writerQueue {
+	q := &writerQueue{
+		ops:  make(chan func(), writerQueueSize),
+		done: make(chan struct{}),
+	}
+	go q.run()
+	return q
+}
+
+func (q *writerQueue) run() {
+	defer close(q.done)
+	for op := range q.ops {
+		// Nobody is left to answer once the connection is gone
+		if q.closed.Load() {
+			continue
+		}
+		q.running.Store(true)
+		op()
+		q.running.Store(false)
+	}
+}
+
+// enqueue schedules op after the operations already queued.
+func (q *writerQueue) enqueue(op func()) {
+	q.ops <- op
+}
+
+// close stops the queue. The running operation completes; queued ones are skipped.
+func (q *writerQueue) close() {
+	q.closed.Store(true)
+	close(q.ops)
+}
+
+// busy reports whether an operation is running.
+func (q *writerQueue) busy() bool {
+	return q.running.Load()
+}
+
+// wait blocks until the running operation, if any, completes after close.
+func (q *writerQueue) wait() {
+	<-q.done
+}
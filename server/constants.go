@@ -22,6 +22,119 @@ const (
 	WSMessageTypeWriteRequest  = "writeRequest"
 	WSMessageTypeWriteResponse = "writeResponse"
 	WSMessageTypeError         = "error"
+	WSMessageTypeReady         = "ready"
+
+	WSMessageTypeFormatNDEF         = "formatNdef"
+	WSMessageTypeFormatNDEFResponse = "formatNdefResponse"
+
+	WSMessageTypeReadManufacturerBlock         = "readManufacturerBlock"
+	WSMessageTypeReadManufacturerBlockResponse = "readManufacturerBlockResponse"
+
+	WSMessageTypeGetVersion         = "getVersion"
+	WSMessageTypeGetVersionResponse = "getVersionResponse"
+
+	WSMessageTypeWaitForCard         = "waitForCard"
+	WSMessageTypeWaitForCardResponse = "waitForCardResponse"
+
+	WSMessageTypeReadRange         = "readRange"
+	WSMessageTypeReadRangeResponse = "readRangeResponse"
+
+	WSMessageTypeSubscribe         = "subscribe"
+	WSMessageTypeSubscribeResponse = "subscribeResponse"
+
+	WSMessageTypeGetWearStats         = "getWearStats"
+	WSMessageTypeGetWearStatsResponse = "getWearStatsResponse"
+
+	WSMessageTypeClearCache         = "clearCache"
+	WSMessageTypeClearCacheResponse = "clearCacheResponse"
+
+	WSMessageTypeGetCardInfo         = "getCardInfo"
+	WSMessageTypeGetCardInfoResponse = "getCardInfoResponse"
+
+	WSMessageTypeListTags         = "listTags"
+	WSMessageTypeListTagsResponse = "listTagsResponse"
+
+	WSMessageTypeReadCard         = "readCard"
+	WSMessageTypeReadCardResponse = "readCardResponse"
+
+	WSMessageTypeWriteRaw         = "writeRaw"
+	WSMessageTypeWriteRawResponse = "writeRawResponse"
+
+	WSMessageTypeResetDevice         = "resetDevice"
+	WSMessageTypeResetDeviceResponse = "resetDeviceResponse"
+
+	WSMessageTypeGetLatencyStats         = "getLatencyStats"
+	WSMessageTypeGetLatencyStatsResponse = "getLatencyStatsResponse"
+
+	WSMessageTypeGetAllowedTypes         = "getAllowedTypes"
+	WSMessageTypeGetAllowedTypesResponse = "getAllowedTypesResponse"
+	WSMessageTypeSetAllowedTypes         = "setAllowedTypes"
+	WSMessageTypeSetAllowedTypesResponse = "setAllowedTypesResponse"
+
+	WSMessageTypeNormalize         = "normalize"
+	WSMessageTypeNormalizeResponse = "normalizeResponse"
+
+	WSMessageTypePause          = "pause"
+	WSMessageTypePauseResponse  = "pauseResponse"
+	WSMessageTypeResume         = "resume"
+	WSMessageTypeResumeResponse = "resumeResponse"
+
+	WSMessageTypeWriteLocalizedText         = "writeLocalizedText"
+	WSMessageTypeWriteLocalizedTextResponse = "writeLocalizedTextResponse"
+
+	WSMessageTypeCheckWrite         = "checkWrite"
+	WSMessageTypeCheckWriteResponse = "checkWriteResponse"
+
+	WSMessageTypeGetATR         = "getATR"
+	WSMessageTypeGetATRResponse = "getATRResponse"
+
+	WSMessageTypeWriteAndRead         = "writeAndRead"
+	WSMessageTypeWriteAndReadResponse = "writeAndReadResponse"
+
+	WSMessageTypeCaptureReference           = "captureReference"
+	WSMessageTypeCaptureReferenceResponse   = "captureReferenceResponse"
+	WSMessageTypeCompareToReference         = "compareToReference"
+	WSMessageTypeCompareToReferenceResponse = "compareToReferenceResponse"
+
+	WSMessageTypeQuickWritable         = "quickWritable"
+	WSMessageTypeQuickWritableResponse = "quickWritableResponse"
+
+	WSMessageTypeRescan         = "rescan"
+	WSMessageTypeRescanResponse = "rescanResponse"
+
+	WSMessageTypeGetCapabilities         = "getCapabilities"
+	WSMessageTypeGetCapabilitiesResponse = "getCapabilitiesResponse"
+
+	// Sent instead of deviceStatus to clients connected with ?status=delta
+	WSMessageTypeDeviceStatusPatch = "deviceStatusPatch"
+
+	// Sent periodically while a card is present to clients that subscribed with heartbeatMs
+	WSMessageTypeCardHeartbeat = "cardHeartbeat"
+
+	// Broadcast when a card the reader cannot read is tapped, with -unsupported-tags=event
+	WSMessageTypeUnsupportedCard = "unsupportedCard"
+
+	// Debug commands, only accepted when enabled in the client server config
+	WSMessageTypeReadPages          = "readPages"
+	WSMessageTypeReadPagesResponse  = "readPagesResponse"
+	WSMessageTypeWritePage          = "writePage"
+	WSMessageTypeWritePageResponse  = "writePageResponse"
+	WSMessageTypeReadMAD            = "readMAD"
+	WSMessageTypeReadMADResponse    = "readMADResponse"
+	WSMessageTypeReadRecord         = "readRecord"
+	WSMessageTypeReadRecordResponse = "readRecordResponse"
+)
+
+// Wait-for-card limits (milliseconds)
+const (
+	DefaultWaitForCardTimeoutMs = 30000
+	MaxWaitForCardTimeoutMs     = 300000
+)
+
+// Partial NDEF read limits (bytes)
+const (
+	DefaultReadRangeLength = 256
+	MaxReadRangeLength     = 4096
 )
 
 // CORS configuration
@@ -1,15 +1,26 @@
 package deviceserver
 
 import (
+	"time"
+
 	"github.com/dotside-studios/davi-nfc-agent/nfc"
 	"github.com/dotside-studios/davi-nfc-agent/nfc/remotenfc"
+	"github.com/dotside-studios/davi-nfc-agent/server"
 )
 
+// DefaultDeviceWriteTimeout is how long a write sent to a device waits for the
+// device's response when Config.DeviceWriteTimeout is not set.
+const DefaultDeviceWriteTimeout = 15 * time.Second
+
 // Config holds configuration for the Device Server.
 type Config struct {
 	// Reader is the NFC reader instance (hardware NFC)
 	Reader *nfc.NFCReader
 
+	// Readers are additional lane readers whose tag data and status carry
+	// their reader ID; writes reach them through WriteRequest.ReaderID (optional)
+	Readers *nfc.MultiReader
+
 	// DeviceManager manages external devices (phones, tablets, etc.)
 	DeviceManager *remotenfc.Manager
 
@@ -19,9 +30,46 @@ type Config struct {
 	// APISecret is the optional API secret for authentication
 	APISecret string
 
-	// AllowedCardTypes limits which card types are accepted
+	// AllowedCardTypes limits which card types the reader reads (empty = all)
 	AllowedCardTypes map[string]bool
 
+	// DeviceWriteTimeout limits how long a write routed to a device (phone)
+	// waits for its response (default DefaultDeviceWriteTimeout)
+	DeviceWriteTimeout time.Duration
+
+	// WriteRateLimit caps the writes each client can send per second;
+	// writeRequest, formatNdef and writePage all count (0 = unlimited)
+	WriteRateLimit float64
+
+	// WriteBurst is how many writes a client can send at once before
+	// WriteRateLimit applies (default: WriteRateLimit rounded up)
+	WriteBurst int
+
+	// RedactAPDUs masks the data bytes of APDU traces returned to clients,
+	// keeping command headers, lengths and status words
+	RedactAPDUs bool
+
+	// TimestampFormat controls how scannedAt is encoded in command responses
+	// (default server.TimestampRFC3339)
+	TimestampFormat server.TimestampFormat
+
+	// MDNSName is the advertised mDNS instance name
+	// (default server.DefaultMDNSInstanceName)
+	MDNSName string
+
+	// OnTagData, when set, is called with every card read by the readers,
+	// after it is forwarded to clients. It runs on the reader loop, so it
+	// must not block.
+	OnTagData func(data nfc.NFCData)
+
+	// OnAllowedCardTypesChange, when set, is called with the new allowlist
+	// after a client changes it with setAllowedTypes, so the owner can keep
+	// its own copy (AllowedCardTypes is not updated)
+	OnAllowedCardTypesChange func(types map[string]bool)
+
+	// AgentID is advertised in the "id" mDNS TXT record when set
+	AgentID string
+
 	// TLS configuration (optional)
 	CertFile string // Path to TLS certificate file
 	KeyFile  string // Path to TLS private key file
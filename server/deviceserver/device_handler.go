@@ -3,18 +3,37 @@ package deviceserver
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"sync"
+	"time"
 
+	"github.com/dotside-studios/davi-nfc-agent/buildinfo"
 	"github.com/dotside-studios/davi-nfc-agent/nfc"
 	"github.com/dotside-studios/davi-nfc-agent/nfc/remotenfc"
 	"github.com/dotside-studios/davi-nfc-agent/protocol"
 	"github.com/dotside-studios/davi-nfc-agent/server"
+	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 )
 
+// ErrDeviceWriteTimeout is returned when a device does not answer a write
+// request within the configured timeout.
+var ErrDeviceWriteTimeout = errors.New("device did not answer write request in time")
+
+// ErrDeviceDisconnected is returned when a device disconnects before answering
+// a write request.
+var ErrDeviceDisconnected = errors.New("device disconnected before answering write request")
+
+// pendingWrite is a write request waiting for its deviceWriteResponse.
+type pendingWrite struct {
+	deviceID string
+	respCh   chan protocol.DeviceWriteResponse // buffered, size 1
+	gone     chan struct{}                     // closed when the device disconnects
+}
+
 // DeviceHandler handles all device WebSocket connections and management.
 type DeviceHandler struct {
 	manager           *remotenfc.Manager
@@ -23,21 +42,38 @@ type DeviceHandler struct {
 	deviceSessionsMux sync.RWMutex
 	connToDeviceID    map[*websocket.Conn]string // reverse lookup: conn -> deviceID
 	upgrader          websocket.Upgrader
+	connWriteMux      sync.Mutex // serializes writes; gorilla allows one writer per conn
+
+	writeTimeout  time.Duration
+	pendingWrites map[string]pendingWrite // requestID -> waiting write
+	pendingMux    sync.Mutex
 }
 
-// NewDeviceHandler creates a new device handler.
-func NewDeviceHandler(manager *remotenfc.Manager, bridge *server.ServerBridge) *DeviceHandler {
-	return &DeviceHandler{
+// NewDeviceHandler creates a new device handler. Writes sent to a device fail
+// with ErrDeviceWriteTimeout if unanswered after writeTimeout; a non-positive
+// value uses DefaultDeviceWriteTimeout.
+func NewDeviceHandler(manager *remotenfc.Manager, bridge *server.ServerBridge, writeTimeout time.Duration) *DeviceHandler {
+	if writeTimeout <= 0 {
+		writeTimeout = DefaultDeviceWriteTimeout
+	}
+
+	h := &DeviceHandler{
 		manager:        manager,
 		bridge:         bridge,
 		deviceSessions: make(map[string]*websocket.Conn),
 		connToDeviceID: make(map[*websocket.Conn]string),
+		writeTimeout:   writeTimeout,
+		pendingWrites:  make(map[string]pendingWrite),
 		upgrader: websocket.Upgrader{
 			CheckOrigin: func(r *http.Request) bool {
 				return true // Allow all origins
 			},
 		},
 	}
+	if manager != nil {
+		manager.SetSender(h.SendToDevice)
+	}
+	return h
 }
 
 // Register registers the handler with the server.
@@ -148,8 +184,7 @@ func (h *DeviceHandler) HandleWebSocket(w http.ResponseWriter, r *http.Request)
 			case protocol.WSTypeDeviceHeartbeat:
 				handlerErr = h.handleDeviceHeartbeat(conn, deviceID, wsRequest)
 			case protocol.WSTypeDeviceWriteResponse:
-				log.Printf("[device] Write response received (not yet implemented)")
-				continue
+				handlerErr = h.handleDeviceWriteResponse(deviceID, wsRequest)
 			default:
 				log.Printf("[device] Unknown message type: %s", wsRequest.Type)
 				h.sendError(conn, wsRequest.ID, "UNKNOWN_TYPE", fmt.Sprintf("Unknown message type: %s", wsRequest.Type))
@@ -199,6 +234,10 @@ func (h *DeviceHandler) handleRegisterDevice(_ context.Context, conn *websocket.
 
 	// Register device
 	device, err := h.manager.RegisterDevice(phoneReq)
+	if errors.Is(err, remotenfc.ErrTooManyDevices) {
+		h.sendError(conn, req.ID, "TOO_MANY_DEVICES", err.Error())
+		return fmt.Errorf("failed to register device: %w", err)
+	}
 	if err != nil {
 		h.sendError(conn, req.ID, "REGISTRATION_FAILED", err.Error())
 		return fmt.Errorf("failed to register device: %w", err)
@@ -218,13 +257,13 @@ func (h *DeviceHandler) handleRegisterDevice(_ context.Context, conn *websocket.
 			DeviceID:     deviceID,
 			SessionToken: "",
 			ServerInfo: protocol.ServerInfo{
-				Version:      "1.0.0",
+				Version:      buildinfo.Version,
 				SupportedNFC: []string{"mifare", "desfire", "type4", "ultralight"},
 			},
 		},
 	}
 
-	if err := conn.WriteJSON(response); err != nil {
+	if err := h.writeJSON(conn, response); err != nil {
 		h.removeDeviceSession(deviceID)
 		h.manager.UnregisterDevice(deviceID)
 		return fmt.Errorf("failed to send registration response: %w", err)
@@ -340,9 +379,111 @@ func (h *DeviceHandler) handleDeviceHeartbeat(_ *websocket.Conn, deviceID string
 	return nil
 }
 
+// handleDeviceWriteResponse hands a device's write result to the waiting
+// WriteToDevice call. Responses that arrive after the timeout find no pending
+// entry and are dropped.
+func (h *DeviceHandler) handleDeviceWriteResponse(deviceID string, req protocol.WebSocketRequest) error {
+	payloadBytes, err := json.Marshal(req.Payload)
+	if err != nil {
+		return err
+	}
+
+	var resp protocol.DeviceWriteResponse
+	if err := json.Unmarshal(payloadBytes, &resp); err != nil {
+		return err
+	}
+	if resp.RequestID == "" {
+		resp.RequestID = req.ID
+	}
+
+	h.pendingMux.Lock()
+	pending, ok := h.pendingWrites[resp.RequestID]
+	h.pendingMux.Unlock()
+
+	if !ok || pending.deviceID != deviceID {
+		return fmt.Errorf("no pending write request %q for device %s", resp.RequestID, deviceID)
+	}
+
+	select {
+	case pending.respCh <- resp:
+	default:
+		// Duplicate response; the first one wins
+	}
+	return nil
+}
+
+// WriteToDevice asks a device to write msg to the tag in its field and waits
+// for the deviceWriteResponse carrying the same request ID. The pending entry
+// is removed on every path, so a device that never answers cannot leak it.
+func (h *DeviceHandler) WriteToDevice(deviceID string, msg *protocol.NDEFMessageInput) error {
+	requestID := uuid.New().String()
+	pending := pendingWrite{
+		deviceID: deviceID,
+		respCh:   make(chan protocol.DeviceWriteResponse, 1),
+		gone:     make(chan struct{}),
+	}
+
+	h.pendingMux.Lock()
+	h.pendingWrites[requestID] = pending
+	h.pendingMux.Unlock()
+	defer h.removePendingWrite(requestID)
+
+	err := h.SendToDevice(deviceID, protocol.WebSocketMessage{
+		ID:   requestID,
+		Type: protocol.WSTypeDeviceWriteRequest,
+		Payload: protocol.DeviceWriteRequest{
+			RequestID:   requestID,
+			DeviceID:    deviceID,
+			NDEFMessage: msg,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send write request: %w", err)
+	}
+
+	timer := time.NewTimer(h.writeTimeout)
+	defer timer.Stop()
+
+	select {
+	case resp := <-pending.respCh:
+		if !resp.Success {
+			return fmt.Errorf("device write failed: %s", resp.Error)
+		}
+		return nil
+	case <-pending.gone:
+		return ErrDeviceDisconnected
+	case <-timer.C:
+		log.Printf("[device] Write request %s to %s timed out after %v", requestID, deviceID, h.writeTimeout)
+		return fmt.Errorf("%w (waited %v)", ErrDeviceWriteTimeout, h.writeTimeout)
+	}
+}
+
+// removePendingWrite drops the correlation entry for a write request.
+func (h *DeviceHandler) removePendingWrite(requestID string) {
+	h.pendingMux.Lock()
+	defer h.pendingMux.Unlock()
+
+	delete(h.pendingWrites, requestID)
+}
+
+// failPendingWrites fails the writes still waiting on deviceID at once,
+// instead of leaving them to time out.
+func (h *DeviceHandler) failPendingWrites(deviceID string) {
+	h.pendingMux.Lock()
+	defer h.pendingMux.Unlock()
+
+	for requestID, pending := range h.pendingWrites {
+		if pending.deviceID == deviceID {
+			close(pending.gone)
+			delete(h.pendingWrites, requestID)
+		}
+	}
+}
+
 // handleDeviceDisconnect cleans up when device WebSocket closes.
 func (h *DeviceHandler) handleDeviceDisconnect(deviceID string) {
 	h.removeDeviceSession(deviceID)
+	h.failPendingWrites(deviceID)
 
 	if h.manager != nil {
 		h.manager.UnregisterDevice(deviceID)
@@ -351,6 +492,26 @@ func (h *DeviceHandler) handleDeviceDisconnect(deviceID string) {
 	log.Printf("[device] Device disconnected: %s", deviceID)
 }
 
+// Shutdown notifies every registered device that the server is stopping,
+// unregisters it and closes its connection.
+func (h *DeviceHandler) Shutdown() {
+	if h.manager != nil {
+		h.manager.Shutdown()
+	}
+
+	h.deviceSessionsMux.Lock()
+	conns := make([]*websocket.Conn, 0, len(h.deviceSessions))
+	for _, conn := range h.deviceSessions {
+		conns = append(conns, conn)
+	}
+	h.deviceSessionsMux.Unlock()
+
+	// Closing the connection ends its read loop, which removes the session
+	for _, conn := range conns {
+		conn.Close()
+	}
+}
+
 // addDeviceSession stores a WebSocket connection for a device.
 func (h *DeviceHandler) addDeviceSession(deviceID string, conn *websocket.Conn) {
 	h.deviceSessionsMux.Lock()
@@ -389,7 +550,15 @@ func (h *DeviceHandler) SendToDevice(deviceID string, message any) error {
 		return fmt.Errorf("device not connected: %s", deviceID)
 	}
 
-	return conn.WriteJSON(message)
+	return h.writeJSON(conn, message)
+}
+
+// writeJSON sends v on conn. Registration replies and write requests come
+// from different goroutines, so every write to a device goes through here.
+func (h *DeviceHandler) writeJSON(conn *websocket.Conn, v any) error {
+	h.connWriteMux.Lock()
+	defer h.connWriteMux.Unlock()
+	return conn.WriteJSON(v)
 }
 
 // sendError sends an error response to a device.
@@ -404,7 +573,7 @@ func (h *DeviceHandler) sendError(conn *websocket.Conn, requestID string, errorC
 		},
 	}
 
-	if err := conn.WriteJSON(response); err != nil {
+	if err := h.writeJSON(conn, response); err != nil {
 		log.Printf("[device] Failed to send error response: %v", err)
 	}
 }
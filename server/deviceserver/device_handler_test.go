@@ -0,0 +1,278 @@
+package deviceserver
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dotside-studios/davi-nfc-agent/nfc/remotenfc"
+	"github.com/dotside-studios/davi-nfc-agent/protocol"
+	"github.com/dotside-studios/davi-nfc-agent/server"
+	"github.com/gorilla/websocket"
+)
+
+// connectPhone serves the device handler over httptest and registers a phone,
+// returning its connection and device ID.
+func connectPhone(t *testing.T, h *DeviceHandler) (*websocket.Conn, string) {
+	t.Helper()
+
+	httpServer := httptest.NewServer(http.HandlerFunc(h.HandleWebSocket))
+	t.Cleanup(httpServer.Close)
+
+	url := "ws" + strings.TrimPrefix(httpServer.URL, "http") + "/ws?mode=device"
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	err = conn.WriteJSON(protocol.WebSocketRequest{
+		ID:      "reg",
+		Type:    protocol.WSTypeRegisterDevice,
+		Payload: map[string]any{"deviceName": "Test Phone", "platform": "android"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to send registration: %v", err)
+	}
+
+	var resp struct {
+		Type    string                              `json:"type"`
+		Payload protocol.DeviceRegistrationResponse `json:"payload"`
+	}
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	if err := conn.ReadJSON(&resp); err != nil {
+		t.Fatalf("Failed to read registration response: %v", err)
+	}
+	if resp.Type != protocol.WSTypeRegisterDeviceResponse {
+		t.Fatalf("Expected %q, got %q", protocol.WSTypeRegisterDeviceResponse, resp.Type)
+	}
+
+	return conn, resp.Payload.DeviceID
+}
+
+// readWriteRequest reads the deviceWriteRequest sent to a phone. It runs in
+// the phone's goroutine, so failures are returned for the test to report.
+func readWriteRequest(conn *websocket.Conn) (protocol.DeviceWriteRequest, error) {
+	var msg struct {
+		Type    string                      `json:"type"`
+		Payload protocol.DeviceWriteRequest `json:"payload"`
+	}
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	if err := conn.ReadJSON(&msg); err != nil {
+		return msg.Payload, fmt.Errorf("failed to read write request: %w", err)
+	}
+	if msg.Type != protocol.WSTypeDeviceWriteRequest {
+		return msg.Payload, fmt.Errorf("expected %q, got %q", protocol.WSTypeDeviceWriteRequest, msg.Type)
+	}
+	return msg.Payload, nil
+}
+
+func (h *DeviceHandler) pendingWriteCount() int {
+	h.pendingMux.Lock()
+	defer h.pendingMux.Unlock()
+	return len(h.pendingWrites)
+}
+
+// TestDeviceHandler_WriteToDevice tests that a phone's answer is matched to
+// the request by ID.
+func TestDeviceHandler_WriteToDevice(t *testing.T) {
+	manager := remotenfc.NewManager(time.Minute)
+	defer manager.Close()
+	h := NewDeviceHandler(manager, server.NewServerBridge(), time.Second)
+
+	conn, deviceID := connectPhone(t, h)
+
+	phoneErr := make(chan error, 1)
+	go func() {
+		req, err := readWriteRequest(conn)
+		if err != nil {
+			phoneErr <- err
+			return
+		}
+		phoneErr <- conn.WriteJSON(protocol.WebSocketRequest{
+			Type:    protocol.WSTypeDeviceWriteResponse,
+			Payload: map[string]any{"requestID": req.RequestID, "success": true},
+		})
+	}()
+
+	msg := &protocol.NDEFMessageInput{Records: []protocol.NDEFRecordInput{{RecordType: "text", Content: "Hi"}}}
+	writeErr := h.WriteToDevice(deviceID, msg)
+	if err := <-phoneErr; err != nil {
+		t.Fatalf("Phone: %v", err)
+	}
+	if writeErr != nil {
+		t.Fatalf("WriteToDevice() error = %v", writeErr)
+	}
+	if n := h.pendingWriteCount(); n != 0 {
+		t.Errorf("Expected no pending writes, got %d", n)
+	}
+}
+
+// TestServer_DeviceWriteTimeout tests that a phone that never answers fails
+// the client's write with DEVICE_TIMEOUT and leaves no pending entry behind.
+func TestServer_DeviceWriteTimeout(t *testing.T) {
+	manager := remotenfc.NewManager(time.Minute)
+	defer manager.Close()
+	s := New(Config{DeviceManager: manager, DeviceWriteTimeout: 50 * time.Millisecond}, server.NewServerBridge())
+
+	conn, deviceID := connectPhone(t, s.deviceHandler)
+
+	// The phone receives the request but stays silent
+	received := make(chan protocol.DeviceWriteRequest, 1)
+	phoneErr := make(chan error, 1)
+	go func() {
+		req, err := readWriteRequest(conn)
+		received <- req
+		phoneErr <- err
+	}()
+
+	msg := server.WriteRequestMessage{
+		RequestID: "req_1",
+		Request: server.WriteRequest{
+			DeviceID: deviceID,
+			Records:  []server.WriteRecord{{Type: "text", Content: "Hi"}},
+		},
+		ResponseCh: make(chan server.WriteResponseMessage, 1),
+	}
+
+	start := time.Now()
+	s.executeWriteRequest(msg)
+	resp := <-msg.ResponseCh
+
+	if resp.Success {
+		t.Fatal("Expected write to a silent phone to fail")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Write took %v, expected it to time out after 50ms", elapsed)
+	}
+	if code := resp.Payload.(map[string]any)["code"]; code != "DEVICE_TIMEOUT" {
+		t.Errorf("Expected DEVICE_TIMEOUT, got %v (error: %s)", code, resp.Error)
+	}
+	if n := s.deviceHandler.pendingWriteCount(); n != 0 {
+		t.Errorf("Expected pending write to be cleaned up, got %d", n)
+	}
+
+	// A late answer must be dropped without reviving the request
+	req := <-received
+	if err := <-phoneErr; err != nil {
+		t.Fatalf("Phone: %v", err)
+	}
+	if req.DeviceID != deviceID || req.NDEFMessage == nil || req.NDEFMessage.Records[0].Content != "Hi" {
+		t.Errorf("Unexpected write request sent to phone: %+v", req)
+	}
+	err := s.deviceHandler.handleDeviceWriteResponse(deviceID, protocol.WebSocketRequest{
+		Type:    protocol.WSTypeDeviceWriteResponse,
+		Payload: map[string]any{"requestID": req.RequestID, "success": true},
+	})
+	if err == nil {
+		t.Error("Expected late response to be rejected")
+	}
+}
+
+// TestServer_DeviceWriteDisconnect tests that a write waiting on a phone does
+// not hold up other writes, and fails with DEVICE_DISCONNECTED as soon as the
+// phone goes away.
+func TestServer_DeviceWriteDisconnect(t *testing.T) {
+	manager := remotenfc.NewManager(time.Minute)
+	defer manager.Close()
+	s := New(Config{DeviceManager: manager, DeviceWriteTimeout: time.Minute}, server.NewServerBridge())
+
+	conn, deviceID := connectPhone(t, s.deviceHandler)
+
+	msg := server.WriteRequestMessage{
+		RequestID: "req_1",
+		Request: server.WriteRequest{
+			DeviceID: deviceID,
+			Records:  []server.WriteRecord{{Type: "text", Content: "Hi"}},
+		},
+		ResponseCh: make(chan server.WriteResponseMessage, 1),
+	}
+	s.executeWriteRequest(msg)
+
+	// The next write is answered while the phone still has the first one
+	other := server.WriteRequestMessage{
+		RequestID:  "req_2",
+		Request:    server.WriteRequest{Records: []server.WriteRecord{{Type: "text", Content: "Hi"}}},
+		ResponseCh: make(chan server.WriteResponseMessage, 1),
+	}
+	s.executeWriteRequest(other)
+	select {
+	case <-other.ResponseCh:
+	case <-time.After(time.Second):
+		t.Fatal("Write queued behind a phone write was not handled")
+	}
+
+	if _, err := readWriteRequest(conn); err != nil {
+		t.Fatalf("Phone: %v", err)
+	}
+	conn.Close()
+
+	select {
+	case resp := <-msg.ResponseCh:
+		if resp.Success {
+			t.Fatal("Expected write to a disconnected phone to fail")
+		}
+		if code := resp.Payload.(map[string]any)["code"]; code != "DEVICE_DISCONNECTED" {
+			t.Errorf("Expected DEVICE_DISCONNECTED, got %v (error: %s)", code, resp.Error)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Write was not failed when the phone disconnected")
+	}
+	if n := s.deviceHandler.pendingWriteCount(); n != 0 {
+		t.Errorf("Expected pending write to be cleaned up, got %d", n)
+	}
+}
+
+// TestServer_StopNotifiesDevices tests that stopping the server tells
+// registered phones and closes their connections.
+func TestServer_StopNotifiesDevices(t *testing.T) {
+	manager := remotenfc.NewManager(time.Minute)
+	defer manager.Close()
+	s := New(Config{DeviceManager: manager}, server.NewServerBridge())
+
+	conn, deviceID := connectPhone(t, s.deviceHandler)
+
+	s.Stop()
+
+	var msg protocol.WebSocketMessage
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatalf("Failed to read shutdown message: %v", err)
+	}
+	if msg.Type != protocol.WSTypeServerShutdown {
+		t.Errorf("Expected %q, got %q", protocol.WSTypeServerShutdown, msg.Type)
+	}
+	if _, exists := manager.GetDevice(deviceID); exists {
+		t.Error("Device should be unregistered after Stop")
+	}
+	if _, _, err := conn.ReadMessage(); err == nil {
+		t.Error("Expected the connection to be closed after the shutdown message")
+	}
+}
+
+// TestServer_WriteUnknownReader tests that a write naming a reader ID the
+// server does not have fails with UNKNOWN_READER.
+func TestServer_WriteUnknownReader(t *testing.T) {
+	s := New(Config{}, server.NewServerBridge())
+
+	msg := server.WriteRequestMessage{
+		RequestID: "req_1",
+		Request: server.WriteRequest{
+			ReaderID: "lane9",
+			Records:  []server.WriteRecord{{Type: "text", Content: "Hi"}},
+		},
+		ResponseCh: make(chan server.WriteResponseMessage, 1),
+	}
+	s.executeWriteRequest(msg)
+
+	resp := <-msg.ResponseCh
+	if resp.Success {
+		t.Fatal("Expected write to an unknown reader to fail")
+	}
+	if code := resp.Payload.(map[string]any)["code"]; code != "UNKNOWN_READER" {
+		t.Errorf("Expected UNKNOWN_READER, got %v (error: %s)", code, resp.Error)
+	}
+}
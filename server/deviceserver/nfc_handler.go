@@ -2,27 +2,34 @@ package deviceserver
 
 import (
 	"context"
-	"fmt"
 	"log"
 
 	"github.com/dotside-studios/davi-nfc-agent/nfc"
 	"github.com/dotside-studios/davi-nfc-agent/server"
 )
 
+// ReaderSource is the tag event stream an NFCHandler forwards: a single
+// *nfc.NFCReader or an *nfc.MultiReader, whose events carry a reader ID.
+type ReaderSource interface {
+	Data() <-chan nfc.NFCData
+	StatusUpdates() <-chan nfc.DeviceStatus
+	SetAllowedCardTypes(types map[string]bool)
+}
+
 // NFCHandler handles NFC reader operations for the device server.
 // It reads from the NFC reader and sends data through the bridge.
 type NFCHandler struct {
-	reader           *nfc.NFCReader
-	allowedCardTypes map[string]bool
-	bridge           *server.ServerBridge
+	reader ReaderSource
+	bridge *server.ServerBridge
 }
 
-// NewNFCHandler creates a new NFC handler for the device server.
-func NewNFCHandler(reader *nfc.NFCReader, allowedCardTypes map[string]bool, bridge *server.ServerBridge) *NFCHandler {
+// NewNFCHandler creates a new NFC handler for the device server. The card
+// type allowlist is applied by the reader, so disallowed cards are never read.
+func NewNFCHandler(reader ReaderSource, allowedCardTypes map[string]bool, bridge *server.ServerBridge) *NFCHandler {
+	reader.SetAllowedCardTypes(allowedCardTypes)
 	return &NFCHandler{
-		reader:           reader,
-		allowedCardTypes: allowedCardTypes,
-		bridge:           bridge,
+		reader: reader,
+		bridge: bridge,
 	}
 }
 
@@ -53,18 +60,13 @@ func (h *NFCHandler) handleTagData(data nfc.NFCData, s *Server) {
 		return
 	}
 
-	if data.Card == nil {
+	if data.Unsupported != nil {
+		log.Printf("Unsupported card tapped: UID %s, ATR %s", data.Unsupported.UID, data.Unsupported.ATR)
+		s.BroadcastTagData(data)
 		return
 	}
 
-	// Check card type filter
-	if len(h.allowedCardTypes) > 0 && !h.allowedCardTypes[data.Card.Type] {
-		log.Printf("Card type '%s' not in allowed list, ignoring", data.Card.Type)
-		// Send error message to consumers
-		s.BroadcastTagData(nfc.NFCData{
-			Card: nil,
-			Err:  fmt.Errorf("card type '%s' not allowed by filter", data.Card.Type),
-		})
+	if data.Card == nil {
 		return
 	}
 
@@ -81,7 +83,10 @@ func (h *NFCHandler) handleTagData(data nfc.NFCData, s *Server) {
 			text = textMsg.Text
 		}
 	}
-	fmt.Printf("UID: %s\nDecoded text: %s\n", data.Card.UID, text)
+	log.Printf("UID: %s, decoded text: %s", data.Card.UID, text)
 
 	s.BroadcastTagData(data)
+	if s.config.OnTagData != nil {
+		s.config.OnTagData(data)
+	}
 }
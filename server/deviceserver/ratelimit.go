@@ -0,0 +1,99 @@
+package deviceserver
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited indicates a client sent writes faster than Config.WriteRateLimit
+// allows. Use errors.As with *RateLimitError to get the time until the next
+// write is accepted.
+var ErrRateLimited = errors.New("write rate limit exceeded")
+
+// RateLimitError is returned for writes refused by the rate limiter. It
+// matches ErrRateLimited with errors.Is.
+type RateLimitError struct {
+	RetryAfter time.Duration // Time until the client's next write is accepted
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("%v, retry in %v", ErrRateLimited, e.RetryAfter.Round(time.Millisecond))
+}
+
+func (e *RateLimitError) Is(target error) bool {
+	return target == ErrRateLimited
+}
+
+// writeLimiter is a token bucket per client: each client may burst writes up
+// to the bucket size, after which it earns rate writes per second.
+type writeLimiter struct {
+	rate  float64 // Tokens added per second
+	burst float64 // Bucket size
+	now   func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket // clientID -> bucket
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// newWriteLimiter returns a limiter allowing rate writes per second per client,
+// or nil when rate is not positive. burst defaults to the rate rounded up.
+func newWriteLimiter(rate float64, burst int) *writeLimiter {
+	if rate <= 0 {
+		return nil
+	}
+	if burst <= 0 {
+		burst = int(math.Ceil(rate))
+	}
+	return &writeLimiter{
+		rate:    rate,
+		burst:   float64(burst),
+		now:     time.Now,
+		buckets: make(map[string]*tokenBucket),
+	}
+}
+
+// allow takes a token from clientID's bucket, returning a *RateLimitError if
+// it is empty. A nil limiter allows everything.
+func (l *writeLimiter) allow(clientID string) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.prune(now)
+
+	b, ok := l.buckets[clientID]
+	if !ok {
+		b = &tokenBucket{tokens: l.burst, last: now}
+		l.buckets[clientID] = b
+	}
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+		return &RateLimitError{RetryAfter: wait}
+	}
+	b.tokens--
+	return nil
+}
+
+// prune drops buckets that have refilled completely; they behave exactly like
+// a new bucket, so clients that went away don't accumulate.
+func (l *writeLimiter) prune(now time.Time) {
+	for id, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, id)
+		}
+	}
+}
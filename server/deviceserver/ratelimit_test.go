@@ -0,0 +1,87 @@
+package deviceserver
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dotside-studios/davi-nfc-agent/server"
+)
+
+func TestWriteLimiter(t *testing.T) {
+	now := time.Unix(0, 0)
+	l := newWriteLimiter(2, 3)
+	l.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		if err := l.allow("a"); err != nil {
+			t.Fatalf("Write %d within burst: %v", i, err)
+		}
+	}
+
+	err := l.allow("a")
+	var limited *RateLimitError
+	if !errors.As(err, &limited) || !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("Expected RateLimitError, got %v", err)
+	}
+	if limited.RetryAfter != 500*time.Millisecond {
+		t.Errorf("RetryAfter = %v, want 500ms", limited.RetryAfter)
+	}
+
+	// Other clients have their own bucket
+	if err := l.allow("b"); err != nil {
+		t.Errorf("Other client: %v", err)
+	}
+
+	now = now.Add(500 * time.Millisecond)
+	if err := l.allow("a"); err != nil {
+		t.Errorf("Write after refill: %v", err)
+	}
+
+	// Idle clients are forgotten once their bucket is full again
+	now = now.Add(10 * time.Second)
+	l.allow("c")
+	if _, ok := l.buckets["a"]; ok {
+		t.Error("Expected the idle client's bucket to be pruned")
+	}
+
+	if newWriteLimiter(0, 5) != nil {
+		t.Error("Expected no limiter for a zero rate")
+	}
+	var none *writeLimiter
+	if err := none.allow("a"); err != nil {
+		t.Errorf("nil limiter: %v", err)
+	}
+}
+
+// TestServer_WriteRateLimited tests that writes over the limit fail with
+// RATE_LIMITED and a retry hint.
+func TestServer_WriteRateLimited(t *testing.T) {
+	s := New(Config{WriteRateLimit: 1}, server.NewServerBridge())
+
+	write := func(clientID string) server.WriteResponseMessage {
+		msg := server.WriteRequestMessage{
+			RequestID:  "req_1",
+			ClientID:   clientID,
+			Request:    server.WriteRequest{Records: []server.WriteRecord{{Type: "text", Content: "Hi"}}},
+			ResponseCh: make(chan server.WriteResponseMessage, 1),
+		}
+		s.executeWriteRequest(msg)
+		return <-msg.ResponseCh
+	}
+
+	if resp := write("a"); resp.Error != "No NFC reader available" {
+		t.Fatalf("First write: unexpected error %q", resp.Error)
+	}
+	resp := write("a")
+	payload, _ := resp.Payload.(map[string]any)
+	if payload["code"] != "RATE_LIMITED" {
+		t.Fatalf("Expected RATE_LIMITED, got %v (error: %s)", payload["code"], resp.Error)
+	}
+	if ms, _ := payload["retryAfterMs"].(int64); ms <= 0 || ms > 1000 {
+		t.Errorf("retryAfterMs = %v, want 1..1000", payload["retryAfterMs"])
+	}
+	if resp := write("b"); resp.Error != "No NFC reader available" {
+		t.Errorf("Other client: unexpected error %q", resp.Error)
+	}
+}
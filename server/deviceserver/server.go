@@ -2,14 +2,20 @@
 package deviceserver
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/dotside-studios/davi-nfc-agent/buildinfo"
 	"github.com/dotside-studios/davi-nfc-agent/nfc"
 	"github.com/dotside-studios/davi-nfc-agent/protocol"
 	"github.com/dotside-studios/davi-nfc-agent/server"
@@ -32,12 +38,20 @@ type Server struct {
 	// WebSocket upgrader
 	upgrader websocket.Upgrader
 
-	// mDNS service for auto-discovery
+	// mDNS service for auto-discovery, and the reader it last advertised
 	mdnsServer *zeroconf.Server
+	mdnsReader nfc.ReaderInfo
+	mdnsMux    sync.Mutex
 
 	// Device connections (phones, etc.)
 	devices    map[*websocket.Conn]string // conn -> deviceID
 	devicesMux sync.RWMutex
+
+	// deviceHandler routes writes to devices; nil without a DeviceManager
+	deviceHandler *DeviceHandler
+
+	// writeLimiter caps writes per client; nil without Config.WriteRateLimit
+	writeLimiter *writeLimiter
 }
 
 // New creates a new device server instance.
@@ -52,6 +66,7 @@ func New(config Config, bridge *server.ServerBridge) *Server {
 			},
 		},
 		handlerRegistry: server.NewHandlerRegistry(),
+		writeLimiter:    newWriteLimiter(config.WriteRateLimit, config.WriteBurst),
 	}
 
 	// Register NFC reader handlers (hardware NFC)
@@ -59,11 +74,14 @@ func New(config Config, bridge *server.ServerBridge) *Server {
 		nfcHandler := NewNFCHandler(config.Reader, config.AllowedCardTypes, bridge)
 		nfcHandler.Register(s)
 	}
+	if config.Readers != nil {
+		NewNFCHandler(config.Readers, config.AllowedCardTypes, bridge).Register(s)
+	}
 
 	// Register device handler (external devices like phones)
 	if config.DeviceManager != nil {
-		deviceHandler := NewDeviceHandler(config.DeviceManager, bridge)
-		deviceHandler.Register(s)
+		s.deviceHandler = NewDeviceHandler(config.DeviceManager, bridge, config.DeviceWriteTimeout)
+		s.deviceHandler.Register(s)
 	}
 
 	return s
@@ -93,6 +111,9 @@ func (s *Server) BroadcastTagData(data nfc.NFCData) {
 
 // BroadcastDeviceStatus sends device status through the bridge to the client server.
 func (s *Server) BroadcastDeviceStatus(status nfc.DeviceStatus) {
+	if status.ReaderFirmware != "" {
+		s.updateMDNSReader(nfc.ReaderInfo{Model: status.ReaderModel, Firmware: status.ReaderFirmware})
+	}
 	if !s.bridge.SendDeviceStatus(status) {
 		log.Printf("[device] Warning: failed to send device status to bridge (channel full or closed)")
 	}
@@ -169,12 +190,16 @@ func (s *Server) Start() error {
 	if reader != nil {
 		reader.Start()
 	}
+	if s.config.Readers != nil {
+		s.config.Readers.Start()
+	}
 
 	// Start lifecycle handlers
 	s.handlerRegistry.StartLifecycleHandlers(s.ctx)
 
 	// Start write request handler
 	go s.handleWriteRequests()
+	go s.handleCommands()
 
 	// Block until shutdown
 	<-s.ctx.Done()
@@ -183,11 +208,12 @@ func (s *Server) Start() error {
 	return nil
 }
 
-// Stop stops the device server.
+// Stop stops the device server. The mDNS record is withdrawn last, once
+// nothing is served under it anymore.
 func (s *Server) Stop() {
-	if s.mdnsServer != nil {
-		s.mdnsServer.Shutdown()
-		s.mdnsServer = nil
+	// Tell phones before their sockets go away so they can reconnect
+	if s.deviceHandler != nil {
+		s.deviceHandler.Shutdown()
 	}
 
 	if s.httpServer != nil {
@@ -199,6 +225,23 @@ func (s *Server) Stop() {
 	if s.cancel != nil {
 		s.cancel()
 	}
+
+	s.mdnsMux.Lock()
+	if s.mdnsServer != nil {
+		s.mdnsServer.Shutdown()
+		s.mdnsServer = nil
+	}
+	s.mdnsMux.Unlock()
+}
+
+// StopAccepting closes the listener so no new devices or clients connect,
+// waiting until ctx ends for plain HTTP requests in flight. Connected phones
+// are kept until Stop, and the mDNS record stays registered.
+func (s *Server) StopAccepting(ctx context.Context) error {
+	if s.httpServer == nil {
+		return nil
+	}
+	return s.httpServer.Shutdown(ctx)
 }
 
 // handleWebSocket handles WebSocket connections from devices.
@@ -273,7 +316,39 @@ func (s *Server) handleWriteRequests() {
 
 // executeWriteRequest executes a write request from the client server.
 func (s *Server) executeWriteRequest(msg server.WriteRequestMessage) {
+	if err := s.writeLimiter.allow(msg.ClientID); err != nil {
+		msg.ResponseCh <- server.WriteResponseMessage{
+			RequestID: msg.RequestID,
+			Success:   false,
+			Error:     err.Error(),
+			Payload:   rateLimitErrorPayload(err),
+		}
+		return
+	}
+
+	if msg.Request.DeviceID != "" {
+		// Waits up to DeviceWriteTimeout for the phone; don't hold up
+		// hardware writes queued behind it
+		go s.executeDeviceWriteRequest(msg)
+		return
+	}
+
 	reader := s.config.Reader
+	if id := msg.Request.ReaderID; id != "" {
+		reader = nil
+		if s.config.Readers != nil {
+			reader, _ = s.config.Readers.Reader(id)
+		}
+		if reader == nil {
+			msg.ResponseCh <- server.WriteResponseMessage{
+				RequestID: msg.RequestID,
+				Success:   false,
+				Error:     fmt.Sprintf("%v: %q", nfc.ErrUnknownReader, id),
+				Payload:   map[string]any{"code": "UNKNOWN_READER"},
+			}
+			return
+		}
+	}
 	if reader == nil {
 		msg.ResponseCh <- server.WriteResponseMessage{
 			RequestID: msg.RequestID,
@@ -294,24 +369,1007 @@ func (s *Server) executeWriteRequest(msg server.WriteRequestMessage) {
 		return
 	}
 
+	var trace *nfc.APDUTrace
+	if msg.Request.Trace {
+		trace = s.newTrace()
+	}
+
 	// Write to card with overwrite option
 	err = reader.WriteMessageWithOptions(ndefMsg, nfc.WriteOptions{
-		Overwrite: true,
-		Index:     -1,
+		Overwrite:      true,
+		Index:          -1,
+		LockAfterWrite: msg.Request.LockAfterWrite,
+		Trace:          trace,
 	})
 	if err != nil {
-		msg.ResponseCh <- server.WriteResponseMessage{
+		payload := writeErrorPayload(err)
+		resp := server.WriteResponseMessage{
 			RequestID: msg.RequestID,
 			Success:   false,
 			Error:     err.Error(),
 		}
+		if trace != nil {
+			payload["trace"] = tracePayload(trace)
+		}
+		if len(payload) > 0 {
+			resp.Payload = payload
+		}
+		msg.ResponseCh <- resp
 		return
 	}
 
-	msg.ResponseCh <- server.WriteResponseMessage{
+	resp := server.WriteResponseMessage{
 		RequestID: msg.RequestID,
 		Success:   true,
 	}
+	if trace != nil {
+		resp.Payload = map[string]any{"trace": tracePayload(trace)}
+	}
+	msg.ResponseCh <- resp
+}
+
+// executeDeviceWriteRequest forwards a write request to the device (phone)
+// named in the request and reports the device's answer.
+func (s *Server) executeDeviceWriteRequest(msg server.WriteRequestMessage) {
+	resp := server.WriteResponseMessage{RequestID: msg.RequestID}
+
+	if s.deviceHandler == nil {
+		resp.Error = "No remote devices available"
+		msg.ResponseCh <- resp
+		return
+	}
+
+	// The device protocol has no lock step, so don't silently leave the card writable
+	if msg.Request.LockAfterWrite {
+		resp.Error = "lockAfterWrite is not supported for device writes"
+		msg.ResponseCh <- resp
+		return
+	}
+
+	// Validate the records the same way hardware writes do
+	if _, err := server.BuildNDEFMessage(msg.Request); err != nil {
+		resp.Error = err.Error()
+		msg.ResponseCh <- resp
+		return
+	}
+
+	err := s.deviceHandler.WriteToDevice(msg.Request.DeviceID, writeRequestToNDEFInput(msg.Request))
+	switch {
+	case errors.Is(err, ErrDeviceWriteTimeout):
+		resp.Error = err.Error()
+		resp.Payload = map[string]any{"code": "DEVICE_TIMEOUT"}
+	case errors.Is(err, ErrDeviceDisconnected):
+		resp.Error = err.Error()
+		resp.Payload = map[string]any{"code": "DEVICE_DISCONNECTED"}
+	case err != nil:
+		resp.Error = err.Error()
+	default:
+		resp.Success = true
+	}
+	msg.ResponseCh <- resp
+}
+
+// writeRequestToNDEFInput converts client write records to the NDEF input
+// format understood by devices.
+func writeRequestToNDEFInput(req server.WriteRequest) *protocol.NDEFMessageInput {
+	input := &protocol.NDEFMessageInput{}
+	for _, record := range req.Records {
+		input.Records = append(input.Records, protocol.NDEFRecordInput{
+			RecordType: record.Type,
+			Content:    record.Content,
+			Language:   record.Language,
+		})
+	}
+	return input
+}
+
+// handleCommands listens for commands from the client server.
+func (s *Server) handleCommands() {
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case msg, ok := <-s.bridge.Command:
+			if !ok {
+				return
+			}
+			if msg.Type == server.WSMessageTypeWaitForCard {
+				// Blocks until a card is tapped; don't hold up other commands
+				go func(msg server.CommandMessage) {
+					msg.ResponseCh <- s.executeCommand(msg)
+				}(msg)
+				continue
+			}
+			msg.ResponseCh <- s.executeCommand(msg)
+		}
+	}
+}
+
+// executeCommand executes a command from the client server.
+func (s *Server) executeCommand(msg server.CommandMessage) server.CommandResponseMessage {
+	resp := server.CommandResponseMessage{RequestID: msg.RequestID}
+
+	reader := s.config.Reader
+	if reader == nil {
+		resp.Error = "No NFC reader available"
+		return resp
+	}
+
+	// Commands that write to the card count against the client's write rate
+	switch msg.Type {
+	case server.WSMessageTypeFormatNDEF, server.WSMessageTypeWriteRaw, server.WSMessageTypeWritePage, server.WSMessageTypeWriteLocalizedText, server.WSMessageTypeWriteAndRead:
+		if err := s.writeLimiter.allow(msg.ClientID); err != nil {
+			resp.Error = err.Error()
+			resp.Payload = rateLimitErrorPayload(err)
+			return resp
+		}
+	}
+
+	switch msg.Type {
+	case server.WSMessageTypeFormatNDEF:
+		force, _ := msg.Payload["force"].(bool)
+		if err := reader.FormatNDEF(force); err != nil {
+			resp.Error = err.Error()
+			resp.Payload = map[string]any{"code": "FORMAT_FAILED"}
+			switch {
+			case errors.Is(err, nfc.ErrDeviceCooldown):
+				resp.Payload = cooldownErrorPayload(err)
+			case errors.Is(err, nfc.ErrDeviceBusy):
+				resp.Payload = map[string]any{"code": "DEVICE_BUSY"}
+			}
+			return resp
+		}
+		resp.Payload = map[string]any{"message": "Card formatted for NDEF"}
+	case server.WSMessageTypeWriteRaw:
+		ndefHex, _ := msg.Payload["ndefHex"].(string)
+		raw, err := hex.DecodeString(ndefHex)
+		if err != nil {
+			resp.Error = "ndefHex must be hex"
+			resp.Payload = map[string]any{"code": "INVALID_REQUEST"}
+			return resp
+		}
+		// Refuse anything that is not a complete NDEF message before touching the card
+		ndefMsg, err := nfc.ParseRawNDEF(raw)
+		if err != nil {
+			resp.Error = err.Error()
+			resp.Payload = map[string]any{"code": "INVALID_NDEF"}
+			return resp
+		}
+		err = reader.WriteMessageWithOptions(ndefMsg, nfc.WriteOptions{Overwrite: true, Index: -1})
+		if err != nil {
+			payload := writeErrorPayload(err)
+			if _, ok := payload["code"]; !ok {
+				payload["code"] = "WRITE_FAILED"
+			}
+			resp.Error = err.Error()
+			resp.Payload = payload
+			return resp
+		}
+		resp.Payload = map[string]any{"message": "NDEF message written", "length": len(raw)}
+	case server.WSMessageTypeWriteLocalizedText:
+		rawTexts, _ := msg.Payload["texts"].(map[string]any)
+		texts := make(map[string]string, len(rawTexts))
+		for lang, v := range rawTexts {
+			text, ok := v.(string)
+			if !ok {
+				resp.Error = fmt.Sprintf("text for %q must be a string", lang)
+				resp.Payload = map[string]any{"code": "INVALID_REQUEST"}
+				return resp
+			}
+			texts[lang] = text
+		}
+		ndefMsg, err := nfc.NewLocalizedTextMessage(texts)
+		if err != nil {
+			resp.Error = err.Error()
+			resp.Payload = map[string]any{"code": "INVALID_REQUEST"}
+			return resp
+		}
+		err = reader.WriteMessageWithOptions(ndefMsg, nfc.WriteOptions{Overwrite: true, Index: -1})
+		if err != nil {
+			payload := writeErrorPayload(err)
+			if _, ok := payload["code"]; !ok {
+				payload["code"] = "WRITE_FAILED"
+			}
+			resp.Error = err.Error()
+			resp.Payload = payload
+			return resp
+		}
+		resp.Payload = map[string]any{"message": "Localized text written", "records": len(ndefMsg.Records())}
+	case server.WSMessageTypeWriteAndRead:
+		var writeReq server.WriteRequest
+		data, err := json.Marshal(msg.Payload)
+		if err == nil {
+			err = json.Unmarshal(data, &writeReq)
+		}
+		var ndefMsg *nfc.NDEFMessage
+		if err == nil {
+			ndefMsg, err = server.BuildNDEFMessage(writeReq)
+		}
+		if err != nil {
+			resp.Error = err.Error()
+			resp.Payload = map[string]any{"code": "INVALID_REQUEST"}
+			return resp
+		}
+		result, err := reader.WriteAndRead(ndefMsg, nfc.WriteOptions{
+			Overwrite:      true,
+			Index:          -1,
+			LockAfterWrite: writeReq.LockAfterWrite,
+		})
+		if err != nil {
+			payload := map[string]any{"code": "READ_BACK_FAILED", "stage": "read"}
+			if !errors.Is(err, nfc.ErrReadBackFailed) {
+				payload = writeErrorPayload(err)
+				if _, ok := payload["code"]; !ok {
+					payload["code"] = "WRITE_FAILED"
+				}
+				payload["stage"] = "write"
+			}
+			resp.Error = err.Error()
+			resp.Payload = payload
+			return resp
+		}
+		resp.Payload = readCardPayload(result)
+	case server.WSMessageTypeReadManufacturerBlock:
+		info, err := reader.ReadManufacturerBlock()
+		if err != nil {
+			resp.Error = err.Error()
+			resp.Payload = map[string]any{"code": "READ_FAILED"}
+			return resp
+		}
+		resp.Payload = manufacturerBlockPayload(info)
+	case server.WSMessageTypeGetCardInfo:
+		info, err := reader.ReadCardInfo()
+		if err != nil {
+			resp.Error = err.Error()
+			resp.Payload = map[string]any{"code": "READ_FAILED"}
+			return resp
+		}
+		resp.Payload = cardInfoPayload(info)
+	case server.WSMessageTypeReadCard:
+		bestEffort, _ := msg.Payload["bestEffort"].(bool)
+		var trace *nfc.APDUTrace
+		if t, _ := msg.Payload["trace"].(bool); t {
+			trace = s.newTrace()
+		}
+		result, err := reader.ReadWithOptions(nfc.ReadOptions{BestEffort: bestEffort, Trace: trace})
+		if err != nil {
+			resp.Error = err.Error()
+			payload := map[string]any{"code": "READ_FAILED"}
+			if trace != nil {
+				payload["trace"] = tracePayload(trace)
+			}
+			resp.Payload = payload
+			return resp
+		}
+		payload := readCardPayload(result)
+		if trace != nil {
+			payload.Trace = tracePayload(trace)
+		}
+		resp.Payload = payload
+	case server.WSMessageTypeNormalize:
+		result, err := reader.ReadWithOptions(nfc.ReadOptions{})
+		if err != nil {
+			resp.Error = err.Error()
+			resp.Payload = map[string]any{"code": "READ_FAILED"}
+			return resp
+		}
+		if result.Message == nil || len(result.Message.Records()) == 0 {
+			resp.Error = "card does not hold an NDEF message"
+			resp.Payload = map[string]any{"code": "INVALID_NDEF"}
+			return resp
+		}
+		payload, err := normalizePayload(result)
+		if err != nil {
+			resp.Error = err.Error()
+			resp.Payload = map[string]any{"code": "INVALID_NDEF"}
+			return resp
+		}
+		resp.Payload = payload
+	case server.WSMessageTypeCheckWrite:
+		var writeReq server.WriteRequest
+		data, err := json.Marshal(msg.Payload)
+		if err == nil {
+			err = json.Unmarshal(data, &writeReq)
+		}
+		var ndefMsg *nfc.NDEFMessage
+		if err == nil {
+			ndefMsg, err = server.BuildNDEFMessage(writeReq)
+		}
+		if err != nil {
+			resp.Error = err.Error()
+			resp.Payload = map[string]any{"code": "INVALID_REQUEST"}
+			return resp
+		}
+		ok, reasons, err := reader.CheckWrite(ndefMsg, nfc.WriteOptions{
+			Overwrite:      true,
+			Index:          -1,
+			LockAfterWrite: writeReq.LockAfterWrite,
+		})
+		if err != nil {
+			resp.Error = err.Error()
+			resp.Payload = map[string]any{"code": "READ_FAILED"}
+			return resp
+		}
+		if reasons == nil {
+			reasons = []string{}
+		}
+		resp.Payload = protocol.CheckWritePayload{OK: ok, Reasons: reasons}
+	case server.WSMessageTypeQuickWritable:
+		uid, writable, err := reader.QuickWritable()
+		if err != nil {
+			resp.Error = err.Error()
+			resp.Payload = map[string]any{"code": "READ_FAILED"}
+			return resp
+		}
+		resp.Payload = protocol.QuickWritablePayload{UID: uid, Writable: writable}
+	case server.WSMessageTypeRescan:
+		card, err := reader.Rescan()
+		if err != nil {
+			resp.Error = err.Error()
+			resp.Payload = map[string]any{"code": "READ_FAILED"}
+			return resp
+		}
+		resp.Payload = rescanPayload(card)
+	case server.WSMessageTypeGetCapabilities:
+		caps, connected := reader.DeviceCapabilities()
+		resp.Payload = protocol.CapabilitiesPayload{
+			Connected:         connected,
+			DeviceType:        caps.DeviceType,
+			SupportedTagTypes: caps.SupportedTagTypes,
+			CanTransceive:     caps.CanTransceive,
+			CanPoll:           caps.CanPoll,
+			SupportsEvents:    caps.SupportsEvents,
+		}
+	case server.WSMessageTypeGetATR:
+		atr, err := reader.ReadATR()
+		if err != nil {
+			resp.Error = err.Error()
+			resp.Payload = map[string]any{"code": pageErrorCode(err, "READ_FAILED")}
+			return resp
+		}
+		resp.Payload = atrPayload(atr)
+	case server.WSMessageTypeCaptureReference:
+		result, err := reader.ReadWithOptions(nfc.ReadOptions{})
+		if err != nil {
+			resp.Error = err.Error()
+			resp.Payload = map[string]any{"code": "READ_FAILED"}
+			return resp
+		}
+		resp.Payload = readCardPayload(result)
+	case server.WSMessageTypeCompareToReference:
+		referenceHex, _ := msg.Payload["reference"].(string)
+		reference, err := hex.DecodeString(referenceHex)
+		if err != nil {
+			resp.Error = "reference must be hex"
+			resp.Payload = map[string]any{"code": "INVALID_REQUEST"}
+			return resp
+		}
+		mode, _ := msg.Payload["mode"].(string)
+		if mode == "" {
+			mode = "bytes"
+		}
+		if mode != "bytes" && mode != "records" {
+			resp.Error = fmt.Sprintf("unknown comparison mode %q (expected bytes or records)", mode)
+			resp.Payload = map[string]any{"code": "INVALID_REQUEST"}
+			return resp
+		}
+		result, err := reader.ReadWithOptions(nfc.ReadOptions{})
+		if err != nil {
+			resp.Error = err.Error()
+			resp.Payload = map[string]any{"code": "READ_FAILED"}
+			return resp
+		}
+		referenceUID, _ := msg.Payload["referenceUid"].(string)
+		resp.Payload = compareReferencePayload(result, reference, referenceUID, mode)
+	case server.WSMessageTypeListTags:
+		infos, err := reader.ListTags()
+		if err != nil {
+			resp.Error = err.Error()
+			resp.Payload = map[string]any{"code": "READ_FAILED"}
+			return resp
+		}
+		payload := protocol.ListTagsPayload{Tags: []protocol.CardInfoPayload{}}
+		for _, info := range infos {
+			payload.Tags = append(payload.Tags, cardInfoPayload(info))
+		}
+		resp.Payload = payload
+	case server.WSMessageTypeWaitForCard:
+		timeoutMs := server.DefaultWaitForCardTimeoutMs
+		if t, ok := msg.Payload["timeout"].(float64); ok && t > 0 {
+			timeoutMs = min(int(t), server.MaxWaitForCardTimeoutMs)
+		}
+		card, err := reader.WaitForCard(time.Duration(timeoutMs) * time.Millisecond)
+		if err != nil {
+			resp.Error = err.Error()
+			resp.Payload = map[string]any{"code": waitForCardErrorCode(err)}
+			return resp
+		}
+		resp.Payload = waitForCardPayload(card, s.config.TimestampFormat)
+	case server.WSMessageTypeGetWearStats:
+		uid, _ := msg.Payload["uid"].(string)
+		if strings.TrimSpace(uid) == "" {
+			resp.Error = "uid is required"
+			resp.Payload = map[string]any{"code": "INVALID_REQUEST"}
+			return resp
+		}
+		resp.Payload = wearStatsPayload(reader.WearStats(uid))
+	case server.WSMessageTypeGetLatencyStats:
+		resp.Payload = latencyStatsPayload(reader.LatencyStats())
+	case server.WSMessageTypeGetAllowedTypes:
+		resp.Payload = allowedTypesPayload(reader.AllowedCardTypes())
+	case server.WSMessageTypeSetAllowedTypes:
+		types, err := parseAllowedTypes(msg.Payload["types"])
+		if err != nil {
+			resp.Error = err.Error()
+			resp.Payload = map[string]any{"code": "INVALID_REQUEST"}
+			if errors.Is(err, errUnknownCardType) {
+				resp.Payload = map[string]any{"code": "UNKNOWN_CARD_TYPE"}
+			}
+			return resp
+		}
+		reader.SetAllowedCardTypes(types)
+		if s.config.Readers != nil {
+			s.config.Readers.SetAllowedCardTypes(types)
+		}
+		if s.config.OnAllowedCardTypesChange != nil {
+			s.config.OnAllowedCardTypesChange(types)
+		}
+		log.Printf("Allowed card types changed: %v", reader.AllowedCardTypes())
+		resp.Payload = allowedTypesPayload(reader.AllowedCardTypes())
+	case server.WSMessageTypeReadRange:
+		offset, _ := msg.Payload["offset"].(float64)
+		length := server.DefaultReadRangeLength
+		if l, ok := msg.Payload["length"].(float64); ok && l > 0 {
+			length = min(int(l), server.MaxReadRangeLength)
+		}
+		encoding, _ := msg.Payload["encoding"].(string)
+		if encoding == "" {
+			encoding = "hex"
+		}
+		if encoding != "hex" && encoding != "base64" {
+			resp.Error = fmt.Sprintf("unsupported encoding %q (use hex or base64)", encoding)
+			resp.Payload = map[string]any{"code": "INVALID_REQUEST"}
+			return resp
+		}
+		if offset < 0 {
+			resp.Error = "offset must not be negative"
+			resp.Payload = map[string]any{"code": "INVALID_REQUEST"}
+			return resp
+		}
+
+		data, err := reader.ReadNDEFRange(int(offset), length)
+		if err != nil {
+			code := "READ_FAILED"
+			if nfc.IsNotSupportedError(err) {
+				code = "NOT_SUPPORTED"
+			}
+			resp.Error = err.Error()
+			resp.Payload = map[string]any{"code": code}
+			return resp
+		}
+		resp.Payload = readRangePayload(int(offset), data, encoding)
+	case server.WSMessageTypeReadMAD:
+		entries, err := reader.ReadMADInfo()
+		if err != nil && !(errors.Is(err, nfc.ErrMADCRC) && len(entries) > 0) {
+			resp.Error = err.Error()
+			resp.Payload = map[string]any{"code": pageErrorCode(err, "READ_FAILED")}
+			return resp
+		}
+		resp.Payload = madPayload(entries, err)
+	case server.WSMessageTypeReadPages:
+		start, _ := msg.Payload["start"].(float64)
+		count, _ := msg.Payload["count"].(float64)
+		data, err := reader.ReadPages(int(start), int(count))
+		if err != nil {
+			resp.Error = err.Error()
+			resp.Payload = map[string]any{"code": pageErrorCode(err, "READ_FAILED")}
+			return resp
+		}
+		resp.Payload = protocol.PagesPayload{
+			Start: int(start),
+			Count: int(count),
+			Data:  strings.ToUpper(hex.EncodeToString(data)),
+		}
+	case server.WSMessageTypeReadRecord:
+		req, err := recordRead(msg.Payload)
+		if err != nil {
+			resp.Error = err.Error()
+			resp.Payload = map[string]any{"code": "INVALID_REQUEST"}
+			return resp
+		}
+		result, err := reader.ReadRecord(req)
+		if err != nil {
+			resp.Error = err.Error()
+			resp.Payload = map[string]any{"code": pageErrorCode(err, "READ_FAILED")}
+			return resp
+		}
+		resp.Payload = protocol.RecordPayload{
+			Step: result.Step,
+			Data: nfc.BytesToHex(result.Response.Data),
+			SW:   fmt.Sprintf("%04X", result.Response.StatusWord()),
+		}
+	case server.WSMessageTypeWritePage:
+		page, _ := msg.Payload["page"].(float64)
+		dataHex, _ := msg.Payload["data"].(string)
+		raw, err := hex.DecodeString(dataHex)
+		if err != nil || len(raw) != 4 {
+			resp.Error = "data must be 4 bytes of hex (8 characters)"
+			resp.Payload = map[string]any{"code": "INVALID_REQUEST"}
+			return resp
+		}
+		if err := reader.WritePage(int(page), [4]byte(raw)); err != nil {
+			resp.Error = err.Error()
+			resp.Payload = map[string]any{"code": pageErrorCode(err, "WRITE_FAILED")}
+			return resp
+		}
+		resp.Payload = protocol.PagesPayload{Start: int(page), Count: 1}
+	case server.WSMessageTypePause:
+		reader.Pause()
+		resp.Payload = map[string]any{"paused": true}
+	case server.WSMessageTypeResume:
+		reader.Resume()
+		resp.Payload = map[string]any{"paused": false}
+	case server.WSMessageTypeClearCache:
+		if err := reader.ClearCache(); err != nil {
+			code := "CLEAR_FAILED"
+			if errors.Is(err, nfc.ErrOperationInProgress) {
+				code = "OPERATION_IN_PROGRESS"
+			}
+			resp.Error = err.Error()
+			resp.Payload = map[string]any{"code": code}
+			return resp
+		}
+		resp.Payload = map[string]any{"message": "Tag cache cleared"}
+	case server.WSMessageTypeResetDevice:
+		if err := reader.ResetDevice(); err != nil {
+			code := "RESET_FAILED"
+			switch {
+			case errors.Is(err, nfc.ErrHardwareResetDisabled):
+				code = "RESET_DISABLED"
+			case nfc.IsNotSupportedError(err):
+				code = "NOT_SUPPORTED"
+			}
+			resp.Error = err.Error()
+			resp.Payload = map[string]any{"code": code}
+			return resp
+		}
+		resp.Payload = map[string]any{"message": "Reader reset"}
+	default:
+		resp.Error = fmt.Sprintf("Unsupported command: %s", msg.Type)
+		return resp
+	}
+
+	resp.Success = true
+	return resp
+}
+
+// waitForCardErrorCode maps WaitForCard errors to client error codes.
+func waitForCardErrorCode(err error) string {
+	switch {
+	case errors.Is(err, nfc.ErrCardRemovedDuringRead):
+		return "CARD_REMOVED"
+	case errors.Is(err, nfc.ErrWaitTimeout):
+		return "WAIT_TIMEOUT"
+	default:
+		return "READ_FAILED"
+	}
+}
+
+// wearStatsPayload converts write wear statistics into their wire format.
+func wearStatsPayload(stats nfc.WearStats) protocol.WearStatsPayload {
+	payload := protocol.WearStatsPayload{UID: stats.UID, Writes: stats.Writes}
+	if stats.LastWrite != nil {
+		payload.LastWrite = stats.LastWrite.Format(time.RFC3339)
+	}
+	return payload
+}
+
+func latencyStatsPayload(stats map[string]nfc.LatencyStats) protocol.LatencyStatsPayload {
+	ms := func(d time.Duration) float64 {
+		return float64(d.Microseconds()) / 1000
+	}
+	payload := protocol.LatencyStatsPayload{
+		WindowMs:   nfc.LatencyWindow.Milliseconds(),
+		Operations: make(map[string]protocol.OperationLatencies, len(stats)),
+	}
+	for kind, s := range stats {
+		payload.Operations[kind] = protocol.OperationLatencies{
+			Count: s.Count,
+			P50Ms: ms(s.P50),
+			P95Ms: ms(s.P95),
+			P99Ms: ms(s.P99),
+			MaxMs: ms(s.Max),
+		}
+	}
+	return payload
+}
+
+// errUnknownCardType is returned by parseAllowedTypes for names that are not
+// in nfc.GetAllCardTypes.
+var errUnknownCardType = errors.New("unknown card type")
+
+// allowedTypesPayload lists the allowed card types alongside every known one.
+func allowedTypesPayload(types []string) protocol.AllowedTypesPayload {
+	return protocol.AllowedTypesPayload{Types: types, Known: nfc.GetAllCardTypes()}
+}
+
+// parseAllowedTypes validates the types of a setAllowedTypes request. The
+// whole request is refused if any name is unknown, so a typo cannot silently
+// block every card.
+func parseAllowedTypes(raw any) (map[string]bool, error) {
+	list, ok := raw.([]any)
+	if !ok {
+		return nil, errors.New("types must be an array of card type names")
+	}
+
+	known := make(map[string]bool)
+	for _, cardType := range nfc.GetAllCardTypes() {
+		known[cardType] = true
+	}
+
+	types := make(map[string]bool, len(list))
+	var unknown []string
+	for _, item := range list {
+		cardType, ok := item.(string)
+		if !ok {
+			return nil, errors.New("types must be an array of card type names")
+		}
+		if !known[cardType] {
+			unknown = append(unknown, cardType)
+			continue
+		}
+		types[cardType] = true
+	}
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("%w: %s", errUnknownCardType, strings.Join(unknown, ", "))
+	}
+	return types, nil
+}
+
+// readRangePayload encodes a partial NDEF read in the requested encoding.
+func readRangePayload(offset int, data []byte, encoding string) protocol.ReadRangeResponsePayload {
+	encoded := strings.ToUpper(hex.EncodeToString(data))
+	if encoding == "base64" {
+		encoded = base64.StdEncoding.EncodeToString(data)
+	}
+
+	return protocol.ReadRangeResponsePayload{
+		Offset:   offset,
+		Length:   len(data),
+		Encoding: encoding,
+		Data:     encoded,
+	}
+}
+
+// writeErrorPayload maps a failed NDEF write to its error payload, which is
+// empty for errors without a specific code.
+func writeErrorPayload(err error) map[string]any {
+	payload := map[string]any{}
+	switch {
+	case errors.Is(err, nfc.ErrVerifyFailed):
+		payload["code"] = "VERIFY_FAILED"
+	case errors.Is(err, nfc.ErrUIDMismatch):
+		payload["code"] = "UID_MISMATCH"
+	case errors.Is(err, nfc.ErrDeviceCooldown):
+		payload = cooldownErrorPayload(err)
+	case errors.Is(err, nfc.ErrDeviceBusy):
+		payload["code"] = "DEVICE_BUSY"
+	}
+	return payload
+}
+
+// cooldownErrorPayload is the error payload for operations refused while the
+// reader is in cooldown, telling the client how long to wait.
+func cooldownErrorPayload(err error) map[string]any {
+	payload := map[string]any{"code": "DEVICE_COOLDOWN"}
+	var cooldown *nfc.CooldownError
+	if errors.As(err, &cooldown) {
+		payload["retryAfterMs"] = cooldown.Remaining.Milliseconds()
+	}
+	return payload
+}
+
+// rateLimitErrorPayload is the error payload for writes refused by the rate
+// limiter, telling the client how long to wait.
+func rateLimitErrorPayload(err error) map[string]any {
+	payload := map[string]any{"code": "RATE_LIMITED"}
+	var limited *RateLimitError
+	if errors.As(err, &limited) {
+		payload["retryAfterMs"] = limited.RetryAfter.Milliseconds()
+	}
+	return payload
+}
+
+// pageErrorCode maps raw page access errors to client error codes.
+func pageErrorCode(err error, fallback string) string {
+	switch {
+	case errors.Is(err, nfc.ErrPageOutOfRange):
+		return "PAGE_OUT_OF_RANGE"
+	case nfc.IsNotSupportedError(err):
+		return "NOT_SUPPORTED"
+	default:
+		return fallback
+	}
+}
+
+// recordRead decodes and checks a readRecord payload.
+func recordRead(payload map[string]any) (nfc.RecordRead, error) {
+	var req nfc.RecordRead
+
+	if aidHex, _ := payload["aid"].(string); aidHex != "" {
+		aid, err := hex.DecodeString(aidHex)
+		if err != nil || len(aid) < 5 || len(aid) > 16 {
+			return req, fmt.Errorf("aid must be 5-16 bytes of hex")
+		}
+		req.AID = aid
+	}
+
+	fids, _ := payload["fids"].([]any)
+	for _, v := range fids {
+		fidHex, _ := v.(string)
+		fid, err := hex.DecodeString(fidHex)
+		if err != nil || len(fid) != 2 {
+			return req, fmt.Errorf("each fid must be 2 bytes of hex (4 characters)")
+		}
+		req.FIDs = append(req.FIDs, fid)
+	}
+
+	record, _ := payload["record"].(float64)
+	if record < 1 || record > 254 || record != float64(int(record)) {
+		return req, fmt.Errorf("record must be 1-254")
+	}
+	req.Record = byte(record)
+
+	sfi, _ := payload["sfi"].(float64)
+	if sfi < 0 || sfi > 30 || sfi != float64(int(sfi)) {
+		return req, fmt.Errorf("sfi must be 0-30")
+	}
+	req.SFI = byte(sfi)
+
+	return req, nil
+}
+
+// waitForCardPayload describes the card returned by WaitForCard, encoding
+// scannedAt in format.
+func waitForCardPayload(card *nfc.Card, format server.TimestampFormat) map[string]any {
+	payload := map[string]any{
+		"uid":        card.UID,
+		"type":       card.Type,
+		"technology": card.Technology,
+		"text":       "",
+	}
+	server.SetTimestamp(payload, "scannedAt", card.ScannedAt, format)
+	if msg, err := card.ReadMessage(); err == nil {
+		if ndefMsg, ok := msg.(*nfc.NDEFMessage); ok {
+			payload["text"], _ = ndefMsg.GetText()
+			payload["message"] = ndefMsg.ToJSONMap()
+		}
+	}
+	return payload
+}
+
+// manufacturerBlockPayload converts block 0 data into its wire format.
+func manufacturerBlockPayload(info nfc.ManufacturerInfo) protocol.ManufacturerBlockPayload {
+	note := "Block 0 is read-only on genuine cards, but gen-1a \"magic\" cards allow rewriting it, so a valid BCC does not prove provenance."
+	if !info.BCCValid {
+		note = "BCC does not match the UID: block 0 was likely rewritten on a gen-1a \"magic\" card or is corrupted."
+	}
+
+	return protocol.ManufacturerBlockPayload{
+		UID:      nfc.BytesToHex(info.UID),
+		SAK:      nfc.BytesToHex(info.SAK),
+		ATQA:     nfc.BytesToHex(info.ATQA),
+		BCCValid: info.BCCValid,
+		Note:     note,
+	}
+}
+
+// madPayload converts MAD entries into their wire format. crcErr is the
+// ErrMADCRC mismatch reported alongside the entries, if any.
+func madPayload(entries []nfc.MADEntry, crcErr error) protocol.MADPayload {
+	payload := protocol.MADPayload{
+		Entries:  make([]protocol.MADEntryPayload, len(entries)),
+		CRCValid: crcErr == nil,
+	}
+	for i, e := range entries {
+		payload.Entries[i] = protocol.MADEntryPayload{
+			Sector:      e.Sector,
+			AID:         fmt.Sprintf("%04X", e.AID),
+			Application: e.Application(),
+		}
+	}
+	if crcErr != nil {
+		payload.Note = crcErr.Error()
+	}
+	return payload
+}
+
+// atrPayload decodes an ATR into its wire format.
+func atrPayload(atr []byte) protocol.ATRPayload {
+	info, err := nfc.ParseATR(atr)
+	payload := protocol.ATRPayload{
+		ATR:             strings.ToUpper(hex.EncodeToString(atr)),
+		TS:              fmt.Sprintf("%02X", info.TS),
+		T0:              fmt.Sprintf("%02X", info.T0),
+		InterfaceBytes:  make([]protocol.ATRInterfaceByte, len(info.InterfaceBytes)),
+		Protocols:       make([]string, len(info.Protocols)),
+		HistoricalBytes: strings.ToUpper(hex.EncodeToString(info.Historical)),
+		Standard:        info.Standard,
+		CardName:        info.CardName,
+	}
+	for i, b := range info.InterfaceBytes {
+		payload.InterfaceBytes[i] = protocol.ATRInterfaceByte{Name: b.Name, Value: fmt.Sprintf("%02X", b.Value)}
+	}
+	for i, p := range info.Protocols {
+		payload.Protocols[i] = fmt.Sprintf("T=%d", p)
+	}
+	if info.HasTCK {
+		payload.TCK = fmt.Sprintf("%02X", info.TCK)
+		payload.TCKValid = &info.TCKValid
+	}
+	if info.Standard != "" {
+		payload.CardNameCode = fmt.Sprintf("%04X", info.CardNameCode)
+	}
+	if err != nil {
+		payload.Note = err.Error()
+	}
+	return payload
+}
+
+// cardInfoPayload converts card identification data into its wire format.
+func cardInfoPayload(info nfc.CardInfo) protocol.CardInfoPayload {
+	payload := protocol.CardInfoPayload{
+		UID:            info.UID,
+		Type:           info.Type,
+		Signature:      strings.ToUpper(hex.EncodeToString(info.Signature)),
+		Originality:    info.Originality,
+		ReaderModel:    info.Reader.Model,
+		ReaderFirmware: info.Reader.Firmware,
+	}
+	if m := info.Magic; m != nil {
+		payload.Magic = &m.Magic
+		payload.Block0Writable = &m.Block0Writable
+	}
+	if info.GPB != nil {
+		payload.GPB = make(map[int]string, len(info.GPB))
+		for sector, gpb := range info.GPB {
+			payload.GPB[sector] = fmt.Sprintf("%02X", gpb)
+		}
+	}
+	if df := info.DESFire; df != nil {
+		v := df.Version
+		payload.DESFire = &protocol.DESFireInfoPayload{
+			Variant:        v.Variant(),
+			Hardware:       desfireVersionPayload(v.Hardware),
+			Software:       desfireVersionPayload(v.Software),
+			UID:            strings.ToUpper(hex.EncodeToString(v.UID)),
+			BatchNo:        strings.ToUpper(hex.EncodeToString(v.BatchNo)),
+			ProductionWeek: v.ProductionWeek,
+			ProductionYear: v.ProductionYear,
+		}
+		if df.FreeMemory >= 0 {
+			free := df.FreeMemory
+			payload.DESFire.FreeMemory = &free
+		}
+	}
+	return payload
+}
+
+// readCardPayload converts a read result into its wire format.
+func readCardPayload(result nfc.ReadResult) protocol.ReadCardPayload {
+	payload := protocol.ReadCardPayload{
+		UID:            result.UID,
+		Type:           result.Type,
+		Data:           strings.ToUpper(hex.EncodeToString(result.Data)),
+		Partial:        result.Partial(),
+		SkippedSectors: []int{},
+	}
+	if result.Partial() {
+		payload.SkippedSectors = result.SkippedSectors
+	}
+	if result.Message != nil {
+		payload.Message = result.Message.ToJSONMap()
+		payload.Text, _ = result.Message.GetText()
+	}
+	return payload
+}
+
+// rescanPayload builds the rescan response from the freshly read card. Data
+// that does not decode as NDEF is returned raw, with its text.
+func rescanPayload(card *nfc.Card) protocol.ReadCardPayload {
+	payload := protocol.ReadCardPayload{
+		UID:            card.UID,
+		Type:           card.Type,
+		SkippedSectors: []int{},
+	}
+	switch msg := card.MessageData.(type) {
+	case *nfc.NDEFMessage:
+		data, _ := msg.Encode()
+		payload.Data = strings.ToUpper(hex.EncodeToString(data))
+		payload.Message = msg.ToJSONMap()
+		payload.Text, _ = msg.GetText()
+	case *nfc.TextMessage:
+		payload.Data = strings.ToUpper(hex.EncodeToString(msg.Bytes()))
+		payload.Text = msg.Text
+	}
+	return payload
+}
+
+// compareReferencePayload compares the message read from the card with the
+// reference, reporting the comparison mode selects as the match.
+func compareReferencePayload(result nfc.ReadResult, reference []byte, referenceUID, mode string) protocol.CompareReferencePayload {
+	cmp := nfc.CompareNDEF(result.Data, reference)
+	payload := protocol.CompareReferencePayload{
+		UID:               result.UID,
+		ReferenceUID:      referenceUID,
+		Mode:              mode,
+		Match:             cmp.BytesMatch,
+		BytesMatch:        cmp.BytesMatch,
+		RecordsMatch:      cmp.RecordsMatch,
+		MismatchedRecords: cmp.Mismatched,
+		Data:              strings.ToUpper(hex.EncodeToString(result.Data)),
+	}
+	if mode == "records" {
+		payload.Match = cmp.RecordsMatch
+	}
+	if payload.MismatchedRecords == nil {
+		payload.MismatchedRecords = []int{}
+	}
+	return payload
+}
+
+// normalizePayload re-encodes the message read from the card canonically.
+func normalizePayload(result nfc.ReadResult) (protocol.NormalizePayload, error) {
+	canonical := nfc.CanonicalNDEF(result.Message)
+	data, err := canonical.Encode()
+	if err != nil {
+		return protocol.NormalizePayload{}, err
+	}
+	return protocol.NormalizePayload{
+		UID:       result.UID,
+		Type:      result.Type,
+		Original:  strings.ToUpper(hex.EncodeToString(result.Data)),
+		Canonical: strings.ToUpper(hex.EncodeToString(data)),
+		Changed:   !bytes.Equal(result.Data, data),
+		Message:   canonical.ToJSONMap(),
+	}, nil
+}
+
+// newTrace creates an APDU trace for a client request, redacted if configured.
+func (s *Server) newTrace() *nfc.APDUTrace {
+	if s.config.RedactAPDUs {
+		return nfc.NewRedactedAPDUTrace()
+	}
+	return nfc.NewAPDUTrace()
+}
+
+// tracePayload converts a recorded APDU trace into its wire format.
+func tracePayload(trace *nfc.APDUTrace) []protocol.APDUTraceEntry {
+	entries := []protocol.APDUTraceEntry{}
+	for _, e := range trace.Entries() {
+		entry := protocol.APDUTraceEntry{
+			TX:         strings.ToUpper(hex.EncodeToString(e.TX)),
+			RX:         strings.ToUpper(hex.EncodeToString(e.RX)),
+			DurationUs: e.Duration.Microseconds(),
+		}
+		if e.Err != nil {
+			entry.Error = e.Err.Error()
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// desfireVersionPayload converts one half of a DESFire version into its wire format.
+func desfireVersionPayload(p nfc.DESFireVersionPart) protocol.DESFireVersionPayload {
+	return protocol.DESFireVersionPayload{
+		VendorID:     fmt.Sprintf("%02X", p.VendorID),
+		Type:         fmt.Sprintf("%02X", p.Type),
+		Subtype:      fmt.Sprintf("%02X", p.Subtype),
+		Version:      fmt.Sprintf("%d.%d", p.Major, p.Minor),
+		StorageBytes: p.StorageBytes(),
+		Protocol:     fmt.Sprintf("%02X", p.Protocol),
+	}
 }
 
 // enableCORS adds CORS headers.
@@ -332,23 +1390,61 @@ func (s *Server) enableCORS(next http.HandlerFunc) http.HandlerFunc {
 
 // startMDNS starts the mDNS service for auto-discovery.
 func (s *Server) startMDNS() error {
+	name := s.config.MDNSName
+	if name == "" {
+		name = server.DefaultMDNSInstanceName()
+	}
+
+	s.mdnsMux.Lock()
+	defer s.mdnsMux.Unlock()
+
 	var err error
 	s.mdnsServer, err = zeroconf.Register(
-		server.MDNSDeviceServiceName,
+		name,
 		server.MDNSDeviceServiceType,
 		server.MDNSDomain,
 		s.config.Port,
-		[]string{
-			"version=1.0",
-			"protocol=websocket",
-			"path=/ws",
-			"type=device",
-		},
+		s.mdnsText(),
 		nil,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to register mDNS service: %w", err)
 	}
-	log.Printf("[device] mDNS service registered: %s on port %d", server.MDNSDeviceServiceType, s.config.Port)
+	log.Printf("[device] mDNS service registered: %q (%s) on port %d", name, server.MDNSDeviceServiceType, s.config.Port)
 	return nil
 }
+
+// mdnsText returns the TXT records advertised over mDNS. The reader model and
+// firmware are only known once a reader has connected. Callers hold mdnsMux.
+func (s *Server) mdnsText() []string {
+	txt := []string{
+		"version=" + buildinfo.Version,
+		"protocol=websocket",
+		"path=/ws",
+		"type=device",
+	}
+	if s.config.AgentID != "" {
+		txt = append(txt, "id="+s.config.AgentID)
+	}
+	if s.mdnsReader.Model != "" {
+		txt = append(txt, "reader="+s.mdnsReader.Model)
+	}
+	if s.mdnsReader.Firmware != "" {
+		txt = append(txt, "firmware="+s.mdnsReader.Firmware)
+	}
+	return txt
+}
+
+// updateMDNSReader re-advertises the TXT records when the reader changes.
+func (s *Server) updateMDNSReader(info nfc.ReaderInfo) {
+	s.mdnsMux.Lock()
+	defer s.mdnsMux.Unlock()
+
+	if info == s.mdnsReader {
+		return
+	}
+	s.mdnsReader = info
+	if s.mdnsServer != nil {
+		s.mdnsServer.SetText(s.mdnsText())
+	}
+}
@@ -0,0 +1,593 @@
+package deviceserver
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dotside-studios/davi-nfc-agent/nfc"
+	"github.com/dotside-studios/davi-nfc-agent/protocol"
+	"github.com/dotside-studios/davi-nfc-agent/server"
+)
+
+// TestServer_WriteRaw tests that writeRaw writes client-built NDEF bytes
+// verbatim and refuses anything that is not a well-formed message.
+func TestServer_WriteRaw(t *testing.T) {
+	manager := nfc.NewMockManager()
+	manager.DevicesList = []string{"mock:usb:001"}
+	tag := nfc.NewMockTag("04A1B2C3")
+	tag.IsConnected = true
+	tag.Data = nfc.EncodeNdefMessageWithTextRecord("Hello", "en")
+	device := nfc.NewMockDevice()
+	device.SetTags([]nfc.Tag{tag})
+	manager.MockDevice = device
+
+	reader, err := nfc.NewNFCReader("mock:usb:001", manager, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Failed to create NFCReader: %v", err)
+	}
+	defer reader.Close()
+
+	s := New(Config{Reader: reader}, server.NewServerBridge())
+	writeRaw := func(ndefHex string) server.CommandResponseMessage {
+		return s.executeCommand(server.CommandMessage{
+			Type:    server.WSMessageTypeWriteRaw,
+			Payload: map[string]any{"ndefHex": ndefHex},
+		})
+	}
+
+	for ndefHex, code := range map[string]string{
+		"D1010":              "INVALID_REQUEST",
+		"D101095402656E68":   "INVALID_NDEF", // Payload runs past the end
+		"D101045402656E6800": "INVALID_NDEF", // Trailing byte
+	} {
+		resp := writeRaw(ndefHex)
+		if got := resp.Payload.(map[string]any)["code"]; got != code {
+			t.Errorf("writeRaw(%s): code = %v, want %s (error: %s)", ndefHex, got, code, resp.Error)
+		}
+	}
+
+	if text, _ := nfc.ParseNdefMessageForTextRecord(tag.Data); text != "Hello" {
+		t.Fatalf("Card was written despite invalid input: % X", tag.Data)
+	}
+
+	// A long-form record, which a re-encoded message would store as a short one
+	raw := []byte{0xC1, 0x01, 0x00, 0x00, 0x00, 0x04, 'T', 0x02, 'e', 'n', 'h'}
+	if resp := writeRaw(hex.EncodeToString(raw)); resp.Error != "" {
+		t.Fatalf("writeRaw() error = %s", resp.Error)
+	}
+	if !bytes.Equal(tag.Data, raw) {
+		t.Errorf("Card data = % X, want % X", tag.Data, raw)
+	}
+}
+
+// TestServer_WriteLocalizedText tests that writeLocalizedText validates every
+// language before writing one text record per language.
+func TestServer_WriteLocalizedText(t *testing.T) {
+	manager := nfc.NewMockManager()
+	manager.DevicesList = []string{"mock:usb:001"}
+	tag := nfc.NewMockTag("04A1B2C3")
+	tag.IsConnected = true
+	tag.Data = nfc.EncodeNdefMessageWithTextRecord("Hello", "en")
+	device := nfc.NewMockDevice()
+	device.SetTags([]nfc.Tag{tag})
+	manager.MockDevice = device
+
+	reader, err := nfc.NewNFCReader("mock:usb:001", manager, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Failed to create NFCReader: %v", err)
+	}
+	defer reader.Close()
+
+	s := New(Config{Reader: reader}, server.NewServerBridge())
+	writeTexts := func(texts map[string]any) server.CommandResponseMessage {
+		return s.executeCommand(server.CommandMessage{
+			Type:    server.WSMessageTypeWriteLocalizedText,
+			Payload: map[string]any{"texts": texts},
+		})
+	}
+
+	for _, texts := range []map[string]any{
+		{},
+		{"en": "Hello", "english": "Hello"},
+		{"en": 42},
+	} {
+		resp := writeTexts(texts)
+		if got := resp.Payload.(map[string]any)["code"]; got != "INVALID_REQUEST" {
+			t.Errorf("writeLocalizedText(%v): code = %v, want INVALID_REQUEST (error: %s)", texts, got, resp.Error)
+		}
+	}
+	if text, _ := nfc.ParseNdefMessageForTextRecord(tag.Data); text != "Hello" {
+		t.Fatalf("Card was written despite invalid input: % X", tag.Data)
+	}
+
+	resp := writeTexts(map[string]any{"en": "Hello", "fr": "Bonjour", "es": "Hola"})
+	if resp.Error != "" {
+		t.Fatalf("writeLocalizedText() error = %s", resp.Error)
+	}
+	want, _ := nfc.NewNDEFMessage().AddText("Hello", "en").AddText("Hola", "es").AddText("Bonjour", "fr").Encode()
+	if !bytes.Equal(tag.Data, want) {
+		t.Errorf("Card data = % X, want % X", tag.Data, want)
+	}
+}
+
+// TestServer_CheckWrite tests that checkWrite reports every reason a write
+// would fail without writing.
+func TestServer_CheckWrite(t *testing.T) {
+	manager := nfc.NewMockManager()
+	manager.DevicesList = []string{"mock:usb:001"}
+	tag := nfc.NewMockTag("04A1B2C3")
+	tag.IsConnected = true
+	tag.TagType = "NTAG213"
+	tag.Data = nfc.EncodeNdefMessageWithTextRecord("Hello", "en")
+	device := nfc.NewMockDevice()
+	device.SetTags([]nfc.Tag{tag})
+	manager.MockDevice = device
+
+	reader, err := nfc.NewNFCReader("mock:usb:001", manager, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Failed to create NFCReader: %v", err)
+	}
+	defer reader.Close()
+
+	s := New(Config{Reader: reader}, server.NewServerBridge())
+	checkWrite := func(content string) server.CommandResponseMessage {
+		return s.executeCommand(server.CommandMessage{
+			Type: server.WSMessageTypeCheckWrite,
+			Payload: map[string]any{
+				"records":        []any{map[string]any{"type": "text", "content": content}},
+				"lockAfterWrite": true,
+			},
+		})
+	}
+
+	resp := checkWrite("Hi")
+	if payload, _ := resp.Payload.(protocol.CheckWritePayload); resp.Error != "" || !payload.OK || len(payload.Reasons) != 0 {
+		t.Errorf("checkWrite() = %+v, error %q; want ok", resp.Payload, resp.Error)
+	}
+
+	tag.IsReadOnly = true
+	resp = checkWrite(strings.Repeat("A", 200))
+	payload, _ := resp.Payload.(protocol.CheckWritePayload)
+	if resp.Error != "" || payload.OK || len(payload.Reasons) != 3 {
+		t.Errorf("checkWrite() = %+v, error %q; want three reasons", resp.Payload, resp.Error)
+	}
+	if text, _ := nfc.ParseNdefMessageForTextRecord(tag.Data); text != "Hello" {
+		t.Errorf("checkWrite() changed the card: % X", tag.Data)
+	}
+
+	resp = s.executeCommand(server.CommandMessage{
+		Type:    server.WSMessageTypeCheckWrite,
+		Payload: map[string]any{"records": []any{}},
+	})
+	if got := resp.Payload.(map[string]any)["code"]; got != "INVALID_REQUEST" {
+		t.Errorf("checkWrite() without records: code = %v, want INVALID_REQUEST", got)
+	}
+}
+
+// TestServer_QuickWritable tests that quickWritable reports the card's
+// writability, and false in read-only mode.
+func TestServer_QuickWritable(t *testing.T) {
+	manager := nfc.NewMockManager()
+	manager.DevicesList = []string{"mock:usb:001"}
+	tag := nfc.NewMockTag("04A1B2C3")
+	tag.IsConnected = true
+	tag.TagType = "NTAG213"
+	device := nfc.NewMockDevice()
+	device.SetTags([]nfc.Tag{tag})
+	manager.MockDevice = device
+
+	reader, err := nfc.NewNFCReader("mock:usb:001", manager, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Failed to create NFCReader: %v", err)
+	}
+	defer reader.Close()
+
+	s := New(Config{Reader: reader}, server.NewServerBridge())
+	quickWritable := func() protocol.QuickWritablePayload {
+		t.Helper()
+		resp := s.executeCommand(server.CommandMessage{Type: server.WSMessageTypeQuickWritable})
+		if resp.Error != "" {
+			t.Fatalf("quickWritable() error = %s", resp.Error)
+		}
+		payload, _ := resp.Payload.(protocol.QuickWritablePayload)
+		return payload
+	}
+
+	if got := quickWritable(); got.UID != "04A1B2C3" || !got.Writable {
+		t.Errorf("quickWritable() = %+v, want writable 04A1B2C3", got)
+	}
+
+	tag.IsReadOnly = true
+	if got := quickWritable(); got.Writable {
+		t.Error("quickWritable() reported a read-only card as writable")
+	}
+
+	tag.IsReadOnly = false
+	reader.SetMode(nfc.ModeReadOnly)
+	if got := quickWritable(); got.Writable {
+		t.Error("quickWritable() reported writable in read-only mode")
+	}
+
+	device.SetTags(nil)
+	resp := s.executeCommand(server.CommandMessage{Type: server.WSMessageTypeQuickWritable})
+	if code, _ := resp.Payload.(map[string]any)["code"]; code != "READ_FAILED" {
+		t.Errorf("quickWritable() without a card: code = %v, want READ_FAILED", code)
+	}
+}
+
+// TestServer_WriteAndRead tests that writeAndRead returns the card as read
+// back, and names the stage that failed.
+func TestServer_WriteAndRead(t *testing.T) {
+	manager := nfc.NewMockManager()
+	manager.DevicesList = []string{"mock:usb:001"}
+	tag := nfc.NewMockTag("04A1B2C3")
+	tag.IsConnected = true
+	device := nfc.NewMockDevice()
+	device.SetTags([]nfc.Tag{tag})
+	manager.MockDevice = device
+
+	reader, err := nfc.NewNFCReader("mock:usb:001", manager, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Failed to create NFCReader: %v", err)
+	}
+	defer reader.Close()
+
+	s := New(Config{Reader: reader}, server.NewServerBridge())
+	writeAndRead := func(content string) server.CommandResponseMessage {
+		return s.executeCommand(server.CommandMessage{
+			Type:    server.WSMessageTypeWriteAndRead,
+			Payload: map[string]any{"records": []any{map[string]any{"type": "text", "content": content}}},
+		})
+	}
+
+	resp := writeAndRead("Enrolled")
+	payload, _ := resp.Payload.(protocol.ReadCardPayload)
+	if resp.Error != "" || payload.UID != "04A1B2C3" || payload.Text != "Enrolled" {
+		t.Fatalf("writeAndRead() = %+v, error %q; want the written text", resp.Payload, resp.Error)
+	}
+
+	tag.ReadDataError = errors.New("read timeout")
+	resp = writeAndRead("Again")
+	if p, _ := resp.Payload.(map[string]any); p["code"] != "READ_BACK_FAILED" || p["stage"] != "read" {
+		t.Errorf("writeAndRead() with a failing read = %v, want READ_BACK_FAILED at the read stage", resp.Payload)
+	}
+	if text, _ := nfc.ParseNdefMessageForTextRecord(tag.Data); text != "Again" {
+		t.Errorf("Card text = %q, want the write to have gone through", text)
+	}
+
+	tag.ReadDataError = nil
+	tag.WriteDataError = errors.New("write timeout")
+	resp = writeAndRead("Third")
+	if p, _ := resp.Payload.(map[string]any); p["code"] != "WRITE_FAILED" || p["stage"] != "write" {
+		t.Errorf("writeAndRead() with a failing write = %v, want WRITE_FAILED at the write stage", resp.Payload)
+	}
+}
+
+// TestServer_GetATR tests the ATR decode and that backends without an ATR
+// answer NOT_SUPPORTED.
+func TestServer_GetATR(t *testing.T) {
+	payload := atrPayload([]byte{0x3B, 0x8F, 0x80, 0x01, 0x80, 0x4F, 0x0C, 0xA0, 0x00, 0x00, 0x03, 0x06, 0x03, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00, 0x6A})
+	if payload.ATR != "3B8F8001804F0CA000000306030001000000006A" || payload.CardName != "MIFARE Classic 1K" || payload.CardNameCode != "0001" {
+		t.Errorf("atrPayload() = %+v", payload)
+	}
+	if !reflect.DeepEqual(payload.Protocols, []string{"T=0", "T=1"}) || payload.TCK != "6A" || payload.TCKValid == nil || !*payload.TCKValid {
+		t.Errorf("atrPayload() protocols %v, TCK %q; want T=0, T=1 and a valid 6A", payload.Protocols, payload.TCK)
+	}
+
+	manager := nfc.NewMockManager()
+	manager.DevicesList = []string{"mock:usb:001"}
+	reader, err := nfc.NewNFCReader("mock:usb:001", manager, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Failed to create NFCReader: %v", err)
+	}
+	defer reader.Close()
+
+	s := New(Config{Reader: reader}, server.NewServerBridge())
+	resp := s.executeCommand(server.CommandMessage{Type: server.WSMessageTypeGetATR})
+	if code := resp.Payload.(map[string]any)["code"]; code != "NOT_SUPPORTED" || !strings.Contains(resp.Error, "not available for the mock backend") {
+		t.Errorf("getATR() = code %v, error %q; want NOT_SUPPORTED for the mock backend", code, resp.Error)
+	}
+}
+
+// TestServer_ReadRecord tests that readRecord validates its payload and
+// reports the card's status word.
+func TestServer_ReadRecord(t *testing.T) {
+	manager := nfc.NewMockManager()
+	manager.DevicesList = []string{"mock:usb:001"}
+	tag := nfc.NewMockISO14443Tag("04A1B2C3D4E5F6")
+	tag.IsConnected = true
+	tag.Applications = map[string]bool{"A0000002471001": true}
+	tag.Records = map[byte][][]byte{2: {{0xCA, 0xFE}}}
+	device := nfc.NewMockDevice()
+	device.SetTags([]nfc.Tag{tag})
+	manager.MockDevice = device
+
+	reader, err := nfc.NewNFCReader("mock:usb:001", manager, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Failed to create NFCReader: %v", err)
+	}
+	defer reader.Close()
+
+	s := New(Config{Reader: reader}, server.NewServerBridge())
+	readRecord := func(payload map[string]any) server.CommandResponseMessage {
+		return s.executeCommand(server.CommandMessage{Type: server.WSMessageTypeReadRecord, Payload: payload})
+	}
+
+	for _, payload := range []map[string]any{
+		{"record": float64(0)},
+		{"record": float64(1), "sfi": float64(31)},
+		{"record": float64(1), "aid": "A000"},
+		{"record": float64(1), "fids": []any{"011E00"}},
+	} {
+		resp := readRecord(payload)
+		if got := resp.Payload.(map[string]any)["code"]; got != "INVALID_REQUEST" {
+			t.Errorf("readRecord(%v): code = %v, want INVALID_REQUEST", payload, got)
+		}
+	}
+
+	resp := readRecord(map[string]any{"aid": "A0000002471001", "record": float64(1), "sfi": float64(2)})
+	if resp.Error != "" {
+		t.Fatalf("readRecord() error = %s", resp.Error)
+	}
+	want := protocol.RecordPayload{Step: nfc.RecordStepReadRecord, Data: "CAFE", SW: "9000"}
+	if resp.Payload != want {
+		t.Errorf("readRecord() payload = %+v, want %+v", resp.Payload, want)
+	}
+
+	resp = readRecord(map[string]any{"aid": "A0000002471002", "record": float64(1)})
+	want = protocol.RecordPayload{Step: nfc.RecordStepSelectAID, Data: "", SW: "6A82"}
+	if resp.Error != "" || resp.Payload != want {
+		t.Errorf("readRecord() with unknown AID = %+v (%s), want %+v", resp.Payload, resp.Error, want)
+	}
+}
+
+// TestServer_AllowedTypes tests that clients can read and replace the card
+// type filter, and that unknown type names are refused.
+func TestServer_AllowedTypes(t *testing.T) {
+	manager := nfc.NewMockManager()
+	reader, err := nfc.NewNFCReader("mock:usb:001", manager, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Failed to create NFCReader: %v", err)
+	}
+	defer reader.Close()
+
+	var changed map[string]bool
+	s := New(Config{
+		Reader:                   reader,
+		AllowedCardTypes:         map[string]bool{nfc.CardTypeNtag215: true},
+		OnAllowedCardTypesChange: func(types map[string]bool) { changed = types },
+	}, server.NewServerBridge())
+	command := func(msgType string, payload map[string]any) server.CommandResponseMessage {
+		return s.executeCommand(server.CommandMessage{Type: msgType, Payload: payload})
+	}
+
+	resp := command(server.WSMessageTypeGetAllowedTypes, nil)
+	got, ok := resp.Payload.(protocol.AllowedTypesPayload)
+	if !ok || !reflect.DeepEqual(got.Types, []string{nfc.CardTypeNtag215}) || len(got.Known) != len(nfc.GetAllCardTypes()) {
+		t.Fatalf("getAllowedTypes payload = %+v", resp.Payload)
+	}
+
+	for code, payload := range map[string]map[string]any{
+		"INVALID_REQUEST":   {"types": "NTAG215"},
+		"UNKNOWN_CARD_TYPE": {"types": []any{nfc.CardTypeNtag213, "NTAG999"}},
+	} {
+		resp := command(server.WSMessageTypeSetAllowedTypes, payload)
+		if resp.Error == "" || resp.Payload.(map[string]any)["code"] != code {
+			t.Errorf("setAllowedTypes(%v) = %+v, want %s", payload, resp, code)
+		}
+	}
+	if types := reader.AllowedCardTypes(); !reflect.DeepEqual(types, []string{nfc.CardTypeNtag215}) || changed != nil {
+		t.Fatalf("Refused request changed the filter to %v", types)
+	}
+
+	resp = command(server.WSMessageTypeSetAllowedTypes, map[string]any{"types": []any{nfc.CardTypeNtag216, nfc.CardTypeNtag213}})
+	want := []string{nfc.CardTypeNtag213, nfc.CardTypeNtag216}
+	if resp.Error != "" || !reflect.DeepEqual(resp.Payload.(protocol.AllowedTypesPayload).Types, want) {
+		t.Fatalf("setAllowedTypes() = %+v, want types %v", resp, want)
+	}
+	if types := reader.AllowedCardTypes(); !reflect.DeepEqual(types, want) {
+		t.Errorf("Reader filter = %v, want %v", types, want)
+	}
+	if !changed[nfc.CardTypeNtag213] || !changed[nfc.CardTypeNtag216] || len(changed) != 2 {
+		t.Errorf("OnAllowedCardTypesChange got %v", changed)
+	}
+
+	resp = command(server.WSMessageTypeSetAllowedTypes, map[string]any{"types": []any{}})
+	if resp.Error != "" || len(reader.AllowedCardTypes()) != 0 {
+		t.Errorf("Empty types should allow every card, got %+v", resp)
+	}
+}
+
+// TestServer_Normalize tests that normalize returns the card's message and
+// its canonical encoding without writing to the card.
+func TestServer_Normalize(t *testing.T) {
+	manager := nfc.NewMockManager()
+	manager.DevicesList = []string{"mock:usb:001"}
+	tag := nfc.NewMockTag("04A1B2C3")
+	tag.IsConnected = true
+	// A long-form URI record without prefix abbreviation
+	tag.Data = append([]byte{0xC1, 0x01, 0x00, 0x00, 0x00, 0x14, 'U', 0x00}, "https://example.com"...)
+	original := append([]byte(nil), tag.Data...)
+	device := nfc.NewMockDevice()
+	device.SetTags([]nfc.Tag{tag})
+	manager.MockDevice = device
+
+	reader, err := nfc.NewNFCReader("mock:usb:001", manager, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Failed to create NFCReader: %v", err)
+	}
+	defer reader.Close()
+	time.Sleep(100 * time.Millisecond)
+
+	s := New(Config{Reader: reader}, server.NewServerBridge())
+	resp := s.executeCommand(server.CommandMessage{Type: server.WSMessageTypeNormalize})
+	if resp.Error != "" {
+		t.Fatalf("normalize error = %s", resp.Error)
+	}
+
+	payload := resp.Payload.(protocol.NormalizePayload)
+	if payload.Original != strings.ToUpper(hex.EncodeToString(original)) {
+		t.Errorf("Original = %s, want % X", payload.Original, original)
+	}
+	if want := "D1010C55046578616D706C652E636F6D"; payload.Canonical != want {
+		t.Errorf("Canonical = %s, want %s", payload.Canonical, want)
+	}
+	if !payload.Changed {
+		t.Error("Changed = false, want true")
+	}
+	if !bytes.Equal(tag.Data, original) {
+		t.Errorf("Card was written: % X", tag.Data)
+	}
+}
+
+func TestServer_CompareToReference(t *testing.T) {
+	manager := nfc.NewMockManager()
+	manager.DevicesList = []string{"mock:usb:001"}
+	tag := nfc.NewMockTag("04A1B2C3")
+	tag.IsConnected = true
+	tag.Data = []byte{0xD1, 0x01, 0x05, 'T', 0x02, 'e', 'n', 'H', 'i'}
+	device := nfc.NewMockDevice()
+	device.SetTags([]nfc.Tag{tag})
+	manager.MockDevice = device
+
+	reader, err := nfc.NewNFCReader("mock:usb:001", manager, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Failed to create NFCReader: %v", err)
+	}
+	defer reader.Close()
+	time.Sleep(100 * time.Millisecond)
+
+	s := New(Config{Reader: reader}, server.NewServerBridge())
+
+	resp := s.executeCommand(server.CommandMessage{Type: server.WSMessageTypeCaptureReference})
+	if resp.Error != "" {
+		t.Fatalf("captureReference error = %s", resp.Error)
+	}
+	captured := resp.Payload.(protocol.ReadCardPayload)
+	if captured.Data != "D101055402656E4869" {
+		t.Fatalf("Captured data = %s", captured.Data)
+	}
+
+	tests := []struct {
+		name         string
+		reference    string
+		mode         string
+		match        bool
+		recordsMatch bool
+		mismatched   []int
+	}{
+		{"identical", captured.Data, "", true, true, []int{}},
+		{"long form, bytes", "C101000000055402656E4869", "bytes", false, true, []int{}},
+		{"long form, records", "C101000000055402656E4869", "records", true, true, []int{}},
+		{"other text", "D101055402656E486F", "records", false, false, []int{0}},
+		{"extra record", "9101055402656E48695101055402656E4869", "records", false, false, []int{1}},
+	}
+	for _, tt := range tests {
+		resp := s.executeCommand(server.CommandMessage{
+			Type:    server.WSMessageTypeCompareToReference,
+			Payload: map[string]any{"reference": tt.reference, "referenceUid": "04DDEEFF", "mode": tt.mode},
+		})
+		if resp.Error != "" {
+			t.Fatalf("%s: compareToReference error = %s", tt.name, resp.Error)
+		}
+		payload := resp.Payload.(protocol.CompareReferencePayload)
+		if payload.Match != tt.match || payload.RecordsMatch != tt.recordsMatch || !slices.Equal(payload.MismatchedRecords, tt.mismatched) {
+			t.Errorf("%s: got %+v", tt.name, payload)
+		}
+		if payload.UID != "04A1B2C3" || payload.ReferenceUID != "04DDEEFF" {
+			t.Errorf("%s: UIDs = %s, %s", tt.name, payload.UID, payload.ReferenceUID)
+		}
+	}
+
+	resp = s.executeCommand(server.CommandMessage{
+		Type:    server.WSMessageTypeCompareToReference,
+		Payload: map[string]any{"reference": captured.Data, "mode": "fuzzy"},
+	})
+	if code := resp.Payload.(map[string]any)["code"]; code != "INVALID_REQUEST" {
+		t.Errorf("Unknown mode code = %v, want INVALID_REQUEST", code)
+	}
+}
+
+func TestServer_Rescan(t *testing.T) {
+	manager := nfc.NewMockManager()
+	manager.DevicesList = []string{"mock:usb:001"}
+	tag := nfc.NewMockTag("04A1B2C3")
+	tag.IsConnected = true
+	tag.Data = nfc.EncodeNdefMessageWithTextRecord("Hello", "en")
+	device := nfc.NewMockDevice()
+	device.SetTags([]nfc.Tag{tag})
+	manager.MockDevice = device
+
+	reader, err := nfc.NewNFCReader("mock:usb:001", manager, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Failed to create NFCReader: %v", err)
+	}
+	defer reader.Close()
+
+	s := New(Config{Reader: reader}, server.NewServerBridge())
+	resp := s.executeCommand(server.CommandMessage{Type: server.WSMessageTypeRescan})
+	if resp.Error != "" {
+		t.Fatalf("rescan() error = %s", resp.Error)
+	}
+	payload, _ := resp.Payload.(protocol.ReadCardPayload)
+	if payload.UID != "04A1B2C3" || payload.Text != "Hello" || payload.Message == nil {
+		t.Errorf("rescan() = %+v, want card 04A1B2C3 with text Hello", payload)
+	}
+
+	device.SetTags(nil)
+	resp = s.executeCommand(server.CommandMessage{Type: server.WSMessageTypeRescan})
+	if code, _ := resp.Payload.(map[string]any)["code"]; code != "READ_FAILED" {
+		t.Errorf("rescan() without a card: code = %v, want READ_FAILED", code)
+	}
+}
+
+func TestServer_GetCapabilities(t *testing.T) {
+	manager := nfc.NewMockManager()
+	manager.DevicesList = []string{"mock:usb:001"}
+	device := nfc.NewMockDevice()
+	device.MockSupportedTagTypes = []string{"DESFire"}
+	manager.MockDevice = device
+
+	reader, err := nfc.NewNFCReader("mock:usb:001", manager, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Failed to create NFCReader: %v", err)
+	}
+	defer reader.Close()
+
+	s := New(Config{Reader: reader}, server.NewServerBridge())
+	resp := s.executeCommand(server.CommandMessage{Type: server.WSMessageTypeGetCapabilities})
+	payload, _ := resp.Payload.(protocol.CapabilitiesPayload)
+	if !payload.Connected || payload.DeviceType != "mock" || len(payload.SupportedTagTypes) != 1 || payload.SupportedTagTypes[0] != "DESFire" {
+		t.Errorf("getCapabilities() = %+v, want the mock device's DESFire support", payload)
+	}
+
+	reader.Close()
+	resp = s.executeCommand(server.CommandMessage{Type: server.WSMessageTypeGetCapabilities})
+	payload, _ = resp.Payload.(protocol.CapabilitiesPayload)
+	if payload.Connected || len(payload.SupportedTagTypes) == 0 {
+		t.Errorf("getCapabilities() without a device = %+v, want disconnected defaults", payload)
+	}
+}
+
+// TestWaitForCardPayload_TimestampFormat tests that scannedAt follows the
+// configured timestamp format.
+func TestWaitForCardPayload_TimestampFormat(t *testing.T) {
+	card := nfc.NewCard(nfc.NewMockTag("04A1B2C3"))
+	card.ScannedAt = time.UnixMilli(1728218096000)
+
+	payload := waitForCardPayload(card, server.TimestampEpochMillis)
+	if payload["scannedAt"] != int64(1728218096000) {
+		t.Errorf("scannedAt = %v, want 1728218096000", payload["scannedAt"])
+	}
+
+	payload = waitForCardPayload(card, "")
+	if payload["scannedAt"] != card.ScannedAt.Format(time.RFC3339) {
+		t.Errorf("scannedAt = %v, want RFC 3339", payload["scannedAt"])
+	}
+}
@@ -0,0 +1,139 @@
+package server
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Event levels, inferred from the wording of log lines.
+const (
+	EventLevelInfo  = "info"
+	EventLevelWarn  = "warn"
+	EventLevelError = "error"
+)
+
+// DefaultEventLogSize is the number of events kept when no size is configured.
+const DefaultEventLogSize = 500
+
+// Event is one entry in the EventLog.
+type Event struct {
+	Time     time.Time `json:"time"`
+	Level    string    `json:"level"`
+	Category string    `json:"category"`
+	Message  string    `json:"message"`
+}
+
+// EventLog keeps the most recent events in a fixed-size ring so they can be
+// pulled over HTTP for troubleshooting. It implements io.Writer, so it can be
+// attached to a log.Logger alongside the normal output.
+type EventLog struct {
+	mu     sync.Mutex
+	events []Event
+	next   int  // index of the slot the next event goes into
+	full   bool // whether the ring has wrapped
+}
+
+// NewEventLog creates an event log holding up to size events.
+// A non-positive size uses DefaultEventLogSize.
+func NewEventLog(size int) *EventLog {
+	if size <= 0 {
+		size = DefaultEventLogSize
+	}
+	return &EventLog{events: make([]Event, size)}
+}
+
+// Add records an event, evicting the oldest one when the ring is full.
+func (l *EventLog) Add(e Event) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.events[l.next] = e
+	l.next = (l.next + 1) % len(l.events)
+	if l.next == 0 {
+		l.full = true
+	}
+}
+
+// Since returns events recorded strictly after t, oldest first.
+// A zero t returns every event in the ring.
+func (l *EventLog) Since(t time.Time) []Event {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	ordered := l.events[:l.next]
+	if l.full {
+		ordered = append(append([]Event{}, l.events[l.next:]...), l.events[:l.next]...)
+	}
+
+	result := []Event{}
+	for _, e := range ordered {
+		if t.IsZero() || e.Time.After(t) {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+// logTimestamp matches the date and time prefix written by log.LstdFlags.
+var logTimestamp = regexp.MustCompile(`^\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2}(\.\d+)? `)
+
+// logPrefix matches a component prefix such as "[client] " or "[agent] ".
+var logPrefix = regexp.MustCompile(`^\[(\w+)\] `)
+
+// Write records each log line in p as an event. It never fails, so a full or
+// misbehaving event log can't break the logger it is attached to.
+func (l *EventLog) Write(p []byte) (int, error) {
+	now := time.Now()
+	for _, line := range strings.Split(strings.TrimRight(string(p), "\n"), "\n") {
+		if e, ok := parseLogLine(line, now); ok {
+			l.Add(e)
+		}
+	}
+	return len(p), nil
+}
+
+// parseLogLine turns one log line into an event. The category is the
+// component prefix when there is one, otherwise it is guessed from the message.
+func parseLogLine(line string, at time.Time) (Event, bool) {
+	msg := logTimestamp.ReplaceAllString(line, "")
+	if strings.TrimSpace(msg) == "" {
+		return Event{}, false
+	}
+
+	category := ""
+	if m := logPrefix.FindStringSubmatch(msg); m != nil {
+		category = m[1]
+		msg = msg[len(m[0]):]
+	}
+
+	lower := strings.ToLower(msg)
+	if category == "" {
+		category = eventCategory(lower)
+	}
+
+	level := EventLevelInfo
+	switch {
+	case strings.Contains(lower, "error") || strings.Contains(lower, "failed"):
+		level = EventLevelError
+	case strings.Contains(lower, "warning"):
+		level = EventLevelWarn
+	}
+
+	return Event{Time: at, Level: level, Category: category, Message: msg}, true
+}
+
+// eventCategory guesses the category of an unprefixed log message.
+func eventCategory(lower string) string {
+	switch {
+	case strings.Contains(lower, "write") || strings.Contains(lower, "wrote"):
+		return "write"
+	case strings.Contains(lower, "uid") || strings.Contains(lower, "card"):
+		return "scan"
+	case strings.Contains(lower, "connect"):
+		return "connection"
+	default:
+		return "agent"
+	}
+}
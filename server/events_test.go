@@ -0,0 +1,60 @@
+package server
+
+import (
+	"log"
+	"testing"
+	"time"
+)
+
+func TestEventLog_RingEvictsOldest(t *testing.T) {
+	l := NewEventLog(3)
+	base := time.Now()
+	for i := 0; i < 5; i++ {
+		l.Add(Event{Time: base.Add(time.Duration(i) * time.Second), Message: string(rune('a' + i))})
+	}
+
+	events := l.Since(time.Time{})
+	if len(events) != 3 {
+		t.Fatalf("Since() returned %d events, want 3", len(events))
+	}
+	for i, want := range []string{"c", "d", "e"} {
+		if events[i].Message != want {
+			t.Errorf("events[%d] = %q, want %q", i, events[i].Message, want)
+		}
+	}
+
+	if events := l.Since(base.Add(3 * time.Second)); len(events) != 1 || events[0].Message != "e" {
+		t.Errorf("Since(+3s) = %+v, want only e", events)
+	}
+}
+
+func TestEventLog_Write(t *testing.T) {
+	l := NewEventLog(10)
+	logger := log.New(l, "", log.LstdFlags)
+
+	logger.Printf("[client] Client connected: abcd1234 as writer (total: 1)")
+	logger.Printf("Error: failed to read card")
+	logger.Printf("Warning: Wear stats disabled")
+	logger.Printf("Successfully wrote NDEF message to card UID: 04A1B2C3")
+
+	want := []Event{
+		{Level: EventLevelInfo, Category: "client", Message: "Client connected: abcd1234 as writer (total: 1)"},
+		{Level: EventLevelError, Category: "scan", Message: "Error: failed to read card"},
+		{Level: EventLevelWarn, Category: "agent", Message: "Warning: Wear stats disabled"},
+		{Level: EventLevelInfo, Category: "write", Message: "Successfully wrote NDEF message to card UID: 04A1B2C3"},
+	}
+
+	events := l.Since(time.Time{})
+	if len(events) != len(want) {
+		t.Fatalf("Recorded %d events, want %d: %+v", len(events), len(want), events)
+	}
+	for i, w := range want {
+		e := events[i]
+		if e.Level != w.Level || e.Category != w.Category || e.Message != w.Message {
+			t.Errorf("events[%d] = {%s %s %q}, want {%s %s %q}", i, e.Level, e.Category, e.Message, w.Level, w.Category, w.Message)
+		}
+		if e.Time.IsZero() {
+			t.Errorf("events[%d] has no timestamp", i)
+		}
+	}
+}
@@ -17,6 +17,10 @@ type WriteRecord struct {
 
 	// Language code for text records (default: "en")
 	Language string `json:"language,omitempty"`
+
+	// Fields are the structured fields of a registered External Type record,
+	// in which case Type is the external type name (domain:type)
+	Fields map[string]any `json:"fields,omitempty"`
 }
 
 // WriteRequest represents a request to write data to an NFC card.
@@ -26,6 +30,20 @@ type WriteRecord struct {
 type WriteRequest struct {
 	// Records is an array of NDEF records to write
 	Records []WriteRecord `json:"records"`
+
+	// DeviceID optionally routes the write to a registered device (phone)
+	// instead of the hardware reader
+	DeviceID string `json:"deviceID,omitempty"`
+
+	// ReaderID optionally routes the write to one of the lane readers, matching
+	// the readerId reported with their tag data
+	ReaderID string `json:"readerId,omitempty"`
+
+	// LockAfterWrite makes the card read-only once the write is verified
+	LockAfterWrite bool `json:"lockAfterWrite,omitempty"`
+
+	// Trace returns the APDUs exchanged during the write (debug commands only)
+	Trace bool `json:"trace,omitempty"`
 }
 
 // BuildNDEFMessage builds an NDEF message from the request.
@@ -55,7 +73,10 @@ func BuildNDEFMessage(writeReq WriteRequest) (*nfc.NDEFMessage, error) {
 		case "uri":
 			builder = &nfc.NDEFURI{Content: record.Content}
 		default:
-			return nil, fmt.Errorf("unsupported record type '%s' at index %d", recordType, i)
+			if !nfc.IsExternalTypeRegistered(recordType) {
+				return nil, fmt.Errorf("unsupported record type '%s' at index %d", recordType, i)
+			}
+			builder = &nfc.NDEFExternalRecord{Name: recordType, Fields: record.Fields}
 		}
 
 		recordBuilders = append(recordBuilders, builder)
@@ -65,7 +86,10 @@ func BuildNDEFMessage(writeReq WriteRequest) (*nfc.NDEFMessage, error) {
 	builder := &nfc.NDEFMessageBuilder{
 		Records: recordBuilders,
 	}
-	ndefMsg := builder.MustBuild()
+	ndefMsg, err := builder.Build()
+	if err != nil {
+		return nil, err
+	}
 
 	log.Printf("WriteRequest: Writing %d NDEF record(s) (complete overwrite)", len(recordBuilders))
 	return ndefMsg, nil
@@ -82,8 +106,9 @@ func HandleWriteRequest(reader *nfc.NFCReader, writeReq WriteRequest) error {
 
 	// Write with overwrite option (complete replacement)
 	err = reader.WriteMessageWithOptions(ndefMsg, nfc.WriteOptions{
-		Overwrite: true,
-		Index:     -1,
+		Overwrite:      true,
+		Index:          -1,
+		LockAfterWrite: writeReq.LockAfterWrite,
 	})
 	if err != nil {
 		return fmt.Errorf("write failed: %w", err)
@@ -1,6 +1,7 @@
 package server
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/dotside-studios/davi-nfc-agent/nfc"
@@ -93,6 +94,15 @@ func TestBuildNDEFMessage(t *testing.T) {
 			},
 			expectError: true,
 		},
+		{
+			name: "Unregistered external type",
+			request: WriteRequest{
+				Records: []WriteRecord{
+					{Type: "example.com:unknown", Fields: map[string]any{"id": "1"}},
+				},
+			},
+			expectError: true,
+		},
 		{
 			name: "Empty records array",
 			request: WriteRequest{
@@ -127,3 +137,28 @@ func TestBuildNDEFMessage(t *testing.T) {
 		})
 	}
 }
+
+// TestBuildNDEFMessage_ExternalType tests that registered external types are
+// built from their structured fields.
+func TestBuildNDEFMessage_ExternalType(t *testing.T) {
+	err := nfc.RegisterExternalType("example.com:ticket", nfc.ExternalTypeCodec{
+		Decode: func(p []byte) (map[string]any, error) { return map[string]any{"seat": string(p)}, nil },
+		Encode: func(f map[string]any) ([]byte, error) { return []byte(fmt.Sprint(f["seat"])), nil },
+	})
+	if err != nil {
+		t.Fatalf("RegisterExternalType() error = %v", err)
+	}
+	defer nfc.UnregisterExternalType("example.com:ticket")
+
+	msg, err := BuildNDEFMessage(WriteRequest{
+		Records: []WriteRecord{{Type: "example.com:ticket", Fields: map[string]any{"seat": "12A"}}},
+	})
+	if err != nil {
+		t.Fatalf("BuildNDEFMessage() error = %v", err)
+	}
+
+	fields, ok := msg.Records()[0].ExternalFields()
+	if !ok || fields["seat"] != "12A" {
+		t.Errorf("Expected seat 12A, got %v (decoded: %v)", fields, ok)
+	}
+}
@@ -0,0 +1,48 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/dotside-studios/davi-nfc-agent/store"
+)
+
+// AgentIDFile is the store key (a file in the config directory) that holds
+// the agent ID.
+const AgentIDFile = "agent-id"
+
+// LoadOrCreateAgentID returns the agent ID kept in s, generating and saving a
+// random one on first use. The ID is advertised over mDNS so clients can tell
+// agents on the same network apart and recognize one across restarts.
+func LoadOrCreateAgentID(s store.Store) (string, error) {
+	data, err := s.Get(AgentIDFile)
+	if err == nil {
+		if id := strings.TrimSpace(string(data)); id != "" {
+			return id, nil
+		}
+	} else if !errors.Is(err, store.ErrNotFound) {
+		return "", fmt.Errorf("failed to read agent ID: %w", err)
+	}
+
+	id := uuid.New().String()
+	if err := s.Set(AgentIDFile, []byte(id+"\n")); err != nil {
+		return "", fmt.Errorf("failed to save agent ID: %w", err)
+	}
+	return id, nil
+}
+
+// DefaultMDNSInstanceName returns the device server's mDNS instance name for
+// this host, e.g. "Davi NFC Agent Device (front-desk)". It falls back to
+// MDNSDeviceServiceName when the hostname is unavailable.
+func DefaultMDNSInstanceName() string {
+	host, err := os.Hostname()
+	host, _, _ = strings.Cut(host, ".") // Drop ".local" and other domain suffixes
+	if err != nil || host == "" {
+		return MDNSDeviceServiceName
+	}
+	return fmt.Sprintf("%s (%s)", MDNSDeviceServiceName, host)
+}
@@ -0,0 +1,53 @@
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dotside-studios/davi-nfc-agent/store"
+)
+
+func TestLoadOrCreateAgentID(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "config")
+	s := store.NewFileStore(dir)
+
+	id, err := LoadOrCreateAgentID(s)
+	if err != nil {
+		t.Fatalf("LoadOrCreateAgentID() failed: %v", err)
+	}
+	if id == "" {
+		t.Fatal("Expected a generated ID")
+	}
+
+	again, err := LoadOrCreateAgentID(s)
+	if err != nil {
+		t.Fatalf("LoadOrCreateAgentID() on reload failed: %v", err)
+	}
+	if again != id {
+		t.Errorf("Expected persisted ID %q, got %q", id, again)
+	}
+
+	// A hand-edited ID is kept as is
+	if err := os.WriteFile(filepath.Join(dir, AgentIDFile), []byte("  front-desk \n"), 0600); err != nil {
+		t.Fatalf("Failed to write ID: %v", err)
+	}
+	if id, _ := LoadOrCreateAgentID(s); id != "front-desk" {
+		t.Errorf("Expected ID front-desk, got %q", id)
+	}
+}
+
+func TestDefaultMDNSInstanceName(t *testing.T) {
+	name := DefaultMDNSInstanceName()
+	if !strings.HasPrefix(name, MDNSDeviceServiceName) {
+		t.Errorf("Expected name to start with %q, got %q", MDNSDeviceServiceName, name)
+	}
+
+	if host, err := os.Hostname(); err == nil && host != "" {
+		short, _, _ := strings.Cut(host, ".")
+		if !strings.HasSuffix(name, "("+short+")") {
+			t.Errorf("Expected name to end with (%s), got %q", short, name)
+		}
+	}
+}
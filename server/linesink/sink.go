@@ -0,0 +1,251 @@
+// Package linesink writes tag scans as plain text lines, one per scan, to
+// stdout or to clients of a TCP port. It serves legacy integrations that read
+// "UID,text" records instead of WebSocket JSON.
+package linesink
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/dotside-studios/davi-nfc-agent/nfc"
+)
+
+// DefaultFormat writes the UID and the card text as a CSV record.
+const DefaultFormat = "{{.UID}},{{csv .Text}}"
+
+// lineBufferSize is the number of lines queued for the destination before
+// new scans are dropped.
+const lineBufferSize = 64
+
+// tcpWriteTimeout bounds how long a slow TCP client can hold up a line.
+const tcpWriteTimeout = time.Second
+
+// Record holds the fields a format template can use.
+type Record struct {
+	UID        string
+	Type       string
+	Technology string
+	Text       string // Text record, or the URI when there is no text
+	ReaderID   string // Lane reader ID, when readers are merged
+	ScannedAt  time.Time
+}
+
+// Format renders a Record as one line using a text/template, e.g.
+// "{{.UID}};{{.Type}};{{csv .Text}}". Besides the Record fields, templates can
+// call csv, which quotes a value for a CSV field when it needs it.
+type Format struct {
+	tmpl *template.Template
+}
+
+// ParseFormat parses a format template; empty selects DefaultFormat.
+func ParseFormat(s string) (*Format, error) {
+	if s == "" {
+		s = DefaultFormat
+	}
+	tmpl, err := template.New("line").Funcs(template.FuncMap{"csv": csvField}).Parse(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid line format: %w", err)
+	}
+	// Fail on unknown fields now rather than on the first scan
+	if err := tmpl.Execute(io.Discard, Record{}); err != nil {
+		return nil, fmt.Errorf("invalid line format: %w", err)
+	}
+	return &Format{tmpl: tmpl}, nil
+}
+
+// Render returns the line for r, without the trailing newline. Line breaks
+// in the result are replaced by spaces, so every scan is exactly one line.
+func (f *Format) Render(r Record) (string, error) {
+	var b strings.Builder
+	if err := f.tmpl.Execute(&b, r); err != nil {
+		return "", err
+	}
+	return strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ").Replace(b.String()), nil
+}
+
+// csvField quotes s as a CSV field if it contains a separator or quote.
+func csvField(s string) string {
+	if !strings.ContainsAny(s, ",;\"\r\n") {
+		return s
+	}
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+// Sink writes a line for every scanned card to its destination.
+type Sink struct {
+	format *Format
+	lines  chan string
+	done   chan struct{}
+	once   sync.Once
+
+	out io.Writer // stdout destination
+
+	listener net.Listener // TCP destination
+	connsMu  sync.Mutex
+	conns    map[net.Conn]struct{}
+}
+
+// New creates a sink for dest, which is "stdout" or "tcp:<address>" (e.g.
+// "tcp::9473" for every interface), and starts writing to it.
+func New(dest string, format *Format) (*Sink, error) {
+	s := &Sink{
+		format: format,
+		lines:  make(chan string, lineBufferSize),
+		done:   make(chan struct{}),
+		conns:  make(map[net.Conn]struct{}),
+	}
+
+	switch {
+	case dest == "stdout":
+		s.out = os.Stdout
+	case strings.HasPrefix(dest, "tcp:"):
+		listener, err := net.Listen("tcp", strings.TrimPrefix(dest, "tcp:"))
+		if err != nil {
+			return nil, fmt.Errorf("line sink: %w", err)
+		}
+		s.listener = listener
+		go s.accept()
+	default:
+		return nil, fmt.Errorf("unknown line sink destination %q (expected stdout or tcp:<address>)", dest)
+	}
+
+	go s.run()
+	return s, nil
+}
+
+// Addr returns the TCP address the sink listens on, or nil for stdout.
+func (s *Sink) Addr() net.Addr {
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Addr()
+}
+
+// Write queues a line for a scanned card. Errors and events without a card
+// are skipped. It never blocks: when the destination falls behind, the line
+// is dropped.
+func (s *Sink) Write(data nfc.NFCData) {
+	if data.Err != nil || data.Card == nil {
+		return
+	}
+
+	line, err := s.format.Render(recordFor(data))
+	if err != nil {
+		log.Printf("[linesink] Failed to render line for %s: %v", data.Card.UID, err)
+		return
+	}
+
+	select {
+	case <-s.done:
+	case s.lines <- line:
+	default:
+		log.Printf("[linesink] Destination is behind, dropped line for %s", data.Card.UID)
+	}
+}
+
+// Close stops the sink and disconnects TCP clients.
+func (s *Sink) Close() error {
+	var err error
+	s.once.Do(func() {
+		close(s.done)
+		if s.listener != nil {
+			err = s.listener.Close()
+		}
+		s.connsMu.Lock()
+		for conn := range s.conns {
+			conn.Close()
+			delete(s.conns, conn)
+		}
+		s.connsMu.Unlock()
+	})
+	return err
+}
+
+// recordFor extracts the template fields from a scan.
+func recordFor(data nfc.NFCData) Record {
+	card := data.Card
+	r := Record{
+		UID:        card.UID,
+		Type:       card.Type,
+		Technology: card.Technology,
+		ReaderID:   data.ReaderID,
+		ScannedAt:  card.ScannedAt,
+	}
+
+	if msg, err := card.ReadMessage(); err == nil {
+		if ndefMsg, ok := msg.(*nfc.NDEFMessage); ok {
+			r.Text, _ = ndefMsg.GetText()
+			if r.Text == "" {
+				r.Text, _ = ndefMsg.GetURI()
+			}
+		} else if textMsg, ok := msg.(*nfc.TextMessage); ok {
+			r.Text = textMsg.Text
+		}
+	}
+	return r
+}
+
+// run writes queued lines to the destination.
+func (s *Sink) run() {
+	for {
+		select {
+		case <-s.done:
+			return
+		case line := <-s.lines:
+			s.send(line + "\n")
+		}
+	}
+}
+
+// send writes one line to stdout or every TCP client, dropping clients that
+// fail to take it.
+func (s *Sink) send(line string) {
+	if s.out != nil {
+		if _, err := io.WriteString(s.out, line); err != nil {
+			log.Printf("[linesink] Failed to write line: %v", err)
+		}
+		return
+	}
+
+	s.connsMu.Lock()
+	defer s.connsMu.Unlock()
+	for conn := range s.conns {
+		conn.SetWriteDeadline(time.Now().Add(tcpWriteTimeout))
+		if _, err := io.WriteString(conn, line); err != nil {
+			log.Printf("[linesink] Dropping client %s: %v", conn.RemoteAddr(), err)
+			conn.Close()
+			delete(s.conns, conn)
+		}
+	}
+}
+
+// accept registers TCP clients until the sink is closed.
+func (s *Sink) accept() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			select {
+			case <-s.done:
+			default:
+				log.Printf("[linesink] Accept failed: %v", err)
+			}
+			return
+		}
+
+		s.connsMu.Lock()
+		select {
+		case <-s.done:
+			conn.Close()
+		default:
+			s.conns[conn] = struct{}{}
+		}
+		s.connsMu.Unlock()
+	}
+}
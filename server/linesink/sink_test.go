@@ -0,0 +1,114 @@
+package linesink
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dotside-studios/davi-nfc-agent/nfc"
+)
+
+// scan returns tag data for a mock card holding text.
+func scan(uid, text string) nfc.NFCData {
+	tag := nfc.NewMockTag(uid)
+	tag.IsConnected = true
+	tag.Data = nfc.EncodeNdefMessageWithTextRecord(text, "en")
+	return nfc.NFCData{Card: nfc.NewCard(tag)}
+}
+
+func TestFormat_Render(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		record Record
+		want   string
+	}{
+		{"default", "", Record{UID: "04A1B2C3", Text: "Hello"}, "04A1B2C3,Hello"},
+		{"quoted", "", Record{UID: "04A1B2C3", Text: `a, "b"`}, `04A1B2C3,"a, ""b"""`},
+		{"line breaks", "", Record{UID: "04A1B2C3", Text: "two\nlines"}, `04A1B2C3,"two lines"`},
+		{"custom", "{{.ReaderID}};{{.UID}};{{.Type}}", Record{UID: "04", Type: "NTAG215", ReaderID: "lane1"}, "lane1;04;NTAG215"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := ParseFormat(tt.format)
+			if err != nil {
+				t.Fatalf("ParseFormat() error = %v", err)
+			}
+			got, err := f.Render(tt.record)
+			if err != nil || got != tt.want {
+				t.Errorf("Render() = %q, %v; want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFormat_Errors(t *testing.T) {
+	for _, format := range []string{"{{.UID", "{{.Missing}}"} {
+		if _, err := ParseFormat(format); err == nil {
+			t.Errorf("ParseFormat(%q) succeeded, want error", format)
+		}
+	}
+}
+
+func TestNew_UnknownDestination(t *testing.T) {
+	f, _ := ParseFormat("")
+	if _, err := New("udp::9473", f); err == nil {
+		t.Error("Expected an unknown destination to be rejected")
+	}
+}
+
+// TestSink_TCP tests that every TCP client receives a line per scan and that
+// errors are skipped.
+func TestSink_TCP(t *testing.T) {
+	f, _ := ParseFormat("")
+	sink, err := New("tcp:127.0.0.1:0", f)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer sink.Close()
+
+	var readers []*bufio.Reader
+	for i := 0; i < 2; i++ {
+		conn, err := net.Dial("tcp", sink.Addr().String())
+		if err != nil {
+			t.Fatalf("Dial() error = %v", err)
+		}
+		defer conn.Close()
+		conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		readers = append(readers, bufio.NewReader(conn))
+	}
+
+	// Wait until both clients are registered
+	deadline := time.Now().Add(time.Second)
+	for {
+		sink.connsMu.Lock()
+		n := len(sink.conns)
+		sink.connsMu.Unlock()
+		if n == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected 2 clients, got %d", n)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	sink.Write(nfc.NFCData{Err: errors.New("read failed")})
+	sink.Write(scan("04A1B2C3", "Hello"))
+	sink.Write(scan("04D5E6F7", "World"))
+
+	for i, r := range readers {
+		for _, want := range []string{"04A1B2C3,Hello", "04D5E6F7,World"} {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				t.Fatalf("Client %d: ReadString() error = %v", i, err)
+			}
+			if got := strings.TrimSuffix(line, "\n"); got != want {
+				t.Errorf("Client %d: line = %q, want %q", i, got, want)
+			}
+		}
+	}
+}
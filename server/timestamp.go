@@ -0,0 +1,43 @@
+package server
+
+import (
+	"fmt"
+	"time"
+)
+
+// TimestampFormat selects how timestamps are encoded in client payloads.
+type TimestampFormat string
+
+const (
+	// TimestampRFC3339 encodes timestamps as RFC3339 strings (default).
+	TimestampRFC3339 TimestampFormat = "rfc3339"
+	// TimestampEpochMillis encodes timestamps as integer milliseconds since the Unix epoch.
+	TimestampEpochMillis TimestampFormat = "epochms"
+	// TimestampBoth keeps the RFC3339 string and adds a "<key>Ms" epoch-millis field.
+	TimestampBoth TimestampFormat = "both"
+)
+
+// ParseTimestampFormat parses a timestamp format name. An empty string selects RFC3339.
+func ParseTimestampFormat(s string) (TimestampFormat, error) {
+	switch TimestampFormat(s) {
+	case "", TimestampRFC3339:
+		return TimestampRFC3339, nil
+	case TimestampEpochMillis, TimestampBoth:
+		return TimestampFormat(s), nil
+	default:
+		return TimestampRFC3339, fmt.Errorf("unknown timestamp format %q (expected rfc3339, epochms or both)", s)
+	}
+}
+
+// SetTimestamp stores t in payload under key using the given format.
+func SetTimestamp(payload map[string]any, key string, t time.Time, format TimestampFormat) {
+	switch format {
+	case TimestampEpochMillis:
+		payload[key] = t.UnixMilli()
+	case TimestampBoth:
+		payload[key] = t.Format(time.RFC3339)
+		payload[key+"Ms"] = t.UnixMilli()
+	default:
+		payload[key] = t.Format(time.RFC3339)
+	}
+}
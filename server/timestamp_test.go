@@ -0,0 +1,58 @@
+package server
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTimestampFormat(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    TimestampFormat
+		wantErr bool
+	}{
+		{"", TimestampRFC3339, false},
+		{"rfc3339", TimestampRFC3339, false},
+		{"epochms", TimestampEpochMillis, false},
+		{"both", TimestampBoth, false},
+		{"unix", TimestampRFC3339, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseTimestampFormat(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTimestampFormat(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseTimestampFormat(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetTimestamp(t *testing.T) {
+	ts := time.Date(2024, 10, 6, 12, 34, 56, 0, time.UTC)
+	const millis = int64(1728218096000)
+
+	payload := map[string]any{}
+	SetTimestamp(payload, "scannedAt", ts, TimestampRFC3339)
+	if payload["scannedAt"] != "2024-10-06T12:34:56Z" {
+		t.Errorf("rfc3339: scannedAt = %v", payload["scannedAt"])
+	}
+	if _, ok := payload["scannedAtMs"]; ok {
+		t.Error("rfc3339: unexpected scannedAtMs field")
+	}
+
+	payload = map[string]any{}
+	SetTimestamp(payload, "scannedAt", ts, TimestampEpochMillis)
+	if payload["scannedAt"] != millis {
+		t.Errorf("epochms: scannedAt = %v, want %d", payload["scannedAt"], millis)
+	}
+
+	payload = map[string]any{}
+	SetTimestamp(payload, "scannedAt", ts, TimestampBoth)
+	if payload["scannedAt"] != "2024-10-06T12:34:56Z" || payload["scannedAtMs"] != millis {
+		t.Errorf("both: payload = %v", payload)
+	}
+}
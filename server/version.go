@@ -0,0 +1,59 @@
+package server
+
+import (
+	"github.com/dotside-studios/davi-nfc-agent/buildinfo"
+	"github.com/dotside-studios/davi-nfc-agent/protocol"
+)
+
+// Features lists the optional protocol features this agent supports.
+// Clients use it to negotiate behavior instead of comparing version numbers.
+var Features = []string{
+	"ready",
+	WSMessageTypeWriteRequest,
+	WSMessageTypeFormatNDEF,
+	WSMessageTypeReadManufacturerBlock,
+	WSMessageTypeGetVersion,
+	WSMessageTypeWaitForCard,
+	WSMessageTypeReadRange,
+	WSMessageTypeGetWearStats,
+	WSMessageTypeSubscribe,
+	"compress",
+	WSMessageTypeDeviceStatusPatch,
+	WSMessageTypeClearCache,
+	WSMessageTypeGetCardInfo,
+	WSMessageTypeListTags,
+	WSMessageTypeReadCard,
+	WSMessageTypeWriteRaw,
+	WSMessageTypeResetDevice,
+	WSMessageTypeGetLatencyStats,
+	WSMessageTypeGetAllowedTypes,
+	WSMessageTypeSetAllowedTypes,
+	WSMessageTypeNormalize,
+	WSMessageTypePause,
+	WSMessageTypeResume,
+	WSMessageTypeWriteLocalizedText,
+	WSMessageTypeCheckWrite,
+	WSMessageTypeGetATR,
+	WSMessageTypeWriteAndRead,
+	WSMessageTypeCardHeartbeat,
+	WSMessageTypeCaptureReference,
+	WSMessageTypeCompareToReference,
+	WSMessageTypeQuickWritable,
+	WSMessageTypeRescan,
+	WSMessageTypeGetCapabilities,
+	WSMessageTypeUnsupportedCard,
+}
+
+// VersionInfo returns the agent version, build metadata and supported features.
+func VersionInfo() protocol.VersionPayload {
+	features := make([]string, len(Features))
+	copy(features, Features)
+
+	return protocol.VersionPayload{
+		Name:      buildinfo.Name,
+		Version:   buildinfo.Version,
+		Commit:    buildinfo.Commit,
+		BuildTime: buildinfo.BuildTime,
+		Features:  features,
+	}
+}
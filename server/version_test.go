@@ -0,0 +1,34 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/dotside-studios/davi-nfc-agent/buildinfo"
+)
+
+func TestVersionInfo(t *testing.T) {
+	info := VersionInfo()
+
+	if info.Version != buildinfo.Version {
+		t.Errorf("Version = %q, want %q", info.Version, buildinfo.Version)
+	}
+	if info.Commit != buildinfo.Commit {
+		t.Errorf("Commit = %q, want %q", info.Commit, buildinfo.Commit)
+	}
+
+	found := false
+	for _, f := range info.Features {
+		if f == WSMessageTypeGetVersion {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Features %v should include %q", info.Features, WSMessageTypeGetVersion)
+	}
+
+	// Callers must not be able to mutate the shared feature list
+	info.Features[0] = "mutated"
+	if Features[0] == "mutated" {
+		t.Error("VersionInfo() should return a copy of Features")
+	}
+}
@@ -0,0 +1,166 @@
+// Package store provides small key/value persistence for agent features such
+// as wear stats and the agent ID, so embedders can supply their own storage
+// where files are not available.
+//
+// Keys are plain names such as "wear-stats.json"; the file store maps each key
+// to a file of that name in its directory.
+package store
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ErrNotFound is returned by Get for keys that have never been set.
+var ErrNotFound = errors.New("key not found")
+
+// Store persists values by key. Implementations must be safe for concurrent use.
+type Store interface {
+	// Get returns the value stored under key, or ErrNotFound.
+	Get(key string) ([]byte, error)
+	// Set replaces the value stored under key.
+	Set(key string, value []byte) error
+	// Append adds value to the end of the value stored under key, creating it
+	// if needed. Callers add their own separators, e.g. a newline per entry.
+	Append(key string, value []byte) error
+}
+
+// validateKey refuses keys that would escape the store's directory.
+func validateKey(key string) error {
+	if key == "" || key == "." || key == ".." || strings.ContainsAny(key, `/\`) {
+		return fmt.Errorf("invalid store key %q", key)
+	}
+	return nil
+}
+
+// FileStore keeps each key in a file of the same name in one directory,
+// created on first write.
+type FileStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewFileStore returns a store keeping its files in dir.
+func NewFileStore(dir string) *FileStore {
+	return &FileStore{dir: dir}
+}
+
+// Path returns the file that holds key.
+func (s *FileStore) Path(key string) string {
+	return filepath.Join(s.dir, key)
+}
+
+// Get reads the file for key.
+func (s *FileStore) Get(key string) ([]byte, error) {
+	if err := validateKey(key); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(s.Path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", key, err)
+	}
+	return data, nil
+}
+
+// Set writes the file for key via a temporary file, so a crash mid-write
+// cannot truncate it.
+func (s *FileStore) Set(key string, value []byte) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return fmt.Errorf("failed to create store directory: %w", err)
+	}
+
+	path := s.Path(key)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, value, 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", key, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to save %s: %w", key, err)
+	}
+	return nil
+}
+
+// Append appends value to the file for key.
+func (s *FileStore) Append(key string, value []byte) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return fmt.Errorf("failed to create store directory: %w", err)
+	}
+
+	f, err := os.OpenFile(s.Path(key), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", key, err)
+	}
+	if _, err := f.Write(value); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to append to %s: %w", key, err)
+	}
+	return f.Close()
+}
+
+// MemoryStore keeps values in memory only. The zero value is ready to use.
+type MemoryStore struct {
+	mu     sync.Mutex
+	values map[string][]byte
+}
+
+// NewMemoryStore returns an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+// Get returns a copy of the value for key.
+func (s *MemoryStore) Get(key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	value, ok := s.values[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return append([]byte(nil), value...), nil
+}
+
+// Set stores a copy of value under key.
+func (s *MemoryStore) Set(key string, value []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.values == nil {
+		s.values = make(map[string][]byte)
+	}
+	s.values[key] = append([]byte(nil), value...)
+	return nil
+}
+
+// Append adds value to the end of the value under key.
+func (s *MemoryStore) Append(key string, value []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.values == nil {
+		s.values = make(map[string][]byte)
+	}
+	s.values[key] = append(s.values[key], value...)
+	return nil
+}
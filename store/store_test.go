@@ -0,0 +1,60 @@
+package store
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStores(t *testing.T) {
+	stores := map[string]Store{
+		"file":   NewFileStore(filepath.Join(t.TempDir(), "config")),
+		"memory": NewMemoryStore(),
+	}
+
+	for name, s := range stores {
+		t.Run(name, func(t *testing.T) {
+			if _, err := s.Get("missing"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Get() of a missing key = %v, want ErrNotFound", err)
+			}
+
+			if err := s.Set("key", []byte("one")); err != nil {
+				t.Fatalf("Set() failed: %v", err)
+			}
+			if err := s.Set("key", []byte("two")); err != nil {
+				t.Fatalf("Set() failed: %v", err)
+			}
+			if got, err := s.Get("key"); err != nil || string(got) != "two" {
+				t.Errorf("Get() = %q, %v; want %q", got, err, "two")
+			}
+
+			for _, line := range []string{"a\n", "b\n"} {
+				if err := s.Append("log", []byte(line)); err != nil {
+					t.Fatalf("Append() failed: %v", err)
+				}
+			}
+			if got, err := s.Get("log"); err != nil || string(got) != "a\nb\n" {
+				t.Errorf("Get() after Append() = %q, %v; want %q", got, err, "a\nb\n")
+			}
+		})
+	}
+}
+
+func TestFileStore_Keys(t *testing.T) {
+	dir := t.TempDir()
+	s := NewFileStore(dir)
+
+	if err := s.Set("agent-id", []byte("id")); err != nil {
+		t.Fatalf("Set() failed: %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "agent-id")); err != nil || string(data) != "id" {
+		t.Errorf("File contents = %q, %v; want %q", data, err, "id")
+	}
+
+	for _, key := range []string{"", "..", "../escape", `sub\key`} {
+		if err := s.Set(key, nil); err == nil {
+			t.Errorf("Set(%q) expected error", key)
+		}
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"net"
 	"os/exec"
 	"runtime"
+	"strings"
+	"sync"
 	"time"
 
 	"fyne.io/systray"
@@ -14,6 +16,28 @@ import (
 	"github.com/dotside-studios/davi-nfc-agent/nfc"
 )
 
+// CardDisplayFormat controls how the last-seen card is labelled in the tray menu
+type CardDisplayFormat string
+
+const (
+	CardDisplayUID  CardDisplayFormat = "uid"  // Show only the card UID
+	CardDisplayText CardDisplayFormat = "text" // Show only the card's text content
+	CardDisplayBoth CardDisplayFormat = "both" // Show UID followed by text content
+
+	// DefaultCardTextMaxLen is the default number of characters shown before ellipsizing
+	DefaultCardTextMaxLen = 32
+)
+
+// ParseCardDisplayFormat parses a card display format name
+func ParseCardDisplayFormat(name string) (CardDisplayFormat, error) {
+	switch format := CardDisplayFormat(strings.ToLower(name)); format {
+	case CardDisplayUID, CardDisplayText, CardDisplayBoth:
+		return format, nil
+	default:
+		return "", fmt.Errorf("invalid card display format %q (must be uid, text or both)", name)
+	}
+}
+
 // cardTypeFilterItem holds a menu item and its associated card type
 type cardTypeFilterItem struct {
 	menuItem *systray.MenuItem
@@ -45,6 +69,13 @@ type SystrayApp struct {
 	initialDevice string
 	bootstrapPort int
 
+	// CardDisplay selects the label format for the last-seen card. Set it
+	// before Run; afterwards the menu changes it under displayMu
+	CardDisplay CardDisplayFormat
+	displayMu   sync.Mutex
+	// CardTextMaxLen truncates card text longer than this many characters (0 disables)
+	CardTextMaxLen int
+
 	// Menu items
 	mStatus     *systray.MenuItem
 	mCardUID    *systray.MenuItem
@@ -70,6 +101,12 @@ type SystrayApp struct {
 	mReadMode      *systray.MenuItem
 	mWriteMode     *systray.MenuItem
 
+	// Card display menu items
+	mCardDisplayMenu *systray.MenuItem
+	mDisplayUID      *systray.MenuItem
+	mDisplayText     *systray.MenuItem
+	mDisplayBoth     *systray.MenuItem
+
 	// Card filter menu items
 	mCardFilterMenu *systray.MenuItem
 	mFilterAll      *systray.MenuItem
@@ -83,6 +120,8 @@ func NewSystrayApp(agent *Agent, initialDevice string, bootstrapPort int) *Systr
 		initialDevice:   initialDevice,
 		currentDevice:   initialDevice,
 		bootstrapPort:   bootstrapPort,
+		CardDisplay:     CardDisplayUID,
+		CardTextMaxLen:  DefaultCardTextMaxLen,
 		deviceMenuItems: make(map[string]*systray.MenuItem),
 		cardTypeFilters: make(map[string]*cardTypeFilterItem),
 	}
@@ -131,12 +170,18 @@ func (s *SystrayApp) setupUI() {
 	systray.AddSeparator()
 
 	// Card info section
-	s.mCardUID = systray.AddMenuItem("Card UID: None", "Current card UID")
+	s.mCardUID = systray.AddMenuItem(s.cardLabelTitle(""), "Last-seen card")
 	s.mCardUID.Disable()
 
 	s.mCardType = systray.AddMenuItem("Card Type: None", "Current card type")
 	s.mCardType.Disable()
 
+	s.mCardDisplayMenu = systray.AddMenuItem("Card Display", "Choose how the last card is shown")
+	display := s.cardDisplay()
+	s.mDisplayUID = s.mCardDisplayMenu.AddSubMenuItemCheckbox("UID", "Show card UID", display == CardDisplayUID)
+	s.mDisplayText = s.mCardDisplayMenu.AddSubMenuItemCheckbox("Text", "Show card text", display == CardDisplayText)
+	s.mDisplayBoth = s.mCardDisplayMenu.AddSubMenuItemCheckbox("UID and Text", "Show card UID and text", display == CardDisplayBoth)
+
 	systray.AddSeparator()
 
 	// Device management section
@@ -222,7 +267,7 @@ func (s *SystrayApp) startCardInfoUpdater() {
 	go func() {
 		ticker := time.NewTicker(500 * time.Millisecond)
 		defer ticker.Stop()
-		lastUID := ""
+		lastLabel := ""
 		lastType := ""
 
 		for range ticker.C {
@@ -232,10 +277,11 @@ func (s *SystrayApp) startCardInfoUpdater() {
 			}
 
 			uid, cardType := s.getCardInfo(card)
+			label := s.formatCardLabel(uid, getCardText(card))
 
-			if uid != lastUID {
-				s.updateCardUID(uid)
-				lastUID = uid
+			if label != lastLabel {
+				s.updateCardUID(label)
+				lastLabel = label
 			}
 
 			if cardType != lastType {
@@ -302,6 +348,12 @@ func (s *SystrayApp) handleMenuEvents(mRefreshDevices, mQuit *systray.MenuItem)
 			s.handleModeSwitch(nfc.ModeReadOnly, "Read Only")
 		case <-s.mWriteMode.ClickedCh:
 			s.handleModeSwitch(nfc.ModeWriteOnly, "Write Only")
+		case <-s.mDisplayUID.ClickedCh:
+			s.handleCardDisplaySwitch(CardDisplayUID)
+		case <-s.mDisplayText.ClickedCh:
+			s.handleCardDisplaySwitch(CardDisplayText)
+		case <-s.mDisplayBoth.ClickedCh:
+			s.handleCardDisplaySwitch(CardDisplayBoth)
 		case <-s.mFilterAll.ClickedCh:
 			s.handleFilterAll()
 		case <-mQuit.ClickedCh:
@@ -364,6 +416,34 @@ func (s *SystrayApp) handleModeSwitch(mode nfc.ReaderMode, modeName string) {
 	log.Printf("Switched to %s mode", modeName)
 }
 
+// handleCardDisplaySwitch changes the label format for the last-seen card
+func (s *SystrayApp) handleCardDisplaySwitch(format CardDisplayFormat) {
+	s.displayMu.Lock()
+	s.CardDisplay = format
+	s.displayMu.Unlock()
+
+	s.mDisplayUID.Uncheck()
+	s.mDisplayText.Uncheck()
+	s.mDisplayBoth.Uncheck()
+
+	switch format {
+	case CardDisplayUID:
+		s.mDisplayUID.Check()
+	case CardDisplayText:
+		s.mDisplayText.Check()
+	case CardDisplayBoth:
+		s.mDisplayBoth.Check()
+	}
+
+	// The updater only redraws on change, so refresh immediately
+	var card *nfc.Card
+	if s.agent.ClientServer != nil {
+		card = s.agent.ClientServer.GetLastCard()
+	}
+	uid, _ := s.getCardInfo(card)
+	s.updateCardUID(s.formatCardLabel(uid, getCardText(card)))
+}
+
 // handleFilterAll enables all card type filters
 func (s *SystrayApp) handleFilterAll() {
 	s.mFilterAll.Check()
@@ -505,15 +585,84 @@ func (s *SystrayApp) getCardInfo(card *nfc.Card) (uid, cardType string) {
 	return
 }
 
-// updateCardUID updates the card UID display
-func (s *SystrayApp) updateCardUID(uid string) {
+// getCardText returns the text already decoded for a card, without touching the reader
+func getCardText(card *nfc.Card) string {
+	if card == nil {
+		return ""
+	}
+	switch msg := card.MessageData.(type) {
+	case *nfc.NDEFMessage:
+		text, _ := msg.GetText()
+		return text
+	case *nfc.TextMessage:
+		return msg.Text
+	}
+	return ""
+}
+
+// cardDisplay returns the current card label format
+func (s *SystrayApp) cardDisplay() CardDisplayFormat {
+	s.displayMu.Lock()
+	defer s.displayMu.Unlock()
+	return s.CardDisplay
+}
+
+// formatCardLabel builds the card label value according to the display format
+func (s *SystrayApp) formatCardLabel(uid, text string) string {
 	if uid == "" {
-		s.mCardUID.SetTitle("Card UID: None")
-	} else {
-		s.mCardUID.SetTitle("Card UID: " + uid)
+		return ""
+	}
+	text = ellipsize(text, s.CardTextMaxLen)
+
+	switch s.cardDisplay() {
+	case CardDisplayText:
+		if text == "" {
+			return "(no text)"
+		}
+		return text
+	case CardDisplayBoth:
+		if text == "" {
+			return uid
+		}
+		return uid + " - " + text
+	default:
+		return uid
 	}
 }
 
+// cardLabelTitle returns the menu prefix matching the display format
+func (s *SystrayApp) cardLabelTitle(label string) string {
+	prefix := "Card UID: "
+	switch s.cardDisplay() {
+	case CardDisplayText:
+		prefix = "Card Text: "
+	case CardDisplayBoth:
+		prefix = "Card: "
+	}
+	if label == "" {
+		return prefix + "None"
+	}
+	return prefix + label
+}
+
+// ellipsize shortens text to maxLen characters, ending with an ellipsis
+func ellipsize(text string, maxLen int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	runes := []rune(text)
+	if maxLen <= 0 || len(runes) <= maxLen {
+		return text
+	}
+	if maxLen == 1 {
+		return "…"
+	}
+	return string(runes[:maxLen-1]) + "…"
+}
+
+// updateCardUID updates the last-seen card display
+func (s *SystrayApp) updateCardUID(label string) {
+	s.mCardUID.SetTitle(s.cardLabelTitle(label))
+}
+
 // updateCardType updates the card type display
 func (s *SystrayApp) updateCardType(cardType string) {
 	if cardType == "" {